@@ -18,8 +18,11 @@ package katalyst_base
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
 	"net/http"
 	"net/http/pprof"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -57,9 +60,14 @@ type GenericOptions func(i interface{})
 
 type GenericContext struct {
 	*http.Server
+	mux           *http.ServeMux
 	httpHandler   *process.HTTPHandler
 	healthChecker *HealthzChecker
 
+	// generic endpoint is served with https if tls cert and key are set
+	tlsCertFile string
+	tlsKeyFile  string
+
 	// those following components are shared by all generic components.
 	//nolint
 	BroadcastAdapter events.EventBroadcasterAdapter
@@ -164,6 +172,8 @@ func NewGenericContext(
 	// since some authentication implementation needs kcc and kcc only support agent component, so we only enable
 	// authentication for agent component for now.
 	if component == consts.KatalystComponentAgent {
+		RegisterTokenReviewCredential(clientSet)
+
 		cred, credErr := credential.GetCredential(genericConf, dynamicConfiguration)
 		if credErr != nil {
 			return nil, credErr
@@ -183,12 +193,21 @@ func NewGenericContext(
 		}
 	}
 
+	tlsConfig, err := GetTLSConfig(genericConf)
+	if err != nil {
+		return nil, err
+	}
+
 	c := &GenericContext{
+		mux:         mux,
 		httpHandler: httpHandler,
 		Server: &http.Server{
-			Handler: httpHandler.WithHandleChain(mux),
-			Addr:    genericConf.GenericEndpoint,
+			Handler:   httpHandler.WithHandleChain(mux),
+			Addr:      genericConf.GenericEndpoint,
+			TLSConfig: tlsConfig,
 		},
+		tlsCertFile:               genericConf.GenericEndpointTLSCertFile,
+		tlsKeyFile:                genericConf.GenericEndpointTLSKeyFile,
 		healthChecker:             NewHealthzChecker(customMetricsEmitterPool.GetDefaultMetricsEmitter()),
 		DisabledByDefault:         disabledByDefault,
 		MetaInformerFactory:       metaInformerFactory,
@@ -210,6 +229,38 @@ func NewGenericContext(
 	return c, nil
 }
 
+// RegisterTokenReviewCredential registers the initializer of token review credential;
+// it depends on kube client which can't be obtained from configurations, so components
+// must register it with their own client set before getting credentials.
+func RegisterTokenReviewCredential(clientSet *client.GenericClientSet) {
+	if clientSet != nil && clientSet.KubeClient != nil {
+		credential.RegisterCredentialInitializer(credential.AuthTypeTokenReview,
+			credential.NewTokenReviewCredentialInitializer(clientSet.KubeClient))
+	}
+}
+
+// GetTLSConfig returns the tls config of generic endpoint; client certificates are
+// verified if they are provided and client ca is set, and requests without
+// certificates are still allowed, e.g. health check or other credentials.
+func GetTLSConfig(genericConf *generic.GenericConfiguration) (*tls.Config, error) {
+	if genericConf.GenericEndpointTLSCertFile == "" {
+		return nil, nil
+	} else if genericConf.GenericEndpointTLSKeyFile == "" {
+		return nil, fmt.Errorf("tls key file must be set with tls cert file")
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if genericConf.ClientCAFile != "" {
+		clientCAs, err := credential.LoadCertPool(genericConf.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tlsConfig, nil
+}
+
 // IsEnabled checks if the context's components enabled or not
 func (c *GenericContext) IsEnabled(name string, components []string) bool {
 	return general.IsNameEnabled(name, c.DisabledByDefault, components)
@@ -220,6 +271,21 @@ func (c *GenericContext) SetDefaultMetricsEmitter(metricEmitter metrics.MetricEm
 	c.EmitterPool.SetDefaultMetricsEmitter(metricEmitter)
 }
 
+// RegisterHTTPHandler adds handler to the generic endpoint; since those handlers expose
+// internal states, requests to them are always authenticated and authorized regardless of
+// http-strict-authentication, and anonymous requests are rejected even with insecure auth type.
+// paths skipping authentication are not allowed.
+func (c *GenericContext) RegisterHTTPHandler(path string, handler http.Handler) error {
+	for _, prefix := range []string{healthZPath, debugPrefix} {
+		if strings.HasPrefix(path, prefix) {
+			return fmt.Errorf("path %v with reserved prefix %v is not allowed", path, prefix)
+		}
+	}
+
+	c.mux.Handle(path, c.httpHandler.WithStrictCredential(handler))
+	return nil
+}
+
 // Run starts the generic components
 func (c *GenericContext) Run(ctx context.Context) {
 	c.httpHandler.Run(ctx)
@@ -227,7 +293,11 @@ func (c *GenericContext) Run(ctx context.Context) {
 	c.EmitterPool.Run(ctx)
 	c.BroadcastAdapter.StartRecordingToSink(ctx.Done())
 	go func() {
-		klog.Fatal(c.ListenAndServe())
+		if c.tlsCertFile != "" {
+			klog.Fatal(c.ListenAndServeTLS(c.tlsCertFile, c.tlsKeyFile))
+		} else {
+			klog.Fatal(c.ListenAndServe())
+		}
 		<-ctx.Done()
 	}()
 }
@@ -17,6 +17,8 @@ limitations under the License.
 package options
 
 import (
+	"time"
+
 	"github.com/spf13/pflag"
 
 	"github.com/kubewharf/katalyst-core/pkg/config/generic"
@@ -32,28 +34,48 @@ type AuthOptions struct {
 	AccessControlType string
 
 	HttpStrictAuthentication bool
+
+	TokenReviewAudiences []string
+	TokenReviewCacheTTL  time.Duration
+
+	ClientCAFile              string
+	CertificateUsernameSource string
 }
 
 func NewAuthOptions() *AuthOptions {
 	return &AuthOptions{
-		AuthType:                 credential.AuthTypeInsecure,
-		AccessControlType:        authorization.AccessControlTypeInsecure,
-		HttpStrictAuthentication: false,
+		AuthType:                  credential.AuthTypeInsecure,
+		AccessControlType:         authorization.AccessControlTypeInsecure,
+		HttpStrictAuthentication:  false,
+		TokenReviewCacheTTL:       10 * time.Second,
+		CertificateUsernameSource: credential.CertificateUsernameSourceCN,
 	}
 }
 
 // AddFlags adds flags  to the specified FlagSet.
 func (o *AuthOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&o.AuthType, "auth-type", o.AuthType, "which auth type for common http endpoint,"+
-		"e.g. BasicAuth")
+		"e.g. Basic, TokenReview or Certificate")
 	fs.StringVar(&o.AccessControlType, "access-control-type", o.AccessControlType, "access control type")
 	fs.BoolVar(&o.HttpStrictAuthentication, "http-strict-authentication", o.HttpStrictAuthentication,
 		"whether to strict authenticate http request")
+	fs.StringSliceVar(&o.TokenReviewAudiences, "token-review-audiences", o.TokenReviewAudiences,
+		"the audiences that bearer tokens must be issued for, only used by TokenReview auth type")
+	fs.DurationVar(&o.TokenReviewCacheTTL, "token-review-cache-ttl", o.TokenReviewCacheTTL,
+		"the duration to cache authenticated bearer tokens, only used by TokenReview auth type")
+	fs.StringVar(&o.ClientCAFile, "client-ca-file", o.ClientCAFile,
+		"the ca bundle to verify client certificates, required by Certificate auth type")
+	fs.StringVar(&o.CertificateUsernameSource, "certificate-username-source", o.CertificateUsernameSource,
+		"which part of client certificate is used as the user, CN or SAN")
 }
 
 func (o *AuthOptions) ApplyTo(c *generic.AuthConfiguration) error {
 	c.AuthType = o.AuthType
 	c.AccessControlType = o.AccessControlType
 	c.HttpStrictAuthentication = o.HttpStrictAuthentication
+	c.TokenReviewAudiences = o.TokenReviewAudiences
+	c.TokenReviewCacheTTL = o.TokenReviewCacheTTL
+	c.ClientCAFile = o.ClientCAFile
+	c.CertificateUsernameSource = o.CertificateUsernameSource
 	return nil
 }
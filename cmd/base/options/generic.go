@@ -42,6 +42,8 @@ type GenericOptions struct {
 	// todo actually those auth info should be stored in secrets or somewhere like that
 	GenericEndpoint             string
 	GenericEndpointHandleChains []string
+	GenericEndpointTLSCertFile  string
+	GenericEndpointTLSKeyFile   string
 
 	qosOptions     *QoSOptions
 	metricsOptions *MetricsOptions
@@ -92,6 +94,10 @@ func (o *GenericOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 		"the endpoint of generic purpose, which will use as prometheus, health check and profiling")
 	fs.StringSliceVar(&o.GenericEndpointHandleChains, "generic-handler-chains", o.GenericEndpointHandleChains,
 		"this flag defines the handler chains that should be enabled")
+	fs.StringVar(&o.GenericEndpointTLSCertFile, "generic-endpoint-tls-cert-file", o.GenericEndpointTLSCertFile,
+		"the cert file to serve generic endpoint with https, required by client certificate authentication")
+	fs.StringVar(&o.GenericEndpointTLSKeyFile, "generic-endpoint-tls-key-file", o.GenericEndpointTLSKeyFile,
+		"the key file to serve generic endpoint with https")
 
 	o.qosOptions.AddFlags(fs)
 	o.metricsOptions.AddFlags(fs)
@@ -111,6 +117,8 @@ func (o *GenericOptions) ApplyTo(c *generic.GenericConfiguration) error {
 
 	c.GenericEndpoint = o.GenericEndpoint
 	c.GenericEndpointHandleChains = o.GenericEndpointHandleChains
+	c.GenericEndpointTLSCertFile = o.GenericEndpointTLSCertFile
+	c.GenericEndpointTLSKeyFile = o.GenericEndpointTLSKeyFile
 
 	errList := make([]error, 0, 1)
 	errList = append(errList, o.qosOptions.ApplyTo(c.QoSConfiguration))
@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
+	cgroupmgr "github.com/kubewharf/katalyst-core/pkg/util/cgroup/manager"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+)
+
+const (
+	CgroupReconciler = "katalyst-agent-cgroup-reconciler"
+)
+
+func InitCgroupReconciler(agentCtx *GenericContext, conf *config.Configuration, _ interface{}, _ string) (bool, Component, error) {
+	if conf.CgroupReconcileInterval <= 0 {
+		return false, ComponentStub{}, fmt.Errorf("invalid cgroup reconcile interval: %v", conf.CgroupReconcileInterval)
+	}
+
+	// desired values are recorded only if they are reconciled, since the
+	// reconciler is responsible for forgetting values of removed cgroups
+	common.GetDesiredStateStore().Enable()
+	reconciler := cgroupmgr.NewDesiredStateReconciler(common.GetDesiredStateStore(), eventbus.GetDefaultEventBus(),
+		agentCtx.EmitterPool.GetDefaultMetricsEmitter(), conf.CgroupReconcileInterval,
+		func(absCgroupPath string) string {
+			return getCgroupQoSLevel(agentCtx, conf, absCgroupPath)
+		})
+
+	return true, reconciler, nil
+}
+
+// getCgroupQoSLevel returns the qos level of pod that the cgroup belongs to,
+// and empty string is returned if the pod isn't found.
+func getCgroupQoSLevel(agentCtx *GenericContext, conf *config.Configuration, absCgroupPath string) string {
+	podUID, ok := common.GetPodUIDFromCgroupPath(absCgroupPath)
+	if !ok || agentCtx.MetaServer == nil {
+		return ""
+	}
+
+	pod, err := agentCtx.MetaServer.GetPod(context.Background(), podUID)
+	if err != nil {
+		return ""
+	}
+
+	qosLevel, err := conf.QoSConfiguration.GetQoSLevelForPod(pod)
+	if err != nil {
+		return ""
+	}
+	return qosLevel
+}
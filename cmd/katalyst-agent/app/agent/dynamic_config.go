@@ -0,0 +1,54 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"fmt"
+
+	"k8s.io/klog/v2"
+
+	dynamicoptions "github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options/dynamic"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/kcc"
+)
+
+const (
+	DynamicConfigView = "katalyst-agent-dynamic-config-view"
+)
+
+// InitDynamicConfigView registers http endpoint to show the effective dynamic
+// configuration; it only serves requests on the generic endpoint, so nothing
+// needs to be run.
+func InitDynamicConfigView(agentCtx *GenericContext, _ *config.Configuration, _ interface{}, _ string) (bool, Component, error) {
+	getter, ok := agentCtx.MetaServer.ConfigurationManager.(kcc.EffectiveConfigurationGetter)
+	if !ok {
+		klog.Warningf("configuration manager %T can't show effective configuration", agentCtx.MetaServer.ConfigurationManager)
+		return false, ComponentStub{}, nil
+	}
+
+	// built-in defaults are the configuration without any flags
+	builtin := dynamic.NewConfiguration()
+	if err := dynamicoptions.NewDynamicOptions().ApplyTo(builtin); err != nil {
+		return false, ComponentStub{}, fmt.Errorf("generate built-in dynamic configuration failed: %v", err)
+	}
+
+	if err := kcc.RegisterEffectiveConfigurationHandler(agentCtx.GenericContext, getter, builtin); err != nil {
+		return false, ComponentStub{}, fmt.Errorf("register dynamic config view failed: %v", err)
+	}
+	return false, ComponentStub{}, nil
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"fmt"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/utilcomponent/oomwatcher"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+)
+
+const (
+	OOMWatcher = "katalyst-agent-oom-watcher"
+)
+
+func InitOOMWatcher(agentCtx *GenericContext, conf *config.Configuration, _ interface{}, _ string) (bool, Component, error) {
+	if conf.OOMWatchInterval <= 0 {
+		return false, ComponentStub{}, fmt.Errorf("invalid oom watch interval: %v", conf.OOMWatchInterval)
+	}
+
+	watcher := oomwatcher.NewOOMWatcher(agentCtx.MetaServer.PodFetcher, eventbus.GetDefaultEventBus(),
+		agentCtx.BroadcastAdapter.NewRecorder(OOMWatcher), agentCtx.EmitterPool.GetDefaultMetricsEmitter(),
+		conf.OOMWatchInterval)
+
+	return true, watcher, nil
+}
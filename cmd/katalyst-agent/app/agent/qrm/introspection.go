@@ -0,0 +1,40 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package qrm
+
+import (
+	"fmt"
+
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/agent"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/introspection"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+)
+
+const (
+	QRMStateIntrospection = "qrm_state_introspection"
+)
+
+// InitQRMStateIntrospection registers http endpoints to inspect states of qrm plugins;
+// it only serves requests on the generic endpoint, so nothing needs to be run. Requests
+// are always authenticated and authorized, so the endpoints reject all requests until
+// a secure auth-type is configured.
+func InitQRMStateIntrospection(agentCtx *agent.GenericContext, _ *config.Configuration, _ interface{}, _ string) (bool, agent.Component, error) {
+	if err := introspection.NewHandler(agentCtx.MetaServer).Register(agentCtx.GenericContext); err != nil {
+		return false, agent.ComponentStub{}, fmt.Errorf("register qrm state introspection failed: %v", err)
+	}
+	return false, agent.ComponentStub{}, nil
+}
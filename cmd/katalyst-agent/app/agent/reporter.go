@@ -24,6 +24,7 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/agent/resourcemanager/fetcher"
 	"github.com/kubewharf/katalyst-core/pkg/agent/resourcemanager/reporter"
 	"github.com/kubewharf/katalyst-core/pkg/agent/resourcemanager/reporter/cnr"
+	"github.com/kubewharf/katalyst-core/pkg/agent/resourcemanager/reporter/node"
 	"github.com/kubewharf/katalyst-core/pkg/config"
 	"github.com/kubewharf/katalyst-core/pkg/util"
 )
@@ -34,6 +35,7 @@ const (
 
 func init() {
 	reporter.RegisterReporterInitializer(util.CNRGroupVersionKind, cnr.NewCNRReporter)
+	reporter.RegisterReporterInitializer(util.NodeGroupVersionKind, node.NewNodeReporter)
 }
 
 func InitReporterManager(agentCtx *GenericContext, conf *config.Configuration,
@@ -20,12 +20,19 @@ import (
 	"fmt"
 
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/trace"
 	"github.com/kubewharf/katalyst-core/pkg/config"
 )
 
 const QoSSysAdvisor = "katalyst-agent-advisor"
 
 func InitSysAdvisor(agentCtx *GenericContext, conf *config.Configuration, extraConf interface{}, _ string) (bool, Component, error) {
+	trace.SetDefaultRecorder(trace.NewRecorder(conf.DecisionTraceCapacity, conf.DecisionTraceDumpDir,
+		conf.DecisionTraceMaxDumpFiles))
+	if err := trace.RegisterHandlers(agentCtx.GenericContext); err != nil {
+		return false, nil, fmt.Errorf("failed register sysadvisor decision trace handlers: %s", err)
+	}
+
 	sysadvisorAgent, err := sysadvisor.NewAdvisorAgent(conf, extraConf, agentCtx.MetaServer, agentCtx.EmitterPool)
 	if err != nil {
 		return false, nil, fmt.Errorf("failed init sysadvisor plugin agent: %s", err)
@@ -38,7 +38,7 @@ type AgentStarter struct {
 }
 
 // AgentsDisabledByDefault is the set of controllers which is disabled by default
-var AgentsDisabledByDefault = sets.NewString()
+var AgentsDisabledByDefault = sets.NewString(agent.CgroupReconciler, agent.OOMWatcher)
 
 // agentInitializers is used to store the initializing function for each agent
 var agentInitializers sync.Map
@@ -51,12 +51,16 @@ func init() {
 
 	agentInitializers.Store(agent.ORMAgent, AgentStarter{Init: agent.InitORM})
 	agentInitializers.Store(agent.AuditManager, AgentStarter{Init: agent.InitAuditManager})
+	agentInitializers.Store(agent.DynamicConfigView, AgentStarter{Init: agent.InitDynamicConfigView})
+	agentInitializers.Store(agent.CgroupReconciler, AgentStarter{Init: agent.InitCgroupReconciler})
+	agentInitializers.Store(agent.OOMWatcher, AgentStarter{Init: agent.InitOOMWatcher})
 
 	// qrm plugins are registered at top level of agent
 	agentInitializers.Store(qrm.QRMPluginNameCPU, AgentStarter{Init: qrm.InitQRMCPUPlugins})
 	agentInitializers.Store(qrm.QRMPluginNameMemory, AgentStarter{Init: qrm.InitQRMMemoryPlugins})
 	agentInitializers.Store(qrm.QRMPluginNameNetwork, AgentStarter{Init: qrm.InitQRMNetworkPlugins})
 	agentInitializers.Store(qrm.QRMPluginNameIO, AgentStarter{Init: qrm.InitQRMIOPlugins})
+	agentInitializers.Store(qrm.QRMStateIntrospection, AgentStarter{Init: qrm.InitQRMStateIntrospection})
 }
 
 // RegisterAgentInitializer is used to register user-defined agents
@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package swap
+
+import (
+	"fmt"
+	"strconv"
+
+	cliflag "k8s.io/component-base/cli/flag"
+
+	"github.com/kubewharf/katalyst-api/pkg/consts"
+	swapdynamicconf "github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/swap"
+)
+
+type SwapOptions struct {
+	QoSLevelSwapMaxRatio  map[string]string
+	QoSLevelZswapMaxRatio map[string]string
+}
+
+func NewSwapOptions() *SwapOptions {
+	return &SwapOptions{
+		QoSLevelSwapMaxRatio:  map[string]string{},
+		QoSLevelZswapMaxRatio: map[string]string{},
+	}
+}
+
+func (o *SwapOptions) AddFlags(fss *cliflag.NamedFlagSets) {
+	fs := fss.FlagSet("swap")
+
+	fs.StringToStringVar(&o.QoSLevelSwapMaxRatio, "qos-level-swap-max-ratio", o.QoSLevelSwapMaxRatio,
+		"the ratio of memory limit that containers in each QoS level may swap, "+
+			"e.g. reclaimed_cores=0.2,dedicated_cores=0, and swap of QoS levels not specified is left untouched; "+
+			"budgets of all QoS levels are overridden by the qos-level-swap-configs annotation of AdminQoSConfiguration if set")
+	fs.StringToStringVar(&o.QoSLevelZswapMaxRatio, "qos-level-zswap-max-ratio", o.QoSLevelZswapMaxRatio,
+		"the ratio of memory limit that containers in each QoS level may store in zswap, "+
+			"and it only takes effect for the QoS levels specified in qos-level-swap-max-ratio")
+}
+
+func (o *SwapOptions) ApplyTo(c *swapdynamicconf.SwapConfiguration) error {
+	for qosLevel, value := range o.QoSLevelSwapMaxRatio {
+		ratio, err := parseRatio(value)
+		if err != nil {
+			return fmt.Errorf("invalid swap max ratio for %v: %v", qosLevel, err)
+		}
+		c.QoSLevelSwapConfigs[consts.QoSLevel(qosLevel)] = &swapdynamicconf.SwapConfigDetail{SwapMaxRatio: ratio}
+	}
+
+	for qosLevel, value := range o.QoSLevelZswapMaxRatio {
+		ratio, err := parseRatio(value)
+		if err != nil {
+			return fmt.Errorf("invalid zswap max ratio for %v: %v", qosLevel, err)
+		}
+		detail, ok := c.QoSLevelSwapConfigs[consts.QoSLevel(qosLevel)]
+		if !ok {
+			return fmt.Errorf("zswap max ratio is set for %v without swap max ratio", qosLevel)
+		}
+		detail.ZswapMaxRatio = ratio
+	}
+	return nil
+}
+
+func parseRatio(value string) (float64, error) {
+	ratio, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	} else if ratio < 0 || ratio > 1 {
+		return 0, fmt.Errorf("ratio %v is out of range [0, 1]", ratio)
+	}
+	return ratio, nil
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package global
+
+import (
+	"time"
+
+	cliflag "k8s.io/component-base/cli/flag"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/global"
+)
+
+const defaultCgroupReconcileInterval = time.Minute
+
+type CgroupReconcileOptions struct {
+	CgroupReconcileInterval time.Duration
+}
+
+func NewCgroupReconcileOptions() *CgroupReconcileOptions {
+	return &CgroupReconcileOptions{
+		CgroupReconcileInterval: defaultCgroupReconcileInterval,
+	}
+}
+
+// AddFlags adds flags to the specified FlagSet.
+func (o *CgroupReconcileOptions) AddFlags(fss *cliflag.NamedFlagSets) {
+	fs := fss.FlagSet("cgroup-reconcile")
+	fs.DurationVar(&o.CgroupReconcileInterval, "cgroup-reconcile-interval", o.CgroupReconcileInterval,
+		"the interval to check whether cgroup files drift from the values applied by katalyst, and apply them again if drifted")
+}
+
+// ApplyTo fills up config with options
+func (o *CgroupReconcileOptions) ApplyTo(conf *global.CgroupReconcileConfiguration) error {
+	conf.CgroupReconcileInterval = o.CgroupReconcileInterval
+	return nil
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package global
+
+import (
+	"time"
+
+	cliflag "k8s.io/component-base/cli/flag"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/global"
+)
+
+const defaultOOMWatchInterval = 10 * time.Second
+
+type OOMWatcherOptions struct {
+	OOMWatchInterval time.Duration
+}
+
+func NewOOMWatcherOptions() *OOMWatcherOptions {
+	return &OOMWatcherOptions{
+		OOMWatchInterval: defaultOOMWatchInterval,
+	}
+}
+
+// AddFlags adds flags to the specified FlagSet.
+func (o *OOMWatcherOptions) AddFlags(fss *cliflag.NamedFlagSets) {
+	fs := fss.FlagSet("oom-watcher")
+	fs.DurationVar(&o.OOMWatchInterval, "oom-watch-interval", o.OOMWatchInterval,
+		"the interval to check memory events of containers, and report those hit their memory limits")
+}
+
+// ApplyTo fills up config with options
+func (o *OOMWatcherOptions) ApplyTo(conf *global.OOMWatcherConfiguration) error {
+	conf.OOMWatchInterval = o.OOMWatchInterval
+	return nil
+}
@@ -42,6 +42,8 @@ type Options struct {
 	*global.BaseOptions
 	*global.PluginManagerOptions
 	*global.AuditOptions
+	*global.CgroupReconcileOptions
+	*global.OOMWatcherOptions
 	*metaserver.MetaServerOptions
 	*global.QRMAdvisorOptions
 
@@ -64,13 +66,15 @@ type Options struct {
 // NewOptions creates a new Options with a default config.
 func NewOptions() *Options {
 	return &Options{
-		GenericOptions:       options.NewGenericOptions(),
-		DynamicOptions:       dynamic.NewDynamicOptions(),
-		BaseOptions:          global.NewBaseOptions(),
-		MetaServerOptions:    metaserver.NewMetaServerOptions(),
-		PluginManagerOptions: global.NewPluginManagerOptions(),
-		AuditOptions:         global.NewAuditOptions(),
-		QRMAdvisorOptions:    global.NewQRMAdvisorOptions(),
+		GenericOptions:         options.NewGenericOptions(),
+		DynamicOptions:         dynamic.NewDynamicOptions(),
+		BaseOptions:            global.NewBaseOptions(),
+		MetaServerOptions:      metaserver.NewMetaServerOptions(),
+		PluginManagerOptions:   global.NewPluginManagerOptions(),
+		AuditOptions:           global.NewAuditOptions(),
+		CgroupReconcileOptions: global.NewCgroupReconcileOptions(),
+		OOMWatcherOptions:      global.NewOOMWatcherOptions(),
+		QRMAdvisorOptions:      global.NewQRMAdvisorOptions(),
 
 		genericEvictionOptions:   eviction.NewGenericEvictionOptions(),
 		evictionOptions:          eviction.NewEvictionOptions(),
@@ -91,6 +95,8 @@ func (o *Options) AddFlags(fss *cliflag.NamedFlagSets) {
 	o.MetaServerOptions.AddFlags(fss)
 	o.PluginManagerOptions.AddFlags(fss)
 	o.AuditOptions.AddFlags(fss)
+	o.CgroupReconcileOptions.AddFlags(fss)
+	o.OOMWatcherOptions.AddFlags(fss)
 	o.BaseOptions.AddFlags(fss)
 	o.QRMAdvisorOptions.AddFlags(fss)
 	o.genericEvictionOptions.AddFlags(fss)
@@ -113,6 +119,8 @@ func (o *Options) ApplyTo(c *config.Configuration) error {
 	errList = append(errList, o.BaseOptions.ApplyTo(c.BaseConfiguration))
 	errList = append(errList, o.PluginManagerOptions.ApplyTo(c.PluginManagerConfiguration))
 	errList = append(errList, o.AuditOptions.ApplyTo(c.AuditConfiguration))
+	errList = append(errList, o.CgroupReconcileOptions.ApplyTo(c.CgroupReconcileConfiguration))
+	errList = append(errList, o.OOMWatcherOptions.ApplyTo(c.OOMWatcherConfiguration))
 	errList = append(errList, o.MetaServerOptions.ApplyTo(c.MetaServerConfiguration))
 	errList = append(errList, o.QRMAdvisorOptions.ApplyTo(c.QRMAdvisorConfiguration))
 	errList = append(errList, o.genericEvictionOptions.ApplyTo(c.GenericEvictionConfiguration))
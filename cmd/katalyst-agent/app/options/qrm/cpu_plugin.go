@@ -47,6 +47,7 @@ type CPUDynamicPolicyOptions struct {
 	CPUNUMAHintPreferLowThreshold             float64
 	SharedCoresNUMABindingResultAnnotationKey string
 	EnableMetricPreferredNumaAllocation       bool
+	EnableL3CacheAwareAllocation              bool
 	*hintoptimizer.HintOptimizerOptions
 }
 
@@ -74,6 +75,7 @@ func NewCPUOptions() *CPUOptions {
 			},
 			SharedCoresNUMABindingResultAnnotationKey: consts.PodAnnotationNUMABindResultKey,
 			EnableMetricPreferredNumaAllocation:       false,
+			EnableL3CacheAwareAllocation:              false,
 			HintOptimizerOptions:                      hintoptimizer.NewHintOptimizerOptions(),
 		},
 		CPUNativePolicyOptions: CPUNativePolicyOptions{
@@ -107,6 +109,9 @@ func (o *CPUOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 			"specific cgroup paths and it requires --enable-syncing-cpu-idle=true to make effect")
 	fs.BoolVar(&o.EnableMetricPreferredNumaAllocation, "enable-metric-preferred-numa-allocation", o.EnableMetricPreferredNumaAllocation,
 		"if set true, we will enable metric preferred numa")
+	fs.BoolVar(&o.EnableL3CacheAwareAllocation, "enable-l3-cache-aware-allocation", o.EnableL3CacheAwareAllocation,
+		"if set true, we will allocate cpus in as few L3 caches as possible in each NUMA for dedicated pods and pools, "+
+			"while they are still spread on NUMAs")
 	fs.StringVar(&o.CPUAllocationOption, "cpu-allocation-option",
 		o.CPUAllocationOption, "The allocation option of cpu (packed/distributed). The default value is packed."+
 			"in cases where more than one NUMA node is required to satisfy the allocation.")
@@ -132,6 +137,7 @@ func (o *CPUOptions) ApplyTo(conf *qrmconfig.CPUQRMPluginConfig) error {
 	conf.EnableFullPhysicalCPUsOnly = o.EnableFullPhysicalCPUsOnly
 	conf.CPUAllocationOption = o.CPUAllocationOption
 	conf.EnableMetricPreferredNumaAllocation = o.EnableMetricPreferredNumaAllocation
+	conf.EnableL3CacheAwareAllocation = o.EnableL3CacheAwareAllocation
 	conf.SharedCoresNUMABindingResultAnnotationKey = o.SharedCoresNUMABindingResultAnnotationKey
 	if err := o.HintOptimizerOptions.ApplyTo(conf.HintOptimizerConfiguration); err != nil {
 		return err
@@ -42,6 +42,7 @@ type MemoryOptions struct {
 	SockMemOptions
 	LogCacheOptions
 	FragMemOptions
+	SwapOptions
 	ResctrlOptions
 }
 
@@ -53,6 +54,11 @@ type SockMemOptions struct {
 	SetCgroupTCPMemRatio int
 }
 
+type SwapOptions struct {
+	// EnableSettingSwap is used to limit swap and zswap usage of containers by their QoS levels
+	EnableSettingSwap bool
+}
+
 type LogCacheOptions struct {
 	// EnableEvictingLogCache is used to enable evicting log cache by advise kernel to throw page cache for log files
 	EnableEvictingLogCache bool
@@ -120,6 +126,9 @@ func NewMemoryOptions() *MemoryOptions {
 			EnableSettingFragMem: false,
 			SetMemFragScoreAsync: 80,
 		},
+		SwapOptions: SwapOptions{
+			EnableSettingSwap: false,
+		},
 		ResctrlOptions: ResctrlOptions{
 			CPUSetPoolToSharedSubgroup: make(map[string]int),
 			DefaultSharedSubgroup:      -1,
@@ -179,6 +188,8 @@ func (o *MemoryOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 		o.EnableSettingFragMem, "if set true, we will enable memory compaction related features")
 	fs.IntVar(&o.SetMemFragScoreAsync, "qrm-memory-frag-score-async",
 		o.SetMemFragScoreAsync, "set the threshold of frag score for async memory compaction")
+	fs.BoolVar(&o.EnableSettingSwap, "enable-setting-swap",
+		o.EnableSettingSwap, "if set true, we will limit swap and zswap of containers by the swap budgets of their QoS levels")
 	fs.BoolVar(&o.EnableResctrlHint, "pod-admit-resctrl-layout-hint",
 		o.EnableResctrlHint, "if set true, we will enable resctrl hint on pod admission")
 	fs.StringToIntVar(&o.CPUSetPoolToSharedSubgroup, "resctrl-cpuset-pool-to-shared-subgroup",
@@ -214,6 +225,7 @@ func (o *MemoryOptions) ApplyTo(conf *qrmconfig.MemoryQRMPluginConfig) error {
 	conf.FileFilters = o.FileFilters
 	conf.EnableSettingFragMem = o.EnableSettingFragMem
 	conf.SetMemFragScoreAsync = o.SetMemFragScoreAsync
+	conf.EnableSettingSwap = o.EnableSettingSwap
 	conf.EnableResctrlHint = o.EnableResctrlHint
 	conf.CPUSetPoolToSharedSubgroup = o.CPUSetPoolToSharedSubgroup
 	conf.DefaultSharedSubgroup = o.DefaultSharedSubgroup
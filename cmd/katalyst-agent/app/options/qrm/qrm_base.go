@@ -32,6 +32,7 @@ type GenericQRMPluginOptions struct {
 	PodLabelKeptKeys            []string
 	EnableReclaimNUMABinding    bool
 	EnableSNBHighNumaPreference bool
+	CheckpointMigrationDryRun   bool
 }
 
 func NewGenericQRMPluginOptions() *GenericQRMPluginOptions {
@@ -63,6 +64,9 @@ func (o *GenericQRMPluginOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 		o.EnableReclaimNUMABinding, "if set true, reclaim pod will be allocated on a specific NUMA node best-effort, otherwise, reclaim pod will be allocated on multi NUMA nodes")
 	fs.BoolVar(&o.EnableSNBHighNumaPreference, "enable-snb-high-numa-preference",
 		o.EnableSNBHighNumaPreference, "default false,if set true, snb pod will be preferentially allocated on high numa node")
+	fs.BoolVar(&o.CheckpointMigrationDryRun, "qrm-checkpoint-migration-dry-run",
+		o.CheckpointMigrationDryRun, "if set true, qrm plugins only report the migration of checkpoints written by older versions, "+
+			"and refuse to start if any migration is required")
 }
 
 func (o *GenericQRMPluginOptions) ApplyTo(conf *qrmconfig.GenericQRMPluginConfiguration) error {
@@ -75,6 +79,7 @@ func (o *GenericQRMPluginOptions) ApplyTo(conf *qrmconfig.GenericQRMPluginConfig
 	conf.PodLabelKeptKeys = append(conf.PodLabelKeptKeys, o.PodLabelKeptKeys...)
 	conf.EnableReclaimNUMABinding = o.EnableReclaimNUMABinding
 	conf.EnableSNBHighNumaPreference = o.EnableSNBHighNumaPreference
+	conf.CheckpointMigrationDryRun = o.CheckpointMigrationDryRun
 
 	return nil
 }
@@ -29,6 +29,9 @@ import (
 const (
 	defaultCollectInterval        = 5 * time.Second
 	defaultRefreshLatestCNRPeriod = 5 * time.Minute
+
+	defaultNodeReporterFieldManager = "katalyst-agent-node-reporter"
+	defaultNodeReporterResyncPeriod = 5 * time.Minute
 )
 
 // GenericReporterOptions holds the configurations for reporter
@@ -37,6 +40,9 @@ type GenericReporterOptions struct {
 	InnerPlugins           []string
 	RefreshLatestCNRPeriod time.Duration
 	DefaultCNRLabels       map[string]string
+
+	NodeReporterFieldManager string
+	NodeReporterResyncPeriod time.Duration
 }
 
 // NewGenericReporterOptions creates a new Options with a default config.
@@ -46,6 +52,9 @@ func NewGenericReporterOptions() *GenericReporterOptions {
 		CollectInterval:        defaultCollectInterval,
 		RefreshLatestCNRPeriod: defaultRefreshLatestCNRPeriod,
 		DefaultCNRLabels:       make(map[string]string),
+
+		NodeReporterFieldManager: defaultNodeReporterFieldManager,
+		NodeReporterResyncPeriod: defaultNodeReporterResyncPeriod,
 	}
 }
 
@@ -63,6 +72,10 @@ func (o *GenericReporterOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 		"named 'foo', '-foo' disables the reporter plugin named 'foo'"))
 	fs.StringToStringVar(&o.DefaultCNRLabels, "default-cnr-labels", o.DefaultCNRLabels,
 		"the default labels of cnr created by agent, this config must be consistent with the label-selector in katalyst-controller.")
+	fs.StringVar(&o.NodeReporterFieldManager, "reporter-node-field-manager", o.NodeReporterFieldManager,
+		"the field manager used by node reporter to apply node labels, annotations and extended resources")
+	fs.DurationVar(&o.NodeReporterResyncPeriod, "reporter-node-resync-period", o.NodeReporterResyncPeriod,
+		"the period for node reporter to re-apply unchanged node contents")
 }
 
 // ApplyTo fills up config with options
@@ -71,6 +84,8 @@ func (o *GenericReporterOptions) ApplyTo(c *reporterconfig.GenericReporterConfig
 	c.InnerPlugins = o.InnerPlugins
 	c.RefreshLatestCNRPeriod = o.RefreshLatestCNRPeriod
 	c.DefaultCNRLabels = o.DefaultCNRLabels
+	c.NodeReporterFieldManager = o.NodeReporterFieldManager
+	c.NodeReporterResyncPeriod = o.NodeReporterResyncPeriod
 	return nil
 }
 
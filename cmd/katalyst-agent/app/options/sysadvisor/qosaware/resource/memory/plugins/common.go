@@ -27,6 +27,7 @@ type MemoryAdvisorPluginsOptions struct {
 	*CacheReaperOptions
 	*MemoryProvisionerOptions
 	*NumaBalancerOptions
+	*OOMProtectorOptions
 }
 
 func NewMemoryAdvisorPluginsOptions() *MemoryAdvisorPluginsOptions {
@@ -34,6 +35,7 @@ func NewMemoryAdvisorPluginsOptions() *MemoryAdvisorPluginsOptions {
 		CacheReaperOptions:       NewCacheReaperOptions(),
 		MemoryProvisionerOptions: NewMemoryProvisionerOptions(),
 		NumaBalancerOptions:      NewNumaBalancerOptions(),
+		OOMProtectorOptions:      NewOOMProtectorOptions(),
 	}
 }
 
@@ -41,6 +43,7 @@ func (o *MemoryAdvisorPluginsOptions) AddFlags(fs *pflag.FlagSet) {
 	o.CacheReaperOptions.AddFlags(fs)
 	o.MemoryProvisionerOptions.AddFlags(fs)
 	o.NumaBalancerOptions.AddFlags(fs)
+	o.OOMProtectorOptions.AddFlags(fs)
 }
 
 func (o *MemoryAdvisorPluginsOptions) ApplyTo(c *plugins.MemoryAdvisorPluginsConfiguration) error {
@@ -48,5 +51,6 @@ func (o *MemoryAdvisorPluginsOptions) ApplyTo(c *plugins.MemoryAdvisorPluginsCon
 	errList = append(errList, o.CacheReaperOptions.ApplyTo(c.CacheReaperConfiguration))
 	errList = append(errList, o.MemoryProvisionerOptions.ApplyTo(c.MemoryProvisionerConfiguration))
 	errList = append(errList, o.NumaBalancerOptions.ApplyTo(c.NumaBalancerConfiguration))
+	errList = append(errList, o.OOMProtectorOptions.ApplyTo(c.OOMProtectorConfiguration))
 	return errors.NewAggregate(errList)
 }
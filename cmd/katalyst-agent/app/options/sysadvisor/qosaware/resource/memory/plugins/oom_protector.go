@@ -0,0 +1,54 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package plugins
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/memory/plugins"
+)
+
+type OOMProtectorOptions struct {
+	OOMProtectionThreshold int
+	OOMProtectionWindow    time.Duration
+	OOMProtectionDuration  time.Duration
+}
+
+func NewOOMProtectorOptions() *OOMProtectorOptions {
+	return &OOMProtectorOptions{
+		OOMProtectionThreshold: 3,
+		OOMProtectionWindow:    10 * time.Minute,
+		OOMProtectionDuration:  30 * time.Minute,
+	}
+}
+
+func (o *OOMProtectorOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&o.OOMProtectionThreshold, "memory-advisor-oom-protection-threshold", o.OOMProtectionThreshold,
+		"the number of OOMs within the window that triggers memory protection for a non-reclaimed container")
+	fs.DurationVar(&o.OOMProtectionWindow, "memory-advisor-oom-protection-window", o.OOMProtectionWindow,
+		"the sliding window in which OOMs of a container are counted by oom-protector")
+	fs.DurationVar(&o.OOMProtectionDuration, "memory-advisor-oom-protection-duration", o.OOMProtectionDuration,
+		"how long memory protection lasts once it's triggered by oom-protector")
+}
+
+func (o *OOMProtectorOptions) ApplyTo(c *plugins.OOMProtectorConfiguration) error {
+	c.OOMProtectionThreshold = o.OOMProtectionThreshold
+	c.OOMProtectionWindow = o.OOMProtectionWindow
+	c.OOMProtectionDuration = o.OOMProtectionDuration
+	return nil
+}
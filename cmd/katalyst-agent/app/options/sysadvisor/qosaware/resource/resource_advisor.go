@@ -27,7 +27,10 @@ import (
 
 // ResourceAdvisorOptions holds the configurations for resource advisors in qos aware plugin
 type ResourceAdvisorOptions struct {
-	ResourceAdvisors []string
+	ResourceAdvisors          []string
+	DecisionTraceCapacity     int
+	DecisionTraceDumpDir      string
+	DecisionTraceMaxDumpFiles int
 
 	*cpu.CPUAdvisorOptions
 	*memory.MemoryAdvisorOptions
@@ -36,15 +39,23 @@ type ResourceAdvisorOptions struct {
 // NewResourceAdvisorOptions creates a new Options with a default config
 func NewResourceAdvisorOptions() *ResourceAdvisorOptions {
 	return &ResourceAdvisorOptions{
-		ResourceAdvisors:     []string{"cpu", "memory"},
-		CPUAdvisorOptions:    cpu.NewCPUAdvisorOptions(),
-		MemoryAdvisorOptions: memory.NewMemoryAdvisorOptions(),
+		ResourceAdvisors:          []string{"cpu", "memory"},
+		DecisionTraceCapacity:     120,
+		DecisionTraceMaxDumpFiles: 10,
+		CPUAdvisorOptions:         cpu.NewCPUAdvisorOptions(),
+		MemoryAdvisorOptions:      memory.NewMemoryAdvisorOptions(),
 	}
 }
 
 // AddFlags adds flags to the specified FlagSet.
 func (o *ResourceAdvisorOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.StringSliceVar(&o.ResourceAdvisors, "resource-advisors", o.ResourceAdvisors, "active dimensions for resource advisors")
+	fs.IntVar(&o.DecisionTraceCapacity, "resource-advisor-decision-trace-capacity", o.DecisionTraceCapacity,
+		"the number of latest decision traces kept in memory, shared by all resource advisors")
+	fs.StringVar(&o.DecisionTraceDumpDir, "resource-advisor-decision-trace-dump-dir", o.DecisionTraceDumpDir,
+		"the directory to dump decision traces into on request, and dumping is disabled if it's empty")
+	fs.IntVar(&o.DecisionTraceMaxDumpFiles, "resource-advisor-decision-trace-max-dump-files", o.DecisionTraceMaxDumpFiles,
+		"the max number of decision trace dump files kept in dump dir, and the oldest ones are removed beyond it")
 
 	o.CPUAdvisorOptions.AddFlags(fs)
 	o.MemoryAdvisorOptions.AddFlags(fs)
@@ -53,6 +64,9 @@ func (o *ResourceAdvisorOptions) AddFlags(fs *pflag.FlagSet) {
 // ApplyTo fills up config with options
 func (o *ResourceAdvisorOptions) ApplyTo(c *resource.ResourceAdvisorConfiguration) error {
 	c.ResourceAdvisors = o.ResourceAdvisors
+	c.DecisionTraceCapacity = o.DecisionTraceCapacity
+	c.DecisionTraceDumpDir = o.DecisionTraceDumpDir
+	c.DecisionTraceMaxDumpFiles = o.DecisionTraceMaxDumpFiles
 
 	var errList []error
 	errList = append(errList, o.CPUAdvisorOptions.ApplyTo(c.CPUAdvisorConfiguration))
@@ -73,6 +73,24 @@ func StartKCCController(ctx context.Context, controlCtx *katalystbase.GenericCon
 		return false, err
 	}
 
+	if conf.ControllersConfiguration.KCCConfig.EnableRolloutGuard {
+		rolloutGuard, err := kcc.NewRolloutGuard(
+			ctx,
+			conf.ControllersConfiguration.KCCConfig.RolloutGuardConfig,
+			conf.ControllersConfiguration.LifeCycleConfig,
+			controlCtx.KubeInformerFactory.Core().V1().Nodes(),
+			controlCtx.KubeInformerFactory.Core().V1().Pods(),
+			controlCtx.KubeInformerFactory.Core().V1().Events(),
+			controlCtx.InternalInformerFactory.Node().V1alpha1().CustomNodeResources(),
+			controlCtx.EmitterPool.GetDefaultMetricsEmitter(),
+		)
+		if err != nil {
+			klog.Errorf("failed to new kcc rollout guard")
+			return false, err
+		}
+		kccTargetController.SetRolloutGuard(rolloutGuard)
+	}
+
 	go targetHandler.Run()
 	go kccController.Run()
 	go kccTargetController.Run()
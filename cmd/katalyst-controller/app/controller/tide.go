@@ -39,6 +39,7 @@ func StartTideController(ctx context.Context, controlCtx *katalystbase.GenericCo
 		controlCtx,
 		conf.GenericConfiguration,
 		conf.GenericControllerConfiguration,
+		conf.TideConfig,
 	)
 	if err != nil {
 		klog.Errorf("failed to new kcc controller")
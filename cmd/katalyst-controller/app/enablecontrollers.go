@@ -41,7 +41,7 @@ type ControllerStarter struct {
 }
 
 // ControllersDisabledByDefault is the set of controllers which is disabled by default
-var ControllersDisabledByDefault = sets.NewString()
+var ControllersDisabledByDefault = sets.NewString(controller.ReschedulingControllerName)
 
 // ControllerInitializers is used to store the initializing function for each controller
 var controllerInitializers sync.Map
@@ -57,6 +57,7 @@ func init() {
 	controllerInitializers.Store(controller.OvercommitControllerName, ControllerStarter{Starter: controller.StartOvercommitController})
 	controllerInitializers.Store(controller.TideControllerName, ControllerStarter{Starter: controller.StartTideController})
 	controllerInitializers.Store(controller.ResourceRecommenderControllerName, ControllerStarter{Starter: controller.StartResourceRecommenderController})
+	controllerInitializers.Store(controller.ReschedulingControllerName, ControllerStarter{Starter: controller.StartReschedulingController})
 }
 
 // RegisterControllerInitializer is used to register user-defined controllers
@@ -32,7 +32,9 @@ type ControllersOptions struct {
 	*LifeCycleOptions
 	*MonitorOptions
 	*OvercommitOptions
+	*TideOptions
 	*ResourceRecommenderOptions
+	*ReschedulingOptions
 }
 
 func NewControllersOptions() *ControllersOptions {
@@ -45,7 +47,9 @@ func NewControllersOptions() *ControllersOptions {
 		LifeCycleOptions:           NewLifeCycleOptions(),
 		MonitorOptions:             NewMonitorOptions(),
 		OvercommitOptions:          NewOvercommitOptions(),
+		TideOptions:                NewTideOptions(),
 		ResourceRecommenderOptions: NewResourceRecommenderOptions(),
+		ReschedulingOptions:        NewReschedulingOptions(),
 	}
 }
 
@@ -58,7 +62,9 @@ func (o *ControllersOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 	o.LifeCycleOptions.AddFlags(fss)
 	o.MonitorOptions.AddFlags(fss)
 	o.OvercommitOptions.AddFlags(fss)
+	o.TideOptions.AddFlags(fss)
 	o.ResourceRecommenderOptions.AddFlags(fss)
+	o.ReschedulingOptions.AddFlags(fss)
 }
 
 // ApplyTo fills up config with options
@@ -73,7 +79,9 @@ func (o *ControllersOptions) ApplyTo(c *controllerconfig.ControllersConfiguratio
 	errList = append(errList, o.LifeCycleOptions.ApplyTo(c.LifeCycleConfig))
 	errList = append(errList, o.MonitorOptions.ApplyTo(c.MonitorConfig))
 	errList = append(errList, o.OvercommitOptions.ApplyTo(c.OvercommitConfig))
+	errList = append(errList, o.TideOptions.ApplyTo(c.TideConfig))
 	errList = append(errList, o.ResourceRecommenderOptions.ApplyTo(c.ResourceRecommenderConfig))
+	errList = append(errList, o.ReschedulingOptions.ApplyTo(c.ReschedulingConfig))
 	return errors.NewAggregate(errList)
 }
 
@@ -28,12 +28,20 @@ import (
 type KCCOptions struct {
 	ValidAPIGroupSet []string
 	DefaultGVRs      []string
+
+	EnableRolloutGuard              bool
+	RolloutGuardRegressionThreshold float64
+	RolloutGuardMinUnhealthyNodes   int
+	RolloutGuardEvictionThreshold   int
 }
 
 // NewKCCOptions creates a new Options with a default config.
 func NewKCCOptions() *KCCOptions {
 	return &KCCOptions{
-		ValidAPIGroupSet: []string{v1alpha1.SchemeGroupVersion.Group},
+		ValidAPIGroupSet:                []string{v1alpha1.SchemeGroupVersion.Group},
+		RolloutGuardRegressionThreshold: 0.2,
+		RolloutGuardMinUnhealthyNodes:   1,
+		RolloutGuardEvictionThreshold:   3,
 	}
 }
 
@@ -43,17 +51,34 @@ func (o *KCCOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 
 	fs.StringSliceVar(&o.ValidAPIGroupSet, "kcc-valid-api-group-set", o.ValidAPIGroupSet, "which Groups is allowed")
 	fs.StringSliceVar(&o.DefaultGVRs, "kcc-default-gvrs", o.DefaultGVRs, "which need to watch by default")
+
+	fs.BoolVar(&o.EnableRolloutGuard, "kcc-rollout-guard-enabled", o.EnableRolloutGuard,
+		"whether to pause and revert kcc config rollouts when canary nodes regress in agent health")
+	fs.Float64Var(&o.RolloutGuardRegressionThreshold, "kcc-rollout-guard-regression-threshold", o.RolloutGuardRegressionThreshold,
+		"the max tolerated difference between unhealthy ratio of canary nodes and that of the other target nodes")
+	fs.IntVar(&o.RolloutGuardMinUnhealthyNodes, "kcc-rollout-guard-min-unhealthy-nodes", o.RolloutGuardMinUnhealthyNodes,
+		"the min number of unhealthy canary nodes to regard a rollout as regressed")
+	fs.IntVar(&o.RolloutGuardEvictionThreshold, "kcc-rollout-guard-eviction-threshold", o.RolloutGuardEvictionThreshold,
+		"the number of evictions on a node since it received the new config to regard it as unhealthy")
 }
 
 // ApplyTo fills up config with options
 func (o *KCCOptions) ApplyTo(c *controller.KCCConfig) error {
 	c.ValidAPIGroupSet = sets.NewString(o.ValidAPIGroupSet...)
 	c.DefaultGVRs = o.DefaultGVRs
+
+	if c.RolloutGuardConfig == nil {
+		c.RolloutGuardConfig = &controller.RolloutGuardConfig{}
+	}
+	c.EnableRolloutGuard = o.EnableRolloutGuard
+	c.RegressionThreshold = o.RolloutGuardRegressionThreshold
+	c.MinUnhealthyNodes = o.RolloutGuardMinUnhealthyNodes
+	c.EvictionThreshold = o.RolloutGuardEvictionThreshold
 	return nil
 }
 
 func (o *KCCOptions) Config() (*controller.KCCConfig, error) {
-	c := &controller.KCCConfig{}
+	c := controller.NewKCCConfig()
 	if err := o.ApplyTo(c); err != nil {
 		return nil, err
 	}
@@ -17,12 +17,14 @@ limitations under the License.
 package options
 
 import (
+	"fmt"
 	"time"
 
 	"k8s.io/apimachinery/pkg/labels"
 	cliflag "k8s.io/component-base/cli/flag"
 
 	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
 )
 
 type HealthzOptions struct {
@@ -37,6 +39,8 @@ type HealthzOptions struct {
 	HandlePeriod  time.Duration
 	AgentHandlers map[string]string
 
+	ComponentRemediations map[string]string
+
 	TaintQPS                 float32
 	EvictQPS                 float32
 	DisruptionTaintThreshold float32
@@ -66,6 +70,13 @@ func NewLifeCycleOptions() *LifeCycleOptions {
 
 			HandlePeriod:  5 * time.Minute,
 			AgentHandlers: map[string]string{},
+			ComponentRemediations: map[string]string{
+				consts.AgentComponentQRMCPU:          string(controller.ComponentRemediationNoScheduleDedicated),
+				consts.AgentComponentQRMMemory:       string(controller.ComponentRemediationNoScheduleDedicated),
+				consts.AgentComponentSysAdvisor:      string(controller.ComponentRemediationTaintReclaimed),
+				consts.AgentComponentEvictionManager: string(controller.ComponentRemediationEvictReclaimed),
+				consts.AgentComponentReporter:        string(controller.ComponentRemediationTaintReclaimed),
+			},
 
 			TaintQPS:                 0.2,
 			EvictQPS:                 0.1,
@@ -102,6 +113,12 @@ func (o *LifeCycleOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 		"the interval to trigger performs")
 	fs.StringToStringVar(&o.AgentHandlers, "healthz-agent-handles", o.AgentHandlers,
 		"the handler-name to handle each agent, each agent many have a corresponding handler")
+	fs.StringToStringVar(&o.ComponentRemediations, "healthz-component-remediations", o.ComponentRemediations,
+		fmt.Sprintf("the remediation to perform when each agent component is degraded, supported remediations: %v",
+			[]controller.ComponentRemediation{
+				controller.ComponentRemediationNone, controller.ComponentRemediationTaintReclaimed,
+				controller.ComponentRemediationNoScheduleDedicated, controller.ComponentRemediationEvictReclaimed,
+			}))
 
 	fs.Float32Var(&o.TaintQPS, "healthz-taint-qps", o.TaintQPS,
 		"the qps to perform tainting")
@@ -144,6 +161,16 @@ func (o *LifeCycleOptions) ApplyTo(c *controller.LifeCycleConfig) error {
 
 	c.HandlePeriod = o.HandlePeriod
 	c.AgentHandlers = o.AgentHandlers
+	c.ComponentRemediations = make(map[string]controller.ComponentRemediation)
+	for component, remediation := range o.ComponentRemediations {
+		switch r := controller.ComponentRemediation(remediation); r {
+		case controller.ComponentRemediationNone, controller.ComponentRemediationTaintReclaimed,
+			controller.ComponentRemediationNoScheduleDedicated, controller.ComponentRemediationEvictReclaimed:
+			c.ComponentRemediations[component] = r
+		default:
+			return fmt.Errorf("unsupported remediation %s for component %s", remediation, component)
+		}
+	}
 
 	c.TaintQPS = o.TaintQPS
 	c.EvictQPS = o.EvictQPS
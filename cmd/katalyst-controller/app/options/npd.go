@@ -17,15 +17,31 @@ limitations under the License.
 package options
 
 import (
+	"time"
+
 	cliflag "k8s.io/component-base/cli/flag"
 
 	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	"github.com/kubewharf/katalyst-core/pkg/util/datasource/prometheus"
 )
 
 type NPDOptions struct {
 	NPDMetricsPlugins     []string
 	EnableScopeDuplicated bool
 	SyncWorkers           int
+
+	*UsageMetricsPluginOptions
+}
+
+type UsageMetricsPluginOptions struct {
+	DataSourcePromConfig prometheus.PromConfig
+
+	SyncPeriod         time.Duration
+	Windows            []time.Duration
+	WindowRefreshRatio float64
+	Step               time.Duration
+	EnablePodMetrics   bool
+	NodeLabel          string
 }
 
 func NewNPDOptions() *NPDOptions {
@@ -33,6 +49,17 @@ func NewNPDOptions() *NPDOptions {
 		NPDMetricsPlugins:     []string{},
 		EnableScopeDuplicated: false,
 		SyncWorkers:           1,
+		UsageMetricsPluginOptions: &UsageMetricsPluginOptions{
+			DataSourcePromConfig: prometheus.PromConfig{
+				KeepAlive: 60 * time.Second,
+				Timeout:   time.Minute,
+			},
+			SyncPeriod:         time.Minute,
+			Windows:            []time.Duration{5 * time.Minute, time.Hour, 24 * time.Hour},
+			WindowRefreshRatio: 0.1,
+			Step:               time.Minute,
+			NodeLabel:          "node",
+		},
 	}
 }
 
@@ -45,12 +72,52 @@ func (o *NPDOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 		"Whether metrics with the same scope can be updated by multiple plugins")
 	fs.IntVar(&o.SyncWorkers, "npd-sync-workers", o.SyncWorkers,
 		"Number of workers to sync npd status")
+
+	fs.StringVar(&o.DataSourcePromConfig.Address, "npd-usage-prometheus-address", o.DataSourcePromConfig.Address,
+		"prometheus address used by usage metrics plugin")
+	fs.StringVar(&o.DataSourcePromConfig.Auth.Type, "npd-usage-prometheus-auth-type", o.DataSourcePromConfig.Auth.Type,
+		"prometheus auth type used by usage metrics plugin")
+	fs.StringVar(&o.DataSourcePromConfig.Auth.Username, "npd-usage-prometheus-auth-username", o.DataSourcePromConfig.Auth.Username,
+		"prometheus auth username used by usage metrics plugin")
+	fs.StringVar(&o.DataSourcePromConfig.Auth.Password, "npd-usage-prometheus-auth-password", o.DataSourcePromConfig.Auth.Password,
+		"prometheus auth password used by usage metrics plugin")
+	fs.StringVar(&o.DataSourcePromConfig.Auth.BearerToken, "npd-usage-prometheus-auth-bearertoken", o.DataSourcePromConfig.Auth.BearerToken,
+		"prometheus auth bearertoken used by usage metrics plugin")
+	fs.DurationVar(&o.DataSourcePromConfig.KeepAlive, "npd-usage-prometheus-keepalive", o.DataSourcePromConfig.KeepAlive,
+		"prometheus keep alive used by usage metrics plugin")
+	fs.DurationVar(&o.DataSourcePromConfig.Timeout, "npd-usage-prometheus-timeout", o.DataSourcePromConfig.Timeout,
+		"prometheus timeout used by usage metrics plugin")
+	fs.StringVar(&o.DataSourcePromConfig.BaseFilter, "npd-usage-prometheus-promql-base-filter", o.DataSourcePromConfig.BaseFilter,
+		"basic filters added to all promql statements of usage metrics plugin, e.g: cluster=\\\"test\\\"")
+	fs.DurationVar(&o.SyncPeriod, "npd-usage-sync-period", o.SyncPeriod,
+		"the interval for usage metrics plugin to update usage metrics")
+	fs.DurationSliceVar(&o.Windows, "npd-usage-windows", o.Windows,
+		"the time windows that usage metrics are aggregated over")
+	fs.Float64Var(&o.WindowRefreshRatio, "npd-usage-window-refresh-ratio", o.WindowRefreshRatio,
+		"each window is refreshed every max(sync period, window * ratio) to limit the query load of long windows, "+
+			"so consumers should expire metrics of each window in proportion to it")
+	fs.DurationVar(&o.Step, "npd-usage-step", o.Step,
+		"the resolution of samples used to aggregate usage metrics")
+	fs.BoolVar(&o.EnablePodMetrics, "npd-usage-pod-metrics-enabled", o.EnablePodMetrics,
+		"whether to query the usage of all pods besides nodes, which is expensive for prometheus in large clusters")
+	fs.StringVar(&o.NodeLabel, "npd-usage-node-label", o.NodeLabel,
+		"the prometheus label which identifies the node of usage samples")
 }
 
 func (o *NPDOptions) ApplyTo(c *controller.NPDConfig) error {
 	c.NPDMetricsPlugins = o.NPDMetricsPlugins
 	c.EnableScopeDuplicated = o.EnableScopeDuplicated
 	c.SyncWorkers = o.SyncWorkers
+
+	c.UsageMetricsPluginConfig = &controller.UsageMetricsPluginConfig{
+		DataSourcePromConfig: o.DataSourcePromConfig,
+		SyncPeriod:           o.SyncPeriod,
+		Windows:              o.Windows,
+		WindowRefreshRatio:   o.WindowRefreshRatio,
+		Step:                 o.Step,
+		EnablePodMetrics:     o.EnablePodMetrics,
+		NodeLabel:            o.NodeLabel,
+	}
 	return nil
 }
 
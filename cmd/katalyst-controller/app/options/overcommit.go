@@ -27,6 +27,14 @@ import (
 const (
 	defaultNodeOvercommitSyncWorkers     = 1
 	defaultNodeOvercommitReconcilePeriod = 30 * time.Minute
+
+	defaultOvercommitRecommendationSyncPeriod        = 10 * time.Minute
+	defaultOvercommitRecommendationPercentile        = 10
+	defaultOvercommitRecommendationHistoryWindow     = 24 * time.Hour
+	defaultOvercommitRecommendationMinNodes          = 3
+	defaultOvercommitRecommendationAutoApplyMaxStep  = 0.1
+	defaultOvercommitRecommendationAutoApplyInterval = time.Hour
+	defaultOvercommitRecommendationAutoApplyMaxRatio = 3
 )
 
 // OvercommitOptions holds the configurations for overcommit.
@@ -41,6 +49,15 @@ type NodeOvercommitOptions struct {
 
 	// time interval of reconcile overcommit config
 	ConfigReconcilePeriod time.Duration
+
+	RecommendationEnabled           bool
+	RecommendationSyncPeriod        time.Duration
+	RecommendationPercentile        float64
+	RecommendationHistoryWindow     time.Duration
+	RecommendationMinNodes          int
+	RecommendationAutoApplyMaxStep  float64
+	RecommendationAutoApplyInterval time.Duration
+	RecommendationAutoApplyMaxRatio float64
 }
 
 // NewOvercommitOptions creates a new Options with a default config.
@@ -54,11 +71,37 @@ func (o *OvercommitOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 
 	fs.IntVar(&o.SyncWorkers, "nodeovercommit-sync-workers", defaultNodeOvercommitSyncWorkers, "num of goroutines to sync nodeovercommitconfig")
 	fs.DurationVar(&o.ConfigReconcilePeriod, "nodeovercommit-reconcile-period", defaultNodeOvercommitReconcilePeriod, "Period for nodeovercommit controller to sync configs")
+	fs.BoolVar(&o.RecommendationEnabled, "nodeovercommit-recommendation-enabled", false,
+		"whether to recommend overcommit ratio of nodeovercommitconfig by realtime overcommit ratios of matched nodes")
+	fs.DurationVar(&o.RecommendationSyncPeriod, "nodeovercommit-recommendation-sync-period", defaultOvercommitRecommendationSyncPeriod,
+		"Period to sample realtime overcommit ratios and update recommendations")
+	fs.Float64Var(&o.RecommendationPercentile, "nodeovercommit-recommendation-percentile", defaultOvercommitRecommendationPercentile,
+		"percentile of realtime overcommit ratios of matched nodes taken in each sample")
+	fs.DurationVar(&o.RecommendationHistoryWindow, "nodeovercommit-recommendation-history-window", defaultOvercommitRecommendationHistoryWindow,
+		"the recommended overcommit ratio is the min sample in the history window")
+	fs.IntVar(&o.RecommendationMinNodes, "nodeovercommit-recommendation-min-nodes", defaultOvercommitRecommendationMinNodes,
+		"min number of matched nodes with realtime overcommit ratios to take a sample")
+	fs.Float64Var(&o.RecommendationAutoApplyMaxStep, "nodeovercommit-recommendation-auto-apply-max-step", defaultOvercommitRecommendationAutoApplyMaxStep,
+		"max increase of overcommit ratio in each auto-apply, decreases are applied at once")
+	fs.DurationVar(&o.RecommendationAutoApplyInterval, "nodeovercommit-recommendation-auto-apply-interval", defaultOvercommitRecommendationAutoApplyInterval,
+		"min time interval between two increases of overcommit ratio in auto-apply")
+	fs.Float64Var(&o.RecommendationAutoApplyMaxRatio, "nodeovercommit-recommendation-auto-apply-max-ratio", defaultOvercommitRecommendationAutoApplyMaxRatio,
+		"max overcommit ratio that can be auto-applied")
 }
 
 func (o *OvercommitOptions) ApplyTo(c *controller.OvercommitConfig) error {
 	c.Node.SyncWorkers = o.SyncWorkers
 	c.Node.ConfigReconcilePeriod = o.ConfigReconcilePeriod
+	c.Node.Recommendation = controller.OvercommitRecommendationConfig{
+		Enabled:           o.RecommendationEnabled,
+		SyncPeriod:        o.RecommendationSyncPeriod,
+		Percentile:        o.RecommendationPercentile,
+		HistoryWindow:     o.RecommendationHistoryWindow,
+		MinNodes:          o.RecommendationMinNodes,
+		AutoApplyMaxStep:  o.RecommendationAutoApplyMaxStep,
+		AutoApplyInterval: o.RecommendationAutoApplyInterval,
+		AutoApplyMaxRatio: o.RecommendationAutoApplyMaxRatio,
+	}
 	return nil
 }
 
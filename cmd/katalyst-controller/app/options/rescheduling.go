@@ -0,0 +1,172 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	cliflag "k8s.io/component-base/cli/flag"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+)
+
+const (
+	defaultReschedulingSyncPeriod               = 5 * time.Minute
+	defaultReschedulingChronicDuration          = 30 * time.Minute
+	defaultReschedulingHotCPUUsageThreshold     = 0.85
+	defaultReschedulingHotMemoryUsageThreshold  = 0.9
+	defaultReschedulingNPDMetricWindow          = 5 * time.Minute
+	defaultReschedulingNPDMetricAggregator      = "avg"
+	defaultReschedulingNPDMetricExpiration      = 10 * time.Minute
+	defaultReschedulingNPDMetricExpirationRatio = 0.2
+	defaultReschedulingFragmentationThreshold   = 0.5
+	defaultReschedulingFragmentationMinFreeCPU  = 4
+	defaultReschedulingMaxEvictionsPerCycle     = 5
+	defaultReschedulingMaxEvictionsPerNode      = 1
+)
+
+// ReschedulingOptions holds the configurations for rescheduling controller.
+type ReschedulingOptions struct {
+	SyncPeriod      time.Duration
+	DryRun          bool
+	ChronicDuration time.Duration
+
+	HotCPUUsageThreshold    float64
+	HotMemoryUsageThreshold float64
+
+	NPDMetricScope      string
+	NPDMetricThresholds map[string]string
+
+	NPDMetricWindow                time.Duration
+	NPDMetricAggregator            string
+	NPDMetricExpiration            time.Duration
+	NPDMetricExpirationWindowRatio float64
+
+	FragmentationThreshold  float64
+	FragmentationMinFreeCPU float64
+
+	MaxEvictionsPerCycle int
+	MaxEvictionsPerNode  int
+
+	EvictDedicatedCores bool
+	SkippedNamespaces   []string
+}
+
+// NewReschedulingOptions creates a new Options with a default config.
+func NewReschedulingOptions() *ReschedulingOptions {
+	return &ReschedulingOptions{
+		SyncPeriod:                     defaultReschedulingSyncPeriod,
+		ChronicDuration:                defaultReschedulingChronicDuration,
+		HotCPUUsageThreshold:           defaultReschedulingHotCPUUsageThreshold,
+		HotMemoryUsageThreshold:        defaultReschedulingHotMemoryUsageThreshold,
+		NPDMetricThresholds:            map[string]string{},
+		NPDMetricWindow:                defaultReschedulingNPDMetricWindow,
+		NPDMetricAggregator:            defaultReschedulingNPDMetricAggregator,
+		NPDMetricExpiration:            defaultReschedulingNPDMetricExpiration,
+		NPDMetricExpirationWindowRatio: defaultReschedulingNPDMetricExpirationRatio,
+		FragmentationThreshold:         defaultReschedulingFragmentationThreshold,
+		FragmentationMinFreeCPU:        defaultReschedulingFragmentationMinFreeCPU,
+		MaxEvictionsPerCycle:           defaultReschedulingMaxEvictionsPerCycle,
+		MaxEvictionsPerNode:            defaultReschedulingMaxEvictionsPerNode,
+		SkippedNamespaces:              []string{"kube-system"},
+	}
+}
+
+// AddFlags adds flags to the specified FlagSet
+func (o *ReschedulingOptions) AddFlags(fss *cliflag.NamedFlagSets) {
+	fs := fss.FlagSet("rescheduling")
+
+	fs.DurationVar(&o.SyncPeriod, "rescheduling-sync-period", o.SyncPeriod,
+		"the interval to evaluate nodes and pick pods to evict")
+	fs.BoolVar(&o.DryRun, "rescheduling-dry-run", o.DryRun,
+		"if set, decisions are only reported as events without evicting any pod")
+	fs.DurationVar(&o.ChronicDuration, "rescheduling-chronic-duration", o.ChronicDuration,
+		"how long a node must keep unhealthy or imbalanced before pods are moved off from it")
+	fs.Float64Var(&o.HotCPUUsageThreshold, "rescheduling-hot-cpu-usage-threshold", o.HotCPUUsageThreshold,
+		"cpu usage ratio in cnr above which the node is considered hot")
+	fs.Float64Var(&o.HotMemoryUsageThreshold, "rescheduling-hot-memory-usage-threshold", o.HotMemoryUsageThreshold,
+		"memory usage ratio in cnr above which the node is considered hot")
+	fs.StringVar(&o.NPDMetricScope, "rescheduling-npd-metric-scope", o.NPDMetricScope,
+		"the scope of node metrics in npd to consider")
+	fs.StringToStringVar(&o.NPDMetricThresholds, "rescheduling-npd-metric-thresholds", o.NPDMetricThresholds,
+		"node metrics in npd and their thresholds above which the node is considered hot, e.g. load_1min=0.8")
+	fs.DurationVar(&o.NPDMetricWindow, "rescheduling-npd-metric-window", o.NPDMetricWindow,
+		"the window of node metrics in npd to compare with thresholds, 0 only matches metrics without window")
+	fs.StringVar(&o.NPDMetricAggregator, "rescheduling-npd-metric-aggregator", o.NPDMetricAggregator,
+		"the aggregator of node metrics in npd to compare with thresholds, empty only matches metrics without aggregator")
+	fs.DurationVar(&o.NPDMetricExpiration, "rescheduling-npd-metric-expiration", o.NPDMetricExpiration,
+		"the min age of node metrics in npd after which they are considered out of date")
+	fs.Float64Var(&o.NPDMetricExpirationWindowRatio, "rescheduling-npd-metric-expiration-window-ratio", o.NPDMetricExpirationWindowRatio,
+		"node metrics in npd are also trusted until they are older than their window multiplied by this ratio")
+	fs.Float64Var(&o.FragmentationThreshold, "rescheduling-fragmentation-threshold", o.FragmentationThreshold,
+		"ratio of free cpus outside the most free numa node above which the node is considered fragmented")
+	fs.Float64Var(&o.FragmentationMinFreeCPU, "rescheduling-fragmentation-min-free-cpu", o.FragmentationMinFreeCPU,
+		"nodes with less free cpus are never considered fragmented")
+	fs.IntVar(&o.MaxEvictionsPerCycle, "rescheduling-max-evictions-per-cycle", o.MaxEvictionsPerCycle,
+		"max number of pods evicted in each sync period")
+	fs.IntVar(&o.MaxEvictionsPerNode, "rescheduling-max-evictions-per-node", o.MaxEvictionsPerNode,
+		"max number of pods evicted from each node in each sync period")
+	fs.BoolVar(&o.EvictDedicatedCores, "rescheduling-evict-dedicated-cores", o.EvictDedicatedCores,
+		"whether pods with dedicated_cores qos level can be evicted")
+	fs.StringSliceVar(&o.SkippedNamespaces, "rescheduling-skipped-namespaces", o.SkippedNamespaces,
+		"namespaces whose pods are never evicted")
+}
+
+// ApplyTo fills up config with options
+func (o *ReschedulingOptions) ApplyTo(c *controller.ReschedulingConfig) error {
+	c.SyncPeriod = o.SyncPeriod
+	c.DryRun = o.DryRun
+	c.ChronicDuration = o.ChronicDuration
+	c.HotCPUUsageThreshold = o.HotCPUUsageThreshold
+	c.HotMemoryUsageThreshold = o.HotMemoryUsageThreshold
+	c.NPDMetricScope = o.NPDMetricScope
+
+	c.NPDMetricThresholds = make(map[string]float64, len(o.NPDMetricThresholds))
+	for metricName, thresholdStr := range o.NPDMetricThresholds {
+		threshold, err := strconv.ParseFloat(thresholdStr, 64)
+		if err != nil {
+			return fmt.Errorf("invalid threshold %v for npd metric %v: %v", thresholdStr, metricName, err)
+		}
+		c.NPDMetricThresholds[metricName] = threshold
+	}
+
+	if o.NPDMetricExpirationWindowRatio < 0 {
+		return fmt.Errorf("invalid npd metric expiration window ratio %v", o.NPDMetricExpirationWindowRatio)
+	}
+	c.NPDMetricWindow = o.NPDMetricWindow
+	c.NPDMetricAggregator = o.NPDMetricAggregator
+	c.NPDMetricExpiration = o.NPDMetricExpiration
+	c.NPDMetricExpirationWindowRatio = o.NPDMetricExpirationWindowRatio
+
+	c.FragmentationThreshold = o.FragmentationThreshold
+	c.FragmentationMinFreeCPU = o.FragmentationMinFreeCPU
+	c.MaxEvictionsPerCycle = o.MaxEvictionsPerCycle
+	c.MaxEvictionsPerNode = o.MaxEvictionsPerNode
+	c.EvictDedicatedCores = o.EvictDedicatedCores
+	c.SkippedNamespaces = o.SkippedNamespaces
+	return nil
+}
+
+func (o *ReschedulingOptions) Config() (*controller.ReschedulingConfig, error) {
+	c := controller.NewReschedulingConfig()
+	if err := o.ApplyTo(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+	"time"
+
+	cliflag "k8s.io/component-base/cli/flag"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	"github.com/kubewharf/katalyst-core/pkg/util/datasource/prometheus"
+)
+
+// defaultTideOnlineDemandQuery sums up cpu requests of online pods on nodes of the node pool
+// exported by kube-state-metrics, which must be configured to export the tide labels of pods and nodes
+const defaultTideOnlineDemandQuery = `sum(kube_pod_container_resource_requests{resource="cpu"${filters}}` +
+	` * on (namespace, pod) group_left() max by (namespace, pod) (kube_pod_labels{label_tide_katalyst_kubewharf_io_pod_type="online"${filters}})` +
+	` * on (node) group_left() max by (node) (kube_node_labels{label_tide_katalyst_kubewharf_io_node_pool="${node_pool}"${filters}}))`
+
+// TideOptions holds the configurations for tide controller.
+type TideOptions struct {
+	DataSourcePromConfig prometheus.PromConfig
+
+	OnlineDemandQuery       string
+	OnlineDemandHistoryDays int
+	OnlineDemandHistoryStep time.Duration
+}
+
+// NewTideOptions creates a new Options with a default config.
+func NewTideOptions() *TideOptions {
+	return &TideOptions{
+		DataSourcePromConfig: prometheus.PromConfig{
+			KeepAlive: 60 * time.Second,
+			Timeout:   time.Minute,
+		},
+		OnlineDemandQuery:       defaultTideOnlineDemandQuery,
+		OnlineDemandHistoryDays: 7,
+		OnlineDemandHistoryStep: time.Minute,
+	}
+}
+
+// AddFlags adds flags to the specified FlagSet
+func (o *TideOptions) AddFlags(fss *cliflag.NamedFlagSets) {
+	fs := fss.FlagSet("tide")
+
+	fs.StringVar(&o.DataSourcePromConfig.Address, "tide-prometheus-address", o.DataSourcePromConfig.Address,
+		"prometheus address which provides the history of online demand for predictive mode, "+
+			"and only the demand observed by the controller is used if it's empty")
+	fs.StringVar(&o.DataSourcePromConfig.Auth.Type, "tide-prometheus-auth-type", o.DataSourcePromConfig.Auth.Type,
+		"prometheus auth type used by tide controller")
+	fs.StringVar(&o.DataSourcePromConfig.Auth.Username, "tide-prometheus-auth-username", o.DataSourcePromConfig.Auth.Username,
+		"prometheus auth username used by tide controller")
+	fs.StringVar(&o.DataSourcePromConfig.Auth.Password, "tide-prometheus-auth-password", o.DataSourcePromConfig.Auth.Password,
+		"prometheus auth password used by tide controller")
+	fs.StringVar(&o.DataSourcePromConfig.Auth.BearerToken, "tide-prometheus-auth-bearertoken", o.DataSourcePromConfig.Auth.BearerToken,
+		"prometheus auth bearertoken used by tide controller")
+	fs.DurationVar(&o.DataSourcePromConfig.KeepAlive, "tide-prometheus-keepalive", o.DataSourcePromConfig.KeepAlive,
+		"prometheus keep alive used by tide controller")
+	fs.DurationVar(&o.DataSourcePromConfig.Timeout, "tide-prometheus-timeout", o.DataSourcePromConfig.Timeout,
+		"prometheus timeout used by tide controller")
+	fs.StringVar(&o.DataSourcePromConfig.BaseFilter, "tide-prometheus-promql-base-filter", o.DataSourcePromConfig.BaseFilter,
+		"basic filters added to the online demand query, e.g: cluster=\\\"test\\\"")
+	fs.StringVar(&o.OnlineDemandQuery, "tide-online-demand-query", o.OnlineDemandQuery,
+		"the promql expression of online cpu demand (in cores) of a node pool, in which ${node_pool} is replaced "+
+			"by the name of the node pool and ${filters} by the base filter")
+	fs.IntVar(&o.OnlineDemandHistoryDays, "tide-online-demand-history-days", o.OnlineDemandHistoryDays,
+		"the number of previous days whose online demand at the same time of day is used to forecast")
+	fs.DurationVar(&o.OnlineDemandHistoryStep, "tide-online-demand-history-step", o.OnlineDemandHistoryStep,
+		"the resolution of online demand history, and forecasts are refreshed at this interval")
+}
+
+// ApplyTo fills up config with options
+func (o *TideOptions) ApplyTo(c *controller.TideConfig) error {
+	if o.OnlineDemandHistoryDays <= 0 {
+		return fmt.Errorf("invalid online demand history days %v", o.OnlineDemandHistoryDays)
+	} else if o.OnlineDemandHistoryStep <= 0 {
+		return fmt.Errorf("invalid online demand history step %v", o.OnlineDemandHistoryStep)
+	}
+
+	c.DataSourcePromConfig = o.DataSourcePromConfig
+	c.OnlineDemandQuery = o.OnlineDemandQuery
+	c.OnlineDemandHistoryDays = o.OnlineDemandHistoryDays
+	c.OnlineDemandHistoryStep = o.OnlineDemandHistoryStep
+	return nil
+}
+
+func (o *TideOptions) Config() (*controller.TideConfig, error) {
+	c := controller.NewTideConfig()
+	if err := o.ApplyTo(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -31,8 +31,10 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/consts"
 	"github.com/kubewharf/katalyst-core/pkg/custom-metric/collector"
 	"github.com/kubewharf/katalyst-core/pkg/custom-metric/collector/prometheus"
+	"github.com/kubewharf/katalyst-core/pkg/custom-metric/collector/push"
 	"github.com/kubewharf/katalyst-core/pkg/custom-metric/mock"
 	"github.com/kubewharf/katalyst-core/pkg/custom-metric/store"
+	"github.com/kubewharf/katalyst-core/pkg/util/credential"
 )
 
 func StartCustomMetricCollect(ctx context.Context, baseCtx *katalystbase.GenericContext, conf *config.Configuration,
@@ -111,6 +113,15 @@ func initMetricCollector(ctx context.Context, baseCtx *katalystbase.GenericConte
 	switch conf.CollectorConfiguration.CollectorName {
 	case prometheus.MetricCollectorNamePrometheus:
 		return prometheus.NewPrometheusCollector(ctx, baseCtx, conf.GenericMetricConfiguration, conf.CollectorConfiguration, metricStore)
+	case push.MetricCollectorNamePush:
+		katalystbase.RegisterTokenReviewCredential(baseCtx.Client)
+
+		cred, err := credential.GetCredential(conf.GenericConfiguration, conf.DynamicAgentConfiguration)
+		if err != nil {
+			return nil, err
+		}
+		return push.NewPushCollector(ctx, baseCtx, conf.GenericConfiguration, conf.GenericMetricConfiguration,
+			conf.CollectorConfiguration, cred, metricStore)
 	case mock.MetricCollectorNameMock:
 		return mock.NewMockCollector(ctx, baseCtx, conf.GenericMetricConfiguration, conf.CollectorConfiguration, conf.MockConfiguration, metricStore)
 	}
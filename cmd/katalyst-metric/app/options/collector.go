@@ -40,6 +40,11 @@ type CollectorOptions struct {
 	CollectorName   string
 	CollectInterval time.Duration
 	CredentialPath  string
+
+	PushListenAddress       string
+	PushMaxPendingSamples   int
+	PushMaxInflightRequests int
+	PushPrivilegedSubjects  []string
 }
 
 // NewCollectorOptions creates a new CollectorOptions with a default config.
@@ -53,6 +58,10 @@ func NewCollectorOptions() *CollectorOptions {
 		NodeLabelSelector: labels.Everything().String(),
 
 		CredentialPath: "/etc/katalyst/credential",
+
+		PushListenAddress:       ":9317",
+		PushMaxPendingSamples:   100000,
+		PushMaxInflightRequests: 64,
 	}
 }
 
@@ -74,6 +83,16 @@ func (o *CollectorOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 
 	fs.StringVar(&o.CredentialPath, "credential-path", o.CredentialPath, fmt.Sprintf(
 		"directory path where credential files should be in"))
+
+	fs.StringVar(&o.PushListenAddress, "collector-push-address", o.PushListenAddress, fmt.Sprintf(
+		"the address push collector listens on to accept pushed metric samples"))
+	fs.IntVar(&o.PushMaxPendingSamples, "collector-push-max-pending-samples", o.PushMaxPendingSamples, fmt.Sprintf(
+		"the max number of pushed samples (i.e. metric data points) buffered before flushing into store, pushing will be rejected beyond it"))
+	fs.IntVar(&o.PushMaxInflightRequests, "collector-push-max-inflight-requests", o.PushMaxInflightRequests, fmt.Sprintf(
+		"the max number of pushing requests handled concurrently, pushing will be rejected beyond it"))
+	fs.StringSliceVar(&o.PushPrivilegedSubjects, "collector-push-privileged-subjects", o.PushPrivilegedSubjects, fmt.Sprintf(
+		"the subjects allowed to push metrics of any objects, other subjects must be node credentials "+
+			"and can only push metrics of their own nodes and pods on them"))
 }
 
 // ApplyTo fills up config with options
@@ -95,6 +114,11 @@ func (o *CollectorOptions) ApplyTo(c *metric.CollectorConfiguration) error {
 	c.NodeSelector = nodeSelector
 
 	c.CredentialPath = o.CredentialPath
+
+	c.PushListenAddress = o.PushListenAddress
+	c.PushMaxPendingSamples = o.PushMaxPendingSamples
+	c.PushMaxInflightRequests = o.PushMaxInflightRequests
+	c.PushPrivilegedSubjects = o.PushPrivilegedSubjects
 	return nil
 }
 
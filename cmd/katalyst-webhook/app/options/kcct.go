@@ -0,0 +1,63 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"k8s.io/apimachinery/pkg/util/sets"
+	cliflag "k8s.io/component-base/cli/flag"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/config/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/config/webhook"
+)
+
+// KCCTOptions holds the configurations for KCC target webhook.
+type KCCTOptions struct {
+	ValidAPIGroupSet []string
+	DefaultGVRs      []string
+}
+
+// NewKCCTOptions creates a new Options with a default config.
+func NewKCCTOptions() *KCCTOptions {
+	return &KCCTOptions{
+		ValidAPIGroupSet: []string{v1alpha1.SchemeGroupVersion.Group},
+	}
+}
+
+// AddFlags adds flags  to the specified FlagSet.
+func (o *KCCTOptions) AddFlags(fss *cliflag.NamedFlagSets) {
+	fs := fss.FlagSet("kcct-webhook")
+
+	fs.StringSliceVar(&o.ValidAPIGroupSet, "kcct-webhook-valid-api-group-set", o.ValidAPIGroupSet,
+		"which Groups is allowed for kcc targets to be validated")
+	fs.StringSliceVar(&o.DefaultGVRs, "kcct-webhook-default-gvrs", o.DefaultGVRs,
+		"which kcc target gvrs need to be validated by default")
+}
+
+// ApplyTo fills up config with options
+func (o *KCCTOptions) ApplyTo(c *webhook.KCCTConfig) error {
+	c.ValidAPIGroupSet = sets.NewString(o.ValidAPIGroupSet...)
+	c.DefaultGVRs = o.DefaultGVRs
+	return nil
+}
+
+func (o *KCCTOptions) Config() (*webhook.KCCTConfig, error) {
+	c := webhook.NewKCCTConfig()
+	if err := o.ApplyTo(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
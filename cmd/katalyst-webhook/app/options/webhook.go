@@ -26,18 +26,21 @@ import (
 type WebhookOptions struct {
 	*VPAOptions
 	*PodOptions
+	*KCCTOptions
 }
 
 func NewWebhooksOptions() *WebhookOptions {
 	return &WebhookOptions{
-		VPAOptions: NewVPAOptions(),
-		PodOptions: NewPodOptions(),
+		VPAOptions:  NewVPAOptions(),
+		PodOptions:  NewPodOptions(),
+		KCCTOptions: NewKCCTOptions(),
 	}
 }
 
 func (o *WebhookOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 	o.VPAOptions.AddFlags(fss)
 	o.PodOptions.AddFlags(fss)
+	o.KCCTOptions.AddFlags(fss)
 }
 
 // ApplyTo fills up config with options
@@ -46,6 +49,7 @@ func (o *WebhookOptions) ApplyTo(c *webhookconfig.WebhooksConfiguration) error {
 
 	errList = append(errList, o.VPAOptions.ApplyTo(c.VPAConfig))
 	errList = append(errList, o.PodOptions.ApplyTo(c.PodConfig))
+	errList = append(errList, o.KCCTOptions.ApplyTo(c.KCCTConfig))
 	return errors.NewAggregate(errList)
 }
 
@@ -114,6 +114,10 @@ var WebhooksDisabledByDefault = sets.NewString()
 func NewWebhookInitializers() map[string]InitFunc {
 	webhooks := make(map[string]InitFunc)
 	webhooks[validating.VPAWebhookName] = validating.StartVPAWebhook
+	webhooks[validating.PodWebhookName] = validating.StartPodWebhook
+	webhooks[validating.KCCTWebhookName] = validating.StartKCCTWebhook
+	webhooks[validating.SPDWebhookName] = validating.StartSPDWebhook
+	webhooks[validating.IHPAWebhookName] = validating.StartIHPAWebhook
 	webhooks[mutating.PodWebhookName] = mutating.StartPodWebhook
 	webhooks[mutating.NodeWebhookName] = mutating.StartNodeWebhook
 	return webhooks
@@ -34,9 +34,17 @@ func (b *BaseAuditSink) Run(ctx context.Context, bus eventbus.EventBus) {
 	if err != nil {
 		general.Errorf("subscribe %v failed", consts.TopicNameApplyCGroup)
 	}
+	err = bus.Subscribe(consts.TopicNameCGroupDrift, b.GetName(), b.GetBufferSize(), b.GetHandler())
+	if err != nil {
+		general.Errorf("subscribe %v failed", consts.TopicNameCGroupDrift)
+	}
 	err = bus.Subscribe(consts.TopicNameSyscall, b.GetName(), b.GetBufferSize(), b.GetHandler())
 	if err != nil {
 		general.Errorf("subscribe %v failed", consts.TopicNameSyscall)
 	}
+	err = bus.Subscribe(consts.TopicNameContainerOOM, b.GetName(), b.GetBufferSize(), b.GetHandler())
+	if err != nil {
+		general.Errorf("subscribe %v failed", consts.TopicNameContainerOOM)
+	}
 	<-ctx.Done()
 }
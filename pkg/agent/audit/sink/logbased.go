@@ -50,8 +50,12 @@ func (f *LogBasedAuditSink) GetHandler() eventbus.ConsumeFunc {
 		switch e := event.(type) {
 		case eventbus.RawCGroupEvent:
 			general.Infof("[audit log] cgroup event: %+v", e)
+		case eventbus.CGroupDriftEvent:
+			general.Infof("[audit log] cgroup drift event: %+v", e)
 		case eventbus.SyscallEvent:
 			general.Infof("[audit log] syscall event: %+v", e)
+		case eventbus.ContainerOOMEvent:
+			general.Infof("[audit log] container oom event: %+v", e)
 		default:
 			general.Warningf("unsupported event type:%v", reflect.TypeOf(event))
 		}
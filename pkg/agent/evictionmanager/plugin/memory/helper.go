@@ -17,10 +17,12 @@ limitations under the License.
 package memory
 
 import (
+	"fmt"
 	"strconv"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/kubewharf/katalyst-core/pkg/config"
 	evictionconfig "github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/adminqos/eviction"
@@ -28,6 +30,8 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/metaserver"
 	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/metric/helper"
 	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
+	cgroupmgr "github.com/kubewharf/katalyst-core/pkg/util/cgroup/manager"
 	"github.com/kubewharf/katalyst-core/pkg/util/general"
 	"github.com/kubewharf/katalyst-core/pkg/util/native"
 )
@@ -79,6 +83,7 @@ type EvictionHelper struct {
 	metaServer         *metaserver.MetaServer
 	emitter            metrics.MetricEmitter
 	reclaimedPodFilter func(pod *v1.Pod) (bool, error)
+	podSwapUsageGetter func(pod *v1.Pod) (uint64, error)
 }
 
 func NewEvictionHelper(emitter metrics.MetricEmitter, metaServer *metaserver.MetaServer, conf *config.Configuration) *EvictionHelper {
@@ -86,15 +91,32 @@ func NewEvictionHelper(emitter metrics.MetricEmitter, metaServer *metaserver.Met
 		metaServer:         metaServer,
 		emitter:            emitter,
 		reclaimedPodFilter: conf.CheckReclaimedQoSForPod,
+		podSwapUsageGetter: getPodSwapUsage,
 	}
 }
 
+// getPodSwapUsage returns swap usage of the pod, and it's only supported in cgroup v2
+func getPodSwapUsage(pod *v1.Pod) (uint64, error) {
+	absCgroupPath, err := common.GetPodAbsCgroupPath(common.CgroupSubsysMemory, string(pod.UID))
+	if err != nil {
+		return 0, err
+	}
+
+	swapStats, err := cgroupmgr.GetSwapWithAbsolutePath(absCgroupPath)
+	if err != nil {
+		return 0, err
+	} else if swapStats == nil {
+		return 0, fmt.Errorf("nil swap stats of pod %s/%s", pod.Namespace, pod.Name)
+	}
+	return swapStats.SwapUsage, nil
+}
+
 func (e *EvictionHelper) selectTopNPodsToEvictByMetrics(activePods []*v1.Pod, topN uint64, numaID,
 	action int, rankingMetrics []string, podToEvictMap map[string]*v1.Pod,
 ) {
 	filteredPods := e.filterPods(activePods, action)
 	if filteredPods != nil {
-		general.NewMultiSorter(e.getEvictionCmpFuncs(rankingMetrics, numaID)...).Sort(native.NewPodSourceImpList(filteredPods))
+		general.NewMultiSorter(e.getEvictionCmpFuncs(rankingMetrics, numaID, filteredPods)...).Sort(native.NewPodSourceImpList(filteredPods))
 		for i := 0; uint64(i) < general.MinUInt64(topN, uint64(len(filteredPods))); i++ {
 			podToEvictMap[string(filteredPods[i].UID)] = filteredPods[i]
 		}
@@ -112,10 +134,19 @@ func (e *EvictionHelper) filterPods(pods []*v1.Pod, action int) []*v1.Pod {
 	}
 }
 
-// getEvictionCmpFuncs returns a comparison function list to judge the eviction order of different pods
-func (e *EvictionHelper) getEvictionCmpFuncs(rankingMetrics []string, numaID int) []general.CmpFunc {
+// getEvictionCmpFuncs returns a comparison function list to judge the eviction order of the given pods
+func (e *EvictionHelper) getEvictionCmpFuncs(rankingMetrics []string, numaID int, pods []*v1.Pod) []general.CmpFunc {
 	cmpFuncs := make([]general.CmpFunc, 0, len(rankingMetrics))
 
+	// swap usage is read from cgroupfs, so it's read only once for each pod before sorting
+	var podSwapUsage map[types.UID]uint64
+	for _, m := range rankingMetrics {
+		if m == evictionconfig.FakeMetricSwapUsage {
+			podSwapUsage = e.getPodSwapUsage(pods)
+			break
+		}
+	}
+
 	for _, m := range rankingMetrics {
 		currentMetric := m
 		cmpFuncs = append(cmpFuncs, func(s1, s2 interface{}) int {
@@ -142,6 +173,17 @@ func (e *EvictionHelper) getEvictionCmpFuncs(rankingMetrics []string, numaID int
 			case evictionconfig.FakeMetricPriority:
 				// prioritize evicting the pod whose priority is lower
 				return general.ReverseCmpFunc(native.PodPriorityCmpFunc)(p1, p2)
+			case evictionconfig.FakeMetricSwapUsage:
+				p1Swap, p1Found := podSwapUsage[p1.UID]
+				p2Swap, p2Found := podSwapUsage[p2.UID]
+				if !p1Found || !p2Found {
+					// prioritize evicting the pod whose swap usage is available,
+					// since swap may be not supported for the others
+					return general.CmpBool(p1Found, p2Found)
+				}
+
+				// prioritize evicting the pod which swaps more
+				return general.CmpFloat64(float64(p1Swap), float64(p2Swap))
 			default:
 				p1Metric, p1Err := helper.GetPodMetric(e.metaServer.MetricsFetcher, e.emitter, p1, currentMetric, numaID)
 				p2Metric, p2Err := helper.GetPodMetric(e.metaServer.MetricsFetcher, e.emitter, p2, currentMetric, numaID)
@@ -165,6 +207,20 @@ func (e *EvictionHelper) getEvictionCmpFuncs(rankingMetrics []string, numaID int
 	return cmpFuncs
 }
 
+// getPodSwapUsage returns swap usage of those pods, and the pods failed to get are skipped
+func (e *EvictionHelper) getPodSwapUsage(pods []*v1.Pod) map[types.UID]uint64 {
+	podSwapUsage := make(map[types.UID]uint64, len(pods))
+	for _, pod := range pods {
+		swapUsage, err := e.podSwapUsageGetter(pod)
+		if err != nil {
+			general.Warningf("failed to get swap usage of pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			continue
+		}
+		podSwapUsage[pod.UID] = swapUsage
+	}
+	return podSwapUsage
+}
+
 // getCandidates returns pods which use memory more than minimumUsageThreshold.
 func (e *EvictionHelper) getCandidates(pods []*v1.Pod, numaID int, minimumUsageThreshold float64) []*v1.Pod {
 	result := make([]*v1.Pod, 0, len(pods))
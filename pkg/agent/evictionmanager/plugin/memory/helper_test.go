@@ -17,14 +17,17 @@ limitations under the License.
 package memory
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	evictionconfig "github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/adminqos/eviction"
 	"github.com/kubewharf/katalyst-core/pkg/consts"
 	"github.com/kubewharf/katalyst-core/pkg/metaserver"
 	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/metric"
@@ -220,7 +223,7 @@ func TestEvictionHelper_getEvictionCmpFuncs(t *testing.T) {
 		fakeMetricsFetcher.SetContainerMetric(string(pod.UID), pod.Spec.Containers[0].Name, consts.MetricMemUsageContainer, utilmetric.MetricData{Value: podUsageSystem[i], Time: &now})
 	}
 	general.NewMultiSorter(helper.getEvictionCmpFuncs(conf.GetDynamicConfiguration().SystemEvictionRankingMetrics,
-		nonExistNumaID)...).Sort(native.NewPodSourceImpList(pods))
+		nonExistNumaID, pods)...).Sort(native.NewPodSourceImpList(pods))
 
 	wantPodNameList := []string{
 		"pod-2",
@@ -236,3 +239,42 @@ func TestEvictionHelper_getEvictionCmpFuncs(t *testing.T) {
 		assert.Equal(t, wantPodNameList[i], pods[i].Name)
 	}
 }
+
+func TestEvictionHelper_getEvictionCmpFuncsBySwapUsage(t *testing.T) {
+	t.Parallel()
+
+	metaServer := makeMetaServer()
+	helper, err := makeHelper(metaServer)
+	assert.NoError(t, err)
+
+	swapUsage := map[string]uint64{
+		"pod-1": 1 << 20,
+		"pod-2": 1 << 30,
+		"pod-3": 0,
+	}
+	getterCalls := map[string]int{}
+	helper.podSwapUsageGetter = func(pod *v1.Pod) (uint64, error) {
+		getterCalls[pod.Name]++
+		usage, ok := swapUsage[pod.Name]
+		if !ok {
+			return 0, fmt.Errorf("no swap usage of %s", pod.Name)
+		}
+		return usage, nil
+	}
+
+	pods := make([]*v1.Pod, 0)
+	for _, name := range []string{"pod-1", "pod-2", "pod-3", "pod-4"} {
+		pods = append(pods, &v1.Pod{ObjectMeta: metav1.ObjectMeta{UID: types.UID(name), Name: name}})
+	}
+
+	general.NewMultiSorter(helper.getEvictionCmpFuncs([]string{evictionconfig.FakeMetricSwapUsage},
+		nonExistNumaID, pods)...).Sort(native.NewPodSourceImpList(pods))
+
+	podNames := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		podNames = append(podNames, pod.Name)
+	}
+	assert.Equal(t, []string{"pod-2", "pod-1", "pod-3", "pod-4"}, podNames)
+	// swap usage is read only once for each pod
+	assert.Equal(t, map[string]int{"pod-1": 1, "pod-2": 1, "pod-3": 1, "pod-4": 1}, getterCalls)
+}
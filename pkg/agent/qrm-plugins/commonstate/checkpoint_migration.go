@@ -0,0 +1,341 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commonstate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager"
+
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	// CheckpointVersionKey is the json key of schema version in qrm plugin checkpoints,
+	// and checkpoints without it are regarded as LegacyCheckpointVersion.
+	CheckpointVersionKey    = "version"
+	LegacyCheckpointVersion = 0
+
+	checkpointBackupFileFormat = "%s.v%d.bak"
+)
+
+// CheckpointMigrateFunc migrates the decoded checkpoint in place to the next version;
+// numbers in checkpoint are decoded as json.Number to keep them precise.
+type CheckpointMigrateFunc func(checkpoint map[string]interface{}) error
+
+// CheckpointMigration migrates checkpoints from FromVersion to FromVersion+1
+type CheckpointMigration struct {
+	FromVersion int
+	Description string
+	Migrate     CheckpointMigrateFunc
+	// Verify checks the checksum of checkpoint in FromVersion, and it's optional
+	// since the types to calculate checksum of old versions may not exist anymore.
+	Verify func(blob []byte) error
+}
+
+// CheckpointMigrationReport describes what a migration changes (or would change in dry run),
+// and values are identified by their json paths joined by dots.
+type CheckpointMigrationReport struct {
+	CheckpointName string   `json:"checkpointName"`
+	FromVersion    int      `json:"fromVersion"`
+	ToVersion      int      `json:"toVersion"`
+	Steps          []string `json:"steps,omitempty"`
+	Added          []string `json:"added,omitempty"`
+	Removed        []string `json:"removed,omitempty"`
+	Modified       []string `json:"modified,omitempty"`
+	BackupFile     string   `json:"backupFile,omitempty"`
+	DryRun         bool     `json:"dryRun"`
+}
+
+func (r *CheckpointMigrationReport) String() string {
+	return fmt.Sprintf("checkpoint %s from v%d to v%d (dryRun: %v), steps: [%s], added: %v, removed: %v, modified: %v",
+		r.CheckpointName, r.FromVersion, r.ToVersion, r.DryRun, strings.Join(r.Steps, "; "), r.Added, r.Removed, r.Modified)
+}
+
+// CheckpointMigrator is the registry of forward migrations for checkpoints of a qrm plugin,
+// and it migrates checkpoint files written by older versions of agent before they are restored.
+type CheckpointMigrator struct {
+	mutex          sync.RWMutex
+	pluginName     string
+	currentVersion int
+	newCheckpoint  func() checkpointmanager.Checkpoint
+	migrations     map[int]CheckpointMigration
+}
+
+// NewCheckpointMigrator returns a migrator for checkpoints in currentVersion, and
+// newCheckpoint returns an empty checkpoint of current schema.
+func NewCheckpointMigrator(pluginName string, currentVersion int,
+	newCheckpoint func() checkpointmanager.Checkpoint,
+) *CheckpointMigrator {
+	return &CheckpointMigrator{
+		pluginName:     pluginName,
+		currentVersion: currentVersion,
+		newCheckpoint:  newCheckpoint,
+		migrations:     make(map[int]CheckpointMigration),
+	}
+}
+
+// NewVersionedCheckpointMigrator returns a migrator with the migration from LegacyCheckpointVersion
+// registered, in which schema is unchanged except that version is recorded, so checkpoints in
+// legacy version can still be verified by current type.
+func NewVersionedCheckpointMigrator(pluginName string, currentVersion int,
+	newCheckpoint func() checkpointmanager.Checkpoint,
+) *CheckpointMigrator {
+	migrator := NewCheckpointMigrator(pluginName, currentVersion, newCheckpoint)
+	migrator.RegisterMigration(CheckpointMigration{
+		FromVersion: LegacyCheckpointVersion,
+		Description: "record schema version",
+		Migrate:     func(_ map[string]interface{}) error { return nil },
+		Verify: func(blob []byte) error {
+			cp := newCheckpoint()
+			if err := cp.UnmarshalCheckpoint(blob); err != nil {
+				return err
+			}
+			return cp.VerifyChecksum()
+		},
+	})
+	return migrator
+}
+
+// RegisterMigration registers a migration, and it overrides the existed one with the same FromVersion
+func (m *CheckpointMigrator) RegisterMigration(migration CheckpointMigration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.migrations[migration.FromVersion] = migration
+}
+
+func (m *CheckpointMigrator) CurrentVersion() int {
+	return m.currentVersion
+}
+
+// DryRun reports what migration would change without touching any files,
+// and it returns nil report if the checkpoint needn't to be migrated.
+func (m *CheckpointMigrator) DryRun(stateDir, checkpointName string) (*CheckpointMigrationReport, error) {
+	return m.migrate(stateDir, checkpointName, true, false)
+}
+
+// CheckMigrationDryRun reports migration in dry run, and returns error if the checkpoint needs
+// to be migrated, so that plugins can refuse to start before the checkpoint is touched.
+func (m *CheckpointMigrator) CheckMigrationDryRun(stateDir, checkpointName string) error {
+	report, err := m.DryRun(stateDir, checkpointName)
+	if err != nil {
+		return err
+	} else if report != nil {
+		return fmt.Errorf("checkpoint migration is required: %v", report)
+	}
+	return nil
+}
+
+// CheckCheckpointMigration checks the checkpoint in stateDir by CheckMigrationDryRun if dryRun is
+// set, and it's called by plugins before their checkpoints are restored.
+func CheckCheckpointMigration(migrator *CheckpointMigrator, dryRun bool, stateDir, checkpointName string) error {
+	if !dryRun {
+		return nil
+	}
+
+	if err := migrator.CheckMigrationDryRun(stateDir, checkpointName); err != nil {
+		return fmt.Errorf("CheckMigrationDryRun for %s failed with error: %v", checkpointName, err)
+	}
+	return nil
+}
+
+// Migrate migrates the checkpoint to current version, and the original checkpoint is
+// backed up in the same directory before it's overwritten. Nil report is returned if the
+// checkpoint doesn't exist, is in current version or can't be decoded (it's left to
+// restoring to handle the corruption). If skipCorruption is true, checkpoints which fail
+// to be verified are still migrated.
+func (m *CheckpointMigrator) Migrate(stateDir, checkpointName string, skipCorruption bool) (*CheckpointMigrationReport, error) {
+	return m.migrate(stateDir, checkpointName, false, skipCorruption)
+}
+
+func (m *CheckpointMigrator) migrate(stateDir, checkpointName string, dryRun, skipCorruption bool) (*CheckpointMigrationReport, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	blob, err := os.ReadFile(filepath.Join(stateDir, checkpointName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read checkpoint %s failed: %v", checkpointName, err)
+	}
+
+	checkpoint, err := decodeCheckpoint(blob)
+	if err != nil {
+		general.Warningf("[%s] decode checkpoint %s failed: %v, skip migration", m.pluginName, checkpointName, err)
+		return nil, nil
+	}
+
+	version, err := getCheckpointVersion(checkpoint)
+	if err != nil {
+		return nil, fmt.Errorf("get version of checkpoint %s failed: %v", checkpointName, err)
+	}
+
+	if version == m.currentVersion {
+		return nil, nil
+	} else if version > m.currentVersion {
+		return nil, fmt.Errorf("checkpoint %s in version %d is newer than supported version %d, downgrade is not supported",
+			checkpointName, version, m.currentVersion)
+	}
+
+	report := &CheckpointMigrationReport{
+		CheckpointName: checkpointName,
+		FromVersion:    version,
+		ToVersion:      m.currentVersion,
+		DryRun:         dryRun,
+	}
+
+	if migration, ok := m.migrations[version]; ok && migration.Verify != nil {
+		if err := migration.Verify(blob); err != nil {
+			if !skipCorruption {
+				return nil, fmt.Errorf("verify checkpoint %s in version %d failed: %v", checkpointName, version, err)
+			}
+			general.Warningf("[%s] verify checkpoint %s in version %d failed: %v, but we skip it",
+				m.pluginName, checkpointName, version, err)
+		}
+	}
+
+	for v := version; v < m.currentVersion; v++ {
+		migration, ok := m.migrations[v]
+		if !ok || migration.Migrate == nil {
+			return nil, fmt.Errorf("no migration registered for checkpoint %s from version %d to %d", checkpointName, v, v+1)
+		}
+
+		if err := migration.Migrate(checkpoint); err != nil {
+			return nil, fmt.Errorf("migrate checkpoint %s from version %d to %d failed: %v", checkpointName, v, v+1, err)
+		}
+		report.Steps = append(report.Steps, fmt.Sprintf("v%d->v%d: %s", v, v+1, migration.Description))
+	}
+	checkpoint[CheckpointVersionKey] = json.Number(fmt.Sprint(m.currentVersion))
+
+	original, err := decodeCheckpoint(blob)
+	if err != nil {
+		return nil, err
+	}
+	diffCheckpoint("", original, checkpoint, report)
+
+	if dryRun {
+		general.Infof("[%s] dry run of checkpoint migration: %v", m.pluginName, report)
+		return report, nil
+	}
+
+	report.BackupFile = fmt.Sprintf(checkpointBackupFileFormat, checkpointName, version)
+	if err := os.WriteFile(filepath.Join(stateDir, report.BackupFile), blob, 0o644); err != nil {
+		return nil, fmt.Errorf("backup checkpoint %s failed: %v", checkpointName, err)
+	}
+
+	migrated, err := json.Marshal(checkpoint)
+	if err != nil {
+		return nil, fmt.Errorf("marshal migrated checkpoint %s failed: %v", checkpointName, err)
+	}
+
+	// write by checkpoint manager to calculate checksum for the current schema
+	cp := m.newCheckpoint()
+	if err := cp.UnmarshalCheckpoint(migrated); err != nil {
+		return nil, fmt.Errorf("unmarshal migrated checkpoint %s failed: %v", checkpointName, err)
+	}
+
+	checkpointManager, err := checkpointmanager.NewCheckpointManager(stateDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize checkpoint manager: %v", err)
+	}
+	if err := checkpointManager.CreateCheckpoint(checkpointName, cp); err != nil {
+		return nil, fmt.Errorf("store migrated checkpoint %s failed: %v", checkpointName, err)
+	}
+
+	general.Infof("[%s] checkpoint migrated: %v", m.pluginName, report)
+	return report, nil
+}
+
+func decodeCheckpoint(blob []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(blob))
+	decoder.UseNumber()
+
+	checkpoint := make(map[string]interface{})
+	if err := decoder.Decode(&checkpoint); err != nil {
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+func getCheckpointVersion(checkpoint map[string]interface{}) (int, error) {
+	value, ok := checkpoint[CheckpointVersionKey]
+	if !ok {
+		return LegacyCheckpointVersion, nil
+	}
+
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("invalid version %v", value)
+	}
+
+	version, err := number.Int64()
+	if err != nil {
+		return 0, fmt.Errorf("invalid version %v: %v", value, err)
+	}
+	return int(version), nil
+}
+
+// diffCheckpoint compares the decoded checkpoints recursively, and records
+// paths of values that are added, removed or modified into report.
+func diffCheckpoint(path string, original, migrated interface{}, report *CheckpointMigrationReport) {
+	originalMap, originalOK := original.(map[string]interface{})
+	migratedMap, migratedOK := migrated.(map[string]interface{})
+	if !originalOK || !migratedOK {
+		if !reflect.DeepEqual(original, migrated) {
+			report.Modified = append(report.Modified, path)
+		}
+		return
+	}
+
+	keys := make([]string, 0, len(originalMap)+len(migratedMap))
+	for key := range originalMap {
+		keys = append(keys, key)
+	}
+	for key := range migratedMap {
+		if _, ok := originalMap[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		subPath := key
+		if path != "" {
+			subPath = path + "." + key
+		}
+
+		originalValue, inOriginal := originalMap[key]
+		migratedValue, inMigrated := migratedMap[key]
+		switch {
+		case !inOriginal:
+			report.Added = append(report.Added, subPath)
+		case !inMigrated:
+			report.Removed = append(report.Removed, subPath)
+		default:
+			diffCheckpoint(subPath, originalValue, migratedValue, report)
+		}
+	}
+}
@@ -0,0 +1,339 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commonstate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager"
+	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager/checksum"
+)
+
+const (
+	testCheckpointName    = "test_plugin_state"
+	testCheckpointVersion = 2
+)
+
+// testCheckpoint is in version 2; "pods" is renamed to "entries" in version 1,
+// and "policy" is added in version 2.
+type testCheckpoint struct {
+	Policy   string            `json:"policy"`
+	Entries  map[string]string `json:"entries"`
+	Checksum checksum.Checksum `json:"checksum"`
+}
+
+func (cp *testCheckpoint) MarshalCheckpoint() ([]byte, error) {
+	cp.Checksum = 0
+	cp.Checksum = checksum.New(cp)
+	return json.Marshal(struct {
+		Version int `json:"version"`
+		*testCheckpoint
+	}{Version: testCheckpointVersion, testCheckpoint: cp})
+}
+
+func (cp *testCheckpoint) UnmarshalCheckpoint(blob []byte) error {
+	return json.Unmarshal(blob, cp)
+}
+
+func (cp *testCheckpoint) VerifyChecksum() error {
+	ck := cp.Checksum
+	cp.Checksum = 0
+	err := ck.Verify(cp)
+	cp.Checksum = ck
+	return err
+}
+
+func newTestCheckpointMigrator(withV1Migration bool) *CheckpointMigrator {
+	migrator := NewCheckpointMigrator("test_plugin", testCheckpointVersion, func() checkpointmanager.Checkpoint {
+		return &testCheckpoint{}
+	})
+	migrator.RegisterMigration(CheckpointMigration{
+		FromVersion: LegacyCheckpointVersion,
+		Description: "rename pods to entries",
+		Migrate: func(checkpoint map[string]interface{}) error {
+			checkpoint["entries"] = checkpoint["pods"]
+			delete(checkpoint, "pods")
+			return nil
+		},
+		Verify: func(blob []byte) error {
+			legacy := struct {
+				Checksum checksum.Checksum `json:"checksum"`
+			}{}
+			if err := json.Unmarshal(blob, &legacy); err != nil {
+				return err
+			} else if legacy.Checksum == 0 {
+				return fmt.Errorf("checkpoint is corrupted")
+			}
+			return nil
+		},
+	})
+	if withV1Migration {
+		migrator.RegisterMigration(CheckpointMigration{
+			FromVersion: 1,
+			Description: "add policy",
+			Migrate: func(checkpoint map[string]interface{}) error {
+				checkpoint["policy"] = "static"
+				return nil
+			},
+		})
+	}
+	return migrator
+}
+
+func TestCheckpointMigrator_Migrate(t *testing.T) {
+	t.Parallel()
+
+	legacyCheckpoint := `{"pods":{"pod-1":"0-3"},"checksum":123}`
+
+	tests := []struct {
+		name            string
+		content         string
+		withV1Migration bool
+		dryRun          bool
+		skipCorruption  bool
+		wantReport      *CheckpointMigrationReport
+		wantErr         bool
+		wantEntries     map[string]string
+	}{
+		{
+			name:            "checkpoint not found",
+			withV1Migration: true,
+		},
+		{
+			name:            "invalid checkpoint is left to restoring",
+			content:         `{`,
+			withV1Migration: true,
+		},
+		{
+			name:            "checkpoint in current version",
+			content:         `{"version":2,"policy":"static","entries":{},"checksum":1}`,
+			withV1Migration: true,
+		},
+		{
+			name:            "checkpoint in newer version",
+			content:         `{"version":3}`,
+			withV1Migration: true,
+			wantErr:         true,
+		},
+		{
+			name:            "migrate legacy checkpoint",
+			content:         legacyCheckpoint,
+			withV1Migration: true,
+			wantReport: &CheckpointMigrationReport{
+				CheckpointName: testCheckpointName,
+				FromVersion:    LegacyCheckpointVersion,
+				ToVersion:      testCheckpointVersion,
+				Steps:          []string{"v0->v1: rename pods to entries", "v1->v2: add policy"},
+				Added:          []string{"entries", "policy", "version"},
+				Removed:        []string{"pods"},
+				BackupFile:     testCheckpointName + ".v0.bak",
+			},
+			wantEntries: map[string]string{"pod-1": "0-3"},
+		},
+		{
+			name:            "migrate checkpoint in version 1",
+			content:         `{"version":1,"entries":{"pod-1":"0-3"},"checksum":123}`,
+			withV1Migration: true,
+			wantReport: &CheckpointMigrationReport{
+				CheckpointName: testCheckpointName,
+				FromVersion:    1,
+				ToVersion:      testCheckpointVersion,
+				Steps:          []string{"v1->v2: add policy"},
+				Added:          []string{"policy"},
+				Modified:       []string{"version"},
+				BackupFile:     testCheckpointName + ".v1.bak",
+			},
+			wantEntries: map[string]string{"pod-1": "0-3"},
+		},
+		{
+			name:            "dry run",
+			content:         legacyCheckpoint,
+			withV1Migration: true,
+			dryRun:          true,
+			wantReport: &CheckpointMigrationReport{
+				CheckpointName: testCheckpointName,
+				FromVersion:    LegacyCheckpointVersion,
+				ToVersion:      testCheckpointVersion,
+				Steps:          []string{"v0->v1: rename pods to entries", "v1->v2: add policy"},
+				Added:          []string{"entries", "policy", "version"},
+				Removed:        []string{"pods"},
+				DryRun:         true,
+			},
+		},
+		{
+			name:    "missing migration",
+			content: legacyCheckpoint,
+			wantErr: true,
+		},
+		{
+			name:            "corrupted legacy checkpoint",
+			content:         `{"pods":{"pod-1":"0-3"}}`,
+			withV1Migration: true,
+			wantErr:         true,
+		},
+		{
+			name:            "skip corrupted legacy checkpoint",
+			content:         `{"pods":{"pod-1":"0-3"}}`,
+			withV1Migration: true,
+			skipCorruption:  true,
+			wantReport: &CheckpointMigrationReport{
+				CheckpointName: testCheckpointName,
+				FromVersion:    LegacyCheckpointVersion,
+				ToVersion:      testCheckpointVersion,
+				Steps:          []string{"v0->v1: rename pods to entries", "v1->v2: add policy"},
+				Added:          []string{"entries", "policy", "version"},
+				Removed:        []string{"pods"},
+				BackupFile:     testCheckpointName + ".v0.bak",
+			},
+			wantEntries: map[string]string{"pod-1": "0-3"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			stateDir := t.TempDir()
+			checkpointFile := filepath.Join(stateDir, testCheckpointName)
+			if tt.content != "" {
+				require.NoError(t, os.WriteFile(checkpointFile, []byte(tt.content), 0o644))
+			}
+
+			migrator := newTestCheckpointMigrator(tt.withV1Migration)
+			var (
+				report *CheckpointMigrationReport
+				err    error
+			)
+			if tt.dryRun {
+				report, err = migrator.DryRun(stateDir, testCheckpointName)
+			} else {
+				report, err = migrator.Migrate(stateDir, testCheckpointName, tt.skipCorruption)
+			}
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantReport, report)
+
+			if report == nil || tt.dryRun {
+				// checkpoint is kept untouched
+				if tt.content != "" {
+					blob, err := os.ReadFile(checkpointFile)
+					assert.NoError(t, err)
+					assert.Equal(t, tt.content, string(blob))
+				}
+				files, err := filepath.Glob(filepath.Join(stateDir, "*.bak"))
+				assert.NoError(t, err)
+				assert.Empty(t, files)
+				return
+			}
+
+			backup, err := os.ReadFile(filepath.Join(stateDir, report.BackupFile))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.content, string(backup))
+
+			checkpointManager, err := checkpointmanager.NewCheckpointManager(stateDir)
+			require.NoError(t, err)
+			cp := &testCheckpoint{}
+			assert.NoError(t, checkpointManager.GetCheckpoint(testCheckpointName, cp))
+			assert.Equal(t, tt.wantEntries, cp.Entries)
+			assert.Equal(t, "static", cp.Policy)
+
+			// migrated checkpoint needn't to be migrated again
+			report, err = migrator.Migrate(stateDir, testCheckpointName, false)
+			assert.NoError(t, err)
+			assert.Nil(t, report)
+		})
+	}
+}
+
+func TestCheckpointMigrator_CheckMigrationDryRun(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		content        string
+		dryRunDisabled bool
+		wantErr        bool
+	}{
+		{
+			name:    "migration is required",
+			content: `{"pods":{},"checksum":123}`,
+			wantErr: true,
+		},
+		{
+			name:    "checkpoint in current version",
+			content: `{"version":2}`,
+		},
+		{
+			name:           "dry run is disabled",
+			content:        `{"pods":{},"checksum":123}`,
+			dryRunDisabled: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			stateDir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(stateDir, testCheckpointName), []byte(tt.content), 0o644))
+
+			err := CheckCheckpointMigration(newTestCheckpointMigrator(true), !tt.dryRunDisabled, stateDir, testCheckpointName)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewVersionedCheckpointMigrator(t *testing.T) {
+	t.Parallel()
+
+	legacy := &testCheckpoint{Policy: "static", Entries: map[string]string{"pod-1": "0-3"}}
+	legacy.Checksum = checksum.New(legacy)
+	blob, err := json.Marshal(legacy)
+	require.NoError(t, err)
+
+	migrator := NewVersionedCheckpointMigrator("test_plugin", 1, func() checkpointmanager.Checkpoint {
+		return &testCheckpoint{}
+	})
+
+	stateDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(stateDir, testCheckpointName), blob, 0o644))
+	report, err := migrator.Migrate(stateDir, testCheckpointName, false)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	assert.Equal(t, []string{"version"}, report.Added)
+
+	// checkpoints with invalid checksum fail to be verified
+	legacy.Checksum++
+	blob, err = json.Marshal(legacy)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(stateDir, testCheckpointName), blob, 0o644))
+	_, err = migrator.Migrate(stateDir, testCheckpointName, false)
+	assert.Error(t, err)
+}
@@ -93,6 +93,11 @@ func (a *cpuAccumulator) isCoreFree(coreID int) bool {
 	return a.cpuDetails.CPUsInCores(coreID).Size() == a.getTopology().CPUsPerCore()
 }
 
+// isL3CacheFree returns true if the supplied L3 cache is fully available
+func (a *cpuAccumulator) isL3CacheFree(l3CacheID int) bool {
+	return a.cpuDetails.CPUsInL3Caches(l3CacheID).Size() == a.getTopology().CPUDetails.CPUsInL3Caches(l3CacheID).Size()
+}
+
 // freeSockets returns free socket IDs as a slice sorted by sortAvailableSockets().
 func (a *cpuAccumulator) freeSockets() []int {
 	free := []int{}
@@ -148,6 +153,13 @@ func (a *cpuAccumulator) sortAvailableSockets() []int {
 	return sockets
 }
 
+// Sort all L3 caches with free CPUs using the sort() algorithm defined above.
+func (a *cpuAccumulator) sortAvailableL3Caches() []int {
+	l3Caches := a.cpuDetails.L3Caches().ToSliceNoSortInt()
+	a.sort(l3Caches, a.cpuDetails.CPUsInL3Caches)
+	return l3Caches
+}
+
 // Sort all cores with free CPUs:
 // - First by socket using sortAvailableSockets().
 // - Then within each socket, using the sort() algorithm defined above.
@@ -191,6 +203,51 @@ func (a *cpuAccumulator) takeFullSockets() {
 	}
 }
 
+func (a *cpuAccumulator) takeFullL3Caches() {
+	for _, l3Cache := range a.sortAvailableL3Caches() {
+		if !a.isL3CacheFree(l3Cache) {
+			continue
+		}
+		cpusInL3Cache := a.getTopology().CPUDetails.CPUsInL3Caches(l3Cache)
+		if !a.needs(cpusInL3Cache.Size()) {
+			continue
+		}
+		klog.V(4).InfoS("takeFullL3Caches: claiming L3 cache", "l3Cache", l3Cache)
+		a.take(cpusInL3Cache)
+	}
+}
+
+// takePartialL3Cache takes the remaining cpus from the L3 cache with the least
+// free cpus that can still satisfy the requirement (best-fit), preferring whole
+// free cores in it; it takes nothing if no such L3 cache exists.
+func (a *cpuAccumulator) takePartialL3Cache() {
+	for _, l3Cache := range a.sortAvailableL3Caches() {
+		if a.cpuDetails.CPUsInL3Caches(l3Cache).Size() < a.numCPUsNeeded {
+			continue
+		}
+		klog.V(4).InfoS("takePartialL3Cache: claiming cpus in L3 cache", "l3Cache", l3Cache)
+
+		for _, core := range a.cpuDetails.CoresInL3Caches(l3Cache).Filter(a.isCoreFree).ToSliceInt() {
+			if !a.needs(a.getTopology().CPUsPerCore()) {
+				break
+			}
+			a.take(a.cpuDetails.CPUsInCores(core))
+		}
+
+		cores := a.cpuDetails.CoresInL3Caches(l3Cache).ToSliceNoSortInt()
+		a.sort(cores, a.cpuDetails.CPUsInCores)
+		for _, core := range cores {
+			for _, cpu := range a.cpuDetails.CPUsInCores(core).ToSliceInt() {
+				if a.isSatisfied() {
+					return
+				}
+				a.take(machine.NewCPUSet(cpu))
+			}
+		}
+		return
+	}
+}
+
 func (a *cpuAccumulator) takeFullCores() {
 	for _, core := range a.freeCores() {
 		cpusInCore := a.getTopology().CPUDetails.CPUsInCores(core)
@@ -262,6 +319,77 @@ func TakeByTopology(info *machine.KatalystMachineInfo, availableCPUs machine.CPU
 	return machine.NewCPUSet(), fmt.Errorf("failed to allocate cpus")
 }
 
+// TakeByL3Cache tries to allocate those required cpus in as few L3 caches as
+// possible, so that cpus sharing the same L3 cache won't be allocated to
+// different workloads if it can be avoided.
+func TakeByL3Cache(info *machine.KatalystMachineInfo, availableCPUs machine.CPUSet,
+	cpuRequirement int,
+) (machine.CPUSet, error) {
+	acc := newCPUAccumulator(info, availableCPUs, cpuRequirement)
+	if acc.isSatisfied() {
+		return acc.result.Clone(), nil
+	}
+	if acc.isFailed() {
+		return machine.NewCPUSet(), fmt.Errorf("not enough cpus available to satisfy request")
+	}
+
+	// Algorithm: L3-cache-aware best-fit
+	// 1. Acquire whole L3 caches, if available and the container requires at
+	//    least an L3 cache's-worth of CPUs.
+	acc.takeFullL3Caches()
+	if acc.isSatisfied() {
+		return acc.result.Clone(), nil
+	}
+
+	// 2. Acquire the remaining CPUs in the single L3 cache which fits them best.
+	acc.takePartialL3Cache()
+	if acc.isSatisfied() {
+		return acc.result.Clone(), nil
+	}
+
+	// 3. Acquire whole cores and single threads across L3 caches if none of
+	//    them is able to hold the remaining CPUs.
+	acc.takeFullCores()
+	if acc.isSatisfied() {
+		return acc.result.Clone(), nil
+	}
+
+	acc.takeRemainingCPUs()
+	if acc.isSatisfied() {
+		return acc.result.Clone(), nil
+	}
+
+	return machine.NewCPUSet(), fmt.Errorf("failed to allocate cpus")
+}
+
+// TakeByNUMABalanceL3Cache tries to make the allocated cpu spread on different NUMAs
+// as TakeByNUMABalance does, and packs cpus in each NUMA into as few L3 caches as possible,
+// so that whole L3 caches in each NUMA are left for the others (e.g. reclaimed pool).
+func TakeByNUMABalanceL3Cache(info *machine.KatalystMachineInfo, availableCPUs machine.CPUSet,
+	cpuRequirement int,
+) (machine.CPUSet, machine.CPUSet, error) {
+	balancedCPUs, _, err := TakeByNUMABalance(info, availableCPUs, cpuRequirement)
+	if err != nil {
+		return machine.NewCPUSet(), availableCPUs, err
+	}
+
+	result := machine.NewCPUSet()
+	for _, numaID := range info.CPUDetails.NUMANodes().ToSliceInt() {
+		numaCPUs := info.CPUDetails.CPUsInNUMANodes(numaID)
+		quantity := balancedCPUs.Intersection(numaCPUs).Size()
+		if quantity == 0 {
+			continue
+		}
+
+		cset, err := TakeByL3Cache(info, availableCPUs.Intersection(numaCPUs), quantity)
+		if err != nil {
+			return machine.NewCPUSet(), availableCPUs, fmt.Errorf("take %d cpus in NUMA %d failed: %v", quantity, numaID, err)
+		}
+		result = result.Union(cset)
+	}
+	return result, availableCPUs.Difference(result), nil
+}
+
 // TakeByNUMABalance tries to make the allocated cpu spread on different
 // sockets, and it uses cpu Cores as the basic allocation unit
 func TakeByNUMABalance(info *machine.KatalystMachineInfo, availableCPUs machine.CPUSet,
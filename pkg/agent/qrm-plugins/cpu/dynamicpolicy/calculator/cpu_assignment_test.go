@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package calculator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestTakeByL3Cache(t *testing.T) {
+	t.Parallel()
+
+	// 16 cpus with 4 L3 caches, and each L3 cache has 2 cores:
+	// L3 0: 0,1,8,9; L3 1: 2,3,10,11; L3 2: 4,5,12,13; L3 3: 6,7,14,15
+	cpuTopology, err := machine.GenerateDummyCPUTopologyWithL3Caches(16, 1, 2, 2)
+	require.NoError(t, err)
+	machineInfo := &machine.KatalystMachineInfo{CPUTopology: cpuTopology}
+
+	tests := []struct {
+		name          string
+		availableCPUs machine.CPUSet
+		numCPUs       int
+		want          machine.CPUSet
+		wantErr       bool
+	}{
+		{
+			name:          "take a whole L3 cache",
+			availableCPUs: cpuTopology.CPUDetails.CPUs(),
+			numCPUs:       4,
+			want:          machine.NewCPUSet(0, 1, 8, 9),
+		},
+		{
+			name:          "take a whole L3 cache and part of another one",
+			availableCPUs: cpuTopology.CPUDetails.CPUs(),
+			numCPUs:       6,
+			want:          machine.NewCPUSet(0, 1, 8, 9, 2, 10),
+		},
+		{
+			name:          "take the best-fit L3 cache with whole cores",
+			availableCPUs: cpuTopology.CPUDetails.CPUs().Difference(machine.NewCPUSet(0)),
+			numCPUs:       2,
+			want:          machine.NewCPUSet(1, 9),
+		},
+		{
+			name:          "take whole cores and partial cores in the same L3 cache",
+			availableCPUs: cpuTopology.CPUDetails.CPUs().Difference(machine.NewCPUSet(0, 2, 4, 6)),
+			numCPUs:       3,
+			want:          machine.NewCPUSet(1, 8, 9),
+		},
+		{
+			name:          "take cores across L3 caches",
+			availableCPUs: machine.NewCPUSet(1, 9, 2, 10, 4, 12),
+			numCPUs:       6,
+			want:          machine.NewCPUSet(1, 9, 2, 10, 4, 12),
+		},
+		{
+			name:          "not enough cpus",
+			availableCPUs: machine.NewCPUSet(1, 9),
+			numCPUs:       3,
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := TakeByL3Cache(machineInfo, tt.availableCPUs, tt.numCPUs)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tt.want.Equals(got), "want %s, got %s", tt.want.String(), got.String())
+		})
+	}
+}
+
+func TestTakeByNUMABalanceL3Cache(t *testing.T) {
+	t.Parallel()
+
+	// 16 cpus with 2 NUMAs, and each NUMA has 2 L3 caches with 2 cores:
+	// NUMA 0: L3 0: 0,1,8,9; L3 1: 2,3,10,11; NUMA 1: L3 2: 4,5,12,13; L3 3: 6,7,14,15
+	cpuTopology, err := machine.GenerateDummyCPUTopologyWithL3Caches(16, 1, 2, 2)
+	require.NoError(t, err)
+	machineInfo := &machine.KatalystMachineInfo{CPUTopology: cpuTopology}
+
+	tests := []struct {
+		name          string
+		availableCPUs machine.CPUSet
+		numCPUs       int
+		want          machine.CPUSet
+		wantErr       bool
+	}{
+		{
+			name:          "spread on NUMAs and pack in L3 caches",
+			availableCPUs: cpuTopology.CPUDetails.CPUs(),
+			numCPUs:       6,
+			want:          machine.NewCPUSet(0, 1, 8, 9, 4, 12),
+		},
+		{
+			name:          "take the best-fit L3 cache in each NUMA",
+			availableCPUs: cpuTopology.CPUDetails.CPUs().Difference(machine.NewCPUSet(0, 6)),
+			numCPUs:       4,
+			want:          machine.NewCPUSet(1, 9, 7, 15),
+		},
+		{
+			name:          "not enough cpus",
+			availableCPUs: cpuTopology.CPUDetails.CPUs(),
+			numCPUs:       17,
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, remaining, err := TakeByNUMABalanceL3Cache(machineInfo, tt.availableCPUs, tt.numCPUs)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.True(t, got.IsEmpty())
+				assert.True(t, tt.availableCPUs.Equals(remaining))
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tt.want.Equals(got), "want %s, got %s", tt.want.String(), got.String())
+			assert.True(t, tt.availableCPUs.Difference(tt.want).Equals(remaining))
+		})
+	}
+}
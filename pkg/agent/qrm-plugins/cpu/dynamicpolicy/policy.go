@@ -129,6 +129,7 @@ type DynamicPolicy struct {
 	sharedCoresNUMABindingResultAnnotationKey string
 	transitionPeriod                          time.Duration
 	enableMetricPreferredNumaAllocation       bool
+	enableL3CacheAwareAllocation              bool
 
 	reservedReclaimedCPUsSize                 int
 	reservedReclaimedCPUSet                   machine.CPUSet
@@ -153,6 +154,11 @@ func NewDynamicPolicy(agentCtx *agent.GenericContext, conf *config.Configuration
 		Val: cpuconsts.CPUResourcePluginPolicyNameDynamic,
 	})
 
+	if err := commonstate.CheckCheckpointMigration(state.GetCheckpointMigrator(), conf.CheckpointMigrationDryRun,
+		conf.GenericQRMPluginConfiguration.StateFileDirectory, cpuPluginStateFileName); err != nil {
+		return false, agent.ComponentStub{}, err
+	}
+
 	stateImpl, stateErr := state.NewCheckpointState(conf.GenericQRMPluginConfiguration.StateFileDirectory, cpuPluginStateFileName,
 		cpuconsts.CPUResourcePluginPolicyNameDynamic, agentCtx.CPUTopology, conf.SkipCPUStateCorruption, state.GenerateMachineStateFromPodEntries, wrappedEmitter)
 	if stateErr != nil {
@@ -204,6 +210,7 @@ func NewDynamicPolicy(agentCtx *agent.GenericContext, conf *config.Configuration
 		enableCPUAdvisor:                    conf.CPUQRMPluginConfig.EnableCPUAdvisor,
 		getAdviceInterval:                   conf.CPUQRMPluginConfig.GetAdviceInterval,
 		enableMetricPreferredNumaAllocation: conf.CPUQRMPluginConfig.EnableMetricPreferredNumaAllocation,
+		enableL3CacheAwareAllocation:        conf.CPUQRMPluginConfig.EnableL3CacheAwareAllocation,
 		reservedCPUs:                        reservedCPUs,
 		extraStateFileAbsPath:               conf.ExtraStateFileAbsPath,
 		enableSyncingCPUIdle:                conf.CPUQRMPluginConfig.EnableSyncingCPUIdle,
@@ -576,7 +576,11 @@ func (p *DynamicPolicy) allocateNumaBindingCPUs(numCPUs int, hint *pluginapi.Top
 		alignedCPUs = alignedAvailableCPUs.Clone()
 	} else {
 		var err error
-		alignedCPUs, err = calculator.TakeByTopology(p.machineInfo, alignedAvailableCPUs, numCPUs)
+		if p.enableL3CacheAwareAllocation {
+			alignedCPUs, err = calculator.TakeByL3Cache(p.machineInfo, alignedAvailableCPUs, numCPUs)
+		} else {
+			alignedCPUs, err = calculator.TakeByTopology(p.machineInfo, alignedAvailableCPUs, numCPUs)
+		}
 		if err != nil {
 			general.ErrorS(err, "take cpu for NUMA not exclusive binding container failed",
 				"hints", hint.Nodes,
@@ -1669,7 +1673,7 @@ func (p *DynamicPolicy) takeCPUsForPools(poolsQuantityMap map[string]int,
 
 		var err error
 		var cset machine.CPUSet
-		cset, availableCPUs, err = calculator.TakeByNUMABalance(p.machineInfo, availableCPUs, req)
+		cset, availableCPUs, err = p.takeCPUs(availableCPUs, req)
 		if err != nil {
 			return nil, clonedAvailableCPUs, fmt.Errorf("take cpu for pool: %s of req: %d failed with error: %v",
 				poolName, req, err)
@@ -1698,7 +1702,7 @@ func (p *DynamicPolicy) takeCPUsForContainers(containersQuantityMap map[string]m
 
 			var err error
 			var cset machine.CPUSet
-			cset, availableCPUs, err = calculator.TakeByNUMABalance(p.machineInfo, availableCPUs, quantity)
+			cset, availableCPUs, err = p.takeCPUs(availableCPUs, quantity)
 			if err != nil {
 				return nil, clonedAvailableCPUs, fmt.Errorf("take cpu for pod: %s container: %s of req: %d failed with error: %v",
 					podUID, containerName, quantity, err)
@@ -1709,6 +1713,17 @@ func (p *DynamicPolicy) takeCPUsForContainers(containersQuantityMap map[string]m
 	return containersCPUSet, availableCPUs, nil
 }
 
+// takeCPUs allocates cpus spread on different NUMAs, and it packs cpus in each NUMA
+// into as few L3 caches as possible if L3-cache-aware allocation is enabled, so that
+// whole L3 caches are left for the others (e.g. reclaimed pool).
+// the returned value includes the allocated cpuset and remaining available cpuset.
+func (p *DynamicPolicy) takeCPUs(availableCPUs machine.CPUSet, req int) (machine.CPUSet, machine.CPUSet, error) {
+	if p.enableL3CacheAwareAllocation {
+		return calculator.TakeByNUMABalanceL3Cache(p.machineInfo, availableCPUs, req)
+	}
+	return calculator.TakeByNUMABalance(p.machineInfo, availableCPUs, req)
+}
+
 func (p *DynamicPolicy) shouldSharedCoresRampUp(podUID string) bool {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
@@ -21,8 +21,14 @@ import (
 
 	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager"
 	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager/checksum"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
 )
 
+// CPUPluginCheckpointVersion is the schema version of CPUPluginCheckpoint, and it must be increased
+// with a migration registered in checkpointMigrator once the schema is changed.
+const CPUPluginCheckpointVersion = 1
+
 var _ checkpointmanager.Checkpoint = &CPUPluginCheckpoint{}
 
 type CPUPluginCheckpoint struct {
@@ -47,7 +53,7 @@ func (cp *CPUPluginCheckpoint) MarshalCheckpoint() ([]byte, error) {
 	// make sure checksum wasn't set before so it doesn't affect output checksum
 	cp.Checksum = 0
 	cp.Checksum = checksum.New(cp)
-	return json.Marshal(*cp)
+	return json.Marshal(versionedCPUPluginCheckpoint{Version: CPUPluginCheckpointVersion, CPUPluginCheckpoint: cp})
 }
 
 // UnmarshalCheckpoint tries to unmarshal passed bytes to checkpoint
@@ -63,3 +69,20 @@ func (cp *CPUPluginCheckpoint) VerifyChecksum() error {
 	cp.Checksum = ck
 	return err
 }
+
+// versionedCPUPluginCheckpoint records schema version along with the checkpoint; the version is kept
+// out of CPUPluginCheckpoint, so that it doesn't affect checksum of checkpoints in legacy version.
+type versionedCPUPluginCheckpoint struct {
+	Version int `json:"version"`
+	*CPUPluginCheckpoint
+}
+
+// checkpointMigrator is the registry of migrations for CPUPluginCheckpoint
+var checkpointMigrator = commonstate.NewVersionedCheckpointMigrator("cpu_plugin", CPUPluginCheckpointVersion, func() checkpointmanager.Checkpoint {
+	return NewCPUPluginCheckpoint()
+})
+
+// GetCheckpointMigrator returns the registry of migrations for CPUPluginCheckpoint
+func GetCheckpointMigrator() *commonstate.CheckpointMigrator {
+	return checkpointMigrator
+}
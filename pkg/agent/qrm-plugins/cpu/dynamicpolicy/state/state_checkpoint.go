@@ -44,6 +44,7 @@ type stateCheckpoint struct {
 	cache             *cpuPluginState
 	policyName        string
 	checkpointManager checkpointmanager.CheckpointManager
+	stateDir          string
 	checkpointName    string
 	// when we add new properties to checkpoint,
 	// it will cause checkpoint corruption, and we should skip it
@@ -67,6 +68,7 @@ func NewCheckpointState(stateDir, checkpointName, policyName string,
 		cache:                              NewCPUPluginState(topology),
 		policyName:                         policyName,
 		checkpointManager:                  checkpointManager,
+		stateDir:                           stateDir,
 		checkpointName:                     checkpointName,
 		skipStateCorruption:                skipStateCorruption,
 		GenerateMachineStateFromPodEntries: generateMachineStateFunc,
@@ -86,6 +88,12 @@ func (sc *stateCheckpoint) RestoreState(topology *machine.CPUTopology) error {
 	var err error
 	var foundAndSkippedStateCorruption bool
 
+	// migrate checkpoint written by older versions before restoring, and pod entries
+	// are kept as they are, so that running pods won't be reallocated
+	if _, err = checkpointMigrator.Migrate(sc.stateDir, sc.checkpointName, sc.skipStateCorruption); err != nil {
+		return fmt.Errorf("migrate checkpoint failed with error: %v", err)
+	}
+
 	checkpoint := NewCPUPluginCheckpoint()
 	if err = sc.checkpointManager.GetCheckpoint(sc.checkpointName, checkpoint); err != nil {
 		if err == errors.ErrCheckpointNotFound {
@@ -17,11 +17,13 @@ limitations under the License.
 package state
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"math"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -32,6 +34,7 @@ import (
 	"k8s.io/klog/v2"
 	pluginapi "k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
 	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager"
+	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager/checksum"
 	testutil "k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/state/testing"
 
 	"github.com/kubewharf/katalyst-api/pkg/consts"
@@ -1507,6 +1510,89 @@ func TestNewCheckpointState(t *testing.T) {
 	}
 }
 
+func TestNewCheckpointState_MigrateLegacyCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	as := require.New(t)
+
+	testName := "test"
+	podUID := "373d08e4-7a6b-4293-aaaf-b135ff8123bf"
+	cpuTopology, err := machine.GenerateDummyCPUTopology(16, 2, 4)
+	as.Nil(err)
+
+	numaCPUs := cpuTopology.CPUDetails.CPUsInNUMANodes(1)
+	podEntries := PodEntries{
+		podUID: ContainerEntries{
+			testName: &AllocationInfo{
+				AllocationMeta: commonstate.AllocationMeta{
+					PodUid:         podUID,
+					PodNamespace:   testName,
+					PodName:        testName,
+					ContainerName:  testName,
+					ContainerType:  pluginapi.ContainerType_MAIN.String(),
+					ContainerIndex: 0,
+					OwnerPoolName:  commonstate.PoolNameDedicated,
+					Annotations: map[string]string{
+						consts.PodAnnotationQoSLevelKey:                  consts.PodAnnotationQoSLevelDedicatedCores,
+						consts.PodAnnotationMemoryEnhancementNumaBinding: consts.PodAnnotationMemoryEnhancementNumaBindingEnable,
+					},
+					QoSLevel: consts.PodAnnotationQoSLevelDedicatedCores,
+				},
+				AllocationResult:                 numaCPUs.Clone(),
+				OriginalAllocationResult:         numaCPUs.Clone(),
+				TopologyAwareAssignments:         map[int]machine.CPUSet{1: numaCPUs.Clone()},
+				OriginalTopologyAwareAssignments: map[int]machine.CPUSet{1: numaCPUs.Clone()},
+				RequestQuantity:                  float64(numaCPUs.Size()),
+			},
+		},
+	}
+	machineState, err := GenerateMachineStateFromPodEntries(cpuTopology, podEntries)
+	as.Nil(err)
+
+	// write checkpoint in legacy version, which has no schema version
+	legacy := NewCPUPluginCheckpoint()
+	legacy.PolicyName = policyName
+	legacy.MachineState = machineState
+	legacy.PodEntries = podEntries
+	legacy.Checksum = checksum.New(legacy)
+	blob, err := json.Marshal(*legacy)
+	as.Nil(err)
+
+	testingDir := t.TempDir()
+	as.Nil(os.WriteFile(filepath.Join(testingDir, cpuPluginStateFileName), blob, 0o644))
+
+	// dry run reports the migration without touching the checkpoint
+	report, err := GetCheckpointMigrator().DryRun(testingDir, cpuPluginStateFileName)
+	as.Nil(err)
+	as.NotNil(report)
+	as.Equal(commonstate.LegacyCheckpointVersion, report.FromVersion)
+	as.Equal(CPUPluginCheckpointVersion, report.ToVersion)
+	as.Equal([]string{commonstate.CheckpointVersionKey}, report.Added)
+	as.Empty(report.Removed)
+	as.Empty(report.Modified)
+	as.Error(GetCheckpointMigrator().CheckMigrationDryRun(testingDir, cpuPluginStateFileName))
+
+	restoredState, err := NewCheckpointState(testingDir, cpuPluginStateFileName, policyName, cpuTopology, false, GenerateMachineStateFromPodEntries, metrics.DummyMetrics{})
+	as.Nil(err)
+
+	// numa binding of the dedicated_cores pod is kept after migration
+	as.Equal(podEntries, restoredState.GetPodEntries())
+	as.Equal(machineState, restoredState.GetMachineState())
+
+	backup, err := os.ReadFile(filepath.Join(testingDir, cpuPluginStateFileName+".v0.bak"))
+	as.Nil(err)
+	as.Equal(blob, backup)
+
+	migrated, err := os.ReadFile(filepath.Join(testingDir, cpuPluginStateFileName))
+	as.Nil(err)
+	version := struct {
+		Version int `json:"version"`
+	}{}
+	as.Nil(json.Unmarshal(migrated, &version))
+	as.Equal(CPUPluginCheckpointVersion, version.Version)
+	as.Nil(GetCheckpointMigrator().CheckMigrationDryRun(testingDir, cpuPluginStateFileName))
+}
+
 func TestClearState(t *testing.T) {
 	t.Parallel()
 
@@ -97,6 +97,11 @@ func NewNativePolicy(agentCtx *agent.GenericContext, conf *config.Configuration,
 		Val: cpuconsts.CPUResourcePluginPolicyNameNative,
 	})
 
+	if err := commonstate.CheckCheckpointMigration(state.GetCheckpointMigrator(), conf.CheckpointMigrationDryRun,
+		conf.GenericQRMPluginConfiguration.StateFileDirectory, cpuPluginStateFileName); err != nil {
+		return false, agent.ComponentStub{}, err
+	}
+
 	stateImpl, stateErr := state.NewCheckpointState(conf.GenericQRMPluginConfiguration.StateFileDirectory, cpuPluginStateFileName,
 		cpuconsts.CPUResourcePluginPolicyNameNative, agentCtx.CPUTopology, conf.SkipCPUStateCorruption, nativepolicyutil.GenerateMachineStateFromPodEntries, wrappedEmitter)
 	if stateErr != nil {
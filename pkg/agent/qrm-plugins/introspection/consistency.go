@@ -0,0 +1,152 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package introspection
+
+import (
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
+	cpustate "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	memorystate "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/memory/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+// ContainerConsistency compares the cpuset recorded in qrm states
+// with the one actually applied in cgroup for a container.
+type ContainerConsistency struct {
+	PodUID        string `json:"podUID"`
+	PodNamespace  string `json:"podNamespace"`
+	PodName       string `json:"podName"`
+	ContainerName string `json:"containerName"`
+
+	ExpectedCPUs string `json:"expectedCPUs,omitempty"`
+	ActualCPUs   string `json:"actualCPUs,omitempty"`
+	ExpectedMems string `json:"expectedMems,omitempty"`
+	ActualMems   string `json:"actualMems,omitempty"`
+
+	Consistent bool   `json:"consistent"`
+	Message    string `json:"message,omitempty"`
+}
+
+type ConsistencyReport struct {
+	Consistent bool                    `json:"consistent"`
+	Containers []*ContainerConsistency `json:"containers"`
+}
+
+// getConsistencyReport walks through containers recorded by cpu and memory plugins,
+// and compares the cpus/mems in states with cpuset.cpus/cpuset.mems in cgroup.
+func (h *Handler) getConsistencyReport(filter stateFilter) *ConsistencyReport {
+	expected := make(map[string]map[string]*ContainerConsistency)
+	add := func(meta commonstate.AllocationMeta, podUID, containerName string) *ContainerConsistency {
+		if expected[podUID] == nil {
+			expected[podUID] = make(map[string]*ContainerConsistency)
+		}
+		if expected[podUID][containerName] == nil {
+			expected[podUID][containerName] = &ContainerConsistency{
+				PodUID:        podUID,
+				PodNamespace:  meta.PodNamespace,
+				PodName:       meta.PodName,
+				ContainerName: containerName,
+			}
+		}
+		return expected[podUID][containerName]
+	}
+
+	if s, err := cpustate.GetReadonlyState(); err == nil {
+		for podUID, containerEntries := range filterCPUPodEntries(s.GetPodEntries(), filter) {
+			if containerEntries.IsPoolEntry() {
+				continue
+			}
+			for containerName, allocationInfo := range containerEntries {
+				add(allocationInfo.AllocationMeta, podUID, containerName).ExpectedCPUs = allocationInfo.AllocationResult.String()
+			}
+		}
+	}
+
+	if s, err := memorystate.GetReadonlyState(); err == nil {
+		podEntries := filterMemoryPodEntries(s.GetPodResourceEntries()[v1.ResourceMemory], filter)
+		for podUID, containerEntries := range podEntries {
+			for containerName, allocationInfo := range containerEntries {
+				if allocationInfo.NumaAllocationResult.IsEmpty() {
+					continue
+				}
+				add(allocationInfo.AllocationMeta, podUID, containerName).ExpectedMems = allocationInfo.NumaAllocationResult.String()
+			}
+		}
+	}
+
+	report := &ConsistencyReport{Consistent: true, Containers: make([]*ContainerConsistency, 0)}
+	for podUID, containers := range expected {
+		for containerName, cc := range containers {
+			h.checkContainer(podUID, containerName, cc)
+			report.Consistent = report.Consistent && cc.Consistent
+			report.Containers = append(report.Containers, cc)
+		}
+	}
+
+	sort.Slice(report.Containers, func(i, j int) bool {
+		if report.Containers[i].PodUID != report.Containers[j].PodUID {
+			return report.Containers[i].PodUID < report.Containers[j].PodUID
+		}
+		return report.Containers[i].ContainerName < report.Containers[j].ContainerName
+	})
+	return report
+}
+
+func (h *Handler) checkContainer(podUID, containerName string, cc *ContainerConsistency) {
+	containerID, err := h.getContainerID(podUID, containerName)
+	if err != nil {
+		cc.Message = fmt.Sprintf("get container id failed: %v", err)
+		return
+	}
+
+	stats, err := h.getCPUSet(podUID, containerID)
+	if err != nil {
+		cc.Message = fmt.Sprintf("get cpuset of container %v failed: %v", containerID, err)
+		return
+	}
+	cc.ActualCPUs, cc.ActualMems = stats.CPUs, stats.Mems
+
+	var mismatched []string
+	if cc.ExpectedCPUs != "" && !cpusetEqual(cc.ExpectedCPUs, cc.ActualCPUs) {
+		mismatched = append(mismatched, "cpus")
+	}
+	if cc.ExpectedMems != "" && !cpusetEqual(cc.ExpectedMems, cc.ActualMems) {
+		mismatched = append(mismatched, "mems")
+	}
+
+	cc.Consistent = len(mismatched) == 0
+	if !cc.Consistent {
+		cc.Message = fmt.Sprintf("%v mismatched with cgroup", mismatched)
+	}
+}
+
+// cpusetEqual compares two cpuset strings semantically, e.g. "0-2" equals to "0,1,2"
+func cpusetEqual(expected, actual string) bool {
+	e, err := machine.Parse(expected)
+	if err != nil {
+		return false
+	}
+	a, err := machine.Parse(actual)
+	if err != nil {
+		return false
+	}
+	return e.Equals(a)
+}
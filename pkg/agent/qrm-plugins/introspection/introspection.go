@@ -0,0 +1,168 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package introspection exposes the in-memory states of qrm plugins through
+// http endpoints, to make it possible to inspect allocations without reading
+// checkpoint files by hand.
+package introspection
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	cgroupcm "github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
+	cgroupmgr "github.com/kubewharf/katalyst-core/pkg/util/cgroup/manager"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	PathCPUState         = "/qrm/state/cpu"
+	PathMemoryState      = "/qrm/state/memory"
+	PathNetworkState     = "/qrm/state/network"
+	PathStateConsistency = "/qrm/state/consistency"
+
+	QueryParamPodUID = "podUID"
+	QueryParamNUMA   = "numa"
+)
+
+// HTTPHandlerRegister registers handler for the given path, and it's
+// usually implemented by the generic context of katalyst components.
+type HTTPHandlerRegister interface {
+	RegisterHTTPHandler(path string, handler http.Handler) error
+}
+
+// stateFilter is used to select the parts of states that the caller cares about,
+// empty podUID or negative numa means no filtering on the corresponding dimension.
+type stateFilter struct {
+	podUID string
+	numa   int
+}
+
+func (f stateFilter) matchPod(podUID string) bool {
+	return f.podUID == "" || f.podUID == podUID
+}
+
+func (f stateFilter) matchNUMA(numa int) bool {
+	return f.numa < 0 || f.numa == numa
+}
+
+type Handler struct {
+	getContainerID func(podUID, containerName string) (string, error)
+	getCPUSet      func(podUID, containerID string) (*cgroupcm.CPUSetStats, error)
+}
+
+func NewHandler(metaServer *metaserver.MetaServer) *Handler {
+	return &Handler{
+		getContainerID: metaServer.GetContainerID,
+		getCPUSet:      cgroupmgr.GetCPUSetForContainer,
+	}
+}
+
+// Register adds all the introspection paths to the given register.
+func (h *Handler) Register(register HTTPHandlerRegister) error {
+	for path, f := range map[string]http.HandlerFunc{
+		PathCPUState:         h.handleCPUState,
+		PathMemoryState:      h.handleMemoryState,
+		PathNetworkState:     h.handleNetworkState,
+		PathStateConsistency: h.handleConsistency,
+	} {
+		if err := register.RegisterHTTPHandler(path, f); err != nil {
+			return fmt.Errorf("register path %v failed: %v", path, err)
+		}
+	}
+	return nil
+}
+
+func (h *Handler) handleCPUState(w http.ResponseWriter, r *http.Request) {
+	serveJSON(w, r, func(filter stateFilter) (interface{}, error) {
+		return getCPUStateView(filter)
+	})
+}
+
+func (h *Handler) handleMemoryState(w http.ResponseWriter, r *http.Request) {
+	serveJSON(w, r, func(filter stateFilter) (interface{}, error) {
+		return getMemoryStateView(filter)
+	})
+}
+
+func (h *Handler) handleNetworkState(w http.ResponseWriter, r *http.Request) {
+	serveJSON(w, r, func(filter stateFilter) (interface{}, error) {
+		return getNetworkStateView(filter)
+	})
+}
+
+func (h *Handler) handleConsistency(w http.ResponseWriter, r *http.Request) {
+	serveJSON(w, r, func(filter stateFilter) (interface{}, error) {
+		return h.getConsistencyReport(filter), nil
+	})
+}
+
+// parseStateFilter parses query parameters into stateFilter
+func parseStateFilter(r *http.Request) (stateFilter, error) {
+	filter := stateFilter{numa: -1}
+	if r.URL == nil {
+		return filter, nil
+	}
+
+	query := r.URL.Query()
+	filter.podUID = query.Get(QueryParamPodUID)
+	if numaStr := query.Get(QueryParamNUMA); numaStr != "" {
+		numa, err := strconv.Atoi(numaStr)
+		if err != nil || numa < 0 {
+			return filter, fmt.Errorf("invalid %v %q", QueryParamNUMA, numaStr)
+		}
+		filter.numa = numa
+	}
+	return filter, nil
+}
+
+func serveJSON(w http.ResponseWriter, r *http.Request, getter func(filter stateFilter) (interface{}, error)) {
+	if r == nil || r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, _ = fmt.Fprintf(w, "request must be GET")
+		return
+	}
+
+	filter, err := parseStateFilter(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprintf(w, "parse query failed: %v", err)
+		return
+	}
+
+	view, err := getter(filter)
+	if err != nil {
+		general.Errorf("get view for %v failed: %v", r.URL.Path, err)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = fmt.Fprintf(w, "get state failed: %v", err)
+		return
+	}
+
+	bytes, err := json.Marshal(view)
+	if err != nil {
+		general.Errorf("marshal view for %v failed: %v", r.URL.Path, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, "marshal state failed: %v", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(bytes)
+}
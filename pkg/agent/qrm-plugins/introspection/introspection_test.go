@@ -0,0 +1,203 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package introspection
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
+	cpustate "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	cgroupcm "github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+type fakeRegister struct {
+	mux *http.ServeMux
+}
+
+func (f *fakeRegister) RegisterHTTPHandler(path string, handler http.Handler) error {
+	f.mux.Handle(path, handler)
+	return nil
+}
+
+func newCPUAllocation(podUID, containerName string, assignments map[int]machine.CPUSet) *cpustate.AllocationInfo {
+	result := machine.NewCPUSet()
+	for _, cset := range assignments {
+		result = result.Union(cset)
+	}
+	return &cpustate.AllocationInfo{
+		AllocationMeta: commonstate.AllocationMeta{
+			PodUid:        podUID,
+			PodNamespace:  "default",
+			PodName:       podUID,
+			ContainerName: containerName,
+			ContainerType: "MAIN",
+		},
+		AllocationResult:         result,
+		OriginalAllocationResult: result.Clone(),
+		TopologyAwareAssignments: assignments,
+	}
+}
+
+func newTestServer(t *testing.T, actual map[string]string) *httptest.Server {
+	topology, err := machine.GenerateDummyCPUTopology(16, 2, 4)
+	require.NoError(t, err)
+
+	s := cpustate.NewCPUPluginState(topology)
+	s.SetPodEntries(cpustate.PodEntries{
+		"pod-1": cpustate.ContainerEntries{
+			"c1": newCPUAllocation("pod-1", "c1", map[int]machine.CPUSet{0: machine.NewCPUSet(0, 1)}),
+		},
+		"pod-2": cpustate.ContainerEntries{
+			"c1": newCPUAllocation("pod-2", "c1", map[int]machine.CPUSet{1: machine.NewCPUSet(4, 5)}),
+		},
+	})
+	cpustate.SetReadonlyState(s)
+
+	h := &Handler{
+		getContainerID: func(podUID, containerName string) (string, error) {
+			return podUID + "/" + containerName, nil
+		},
+		getCPUSet: func(_, containerID string) (*cgroupcm.CPUSetStats, error) {
+			cpus, ok := actual[containerID]
+			if !ok {
+				return nil, fmt.Errorf("container %v not found", containerID)
+			}
+			return &cgroupcm.CPUSetStats{CPUs: cpus}, nil
+		},
+	}
+
+	register := &fakeRegister{mux: http.NewServeMux()}
+	require.NoError(t, h.Register(register))
+	return httptest.NewServer(register.mux)
+}
+
+func get(t *testing.T, url string, into interface{}) int {
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK && into != nil {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(into))
+	}
+	return resp.StatusCode
+}
+
+func TestCPUStateView(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t, nil)
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantPods   []string
+		wantNUMAs  []int
+	}{
+		{
+			name:       "no filter",
+			wantStatus: http.StatusOK,
+			wantPods:   []string{"pod-1", "pod-2"},
+			wantNUMAs:  []int{0, 1, 2, 3},
+		},
+		{
+			name:       "filter by pod",
+			query:      "?podUID=pod-2",
+			wantStatus: http.StatusOK,
+			wantPods:   []string{"pod-2"},
+			wantNUMAs:  []int{0, 1, 2, 3},
+		},
+		{
+			name:       "filter by numa",
+			query:      "?numa=0",
+			wantStatus: http.StatusOK,
+			wantPods:   []string{"pod-1"},
+			wantNUMAs:  []int{0},
+		},
+		{
+			name:       "invalid numa",
+			query:      "?numa=x",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			view := &CPUStateView{}
+			require.Equal(t, tt.wantStatus, get(t, server.URL+PathCPUState+tt.query, view))
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			pods := make([]string, 0)
+			for podUID := range view.PodEntries {
+				pods = append(pods, podUID)
+			}
+			require.ElementsMatch(t, tt.wantPods, pods)
+
+			numas := make([]int, 0)
+			for numa := range view.MachineState {
+				numas = append(numas, numa)
+			}
+			require.ElementsMatch(t, tt.wantNUMAs, numas)
+		})
+	}
+}
+
+func TestNetworkStateViewUnavailable(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t, nil)
+	defer server.Close()
+
+	require.Equal(t, http.StatusServiceUnavailable, get(t, server.URL+PathNetworkState, nil))
+}
+
+func TestConsistencyReport(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t, map[string]string{
+		"pod-1/c1": "0,1",
+		"pod-2/c1": "4-6",
+	})
+	defer server.Close()
+
+	report := &ConsistencyReport{}
+	require.Equal(t, http.StatusOK, get(t, server.URL+PathStateConsistency, report))
+	require.False(t, report.Consistent)
+	require.Len(t, report.Containers, 2)
+
+	require.Equal(t, "pod-1", report.Containers[0].PodUID)
+	require.True(t, report.Containers[0].Consistent)
+	require.Equal(t, "pod-2", report.Containers[1].PodUID)
+	require.False(t, report.Containers[1].Consistent)
+	require.Equal(t, "4-5", report.Containers[1].ExpectedCPUs)
+	require.Equal(t, "4-6", report.Containers[1].ActualCPUs)
+
+	report = &ConsistencyReport{}
+	require.Equal(t, http.StatusOK, get(t, server.URL+PathStateConsistency+"?podUID=pod-1", report))
+	require.True(t, report.Consistent)
+	require.Len(t, report.Containers, 1)
+}
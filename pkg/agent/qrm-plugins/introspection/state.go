@@ -0,0 +1,203 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package introspection
+
+import (
+	cpustate "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	memorystate "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/memory/dynamicpolicy/state"
+	networkstate "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/network/state"
+)
+
+type CPUStateView struct {
+	MachineState cpustate.NUMANodeMap `json:"machineState"`
+	NUMAHeadroom map[int]float64      `json:"numaHeadroom,omitempty"`
+	PodEntries   cpustate.PodEntries  `json:"podEntries"`
+}
+
+type MemoryStateView struct {
+	MachineState       memorystate.NUMANodeResourcesMap `json:"machineState"`
+	NUMAHeadroom       map[int]int64                    `json:"numaHeadroom,omitempty"`
+	PodResourceEntries memorystate.PodResourceEntries   `json:"podResourceEntries"`
+}
+
+type NetworkStateView struct {
+	MachineState networkstate.NICMap     `json:"machineState"`
+	PodEntries   networkstate.PodEntries `json:"podEntries"`
+}
+
+func getCPUStateView(filter stateFilter) (*CPUStateView, error) {
+	s, err := cpustate.GetReadonlyState()
+	if err != nil {
+		return nil, err
+	}
+
+	machineState := s.GetMachineState()
+	for numa, numaState := range machineState {
+		if !filter.matchNUMA(numa) {
+			delete(machineState, numa)
+			continue
+		}
+		if numaState != nil {
+			numaState.PodEntries = filterCPUPodEntries(numaState.PodEntries, filter)
+		}
+	}
+
+	numaHeadroom := make(map[int]float64)
+	for numa, headroom := range s.GetNUMAHeadroom() {
+		if filter.matchNUMA(numa) {
+			numaHeadroom[numa] = headroom
+		}
+	}
+
+	return &CPUStateView{
+		MachineState: machineState,
+		NUMAHeadroom: numaHeadroom,
+		PodEntries:   filterCPUPodEntries(s.GetPodEntries(), filter),
+	}, nil
+}
+
+func getMemoryStateView(filter stateFilter) (*MemoryStateView, error) {
+	s, err := memorystate.GetReadonlyState()
+	if err != nil {
+		return nil, err
+	}
+
+	machineState := s.GetMachineState()
+	for _, numaMap := range machineState {
+		for numa, numaState := range numaMap {
+			if !filter.matchNUMA(numa) {
+				delete(numaMap, numa)
+				continue
+			}
+			if numaState != nil {
+				numaState.PodEntries = filterMemoryPodEntries(numaState.PodEntries, filter)
+			}
+		}
+	}
+
+	numaHeadroom := make(map[int]int64)
+	for numa, headroom := range s.GetNUMAHeadroom() {
+		if filter.matchNUMA(numa) {
+			numaHeadroom[numa] = headroom
+		}
+	}
+
+	podResourceEntries := s.GetPodResourceEntries()
+	for resourceName, podEntries := range podResourceEntries {
+		podResourceEntries[resourceName] = filterMemoryPodEntries(podEntries, filter)
+	}
+
+	return &MemoryStateView{
+		MachineState:       machineState,
+		NUMAHeadroom:       numaHeadroom,
+		PodResourceEntries: podResourceEntries,
+	}, nil
+}
+
+func getNetworkStateView(filter stateFilter) (*NetworkStateView, error) {
+	s, err := networkstate.GetReadonlyState()
+	if err != nil {
+		return nil, err
+	}
+
+	machineState := s.GetMachineState()
+	for _, nicState := range machineState {
+		if nicState != nil {
+			nicState.PodEntries = filterNetworkPodEntries(nicState.PodEntries, filter)
+		}
+	}
+
+	return &NetworkStateView{
+		MachineState: machineState,
+		PodEntries:   filterNetworkPodEntries(s.GetPodEntries(), filter),
+	}, nil
+}
+
+func filterCPUPodEntries(podEntries cpustate.PodEntries, filter stateFilter) cpustate.PodEntries {
+	filtered := make(cpustate.PodEntries)
+	for podUID, containerEntries := range podEntries {
+		if !filter.matchPod(podUID) {
+			continue
+		}
+
+		for containerName, allocationInfo := range containerEntries {
+			if allocationInfo == nil {
+				continue
+			}
+
+			if filter.numa >= 0 && !allocationInfo.GetAllocationResultNUMASet().Contains(filter.numa) {
+				continue
+			}
+
+			if filtered[podUID] == nil {
+				filtered[podUID] = make(cpustate.ContainerEntries)
+			}
+			filtered[podUID][containerName] = allocationInfo
+		}
+	}
+	return filtered
+}
+
+func filterMemoryPodEntries(podEntries memorystate.PodEntries, filter stateFilter) memorystate.PodEntries {
+	filtered := make(memorystate.PodEntries)
+	for podUID, containerEntries := range podEntries {
+		if !filter.matchPod(podUID) {
+			continue
+		}
+
+		for containerName, allocationInfo := range containerEntries {
+			if allocationInfo == nil {
+				continue
+			}
+
+			if filter.numa >= 0 && !allocationInfo.NumaAllocationResult.Contains(filter.numa) {
+				continue
+			}
+
+			if filtered[podUID] == nil {
+				filtered[podUID] = make(memorystate.ContainerEntries)
+			}
+			filtered[podUID][containerName] = allocationInfo
+		}
+	}
+	return filtered
+}
+
+func filterNetworkPodEntries(podEntries networkstate.PodEntries, filter stateFilter) networkstate.PodEntries {
+	filtered := make(networkstate.PodEntries)
+	for podUID, containerEntries := range podEntries {
+		if !filter.matchPod(podUID) {
+			continue
+		}
+
+		for containerName, allocationInfo := range containerEntries {
+			if allocationInfo == nil {
+				continue
+			}
+
+			if filter.numa >= 0 && !allocationInfo.NumaNodes.Contains(filter.numa) {
+				continue
+			}
+
+			if filtered[podUID] == nil {
+				filtered[podUID] = make(networkstate.ContainerEntries)
+			}
+			filtered[podUID][containerName] = allocationInfo
+		}
+	}
+	return filtered
+}
@@ -35,4 +35,5 @@ const (
 	DropCache                     = MemoryPluginDynamicPolicyName + "_drop_cache"
 	EvictLogCache                 = MemoryPluginDynamicPolicyName + "_evict_log_cache"
 	SetMemCompact                 = MemoryPluginDynamicPolicyName + "_mem_compact"
+	SetSwap                       = MemoryPluginDynamicPolicyName + "_set_swap"
 )
@@ -18,6 +18,7 @@ package dynamicpolicy
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
@@ -29,6 +30,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	pluginapi "k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
 	maputil "k8s.io/kubernetes/pkg/util/maps"
 	"k8s.io/utils/clock"
 
@@ -46,12 +48,14 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/memory/handlers/fragmem"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/memory/handlers/logcache"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/memory/handlers/sockmem"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/memory/handlers/swap"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/util"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/util/reactor"
 	"github.com/kubewharf/katalyst-core/pkg/agent/utilcomponent/featuregatenegotiation"
 	"github.com/kubewharf/katalyst-core/pkg/agent/utilcomponent/periodicalhandler"
 	"github.com/kubewharf/katalyst-core/pkg/config"
 	dynamicconfig "github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/crd"
 	"github.com/kubewharf/katalyst-core/pkg/config/generic"
 	"github.com/kubewharf/katalyst-core/pkg/metaserver"
 	"github.com/kubewharf/katalyst-core/pkg/metrics"
@@ -143,6 +147,7 @@ type DynamicPolicy struct {
 	enableSettingMemoryMigrate bool
 	enableSettingSockMem       bool
 	enableSettingFragMem       bool
+	enableSettingSwap          bool
 	enableMemoryAdvisor        bool
 	getAdviceInterval          time.Duration
 	memoryAdvisorSocketAbsPath string
@@ -179,6 +184,11 @@ func NewDynamicPolicy(agentCtx *agent.GenericContext, conf *config.Configuration
 	resourcesReservedMemory := map[v1.ResourceName]map[int]uint64{
 		v1.ResourceMemory: reservedMemory,
 	}
+	if err := commonstate.CheckCheckpointMigration(state.GetCheckpointMigrator(), conf.CheckpointMigrationDryRun,
+		conf.GenericQRMPluginConfiguration.StateFileDirectory, memoryPluginStateFileName); err != nil {
+		return false, agent.ComponentStub{}, err
+	}
+
 	stateImpl, err := state.NewCheckpointState(conf.GenericQRMPluginConfiguration.StateFileDirectory, memoryPluginStateFileName,
 		memconsts.MemoryResourcePluginPolicyNameDynamic, agentCtx.CPUTopology, agentCtx.MachineInfo, resourcesReservedMemory, conf.SkipMemoryStateCorruption, wrappedEmitter)
 	if err != nil {
@@ -220,6 +230,7 @@ func NewDynamicPolicy(agentCtx *agent.GenericContext, conf *config.Configuration
 		enableSettingMemoryMigrate:  conf.EnableSettingMemoryMigrate,
 		enableSettingSockMem:        conf.EnableSettingSockMem,
 		enableSettingFragMem:        conf.EnableSettingFragMem,
+		enableSettingSwap:           conf.EnableSettingSwap,
 		enableMemoryAdvisor:         conf.EnableMemoryAdvisor,
 		getAdviceInterval:           conf.GetAdviceInterval,
 		memoryAdvisorSocketAbsPath:  conf.MemoryAdvisorSocketAbsPath,
@@ -280,6 +291,8 @@ func NewDynamicPolicy(agentCtx *agent.GenericContext, conf *config.Configuration
 		memoryadvisor.ControlKnobHandlerWithChecker(policyImplement.handleAdvisorMemoryOffloading))
 	memoryadvisor.RegisterControlKnobHandler(memoryadvisor.ControlKnobKeyMemoryNUMAHeadroom,
 		memoryadvisor.ControlKnobHandlerWithChecker(policyImplement.handleAdvisorMemoryNUMAHeadroom))
+	memoryadvisor.RegisterControlKnobHandler(memoryadvisor.ControlKnobKeyMemoryLow,
+		memoryadvisor.ControlKnobHandlerWithChecker(policyImplement.handleAdvisorMemoryLow))
 
 	if policyImplement.enableEvictingLogCache {
 		policyImplement.logCacheEvictionManager = logcache.NewManager(conf, agentCtx.MetaServer)
@@ -294,6 +307,13 @@ func NewDynamicPolicy(agentCtx *agent.GenericContext, conf *config.Configuration
 			))
 	}
 
+	if policyImplement.enableSettingSwap {
+		// swap budgets may be overridden by AdminQoSConfiguration
+		if err := agentCtx.MetaServer.ConfigurationManager.AddConfigWatcher(crd.AdminQoSConfigurationGVR); err != nil {
+			return false, agent.ComponentStub{}, err
+		}
+	}
+
 	return true, &agent.PluginWrapper{GenericPlugin: pluginWrapper}, nil
 }
 
@@ -441,6 +461,15 @@ func (p *DynamicPolicy) Start() (err error) {
 			general.Infof("setFragMem failed, err=%v", err)
 		}
 	}
+	if p.enableSettingSwap {
+		general.Infof("setSwap enabled")
+		err := periodicalhandler.RegisterPeriodicalHandlerWithHealthz(memconsts.SetSwap,
+			general.HealthzCheckStateNotReady, qrm.QRMMemoryPluginPeriodicalHandlerGroupName,
+			swap.SetSwapLimit, 60*time.Second, healthCheckTolerationTimes)
+		if err != nil {
+			general.Infof("setSwap failed, err=%v", err)
+		}
+	}
 
 	go wait.Until(func() {
 		periodicalhandler.ReadyToStartHandlersByGroup(qrm.QRMMemoryPluginPeriodicalHandlerGroupName)
@@ -808,6 +837,28 @@ func (p *DynamicPolicy) GetTopologyAwareResources(_ context.Context,
 		}
 	}
 
+	// huge pages allocated with memory are reported as well
+	for resourceName, podEntries := range p.state.GetPodResourceEntries() {
+		if resourceName == v1.ResourceMemory {
+			continue
+		}
+
+		hugePagesAllocationInfo := podEntries[req.PodUid][req.ContainerName]
+		if hugePagesAllocationInfo == nil {
+			continue
+		}
+
+		hugePagesQuantityList := util.GetTopologyAwareQuantityFromAssignmentsSize(hugePagesAllocationInfo.TopologyAwareAllocations)
+		resp.ContainerTopologyAwareResources.AllocatedResources[string(resourceName)] = &pluginapi.TopologyAwareResource{
+			IsNodeResource:                    false,
+			IsScalarResource:                  true,
+			AggregatedQuantity:                float64(hugePagesAllocationInfo.AggregatedQuantity),
+			OriginalAggregatedQuantity:        float64(hugePagesAllocationInfo.AggregatedQuantity),
+			TopologyAwareQuantityList:         hugePagesQuantityList,
+			OriginalTopologyAwareQuantityList: hugePagesQuantityList,
+		}
+	}
+
 	return resp, nil
 }
 
@@ -818,8 +869,23 @@ func (p *DynamicPolicy) GetTopologyAwareAllocatableResources(context.Context,
 	p.RLock()
 	defer p.RUnlock()
 
-	machineState := p.state.GetMachineState()[v1.ResourceMemory]
+	// memory and huge pages (if configured) are all reported
+	allocatableResources := make(map[string]*pluginapi.AllocatableTopologyAwareResource)
+	for resourceName, machineState := range p.state.GetMachineState() {
+		allocatableResource, err := p.getAllocatableTopologyAwareResource(machineState)
+		if err != nil {
+			return nil, fmt.Errorf("get allocatable %s failed with error: %v", resourceName, err)
+		}
+		allocatableResources[string(resourceName)] = allocatableResource
+	}
 
+	return &pluginapi.GetTopologyAwareAllocatableResourcesResponse{
+		AllocatableResources: allocatableResources,
+	}, nil
+}
+
+// getAllocatableTopologyAwareResource returns allocatable resource as topology aware format for the machine state
+func (p *DynamicPolicy) getAllocatableTopologyAwareResource(machineState state.NUMANodeMap) (*pluginapi.AllocatableTopologyAwareResource, error) {
 	numaNodes := p.topology.CPUDetails.NUMANodes().ToSliceInt()
 	topologyAwareAllocatableQuantityList := make([]*pluginapi.TopologyAwareQuantity, 0, len(machineState))
 	topologyAwareCapacityQuantityList := make([]*pluginapi.TopologyAwareQuantity, 0, len(machineState))
@@ -843,17 +909,13 @@ func (p *DynamicPolicy) GetTopologyAwareAllocatableResources(context.Context,
 		aggregatedCapacityQuantity += numaNodeState.TotalMemSize
 	}
 
-	return &pluginapi.GetTopologyAwareAllocatableResourcesResponse{
-		AllocatableResources: map[string]*pluginapi.AllocatableTopologyAwareResource{
-			string(v1.ResourceMemory): {
-				IsNodeResource:                       false,
-				IsScalarResource:                     true,
-				AggregatedAllocatableQuantity:        float64(aggregatedAllocatableQuantity),
-				TopologyAwareAllocatableQuantityList: topologyAwareAllocatableQuantityList,
-				AggregatedCapacityQuantity:           float64(aggregatedCapacityQuantity),
-				TopologyAwareCapacityQuantityList:    topologyAwareCapacityQuantityList,
-			},
-		},
+	return &pluginapi.AllocatableTopologyAwareResource{
+		IsNodeResource:                       false,
+		IsScalarResource:                     true,
+		AggregatedAllocatableQuantity:        float64(aggregatedAllocatableQuantity),
+		TopologyAwareAllocatableQuantityList: topologyAwareAllocatableQuantityList,
+		AggregatedCapacityQuantity:           float64(aggregatedCapacityQuantity),
+		TopologyAwareCapacityQuantityList:    topologyAwareCapacityQuantityList,
 	}, nil
 }
 
@@ -1215,6 +1277,46 @@ func (p *DynamicPolicy) getPodSpecAggregatedMemoryRequestBytes(podUID string) (u
 	return requestBytes, nil
 }
 
+// getPodAggregatedHugePagesRequestBytes returns huge pages requested by the whole pod keyed by resource name,
+// and they are parsed from the aggregated requests annotation if exists, otherwise from the pod spec
+func (p *DynamicPolicy) getPodAggregatedHugePagesRequestBytes(req *pluginapi.ResourceRequest) map[v1.ResourceName]uint64 {
+	var requests v1.ResourceList
+	if value, ok := req.Annotations[apiconsts.PodAnnotationAggregatedRequestsKey]; ok {
+		if err := json.Unmarshal([]byte(value), &requests); err != nil {
+			general.Errorf("pod: %s/%s parse aggregated requests %s failed with error: %v",
+				req.PodNamespace, req.PodName, value, err)
+			requests = nil
+		}
+	}
+
+	if requests == nil && p.metaServer != nil {
+		pod, err := p.metaServer.GetPod(context.Background(), req.PodUid)
+		if err != nil || pod == nil {
+			general.Warningf("pod: %s/%s get pod failed with error: %v, take it as no huge pages requested",
+				req.PodNamespace, req.PodName, err)
+			return nil
+		}
+
+		requests = make(v1.ResourceList)
+		for _, container := range pod.Spec.Containers {
+			for resourceName, quantity := range container.Resources.Requests {
+				if q, ok := requests[resourceName]; ok {
+					quantity.Add(q)
+				}
+				requests[resourceName] = quantity
+			}
+		}
+	}
+
+	hugePagesRequests := make(map[v1.ResourceName]uint64)
+	for resourceName, quantity := range requests {
+		if v1helper.IsHugePageResourceName(resourceName) && quantity.Value() > 0 {
+			hugePagesRequests[resourceName] = uint64(quantity.Value())
+		}
+	}
+	return hugePagesRequests
+}
+
 func (p *DynamicPolicy) getContainerSpecMemoryRequestBytes(podUID, containerName string) (uint64, error) {
 	container, err := p.metaServer.GetContainerSpec(podUID, containerName)
 	if err != nil {
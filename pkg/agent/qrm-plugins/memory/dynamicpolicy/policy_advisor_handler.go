@@ -24,6 +24,7 @@ import (
 	"os"
 	"path"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/samber/lo"
@@ -893,13 +894,53 @@ func (p *DynamicPolicy) doNumaMemoryBalance(ctx context.Context, advice types.Nu
 	return nil
 }
 
-// handleAdvisorMemoryOffloading handles memory offloading from memory-advisor
-func (p *DynamicPolicy) handleAdvisorMemoryOffloading(_ *config.Configuration,
+// handleAdvisorMemoryLow sets memory.low of the container to protect its memory from
+// being reclaimed, and it's only supported in cgroup v2
+func (p *DynamicPolicy) handleAdvisorMemoryLow(_ *config.Configuration,
 	_ interface{},
 	_ *dynamicconfig.DynamicAgentConfiguration,
 	emitter metrics.MetricEmitter,
 	metaServer *metaserver.MetaServer,
 	entryName, subEntryName string,
+	calculationInfo *advisorsvc.CalculationInfo, _ state.PodResourceEntries,
+) error {
+	if calculationInfo.CgroupPath != "" {
+		return fmt.Errorf("%s is only supported for containers", memoryadvisor.ControlKnobKeyMemoryLow)
+	} else if !common.CheckCgroup2UnifiedMode() {
+		return fmt.Errorf("%s is only supported in cgroup v2", memoryadvisor.ControlKnobKeyMemoryLow)
+	}
+
+	memoryLow := calculationInfo.CalculationResult.Values[string(memoryadvisor.ControlKnobKeyMemoryLow)]
+	memoryLowInt64, err := strconv.ParseInt(memoryLow, 10, 64)
+	if err != nil || memoryLowInt64 < 0 {
+		return fmt.Errorf("parse %s: %s failed with error: %v", memoryadvisor.ControlKnobKeyMemoryLow, memoryLow, err)
+	}
+
+	containerID, err := metaServer.GetContainerID(entryName, subEntryName)
+	if err != nil {
+		return fmt.Errorf("GetContainerID failed with error: %v", err)
+	}
+
+	err = cgroupmgr.ApplyUnifiedDataForContainer(entryName, containerID, common.CgroupSubsysMemory, "memory.low", memoryLow)
+	if err != nil {
+		return fmt.Errorf("apply memory.low for pod: %s container: %s failed with error: %v", entryName, subEntryName, err)
+	}
+
+	_ = emitter.StoreInt64(util.MetricNameMemoryHandleAdvisorMemoryLow, memoryLowInt64,
+		metrics.MetricTypeNameRaw, metrics.ConvertMapToTags(map[string]string{
+			"entryName":    entryName,
+			"subEntryName": subEntryName,
+		})...)
+	return nil
+}
+
+// handleAdvisorMemoryOffloading handles memory offloading from memory-advisor
+func (p *DynamicPolicy) handleAdvisorMemoryOffloading(conf *config.Configuration,
+	_ interface{},
+	dynamicConf *dynamicconfig.DynamicAgentConfiguration,
+	emitter metrics.MetricEmitter,
+	metaServer *metaserver.MetaServer,
+	entryName, subEntryName string,
 	calculationInfo *advisorsvc.CalculationInfo, podResourceEntries state.PodResourceEntries,
 ) error {
 	var absCGPath string
@@ -920,9 +961,11 @@ func (p *DynamicPolicy) handleAdvisorMemoryOffloading(_ *config.Configuration,
 		absCGPath = common.GetAbsCgroupPath(common.CgroupSubsysMemory, calculationInfo.CgroupPath)
 	}
 
-	// set swap max before trigger memory offloading
+	// set swap max before trigger memory offloading, unless swap is limited by the swap budget of its QoS level
 	swapMax := calculationInfo.CalculationResult.Values[string(memoryadvisor.ControlKnobKeySwapMax)]
-	if swapMax == consts.ControlKnobON {
+	if qosLevel, ok := p.getSwapBudgetQoSLevel(conf, dynamicConf, entryName, subEntryName, calculationInfo, podResourceEntries); ok {
+		general.Infof("Skip setting swap max for %s since it's managed by swap budget of %s", absCGPath, qosLevel)
+	} else if swapMax == consts.ControlKnobON {
 		err := cgroupmgr.SetSwapMaxWithAbsolutePathRecursive(absCGPath)
 		if err != nil {
 			general.Infof("Failed to set swap max, err: %v", err)
@@ -971,3 +1014,33 @@ func (p *DynamicPolicy) handleAdvisorMemoryOffloading(_ *config.Configuration,
 		})...)
 	return nil
 }
+
+// getSwapBudgetQoSLevel returns the QoS level of the memory offloading entry, if swap of the
+// QoS level is limited by its swap budget; cgroup level entries are only identified as
+// reclaimed_cores if they are (or are under) the reclaim root cgroups.
+func (p *DynamicPolicy) getSwapBudgetQoSLevel(conf *config.Configuration,
+	dynamicConf *dynamicconfig.DynamicAgentConfiguration,
+	entryName, subEntryName string,
+	calculationInfo *advisorsvc.CalculationInfo, podResourceEntries state.PodResourceEntries,
+) (string, bool) {
+	if !p.enableSettingSwap || conf == nil || dynamicConf == nil {
+		return "", false
+	}
+
+	var qosLevel string
+	if calculationInfo.CgroupPath == "" {
+		allocationInfo := podResourceEntries[v1.ResourceMemory][entryName][subEntryName]
+		if allocationInfo == nil {
+			return "", false
+		}
+		qosLevel = allocationInfo.QoSLevel
+	} else if conf.ReclaimRelativeRootCgroupPath != "" &&
+		strings.HasPrefix(calculationInfo.CgroupPath, conf.ReclaimRelativeRootCgroupPath) {
+		qosLevel = apiconsts.PodAnnotationQoSLevelReclaimedCores
+	} else {
+		return "", false
+	}
+
+	_, ok := dynamicConf.GetDynamicConfiguration().QoSLevelSwapConfigs[apiconsts.QoSLevel(qosLevel)]
+	return qosLevel, ok
+}
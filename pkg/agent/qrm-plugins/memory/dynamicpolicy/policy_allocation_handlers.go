@@ -219,7 +219,20 @@ func (p *DynamicPolicy) numaBindingAllocationHandler(ctx context.Context,
 		allocationInfo.SetSpecifiedNUMABindingNUMAID(req.Hint.Nodes[0])
 	}
 
+	hugePagesAllocationInfos, err := p.calculateHugePagesAllocation(req, qosLevel, result)
+	if err != nil {
+		general.ErrorS(err, "unable to allocate huge pages",
+			"podNamespace", req.PodNamespace,
+			"podName", req.PodName,
+			"containerName", req.ContainerName,
+			"numaAllocationResult", result.String())
+		return nil, err
+	}
+
 	p.state.SetAllocationInfo(v1.ResourceMemory, req.PodUid, req.ContainerName, allocationInfo, persistCheckpoint)
+	for resourceName, hugePagesAllocationInfo := range hugePagesAllocationInfos {
+		p.state.SetAllocationInfo(resourceName, req.PodUid, req.ContainerName, hugePagesAllocationInfo, persistCheckpoint)
+	}
 
 	podResourceEntries = p.state.GetPodResourceEntries()
 	machineState, err = state.GenerateMachineStateFromPodEntries(p.state.GetMachineInfo(), podResourceEntries, p.state.GetReservedMemory())
@@ -620,6 +633,59 @@ func (p *DynamicPolicy) calculateMemoryAllocation(req *pluginapi.ResourceRequest
 	return nil
 }
 
+// calculateHugePagesAllocation calculates huge pages allocations of the pod in the given NUMA nodes, which are the
+// memory allocation result of it, so that huge pages are always aligned with memory (and cpu) of the pod;
+// it also will not store the allocations in states, and the returned allocations are keyed by resource name.
+func (p *DynamicPolicy) calculateHugePagesAllocation(req *pluginapi.ResourceRequest, qosLevel string,
+	numaNodes machine.CPUSet,
+) (map[v1.ResourceName]*state.AllocationInfo, error) {
+	hugePagesReqs := p.getPodAggregatedHugePagesRequestBytes(req)
+	if len(hugePagesReqs) == 0 {
+		return nil, nil
+	}
+
+	podResourceEntries := p.state.GetPodResourceEntries()
+	hugePagesAllocationInfos := make(map[v1.ResourceName]*state.AllocationInfo, len(hugePagesReqs))
+	for resourceName, reqBytes := range hugePagesReqs {
+		// the previous allocation of this container should be released before calculating
+		podEntries := podResourceEntries[resourceName]
+		delete(podEntries[req.PodUid], req.ContainerName)
+
+		hugePagesState, err := state.GenerateResourceStateFromPodEntries(p.state.GetMachineInfo(),
+			podEntries, p.state.GetReservedMemory(), resourceName)
+		if err != nil {
+			return nil, fmt.Errorf("GenerateResourceStateFromPodEntries for %s failed with error: %v", resourceName, err)
+		}
+
+		leftQuantity, err := calculateMemoryInNumaNodes(req, hugePagesState, numaNodes.ToSliceInt(), reqBytes, qosLevel)
+		if err != nil {
+			return nil, fmt.Errorf("calculateMemoryInNumaNodes for %s failed with error: %v", resourceName, err)
+		} else if leftQuantity > 0 {
+			return nil, fmt.Errorf("NUMA nodes: %s can't meet %s request: %d bytes, leftQuantity: %d bytes",
+				numaNodes.String(), resourceName, reqBytes, leftQuantity)
+		}
+
+		topologyAwareAllocations := make(map[int]uint64)
+		result := machine.NewCPUSet()
+		for numaNode, numaNodeState := range hugePagesState {
+			numaAllocationInfo := numaNodeState.PodEntries[req.PodUid][req.ContainerName]
+			if numaAllocationInfo != nil && numaAllocationInfo.AggregatedQuantity > 0 {
+				result.Add(numaNode)
+				topologyAwareAllocations[numaNode] = numaAllocationInfo.AggregatedQuantity
+			}
+		}
+
+		hugePagesAllocationInfos[resourceName] = &state.AllocationInfo{
+			AllocationMeta:           state.GenerateMemoryContainerAllocationMeta(req, qosLevel),
+			AggregatedQuantity:       reqBytes,
+			NumaAllocationResult:     result,
+			TopologyAwareAllocations: topologyAwareAllocations,
+		}
+	}
+
+	return hugePagesAllocationInfos, nil
+}
+
 // calculateExclusiveMemory tries to allocate all memories in the numa list to
 // the given container, and returns the remaining un-satisfied quantity.
 // calculateExclusiveMemory will not store the allocation in states, instead,
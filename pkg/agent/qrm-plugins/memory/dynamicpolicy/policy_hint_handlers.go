@@ -383,6 +383,10 @@ func (p *DynamicPolicy) calculateHints(reqInt uint64,
 		return nil, fmt.Errorf("NUMAsPerSocket failed with error: %v", err)
 	}
 
+	// huge pages must be allocated from the same NUMA nodes as memory,
+	// so NUMA nodes without enough free huge pages can't be hinted
+	hugePagesReqs := p.getPodAggregatedHugePagesRequestBytes(req)
+
 	numaToFreeMemoryBytes := make(map[int]uint64, len(numaNodes))
 
 	for _, nodeID := range numaNodes {
@@ -401,8 +405,8 @@ func (p *DynamicPolicy) calculateHints(reqInt uint64,
 		}
 	}
 
-	general.Infof("calculate hints with req: %d, numaToFreeMemoryBytes: %+v",
-		reqInt, numaToFreeMemoryBytes)
+	general.Infof("calculate hints with req: %d, hugePagesReqs: %+v, numaToFreeMemoryBytes: %+v",
+		reqInt, hugePagesReqs, numaToFreeMemoryBytes)
 
 	numaBound := len(numaNodes)
 	if numaBound > machine.LargeNUMAsPoint {
@@ -431,6 +435,8 @@ func (p *DynamicPolicy) calculateHints(reqInt uint64,
 
 		if freeBytesInMask < reqInt {
 			return
+		} else if !checkHugePagesInNUMANodes(resourcesMachineState, hugePagesReqs, maskBits) {
+			return
 		}
 
 		crossSockets, err := machine.CheckNUMACrossSockets(maskBits, p.topology)
@@ -455,11 +461,37 @@ func (p *DynamicPolicy) calculateHints(reqInt uint64,
 		return nil, errNoAvailableMemoryHints
 	}
 
-	return map[string]*pluginapi.ListOfTopologyHints{
+	hints := map[string]*pluginapi.ListOfTopologyHints{
 		string(v1.ResourceMemory): {
 			Hints: availableNumaHints,
 		},
-	}, nil
+	}
+	for resourceName := range hugePagesReqs {
+		hints[string(resourceName)] = &pluginapi.ListOfTopologyHints{
+			Hints: availableNumaHints,
+		}
+	}
+	return hints, nil
+}
+
+// checkHugePagesInNUMANodes returns true if free huge pages in the given NUMA nodes
+// can satisfy all the huge pages requests
+func checkHugePagesInNUMANodes(resourcesMachineState state.NUMANodeResourcesMap,
+	hugePagesReqs map[v1.ResourceName]uint64, numaNodes []int,
+) bool {
+	for resourceName, reqBytes := range hugePagesReqs {
+		var freeBytes uint64 = 0
+		for _, nodeID := range numaNodes {
+			if numaNodeState := resourcesMachineState[resourceName][nodeID]; numaNodeState != nil {
+				freeBytes += numaNodeState.Free
+			}
+		}
+
+		if freeBytes < reqBytes {
+			return false
+		}
+	}
+	return true
 }
 
 // calculateHints is a helper function to calculate the topology hints
@@ -66,6 +66,7 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/config"
 	configagent "github.com/kubewharf/katalyst-core/pkg/config/agent"
 	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/swap"
 	"github.com/kubewharf/katalyst-core/pkg/config/agent/global"
 	qrmconfig "github.com/kubewharf/katalyst-core/pkg/config/agent/qrm"
 	"github.com/kubewharf/katalyst-core/pkg/config/generic"
@@ -5033,3 +5034,169 @@ func TestSwitchBetweenAPIs(t *testing.T) {
 		})
 	}
 }
+
+func TestSNBMemoryAdmitWithHugePages(t *testing.T) {
+	t.Parallel()
+
+	as := require.New(t)
+
+	tmpDir, err := ioutil.TempDir("", "checkpoint-TestSNBMemoryAdmitWithHugePages")
+	as.Nil(err)
+	defer os.RemoveAll(tmpDir)
+
+	cpuTopology, err := machine.GenerateDummyCPUTopology(16, 2, 2)
+	as.Nil(err)
+
+	machineInfo, err := machine.GenerateDummyMachineInfo(2, 16)
+	as.Nil(err)
+	// only NUMA 1 has 1Gi hugepages-2Mi
+	machineInfo.Topology[1].HugePages = []info.HugePagesInfo{{PageSize: 2048, NumPages: 512}}
+
+	dynamicPolicy, err := getTestDynamicPolicyWithInitialization(cpuTopology, machineInfo, tmpDir)
+	as.Nil(err)
+	dynamicPolicy.podAnnotationKeptKeys = []string{
+		consts.PodAnnotationMemoryEnhancementNumaBinding,
+		consts.PodAnnotationInplaceUpdateResizingKey,
+		consts.PodAnnotationAggregatedRequestsKey,
+	}
+
+	hugePagesResourceName := v1.ResourceName("hugepages-2Mi")
+	testName := "test"
+
+	req := &pluginapi.ResourceRequest{
+		PodUid:         string(uuid.NewUUID()),
+		PodNamespace:   testName,
+		PodName:        testName,
+		ContainerName:  testName,
+		ContainerType:  pluginapi.ContainerType_MAIN,
+		ContainerIndex: 0,
+		ResourceName:   string(v1.ResourceMemory),
+		ResourceRequests: map[string]float64{
+			string(v1.ResourceMemory): 2147483648,
+		},
+		Annotations: map[string]string{
+			consts.PodAnnotationQoSLevelKey:           consts.PodAnnotationQoSLevelSharedCores,
+			consts.PodAnnotationMemoryEnhancementKey:  `{"numa_binding": "true", "numa_exclusive": "false"}`,
+			consts.PodAnnotationAggregatedRequestsKey: `{"memory": 2147483648, "hugepages-2Mi": 536870912}`,
+		},
+		Labels: map[string]string{
+			consts.PodAnnotationQoSLevelKey: consts.PodAnnotationQoSLevelSharedCores,
+		},
+	}
+
+	res, err := dynamicPolicy.GetTopologyHints(context.Background(), req)
+	as.Nil(err)
+	as.NotNil(res)
+	as.NotNil(res.ResourceHints[string(v1.ResourceMemory)])
+	as.NotNil(res.ResourceHints[string(hugePagesResourceName)])
+
+	// only NUMA 1 is able to satisfy the hugepages request
+	hints := res.ResourceHints[string(v1.ResourceMemory)].Hints
+	as.NotZero(len(hints))
+	for _, hint := range hints {
+		as.Contains(hint.Nodes, uint64(1))
+	}
+	as.Equal([]uint64{1}, hints[0].Nodes)
+
+	req.Hint = hints[0]
+	allocationRes, err := dynamicPolicy.Allocate(context.Background(), req)
+	as.Nil(err)
+	as.NotNil(allocationRes.AllocationResult)
+
+	hugePagesAllocationInfo := dynamicPolicy.state.GetAllocationInfo(hugePagesResourceName, req.PodUid, req.ContainerName)
+	as.NotNil(hugePagesAllocationInfo)
+	as.Equal(uint64(536870912), hugePagesAllocationInfo.AggregatedQuantity)
+	as.Equal(map[int]uint64{1: 536870912}, hugePagesAllocationInfo.TopologyAwareAllocations)
+
+	machineState := dynamicPolicy.state.GetMachineState()
+	as.Equal(uint64(536870912), machineState[hugePagesResourceName][1].Free)
+	as.Equal(uint64(536870912), machineState[hugePagesResourceName][1].Allocated)
+
+	allocatable, err := dynamicPolicy.GetTopologyAwareAllocatableResources(context.Background(),
+		&pluginapi.GetTopologyAwareAllocatableResourcesRequest{})
+	as.Nil(err)
+	as.NotNil(allocatable.AllocatableResources[string(hugePagesResourceName)])
+	as.Equal(float64(1073741824), allocatable.AllocatableResources[string(hugePagesResourceName)].AggregatedAllocatableQuantity)
+
+	resources, err := dynamicPolicy.GetTopologyAwareResources(context.Background(), &pluginapi.GetTopologyAwareResourcesRequest{
+		PodUid:        req.PodUid,
+		ContainerName: req.ContainerName,
+	})
+	as.Nil(err)
+	as.NotNil(resources.ContainerTopologyAwareResources.AllocatedResources[string(hugePagesResourceName)])
+	as.Equal(float64(536870912), resources.ContainerTopologyAwareResources.AllocatedResources[string(hugePagesResourceName)].AggregatedQuantity)
+}
+
+func TestGetSwapBudgetQoSLevel(t *testing.T) {
+	t.Parallel()
+
+	conf := config.NewConfiguration()
+	conf.ReclaimRelativeRootCgroupPath = "/kubepods/besteffort"
+	dynamicConf := dynamic.NewDynamicAgentConfiguration()
+	dynamicConfig := dynamicConf.GetDynamicConfiguration()
+	dynamicConfig.QoSLevelSwapConfigs = map[consts.QoSLevel]*swap.SwapConfigDetail{
+		consts.QoSLevelReclaimedCores: {SwapMaxRatio: 0.2},
+	}
+	dynamicConf.SetDynamicConfiguration(dynamicConfig)
+
+	podResourceEntries := state.PodResourceEntries{
+		v1.ResourceMemory: state.PodEntries{
+			"pod1": state.ContainerEntries{"c1": &state.AllocationInfo{
+				AllocationMeta: commonstate.AllocationMeta{QoSLevel: consts.PodAnnotationQoSLevelReclaimedCores},
+			}},
+			"pod2": state.ContainerEntries{"c1": &state.AllocationInfo{
+				AllocationMeta: commonstate.AllocationMeta{QoSLevel: consts.PodAnnotationQoSLevelSharedCores},
+			}},
+		},
+	}
+
+	for _, tc := range []struct {
+		name              string
+		enableSettingSwap bool
+		entryName         string
+		cgroupPath        string
+		wantQoSLevel      string
+		wantManaged       bool
+	}{
+		{
+			name:      "setting swap disabled",
+			entryName: "pod1",
+		},
+		{
+			name:              "container with swap budget",
+			enableSettingSwap: true,
+			entryName:         "pod1",
+			wantQoSLevel:      consts.PodAnnotationQoSLevelReclaimedCores,
+			wantManaged:       true,
+		},
+		{
+			name:              "container without swap budget",
+			enableSettingSwap: true,
+			entryName:         "pod2",
+			wantQoSLevel:      consts.PodAnnotationQoSLevelSharedCores,
+		},
+		{
+			name:              "reclaim root cgroup with swap budget",
+			enableSettingSwap: true,
+			cgroupPath:        "/kubepods/besteffort-0",
+			wantQoSLevel:      consts.PodAnnotationQoSLevelReclaimedCores,
+			wantManaged:       true,
+		},
+		{
+			name:              "other cgroup",
+			enableSettingSwap: true,
+			cgroupPath:        "/kubepods/burstable",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := &DynamicPolicy{enableSettingSwap: tc.enableSettingSwap}
+			qosLevel, managed := p.getSwapBudgetQoSLevel(conf, dynamicConf, tc.entryName, "c1",
+				&advisorsvc.CalculationInfo{CgroupPath: tc.cgroupPath}, podResourceEntries)
+			assert.Equal(t, tc.wantQoSLevel, qosLevel)
+			assert.Equal(t, tc.wantManaged, managed)
+		})
+	}
+}
@@ -21,8 +21,14 @@ import (
 
 	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager"
 	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager/checksum"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
 )
 
+// MemoryPluginCheckpointVersion is the schema version of MemoryPluginCheckpoint, and it must be increased
+// with a migration registered in checkpointMigrator once the schema is changed.
+const MemoryPluginCheckpointVersion = 1
+
 var _ checkpointmanager.Checkpoint = &MemoryPluginCheckpoint{}
 
 type MemoryPluginCheckpoint struct {
@@ -48,7 +54,7 @@ func (cp *MemoryPluginCheckpoint) MarshalCheckpoint() ([]byte, error) {
 	// make sure checksum wasn't set before, so it doesn't affect output checksum
 	cp.Checksum = 0
 	cp.Checksum = checksum.New(cp)
-	return json.Marshal(*cp)
+	return json.Marshal(versionedMemoryPluginCheckpoint{Version: MemoryPluginCheckpointVersion, MemoryPluginCheckpoint: cp})
 }
 
 // UnmarshalCheckpoint tries to unmarshal passed bytes to checkpoint
@@ -64,3 +70,20 @@ func (cp *MemoryPluginCheckpoint) VerifyChecksum() error {
 	cp.Checksum = ck
 	return err
 }
+
+// versionedMemoryPluginCheckpoint records schema version along with the checkpoint; the version is kept
+// out of MemoryPluginCheckpoint, so that it doesn't affect checksum of checkpoints in legacy version.
+type versionedMemoryPluginCheckpoint struct {
+	Version int `json:"version"`
+	*MemoryPluginCheckpoint
+}
+
+// checkpointMigrator is the registry of migrations for MemoryPluginCheckpoint
+var checkpointMigrator = commonstate.NewVersionedCheckpointMigrator("memory_plugin", MemoryPluginCheckpointVersion, func() checkpointmanager.Checkpoint {
+	return NewMemoryPluginCheckpoint()
+})
+
+// GetCheckpointMigrator returns the registry of migrations for MemoryPluginCheckpoint
+func GetCheckpointMigrator() *commonstate.CheckpointMigrator {
+	return checkpointMigrator
+}
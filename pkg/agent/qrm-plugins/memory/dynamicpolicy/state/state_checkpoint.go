@@ -48,6 +48,7 @@ type stateCheckpoint struct {
 	cache             *memoryPluginState
 	policyName        string
 	checkpointManager checkpointmanager.CheckpointManager
+	stateDir          string
 	checkpointName    string
 	// when we add new properties to checkpoint,
 	// it will cause checkpoint corruption and we should skip it
@@ -74,6 +75,7 @@ func NewCheckpointState(stateDir, checkpointName, policyName string,
 		cache:               defaultCache,
 		policyName:          policyName,
 		checkpointManager:   checkpointManager,
+		stateDir:            stateDir,
 		checkpointName:      checkpointName,
 		skipStateCorruption: skipStateCorruption,
 		emitter:             emitter,
@@ -93,6 +95,12 @@ func (sc *stateCheckpoint) restoreState(machineInfo *info.MachineInfo, reservedM
 	var err error
 	var foundAndSkippedStateCorruption bool
 
+	// migrate checkpoint written by older versions before restoring, and pod entries
+	// are kept as they are, so that running pods won't be reallocated
+	if _, err = checkpointMigrator.Migrate(sc.stateDir, sc.checkpointName, sc.skipStateCorruption); err != nil {
+		return fmt.Errorf("migrate checkpoint failed with error: %v", err)
+	}
+
 	checkpoint := NewMemoryPluginCheckpoint()
 	if err = sc.checkpointManager.GetCheckpoint(sc.checkpointName, checkpoint); err != nil {
 		if err == errors.ErrCheckpointNotFound {
@@ -18,11 +18,14 @@ package state
 
 import (
 	"fmt"
+	"sort"
 
 	info "github.com/google/cadvisor/info/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog/v2"
 	pluginapi "k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
 
 	"github.com/kubewharf/katalyst-api/pkg/consts"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
@@ -51,9 +54,8 @@ func GenerateMachineState(machineInfo *info.MachineInfo, reserved map[v1.Resourc
 		return nil, fmt.Errorf("GenerateMachineState got nil machineInfo")
 	}
 
-	// todo: currently only support memory, we will support huge page later.
 	defaultResourcesMachineState := make(NUMANodeResourcesMap)
-	for _, resourceName := range []v1.ResourceName{v1.ResourceMemory} {
+	for _, resourceName := range GetMemoryResourceNames(machineInfo) {
 		machineState, err := GenerateResourceState(machineInfo, reserved, resourceName)
 		if err != nil {
 			return nil, fmt.Errorf("GenerateResourceState for resource: %s failed with error: %v", resourceName, err)
@@ -64,6 +66,50 @@ func GenerateMachineState(machineInfo *info.MachineInfo, reserved map[v1.Resourc
 	return defaultResourcesMachineState, nil
 }
 
+// GetMemoryResourceNames returns memory and huge pages resource names (e.g. hugepages-2Mi)
+// managed by memory plugin, and huge pages are only managed if they are configured in any NUMA
+func GetMemoryResourceNames(machineInfo *info.MachineInfo) []v1.ResourceName {
+	resourceNames := []v1.ResourceName{v1.ResourceMemory}
+	if machineInfo == nil {
+		return resourceNames
+	}
+
+	pageSizes := make(map[uint64]bool)
+	for _, node := range machineInfo.Topology {
+		for _, hugePages := range node.HugePages {
+			if hugePages.NumPages > 0 {
+				pageSizes[hugePages.PageSize] = true
+			}
+		}
+	}
+
+	hugePagesResourceNames := make([]v1.ResourceName, 0, len(pageSizes))
+	for pageSize := range pageSizes {
+		hugePagesResourceNames = append(hugePagesResourceNames, hugePagesResourceName(pageSize))
+	}
+	sort.Slice(hugePagesResourceNames, func(i, j int) bool {
+		return hugePagesResourceNames[i] < hugePagesResourceNames[j]
+	})
+
+	return append(resourceNames, hugePagesResourceNames...)
+}
+
+// hugePagesResourceName returns resource name of huge pages with the given page size in KiB,
+// which is the unit of page size reported by cadvisor
+func hugePagesResourceName(pageSizeKiB uint64) v1.ResourceName {
+	return v1helper.HugePageResourceName(*resource.NewQuantity(int64(pageSizeKiB*1024), resource.BinarySI))
+}
+
+// getNUMAHugePagesBytes returns total bytes of huge pages in the given NUMA
+func getNUMAHugePagesBytes(node info.Node, resourceName v1.ResourceName) uint64 {
+	for _, hugePages := range node.HugePages {
+		if hugePagesResourceName(hugePages.PageSize) == resourceName {
+			return hugePages.NumPages * hugePages.PageSize * 1024
+		}
+	}
+	return 0
+}
+
 // WrapAllocationMetaFilter takes a filter function that operates on
 // AllocationMeta and returns a wrapper function that applies the same filter
 // to an AllocationInfo by extracting its AllocationMeta.
@@ -92,14 +138,18 @@ func GetReclaimedNUMAHeadroom(numaHeadroom map[int]int64, numaSet machine.CPUSet
 func GenerateResourceState(machineInfo *info.MachineInfo, reserved map[v1.ResourceName]map[int]uint64, resourceName v1.ResourceName) (NUMANodeMap, error) {
 	defaultMachineState := make(NUMANodeMap)
 
-	switch resourceName {
-	case v1.ResourceMemory:
+	switch {
+	case resourceName == v1.ResourceMemory || v1helper.IsHugePageResourceName(resourceName):
 		for _, node := range machineInfo.Topology {
 			totalMemSizeQuantity := node.Memory
+			if resourceName != v1.ResourceMemory {
+				totalMemSizeQuantity = getNUMAHugePagesBytes(node, resourceName)
+			}
 			numaReservedMemQuantity := reserved[resourceName][node.Id]
 
 			if totalMemSizeQuantity < numaReservedMemQuantity {
-				return nil, fmt.Errorf("invalid reserved memory: %d in NUMA: %d with total memory size: %d", numaReservedMemQuantity, node.Id, totalMemSizeQuantity)
+				return nil, fmt.Errorf("invalid reserved %s: %d in NUMA: %d with total size: %d",
+					resourceName, numaReservedMemQuantity, node.Id, totalMemSizeQuantity)
 			}
 
 			allocatableQuantity := totalMemSizeQuantity - numaReservedMemQuantity
@@ -130,9 +180,8 @@ func GenerateMachineStateFromPodEntries(machineInfo *info.MachineInfo,
 		return nil, fmt.Errorf("GenerateMachineStateFromPodEntries got nil machineInfo")
 	}
 
-	// todo: currently only support memory, we will support huge page later.
 	defaultResourcesMachineState := make(NUMANodeResourcesMap)
-	for _, resourceName := range []v1.ResourceName{v1.ResourceMemory} {
+	for _, resourceName := range GetMemoryResourceNames(machineInfo) {
 		machineState, err := GenerateResourceStateFromPodEntries(machineInfo, podResourceEntries[resourceName], reserved, resourceName)
 		if err != nil {
 			return nil, fmt.Errorf("GenerateResourceState for resource: %s failed with error: %v", resourceName, err)
@@ -148,9 +197,11 @@ func GenerateMachineStateFromPodEntries(machineInfo *info.MachineInfo,
 func GenerateResourceStateFromPodEntries(machineInfo *info.MachineInfo,
 	podEntries PodEntries, reserved map[v1.ResourceName]map[int]uint64, resourceName v1.ResourceName,
 ) (NUMANodeMap, error) {
-	switch resourceName {
-	case v1.ResourceMemory:
+	switch {
+	case resourceName == v1.ResourceMemory:
 		return GenerateMemoryStateFromPodEntries(machineInfo, podEntries, reserved)
+	case v1helper.IsHugePageResourceName(resourceName):
+		return generateStateFromPodEntries(machineInfo, podEntries, reserved, resourceName)
 	default:
 		return nil, fmt.Errorf("unsupported resource name: %s", resourceName)
 	}
@@ -161,7 +212,15 @@ func GenerateResourceStateFromPodEntries(machineInfo *info.MachineInfo,
 func GenerateMemoryStateFromPodEntries(machineInfo *info.MachineInfo,
 	podEntries PodEntries, reserved map[v1.ResourceName]map[int]uint64,
 ) (NUMANodeMap, error) {
-	machineState, err := GenerateResourceState(machineInfo, reserved, v1.ResourceMemory)
+	return generateStateFromPodEntries(machineInfo, podEntries, reserved, v1.ResourceMemory)
+}
+
+// generateStateFromPodEntries returns NUMANodeMap for memory or huge pages based on
+// machine info and reserved resources along with existed pod entries
+func generateStateFromPodEntries(machineInfo *info.MachineInfo,
+	podEntries PodEntries, reserved map[v1.ResourceName]map[int]uint64, resourceName v1.ResourceName,
+) (NUMANodeMap, error) {
+	machineState, err := GenerateResourceState(machineInfo, reserved, resourceName)
 	if err != nil {
 		return nil, fmt.Errorf("GenerateResourceState failed with error: %v", err)
 	}
@@ -195,9 +254,9 @@ func GenerateMemoryStateFromPodEntries(machineInfo *info.MachineInfo,
 
 		numaNodeState.Allocated = allocatedMemQuantityInNumaNode
 		if numaNodeState.Allocatable < numaNodeState.Allocated {
-			klog.Warningf("[GenerateMemoryStateFromPodEntries] invalid allocated memory: %d in NUMA: %d"+
-				" with allocatable memory size: %d, total memory size: %d, reserved memory size: %d",
-				numaNodeState.Allocated, numaId, numaNodeState.Allocatable, numaNodeState.TotalMemSize, numaNodeState.SystemReserved)
+			klog.Warningf("[GenerateMemoryStateFromPodEntries] invalid allocated %s: %d in NUMA: %d"+
+				" with allocatable size: %d, total size: %d, reserved size: %d",
+				resourceName, numaNodeState.Allocated, numaId, numaNodeState.Allocatable, numaNodeState.TotalMemSize, numaNodeState.SystemReserved)
 			numaNodeState.Allocatable = numaNodeState.Allocated
 		}
 		numaNodeState.Free = numaNodeState.Allocatable - numaNodeState.Allocated
@@ -0,0 +1,134 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"testing"
+
+	info "github.com/google/cadvisor/info/v1"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestGenerateMachineStateWithHugePages(t *testing.T) {
+	t.Parallel()
+
+	const gb = uint64(1 << 30)
+
+	hugePages2Mi := v1.ResourceName("hugepages-2Mi")
+	hugePages1Gi := v1.ResourceName("hugepages-1Gi")
+
+	tests := []struct {
+		name          string
+		machineInfo   *info.MachineInfo
+		reserved      map[v1.ResourceName]map[int]uint64
+		wantResources []v1.ResourceName
+		wantState     map[v1.ResourceName]map[int]uint64 // allocatable keyed by resource and numa
+		wantErr       bool
+	}{
+		{
+			name: "memory only",
+			machineInfo: &info.MachineInfo{
+				Topology: []info.Node{
+					{Id: 0, Memory: 4 * gb},
+					{Id: 1, Memory: 4 * gb},
+				},
+			},
+			wantResources: []v1.ResourceName{v1.ResourceMemory},
+			wantState: map[v1.ResourceName]map[int]uint64{
+				v1.ResourceMemory: {0: 4 * gb, 1: 4 * gb},
+			},
+		},
+		{
+			name: "hugepages on part of numa nodes",
+			machineInfo: &info.MachineInfo{
+				Topology: []info.Node{
+					{
+						Id:     0,
+						Memory: 4 * gb,
+						HugePages: []info.HugePagesInfo{
+							{PageSize: 2048, NumPages: 0},
+							{PageSize: 1048576, NumPages: 1},
+						},
+					},
+					{
+						Id:     1,
+						Memory: 4 * gb,
+						HugePages: []info.HugePagesInfo{
+							{PageSize: 2048, NumPages: 512},
+						},
+					},
+				},
+			},
+			reserved: map[v1.ResourceName]map[int]uint64{
+				v1.ResourceMemory: {0: gb},
+			},
+			wantResources: []v1.ResourceName{v1.ResourceMemory, hugePages1Gi, hugePages2Mi},
+			wantState: map[v1.ResourceName]map[int]uint64{
+				v1.ResourceMemory: {0: 3 * gb, 1: 4 * gb},
+				hugePages1Gi:      {0: gb, 1: 0},
+				hugePages2Mi:      {0: 0, 1: gb},
+			},
+		},
+		{
+			name: "reserved hugepages exceed capacity",
+			machineInfo: &info.MachineInfo{
+				Topology: []info.Node{
+					{
+						Id:     0,
+						Memory: 4 * gb,
+						HugePages: []info.HugePagesInfo{
+							{PageSize: 2048, NumPages: 512},
+						},
+					},
+				},
+			},
+			reserved: map[v1.ResourceName]map[int]uint64{
+				hugePages2Mi: {0: 2 * gb},
+			},
+			wantResources: []v1.ResourceName{v1.ResourceMemory, hugePages2Mi},
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.wantResources, GetMemoryResourceNames(tt.machineInfo))
+
+			machineState, err := GenerateMachineState(tt.machineInfo, tt.reserved)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, machineState, len(tt.wantState))
+
+			for resourceName, numaAllocatable := range tt.wantState {
+				require.Contains(t, machineState, resourceName)
+				for numaID, allocatable := range numaAllocatable {
+					numaState := machineState[resourceName][numaID]
+					require.NotNil(t, numaState)
+					require.Equal(t, allocatable, numaState.Allocatable)
+					require.Equal(t, allocatable, numaState.Free)
+				}
+			}
+		})
+	}
+}
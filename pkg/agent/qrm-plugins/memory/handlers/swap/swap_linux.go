@@ -0,0 +1,189 @@
+//go:build linux
+// +build linux
+
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package swap
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/errors"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	memconsts "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/memory/consts"
+	coreconfig "github.com/kubewharf/katalyst-core/pkg/config"
+	dynamicconfig "github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/swap"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
+	cgroupmgr "github.com/kubewharf/katalyst-core/pkg/util/cgroup/manager"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+)
+
+// calculateSwapLimits returns memory.swap.max and memory.zswap.max by the swap budget,
+// and the node memory capacity is taken as the memory limit if the container is unlimited.
+func calculateSwapLimits(memLimit, memCapacity uint64, detail *swap.SwapConfigDetail) (int64, int64) {
+	limit := general.MinUInt64(memLimit, memCapacity)
+	return int64(float64(limit) * detail.SwapMaxRatio), int64(float64(limit) * detail.ZswapMaxRatio)
+}
+
+// toMemoryDataValue converts zero limit to negative, since zero means unchanged in MemoryData
+func toMemoryDataValue(limit int64) int64 {
+	if limit <= 0 {
+		return -1
+	}
+	return limit
+}
+
+func setContainerSwap(emitter metrics.MetricEmitter, pod *v1.Pod, containerName, containerID string,
+	memCapacity uint64, detail *swap.SwapConfigDetail, qosLevel string,
+) error {
+	relCgroupPath, err := common.GetContainerRelativeCgroupPath(string(pod.UID), containerID)
+	if err != nil {
+		return err
+	}
+	absCgroupPath := common.GetAbsCgroupPath(common.CgroupSubsysMemory, relCgroupPath)
+
+	memStats, err := cgroupmgr.GetMemoryWithAbsolutePath(absCgroupPath)
+	if err != nil {
+		return err
+	}
+
+	swapMax, zswapMax := calculateSwapLimits(memStats.Limit, memCapacity, detail)
+	if swapMax > 0 {
+		// swap is limited by the ancestors as well, so make sure that they won't stop the container from swapping
+		if err := cgroupmgr.SetSwapMaxWithAbsolutePathToParentCgroupRecursive(filepath.Dir(absCgroupPath)); err != nil {
+			return err
+		}
+	}
+
+	data := &common.MemoryData{SwapMaxInBytes: toMemoryDataValue(swapMax)}
+	// zswap is optional in kernel, and it's only limited when supported
+	if general.IsPathExists(filepath.Join(absCgroupPath, "memory.zswap.max")) {
+		data.ZswapMaxInBytes = toMemoryDataValue(zswapMax)
+	}
+	if err := cgroupmgr.ApplyMemoryWithRelativePath(relCgroupPath, data); err != nil {
+		return err
+	}
+
+	tags := metrics.ConvertMapToTags(map[string]string{
+		"namespace":     pod.Namespace,
+		"podName":       pod.Name,
+		"containerName": containerName,
+		"qosLevel":      qosLevel,
+	})
+	_ = emitter.StoreInt64(metricNameSwapMax, swapMax, metrics.MetricTypeNameRaw, tags...)
+	_ = emitter.StoreInt64(metricNameZswapMax, zswapMax, metrics.MetricTypeNameRaw, tags...)
+
+	swapStats, err := cgroupmgr.GetSwapWithAbsolutePath(absCgroupPath)
+	if err != nil {
+		return err
+	}
+	_ = emitter.StoreInt64(metricNameSwapUsage, int64(swapStats.SwapUsage), metrics.MetricTypeNameRaw, tags...)
+	_ = emitter.StoreInt64(metricNameZswapUsage, int64(swapStats.ZswapUsage), metrics.MetricTypeNameRaw, tags...)
+	return nil
+}
+
+/*
+SetSwapLimit limits swap and zswap of containers by the swap budgets of their QoS levels.
+* the budget is a ratio of the container's memory limit, and zero ratio means never swap.
+* containers in the QoS levels without budgets are left untouched.
+* it's only supported under cgroupv2.
+*/
+func SetSwapLimit(conf *coreconfig.Configuration,
+	_ interface{}, dynamicConf *dynamicconfig.DynamicAgentConfiguration,
+	emitter metrics.MetricEmitter, metaServer *metaserver.MetaServer,
+) {
+	general.Infof("called")
+
+	var errList []error
+	defer func() {
+		_ = general.UpdateHealthzStateByError(memconsts.SetSwap, errors.NewAggregate(errList))
+	}()
+
+	if conf == nil {
+		general.Errorf("nil extraConf")
+		return
+	} else if dynamicConf == nil {
+		general.Errorf("nil dynamicConf")
+		return
+	} else if emitter == nil {
+		general.Errorf("nil emitter")
+		return
+	} else if metaServer == nil {
+		general.Errorf("nil metaServer")
+		return
+	}
+
+	if !conf.EnableSettingSwap {
+		general.Infof("SetSwapLimit disabled")
+		return
+	}
+
+	if !common.CheckCgroup2UnifiedMode() {
+		general.Infof("skip SetSwapLimit in cg1 env")
+		return
+	}
+
+	swapConfigs := dynamicConf.GetDynamicConfiguration().QoSLevelSwapConfigs
+	if len(swapConfigs) == 0 {
+		return
+	}
+
+	podList, err := metaServer.GetPodList(context.Background(), native.PodIsActive)
+	if err != nil {
+		errList = append(errList, err)
+		general.Errorf("get pod list failed, err: %v", err)
+		return
+	}
+
+	for _, pod := range podList {
+		if pod == nil {
+			general.Errorf("get nil pod from metaServer")
+			continue
+		}
+
+		qosLevel, err := conf.QoSConfiguration.GetQoSLevelForPod(pod)
+		if err != nil {
+			general.Warningf("GetQoSLevelForPod for pod %s/%s failed: %v", pod.Namespace, pod.Name, err)
+			continue
+		}
+		detail, ok := swapConfigs[apiconsts.QoSLevel(qosLevel)]
+		if !ok {
+			continue
+		}
+
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			containerID := native.TrimContainerIDPrefix(containerStatus.ContainerID)
+			if containerID == "" {
+				continue
+			}
+
+			if err := setContainerSwap(emitter, pod, containerStatus.Name, containerID,
+				metaServer.MemoryCapacity, detail, qosLevel); err != nil {
+				errList = append(errList, fmt.Errorf("set swap for pod %s/%s container %s failed: %v",
+					pod.Namespace, pod.Name, containerStatus.Name, err))
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+//go:build linux
+// +build linux
+
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package swap
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	coreconfig "github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent"
+	dynamicconfig "github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/swap"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/qrm"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+func TestCalculateSwapLimits(t *testing.T) {
+	t.Parallel()
+
+	const gb = uint64(1 << 30)
+
+	tests := []struct {
+		name         string
+		memLimit     uint64
+		memCapacity  uint64
+		detail       *swap.SwapConfigDetail
+		wantSwapMax  int64
+		wantZswapMax int64
+	}{
+		{
+			name:         "limited container",
+			memLimit:     4 * gb,
+			memCapacity:  64 * gb,
+			detail:       &swap.SwapConfigDetail{SwapMaxRatio: 0.5, ZswapMaxRatio: 0.25},
+			wantSwapMax:  int64(2 * gb),
+			wantZswapMax: int64(gb),
+		},
+		{
+			name:         "unlimited container",
+			memLimit:     math.MaxUint64,
+			memCapacity:  64 * gb,
+			detail:       &swap.SwapConfigDetail{SwapMaxRatio: 0.5},
+			wantSwapMax:  int64(32 * gb),
+			wantZswapMax: 0,
+		},
+		{
+			name:        "never swap",
+			memLimit:    4 * gb,
+			memCapacity: 64 * gb,
+			detail:      &swap.SwapConfigDetail{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			swapMax, zswapMax := calculateSwapLimits(tt.memLimit, tt.memCapacity, tt.detail)
+			assert.Equal(t, tt.wantSwapMax, swapMax)
+			assert.Equal(t, tt.wantZswapMax, zswapMax)
+			assert.Equal(t, int64(-1), toMemoryDataValue(0))
+		})
+	}
+}
+
+func TestSetSwapLimit(t *testing.T) {
+	t.Parallel()
+
+	conf := &coreconfig.Configuration{
+		AgentConfiguration: &agent.AgentConfiguration{
+			StaticAgentConfiguration: &agent.StaticAgentConfiguration{
+				QRMPluginsConfiguration: &qrm.QRMPluginsConfiguration{
+					MemoryQRMPluginConfig: &qrm.MemoryQRMPluginConfig{
+						SwapQRMPluginConfig: qrm.SwapQRMPluginConfig{
+							EnableSettingSwap: false,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// neither of them should panic
+	SetSwapLimit(nil, nil, dynamicconfig.NewDynamicAgentConfiguration(), metrics.DummyMetrics{}, nil)
+	SetSwapLimit(conf, nil, nil, metrics.DummyMetrics{}, nil)
+	SetSwapLimit(conf, nil, dynamicconfig.NewDynamicAgentConfiguration(), nil, nil)
+}
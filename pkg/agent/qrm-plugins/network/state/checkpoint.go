@@ -21,8 +21,14 @@ import (
 
 	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager"
 	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager/checksum"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
 )
 
+// NetworkPluginCheckpointVersion is the schema version of NetworkPluginCheckpoint, and it must be increased
+// with a migration registered in checkpointMigrator once the schema is changed.
+const NetworkPluginCheckpointVersion = 1
+
 var _ checkpointmanager.Checkpoint = &NetworkPluginCheckpoint{}
 
 type NetworkPluginCheckpoint struct {
@@ -44,7 +50,7 @@ func (cp *NetworkPluginCheckpoint) MarshalCheckpoint() ([]byte, error) {
 	// make sure checksum wasn't set before, so it doesn't affect output checksum
 	cp.Checksum = 0
 	cp.Checksum = checksum.New(cp)
-	return json.Marshal(*cp)
+	return json.Marshal(versionedNetworkPluginCheckpoint{Version: NetworkPluginCheckpointVersion, NetworkPluginCheckpoint: cp})
 }
 
 // UnmarshalCheckpoint tries to unmarshal passed bytes to checkpoint
@@ -60,3 +66,20 @@ func (cp *NetworkPluginCheckpoint) VerifyChecksum() error {
 	cp.Checksum = ck
 	return err
 }
+
+// versionedNetworkPluginCheckpoint records schema version along with the checkpoint; the version is kept
+// out of NetworkPluginCheckpoint, so that it doesn't affect checksum of checkpoints in legacy version.
+type versionedNetworkPluginCheckpoint struct {
+	Version int `json:"version"`
+	*NetworkPluginCheckpoint
+}
+
+// checkpointMigrator is the registry of migrations for NetworkPluginCheckpoint
+var checkpointMigrator = commonstate.NewVersionedCheckpointMigrator("network_plugin", NetworkPluginCheckpointVersion, func() checkpointmanager.Checkpoint {
+	return NewNetworkPluginCheckpoint()
+})
+
+// GetCheckpointMigrator returns the registry of migrations for NetworkPluginCheckpoint
+func GetCheckpointMigrator() *commonstate.CheckpointMigrator {
+	return checkpointMigrator
+}
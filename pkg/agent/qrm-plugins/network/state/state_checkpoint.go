@@ -51,6 +51,7 @@ type stateCheckpoint struct {
 	cache             *networkPluginState
 	policyName        string
 	checkpointManager checkpointmanager.CheckpointManager
+	stateDir          string
 	checkpointName    string
 	// when we add new properties to checkpoint,
 	// it will cause checkpoint corruption and we should skip it
@@ -76,6 +77,7 @@ func NewCheckpointState(conf *qrm.QRMPluginsConfiguration, stateDir, checkpointN
 		cache:               defaultCache,
 		policyName:          policyName,
 		checkpointManager:   checkpointManager,
+		stateDir:            stateDir,
 		checkpointName:      checkpointName,
 		skipStateCorruption: skipStateCorruption,
 		emitter:             emitter,
@@ -95,6 +97,12 @@ func (sc *stateCheckpoint) restoreState(conf *qrm.QRMPluginsConfiguration, nics
 	var err error
 	var foundAndSkippedStateCorruption bool
 
+	// migrate checkpoint written by older versions before restoring, and pod entries
+	// are kept as they are, so that running pods won't be reallocated
+	if _, err = checkpointMigrator.Migrate(sc.stateDir, sc.checkpointName, sc.skipStateCorruption); err != nil {
+		return fmt.Errorf("migrate checkpoint failed with error: %v", err)
+	}
+
 	checkpoint := NewNetworkPluginCheckpoint()
 	if err = sc.checkpointManager.GetCheckpoint(sc.checkpointName, checkpoint); err != nil {
 		if err == errors.ErrCheckpointNotFound {
@@ -138,12 +138,19 @@ func NewStaticPolicy(agentCtx *agent.GenericContext, conf *config.Configuration,
 		return false, agent.ComponentStub{}, fmt.Errorf("getReservedBandwidth failed with error: %v", err)
 	}
 
+	if err := commonstate.CheckCheckpointMigration(state.GetCheckpointMigrator(), conf.CheckpointMigrationDryRun,
+		conf.GenericQRMPluginConfiguration.StateFileDirectory, NetworkPluginStateFileName); err != nil {
+		return false, agent.ComponentStub{}, err
+	}
+
 	stateImpl, err := state.NewCheckpointState(conf.QRMPluginsConfiguration, conf.GenericQRMPluginConfiguration.StateFileDirectory, NetworkPluginStateFileName,
 		NetworkResourcePluginPolicyNameStatic, agentCtx.MachineInfo, enabledNICs, reservation, conf.SkipNetworkStateCorruption, wrappedEmitter)
 	if err != nil {
 		return false, agent.ComponentStub{}, fmt.Errorf("NewCheckpointState failed with error: %v", err)
 	}
 
+	state.SetReadonlyState(stateImpl)
+
 	policyImplement := &StaticPolicy{
 		nicManager:            nicManager,
 		qosConfig:             conf.QoSConfiguration,
@@ -0,0 +1,230 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthz
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubewharf/katalyst-api/pkg/protocol/reporterplugin/v1alpha1"
+	evictionmemory "github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/plugin/memory"
+	evictionnetwork "github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/plugin/network"
+	evictionresource "github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/plugin/resource"
+	cpuconsts "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/consts"
+	memconsts "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/memory/consts"
+	"github.com/kubewharf/katalyst-core/pkg/agent/resourcemanager/fetcher/plugin"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	"github.com/kubewharf/katalyst-core/pkg/util/process"
+)
+
+const (
+	PluginName = "healthz-reporter-plugin"
+)
+
+// componentChecks maps from agent component to the names of its healthz checks; checks are
+// matched by exact names, since some checks registered by a component reflect the health of
+// another one, e.g. qrm plugins fail communicate_with_advisor checks when sysadvisor is down.
+// checks of shared modules (e.g. metaserver) are not attributed to any component.
+var componentChecks = map[string][]string{
+	consts.AgentComponentQRMCPU: {
+		cpuconsts.ClearResidualState,
+		cpuconsts.CheckCPUSet,
+		cpuconsts.SyncCPUIdle,
+	},
+	consts.AgentComponentQRMMemory: {
+		memconsts.ClearResidualState,
+		memconsts.SyncMemoryStateFromSpec,
+		memconsts.CheckMemSet,
+		memconsts.ApplyExternalCGParams,
+		memconsts.SetExtraControlKnob,
+		memconsts.OOMPriority,
+		memconsts.SetSockMem,
+		memconsts.DropCache,
+		memconsts.EvictLogCache,
+		memconsts.SetMemCompact,
+		memconsts.SetSwap,
+	},
+	consts.AgentComponentSysAdvisor: {
+		"cpu_advisor_update",
+		"memory_advisor_update",
+		"cpu-server-lw",
+		"memory-server-lw",
+		"node-metrics-reporter-plugin",
+		cpuconsts.CommunicateWithAdvisor,
+		memconsts.CommunicateWithAdvisor,
+	},
+	consts.AgentComponentEvictionManager: {
+		"eviction_manager_sync",
+		"eviction_manager_report_taint",
+		evictionmemory.EvictionPluginNameNumaMemoryPressure,
+		evictionmemory.EvictionPluginNameSystemMemoryPressure,
+		evictionnetwork.EvictionPluginNameNetwork,
+		evictionresource.ReclaimedResourcesEvictionPluginName,
+	},
+	consts.AgentComponentReporter: {
+		"reporter_push_content",
+	},
+}
+
+// checkComponents maps from the name of healthz check to its owning component
+var checkComponents = func() map[string]string {
+	components := make(map[string]string)
+	for component, checks := range componentChecks {
+		for _, check := range checks {
+			components[check] = component
+		}
+	}
+	return components
+}()
+
+// healthzPlugin implements the endpoint interface, and it's an in-tree reporter plugin
+// to report the health of each agent component into CNR annotations, so that the
+// agent-healthz controller can handle the failures of components separately.
+type healthzPlugin struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mutex                       sync.Mutex
+	latestReportContentResponse *v1alpha1.GetReportContentResponse
+
+	*process.StopControl
+	emitter metrics.MetricEmitter
+}
+
+func NewHealthzReporterPlugin(emitter metrics.MetricEmitter, _ *metaserver.MetaServer,
+	_ *config.Configuration, _ plugin.ListAndWatchCallback,
+) (plugin.ReporterPlugin, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &healthzPlugin{
+		ctx:         ctx,
+		cancel:      cancel,
+		emitter:     emitter,
+		StopControl: process.NewStopControl(time.Time{}),
+	}, nil
+}
+
+func (p *healthzPlugin) Name() string {
+	return PluginName
+}
+
+func (p *healthzPlugin) Run(success chan<- bool) {
+	success <- true
+	<-p.ctx.Done()
+}
+
+func (p *healthzPlugin) Stop() {
+	p.cancel()
+	p.StopControl.Stop()
+}
+
+func (p *healthzPlugin) GetReportContent(_ context.Context) (*v1alpha1.GetReportContentResponse, error) {
+	healths := getComponentHealths(general.GetRegisterReadinessCheckResult())
+
+	value, err := json.Marshal(healths)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal component healths failed")
+	}
+
+	annotationValue, err := json.Marshal(map[string]string{
+		consts.AgentComponentHealthAnnotationKey: string(value),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal component health annotation failed")
+	}
+
+	resp := &v1alpha1.GetReportContentResponse{
+		Content: []*v1alpha1.ReportContent{
+			{
+				GroupVersionKind: &util.CNRGroupVersionKind,
+				Field: []*v1alpha1.ReportField{
+					{
+						FieldType: v1alpha1.FieldType_Metadata,
+						FieldName: util.CNRFieldNameAnnotations,
+						Value:     annotationValue,
+					},
+				},
+			},
+		},
+	}
+
+	p.setCache(resp)
+	return resp, nil
+}
+
+func (p *healthzPlugin) ListAndWatchReportContentCallback(_ string, _ *v1alpha1.GetReportContentResponse) {
+}
+
+func (p *healthzPlugin) GetCache() *v1alpha1.GetReportContentResponse {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.latestReportContentResponse
+}
+
+func (p *healthzPlugin) setCache(resp *v1alpha1.GetReportContentResponse) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.latestReportContentResponse = resp
+}
+
+// getComponentHealths aggregates the results of healthz checks by components; a component
+// is ready only if all of its checks are ready, and components without any checks are skipped.
+func getComponentHealths(results map[general.HealthzCheckName]general.HealthzCheckResult) map[string]general.ComponentHealth {
+	healths := make(map[string]general.ComponentHealth)
+	notReadyMessages := make(map[string][]string)
+	for name, result := range results {
+		component, ok := getComponentOfCheck(string(name))
+		if !ok {
+			continue
+		}
+
+		if _, exist := healths[component]; !exist {
+			healths[component] = general.ComponentHealth{Ready: true}
+		}
+
+		if !result.Ready {
+			notReadyMessages[component] = append(notReadyMessages[component], fmt.Sprintf("%s: %s", name, result.Message))
+		}
+	}
+
+	for component, messages := range notReadyMessages {
+		sort.Strings(messages)
+		healths[component] = general.ComponentHealth{
+			Ready:   false,
+			Message: strings.Join(messages, "; "),
+		}
+	}
+	return healths
+}
+
+func getComponentOfCheck(name string) (string, bool) {
+	component, ok := checkComponents[name]
+	return component, ok
+}
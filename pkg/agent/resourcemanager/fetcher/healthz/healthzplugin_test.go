@@ -0,0 +1,124 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthz
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	evictionmemory "github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/plugin/memory"
+	cpuconsts "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/consts"
+	memconsts "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/memory/consts"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+func Test_getComponentHealths(t *testing.T) {
+	t.Parallel()
+
+	healths := getComponentHealths(map[general.HealthzCheckName]general.HealthzCheckResult{
+		general.HealthzCheckName(cpuconsts.CheckCPUSet):            {Ready: true},
+		general.HealthzCheckName(cpuconsts.CommunicateWithAdvisor): {Ready: false, Message: "timeout"},
+		"eviction_manager_sync":                                    {Ready: true},
+		"unknown_check":                                            {Ready: false},
+	})
+
+	assert.Equal(t, map[string]general.ComponentHealth{
+		consts.AgentComponentQRMCPU: {Ready: true},
+		consts.AgentComponentSysAdvisor: {
+			Ready:   false,
+			Message: cpuconsts.CommunicateWithAdvisor + ": timeout",
+		},
+		consts.AgentComponentEvictionManager: {Ready: true},
+	}, healths)
+}
+
+func Test_getComponentHealthsWithSysAdvisorFailure(t *testing.T) {
+	t.Parallel()
+
+	// sysadvisor is down, so qrm plugins fail to communicate with it,
+	// but qrm plugins themselves must not be regarded as degraded
+	healths := getComponentHealths(map[general.HealthzCheckName]general.HealthzCheckResult{
+		general.HealthzCheckName(cpuconsts.CheckCPUSet):            {Ready: true},
+		general.HealthzCheckName(cpuconsts.CommunicateWithAdvisor): {Ready: false, Message: "timeout"},
+		general.HealthzCheckName(memconsts.CheckMemSet):            {Ready: true},
+		general.HealthzCheckName(memconsts.CommunicateWithAdvisor): {Ready: false, Message: "timeout"},
+		"cpu_advisor_update": {Ready: false, Message: "stale"},
+		general.HealthzCheckName(evictionmemory.EvictionPluginNameSystemMemoryPressure): {Ready: true},
+		"reporter_push_content": {Ready: true},
+	})
+
+	assert.Equal(t, map[string]general.ComponentHealth{
+		consts.AgentComponentQRMCPU:    {Ready: true},
+		consts.AgentComponentQRMMemory: {Ready: true},
+		consts.AgentComponentSysAdvisor: {
+			Ready: false,
+			Message: "cpu_advisor_update: stale; " + cpuconsts.CommunicateWithAdvisor + ": timeout; " +
+				memconsts.CommunicateWithAdvisor + ": timeout",
+		},
+		consts.AgentComponentEvictionManager: {Ready: true},
+		consts.AgentComponentReporter:        {Ready: true},
+	}, healths)
+}
+
+func Test_healthzPlugin_GetReportContent(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewHealthzReporterPlugin(metrics.DummyMetrics{}, nil, nil, nil)
+	require.NoError(t, err)
+
+	resp, err := p.GetReportContent(context.Background())
+	require.NoError(t, err)
+	require.Len(t, resp.Content, 1)
+	require.Len(t, resp.Content[0].Field, 1)
+	assert.Equal(t, resp, p.GetCache())
+
+	annotations := make(map[string]string)
+	require.NoError(t, json.Unmarshal(resp.Content[0].Field[0].Value, &annotations))
+
+	healths := make(map[string]general.ComponentHealth)
+	assert.NoError(t, json.Unmarshal([]byte(annotations[consts.AgentComponentHealthAnnotationKey]), &healths))
+}
+
+func Test_healthzPlugin_Run(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewHealthzReporterPlugin(metrics.DummyMetrics{}, nil, nil, nil)
+	require.NoError(t, err)
+
+	success := make(chan bool, 1)
+	stopped := make(chan struct{})
+	go func() {
+		p.Run(success)
+		close(stopped)
+	}()
+	assert.True(t, <-success)
+
+	p.Stop()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("plugin doesn't exit after stopped")
+	}
+	assert.True(t, p.IsStopped())
+}
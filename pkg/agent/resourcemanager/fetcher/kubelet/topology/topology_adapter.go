@@ -75,6 +75,12 @@ type topologyAdapterImpl struct {
 	// numaSocketZoneNodeMap map numa zone node => socket zone node
 	numaSocketZoneNodeMap map[util.ZoneNode]util.ZoneNode
 
+	// l3CacheZoneNodeMap map L3 cache zone node => numa or socket zone node
+	l3CacheZoneNodeMap map[util.ZoneNode]util.ZoneNode
+
+	// l3CacheZoneAttributes map L3 cache zone node => attributes of the L3 cache, such as its cpus
+	l3CacheZoneAttributes map[util.ZoneNode]util.ZoneAttributes
+
 	// skipDeviceNames name of devices which will be skipped in getting numa allocatable and allocation
 	skipDeviceNames sets.String
 
@@ -118,6 +124,7 @@ func NewPodResourcesServerTopologyAdapter(metaServer *metaserver.MetaServer, qos
 	}
 
 	numaSocketZoneNodeMap := util.GenerateNumaSocketZone(numaInfo)
+	l3CacheZoneNodeMap, l3CacheZoneAttributes := util.GenerateL3CacheZone(numaInfo)
 	return &topologyAdapterImpl{
 		endpoints:                      endpoints,
 		kubeletResourcePluginPaths:     kubeletResourcePluginPaths,
@@ -125,6 +132,8 @@ func NewPodResourcesServerTopologyAdapter(metaServer *metaserver.MetaServer, qos
 		qosConf:                        qosConf,
 		metaServer:                     metaServer,
 		numaSocketZoneNodeMap:          numaSocketZoneNodeMap,
+		l3CacheZoneNodeMap:             l3CacheZoneNodeMap,
+		l3CacheZoneAttributes:          l3CacheZoneAttributes,
 		skipDeviceNames:                skipDeviceNames,
 		getClientFunc:                  getClientFunc,
 		podResourcesFilter:             podResourcesFilter,
@@ -218,6 +227,11 @@ func (p *topologyAdapterImpl) GetTopologyZones(parentCtx context.Context) ([]*no
 		return nil, errors.Wrap(err, "get device zone topology failed")
 	}
 
+	err = p.addL3CacheZoneNodes(topologyZoneGenerator, zoneAttributes)
+	if err != nil {
+		return nil, errors.Wrap(err, "get L3 cache zone topology failed")
+	}
+
 	return topologyZoneGenerator.GenerateTopologyZoneStatus(zoneAllocations, zoneResources, zoneAttributes, zoneSiblings), nil
 }
 
@@ -376,6 +390,31 @@ func (p *topologyAdapterImpl) addDeviceZoneNodes(generator *util.TopologyZoneGen
 	return nil
 }
 
+// addL3CacheZoneNodes add the L3 cache nodes which are children of numa or socket zone nodes to the generator,
+// and merge attributes of L3 caches into zone attributes
+func (p *topologyAdapterImpl) addL3CacheZoneNodes(generator *util.TopologyZoneGenerator,
+	zoneAttributes map[util.ZoneNode]util.ZoneAttributes,
+) error {
+	var errList []error
+	for l3CacheZoneNode, parentZoneNode := range p.l3CacheZoneNodeMap {
+		parentZoneNode := parentZoneNode
+		err := generator.AddNode(&parentZoneNode, l3CacheZoneNode)
+		if err != nil {
+			errList = append(errList, err)
+			continue
+		}
+
+		zoneAttributes[l3CacheZoneNode] = util.MergeAttributes(zoneAttributes[l3CacheZoneNode],
+			p.l3CacheZoneAttributes[l3CacheZoneNode])
+	}
+
+	if len(errList) > 0 {
+		return utilerrors.NewAggregate(errList)
+	}
+
+	return nil
+}
+
 // getZoneResources gets a map of zone node to zone Resources. The zone node Resources is combined by allocatable
 // device and allocatable resources from pod resources server
 func (p *topologyAdapterImpl) getZoneResources(allocatableResources *podresv1.AllocatableResourcesResponse) (map[util.ZoneNode]nodev1alpha1.Resources, error) {
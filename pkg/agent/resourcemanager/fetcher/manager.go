@@ -38,6 +38,7 @@ import (
 	"github.com/kubewharf/katalyst-api/pkg/plugins/registration"
 	"github.com/kubewharf/katalyst-api/pkg/protocol/reporterplugin/v1alpha1"
 	"github.com/kubewharf/katalyst-core/pkg/agent/resourcemanager/fetcher/checkpoint"
+	"github.com/kubewharf/katalyst-core/pkg/agent/resourcemanager/fetcher/healthz"
 	"github.com/kubewharf/katalyst-core/pkg/agent/resourcemanager/fetcher/kubelet"
 	"github.com/kubewharf/katalyst-core/pkg/agent/resourcemanager/fetcher/plugin"
 	"github.com/kubewharf/katalyst-core/pkg/agent/resourcemanager/fetcher/system"
@@ -126,6 +127,7 @@ func newReporterPluginInitializers() map[string]plugin.InitFunc {
 	innerReporterPluginInitializers := make(map[string]plugin.InitFunc)
 	innerReporterPluginInitializers[system.PluginName] = system.NewSystemReporterPlugin
 	innerReporterPluginInitializers[kubelet.PluginName] = kubelet.NewKubeletReporterPlugin
+	innerReporterPluginInitializers[healthz.PluginName] = healthz.NewHealthzReporterPlugin
 	return innerReporterPluginInitializers
 }
 
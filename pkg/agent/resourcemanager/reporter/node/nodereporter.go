@@ -0,0 +1,288 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	applycorev1 "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+
+	"github.com/kubewharf/katalyst-api/pkg/protocol/reporterplugin/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/agent/resourcemanager/reporter"
+	"github.com/kubewharf/katalyst-core/pkg/client"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util"
+)
+
+const (
+	nodeReporterName = "node-reporter"
+
+	resyncNodeJitterFactor = 0.5
+)
+
+const (
+	metricsNameUpdateNodeCost = "update_node_cost"
+)
+
+// nodeContent is the content of node assembled from report fields
+type nodeContent struct {
+	labels      map[string]string
+	annotations map[string]string
+	capacity    v1.ResourceList
+	allocatable v1.ResourceList
+}
+
+// nodeReporterImpl is to report node labels, annotations and extended resources to remote;
+// it uses server-side apply with its own field manager, so that only the fields reported by
+// itself will be changed, and those fields managed by others (e.g. kubelet) won't be touched.
+// Fields no longer reported will be removed from node since they are no longer owned by reporter.
+type nodeReporterImpl struct {
+	nodeName     string
+	fieldManager string
+	resyncPeriod time.Duration
+
+	// lastAppliedContent is the content applied successfully last time,
+	// and the unchanged parts won't be applied again until the next resync.
+	lastAppliedContent *nodeContent
+	mux                sync.Mutex
+
+	client  kubernetes.Interface
+	emitter metrics.MetricEmitter
+}
+
+// NewNodeReporter create a node reporter
+func NewNodeReporter(genericClient *client.GenericClientSet, _ *metaserver.MetaServer,
+	emitter metrics.MetricEmitter, conf *config.Configuration,
+) (reporter.Reporter, error) {
+	if conf.NodeReporterFieldManager == "" {
+		return nil, fmt.Errorf("field manager of node reporter can't be empty")
+	}
+
+	return &nodeReporterImpl{
+		nodeName:     conf.NodeName,
+		fieldManager: conf.NodeReporterFieldManager,
+		resyncPeriod: conf.NodeReporterResyncPeriod,
+		client:       genericClient.KubeClient,
+		emitter:      emitter,
+	}, nil
+}
+
+// Run start node reporter
+func (n *nodeReporterImpl) Run(ctx context.Context) {
+	if n.resyncPeriod > 0 {
+		go wait.JitterUntilWithContext(ctx, n.resetLastAppliedContent, n.resyncPeriod, resyncNodeJitterFactor, false)
+	}
+	<-ctx.Done()
+}
+
+// Update is to apply node content according to reported fields
+func (n *nodeReporterImpl) Update(ctx context.Context, fields []*v1alpha1.ReportField) error {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+
+	begin := time.Now()
+	defer func() {
+		costs := time.Since(begin)
+		klog.V(4).InfoS("finished update node", "costs", costs)
+		_ = n.emitter.StoreInt64(metricsNameUpdateNodeCost, costs.Microseconds(), metrics.MetricTypeNameRaw)
+	}()
+
+	content, err := parseNodeContent(fields)
+	if err != nil {
+		n.countMetricsWithBaseTags("reporter_update_parse_failed")
+		return err
+	}
+
+	var errList []error
+	if n.lastAppliedContent == nil || nodeMetadataHasChanged(n.lastAppliedContent, content) {
+		if err := n.applyNodeMetadata(ctx, content); err != nil {
+			errList = append(errList, err)
+		}
+	}
+
+	if n.lastAppliedContent == nil || nodeStatusHasChanged(n.lastAppliedContent, content) {
+		if err := n.applyNodeStatus(ctx, content); err != nil {
+			errList = append(errList, err)
+		}
+	}
+
+	if len(errList) > 0 {
+		// make sure the whole content will be applied in the next round
+		n.lastAppliedContent = nil
+		return errors.NewAggregate(errList)
+	}
+
+	n.lastAppliedContent = content
+	return nil
+}
+
+func (n *nodeReporterImpl) applyNodeMetadata(ctx context.Context, content *nodeContent) error {
+	node := applycorev1.Node(n.nodeName).
+		WithLabels(content.labels).
+		WithAnnotations(content.annotations)
+
+	_, err := n.client.CoreV1().Nodes().Apply(ctx, node, metav1.ApplyOptions{FieldManager: n.fieldManager})
+	n.countUpdateMetrics("metadata", err)
+	if err != nil {
+		return fmt.Errorf("apply node %s metadata failed: %v", n.nodeName, err)
+	}
+
+	klog.Infof("apply node %s metadata success, labels: %v, annotations: %v",
+		n.nodeName, content.labels, content.annotations)
+	return nil
+}
+
+func (n *nodeReporterImpl) applyNodeStatus(ctx context.Context, content *nodeContent) error {
+	node := applycorev1.Node(n.nodeName).
+		WithStatus(applycorev1.NodeStatus().
+			WithCapacity(content.capacity).
+			WithAllocatable(content.allocatable))
+
+	_, err := n.client.CoreV1().Nodes().ApplyStatus(ctx, node, metav1.ApplyOptions{FieldManager: n.fieldManager})
+	n.countUpdateMetrics("status", err)
+	if err != nil {
+		return fmt.Errorf("apply node %s status failed: %v", n.nodeName, err)
+	}
+
+	klog.Infof("apply node %s status success, capacity: %v, allocatable: %v",
+		n.nodeName, content.capacity, content.allocatable)
+	return nil
+}
+
+func (n *nodeReporterImpl) resetLastAppliedContent(_ context.Context) {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+
+	n.lastAppliedContent = nil
+}
+
+func (n *nodeReporterImpl) countUpdateMetrics(field string, err error) {
+	status := "success"
+	if err != nil {
+		status = "failed"
+	}
+
+	n.countMetricsWithBaseTags("reporter_update",
+		metrics.ConvertMapToTags(map[string]string{
+			"field":  field,
+			"status": status,
+		})...)
+}
+
+func (n *nodeReporterImpl) countMetricsWithBaseTags(key string, tags ...metrics.MetricTag) {
+	tags = append(tags,
+		metrics.ConvertMapToTags(map[string]string{
+			"reporterName": nodeReporterName,
+		})...)
+
+	_ = n.emitter.StoreInt64(key, 1, metrics.MetricTypeNameCount, tags...)
+}
+
+// parseNodeContent assembles node content from report fields; the fields with the same name
+// reported by different plugins are merged, and the latter one wins if keys conflict.
+func parseNodeContent(fields []*v1alpha1.ReportField) (*nodeContent, error) {
+	content := &nodeContent{
+		labels:      make(map[string]string),
+		annotations: make(map[string]string),
+		capacity:    make(v1.ResourceList),
+		allocatable: make(v1.ResourceList),
+	}
+
+	var errList []error
+	for _, f := range fields {
+		if f == nil {
+			continue
+		}
+
+		var err error
+		switch {
+		case f.FieldType == v1alpha1.FieldType_Metadata && f.FieldName == util.NodeFieldNameLabels:
+			err = mergeStringMap(content.labels, f.Value)
+		case f.FieldType == v1alpha1.FieldType_Metadata && f.FieldName == util.NodeFieldNameAnnotations:
+			err = mergeStringMap(content.annotations, f.Value)
+		case f.FieldType == v1alpha1.FieldType_Status && f.FieldName == util.NodeFieldNameCapacity:
+			err = mergeExtendedResources(content.capacity, f.Value)
+		case f.FieldType == v1alpha1.FieldType_Status && f.FieldName == util.NodeFieldNameAllocatable:
+			err = mergeExtendedResources(content.allocatable, f.Value)
+		default:
+			err = fmt.Errorf("not support field %s/%s for node", f.FieldType, f.FieldName)
+		}
+
+		if err != nil {
+			errList = append(errList, fmt.Errorf("parse field %s/%s failed: %v", f.FieldType, f.FieldName, err))
+		}
+	}
+
+	if len(errList) > 0 {
+		return nil, errors.NewAggregate(errList)
+	}
+
+	return content, nil
+}
+
+func mergeStringMap(dst map[string]string, value []byte) error {
+	src := make(map[string]string)
+	if err := json.Unmarshal(value, &src); err != nil {
+		return err
+	}
+
+	for k, v := range src {
+		dst[k] = v
+	}
+	return nil
+}
+
+// mergeExtendedResources only accepts extended resources, since native resources
+// are always managed by kubelet.
+func mergeExtendedResources(dst v1.ResourceList, value []byte) error {
+	src := make(v1.ResourceList)
+	if err := json.Unmarshal(value, &src); err != nil {
+		return err
+	}
+
+	for name, quantity := range src {
+		if !v1helper.IsExtendedResourceName(name) {
+			return fmt.Errorf("resource %s is not an extended resource", name)
+		}
+		dst[name] = quantity
+	}
+	return nil
+}
+
+func nodeMetadataHasChanged(origin, current *nodeContent) bool {
+	return !apiequality.Semantic.DeepEqual(origin.labels, current.labels) ||
+		!apiequality.Semantic.DeepEqual(origin.annotations, current.annotations)
+}
+
+func nodeStatusHasChanged(origin, current *nodeContent) bool {
+	return !apiequality.Semantic.DeepEqual(origin.capacity, current.capacity) ||
+		!apiequality.Semantic.DeepEqual(origin.allocatable, current.allocatable)
+}
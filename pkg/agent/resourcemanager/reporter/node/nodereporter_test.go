@@ -0,0 +1,251 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+
+	"github.com/kubewharf/katalyst-api/pkg/protocol/reporterplugin/v1alpha1"
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
+	"github.com/kubewharf/katalyst-core/pkg/client"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util"
+)
+
+const (
+	nodeName = "test-node"
+)
+
+type appliedPatch struct {
+	subresource string
+	node        v1.Node
+}
+
+// fakeApplyRecorder records the server-side apply patches, since they are not supported by the fake tracker
+type fakeApplyRecorder struct {
+	mux     sync.Mutex
+	patches []appliedPatch
+}
+
+func (r *fakeApplyRecorder) react(action core.Action) (bool, runtime.Object, error) {
+	patchAction := action.(core.PatchAction)
+	if patchAction.GetPatchType() != types.ApplyPatchType {
+		return false, nil, nil
+	}
+
+	node := v1.Node{}
+	if err := json.Unmarshal(patchAction.GetPatch(), &node); err != nil {
+		return true, nil, err
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.patches = append(r.patches, appliedPatch{subresource: patchAction.GetSubresource(), node: node})
+	return true, &node, nil
+}
+
+func (r *fakeApplyRecorder) popPatches() []appliedPatch {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	patches := r.patches
+	r.patches = nil
+	return patches
+}
+
+func newTestNodeReporter(t *testing.T) (*nodeReporterImpl, *fakeApplyRecorder) {
+	conf, err := options.NewOptions().Config()
+	require.NoError(t, err)
+	conf.NodeName = nodeName
+
+	recorder := &fakeApplyRecorder{}
+	kubeClient := fake.NewSimpleClientset()
+	kubeClient.PrependReactor("patch", "nodes", recorder.react)
+
+	r, err := NewNodeReporter(&client.GenericClientSet{KubeClient: kubeClient}, nil, metrics.DummyMetrics{}, conf)
+	require.NoError(t, err)
+	return r.(*nodeReporterImpl), recorder
+}
+
+func mustMarshal(v interface{}) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestParseNodeContent(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name    string
+		fields  []*v1alpha1.ReportField
+		want    *nodeContent
+		wantErr bool
+	}{
+		{
+			name: "merge fields from multiple plugins",
+			fields: []*v1alpha1.ReportField{
+				{
+					FieldType: v1alpha1.FieldType_Metadata,
+					FieldName: util.NodeFieldNameLabels,
+					Value:     mustMarshal(map[string]string{"a": "1", "b": "1"}),
+				},
+				{
+					FieldType: v1alpha1.FieldType_Metadata,
+					FieldName: util.NodeFieldNameLabels,
+					Value:     mustMarshal(map[string]string{"b": "2"}),
+				},
+				{
+					FieldType: v1alpha1.FieldType_Metadata,
+					FieldName: util.NodeFieldNameAnnotations,
+					Value:     mustMarshal(map[string]string{"c": "3"}),
+				},
+				{
+					FieldType: v1alpha1.FieldType_Status,
+					FieldName: util.NodeFieldNameCapacity,
+					Value:     mustMarshal(v1.ResourceList{"example.com/gpu": resource.MustParse("4")}),
+				},
+				{
+					FieldType: v1alpha1.FieldType_Status,
+					FieldName: util.NodeFieldNameAllocatable,
+					Value:     mustMarshal(v1.ResourceList{"example.com/gpu": resource.MustParse("3")}),
+				},
+			},
+			want: &nodeContent{
+				labels:      map[string]string{"a": "1", "b": "2"},
+				annotations: map[string]string{"c": "3"},
+				capacity:    v1.ResourceList{"example.com/gpu": resource.MustParse("4")},
+				allocatable: v1.ResourceList{"example.com/gpu": resource.MustParse("3")},
+			},
+		},
+		{
+			name: "native resources are not allowed",
+			fields: []*v1alpha1.ReportField{
+				{
+					FieldType: v1alpha1.FieldType_Status,
+					FieldName: util.NodeFieldNameCapacity,
+					Value:     mustMarshal(v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")}),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "labels reported as spec field",
+			fields: []*v1alpha1.ReportField{
+				{
+					FieldType: v1alpha1.FieldType_Spec,
+					FieldName: util.NodeFieldNameLabels,
+					Value:     mustMarshal(map[string]string{"a": "1"}),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid value",
+			fields: []*v1alpha1.ReportField{
+				{
+					FieldType: v1alpha1.FieldType_Metadata,
+					FieldName: util.NodeFieldNameAnnotations,
+					Value:     []byte("invalid"),
+				},
+			},
+			wantErr: true,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseNodeContent(tt.fields)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNodeReporterUpdate(t *testing.T) {
+	t.Parallel()
+
+	r, recorder := newTestNodeReporter(t)
+	ctx := context.TODO()
+
+	fields := []*v1alpha1.ReportField{
+		{
+			FieldType: v1alpha1.FieldType_Metadata,
+			FieldName: util.NodeFieldNameLabels,
+			Value:     mustMarshal(map[string]string{"a": "1"}),
+		},
+		{
+			FieldType: v1alpha1.FieldType_Status,
+			FieldName: util.NodeFieldNameCapacity,
+			Value:     mustMarshal(v1.ResourceList{"example.com/gpu": resource.MustParse("4")}),
+		},
+	}
+
+	// the first update applies both metadata and status
+	require.NoError(t, r.Update(ctx, fields))
+	patches := recorder.popPatches()
+	require.Len(t, patches, 2)
+	assert.Equal(t, "", patches[0].subresource)
+	assert.Equal(t, nodeName, patches[0].node.Name)
+	assert.Equal(t, map[string]string{"a": "1"}, patches[0].node.Labels)
+	assert.Equal(t, "status", patches[1].subresource)
+	assert.Equal(t, v1.ResourceList{"example.com/gpu": resource.MustParse("4")}, patches[1].node.Status.Capacity)
+
+	// unchanged content won't be applied again
+	require.NoError(t, r.Update(ctx, fields))
+	assert.Len(t, recorder.popPatches(), 0)
+
+	// only the changed part is applied, and unreported labels are dropped from the applied configuration
+	fields[0].Value = mustMarshal(map[string]string{"b": "2"})
+	require.NoError(t, r.Update(ctx, fields))
+	patches = recorder.popPatches()
+	require.Len(t, patches, 1)
+	assert.Equal(t, map[string]string{"b": "2"}, patches[0].node.Labels)
+
+	// all contents are re-applied after resync
+	r.resetLastAppliedContent(ctx)
+	require.NoError(t, r.Update(ctx, fields))
+	assert.Len(t, recorder.popPatches(), 2)
+
+	// invalid fields won't be applied
+	fields = append(fields, &v1alpha1.ReportField{
+		FieldType: v1alpha1.FieldType_Status,
+		FieldName: util.NodeFieldNameAllocatable,
+		Value:     mustMarshal(v1.ResourceList{v1.ResourceMemory: resource.MustParse("1Gi")}),
+	})
+	assert.Error(t, r.Update(ctx, fields))
+	assert.Len(t, recorder.popPatches(), 0)
+}
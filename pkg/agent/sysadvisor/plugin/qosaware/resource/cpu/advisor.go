@@ -39,6 +39,7 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region/headroompolicy"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region/provisionpolicy"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/trace"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
 	"github.com/kubewharf/katalyst-core/pkg/config"
 	"github.com/kubewharf/katalyst-core/pkg/metaserver"
@@ -195,17 +196,26 @@ func (cra *cpuResourceAdvisor) UpdateAndGetAdvice() (interface{}, error) {
 
 // update works in a monolithic way to maintain lifecycle and triggers update actions for all regions;
 // todo: re-consider whether it's efficient or we should make start individual goroutine for each region
-func (cra *cpuResourceAdvisor) update() (*types.InternalCPUCalculationResult, error) {
+func (cra *cpuResourceAdvisor) update() (result *types.InternalCPUCalculationResult, err error) {
 	startTime := time.Now()
 	cra.mutex.Lock()
 	general.InfoS("acquired lock", "duration", time.Since(startTime))
 	defer cra.mutex.Unlock()
 
-	result, err := cra.updateWithIsolationGuardian(true)
+	cpuTrace := &trace.CPUTrace{}
+	defer func() {
+		trace.GetDefaultRecorder().Record(types.QoSResourceCPU, startTime, err, func(t *trace.Trace) {
+			cpuTrace.Result = result
+			t.CPU = cpuTrace
+		})
+	}()
+
+	result, err = cra.updateWithIsolationGuardian(true, cpuTrace)
 	if err != nil {
 		if err == errIsolationSafetyCheckFailed {
 			klog.Warningf("[qosaware-cpu] failed to updateWithIsolationGuardian(true): %q", err)
-			return cra.updateWithIsolationGuardian(false)
+			cpuTrace.IsolationSafetyCheckFailed = true
+			return cra.updateWithIsolationGuardian(false, cpuTrace)
 		}
 		return nil, err
 	}
@@ -215,7 +225,7 @@ func (cra *cpuResourceAdvisor) update() (*types.InternalCPUCalculationResult, er
 
 // If updateWithIsolationGuardian fails with isolation enabled, we should try again with isolation disabled.
 // todo: we should re-design the mechanism of isolation instead of disabling this functionality
-func (cra *cpuResourceAdvisor) updateWithIsolationGuardian(tryIsolation bool) (
+func (cra *cpuResourceAdvisor) updateWithIsolationGuardian(tryIsolation bool, cpuTrace *trace.CPUTrace) (
 	*types.InternalCPUCalculationResult,
 	error,
 ) {
@@ -234,7 +244,10 @@ func (cra *cpuResourceAdvisor) updateWithIsolationGuardian(tryIsolation bool) (
 	}
 
 	cra.updateNumasAvailableResource()
-	isolationExists := cra.setIsolatedContainers(tryIsolation)
+	isolatedPods := cra.setIsolatedContainers(tryIsolation)
+	isolationExists := len(isolatedPods) > 0
+	cpuTrace.IsolationTried = tryIsolation
+	cpuTrace.IsolatedPods = isolatedPods
 
 	// assign containers to regions
 	if err := cra.assignContainersToRegions(); err != nil {
@@ -250,8 +263,9 @@ func (cra *cpuResourceAdvisor) updateWithIsolationGuardian(tryIsolation bool) (
 	}
 
 	// run an episode of provision and headroom policy update for each region
+	regionEssentials := make(map[string]types.ResourceEssentials, len(cra.regionMap))
 	for _, r := range cra.regionMap {
-		r.SetEssentials(types.ResourceEssentials{
+		essentials := types.ResourceEssentials{
 			EnableReclaim:       cra.conf.GetDynamicConfiguration().EnableReclaim,
 			ResourceUpperBound:  cra.getRegionMaxRequirement(r),
 			ResourceLowerBound:  cra.getRegionMinRequirement(r),
@@ -259,12 +273,15 @@ func (cra *cpuResourceAdvisor) updateWithIsolationGuardian(tryIsolation bool) (
 			ReservedForAllocate: cra.getRegionReservedForAllocate(r),
 
 			AllowSharedCoresOverlapReclaimedCores: cra.allowSharedCoresOverlapReclaimedCores,
-		})
+		}
+		regionEssentials[r.Name()] = essentials
+		r.SetEssentials(essentials)
 
 		r.TryUpdateProvision()
 		r.TryUpdateHeadroom()
 	}
 	cra.updateRegionEntries()
+	cpuTrace.Regions = cra.traceRegions(regionEssentials)
 
 	cra.advisorUpdated = true
 
@@ -284,8 +301,9 @@ func (cra *cpuResourceAdvisor) updateWithIsolationGuardian(tryIsolation bool) (
 	return &calculationResult, nil
 }
 
-// setIsolatedContainers get isolation status from isolator and update into containers
-func (cra *cpuResourceAdvisor) setIsolatedContainers(enableIsolated bool) bool {
+// setIsolatedContainers get isolation status from isolator and update into containers,
+// and returns the isolated pods
+func (cra *cpuResourceAdvisor) setIsolatedContainers(enableIsolated bool) []string {
 	isolatedPods := sets.NewString()
 	if enableIsolated {
 		isolatedPods = sets.NewString(cra.isolator.GetIsolatedPods()...)
@@ -301,7 +319,7 @@ func (cra *cpuResourceAdvisor) setIsolatedContainers(enableIsolated bool) bool {
 		}
 		return true
 	})
-	return len(isolatedPods) > 0
+	return isolatedPods.List()
 }
 
 // checkIsolationSafety returns true iff the isolated-limit-sum and share-pool-size exceed total capacity
@@ -18,6 +18,7 @@ package cpu
 
 import (
 	"fmt"
+	"sort"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -29,6 +30,7 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/assembler/headroomassembler"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/assembler/provisionassembler"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/trace"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
 	"github.com/kubewharf/katalyst-core/pkg/util/general"
 	"github.com/kubewharf/katalyst-core/pkg/util/machine"
@@ -292,6 +294,38 @@ func (cra *cpuResourceAdvisor) updateRegionEntries() {
 	_ = cra.metaCache.SetRegionEntries(entries)
 }
 
+// traceRegions collects decision details of all regions in current round,
+// and it should be called after region entries are updated.
+func (cra *cpuResourceAdvisor) traceRegions(regionEssentials map[string]types.ResourceEssentials) []*trace.RegionTrace {
+	regionTraces := make([]*trace.RegionTrace, 0, len(cra.regionMap))
+	for regionName, r := range cra.regionMap {
+		regionTrace := &trace.RegionTrace{
+			Name:          regionName,
+			Type:          r.Type(),
+			OwnerPoolName: r.OwnerPoolName(),
+			BindingNUMAs:  r.GetBindingNumas().String(),
+			Pods:          sets.StringKeySet(r.GetPods()).List(),
+			Throttled:     r.IsThrottled(),
+			Status:        r.GetStatus(),
+			Essentials:    regionEssentials[regionName],
+			Control:       r.GetControlEssentials(),
+		}
+		regionTrace.ProvisionPolicyTopPriority, regionTrace.ProvisionPolicyInUse = r.GetProvisionPolicy()
+		regionTrace.HeadroomPolicyTopPriority, regionTrace.HeadroomPolicyInUse = r.GetHeadRoomPolicy()
+		regionTrace.RawControlKnobs, regionTrace.RegulatedControlKnobs = r.GetProvisionControlKnobs()
+		if regionInfo, ok := cra.metaCache.GetRegionInfo(regionName); ok {
+			regionTrace.Headroom = regionInfo.Headroom
+		}
+
+		regionTraces = append(regionTraces, regionTrace)
+	}
+
+	sort.Slice(regionTraces, func(i, j int) bool {
+		return regionTraces[i].Name < regionTraces[j].Name
+	})
+	return regionTraces
+}
+
 func (cra *cpuResourceAdvisor) updateRegionStatus() {
 	for regionName, r := range cra.regionMap {
 		r.UpdateStatus()
@@ -173,6 +173,10 @@ func (fake *FakeRegion) GetControlEssentials() types.ControlEssentials {
 	return fake.controlEssentials
 }
 
+func (fake *FakeRegion) GetProvisionControlKnobs() (raw, regulated map[types.CPUProvisionPolicyName]types.ControlKnob) {
+	return nil, nil
+}
+
 type testCasePoolConfig struct {
 	poolName      string
 	poolType      configapi.QoSRegionType
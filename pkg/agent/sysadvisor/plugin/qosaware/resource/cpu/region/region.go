@@ -85,6 +85,8 @@ type QoSRegion interface {
 	GetStatus() types.RegionStatus
 	// GetControlEssentials returns the latest control essentials
 	GetControlEssentials() types.ControlEssentials
+	// GetProvisionControlKnobs returns the latest raw and regulated control knobs keyed by provision policy
+	GetProvisionControlKnobs() (raw, regulated map[types.CPUProvisionPolicyName]types.ControlKnob)
 
 	GetMetaInfo() string
 }
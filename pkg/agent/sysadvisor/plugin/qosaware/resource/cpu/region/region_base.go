@@ -182,6 +182,8 @@ type QoSRegionBase struct {
 	provisionPolicies        []*internalProvisionPolicy
 	provisionPolicyNameInUse types.CPUProvisionPolicyName
 	provisionPolicyResults   map[types.CPUProvisionPolicyName]*provisionPolicyResult
+	// rawProvisionControlKnobs records control knobs given by provision policies before regulation
+	rawProvisionControlKnobs map[types.CPUProvisionPolicyName]types.ControlKnob
 
 	// ctrl knob need policy restrict
 	ctrlKnobsNeedPolicyRestrict map[v1alpha1.ControlKnobName]bool
@@ -539,6 +541,22 @@ func (r *QoSRegionBase) GetControlEssentials() types.ControlEssentials {
 	return r.ControlEssentials
 }
 
+func (r *QoSRegionBase) GetProvisionControlKnobs() (raw, regulated map[types.CPUProvisionPolicyName]types.ControlKnob) {
+	r.Lock()
+	defer r.Unlock()
+
+	raw = make(map[types.CPUProvisionPolicyName]types.ControlKnob, len(r.rawProvisionControlKnobs))
+	for policy, controlKnob := range r.rawProvisionControlKnobs {
+		raw[policy] = controlKnob.Clone()
+	}
+
+	regulated = make(map[types.CPUProvisionPolicyName]types.ControlKnob, len(r.provisionPolicyResults))
+	for policy, result := range r.provisionPolicyResults {
+		regulated[policy] = result.getControlKnob()
+	}
+	return raw, regulated
+}
+
 // getRegionNameFromMetaCache returns region name owned by container from metacache,
 // to restore region info after restart. If numaID is specified, binding numas of the
 // region will be checked, otherwise only one region should be owned by container.
@@ -674,6 +692,8 @@ func (r *QoSRegionBase) getProvisionControlKnob() map[types.CPUProvisionPolicyNa
 func (r *QoSRegionBase) regulateProvisionControlKnob(originControlKnob map[types.CPUProvisionPolicyName]types.ControlKnob,
 	effectiveControlKnob types.ControlKnob,
 ) {
+	r.rawProvisionControlKnobs = originControlKnob
+
 	provisionPolicyResults := make(map[types.CPUProvisionPolicyName]*provisionPolicyResult)
 	firstValidPolicy := types.CPUProvisionPolicyNone
 	for _, internal := range r.provisionPolicies {
@@ -32,6 +32,7 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/memory/headroompolicy"
 	memadvisorplugin "github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/memory/plugin"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/memory/plugin/provisioner"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/trace"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
 	"github.com/kubewharf/katalyst-core/pkg/config"
 	"github.com/kubewharf/katalyst-core/pkg/metaserver"
@@ -49,6 +50,7 @@ func init() {
 	memadvisorplugin.RegisterInitializer(memadvisorplugin.MemoryGuard, memadvisorplugin.NewMemoryGuard)
 	memadvisorplugin.RegisterInitializer(memadvisorplugin.MemsetBinder, memadvisorplugin.NewMemsetBinder)
 	memadvisorplugin.RegisterInitializer(memadvisorplugin.NumaMemoryBalancer, memadvisorplugin.NewMemoryBalancer)
+	memadvisorplugin.RegisterInitializer(memadvisorplugin.OOMProtector, memadvisorplugin.NewOOMProtector)
 	memadvisorplugin.RegisterInitializer(memadvisorplugin.TransparentMemoryOffloading, memadvisorplugin.NewTransparentMemoryOffloading)
 	memadvisorplugin.RegisterInitializer(provisioner.MemoryProvisioner, provisioner.NewMemoryProvisioner)
 }
@@ -78,6 +80,7 @@ type memoryResourceAdvisor struct {
 	conf            *config.Configuration
 	headroomPolices []headroompolicy.HeadroomPolicy
 	plugins         []memadvisorplugin.MemoryAdvisorPlugin
+	pluginNames     []types.MemoryAdvisorPluginName
 	mutex           sync.RWMutex
 
 	metaReader metacache.MetaReader
@@ -120,6 +123,7 @@ func NewMemoryResourceAdvisor(conf *config.Configuration, extraConf interface{},
 		}
 		general.InfoS("add new memory advisor plugin", "pluginName", memadvisorPluginName)
 		ra.plugins = append(ra.plugins, initFunc(conf, extraConf, metaCache, metaServer, emitter))
+		ra.pluginNames = append(ra.pluginNames, memadvisorPluginName)
 	}
 
 	return ra
@@ -173,7 +177,7 @@ func (ra *memoryResourceAdvisor) UpdateAndGetAdvice() (interface{}, error) {
 
 // update updates memory headroom and plugin advices.
 // If the returned result is not nil, it is valid even if an error is returned.
-func (ra *memoryResourceAdvisor) update() (*types.InternalMemoryCalculationResult, error) {
+func (ra *memoryResourceAdvisor) update() (result *types.InternalMemoryCalculationResult, err error) {
 	startTime := time.Now()
 	ra.mutex.Lock()
 	general.InfoS("acquired lock", "duration", time.Since(startTime))
@@ -182,6 +186,14 @@ func (ra *memoryResourceAdvisor) update() (*types.InternalMemoryCalculationResul
 		general.InfoS("finished", "duration", time.Since(startTime))
 	}()
 
+	memoryTrace := &trace.MemoryTrace{}
+	defer func() {
+		trace.GetDefaultRecorder().Record(types.QoSResourceMemory, startTime, err, func(t *trace.Trace) {
+			memoryTrace.Result = result
+			t.Memory = memoryTrace
+		})
+	}()
+
 	if !ra.metaReader.HasSynced() {
 		general.InfoS("metaReader has not synced, skip updating")
 		return nil, fmt.Errorf("meta reader has not synced")
@@ -193,15 +205,19 @@ func (ra *memoryResourceAdvisor) update() (*types.InternalMemoryCalculationResul
 	var nonFatalErrors []error
 	for _, headroomPolicy := range ra.headroomPolices {
 		// capacity and reserved can both be adjusted dynamically during running process
-		headroomPolicy.SetEssentials(types.ResourceEssentials{
+		essentials := types.ResourceEssentials{
 			EnableReclaim:       ra.conf.GetDynamicConfiguration().EnableReclaim,
 			ResourceUpperBound:  float64(ra.metaServer.MemoryCapacity),
 			ReservedForAllocate: reservedForAllocate.AsApproximateFloat64(),
-		})
+		}
+		headroomPolicy.SetEssentials(essentials)
 
+		policyTrace := &trace.MemoryHeadroomPolicyTrace{Name: headroomPolicy.Name(), Essentials: essentials}
+		memoryTrace.HeadroomPolicies = append(memoryTrace.HeadroomPolicies, policyTrace)
 		if err := headroomPolicy.Update(); err != nil {
 			general.ErrorS(err, "update headroom policy failed", "headroomPolicy", headroomPolicy.Name())
 			nonFatalErrors = append(nonFatalErrors, fmt.Errorf("update headroom policy failed for %s: %v", headroomPolicy.Name(), err))
+			policyTrace.Error = err.Error()
 		}
 	}
 
@@ -220,21 +236,30 @@ func (ra *memoryResourceAdvisor) update() (*types.InternalMemoryCalculationResul
 		NodeCondition:  nodeCondition,
 		NUMAConditions: NUMAConditions,
 	}
+	memoryTrace.PressureStatus = &memoryPressureStatus
+
+	result = &types.InternalMemoryCalculationResult{TimeStamp: time.Now()}
+	for i, plugin := range ra.plugins {
+		pluginTrace := &trace.MemoryPluginTrace{}
+		if i < len(ra.pluginNames) {
+			pluginTrace.Name = ra.pluginNames[i]
+		}
+		memoryTrace.Plugins = append(memoryTrace.Plugins, pluginTrace)
 
-	result := types.InternalMemoryCalculationResult{TimeStamp: time.Now()}
-	for _, plugin := range ra.plugins {
 		if err := plugin.Reconcile(&memoryPressureStatus); err != nil {
 			general.Errorf("plugin %T reconcile failed: %v", plugin, err)
 			nonFatalErrors = append(nonFatalErrors, fmt.Errorf("plugin %T reconcile failed: %v", plugin, err))
+			pluginTrace.Error = err.Error()
 			continue
 		}
 
 		advices := plugin.GetAdvices()
+		pluginTrace.Advices = &advices
 		result.ContainerEntries = append(result.ContainerEntries, advices.ContainerEntries...)
 		result.ExtraEntries = append(result.ExtraEntries, advices.ExtraEntries...)
 	}
 
-	return &result, errors.NewAggregate(nonFatalErrors)
+	return result, errors.NewAggregate(nonFatalErrors)
 }
 
 func (ra *memoryResourceAdvisor) detectNUMAPressureConditions() (map[int]*types.MemoryPressureCondition, error) {
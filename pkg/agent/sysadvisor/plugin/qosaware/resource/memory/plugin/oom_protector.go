@@ -0,0 +1,200 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package plugin
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/memory/dynamicpolicy/memoryadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	OOMProtector = "oom-protector"
+
+	oomEventBufferSize = 100
+
+	metricsNameOOMProtectedContainer = "oom_protected_container"
+)
+
+type containerKey struct {
+	podUID        string
+	containerName string
+}
+
+// oomProtector consumes container OOM events reported by the agent, and temporarily
+// raises memory.low to the memory request for the non-reclaimed containers that
+// keep hitting OOM, so that their memory won't be reclaimed in favor of others.
+// memory.low only exists in cgroup v2, so no advice is given in cgroup v1.
+type oomProtector struct {
+	conf       *config.Configuration
+	metaReader metacache.MetaReader
+	emitter    metrics.MetricEmitter
+
+	memoryLowSupported bool
+
+	mutex sync.Mutex
+	// oomTimes records the time of OOMs happened within the window
+	oomTimes map[containerKey][]time.Time
+	// protectedUntil records the deadline of protection for the protected containers
+	protectedUntil map[containerKey]time.Time
+	// toUnprotect records the containers whose protection is expired but not reset yet
+	toUnprotect map[containerKey]struct{}
+
+	now func() time.Time
+}
+
+func NewOOMProtector(conf *config.Configuration, _ interface{}, metaReader metacache.MetaReader,
+	_ *metaserver.MetaServer, emitter metrics.MetricEmitter,
+) MemoryAdvisorPlugin {
+	op := newOOMProtector(conf, metaReader, emitter)
+	if !op.memoryLowSupported {
+		general.Warningf("%v is disabled since memory.low is only supported in cgroup v2", OOMProtector)
+		return op
+	}
+
+	if err := eventbus.GetDefaultEventBus().Subscribe(consts.TopicNameContainerOOM, OOMProtector,
+		oomEventBufferSize, op.onOOMEvent); err != nil {
+		general.Errorf("failed to subscribe topic %v: %v", consts.TopicNameContainerOOM, err)
+	}
+	return op
+}
+
+func newOOMProtector(conf *config.Configuration, metaReader metacache.MetaReader, emitter metrics.MetricEmitter) *oomProtector {
+	return &oomProtector{
+		conf:               conf,
+		metaReader:         metaReader,
+		emitter:            emitter,
+		memoryLowSupported: common.CheckCgroup2UnifiedMode(),
+		oomTimes:           make(map[containerKey][]time.Time),
+		protectedUntil:     make(map[containerKey]time.Time),
+		toUnprotect:        make(map[containerKey]struct{}),
+		now:                time.Now,
+	}
+}
+
+func (op *oomProtector) onOOMEvent(event interface{}) error {
+	oomEvent, ok := event.(eventbus.ContainerOOMEvent)
+	if !ok || !op.memoryLowSupported {
+		return nil
+	}
+
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+	key := containerKey{podUID: oomEvent.PodUID, containerName: oomEvent.ContainerName}
+	op.oomTimes[key] = append(op.oomTimes[key], oomEvent.Time)
+	return nil
+}
+
+func (op *oomProtector) Reconcile(_ *types.MemoryPressureStatus) error {
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+
+	now := op.now()
+	windowStart := now.Add(-op.conf.OOMProtectionWindow)
+	for key, times := range op.oomTimes {
+		recent := make([]time.Time, 0, len(times))
+		for _, t := range times {
+			if t.After(windowStart) {
+				recent = append(recent, t)
+			}
+		}
+		if len(recent) == 0 {
+			delete(op.oomTimes, key)
+			continue
+		}
+		op.oomTimes[key] = recent
+
+		if op.conf.OOMProtectionThreshold <= 0 || len(recent) < op.conf.OOMProtectionThreshold {
+			continue
+		}
+
+		ci, ok := op.metaReader.GetContainerInfo(key.podUID, key.containerName)
+		if !ok || ci.QoSLevel == apiconsts.PodAnnotationQoSLevelReclaimedCores {
+			continue
+		}
+		if _, protected := op.protectedUntil[key]; !protected {
+			general.Infof("pod %v/%v container %v hit OOM %v times within %v, protect its memory for %v",
+				ci.PodNamespace, ci.PodName, ci.ContainerName, len(recent), op.conf.OOMProtectionWindow, op.conf.OOMProtectionDuration)
+		}
+		op.protectedUntil[key] = now.Add(op.conf.OOMProtectionDuration)
+		delete(op.toUnprotect, key)
+	}
+
+	for key, deadline := range op.protectedUntil {
+		_, exist := op.metaReader.GetContainerInfo(key.podUID, key.containerName)
+		if !exist {
+			delete(op.protectedUntil, key)
+			delete(op.oomTimes, key)
+			continue
+		}
+
+		if now.After(deadline) {
+			general.Infof("memory protection of pod %v container %v is expired", key.podUID, key.containerName)
+			delete(op.protectedUntil, key)
+			op.toUnprotect[key] = struct{}{}
+		}
+	}
+
+	_ = op.emitter.StoreInt64(metricsNameOOMProtectedContainer, int64(len(op.protectedUntil)), metrics.MetricTypeNameRaw)
+	return nil
+}
+
+func (op *oomProtector) GetAdvices() types.InternalMemoryCalculationResult {
+	result := types.InternalMemoryCalculationResult{
+		ContainerEntries: make([]types.ContainerMemoryAdvices, 0),
+	}
+
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+
+	for key := range op.protectedUntil {
+		ci, ok := op.metaReader.GetContainerInfo(key.podUID, key.containerName)
+		if !ok {
+			continue
+		}
+		result.ContainerEntries = append(result.ContainerEntries, types.ContainerMemoryAdvices{
+			PodUID:        key.podUID,
+			ContainerName: key.containerName,
+			Values: map[string]string{
+				string(memoryadvisor.ControlKnobKeyMemoryLow): strconv.FormatInt(int64(ci.MemoryRequest), 10),
+			},
+		})
+	}
+
+	// memory.low of the expired containers is reset only once
+	for key := range op.toUnprotect {
+		result.ContainerEntries = append(result.ContainerEntries, types.ContainerMemoryAdvices{
+			PodUID:        key.podUID,
+			ContainerName: key.containerName,
+			Values:        map[string]string{string(memoryadvisor.ControlKnobKeyMemoryLow): "0"},
+		})
+		delete(op.toUnprotect, key)
+	}
+
+	return result
+}
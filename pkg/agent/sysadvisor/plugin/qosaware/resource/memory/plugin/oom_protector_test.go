@@ -0,0 +1,136 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package plugin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/memory/dynamicpolicy/memoryadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+)
+
+type fakeMetaReader struct {
+	metacache.MetaReader
+	containers map[containerKey]*types.ContainerInfo
+}
+
+func (f *fakeMetaReader) GetContainerInfo(podUID string, containerName string) (*types.ContainerInfo, bool) {
+	ci, ok := f.containers[containerKey{podUID: podUID, containerName: containerName}]
+	return ci, ok
+}
+
+func TestOOMProtector(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		qosLevel    string
+		cgroupV1    bool
+		oomAgo      []time.Duration
+		wantAdvices []string // memory_low advised after each step of [reconcile, expire]
+	}{
+		{
+			name:        "below threshold",
+			qosLevel:    apiconsts.PodAnnotationQoSLevelSharedCores,
+			oomAgo:      []time.Duration{time.Minute, 2 * time.Minute},
+			wantAdvices: []string{"", ""},
+		},
+		{
+			name:        "ooms out of window",
+			qosLevel:    apiconsts.PodAnnotationQoSLevelSharedCores,
+			oomAgo:      []time.Duration{time.Minute, 2 * time.Minute, time.Hour},
+			wantAdvices: []string{"", ""},
+		},
+		{
+			name:        "reclaimed container is not protected",
+			qosLevel:    apiconsts.PodAnnotationQoSLevelReclaimedCores,
+			oomAgo:      []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute},
+			wantAdvices: []string{"", ""},
+		},
+		{
+			name:        "protected and then reset",
+			qosLevel:    apiconsts.PodAnnotationQoSLevelSharedCores,
+			oomAgo:      []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute},
+			wantAdvices: []string{"1073741824", "0"},
+		},
+		{
+			name:        "no advice in cgroup v1",
+			qosLevel:    apiconsts.PodAnnotationQoSLevelSharedCores,
+			cgroupV1:    true,
+			oomAgo:      []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute},
+			wantAdvices: []string{"", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := config.NewConfiguration()
+			conf.OOMProtectionThreshold = 3
+			conf.OOMProtectionWindow = 10 * time.Minute
+			conf.OOMProtectionDuration = 30 * time.Minute
+
+			key := containerKey{podUID: "uid1", containerName: "c1"}
+			metaReader := &fakeMetaReader{containers: map[containerKey]*types.ContainerInfo{
+				key: {PodUID: "uid1", ContainerName: "c1", QoSLevel: tt.qosLevel, MemoryRequest: 1 << 30},
+			}}
+
+			now := time.Now()
+			op := newOOMProtector(conf, metaReader, metrics.DummyMetrics{})
+			op.now = func() time.Time { return now }
+			op.memoryLowSupported = !tt.cgroupV1
+			for _, ago := range tt.oomAgo {
+				require.NoError(t, op.onOOMEvent(eventbus.ContainerOOMEvent{
+					BaseEventImpl: eventbus.BaseEventImpl{Time: now.Add(-ago)},
+					PodUID:        "uid1",
+					ContainerName: "c1",
+					OOM:           1,
+				}))
+			}
+
+			getAdvice := func() string {
+				result := op.GetAdvices()
+				if len(result.ContainerEntries) == 0 {
+					return ""
+				}
+				require.Len(t, result.ContainerEntries, 1)
+				return result.ContainerEntries[0].Values[string(memoryadvisor.ControlKnobKeyMemoryLow)]
+			}
+
+			require.NoError(t, op.Reconcile(&types.MemoryPressureStatus{}))
+			assert.Equal(t, tt.wantAdvices[0], getAdvice())
+
+			now = now.Add(time.Hour)
+			require.NoError(t, op.Reconcile(&types.MemoryPressureStatus{}))
+			assert.Equal(t, tt.wantAdvices[1], getAdvice())
+
+			// the reset advice is only given once
+			assert.Equal(t, "", getAdvice())
+			assert.Empty(t, op.oomTimes)
+		})
+	}
+}
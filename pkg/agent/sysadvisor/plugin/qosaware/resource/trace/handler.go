@@ -0,0 +1,122 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trace
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+const (
+	PathTraces    = "/sysadvisor/traces"
+	PathTraceDump = "/sysadvisor/traces/dump"
+
+	QueryParamResource = "resource"
+	QueryParamSince    = "since"
+	QueryParamUntil    = "until"
+	QueryParamLimit    = "limit"
+)
+
+// HTTPHandlerRegister registers handler for the given path, and it's
+// usually implemented by the generic context of katalyst components.
+type HTTPHandlerRegister interface {
+	RegisterHTTPHandler(path string, handler http.Handler) error
+}
+
+// RegisterHandlers exposes traces in the default recorder through http endpoints; the register
+// must authenticate and authorize all requests (as the generic context does), since traces contain
+// internal states of the node and dumping writes files.
+func RegisterHandlers(register HTTPHandlerRegister) error {
+	if err := register.RegisterHTTPHandler(PathTraces, http.HandlerFunc(handleList)); err != nil {
+		return err
+	}
+	return register.RegisterHTTPHandler(PathTraceDump, http.HandlerFunc(handleDump))
+}
+
+func handleList(w http.ResponseWriter, r *http.Request) {
+	if r == nil || r.Method != http.MethodGet || r.URL == nil {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, _ = fmt.Fprintf(w, "request must be GET")
+		return
+	}
+
+	filter, err := parseFilter(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprintf(w, "parse query failed: %v", err)
+		return
+	}
+
+	bytes, err := marshalTraces(GetDefaultRecorder().List(filter))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, "marshal traces failed: %v", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(bytes)
+}
+
+func handleDump(w http.ResponseWriter, r *http.Request) {
+	if r == nil || r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, _ = fmt.Fprintf(w, "request must be POST")
+		return
+	}
+
+	path, err := GetDefaultRecorder().Dump()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, "dump traces failed: %v", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprintf(w, "%s", path)
+}
+
+func parseFilter(r *http.Request) (Filter, error) {
+	var (
+		filter Filter
+		err    error
+	)
+
+	query := r.URL.Query()
+	filter.Resource = types.QoSResourceName(query.Get(QueryParamResource))
+	if since := query.Get(QueryParamSince); since != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			return filter, fmt.Errorf("invalid %v: %v", QueryParamSince, err)
+		}
+	}
+	if until := query.Get(QueryParamUntil); until != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, until); err != nil {
+			return filter, fmt.Errorf("invalid %v: %v", QueryParamUntil, err)
+		}
+	}
+	if limit := query.Get(QueryParamLimit); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit < 0 {
+			return filter, fmt.Errorf("invalid %v %q", QueryParamLimit, limit)
+		}
+	}
+	return filter, nil
+}
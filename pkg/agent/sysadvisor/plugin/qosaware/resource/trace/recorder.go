@@ -0,0 +1,237 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	DefaultCapacity     = 120
+	DefaultMaxDumpFiles = 10
+
+	dumpFilePrefix = "decision-trace-"
+	dumpFileSuffix = ".json"
+	// dumpFileTimeFormat has fixed width, so that dump files are sorted by names chronologically
+	dumpFileTimeFormat = "20060102-150405.000000000"
+)
+
+// Filter selects traces from recorder; zero values mean no filtering
+type Filter struct {
+	Resource types.QoSResourceName
+	Since    time.Time
+	Until    time.Time
+	Limit    int
+}
+
+func (f Filter) match(t *Trace) bool {
+	if f.Resource != "" && f.Resource != t.Resource {
+		return false
+	}
+	if !f.Since.IsZero() && t.StartTime.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && t.StartTime.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Recorder keeps the latest traces in a ring buffer
+type Recorder struct {
+	mutex sync.RWMutex
+	// dumpMutex serializes dumping and rotation of dump files
+	dumpMutex sync.Mutex
+
+	dumpDir      string
+	maxDumpFiles int
+	traces       []*Trace
+	next         int
+	rounds       map[types.QoSResourceName]uint64
+}
+
+func NewRecorder(capacity int, dumpDir string, maxDumpFiles int) *Recorder {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	if maxDumpFiles <= 0 {
+		maxDumpFiles = DefaultMaxDumpFiles
+	}
+
+	return &Recorder{
+		dumpDir:      dumpDir,
+		maxDumpFiles: maxDumpFiles,
+		traces:       make([]*Trace, 0, capacity),
+		rounds:       make(map[types.QoSResourceName]uint64),
+	}
+}
+
+// Record stores the trace of a finished update round, and the oldest
+// trace is overwritten if the ring buffer is full.
+func (r *Recorder) Record(resource types.QoSResourceName, startTime time.Time, err error, f func(t *Trace)) {
+	t := &Trace{
+		Resource:  resource,
+		StartTime: startTime,
+		Duration:  metav1.Duration{Duration: time.Since(startTime)},
+	}
+	if err != nil {
+		t.Error = err.Error()
+	}
+	if f != nil {
+		f(t)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.rounds[resource]++
+	t.Round = r.rounds[resource]
+
+	if len(r.traces) < cap(r.traces) {
+		r.traces = append(r.traces, t)
+		return
+	}
+	r.traces[r.next] = t
+	r.next = (r.next + 1) % len(r.traces)
+}
+
+// List returns traces matching the filter in chronological order;
+// if limit is set, only the latest ones are returned.
+func (r *Recorder) List(filter Filter) []*Trace {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	traces := make([]*Trace, 0, len(r.traces))
+	for i := 0; i < len(r.traces); i++ {
+		t := r.traces[(r.next+i)%len(r.traces)]
+		if filter.match(t) {
+			traces = append(traces, t)
+		}
+	}
+
+	if filter.Limit > 0 && len(traces) > filter.Limit {
+		traces = traces[len(traces)-filter.Limit:]
+	}
+	return traces
+}
+
+// Dump writes all traces in the ring buffer into a file under dump dir,
+// and returns the path of the file; only the latest maxDumpFiles files
+// are kept, and the older ones are removed.
+func (r *Recorder) Dump() (string, error) {
+	if r.dumpDir == "" {
+		return "", fmt.Errorf("dump dir is not configured")
+	}
+
+	r.dumpMutex.Lock()
+	defer r.dumpMutex.Unlock()
+
+	traces := r.List(Filter{})
+	bytes, err := marshalTraces(traces)
+	if err != nil {
+		return "", fmt.Errorf("marshal traces failed: %v", err)
+	}
+
+	if err := os.MkdirAll(r.dumpDir, 0o755); err != nil {
+		return "", fmt.Errorf("create dump dir %v failed: %v", r.dumpDir, err)
+	}
+
+	path := filepath.Join(r.dumpDir, dumpFilePrefix+time.Now().Format(dumpFileTimeFormat)+dumpFileSuffix)
+	if err := os.WriteFile(path, bytes, 0o644); err != nil {
+		return "", fmt.Errorf("write dump file %v failed: %v", path, err)
+	}
+	general.Infof("dumped %v decision traces to %v", len(traces), path)
+
+	if err := r.rotateDumpFiles(); err != nil {
+		general.Errorf("rotate decision trace dump files failed: %v", err)
+	}
+	return path, nil
+}
+
+// rotateDumpFiles removes the oldest dump files beyond maxDumpFiles
+func (r *Recorder) rotateDumpFiles() error {
+	entries, err := os.ReadDir(r.dumpDir)
+	if err != nil {
+		return err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), dumpFilePrefix) && strings.HasSuffix(entry.Name(), dumpFileSuffix) {
+			files = append(files, entry.Name())
+		}
+	}
+	if len(files) <= r.maxDumpFiles {
+		return nil
+	}
+
+	sort.Strings(files)
+	for _, name := range files[:len(files)-r.maxDumpFiles] {
+		if err := os.Remove(filepath.Join(r.dumpDir, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// marshalTraces marshals traces one by one, to avoid a single abnormal
+// trace (e.g. with NaN values) making all the others invisible
+func marshalTraces(traces []*Trace) ([]byte, error) {
+	contents := make([]json.RawMessage, 0, len(traces))
+	for _, t := range traces {
+		bytes, err := json.Marshal(t)
+		if err != nil {
+			general.Errorf("marshal %v trace of round %v failed: %v", t.Resource, t.Round, err)
+			continue
+		}
+		contents = append(contents, bytes)
+	}
+	return json.Marshal(contents)
+}
+
+var (
+	defaultRecorderLock sync.RWMutex
+	defaultRecorder     = NewRecorder(DefaultCapacity, "", DefaultMaxDumpFiles)
+)
+
+// GetDefaultRecorder returns the recorder shared by sub resource advisors
+func GetDefaultRecorder() *Recorder {
+	defaultRecorderLock.RLock()
+	defer defaultRecorderLock.RUnlock()
+
+	return defaultRecorder
+}
+
+// SetDefaultRecorder replaces the recorder shared by sub resource advisors
+func SetDefaultRecorder(recorder *Recorder) {
+	defaultRecorderLock.Lock()
+	defer defaultRecorderLock.Unlock()
+
+	defaultRecorder = recorder
+}
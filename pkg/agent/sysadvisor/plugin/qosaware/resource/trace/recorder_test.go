@@ -0,0 +1,269 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trace
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+func newTestRecorder(capacity int, dumpDir string, base time.Time) *Recorder {
+	r := NewRecorder(capacity, dumpDir, 0)
+	for i := 0; i < 5; i++ {
+		resource := types.QoSResourceCPU
+		if i%2 == 1 {
+			resource = types.QoSResourceMemory
+		}
+		r.Record(resource, base.Add(time.Duration(i)*time.Minute), nil, nil)
+	}
+	return r
+}
+
+func TestRecorderList(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type traceKey struct {
+		resource types.QoSResourceName
+		round    uint64
+	}
+
+	tests := []struct {
+		name     string
+		capacity int
+		filter   Filter
+		want     []traceKey
+	}{
+		{
+			name:     "ring buffer overwrites the oldest traces",
+			capacity: 3,
+			want: []traceKey{
+				{types.QoSResourceCPU, 2},
+				{types.QoSResourceMemory, 2},
+				{types.QoSResourceCPU, 3},
+			},
+		},
+		{
+			name:     "filter by resource",
+			capacity: 10,
+			filter:   Filter{Resource: types.QoSResourceMemory},
+			want: []traceKey{
+				{types.QoSResourceMemory, 1},
+				{types.QoSResourceMemory, 2},
+			},
+		},
+		{
+			name:     "filter by time range",
+			capacity: 10,
+			filter:   Filter{Since: base.Add(time.Minute), Until: base.Add(2 * time.Minute)},
+			want: []traceKey{
+				{types.QoSResourceMemory, 1},
+				{types.QoSResourceCPU, 2},
+			},
+		},
+		{
+			name:     "limit keeps the latest traces",
+			capacity: 10,
+			filter:   Filter{Resource: types.QoSResourceCPU, Limit: 2},
+			want: []traceKey{
+				{types.QoSResourceCPU, 2},
+				{types.QoSResourceCPU, 3},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := newTestRecorder(tt.capacity, "", base)
+			got := make([]traceKey, 0)
+			for _, trace := range r.List(tt.filter) {
+				got = append(got, traceKey{trace.Resource, trace.Round})
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRecorderRecord(t *testing.T) {
+	t.Parallel()
+
+	r := NewRecorder(0, "", 0)
+	require.Equal(t, DefaultCapacity, cap(r.traces))
+
+	r.Record(types.QoSResourceCPU, time.Now(), fmt.Errorf("test error"), func(t *Trace) {
+		t.CPU = &CPUTrace{IsolationTried: true}
+	})
+
+	traces := r.List(Filter{})
+	require.Len(t, traces, 1)
+	require.Equal(t, "test error", traces[0].Error)
+	require.True(t, traces[0].CPU.IsolationTried)
+}
+
+func TestRecorderDump(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := newTestRecorder(10, "", base).Dump()
+	require.Error(t, err)
+
+	r := newTestRecorder(10, t.TempDir(), base)
+	// trace with NaN values can't be marshaled, and it should be skipped
+	r.Record(types.QoSResourceCPU, base, nil, func(t *Trace) {
+		t.CPU = &CPUTrace{Regions: []*RegionTrace{{Headroom: math.NaN()}}}
+	})
+
+	path, err := r.Dump()
+	require.NoError(t, err)
+
+	bytes, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var traces []*Trace
+	require.NoError(t, json.Unmarshal(bytes, &traces))
+	require.Len(t, traces, 5)
+}
+
+func TestRecorderDumpRotation(t *testing.T) {
+	t.Parallel()
+
+	dumpDir := t.TempDir()
+	// files not created by dump are never removed
+	require.NoError(t, os.WriteFile(filepath.Join(dumpDir, "other.json"), []byte("{}"), 0o644))
+
+	r := NewRecorder(10, dumpDir, 3)
+	var paths []string
+	for i := 0; i < 5; i++ {
+		path, err := r.Dump()
+		require.NoError(t, err)
+		paths = append(paths, path)
+	}
+
+	entries, err := os.ReadDir(dumpDir)
+	require.NoError(t, err)
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	want := []string{"other.json"}
+	for _, path := range paths[2:] {
+		want = append(want, filepath.Base(path))
+	}
+	require.ElementsMatch(t, want, names)
+}
+
+type fakeRegister struct {
+	mux *http.ServeMux
+}
+
+func (f *fakeRegister) RegisterHTTPHandler(path string, handler http.Handler) error {
+	f.mux.Handle(path, handler)
+	return nil
+}
+
+func TestHandlers(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	SetDefaultRecorder(newTestRecorder(10, t.TempDir(), base))
+
+	register := &fakeRegister{mux: http.NewServeMux()}
+	require.NoError(t, RegisterHandlers(register))
+	server := httptest.NewServer(register.mux)
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantTraces int
+	}{
+		{
+			name:       "list all traces",
+			method:     http.MethodGet,
+			path:       PathTraces,
+			wantStatus: http.StatusOK,
+			wantTraces: 5,
+		},
+		{
+			name:       "list with filter",
+			method:     http.MethodGet,
+			path:       PathTraces + "?resource=cpu&since=2022-01-01T00:01:00Z&limit=1",
+			wantStatus: http.StatusOK,
+			wantTraces: 1,
+		},
+		{
+			name:       "invalid limit",
+			method:     http.MethodGet,
+			path:       PathTraces + "?limit=-1",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "list with wrong method",
+			method:     http.MethodPost,
+			path:       PathTraces,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "dump traces",
+			method:     http.MethodPost,
+			path:       PathTraceDump,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "dump with wrong method",
+			method:     http.MethodGet,
+			path:       PathTraceDump,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL+tt.path, nil)
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			require.Equal(t, tt.wantStatus, resp.StatusCode)
+			if tt.wantStatus == http.StatusOK && tt.method == http.MethodGet {
+				var traces []*Trace
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&traces))
+				require.Len(t, traces, tt.wantTraces)
+			}
+		})
+	}
+}
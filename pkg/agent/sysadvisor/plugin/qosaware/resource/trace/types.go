@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trace
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configapi "github.com/kubewharf/katalyst-api/pkg/apis/config/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+// Trace records the decision details of one update round for a sub resource advisor
+type Trace struct {
+	Resource  types.QoSResourceName `json:"resource"`
+	Round     uint64                `json:"round"`
+	StartTime time.Time             `json:"startTime"`
+	Duration  metav1.Duration       `json:"duration"`
+	Error     string                `json:"error,omitempty"`
+
+	CPU    *CPUTrace    `json:"cpu,omitempty"`
+	Memory *MemoryTrace `json:"memory,omitempty"`
+}
+
+// CPUTrace records inputs and outputs of cpu advisor in an update round
+type CPUTrace struct {
+	IsolationTried             bool     `json:"isolationTried"`
+	IsolationSafetyCheckFailed bool     `json:"isolationSafetyCheckFailed"`
+	IsolatedPods               []string `json:"isolatedPods,omitempty"`
+
+	Regions []*RegionTrace                      `json:"regions"`
+	Result  *types.InternalCPUCalculationResult `json:"result,omitempty"`
+}
+
+// RegionTrace records the essentials and control knobs of a qos region
+type RegionTrace struct {
+	Name          string                   `json:"name"`
+	Type          configapi.QoSRegionType  `json:"type"`
+	OwnerPoolName string                   `json:"ownerPoolName"`
+	BindingNUMAs  string                   `json:"bindingNUMAs"`
+	Pods          []string                 `json:"pods"`
+	Throttled     bool                     `json:"throttled"`
+	Status        types.RegionStatus       `json:"status"`
+	Essentials    types.ResourceEssentials `json:"essentials"`
+	Control       types.ControlEssentials  `json:"control"`
+
+	ProvisionPolicyTopPriority types.CPUProvisionPolicyName `json:"provisionPolicyTopPriority"`
+	ProvisionPolicyInUse       types.CPUProvisionPolicyName `json:"provisionPolicyInUse"`
+	HeadroomPolicyTopPriority  types.CPUHeadroomPolicyName  `json:"headroomPolicyTopPriority"`
+	HeadroomPolicyInUse        types.CPUHeadroomPolicyName  `json:"headroomPolicyInUse"`
+	Headroom                   float64                      `json:"headroom"`
+
+	// RawControlKnobs and RegulatedControlKnobs are keyed by provision policy name
+	RawControlKnobs       map[types.CPUProvisionPolicyName]types.ControlKnob `json:"rawControlKnobs,omitempty"`
+	RegulatedControlKnobs map[types.CPUProvisionPolicyName]types.ControlKnob `json:"regulatedControlKnobs,omitempty"`
+}
+
+// MemoryTrace records inputs and outputs of memory advisor in an update round
+type MemoryTrace struct {
+	HeadroomPolicies []*MemoryHeadroomPolicyTrace           `json:"headroomPolicies"`
+	PressureStatus   *types.MemoryPressureStatus            `json:"pressureStatus,omitempty"`
+	Plugins          []*MemoryPluginTrace                   `json:"plugins"`
+	Result           *types.InternalMemoryCalculationResult `json:"result,omitempty"`
+}
+
+type MemoryHeadroomPolicyTrace struct {
+	Name       types.MemoryHeadroomPolicyName `json:"name"`
+	Essentials types.ResourceEssentials       `json:"essentials"`
+	Error      string                         `json:"error,omitempty"`
+}
+
+type MemoryPluginTrace struct {
+	Name    types.MemoryAdvisorPluginName          `json:"name"`
+	Advices *types.InternalMemoryCalculationResult `json:"advices,omitempty"`
+	Error   string                                 `json:"error,omitempty"`
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oomwatcher
+
+import (
+	"context"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/events"
+
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/pod"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
+	cgroupmgr "github.com/kubewharf/katalyst-core/pkg/util/cgroup/manager"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+)
+
+const (
+	metricsNameContainerOOM     = "container_oom"
+	metricsNameContainerOOMKill = "container_oom_kill"
+)
+
+// OOMWatcher periodically checks memory events of all containers on the node, and
+// reports the containers that hit their memory limits since the last observation
+// through event bus (consumed by sysadvisor and audit) and kubernetes events.
+type OOMWatcher struct {
+	podFetcher pod.PodFetcher
+	eventBus   eventbus.EventBus
+	recorder   events.EventRecorder
+	emitter    metrics.MetricEmitter
+	interval   time.Duration
+
+	// lastEvents records memory events observed last time keyed by container id,
+	// and a container's first observation is only taken as the baseline, since
+	// OOMs happened before that are either reported already or too old to act on.
+	lastEvents map[string]*common.MemoryEvents
+
+	// getMemoryEvents is replaceable in tests since memory events can't be
+	// faked out of cgroupfs
+	getMemoryEvents func(podUID, containerID string) (*common.MemoryEvents, error)
+}
+
+func NewOOMWatcher(podFetcher pod.PodFetcher, eventBus eventbus.EventBus, recorder events.EventRecorder,
+	emitter metrics.MetricEmitter, interval time.Duration,
+) *OOMWatcher {
+	return &OOMWatcher{
+		podFetcher: podFetcher,
+		eventBus:   eventBus,
+		recorder:   recorder,
+		emitter:    emitter,
+		interval:   interval,
+		lastEvents: make(map[string]*common.MemoryEvents),
+
+		getMemoryEvents: getContainerMemoryEvents,
+	}
+}
+
+func (w *OOMWatcher) Run(ctx context.Context) {
+	go wait.UntilWithContext(ctx, w.Watch, w.interval)
+	<-ctx.Done()
+}
+
+// Watch checks memory events of all active containers once, and
+// records of the containers that no longer exist are forgotten
+func (w *OOMWatcher) Watch(ctx context.Context) {
+	pods, err := w.podFetcher.GetPodList(ctx, native.PodIsActive)
+	if err != nil {
+		general.Errorf("get pod list failed: %v", err)
+		return
+	}
+
+	existed := sets.NewString()
+	for _, p := range pods {
+		for _, containerStatus := range p.Status.ContainerStatuses {
+			containerID := native.TrimContainerIDPrefix(containerStatus.ContainerID)
+			if containerID == "" {
+				continue
+			}
+			existed.Insert(containerID)
+
+			current, err := w.getMemoryEvents(string(p.UID), containerID)
+			if err != nil {
+				general.Warningf("get memory events for pod %s/%s container %s failed: %v",
+					p.Namespace, p.Name, containerStatus.Name, err)
+				continue
+			}
+
+			last, ok := w.lastEvents[containerID]
+			w.lastEvents[containerID] = current
+			if !ok {
+				continue
+			}
+
+			oom, oomKill := increment(last.OOM, current.OOM), increment(last.OOMKill, current.OOMKill)
+			if oom == 0 && oomKill == 0 {
+				continue
+			}
+			w.report(p, containerStatus.Name, containerID, oom, oomKill)
+		}
+	}
+
+	for containerID := range w.lastEvents {
+		if !existed.Has(containerID) {
+			delete(w.lastEvents, containerID)
+		}
+	}
+}
+
+func (w *OOMWatcher) report(p *v1.Pod, containerName, containerID string, oom, oomKill uint64) {
+	general.Warningf("pod %s/%s container %s hit memory limit, oom: %d, oom kill: %d",
+		p.Namespace, p.Name, containerName, oom, oomKill)
+
+	tags := []metrics.MetricTag{
+		{Key: "namespace", Val: p.Namespace},
+		{Key: "podName", Val: p.Name},
+		{Key: "containerName", Val: containerName},
+	}
+	_ = w.emitter.StoreInt64(metricsNameContainerOOM, int64(oom), metrics.MetricTypeNameCount, tags...)
+	_ = w.emitter.StoreInt64(metricsNameContainerOOMKill, int64(oomKill), metrics.MetricTypeNameCount, tags...)
+
+	w.recorder.Eventf(p, nil, v1.EventTypeWarning, consts.EventReasonContainerOOM, consts.EventActionOOMWatching,
+		"container %s hit memory limit %d times, and %d processes are killed by OOM killer", containerName, oom, oomKill)
+
+	_ = w.eventBus.Publish(consts.TopicNameContainerOOM, eventbus.ContainerOOMEvent{
+		BaseEventImpl: eventbus.BaseEventImpl{
+			Time: time.Now(),
+		},
+		PodUID:        string(p.UID),
+		PodNamespace:  p.Namespace,
+		PodName:       p.Name,
+		ContainerName: containerName,
+		ContainerID:   containerID,
+		OOM:           oom,
+		OOMKill:       oomKill,
+	})
+}
+
+// increment returns the increment of a counter, and the counter is
+// taken as reset (e.g. the cgroup is re-created) if it decreases
+func increment(last, current uint64) uint64 {
+	if current < last {
+		return current
+	}
+	return current - last
+}
+
+func getContainerMemoryEvents(podUID, containerID string) (*common.MemoryEvents, error) {
+	absCgroupPath, err := common.GetContainerAbsCgroupPath(common.CgroupSubsysMemory, podUID, containerID)
+	if err != nil {
+		return nil, err
+	}
+	return cgroupmgr.GetMemoryEventsWithAbsolutePath(absCgroupPath)
+}
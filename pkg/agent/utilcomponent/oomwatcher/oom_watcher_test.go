@@ -0,0 +1,182 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oomwatcher
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/pod"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+)
+
+func makeRunningPod(uid, name, containerName, containerID string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       types.UID(uid),
+			Namespace: "default",
+			Name:      name,
+		},
+		Status: v1.PodStatus{
+			Phase: v1.PodRunning,
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					Name:        containerName,
+					ContainerID: "containerd://" + containerID,
+				},
+			},
+		},
+	}
+}
+
+func TestOOMWatcherWatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// rounds are memory events keyed by container id observed in each round
+		rounds       []map[string]*common.MemoryEvents
+		wantOOMEvent *eventbus.ContainerOOMEvent
+	}{
+		{
+			name: "first observation is taken as baseline",
+			rounds: []map[string]*common.MemoryEvents{
+				{"c1": {OOM: 3, OOMKill: 2}},
+			},
+		},
+		{
+			name: "no new oom",
+			rounds: []map[string]*common.MemoryEvents{
+				{"c1": {OOM: 3, OOMKill: 2}},
+				{"c1": {OOM: 3, OOMKill: 2}},
+			},
+		},
+		{
+			name: "new oom since last observation",
+			rounds: []map[string]*common.MemoryEvents{
+				{"c1": {OOM: 3, OOMKill: 2}},
+				{"c1": {OOM: 5, OOMKill: 3}},
+			},
+			wantOOMEvent: &eventbus.ContainerOOMEvent{
+				PodUID:        "uid1",
+				PodNamespace:  "default",
+				PodName:       "pod1",
+				ContainerName: "container1",
+				ContainerID:   "c1",
+				OOM:           2,
+				OOMKill:       1,
+			},
+		},
+		{
+			name: "memory events reset",
+			rounds: []map[string]*common.MemoryEvents{
+				{"c1": {OOM: 3, OOMKill: 2}},
+				{"c1": {OOM: 1, OOMKill: 1}},
+			},
+			wantOOMEvent: &eventbus.ContainerOOMEvent{
+				PodUID:        "uid1",
+				PodNamespace:  "default",
+				PodName:       "pod1",
+				ContainerName: "container1",
+				ContainerID:   "c1",
+				OOM:           1,
+				OOMKill:       1,
+			},
+		},
+		{
+			name: "failed to get memory events",
+			rounds: []map[string]*common.MemoryEvents{
+				{"c1": {OOM: 3, OOMKill: 2}},
+				{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			bus := eventbus.NewEventBus(10)
+			oomEvents := make(chan interface{}, 10)
+			require.NoError(t, bus.Subscribe(consts.TopicNameContainerOOM, "test", 10, func(event interface{}) error {
+				oomEvents <- event
+				return nil
+			}))
+			recorder := events.NewFakeRecorder(10)
+
+			podFetcher := &pod.PodFetcherStub{PodList: []*v1.Pod{makeRunningPod("uid1", "pod1", "container1", "c1")}}
+			watcher := NewOOMWatcher(podFetcher, bus, recorder, metrics.DummyMetrics{}, time.Second)
+
+			for _, round := range tt.rounds {
+				watcher.getMemoryEvents = func(podUID, containerID string) (*common.MemoryEvents, error) {
+					if e, ok := round[containerID]; ok {
+						return e, nil
+					}
+					return nil, fmt.Errorf("memory events of %s not found", containerID)
+				}
+				watcher.Watch(context.Background())
+			}
+
+			if tt.wantOOMEvent == nil {
+				assert.Never(t, func() bool { return len(oomEvents) > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+				assert.Empty(t, recorder.Events)
+				return
+			}
+
+			select {
+			case event := <-oomEvents:
+				oomEvent, ok := event.(eventbus.ContainerOOMEvent)
+				require.True(t, ok)
+				oomEvent.BaseEventImpl = eventbus.BaseEventImpl{}
+				assert.Equal(t, *tt.wantOOMEvent, oomEvent)
+			case <-time.After(time.Second):
+				t.Fatal("no oom event is published")
+			}
+			assert.Len(t, recorder.Events, 1)
+		})
+	}
+}
+
+func TestOOMWatcherForgetRemovedContainers(t *testing.T) {
+	t.Parallel()
+
+	podFetcher := &pod.PodFetcherStub{PodList: []*v1.Pod{makeRunningPod("uid1", "pod1", "container1", "c1")}}
+	watcher := NewOOMWatcher(podFetcher, eventbus.NewEventBus(10), events.NewFakeRecorder(10), metrics.DummyMetrics{}, time.Second)
+	watcher.getMemoryEvents = func(podUID, containerID string) (*common.MemoryEvents, error) {
+		return &common.MemoryEvents{}, nil
+	}
+
+	watcher.Watch(context.Background())
+	assert.Contains(t, watcher.lastEvents, "c1")
+
+	podFetcher.PodList = nil
+	watcher.Watch(context.Background())
+	assert.Empty(t, watcher.lastEvents)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ControllerRevisionControl is used to manage ControllerRevision
+type ControllerRevisionControl interface {
+	// CreateControllerRevision is used to create new ControllerRevision obj
+	CreateControllerRevision(ctx context.Context, revision *appsv1.ControllerRevision,
+		opts metav1.CreateOptions) (*appsv1.ControllerRevision, error)
+
+	// DeleteControllerRevision is used to delete ControllerRevision obj
+	DeleteControllerRevision(ctx context.Context, namespace, name string,
+		opts metav1.DeleteOptions) error
+}
+
+type DummyControllerRevisionControl struct{}
+
+func (d DummyControllerRevisionControl) CreateControllerRevision(_ context.Context, revision *appsv1.ControllerRevision,
+	_ metav1.CreateOptions,
+) (*appsv1.ControllerRevision, error) {
+	return revision, nil
+}
+
+func (d DummyControllerRevisionControl) DeleteControllerRevision(_ context.Context, _, _ string,
+	_ metav1.DeleteOptions,
+) error {
+	return nil
+}
+
+type RealControllerRevisionControl struct {
+	client kubernetes.Interface
+}
+
+func NewRealControllerRevisionControl(client kubernetes.Interface) *RealControllerRevisionControl {
+	return &RealControllerRevisionControl{
+		client: client,
+	}
+}
+
+func (r *RealControllerRevisionControl) CreateControllerRevision(ctx context.Context, revision *appsv1.ControllerRevision,
+	opts metav1.CreateOptions,
+) (*appsv1.ControllerRevision, error) {
+	return r.client.AppsV1().ControllerRevisions(revision.Namespace).Create(ctx, revision, opts)
+}
+
+func (r *RealControllerRevisionControl) DeleteControllerRevision(ctx context.Context, namespace, name string,
+	opts metav1.DeleteOptions,
+) error {
+	return r.client.AppsV1().ControllerRevisions(namespace).Delete(ctx, name, opts)
+}
@@ -31,11 +31,16 @@ import (
 )
 
 type NocUpdater interface {
+	PatchNoc(ctx context.Context, oldNoc, newNoc *v1alpha1.NodeOvercommitConfig) (*v1alpha1.NodeOvercommitConfig, error)
 	PatchNocStatus(ctx context.Context, oldNoc, newNoc *v1alpha1.NodeOvercommitConfig) (*v1alpha1.NodeOvercommitConfig, error)
 }
 
 type DummyNocUpdater struct{}
 
+func (d *DummyNocUpdater) PatchNoc(_ context.Context, _, newNoc *v1alpha1.NodeOvercommitConfig) (*v1alpha1.NodeOvercommitConfig, error) {
+	return newNoc, nil
+}
+
 func (d *DummyNocUpdater) PatchNocStatus(_ context.Context, _, newNoc *v1alpha1.NodeOvercommitConfig) (*v1alpha1.NodeOvercommitConfig, error) {
 	return newNoc, nil
 }
@@ -50,6 +55,39 @@ type RealNocUpdater struct {
 	client clientset.Interface
 }
 
+// PatchNoc patches annotations and spec of noc
+func (r *RealNocUpdater) PatchNoc(ctx context.Context, oldNoc, newNoc *v1alpha1.NodeOvercommitConfig) (*v1alpha1.NodeOvercommitConfig, error) {
+	if oldNoc == nil || newNoc == nil {
+		return nil, fmt.Errorf("can't patch a nil noc")
+	}
+
+	oldData, err := json.Marshal(v1alpha1.NodeOvercommitConfig{
+		ObjectMeta: metav1.ObjectMeta{Annotations: oldNoc.Annotations},
+		Spec:       oldNoc.Spec,
+	})
+	if err != nil {
+		return nil, err
+	}
+	newData, err := json.Marshal(v1alpha1.NodeOvercommitConfig{
+		ObjectMeta: metav1.ObjectMeta{Annotations: newNoc.Annotations},
+		Spec:       newNoc.Spec,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	patchBytes, err := jsonmergepatch.CreateThreeWayJSONMergePatch(oldData, newData, oldData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create merge patch for nodeOvercommitConfig %s: %v",
+			oldNoc.Name, err)
+	}
+	if general.JsonPathEmpty(patchBytes) {
+		return newNoc, nil
+	}
+
+	return r.client.OvercommitV1alpha1().NodeOvercommitConfigs().Patch(ctx, oldNoc.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{})
+}
+
 func (r *RealNocUpdater) PatchNocStatus(ctx context.Context, oldNoc, newNoc *v1alpha1.NodeOvercommitConfig) (*v1alpha1.NodeOvercommitConfig, error) {
 	if oldNoc == nil || newNoc == nil {
 		return nil, fmt.Errorf("can't patch a nil noc")
@@ -51,6 +51,8 @@ type GenericAgentConfiguration struct {
 	*global.PluginManagerConfiguration
 	*global.QRMAdvisorConfiguration
 	*global.AuditConfiguration
+	*global.CgroupReconcileConfiguration
+	*global.OOMWatcherConfiguration
 
 	*metaserver.MetaServerConfiguration
 	*eviction.GenericEvictionConfiguration
@@ -72,6 +74,8 @@ func NewGenericAgentConfiguration() *GenericAgentConfiguration {
 		BaseConfiguration:              global.NewBaseConfiguration(),
 		PluginManagerConfiguration:     global.NewPluginManagerConfiguration(),
 		AuditConfiguration:             global.NewAuditConfiguration(),
+		CgroupReconcileConfiguration:   global.NewCgroupReconcileConfiguration(),
+		OOMWatcherConfiguration:        global.NewOOMWatcherConfiguration(),
 		MetaServerConfiguration:        metaserver.NewMetaServerConfiguration(),
 		QRMAdvisorConfiguration:        global.NewQRMAdvisorConfiguration(),
 		GenericEvictionConfiguration:   eviction.NewGenericEvictionConfiguration(),
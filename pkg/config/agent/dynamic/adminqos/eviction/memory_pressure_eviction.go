@@ -26,6 +26,8 @@ import (
 const (
 	FakeMetricQoSLevel = "qos.pod"
 	FakeMetricPriority = "priority.pod"
+	// FakeMetricSwapUsage is read from memory.swap.current of pod cgroup directly
+	FakeMetricSwapUsage = "swap.usage.pod"
 )
 
 const (
@@ -21,6 +21,7 @@ import (
 
 	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/metricthreshold"
 	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/strategygroup"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/swap"
 	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/tmo"
 
 	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/adminqos"
@@ -59,6 +60,7 @@ type Configuration struct {
 	*tmo.TransparentMemoryOffloadingConfiguration
 	*strategygroup.StrategyGroupConfiguration
 	*metricthreshold.MetricThresholdConfiguration
+	*swap.SwapConfiguration
 }
 
 func NewConfiguration() *Configuration {
@@ -68,6 +70,7 @@ func NewConfiguration() *Configuration {
 		TransparentMemoryOffloadingConfiguration: tmo.NewTransparentMemoryOffloadingConfiguration(),
 		StrategyGroupConfiguration:               strategygroup.NewStrategyGroupConfiguration(),
 		MetricThresholdConfiguration:             metricthreshold.NewMetricThresholdConfiguration(),
+		SwapConfiguration:                        swap.NewSwapConfiguration(),
 	}
 }
 
@@ -77,4 +80,5 @@ func (c *Configuration) ApplyConfiguration(conf *crd.DynamicConfigCRD) {
 	c.TransparentMemoryOffloadingConfiguration.ApplyConfiguration(conf)
 	c.StrategyGroupConfiguration.ApplyConfiguration(conf)
 	c.MetricThresholdConfiguration.ApplyConfiguration(conf)
+	c.SwapConfiguration.ApplyConfiguration(conf)
 }
@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package swap
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/crd"
+	coreconsts "github.com/kubewharf/katalyst-core/pkg/consts"
+)
+
+// SwapConfiguration is the swap budget of containers in each QoS level,
+// and swap of the QoS levels not configured is left untouched. budgets are
+// set by flags and may be overridden by KCC, and they are independent of
+// whether TMO reclaims memory to swap.
+type SwapConfiguration struct {
+	QoSLevelSwapConfigs map[consts.QoSLevel]*SwapConfigDetail
+}
+
+func NewSwapConfiguration() *SwapConfiguration {
+	return &SwapConfiguration{
+		QoSLevelSwapConfigs: map[consts.QoSLevel]*SwapConfigDetail{},
+	}
+}
+
+// SwapConfigDetail limits memory.swap.max and memory.zswap.max as ratios of
+// memory limit of the container, and zero ratio means never swap.
+type SwapConfigDetail struct {
+	SwapMaxRatio  float64 `json:"swapMaxRatio"`
+	ZswapMaxRatio float64 `json:"zswapMaxRatio"`
+}
+
+// ApplyConfiguration overrides budgets of all QoS levels by the ones in the annotation of
+// AdminQoSConfiguration, since AdminQoSConfiguration doesn't carry them yet; budgets are
+// kept unchanged if the annotation is invalid.
+func (c *SwapConfiguration) ApplyConfiguration(conf *crd.DynamicConfigCRD) {
+	if aqc := conf.AdminQoSConfiguration; aqc != nil {
+		value, ok := aqc.Annotations[coreconsts.AdminQoSAnnotationKeyQoSLevelSwapConfigs]
+		if !ok {
+			return
+		}
+
+		swapConfigs, err := parseQoSLevelSwapConfigs(value)
+		if err != nil {
+			klog.Errorf("invalid annotation %v of %v/%v: %v", coreconsts.AdminQoSAnnotationKeyQoSLevelSwapConfigs,
+				aqc.Namespace, aqc.Name, err)
+			return
+		}
+		c.QoSLevelSwapConfigs = swapConfigs
+	}
+}
+
+func parseQoSLevelSwapConfigs(value string) (map[consts.QoSLevel]*SwapConfigDetail, error) {
+	swapConfigs := map[consts.QoSLevel]*SwapConfigDetail{}
+	if err := json.Unmarshal([]byte(value), &swapConfigs); err != nil {
+		return nil, err
+	}
+
+	for qosLevel, detail := range swapConfigs {
+		if detail == nil {
+			return nil, fmt.Errorf("nil swap budget for %v", qosLevel)
+		} else if detail.SwapMaxRatio < 0 || detail.SwapMaxRatio > 1 {
+			return nil, fmt.Errorf("swap max ratio %v for %v is out of range [0, 1]", detail.SwapMaxRatio, qosLevel)
+		} else if detail.ZswapMaxRatio < 0 || detail.ZswapMaxRatio > 1 {
+			return nil, fmt.Errorf("zswap max ratio %v for %v is out of range [0, 1]", detail.ZswapMaxRatio, qosLevel)
+		}
+	}
+	return swapConfigs, nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package swap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/config/v1alpha1"
+	"github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/crd"
+	coreconsts "github.com/kubewharf/katalyst-core/pkg/consts"
+)
+
+func TestSwapConfiguration_ApplyConfiguration(t *testing.T) {
+	t.Parallel()
+
+	flagConfigs := map[consts.QoSLevel]*SwapConfigDetail{
+		consts.QoSLevelSharedCores: {SwapMaxRatio: 0.1},
+	}
+
+	for _, tc := range []struct {
+		name        string
+		annotations map[string]string
+		want        map[consts.QoSLevel]*SwapConfigDetail
+	}{
+		{
+			name: "no annotation",
+			want: flagConfigs,
+		},
+		{
+			name: "budgets overridden by annotation",
+			annotations: map[string]string{
+				coreconsts.AdminQoSAnnotationKeyQoSLevelSwapConfigs: `{"reclaimed_cores":{"swapMaxRatio":0.2,"zswapMaxRatio":0.1},` +
+					`"dedicated_cores":{"swapMaxRatio":0}}`,
+			},
+			want: map[consts.QoSLevel]*SwapConfigDetail{
+				consts.QoSLevelReclaimedCores: {SwapMaxRatio: 0.2, ZswapMaxRatio: 0.1},
+				consts.QoSLevelDedicatedCores: {},
+			},
+		},
+		{
+			name: "empty annotation disables all budgets",
+			annotations: map[string]string{
+				coreconsts.AdminQoSAnnotationKeyQoSLevelSwapConfigs: `{}`,
+			},
+			want: map[consts.QoSLevel]*SwapConfigDetail{},
+		},
+		{
+			name: "invalid json is ignored",
+			annotations: map[string]string{
+				coreconsts.AdminQoSAnnotationKeyQoSLevelSwapConfigs: `{"reclaimed_cores":`,
+			},
+			want: flagConfigs,
+		},
+		{
+			name: "ratio out of range is ignored",
+			annotations: map[string]string{
+				coreconsts.AdminQoSAnnotationKeyQoSLevelSwapConfigs: `{"reclaimed_cores":{"swapMaxRatio":1.5}}`,
+			},
+			want: flagConfigs,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := NewSwapConfiguration()
+			c.QoSLevelSwapConfigs = flagConfigs
+			c.ApplyConfiguration(&crd.DynamicConfigCRD{
+				AdminQoSConfiguration: &v1alpha1.AdminQoSConfiguration{
+					ObjectMeta: metav1.ObjectMeta{Name: "default", Annotations: tc.annotations},
+				},
+			})
+			assert.Equal(t, tc.want, c.QoSLevelSwapConfigs)
+		})
+	}
+}
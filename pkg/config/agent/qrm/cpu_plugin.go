@@ -50,6 +50,9 @@ type CPUDynamicPolicyConfig struct {
 	EnableCPUIdle bool
 	// EnableMetricPreferredNumaAllocation indicates whether to enable metric preferred numa allocation
 	EnableMetricPreferredNumaAllocation bool
+	// EnableL3CacheAwareAllocation indicates whether to allocate cpus in as few L3 caches as possible in each NUMA,
+	// so that dedicated pods prefer whole L3 caches and reclaimed pools are kept on separate L3 caches
+	EnableL3CacheAwareAllocation bool
 	// SharedCoresNUMABindingResultAnnotationKey is the annotation key for storing NUMA binding results of shared_cores QoS pods.
 	// It enables schedulers to specify NUMA binding results, and the plugin will make best efforts to follow these results.
 	// This key must be included in the pod-annotation-kept-keys configuration.
@@ -52,6 +52,8 @@ type MemoryQRMPluginConfig struct {
 	LogCacheQRMPluginConfig
 	// FragMemOptions: the configuration for memory compaction related features
 	FragMemOptions
+	// SwapQRMPluginConfig: the configuration for swap limitation in cgroup level
+	SwapQRMPluginConfig
 	// ResctrlConfig: the configuration for resctrl FS related hints
 	ResctrlConfig
 }
@@ -89,6 +91,12 @@ type FragMemOptions struct {
 	SetMemFragScoreAsync int
 }
 
+type SwapQRMPluginConfig struct {
+	// EnableSettingSwap is used to limit swap and zswap usage of containers by the
+	// swap budgets of their QoS levels in dynamic configuration
+	EnableSettingSwap bool
+}
+
 type ResctrlConfig struct {
 	// EnableResctrlHint is the flag that enable/disable resctrl option related pod admission
 	EnableResctrlHint bool
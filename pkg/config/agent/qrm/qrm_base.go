@@ -35,6 +35,10 @@ type GenericQRMPluginConfiguration struct {
 	// EnableSNBHighNumaPreference indicates whether to enable high numa preference for snb pods
 	// if set true, snb pod will be preferentially allocated on high numa node
 	EnableSNBHighNumaPreference bool
+	// CheckpointMigrationDryRun indicates whether to only report the migration of plugin
+	// checkpoints written by older versions; if set true, plugins whose checkpoints need to
+	// be migrated will refuse to start, and checkpoints are kept untouched
+	CheckpointMigrationDryRun bool
 }
 
 type QRMPluginsConfiguration struct {
@@ -34,6 +34,12 @@ type GenericReporterConfiguration struct {
 
 	// DefaultCNRLabels is the labels for CNR created by reporter
 	DefaultCNRLabels map[string]string
+
+	// NodeReporterFieldManager is the field manager used by node reporter in server-side apply,
+	// and only the fields owned by this manager will be changed by node reporter
+	NodeReporterFieldManager string
+	// NodeReporterResyncPeriod is the period to re-apply node contents even if they are unchanged
+	NodeReporterResyncPeriod time.Duration
 }
 
 type ReporterPluginsConfiguration struct {
@@ -25,6 +25,13 @@ import (
 type ResourceAdvisorConfiguration struct {
 	ResourceAdvisors []string
 
+	// DecisionTraceCapacity is the number of latest decision traces kept in memory
+	DecisionTraceCapacity int
+	// DecisionTraceDumpDir is the directory to dump decision traces into, and dumping is disabled if empty
+	DecisionTraceDumpDir string
+	// DecisionTraceMaxDumpFiles is the max number of dump files kept, and the oldest ones are removed beyond it
+	DecisionTraceMaxDumpFiles int
+
 	*cpu.CPUAdvisorConfiguration
 	*memory.MemoryAdvisorConfiguration
 }
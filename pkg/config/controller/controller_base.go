@@ -52,6 +52,7 @@ type ControllersConfiguration struct {
 	*OvercommitConfig
 	*TideConfig
 	*ResourceRecommenderConfig
+	*ReschedulingConfig
 }
 
 func NewGenericControllerConfiguration() *GenericControllerConfiguration {
@@ -70,5 +71,6 @@ func NewControllersConfiguration() *ControllersConfiguration {
 		OvercommitConfig:          NewOvercommitConfig(),
 		TideConfig:                NewTideConfig(),
 		ResourceRecommenderConfig: NewResourceRecommenderConfig(),
+		ReschedulingConfig:        NewReschedulingConfig(),
 	}
 }
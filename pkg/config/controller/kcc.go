@@ -26,8 +26,27 @@ type KCCConfig struct {
 	// DefaultGVRs indicates the gvr that need to watch by default.
 	// value is gvr string, e.g. "nodeprofiledescriptors.v1alpha1.node.katalyst.kubewharf.io"
 	DefaultGVRs []string
+
+	*RolloutGuardConfig
+}
+
+// RolloutGuardConfig configures the guard which watches the health of canary nodes
+// during kcc config rollouts, and pauses (and reverts) the rollouts once regressed.
+type RolloutGuardConfig struct {
+	EnableRolloutGuard bool
+	// RegressionThreshold is the max tolerated difference between the unhealthy ratio of
+	// canary nodes (updated to the new config) and that of the other target nodes.
+	RegressionThreshold float64
+	// MinUnhealthyNodes is the min number of unhealthy canary nodes to regard a rollout as regressed,
+	// which prevents a single flapping node from blocking a small canary.
+	MinUnhealthyNodes int
+	// EvictionThreshold is the number of evictions on a node since it received the new config
+	// to regard it as unhealthy.
+	EvictionThreshold int
 }
 
 func NewKCCConfig() *KCCConfig {
-	return &KCCConfig{}
+	return &KCCConfig{
+		RolloutGuardConfig: &RolloutGuardConfig{},
+	}
 }
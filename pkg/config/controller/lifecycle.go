@@ -22,6 +22,20 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 )
 
+// ComponentRemediation is the action to take when an agent component is degraded
+type ComponentRemediation string
+
+const (
+	// ComponentRemediationNone only records the degraded state without any actions
+	ComponentRemediationNone ComponentRemediation = "none"
+	// ComponentRemediationTaintReclaimed taints CNR to stop scheduling reclaimed_cores pods
+	ComponentRemediationTaintReclaimed ComponentRemediation = "taint-reclaimed"
+	// ComponentRemediationNoScheduleDedicated taints CNR to stop scheduling dedicated_cores pods
+	ComponentRemediationNoScheduleDedicated ComponentRemediation = "no-schedule-dedicated"
+	// ComponentRemediationEvictReclaimed evicts reclaimed_cores pods on the node
+	ComponentRemediationEvictReclaimed ComponentRemediation = "evict-reclaimed"
+)
+
 type CNRLifecycleConfig struct{}
 
 type CNCLifecycleConfig struct{}
@@ -39,6 +53,9 @@ type HealthzConfig struct {
 	// config for handling logic
 	HandlePeriod  time.Duration
 	AgentHandlers map[string]string
+	// ComponentRemediations maps from agent component to the remediation performed when it's degraded,
+	// and the components not in this map won't be handled.
+	ComponentRemediations map[string]ComponentRemediation
 
 	// config for disrupting logic
 	TaintQPS                 float32
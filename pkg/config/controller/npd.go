@@ -16,18 +16,49 @@ limitations under the License.
 
 package controller
 
+import (
+	"time"
+
+	"github.com/kubewharf/katalyst-core/pkg/util/datasource/prometheus"
+)
+
 type NPDConfig struct {
 	NPDMetricsPlugins []string
 
 	EnableScopeDuplicated bool
 
 	SyncWorkers int
+
+	*UsageMetricsPluginConfig
+}
+
+// UsageMetricsPluginConfig is the config of the in-tree npd metrics plugin,
+// which aggregates node and pod usage from prometheus.
+type UsageMetricsPluginConfig struct {
+	// DataSourcePromConfig is the prometheus datasource config
+	DataSourcePromConfig prometheus.PromConfig
+
+	// SyncPeriod is the interval to query and update usage metrics
+	SyncPeriod time.Duration
+	// Windows are the time windows that usage is aggregated over
+	Windows []time.Duration
+	// WindowRefreshRatio limits the query load of long windows on prometheus, i.e.
+	// each window is refreshed every max(SyncPeriod, window * WindowRefreshRatio)
+	WindowRefreshRatio float64
+	// Step is the resolution of samples used to aggregate usage
+	Step time.Duration
+	// EnablePodMetrics indicates whether to query the usage of all pods besides nodes,
+	// which is expensive for prometheus in large clusters
+	EnablePodMetrics bool
+	// NodeLabel is the prometheus label which identifies the node of a sample
+	NodeLabel string
 }
 
 func NewNPDConfig() *NPDConfig {
 	return &NPDConfig{
-		NPDMetricsPlugins:     []string{},
-		EnableScopeDuplicated: false,
-		SyncWorkers:           1,
+		NPDMetricsPlugins:        []string{},
+		EnableScopeDuplicated:    false,
+		SyncWorkers:              1,
+		UsageMetricsPluginConfig: &UsageMetricsPluginConfig{},
 	}
 }
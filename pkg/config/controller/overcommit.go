@@ -28,6 +28,30 @@ type NodeOvercommitConfig struct {
 
 	// time interval of reconcile overcommit config
 	ConfigReconcilePeriod time.Duration
+
+	Recommendation OvercommitRecommendationConfig
+}
+
+// OvercommitRecommendationConfig is the configuration to recommend overcommit ratio of each
+// overcommit config by realtime overcommit ratios of its matched nodes
+type OvercommitRecommendationConfig struct {
+	Enabled bool
+
+	// time interval to sample realtime overcommit ratios and update recommendations
+	SyncPeriod time.Duration
+	// percentile of realtime overcommit ratios of matched nodes taken in each sample
+	Percentile float64
+	// the recommendation is the min sample in the history window
+	HistoryWindow time.Duration
+	// min number of matched nodes with realtime overcommit ratios to take a sample
+	MinNodes int
+
+	// max increase of overcommit ratio in each auto-apply, decreases are applied at once
+	AutoApplyMaxStep float64
+	// min time interval between two increases of overcommit ratio
+	AutoApplyInterval time.Duration
+	// max overcommit ratio that can be auto-applied
+	AutoApplyMaxRatio float64
 }
 
 func NewOvercommitConfig() *OvercommitConfig {
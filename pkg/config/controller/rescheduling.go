@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import "time"
+
+type ReschedulingConfig struct {
+	// SyncPeriod is the interval to evaluate nodes and pick pods to evict
+	SyncPeriod time.Duration
+
+	// DryRun only reports decisions as events without evicting any pod
+	DryRun bool
+
+	// ChronicDuration is how long a node must keep unhealthy or imbalanced
+	// before pods are moved off from it
+	ChronicDuration time.Duration
+
+	// HotCPUUsageThreshold and HotMemoryUsageThreshold are the usage ratios (of node allocatable)
+	// reported in CNR, above which the node is considered hot
+	HotCPUUsageThreshold    float64
+	HotMemoryUsageThreshold float64
+
+	// NPDMetricScope and NPDMetricThresholds define node metrics in NPD,
+	// above any of which the node is considered hot
+	NPDMetricScope      string
+	NPDMetricThresholds map[string]float64
+
+	// NPDMetricWindow and NPDMetricAggregator select which of the metrics with the
+	// same name in NPD are compared with thresholds; zero values only match metrics
+	// without window or aggregator
+	NPDMetricWindow     time.Duration
+	NPDMetricAggregator string
+
+	// NPDMetricExpiration and NPDMetricExpirationWindowRatio define the max age of
+	// NPD metrics to be trusted, which is the larger one of NPDMetricExpiration and
+	// NPDMetricWindow * NPDMetricExpirationWindowRatio, since long windows are refreshed less often
+	NPDMetricExpiration            time.Duration
+	NPDMetricExpirationWindowRatio float64
+
+	// FragmentationThreshold is the ratio of free cpus outside the most free NUMA node,
+	// above which the node is considered fragmented; it only works for nodes
+	// with at least FragmentationMinFreeCPU free cpus
+	FragmentationThreshold  float64
+	FragmentationMinFreeCPU float64
+
+	// MaxEvictionsPerCycle and MaxEvictionsPerNode limit the number of pods
+	// evicted in each sync period in total and from each node
+	MaxEvictionsPerCycle int
+	MaxEvictionsPerNode  int
+
+	// EvictDedicatedCores allows pods with dedicated_cores qos level to be evicted
+	EvictDedicatedCores bool
+
+	// SkippedNamespaces are namespaces whose pods are never evicted
+	SkippedNamespaces []string
+}
+
+func NewReschedulingConfig() *ReschedulingConfig {
+	return &ReschedulingConfig{
+		NPDMetricThresholds: map[string]float64{},
+	}
+}
@@ -16,7 +16,26 @@ limitations under the License.
 
 package controller
 
-type TideConfig struct{}
+import (
+	"time"
+
+	"github.com/kubewharf/katalyst-core/pkg/util/datasource/prometheus"
+)
+
+type TideConfig struct {
+	// DataSourcePromConfig is the prometheus datasource config, which provides the history of
+	// online demand to predictive mode; only the demand observed by the controller itself is
+	// used to forecast if the address is empty
+	DataSourcePromConfig prometheus.PromConfig
+
+	// OnlineDemandQuery is the promql expression of online cpu demand (in cores) of a node pool,
+	// in which ${node_pool} is replaced by the name of the node pool and ${filters} by the base filter
+	OnlineDemandQuery string
+	// OnlineDemandHistoryDays is the number of previous days whose demand at the same time of day is used to forecast
+	OnlineDemandHistoryDays int
+	// OnlineDemandHistoryStep is the resolution of the demand history, and forecasts are refreshed at this interval
+	OnlineDemandHistoryStep time.Duration
+}
 
 func NewTideConfig() *TideConfig {
 	return &TideConfig{}
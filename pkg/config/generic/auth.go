@@ -16,6 +16,8 @@ limitations under the License.
 
 package generic
 
+import "time"
+
 // AuthConfiguration stores all configurations related to authentication and authorization
 type AuthConfiguration struct {
 	// Authentication type
@@ -24,6 +26,16 @@ type AuthConfiguration struct {
 	AccessControlType string
 
 	HttpStrictAuthentication bool
+
+	// TokenReviewAudiences are the audiences that bearer tokens must be issued for,
+	// and TokenReviewCacheTTL is the duration to cache authenticated tokens
+	TokenReviewAudiences []string
+	TokenReviewCacheTTL  time.Duration
+
+	// ClientCAFile is the CA bundle to verify client certificates, and
+	// CertificateUsernameSource decides whether CN or SAN is used as the user
+	ClientCAFile              string
+	CertificateUsernameSource string
 }
 
 func NewAuthConfiguration() *AuthConfiguration {
@@ -32,6 +32,10 @@ type GenericConfiguration struct {
 
 	GenericEndpoint             string
 	GenericEndpointHandleChains []string
+	// GenericEndpointTLSCertFile and GenericEndpointTLSKeyFile enable https for
+	// generic endpoint, which is required by client certificate authentication
+	GenericEndpointTLSCertFile string
+	GenericEndpointTLSKeyFile  string
 
 	*QoSConfiguration
 	*MetricsConfiguration
@@ -347,6 +347,25 @@ func (c *QoSConfiguration) GetQoSEnhancementKVs(pod *v1.Pod, expandedAnnotations
 	return flattenedEnhancements
 }
 
+// CheckQoSEnhancements checks whether all the enhancements in annotations can be
+// unmarshalled into k-v maps, since GetQoSEnhancementKVs will ignore those malformed ones;
+// - returns error with the first malformed enhancement key.
+func (c *QoSConfiguration) CheckQoSEnhancements(pod *v1.Pod, expandedAnnotations map[string]string) error {
+	annotations := c.getQoSEnhancements(MergeAnnotations(pod, expandedAnnotations))
+	for _, enhancementKey := range validQosEnhancementKey.List() {
+		enhancementValue, ok := annotations[enhancementKey]
+		if !ok {
+			continue
+		}
+
+		enhancements := map[string]string{}
+		if err := json.Unmarshal([]byte(enhancementValue), &enhancements); err != nil {
+			return fmt.Errorf("parse enhancement %s failed: %v", enhancementKey, err)
+		}
+	}
+	return nil
+}
+
 // GetQoSEnhancements returns the standard katalyst QoS Enhancement Map for given annotations;
 // - ignore conflict cases: default enhancement key always prior to expand enhancement key
 func (c *QoSConfiguration) getQoSEnhancements(annotations map[string]string) map[string]string {
@@ -39,6 +39,18 @@ type CollectorConfiguration struct {
 	// depends on the authentication method. For now, we only support basic auth,so there should be two files with name
 	// username and password.
 	CredentialPath string
+
+	// PushListenAddress is the address that push-collector listens on to accept pushed samples;
+	// PushMaxPendingSamples and PushMaxInflightRequests bound the in-memory buffer and concurrent
+	// requests, and the requests beyond those limits will be rejected to let agents back off.
+	PushListenAddress       string
+	PushMaxPendingSamples   int
+	PushMaxInflightRequests int
+
+	// PushPrivilegedSubjects are the subjects allowed to push metrics of any objects, while other
+	// subjects must be node credentials (user system:node:<name> in group system:nodes), and they can
+	// only push metrics of themselves and pods on them.
+	PushPrivilegedSubjects []string
 }
 
 func NewCollectorConfiguration() *CollectorConfiguration {
@@ -43,6 +43,7 @@ type GenericWebhookConfiguration struct {
 type WebhooksConfiguration struct {
 	*VPAConfig
 	*PodConfig
+	*KCCTConfig
 }
 
 func NewGenericWebhookConfiguration() *GenericWebhookConfiguration {
@@ -51,7 +52,8 @@ func NewGenericWebhookConfiguration() *GenericWebhookConfiguration {
 
 func NewWebhooksConfiguration() *WebhooksConfiguration {
 	return &WebhooksConfiguration{
-		VPAConfig: NewVPAConfig(),
-		PodConfig: NewPodConfig(),
+		VPAConfig:  NewVPAConfig(),
+		PodConfig:  NewPodConfig(),
+		KCCTConfig: NewKCCTConfig(),
 	}
 }
@@ -42,12 +42,19 @@ const (
 	EventReasonEvictSucceeded           = "EvictSucceeded"
 
 	EventReasonContainerStopped = "ContainerStopped"
+
+	EventReasonContainerOOM = "ContainerOOM"
+
+	EventReasonReschedulingSkipped = "ReschedulingSkipped"
+	EventReasonReschedulingDryRun  = "ReschedulingDryRun"
 )
 
 // const variable for pod eviction action identifier in event.
 const (
 	EventActionEvicting          = "Evicting"
 	EventActionContainerStopping = "ContainerStopping"
+	EventActionOOMWatching       = "OOMWatching"
+	EventActionRescheduling      = "Rescheduling"
 )
 
 // KeySeparator : to split parts of a key
@@ -90,8 +97,10 @@ var (
 
 // event bus topics
 const (
-	TopicNameApplyCGroup = "ApplyCGroup"
-	TopicNameSyscall     = "Syscall"
+	TopicNameApplyCGroup  = "ApplyCGroup"
+	TopicNameCGroupDrift  = "CGroupDrift"
+	TopicNameSyscall      = "Syscall"
+	TopicNameContainerOOM = "ContainerOOM"
 )
 
 const (
@@ -0,0 +1,30 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consts
+
+// AgentComponentHealthAnnotationKey is the annotation key in CNR to carry the health of each agent component,
+// and the value is a json-encoded map from component name to general.ComponentHealth
+const AgentComponentHealthAnnotationKey = "katalyst.kubewharf.io/agent_component_health"
+
+// const variables for agent components whose health is reported separately
+const (
+	AgentComponentQRMCPU          = "qrm-cpu"
+	AgentComponentQRMMemory       = "qrm-memory"
+	AgentComponentSysAdvisor      = "sysadvisor"
+	AgentComponentEvictionManager = "eviction-manager"
+	AgentComponentReporter        = "reporter"
+)
@@ -0,0 +1,27 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consts
+
+// const variables for ihpa annotations about scaling rules, each of them holds a json value.
+// IHPAAnnotationKeyScheduledMinReplicas, IHPAAnnotationKeyScaleUpOverride and IHPAAnnotationKeyScaleUpTriggers
+// are set by users or external event sources, IHPAAnnotationKeyScalingRulesStatus is set by the ihpa controller.
+const (
+	IHPAAnnotationKeyScheduledMinReplicas = "ihpa.autoscaling.katalyst.kubewharf.io/scheduled-min-replicas"
+	IHPAAnnotationKeyScaleUpOverride      = "ihpa.autoscaling.katalyst.kubewharf.io/scale-up-override"
+	IHPAAnnotationKeyScaleUpTriggers      = "ihpa.autoscaling.katalyst.kubewharf.io/scale-up-triggers"
+	IHPAAnnotationKeyScalingRulesStatus   = "ihpa.autoscaling.katalyst.kubewharf.io/scaling-rules-status"
+)
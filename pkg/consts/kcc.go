@@ -50,3 +50,16 @@ const (
 	KCCTargetConfFieldNameCollisionCount     = "collisionCount"
 	KCCTargetConfFieldNameObservedGeneration = "observedGeneration"
 )
+
+// annotation keys used by the rollout guard of kcc targets
+const (
+	// KCCTargetAnnotationKeyConfigRevisions records the hashes of recent configs of a kcc target, whose contents
+	// are stored in ControllerRevisions, with which CNCs can be reverted to an earlier hash of the same kcc target.
+	KCCTargetAnnotationKeyConfigRevisions = "kcct.katalyst.kubewharf.io/config-revisions"
+	// KCCTargetAnnotationKeyResumeRolloutHash resumes the rollout of the given hash if it's paused
+	// by the rollout guard, and the rollout won't be guarded any more.
+	KCCTargetAnnotationKeyResumeRolloutHash = "kcct.katalyst.kubewharf.io/resume-rollout-hash"
+)
+
+// KCCTargetClusterScopedRevisionNamespace is the namespace of ControllerRevisions for cluster-scoped kcc targets
+const KCCTargetClusterScopedRevisionNamespace = "kube-system"
@@ -0,0 +1,30 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consts
+
+// NPDScopeLoadAware is the npd scope carrying node and pod usage metrics,
+// which are aggregated over time windows and consumed by load-aware scheduling.
+const (
+	NPDScopeLoadAware = "loadAware"
+)
+
+// const variables for metric names in the load-aware npd scope, values of cpu
+// metrics are in cores while values of memory metrics are in bytes.
+const (
+	NPDMetricNameCPUUsage    = "cpu_usage"
+	NPDMetricNameMemoryUsage = "memory_usage"
+)
@@ -0,0 +1,27 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consts
+
+// const variables for noc annotations about overcommit ratio recommendation.
+// NocAnnotationAutoApplyRecommendationKey is set by users to apply the recommended ratio to spec
+// if it's "true", others are set by the overcommit controller since there are no such fields in noc status.
+const (
+	NocAnnotationRecommendedOvercommitRatioKey = "overcommit.katalyst.kubewharf.io/recommended_overcommit_ratio"
+	NocAnnotationOvercommitRatioHistoryKey     = "overcommit.katalyst.kubewharf.io/realtime_overcommit_ratio_history"
+	NocAnnotationAutoApplyRecommendationKey    = "overcommit.katalyst.kubewharf.io/auto_apply_recommendation"
+	NocAnnotationLastAutoApplyTimeKey          = "overcommit.katalyst.kubewharf.io/last_auto_apply_time"
+)
@@ -27,3 +27,11 @@ const (
 	// QRMResourceAnnotationKeyNUMABindResult is the annotation key for the numa binding result
 	QRMResourceAnnotationKeyNUMABindResult = "qrm.katalyst.kubewharf.io/numa_bind_result"
 )
+
+const (
+	// AdminQoSAnnotationKeyQoSLevelSwapConfigs is the annotation key in AdminQoSConfiguration for
+	// swap budgets of QoS levels, and its value is a json map from QoS level to its swap budget, e.g.
+	// {"reclaimed_cores":{"swapMaxRatio":0.2,"zswapMaxRatio":0.1}}; it will be replaced by fields
+	// in AdminQoSConfiguration once katalyst-api carries them.
+	AdminQoSAnnotationKeyQoSLevelSwapConfigs = "qrm.katalyst.kubewharf.io/qos-level-swap-configs"
+)
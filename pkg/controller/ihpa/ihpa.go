@@ -19,6 +19,7 @@ package ihpa
 import (
 	"context"
 	"fmt"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	v2 "k8s.io/api/autoscaling/v2"
@@ -52,8 +53,10 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/client/control"
 	"github.com/kubewharf/katalyst-core/pkg/config/controller"
 	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	katalystconsts "github.com/kubewharf/katalyst-core/pkg/consts"
 	resourceportrait "github.com/kubewharf/katalyst-core/pkg/controller/spd/indicator-plugin/plugins/resource-portrait"
 	katalystmetrics "github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util"
 )
 
 const (
@@ -232,6 +235,9 @@ func (p *IHPAController) syncIHPA(key string) error {
 		return err
 	}
 	ihpaCopy := ihpa.DeepCopy()
+	// work on a copy since annotations may be changed by scaling rules
+	ihpa = ihpa.DeepCopy()
+	now := time.Now()
 
 	podTemplate, err := p.syncWorkload(ihpa)
 	if err != nil {
@@ -239,6 +245,9 @@ func (p *IHPAController) syncIHPA(key string) error {
 		return err
 	}
 
+	// scale-up triggers are fired before hpa is generated, so that the override takes effect in this sync
+	p.syncScaleUpTriggers(ihpa, now)
+
 	hpa, err := p.syncHPA(ihpa, podTemplate)
 	if err != nil {
 		klog.Errorf("[ihpa] failed to sync hpa ihpa [%v]", key)
@@ -258,6 +267,21 @@ func (p *IHPAController) syncIHPA(key string) error {
 	}
 
 	updateStatus(ihpa, hpa, spd)
+	if err := updateScalingRulesStatus(ihpa, hpa, spd, now); err != nil {
+		klog.Errorf("[ihpa] failed to update scaling rules status for ihpa [%v]: %v", key, err)
+		return err
+	}
+
+	if !apiequality.Semantic.DeepEqual(ihpa.Annotations, ihpaCopy.Annotations) {
+		updated, err := p.ihpaUpdater.Update(p.ctx, ihpa, metav1.UpdateOptions{})
+		if err != nil {
+			klog.Errorf("[ihpa] failed to update annotations of ihpa [%v]", key)
+			return err
+		}
+		updated.Status = ihpa.Status
+		ihpa = updated
+	}
+
 	if !apiequality.Semantic.DeepEqual(ihpa.Status, ihpaCopy.Status) {
 		_, err = p.ihpaUpdater.UpdateStatus(p.ctx, ihpa, metav1.UpdateOptions{})
 		if err != nil {
@@ -266,9 +290,40 @@ func (p *IHPAController) syncIHPA(key string) error {
 		}
 	}
 
+	p.requeueAtScalingRulesBoundary(key, ihpa, now)
 	return nil
 }
 
+// requeueAtScalingRulesBoundary requeues ihpa when its scaling rules change next time, so that scheduled
+// windows and scale-up overrides take effect in time instead of waiting for the next resync.
+func (p *IHPAController) requeueAtScalingRulesBoundary(key string, ihpa *apiautoscaling.IntelligentHorizontalPodAutoscaler, now time.Time) {
+	if !util.CheckIHPAScalingRulesEnabled(ihpa) {
+		return
+	}
+
+	if next, ok := getNextScalingRulesBoundary(ihpa, now); ok {
+		klog.V(5).Infof("[ihpa] requeue ihpa [%v] at next scaling rules boundary %v", key, next)
+		p.ihpaSyncQueue.AddAfter(key, next.Sub(now))
+	}
+}
+
+// syncScaleUpTriggers fires scale-up triggers of ihpa with the current resource portrait in spd
+func (p *IHPAController) syncScaleUpTriggers(ihpa *apiautoscaling.IntelligentHorizontalPodAutoscaler, now time.Time) {
+	if _, ok := ihpa.Annotations[katalystconsts.IHPAAnnotationKeyScaleUpTriggers]; !ok {
+		return
+	}
+
+	spd, err := p.spdLister.ServiceProfileDescriptors(ihpa.Namespace).Get(ihpa.Spec.Autoscaler.ScaleTargetRef.Name)
+	if err != nil {
+		klog.Warningf("[ihpa] failed to get spd for scale-up triggers of ihpa %s/%s: %v", ihpa.Namespace, ihpa.Name, err)
+		return
+	}
+
+	if usage, ok := getCurrentPortraitUsage(spd, now); ok {
+		fireScaleUpTriggers(ihpa, usage, now)
+	}
+}
+
 func (p *IHPAController) syncWorkload(ihpa *apiautoscaling.IntelligentHorizontalPodAutoscaler) (*corev1.PodTemplateSpec, error) {
 	gvk := schema.FromAPIVersionAndKind(ihpa.Spec.Autoscaler.ScaleTargetRef.APIVersion, ihpa.Spec.Autoscaler.ScaleTargetRef.Kind)
 	if lister, ok := p.workloadLister[gvk]; ok {
@@ -19,6 +19,7 @@ package ihpa
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/kubewharf/katalyst-api/pkg/apis/config/v1alpha1"
 
@@ -28,12 +29,14 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/utils/pointer"
 
 	apiautoscaling "github.com/kubewharf/katalyst-api/pkg/apis/autoscaling/v1alpha2"
 	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
 	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
 	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	katalystconsts "github.com/kubewharf/katalyst-core/pkg/consts"
 )
 
 func TestResourcePortraitIndicatorPlugin(t *testing.T) {
@@ -141,3 +144,69 @@ func TestResourcePortraitIndicatorPlugin(t *testing.T) {
 		})
 	}
 }
+
+// delayRecordingQueue records items added with delay instead of queueing them
+type delayRecordingQueue struct {
+	workqueue.RateLimitingInterface
+	delays map[interface{}]time.Duration
+}
+
+func (q *delayRecordingQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.delays[item] = duration
+}
+
+func TestIHPAController_requeueAtScalingRulesBoundary(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 8, 59, 50, 0, time.UTC)
+	conf := &controller.IHPAConfig{ResyncPeriod: time.Minute}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wantDelay   time.Duration
+		wantQueued  bool
+	}{
+		{
+			name: "window starts between resyncs",
+			annotations: map[string]string{
+				katalystconsts.IHPAAnnotationKeyScheduledMinReplicas: `[{"cronTab":"0 9 * * *","duration":"10m","minReplicas":50}]`,
+			},
+			wantDelay:  10 * time.Second,
+			wantQueued: true,
+		},
+		{
+			name: "override expires before window starts",
+			annotations: map[string]string{
+				katalystconsts.IHPAAnnotationKeyScheduledMinReplicas: `[{"cronTab":"0 9 * * *","duration":"10m","minReplicas":50}]`,
+				katalystconsts.IHPAAnnotationKeyScaleUpOverride:      `{"minReplicas":80,"expireTime":"2024-01-01T08:59:55Z"}`,
+			},
+			wantDelay:  5 * time.Second,
+			wantQueued: true,
+		},
+		{
+			name:       "no scaling rules",
+			wantQueued: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			queue := &delayRecordingQueue{delays: make(map[interface{}]time.Duration)}
+			ctrl := &IHPAController{conf: conf, ihpaSyncQueue: queue}
+			ihpa := &apiautoscaling.IntelligentHorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Name: "x", Namespace: "x", Annotations: tt.annotations},
+			}
+
+			ctrl.requeueAtScalingRulesBoundary("x/x", ihpa, now)
+			delay, ok := queue.delays["x/x"]
+			assert.Equal(t, tt.wantQueued, ok)
+			assert.Equal(t, tt.wantDelay, delay)
+			if ok {
+				assert.Less(t, delay, conf.ResyncPeriod, "boundary is between resyncs")
+			}
+		})
+	}
+}
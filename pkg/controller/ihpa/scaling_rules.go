@@ -0,0 +1,220 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ihpa
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	v2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/autoscaling/v1alpha2"
+	apiworkload "github.com/kubewharf/katalyst-api/pkg/apis/workload/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/util"
+)
+
+// getScheduledMinReplicas returns the max min replicas of scheduled windows which are active at now,
+// crontab is evaluated in the time zone of now unless it's prefixed with CRON_TZ.
+func getScheduledMinReplicas(scheduled []util.ScheduledMinReplicas, now time.Time) int32 {
+	var minReplicas int32
+	for _, s := range scheduled {
+		schedule, err := cron.ParseStandard(s.CronTab)
+		if err != nil {
+			klog.Errorf("[ihpa] parse crontab %q err: %v", s.CronTab, err)
+			continue
+		}
+
+		// the window is active if it has been started in the last duration
+		if schedule.Next(now.Add(-s.Duration.Duration)).After(now) {
+			continue
+		}
+
+		if s.MinReplicas > minReplicas {
+			minReplicas = s.MinReplicas
+		}
+	}
+	return minReplicas
+}
+
+// getNextScheduledBoundary returns the earliest time after now at which any scheduled window starts or ends,
+// and false if there is no such time.
+func getNextScheduledBoundary(scheduled []util.ScheduledMinReplicas, now time.Time) (time.Time, bool) {
+	var next time.Time
+	for _, s := range scheduled {
+		schedule, err := cron.ParseStandard(s.CronTab)
+		if err != nil {
+			continue
+		}
+
+		boundaries := []time.Time{schedule.Next(now)}
+		// the window is active if it has been started in the last duration, and it ends after the duration
+		if start := schedule.Next(now.Add(-s.Duration.Duration)); !start.IsZero() && !start.After(now) {
+			boundaries = append(boundaries, start.Add(s.Duration.Duration))
+		}
+
+		for _, boundary := range boundaries {
+			if boundary.After(now) && (next.IsZero() || boundary.Before(next)) {
+				next = boundary
+			}
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// getNextScalingRulesBoundary returns the earliest time after now at which scaling rules of ihpa change,
+// i.e. a scheduled window starts or ends or the scale-up override expires, and false if there is no such time.
+func getNextScalingRulesBoundary(ihpa *v1alpha2.IntelligentHorizontalPodAutoscaler, now time.Time) (time.Time, bool) {
+	var next time.Time
+	if scheduled, err := util.GetIHPAScheduledMinReplicas(ihpa); err == nil {
+		next, _ = getNextScheduledBoundary(scheduled, now)
+	}
+
+	if override := getActiveScaleUpOverride(ihpa, now); override != nil &&
+		(next.IsZero() || override.ExpireTime.Time.Before(next)) {
+		next = override.ExpireTime.Time
+	}
+	return next, !next.IsZero()
+}
+
+// getActiveScaleUpOverride returns the scale-up override of ihpa if it hasn't expired at now
+func getActiveScaleUpOverride(ihpa *v1alpha2.IntelligentHorizontalPodAutoscaler, now time.Time) *util.ScaleUpOverride {
+	override, err := util.GetIHPAScaleUpOverride(ihpa)
+	if err != nil {
+		klog.Errorf("[ihpa] failed to get scale-up override of ihpa %s/%s: %v", ihpa.Namespace, ihpa.Name, err)
+		return nil
+	}
+
+	if override == nil || !now.Before(override.ExpireTime.Time) {
+		return nil
+	}
+	return override
+}
+
+// fireScaleUpTriggers sets a scale-up override to ihpa if any trigger reaches its threshold
+// in the current resource portrait, and returns true if the override is changed.
+func fireScaleUpTriggers(ihpa *v1alpha2.IntelligentHorizontalPodAutoscaler, usage corev1.ResourceList, now time.Time) bool {
+	triggers, err := util.GetIHPAScaleUpTriggers(ihpa)
+	if err != nil {
+		klog.Errorf("[ihpa] failed to get scale-up triggers of ihpa %s/%s: %v", ihpa.Namespace, ihpa.Name, err)
+		return false
+	}
+
+	override := getActiveScaleUpOverride(ihpa, now)
+	changed := false
+	for _, trigger := range triggers {
+		value, ok := usage[trigger.Metric]
+		if !ok || value.Cmp(trigger.Threshold) < 0 {
+			continue
+		}
+
+		// an active override with more replicas is kept, and the one with the same replicas is only
+		// extended after half of the duration elapses, to avoid updating ihpa in each sync
+		if override != nil && (override.MinReplicas > trigger.MinReplicas ||
+			(override.MinReplicas == trigger.MinReplicas && override.ExpireTime.Sub(now) > trigger.Duration.Duration/2)) {
+			continue
+		}
+
+		klog.Infof("[ihpa] scale-up trigger of ihpa %s/%s fired: metric %s %s reaches %s, min replicas %d",
+			ihpa.Namespace, ihpa.Name, trigger.Metric, value.String(), trigger.Threshold.String(), trigger.MinReplicas)
+		override = &util.ScaleUpOverride{
+			MinReplicas: trigger.MinReplicas,
+			ExpireTime:  metav1.NewTime(now.Add(trigger.Duration.Duration).Truncate(time.Second)),
+			Reason:      fmt.Sprintf("metric %s reaches %s", trigger.Metric, trigger.Threshold.String()),
+		}
+		changed = true
+	}
+
+	if !changed {
+		return false
+	}
+
+	if err := util.SetIHPAScaleUpOverride(ihpa, override); err != nil {
+		klog.Errorf("[ihpa] failed to set scale-up override of ihpa %s/%s: %v", ihpa.Namespace, ihpa.Name, err)
+		return false
+	}
+	return true
+}
+
+// getScalingRulesStatus evaluates scheduled windows and the scale-up override of ihpa at now
+func getScalingRulesStatus(ihpa *v1alpha2.IntelligentHorizontalPodAutoscaler, now time.Time) *util.IHPAScalingRulesStatus {
+	status := &util.IHPAScalingRulesStatus{}
+
+	scheduled, err := util.GetIHPAScheduledMinReplicas(ihpa)
+	if err != nil {
+		klog.Errorf("[ihpa] failed to get scheduled min replicas of ihpa %s/%s: %v", ihpa.Namespace, ihpa.Name, err)
+	} else {
+		status.ScheduledMinReplicas = getScheduledMinReplicas(scheduled, now)
+	}
+
+	if override := getActiveScaleUpOverride(ihpa, now); override != nil {
+		status.OverrideMinReplicas = override.MinReplicas
+		status.OverrideExpireTime = override.ExpireTime.DeepCopy()
+		status.OverrideReason = override.Reason
+	}
+	return status
+}
+
+// getScalingRulesMinReplicas returns the min replicas required by scaling rules, it's 0 if no rule is active
+func getScalingRulesMinReplicas(status *util.IHPAScalingRulesStatus) int32 {
+	if status.OverrideMinReplicas > status.ScheduledMinReplicas {
+		return status.OverrideMinReplicas
+	}
+	return status.ScheduledMinReplicas
+}
+
+// applyScalingRulesMinReplicas raises min replicas of hpa to the one required by scaling rules, bounded by max replicas
+func applyScalingRulesMinReplicas(min *int32, max int32, status *util.IHPAScalingRulesStatus) *int32 {
+	rulesMin := getScalingRulesMinReplicas(status)
+	if rulesMin > max {
+		rulesMin = max
+	}
+
+	if rulesMin <= 0 || (min != nil && *min >= rulesMin) {
+		return min
+	}
+	return &rulesMin
+}
+
+// updateScalingRulesStatus merges the predicted replicas with scaling rules as desired replicas,
+// and records how they are merged in ihpa annotation.
+func updateScalingRulesStatus(ihpa *v1alpha2.IntelligentHorizontalPodAutoscaler, hpa *v2.HorizontalPodAutoscaler,
+	spd *apiworkload.ServiceProfileDescriptor, now time.Time,
+) error {
+	if !util.CheckIHPAScalingRulesEnabled(ihpa) {
+		return nil
+	}
+
+	status := getScalingRulesStatus(ihpa, now)
+	if usage, ok := getCurrentPortraitUsage(spd, now); ok && hpa != nil {
+		status.PredictedReplicas = getPredictedReplicas(ihpa, hpa, usage)
+	}
+
+	status.DesiredReplicas = status.PredictedReplicas
+	if rulesMin := getScalingRulesMinReplicas(status); rulesMin > status.DesiredReplicas {
+		status.DesiredReplicas = rulesMin
+	}
+	if maxReplicas := ihpa.Spec.Autoscaler.MaxReplicas; maxReplicas > 0 && status.DesiredReplicas > maxReplicas {
+		status.DesiredReplicas = maxReplicas
+	}
+
+	ihpa.Status.DesiredReplicas = status.DesiredReplicas
+	return util.SetIHPAScalingRulesStatus(ihpa, status)
+}
@@ -0,0 +1,398 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ihpa
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v2 "k8s.io/api/autoscaling/v2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	"k8s.io/utils/pointer"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/autoscaling/v1alpha2"
+	apiworkload "github.com/kubewharf/katalyst-api/pkg/apis/workload/v1alpha1"
+	katalystmetric "github.com/kubewharf/katalyst-api/pkg/metric"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	resourceportrait "github.com/kubewharf/katalyst-core/pkg/controller/spd/indicator-plugin/plugins/resource-portrait"
+	"github.com/kubewharf/katalyst-core/pkg/util"
+)
+
+func Test_getScheduledMinReplicas(t *testing.T) {
+	t.Parallel()
+
+	scheduled := []util.ScheduledMinReplicas{
+		{CronTab: "0 9 * * 1-5", Duration: metav1.Duration{Duration: 2 * time.Hour}, MinReplicas: 50},
+		{CronTab: "30 9 * * *", Duration: metav1.Duration{Duration: 30 * time.Minute}, MinReplicas: 80},
+		{CronTab: "invalid", Duration: metav1.Duration{Duration: time.Hour}, MinReplicas: 100},
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want int32
+	}{
+		{
+			name: "before weekday window",
+			now:  time.Date(2024, 1, 1, 8, 59, 0, 0, time.UTC),
+			want: 0,
+		},
+		{
+			name: "start of weekday window",
+			now:  time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+			want: 50,
+		},
+		{
+			name: "overlapped windows",
+			now:  time.Date(2024, 1, 1, 9, 45, 0, 0, time.UTC),
+			want: 80,
+		},
+		{
+			name: "end of weekday window",
+			now:  time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC),
+			want: 0,
+		},
+		{
+			name: "weekend",
+			now:  time.Date(2024, 1, 6, 10, 30, 0, 0, time.UTC),
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, getScheduledMinReplicas(scheduled, tt.now))
+		})
+	}
+}
+
+func Test_getNextScheduledBoundary(t *testing.T) {
+	t.Parallel()
+
+	scheduled := []util.ScheduledMinReplicas{
+		{CronTab: "0 9 * * 1-5", Duration: metav1.Duration{Duration: 2 * time.Hour}, MinReplicas: 50},
+		{CronTab: "30 9 * * *", Duration: metav1.Duration{Duration: 30 * time.Minute}, MinReplicas: 80},
+		{CronTab: "invalid", Duration: metav1.Duration{Duration: time.Hour}, MinReplicas: 100},
+	}
+
+	tests := []struct {
+		name      string
+		scheduled []util.ScheduledMinReplicas
+		now       time.Time
+		want      time.Time
+		wantOK    bool
+	}{
+		{
+			name:      "start of weekday window",
+			scheduled: scheduled,
+			now:       time.Date(2024, 1, 1, 8, 59, 50, 0, time.UTC),
+			want:      time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+			wantOK:    true,
+		},
+		{
+			name:      "start of overlapped window",
+			scheduled: scheduled,
+			now:       time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+			want:      time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC),
+			wantOK:    true,
+		},
+		{
+			name:      "end of overlapped window",
+			scheduled: scheduled,
+			now:       time.Date(2024, 1, 1, 9, 45, 0, 0, time.UTC),
+			want:      time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+			wantOK:    true,
+		},
+		{
+			name:      "end of weekday window",
+			scheduled: scheduled,
+			now:       time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC),
+			want:      time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC),
+			wantOK:    true,
+		},
+		{
+			name:      "no valid window",
+			scheduled: scheduled[2:],
+			now:       time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC),
+			wantOK:    false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := getNextScheduledBoundary(tt.scheduled, tt.now)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_fireScaleUpTriggers(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	triggers := `[{"metric":"qps","threshold":"1k","minReplicas":20,"duration":"30m"}]`
+
+	tests := []struct {
+		name         string
+		override     string
+		usage        v1.ResourceList
+		wantChanged  bool
+		wantOverride *util.ScaleUpOverride
+	}{
+		{
+			name:        "below threshold",
+			usage:       v1.ResourceList{"qps": resource.MustParse("999")},
+			wantChanged: false,
+		},
+		{
+			name:        "reach threshold",
+			usage:       v1.ResourceList{"qps": resource.MustParse("1k")},
+			wantChanged: true,
+			wantOverride: &util.ScaleUpOverride{
+				MinReplicas: 20,
+				ExpireTime:  metav1.NewTime(now.Add(30 * time.Minute)),
+				Reason:      "metric qps reaches 1k",
+			},
+		},
+		{
+			name:        "keep active override with more replicas",
+			override:    `{"minReplicas":30,"expireTime":"2024-01-01T09:05:00Z"}`,
+			usage:       v1.ResourceList{"qps": resource.MustParse("2k")},
+			wantChanged: false,
+			wantOverride: &util.ScaleUpOverride{
+				MinReplicas: 30,
+				ExpireTime:  metav1.NewTime(now.Add(5 * time.Minute)),
+			},
+		},
+		{
+			name:        "don't extend recent override",
+			override:    `{"minReplicas":20,"expireTime":"2024-01-01T09:20:00Z"}`,
+			usage:       v1.ResourceList{"qps": resource.MustParse("2k")},
+			wantChanged: false,
+			wantOverride: &util.ScaleUpOverride{
+				MinReplicas: 20,
+				ExpireTime:  metav1.NewTime(now.Add(20 * time.Minute)),
+			},
+		},
+		{
+			name:        "replace expired override",
+			override:    `{"minReplicas":30,"expireTime":"2024-01-01T08:00:00Z"}`,
+			usage:       v1.ResourceList{"qps": resource.MustParse("2k")},
+			wantChanged: true,
+			wantOverride: &util.ScaleUpOverride{
+				MinReplicas: 20,
+				ExpireTime:  metav1.NewTime(now.Add(30 * time.Minute)),
+				Reason:      "metric qps reaches 1k",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ihpa := &v1alpha2.IntelligentHorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{consts.IHPAAnnotationKeyScaleUpTriggers: triggers},
+				},
+			}
+			if tt.override != "" {
+				ihpa.Annotations[consts.IHPAAnnotationKeyScaleUpOverride] = tt.override
+			}
+
+			assert.Equal(t, tt.wantChanged, fireScaleUpTriggers(ihpa, tt.usage, now))
+			override, err := util.GetIHPAScaleUpOverride(ihpa)
+			require.NoError(t, err)
+			if tt.wantOverride == nil {
+				assert.Nil(t, override)
+				return
+			}
+			require.NotNil(t, override)
+			assert.Equal(t, tt.wantOverride.MinReplicas, override.MinReplicas)
+			assert.True(t, tt.wantOverride.ExpireTime.Equal(&override.ExpireTime))
+			assert.Equal(t, tt.wantOverride.Reason, override.Reason)
+		})
+	}
+}
+
+func Test_applyScalingRulesMinReplicas(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, pointer.Int32(2), applyScalingRulesMinReplicas(pointer.Int32(2), 10, &util.IHPAScalingRulesStatus{}))
+	assert.Equal(t, pointer.Int32(5), applyScalingRulesMinReplicas(nil, 10, &util.IHPAScalingRulesStatus{ScheduledMinReplicas: 5}))
+	assert.Equal(t, pointer.Int32(8), applyScalingRulesMinReplicas(pointer.Int32(2), 10,
+		&util.IHPAScalingRulesStatus{ScheduledMinReplicas: 5, OverrideMinReplicas: 8}))
+	assert.Equal(t, pointer.Int32(10), applyScalingRulesMinReplicas(pointer.Int32(2), 10, &util.IHPAScalingRulesStatus{OverrideMinReplicas: 20}))
+	assert.Equal(t, pointer.Int32(6), applyScalingRulesMinReplicas(pointer.Int32(6), 10, &util.IHPAScalingRulesStatus{ScheduledMinReplicas: 5}))
+}
+
+func Test_updateScalingRulesStatus(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	hpa := &v2.HorizontalPodAutoscaler{
+		Spec: v2.HorizontalPodAutoscalerSpec{
+			Metrics: []v2.MetricSpec{
+				{
+					Type: v2.ExternalMetricSourceType,
+					External: &v2.ExternalMetricSource{
+						Metric: v2.MetricIdentifier{
+							Name: katalystmetric.MetricNameSPDAggMetrics,
+							Selector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{
+									katalystmetric.MetricSelectorKeySPDName:          "dp1",
+									katalystmetric.MetricSelectorKeySPDContainerName: ResourcePortraitContainerName,
+									katalystmetric.MetricSelectorKeySPDResourceName:  "cpu",
+									katalystmetric.MetricSelectorKeySPDScopeName:     resourceportrait.ResourcePortraitPluginName,
+								},
+							},
+						},
+						Target: v2.MetricTarget{
+							Type:         v2.AverageValueMetricType,
+							AverageValue: resource.NewQuantity(1, resource.DecimalSI),
+						},
+					},
+				},
+			},
+		},
+	}
+	spd := &apiworkload.ServiceProfileDescriptor{
+		Status: apiworkload.ServiceProfileDescriptorStatus{
+			AggMetrics: []apiworkload.AggPodMetrics{
+				{
+					Scope: resourceportrait.ResourcePortraitPluginName,
+					Items: []v1beta1.PodMetrics{
+						{
+							Timestamp: metav1.NewTime(now.Add(-time.Minute)),
+							Containers: []v1beta1.ContainerMetrics{
+								{
+									Name:  ResourcePortraitContainerName,
+									Usage: v1.ResourceList{v1.ResourceCPU: resource.MustParse("12")},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	expireTime := metav1.NewTime(now.Add(time.Hour).Truncate(time.Second))
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		maxReplicas int32
+		spd         *apiworkload.ServiceProfileDescriptor
+		wantDesired int32
+		wantStatus  *util.IHPAScalingRulesStatus
+	}{
+		{
+			name:        "no scaling rules",
+			maxReplicas: 100,
+			spd:         spd,
+			wantDesired: 0,
+		},
+		{
+			name: "predicted replicas exceed rules",
+			annotations: map[string]string{
+				consts.IHPAAnnotationKeyScaleUpOverride: `{"minReplicas":10,"expireTime":"` + expireTime.UTC().Format(time.RFC3339) + `","reason":"sale"}`,
+			},
+			maxReplicas: 100,
+			spd:         spd,
+			wantDesired: 12,
+			wantStatus: &util.IHPAScalingRulesStatus{
+				OverrideMinReplicas: 10,
+				OverrideExpireTime:  &expireTime,
+				OverrideReason:      "sale",
+				PredictedReplicas:   12,
+				DesiredReplicas:     12,
+			},
+		},
+		{
+			name: "rules exceed predicted replicas",
+			annotations: map[string]string{
+				consts.IHPAAnnotationKeyScaleUpOverride: `{"minReplicas":20,"expireTime":"` + expireTime.UTC().Format(time.RFC3339) + `"}`,
+			},
+			maxReplicas: 100,
+			spd:         spd,
+			wantDesired: 20,
+			wantStatus: &util.IHPAScalingRulesStatus{
+				OverrideMinReplicas: 20,
+				OverrideExpireTime:  &expireTime,
+				PredictedReplicas:   12,
+				DesiredReplicas:     20,
+			},
+		},
+		{
+			name: "bounded by max replicas without portrait",
+			annotations: map[string]string{
+				consts.IHPAAnnotationKeyScheduledMinReplicas: `[{"cronTab":"* * * * *","duration":"1m","minReplicas":50}]`,
+			},
+			maxReplicas: 30,
+			wantDesired: 30,
+			wantStatus: &util.IHPAScalingRulesStatus{
+				ScheduledMinReplicas: 50,
+				DesiredReplicas:      30,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ihpa := &v1alpha2.IntelligentHorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+				Spec: v1alpha2.IntelligentHorizontalPodAutoscalerSpec{
+					Autoscaler: v1alpha2.AutoscalerSpec{
+						ScaleTargetRef: v2.CrossVersionObjectReference{Name: "dp1"},
+						MaxReplicas:    tt.maxReplicas,
+					},
+				},
+			}
+			require.NoError(t, updateScalingRulesStatus(ihpa, hpa, tt.spd, now))
+			assert.Equal(t, tt.wantDesired, ihpa.Status.DesiredReplicas)
+
+			status := &util.IHPAScalingRulesStatus{}
+			value, ok := ihpa.Annotations[consts.IHPAAnnotationKeyScalingRulesStatus]
+			if tt.wantStatus == nil {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			require.NoError(t, json.Unmarshal([]byte(value), status))
+			assert.Equal(t, tt.wantStatus.ScheduledMinReplicas, status.ScheduledMinReplicas)
+			assert.Equal(t, tt.wantStatus.OverrideMinReplicas, status.OverrideMinReplicas)
+			assert.Equal(t, tt.wantStatus.OverrideReason, status.OverrideReason)
+			assert.Equal(t, tt.wantStatus.PredictedReplicas, status.PredictedReplicas)
+			assert.Equal(t, tt.wantStatus.DesiredReplicas, status.DesiredReplicas)
+			if tt.wantStatus.OverrideExpireTime != nil {
+				require.NotNil(t, status.OverrideExpireTime)
+				assert.True(t, tt.wantStatus.OverrideExpireTime.Equal(status.OverrideExpireTime))
+			}
+		})
+	}
+}
@@ -34,6 +34,7 @@ import (
 	apiworkload "github.com/kubewharf/katalyst-api/pkg/apis/workload/v1alpha1"
 	apimetric "github.com/kubewharf/katalyst-api/pkg/metric"
 	resourceportrait "github.com/kubewharf/katalyst-core/pkg/controller/spd/indicator-plugin/plugins/resource-portrait"
+	"github.com/kubewharf/katalyst-core/pkg/util"
 )
 
 func generateOwnerReference(ihpa *v1alpha2.IntelligentHorizontalPodAutoscaler) metav1.OwnerReference {
@@ -127,6 +128,10 @@ func generateHPA(ihpa *v1alpha2.IntelligentHorizontalPodAutoscaler, podTemplate
 		hpa.Spec.MinReplicas, hpa.Spec.MaxReplicas = calculateCronReplicas(hpa.Spec.MinReplicas, hpa.Spec.MaxReplicas, ihpa.Spec.TimeBounds)
 	}
 
+	if util.CheckIHPAScalingRulesEnabled(ihpa) {
+		hpa.Spec.MinReplicas = applyScalingRulesMinReplicas(hpa.Spec.MinReplicas, hpa.Spec.MaxReplicas, getScalingRulesStatus(ihpa, time.Now()))
+	}
+
 	return &hpa
 }
 
@@ -192,64 +197,75 @@ func updateStatus(ihpa *v1alpha2.IntelligentHorizontalPodAutoscaler, hpa *v2.Hor
 	ihpa.Status.CurrentReplicas = hpa.Status.CurrentReplicas
 	ihpa.Status.CurrentMetrics = hpa.Status.CurrentMetrics
 
+	if usage, ok := getCurrentPortraitUsage(spd, time.Now()); ok {
+		ihpa.Status.DesiredReplicas = getPredictedReplicas(ihpa, hpa, usage)
+	}
+}
+
+// getCurrentPortraitUsage returns the latest resource portrait of the workload before now
+func getCurrentPortraitUsage(spd *apiworkload.ServiceProfileDescriptor, now time.Time) (corev1.ResourceList, bool) {
+	if spd == nil {
+		return nil, false
+	}
+
 	for _, aggMetrics := range spd.Status.AggMetrics {
 		if aggMetrics.Scope != resourceportrait.ResourcePortraitPluginName {
 			continue
 		}
 
 		var currentMetric *v1beta1.PodMetrics
-		now := time.Now()
-		for _, item := range aggMetrics.Items {
-			if now.After(item.Timestamp.Time) {
-				currentMetric = item.DeepCopy()
+		for i := range aggMetrics.Items {
+			if now.After(aggMetrics.Items[i].Timestamp.Time) {
+				currentMetric = &aggMetrics.Items[i]
 			} else {
 				break
 			}
 		}
 		if currentMetric == nil {
-			break
+			return nil, false
 		}
 
-		var containerMetrics *v1beta1.ContainerMetrics
 		for _, item := range currentMetric.Containers {
 			if item.Name == ResourcePortraitContainerName {
-				containerMetrics = &item
+				return item.Usage, true
 			}
 		}
-		if containerMetrics == nil {
-			break
-		}
+		return nil, false
+	}
+	return nil, false
+}
 
-		var desiredReplicasMax int32
-		for resourceName, resourceQuantity := range containerMetrics.Usage {
-			for _, metricSpec := range hpa.Spec.Metrics {
-				if metricSpec.Type != v2.ExternalMetricSourceType {
-					continue
-				}
-				if metricSpec.External == nil ||
-					metricSpec.External.Metric.Selector == nil ||
-					metricSpec.External.Target.AverageValue == nil {
-					continue
-				}
-				if metricSpec.External.Metric.Name != apimetric.MetricNameSPDAggMetrics ||
-					metricSpec.External.Metric.Selector.MatchLabels[apimetric.MetricSelectorKeySPDResourceName] != string(resourceName) ||
-					metricSpec.External.Metric.Selector.MatchLabels[apimetric.MetricSelectorKeySPDName] != ihpa.Spec.Autoscaler.ScaleTargetRef.Name ||
-					metricSpec.External.Metric.Selector.MatchLabels[apimetric.MetricSelectorKeySPDContainerName] != ResourcePortraitContainerName ||
-					metricSpec.External.Metric.Selector.MatchLabels[apimetric.MetricSelectorKeySPDScopeName] != resourceportrait.ResourcePortraitPluginName {
-					continue
-				}
-				if metricSpec.External.Target.AverageValue.MilliValue() == 0 {
-					continue
-				}
+// getPredictedReplicas returns replicas needed for the resource portrait with targets of external metrics in hpa
+func getPredictedReplicas(ihpa *v1alpha2.IntelligentHorizontalPodAutoscaler, hpa *v2.HorizontalPodAutoscaler, usage corev1.ResourceList) int32 {
+	var desiredReplicasMax int32
+	for resourceName, resourceQuantity := range usage {
+		for _, metricSpec := range hpa.Spec.Metrics {
+			if metricSpec.Type != v2.ExternalMetricSourceType {
+				continue
+			}
+			if metricSpec.External == nil ||
+				metricSpec.External.Metric.Selector == nil ||
+				metricSpec.External.Target.AverageValue == nil {
+				continue
+			}
+			if metricSpec.External.Metric.Name != apimetric.MetricNameSPDAggMetrics ||
+				metricSpec.External.Metric.Selector.MatchLabels[apimetric.MetricSelectorKeySPDResourceName] != string(resourceName) ||
+				metricSpec.External.Metric.Selector.MatchLabels[apimetric.MetricSelectorKeySPDName] != ihpa.Spec.Autoscaler.ScaleTargetRef.Name ||
+				metricSpec.External.Metric.Selector.MatchLabels[apimetric.MetricSelectorKeySPDContainerName] != ResourcePortraitContainerName ||
+				metricSpec.External.Metric.Selector.MatchLabels[apimetric.MetricSelectorKeySPDScopeName] != resourceportrait.ResourcePortraitPluginName {
+				continue
+			}
+			if metricSpec.External.Target.AverageValue.MilliValue() == 0 {
+				continue
+			}
 
-				desiredReplicas := int32(math.Ceil(float64(resourceQuantity.MilliValue()) / float64(metricSpec.External.Target.AverageValue.MilliValue())))
-				if desiredReplicasMax < desiredReplicas {
-					desiredReplicasMax = desiredReplicas
-				}
+			desiredReplicas := int32(math.Ceil(float64(resourceQuantity.MilliValue()) / float64(metricSpec.External.Target.AverageValue.MilliValue())))
+			if desiredReplicasMax < desiredReplicas {
+				desiredReplicasMax = desiredReplicas
 			}
 		}
-		ihpa.Status.DesiredReplicas = desiredReplicasMax
 	}
+	return desiredReplicasMax
 }
 
 func getAllCPUAndMemoryRequests(podTemplate *corev1.PodTemplateSpec) (int64, int64) {
@@ -30,7 +30,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/selection"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -76,14 +75,22 @@ const (
 	defaultCNCUpdateBurst = 100
 )
 
+// defaultConfigRevisionHistoryLimit is the number of config revisions kept for each kcc target
+// if its revisionHistoryLimit is not set
+const defaultConfigRevisionHistoryLimit = 3
+
 const (
 	kccTargetConditionReasonNormal                      = "Normal"
 	kccTargetConditionReasonHashFailed                  = "HashFailed"
 	kccTargetConditionReasonMatchMoreOrLessThanOneKCC   = "MatchMoreOrLessThanOneKCC"
 	kccTargetConditionReasonValidateFailed              = "ValidateFailed"
 	kccTargetConditionReasonCalculateCanaryCutoffFailed = "CalculateCanaryCutoffFailed"
+	kccTargetConditionReasonHealthRegression            = "HealthRegression"
 )
 
+// kccTargetConditionTypeRolloutPaused means the rollout of current config is paused by the rollout guard
+const kccTargetConditionTypeRolloutPaused configapis.ConfigConditionType = "RolloutPaused"
+
 type KatalystCustomConfigTargetController struct {
 	ctx       context.Context
 	dryRun    bool
@@ -93,6 +100,7 @@ type KatalystCustomConfigTargetController struct {
 	kccControl          control.KCCControl
 	unstructuredControl control.UnstructuredControl
 	cncControl          control.CNCControl
+	revisionControl     control.ControllerRevisionControl
 
 	// listers from the shared informer's stores
 	katalystCustomConfigLister v1alpha1.KatalystCustomConfigLister
@@ -107,6 +115,9 @@ type KatalystCustomConfigTargetController struct {
 	// targetHandler store gvr kcc and gvr
 	targetHandler *kcctarget.KatalystCustomConfigTargetHandler
 
+	// rolloutGuard is optional, and it pauses the rollouts which make canary nodes unhealthy
+	rolloutGuard *RolloutGuard
+
 	// metricsEmitter for emit metrics
 	metricsEmitter metrics.MetricEmitter
 
@@ -157,10 +168,12 @@ func NewKatalystCustomConfigTargetController(
 	k.kccControl = control.DummyKCCControl{}
 	k.unstructuredControl = control.DummyUnstructuredControl{}
 	k.cncControl = control.DummyCNCControl{}
+	k.revisionControl = control.DummyControllerRevisionControl{}
 	if !k.dryRun {
 		k.kccControl = control.NewRealKCCControl(client.InternalClient)
 		k.unstructuredControl = control.NewRealUnstructuredControl(client.DynamicClient)
 		k.cncControl = control.NewRealCNCControl(client.InternalClient)
+		k.revisionControl = control.NewRealControllerRevisionControl(client.KubeClient)
 	}
 
 	customNodeConfigInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -174,6 +187,12 @@ func NewKatalystCustomConfigTargetController(
 	return k, nil
 }
 
+// SetRolloutGuard enables the rollout guard for kcc targets, and it must be called before Run.
+func (k *KatalystCustomConfigTargetController) SetRolloutGuard(guard *RolloutGuard) {
+	k.rolloutGuard = guard
+	k.syncedFunc = append(k.syncedFunc, guard.HasSynced)
+}
+
 // Run don't need to trigger reconcile logic.
 func (k *KatalystCustomConfigTargetController) Run() {
 	defer utilruntime.HandleCrash()
@@ -186,6 +205,11 @@ func (k *KatalystCustomConfigTargetController) Run() {
 		return
 	}
 	klog.Infof("caches are synced for %s controller", kccTargetControllerName)
+
+	if k.rolloutGuard != nil {
+		k.rolloutGuard.Run()
+	}
+
 	klog.Infof("start %d workers for %s controller", kcctWorkerCount, kccTargetControllerName)
 
 	for i := 0; i < kcctWorkerCount; i++ {
@@ -400,7 +424,7 @@ func (k *KatalystCustomConfigTargetController) validateKCCTs(
 	}
 
 	for _, targetResource := range targetResources {
-		isValid, message, err := k.validateTargetResourceGenericSpec(kcc, targetResource, targetResources)
+		isValid, message, err := kccutil.ValidateTargetResourceGenericSpec(kcc, targetResource, targetResources)
 		if err != nil {
 			errors = append(errors, fmt.Errorf("validate kcc target resource failed: %w", err))
 			invalidKCCTs = append(invalidKCCTs, native.GenerateUniqObjectNameKey(targetResource))
@@ -457,7 +481,12 @@ func (k *KatalystCustomConfigTargetController) manageCNCs(
 		errors = append(errors, errs...)
 	}
 
-	rateLimited, errs := k.updateCNCs(gvr, targetResources, hashes, canaryCutoffPoints, targetCNCIndexes, allCNCs)
+	pausedTargets, errs := k.guardRollouts(gvr, targetResources, hashes, targetCNCIndexes, allCNCs)
+	if len(errs) > 0 {
+		errors = append(errors, errs...)
+	}
+
+	rateLimited, errs := k.updateCNCs(gvr, targetResources, hashes, canaryCutoffPoints, pausedTargets, targetCNCIndexes, allCNCs)
 	if len(errs) > 0 {
 		errors = append(errors, errs...)
 	}
@@ -466,7 +495,7 @@ func (k *KatalystCustomConfigTargetController) manageCNCs(
 		k.queue.AddAfter(gvr, time.Duration(k.cncUpdateBurst/k.cncUpdateQPS/2)*time.Second)
 	}
 
-	errs = k.updateTargetStatuses(gvr, targetResources, hashes, canaryCutoffPoints, targetCNCIndexes, allCNCs)
+	errs = k.updateTargetStatuses(gvr, targetResources, hashes, canaryCutoffPoints, pausedTargets, targetCNCIndexes, allCNCs)
 	if len(errs) > 0 {
 		errors = append(errors, errs...)
 	}
@@ -591,11 +620,248 @@ func (k *KatalystCustomConfigTargetController) computeCanaryCutoffPointsAndMaybe
 	return validTargetResources, canaryCutoffPoints, errors
 }
 
+// guardRollouts checks the health of canary nodes for the rollout of each kcc target; once regressed,
+// the rollout will be paused and its canary CNCs will be reverted. It returns the map from the paused
+// kcc targets to the pausing reasons.
+func (k *KatalystCustomConfigTargetController) guardRollouts(
+	gvr metav1.GroupVersionResource,
+	targetResources []util.KCCTargetResource,
+	hashes map[string]string,
+	targetCNCIndexes map[string][]int,
+	allCNCs []*configapis.CustomNodeConfig,
+) (map[string]string, []error) {
+	pausedTargets := make(map[string]string)
+	if k.rolloutGuard == nil {
+		return pausedTargets, nil
+	}
+
+	var errors []error
+	for i, targetResource := range targetResources {
+		kcctName := native.GenerateUniqObjectNameKey(targetResource)
+		hash := hashes[kcctName]
+
+		// record the config revision of current hash, so that it can be restored after the target is modified in place
+		targetResource, revisions, err := k.ensureConfigRevision(gvr, targetResource, hash, targetCNCIndexes[kcctName], allCNCs)
+		if err != nil {
+			errors = append(errors, fmt.Errorf("ensure config revision of kcc target %s %s failed: %w", gvr.String(), kcctName, err))
+		} else {
+			targetResources[i] = targetResource
+		}
+
+		// the rollout of current hash is resumed explicitly, and it won't be guarded any more
+		if targetResource.GetAnnotations()[consts.KCCTargetAnnotationKeyResumeRolloutHash] == hash {
+			continue
+		}
+
+		status := targetResource.GetGenericStatus()
+		condition := kccutil.GetKCCTGenericCondition(status, kccTargetConditionTypeRolloutPaused)
+		if condition != nil && condition.Status == v1.ConditionTrue && status.CurrentHash == hash {
+			// the rollout of current hash has been paused, and it can only be resumed by updating
+			// the config or annotating the target with the hash to resume
+			pausedTargets[kcctName] = condition.Message
+		} else {
+			startTime := time.Now()
+			if index := findConfigRevision(revisions, hash); index >= 0 {
+				startTime = revisions[index].Timestamp.Time
+			}
+
+			var canaryNodes, baselineNodes []string
+			for _, cncIndex := range targetCNCIndexes[kcctName] {
+				cnc := allCNCs[cncIndex]
+				if kccutil.IsCNCUpdated(cnc, gvr, targetResource, hash) {
+					canaryNodes = append(canaryNodes, cnc.GetName())
+				} else {
+					baselineNodes = append(baselineNodes, cnc.GetName())
+				}
+			}
+
+			regressed, message := k.rolloutGuard.evaluate(gvr, kcctName, hash, startTime, canaryNodes, baselineNodes)
+			if !regressed {
+				continue
+			}
+
+			general.Infof("pause rollout of kcct %s %s: %s", gvr.String(), kcctName, message)
+			pausedTargets[kcctName] = message
+		}
+
+		reverted, unrevertable, errs := k.revertCNCs(gvr, targetResource, hash, revisions, targetCNCIndexes[kcctName], allCNCs)
+		if len(errs) > 0 {
+			errors = append(errors, errs...)
+		}
+		if reverted > 0 {
+			pausedTargets[kcctName] = fmt.Sprintf("%s; reverted %d CNCs", pausedTargets[kcctName], reverted)
+		}
+		if unrevertable > 0 {
+			pausedTargets[kcctName] = fmt.Sprintf("%s; %d CNCs can't be reverted without a previous config revision",
+				pausedTargets[kcctName], unrevertable)
+		}
+	}
+
+	return pausedTargets, errors
+}
+
+// ensureConfigRevision records the config of the given hash in the revisions of kcc target, and prunes
+// the revisions beyond the history limit unless they are still referenced by the target CNCs. The contents
+// of each revision are stored in a ControllerRevision, and only the references are kept in the annotation.
+// It returns the updated kcc target and its revisions.
+func (k *KatalystCustomConfigTargetController) ensureConfigRevision(
+	gvr metav1.GroupVersionResource,
+	targetResource util.KCCTargetResource,
+	hash string,
+	cncIndexes []int,
+	allCNCs []*configapis.CustomNodeConfig,
+) (util.KCCTargetResource, []util.KCCTargetConfigRevision, error) {
+	revisions, err := util.GetKCCTargetConfigRevisions(targetResource)
+	if err != nil {
+		general.Errorf("get config revisions of kcc target %s failed, reset them: %v",
+			native.GenerateUniqObjectNameKey(targetResource), err)
+		revisions = nil
+	}
+
+	// the revision of current hash is always the latest one, even if the target is changed back to an earlier hash,
+	// so that the rollout of current hash starts at its timestamp and can be reverted to the one before it
+	changed := false
+	if index := findConfigRevision(revisions, hash); index < 0 {
+		revision, err := util.NewKCCTargetControllerRevision(gvr, targetResource, hash)
+		if err != nil {
+			return targetResource, revisions, err
+		}
+		_, err = k.revisionControl.CreateControllerRevision(k.ctx, revision, metav1.CreateOptions{})
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return targetResource, revisions, fmt.Errorf("create controller revision %s/%s failed: %w",
+				revision.Namespace, revision.Name, err)
+		}
+
+		revisions = append(revisions, util.KCCTargetConfigRevision{Hash: hash, Timestamp: metav1.NewTime(time.Now())})
+		changed = true
+	} else if index != len(revisions)-1 {
+		revision := revisions[index]
+		revision.Timestamp = metav1.NewTime(time.Now())
+		revisions = append(append(revisions[:index:index], revisions[index+1:]...), revision)
+		changed = true
+	}
+
+	referencedHashes := sets.NewString(hash)
+	for _, cncIndex := range cncIndexes {
+		for _, targetConfig := range allCNCs[cncIndex].Status.KatalystCustomConfigList {
+			if targetConfig.ConfigType == gvr && targetConfig.ConfigNamespace == targetResource.GetNamespace() &&
+				targetConfig.ConfigName == targetResource.GetName() {
+				referencedHashes.Insert(targetConfig.Hash)
+			}
+		}
+	}
+
+	limit := int(targetResource.GetRevisionHistoryLimit())
+	if limit <= 0 {
+		limit = defaultConfigRevisionHistoryLimit
+	}
+	retained := make([]util.KCCTargetConfigRevision, 0, len(revisions))
+	var pruned []util.KCCTargetConfigRevision
+	for i, revision := range revisions {
+		if i >= len(revisions)-limit || referencedHashes.Has(revision.Hash) {
+			retained = append(retained, revision)
+		} else {
+			pruned = append(pruned, revision)
+		}
+	}
+	changed = changed || len(pruned) > 0
+
+	if !changed {
+		return targetResource, retained, nil
+	}
+
+	newTargetResource := targetResource.DeepCopy()
+	if err := util.SetKCCTargetConfigRevisions(newTargetResource, retained); err != nil {
+		return targetResource, revisions, err
+	}
+	updated, err := k.unstructuredControl.PatchUnstructured(k.ctx, gvr, targetResource.GetUnstructured(), newTargetResource.GetUnstructured())
+	if err != nil {
+		return targetResource, revisions, err
+	}
+
+	// the pruned revisions are deleted after they are no longer referenced by the target
+	for _, revision := range pruned {
+		namespace, name := util.GetKCCTargetControllerRevisionKey(gvr, targetResource.GetNamespace(), targetResource.GetName(), revision.Hash)
+		err := k.revisionControl.DeleteControllerRevision(k.ctx, namespace, name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			general.Errorf("delete controller revision %s/%s failed: %v", namespace, name, err)
+		}
+	}
+	return util.ToKCCTargetResource(updated), retained, nil
+}
+
+// revertCNCs reverts the CNCs updated by the rollout of the given hash to the previous config revision of
+// the kcc target, and returns the number of reverted CNCs and that of CNCs which can't be reverted since
+// there is no previous revision.
+func (k *KatalystCustomConfigTargetController) revertCNCs(
+	gvr metav1.GroupVersionResource,
+	targetResource util.KCCTargetResource,
+	hash string,
+	revisions []util.KCCTargetConfigRevision,
+	cncIndexes []int,
+	allCNCs []*configapis.CustomNodeConfig,
+) (int, int, []error) {
+	var errors []error
+	reverted, unrevertable := 0, 0
+
+	// agents fetch configs by the name of kcc target, and the content of the previous hash
+	// is restored by agents from its ControllerRevision.
+	var previous *configapis.TargetConfig
+	if index := findConfigRevision(revisions, hash); index > 0 {
+		previous = &configapis.TargetConfig{
+			ConfigType:      gvr,
+			ConfigNamespace: targetResource.GetNamespace(),
+			ConfigName:      targetResource.GetName(),
+			Hash:            revisions[index-1].Hash,
+		}
+	}
+
+	kcctName := native.GenerateUniqObjectNameKey(targetResource)
+	for _, cncIndex := range cncIndexes {
+		oldCNC := allCNCs[cncIndex]
+		if !kccutil.IsCNCUpdated(oldCNC, gvr, targetResource, hash) {
+			continue
+		}
+
+		if previous == nil {
+			unrevertable++
+			continue
+		}
+
+		newCNC := oldCNC.DeepCopy()
+		kccutil.RestoreTargetConfigToCNC(newCNC, gvr, previous)
+		newCNC, err := k.cncControl.PatchCNCStatus(k.ctx, oldCNC.GetName(), oldCNC, newCNC)
+		if err != nil {
+			errors = append(errors, fmt.Errorf("revert CNC %s status failed: %w", oldCNC.GetName(), err))
+			continue
+		}
+
+		allCNCs[cncIndex] = newCNC
+		reverted++
+	}
+
+	if reverted > 0 || unrevertable > 0 {
+		general.Infof("reverted %d CNCs for kcct %s %s, %d can't be reverted", reverted, gvr.String(), kcctName, unrevertable)
+	}
+	return reverted, unrevertable, errors
+}
+
+// findConfigRevision returns the index of the revision of the given hash, and -1 if it's not found
+func findConfigRevision(revisions []util.KCCTargetConfigRevision, hash string) int {
+	for i := range revisions {
+		if revisions[i].Hash == hash {
+			return i
+		}
+	}
+	return -1
+}
+
 func (k *KatalystCustomConfigTargetController) updateCNCs(
 	gvr metav1.GroupVersionResource,
 	targetResources []util.KCCTargetResource,
 	hashes map[string]string,
 	canaryCutoffPoints map[string]int,
+	pausedTargets map[string]string,
 	targetCNCIndexes map[string][]int,
 	allCNCs []*configapis.CustomNodeConfig,
 ) (bool, []error) {
@@ -619,6 +885,10 @@ kcctLoop:
 		}
 
 		kcctName := native.GenerateUniqObjectNameKey(targetResource)
+		if _, paused := pausedTargets[kcctName]; paused {
+			continue
+		}
+
 		cutoffPoint := canaryCutoffPoints[kcctName]
 		for _, cncIndex := range targetCNCIndexes[kcctName][:cutoffPoint] {
 			if !kccutil.IsCNCUpdated(allCNCs[cncIndex], gvr, targetResource, hashes[kcctName]) {
@@ -668,6 +938,7 @@ func (k *KatalystCustomConfigTargetController) updateTargetStatuses(
 	targetResources []util.KCCTargetResource,
 	hashes map[string]string,
 	canaryCutoffPoints map[string]int,
+	pausedTargets map[string]string,
 	targetCNCIndexes map[string][]int,
 	allCNCs []*configapis.CustomNodeConfig,
 ) []error {
@@ -697,6 +968,8 @@ func (k *KatalystCustomConfigTargetController) updateTargetStatuses(
 
 		newTargetResource := targetResource.DeepCopy()
 		updateValidTargetResourceStatus(newTargetResource, targetNodes, canaryNodes, updatedTargetNodes, updatedNodes, hash)
+		pausedMessage, paused := pausedTargets[kcctName]
+		updateTargetResourceRolloutStatus(newTargetResource, paused, pausedMessage)
 		if !apiequality.Semantic.DeepEqual(newTargetResource, targetResource) {
 			general.Infof(
 				"kcct %s %s update status targetNodes=%d canaryNodes=%d updatedTargetNodes=%d updatedNodes=%d hash=%s",
@@ -736,212 +1009,6 @@ func (k *KatalystCustomConfigTargetController) handleKCCTargetFinalizers(
 	return nil
 }
 
-// validateTargetResourceGenericSpec validate target resource generic spec as follows rule:
-// 1. can not set both labelSelector and nodeNames config at the same time
-// 2. if nodeNames is not set, lastDuration must not be set either
-// 3. labelSelector config must only contain kcc' labelSelectorKey in priority allowed key list
-// 4. labelSelector config cannot overlap with other labelSelector config in same priority
-// 5. nodeNames config must set lastDuration to make sure it will be auto cleared
-// 6. nodeNames config cannot overlap with other nodeNames config
-// 7. it is not allowed two global config (without either labelSelector or nodeNames) overlap
-func (k *KatalystCustomConfigTargetController) validateTargetResourceGenericSpec(
-	kcc *configapis.KatalystCustomConfig,
-	targetResource util.KCCTargetResource,
-	allTargetResoures []util.KCCTargetResource,
-) (bool, string, error) {
-	labelSelector := targetResource.GetLabelSelector()
-	nodeNames := targetResource.GetNodeNames()
-	if len(labelSelector) != 0 && len(nodeNames) != 0 {
-		return false, "both labelSelector and nodeNames has been set", nil
-	} else if len(labelSelector) != 0 {
-		return k.validateTargetResourceLabelSelector(kcc, targetResource, allTargetResoures)
-	} else if len(nodeNames) != 0 {
-		return k.validateTargetResourceNodeNames(kcc, targetResource, allTargetResoures)
-	} else {
-		return k.validateTargetResourceGlobal(kcc, targetResource, allTargetResoures)
-	}
-}
-
-func (k *KatalystCustomConfigTargetController) validateTargetResourceLabelSelector(
-	kcc *configapis.KatalystCustomConfig,
-	targetResource util.KCCTargetResource,
-	allTargetResources []util.KCCTargetResource,
-) (bool, string, error) {
-	priorityAllowedKeyListMap := getPriorityAllowedKeyListMap(kcc)
-	if len(priorityAllowedKeyListMap) == 0 {
-		return false, fmt.Sprintf("kcc %s no support label selector", native.GenerateUniqObjectNameKey(kcc)), nil
-	}
-
-	valid, msg, err := validateLabelSelectorMatchWithKCCDefinition(priorityAllowedKeyListMap, targetResource)
-	if err != nil {
-		return false, "", nil
-	} else if !valid {
-		return false, msg, nil
-	}
-
-	return validateLabelSelectorOverlapped(priorityAllowedKeyListMap, targetResource, allTargetResources)
-}
-
-func getPriorityAllowedKeyListMap(kcc *configapis.KatalystCustomConfig) map[int32]sets.String {
-	priorityAllowedKeyListMap := make(map[int32]sets.String)
-	for _, allowedKey := range kcc.Spec.NodeLabelSelectorAllowedKeyList {
-		priorityAllowedKeyListMap[allowedKey.Priority] = sets.NewString(allowedKey.KeyList...)
-	}
-	return priorityAllowedKeyListMap
-}
-
-// validateLabelSelectorMatchWithKCCDefinition make sures that labelSelector config must only contain key in kcc' allowed key list
-func validateLabelSelectorMatchWithKCCDefinition(priorityAllowedKeyListMap map[int32]sets.String, targetResource util.KCCTargetResource) (bool, string, error) {
-	if targetResource.GetLastDuration() != nil {
-		return false, "both labelSelector and lastDuration has been set", nil
-	}
-
-	labelSelector := targetResource.GetLabelSelector()
-	selector, err := labels.Parse(labelSelector)
-	if err != nil {
-		return false, fmt.Sprintf("labelSelector parse failed: %s", err), nil
-	}
-
-	priority := targetResource.GetPriority()
-	allowedKeyList, ok := priorityAllowedKeyListMap[priority]
-	if !ok {
-		return false, fmt.Sprintf("priority %d not supported", priority), nil
-	}
-
-	reqs, selectable := selector.Requirements()
-	if !selectable {
-		return false, fmt.Sprintf("labelSelector cannot selectable"), nil
-	}
-
-	inValidLabelKeys := sets.String{}
-	for _, r := range reqs {
-		key := r.Key()
-		if !allowedKeyList.Has(key) {
-			inValidLabelKeys.Insert(key)
-		}
-	}
-
-	if len(inValidLabelKeys) > 0 {
-		return false, fmt.Sprintf("labelSelector with invalid key %v (%s)", inValidLabelKeys.List(), allowedKeyList.List()), nil
-	}
-
-	return true, "", nil
-}
-
-// validateLabelSelectorOverlapped make sures that labelSelector config cannot overlap with other labelSelector config
-func validateLabelSelectorOverlapped(priorityAllowedKeyListMap map[int32]sets.String, targetResource util.KCCTargetResource,
-	otherResources []util.KCCTargetResource,
-) (bool, string, error) {
-	labelSelector := targetResource.GetLabelSelector()
-	selector, err := labels.Parse(labelSelector)
-	if err != nil {
-		return false, fmt.Sprintf("labelSelector parse failed: %s", err), nil
-	}
-
-	priority := targetResource.GetPriority()
-	allowedKeyList, ok := priorityAllowedKeyListMap[priority]
-	if !ok {
-		return false, fmt.Sprintf("priority %d not supported", priority), nil
-	}
-
-	overlapResources := sets.String{}
-	for _, res := range otherResources {
-		if (res.GetNamespace() == targetResource.GetNamespace() && res.GetName() == targetResource.GetName()) ||
-			len(res.GetLabelSelector()) == 0 {
-			continue
-		}
-
-		otherSelector, err := labels.Parse(res.GetLabelSelector())
-		if err != nil {
-			continue
-		}
-
-		otherPriority := res.GetPriority()
-		if otherPriority != priority {
-			continue
-		}
-
-		overlap := checkLabelSelectorOverlap(selector, otherSelector, allowedKeyList.List())
-		if overlap {
-			overlapResources.Insert(native.GenerateUniqObjectNameKey(res))
-		}
-	}
-
-	if len(overlapResources) > 0 {
-		return false, fmt.Sprintf("labelSelector overlay with others: %v", overlapResources.List()), nil
-	}
-
-	return true, "", nil
-}
-
-func (k *KatalystCustomConfigTargetController) validateTargetResourceNodeNames(
-	kcc *configapis.KatalystCustomConfig,
-	targetResource util.KCCTargetResource,
-	allTargetResources []util.KCCTargetResource,
-) (bool, string, error) {
-	if targetResource.GetLastDuration() == nil {
-		return false, "nodeNames has been set but lastDuration no set", nil
-	}
-
-	return validateTargetResourceNodeNamesOverlapped(targetResource, allTargetResources)
-}
-
-// validateLabelSelectorOverlapped make sures that nodeNames config cannot overlap with other labelSelector config
-func validateTargetResourceNodeNamesOverlapped(targetResource util.KCCTargetResource, otherResources []util.KCCTargetResource) (bool, string, error) {
-	nodeNames := sets.NewString(targetResource.GetNodeNames()...)
-
-	overlapResources := sets.String{}
-	for _, res := range otherResources {
-		if (res.GetNamespace() == targetResource.GetNamespace() && res.GetName() == targetResource.GetName()) ||
-			len(res.GetNodeNames()) == 0 {
-			continue
-		}
-
-		otherNodeNames := sets.NewString(res.GetNodeNames()...)
-		if nodeNames.Intersection(otherNodeNames).Len() > 0 {
-			overlapResources.Insert(native.GenerateUniqObjectNameKey(res))
-		}
-	}
-
-	if len(overlapResources) > 0 {
-		return false, fmt.Sprintf("nodeNames overlay with others: %v", overlapResources.List()), nil
-	}
-
-	return true, "", nil
-}
-
-func (k *KatalystCustomConfigTargetController) validateTargetResourceGlobal(
-	kcc *configapis.KatalystCustomConfig,
-	targetResource util.KCCTargetResource,
-	allTargetResources []util.KCCTargetResource,
-) (bool, string, error) {
-	if targetResource.GetLastDuration() != nil {
-		return false, "lastDuration has been set for global config", nil
-	}
-
-	return validateTargetResourceGlobalOverlapped(targetResource, allTargetResources)
-}
-
-// validateLabelSelectorOverlapped make sures that only one global configurations is created.
-func validateTargetResourceGlobalOverlapped(targetResource util.KCCTargetResource, otherResources []util.KCCTargetResource) (bool, string, error) {
-	overlapTargetNames := sets.String{}
-	for _, res := range otherResources {
-		if (res.GetNamespace() == targetResource.GetNamespace() && res.GetName() == targetResource.GetName()) ||
-			(len(res.GetNodeNames()) > 0 || len(res.GetLabelSelector()) > 0) {
-			continue
-		}
-
-		overlapTargetNames.Insert(native.GenerateUniqObjectNameKey(res))
-	}
-
-	if len(overlapTargetNames) > 0 {
-		return false, fmt.Sprintf("global config %s overlay with others: %v",
-			native.GenerateUniqObjectNameKey(targetResource), overlapTargetNames.List()), nil
-	}
-
-	return true, "", nil
-}
-
 func updateInvalidTargetResourceStatus(targetResource util.KCCTargetResource, msg, reason string) {
 	status := targetResource.GetGenericStatus()
 	status.ObservedGeneration = targetResource.GetGeneration()
@@ -967,50 +1034,19 @@ func updateValidTargetResourceStatus(
 	targetResource.SetGenericStatus(status)
 }
 
-// checkLabelSelectorOverlap checks whether the labelSelector overlap with other labelSelector by the keyList
-func checkLabelSelectorOverlap(selector labels.Selector, otherSelector labels.Selector,
-	keyList []string,
-) bool {
-	for _, key := range keyList {
-		equalValueSet, inEqualValueSet, _ := getMatchValueSet(selector, key)
-		otherEqualValueSet, otherInEqualValueSet, _ := getMatchValueSet(otherSelector, key)
-		if (equalValueSet.Len() > 0 && otherEqualValueSet.Len() > 0 && equalValueSet.Intersection(otherEqualValueSet).Len() > 0) ||
-			(equalValueSet.Len() == 0 && otherEqualValueSet.Len() == 0) ||
-			(inEqualValueSet.Len() > 0 && !inEqualValueSet.Intersection(otherEqualValueSet).Equal(otherEqualValueSet)) ||
-			(otherInEqualValueSet.Len() > 0 && !otherInEqualValueSet.Intersection(equalValueSet).Equal(equalValueSet)) ||
-			(equalValueSet.Len() > 0 && otherEqualValueSet.Len() == 0 && otherInEqualValueSet.Len() == 0) ||
-			(otherEqualValueSet.Len() > 0 && equalValueSet.Len() == 0 && inEqualValueSet.Len() == 0) {
-			continue
-		} else {
-			return false
-		}
-	}
-
-	return true
-}
-
-func getMatchValueSet(selector labels.Selector, key string) (sets.String, sets.String, error) {
-	reqs, selectable := selector.Requirements()
-	if !selectable {
-		return nil, nil, fmt.Errorf("labelSelector cannot selectable")
+// updateTargetResourceRolloutStatus records whether the rollout is paused by the rollout guard,
+// and the condition is only added once the rollout has been paused.
+func updateTargetResourceRolloutStatus(targetResource util.KCCTargetResource, paused bool, msg string) {
+	status := targetResource.GetGenericStatus()
+	if paused {
+		kccutil.UpdateKCCTGenericConditions(&status, kccTargetConditionTypeRolloutPaused, v1.ConditionTrue, kccTargetConditionReasonHealthRegression, msg)
+	} else if kccutil.GetKCCTGenericCondition(status, kccTargetConditionTypeRolloutPaused) != nil {
+		kccutil.UpdateKCCTGenericConditions(&status, kccTargetConditionTypeRolloutPaused, v1.ConditionFalse, kccTargetConditionReasonNormal, "")
+	} else {
+		return
 	}
 
-	equalValueSet := sets.String{}
-	inEqualValueSet := sets.String{}
-	for _, r := range reqs {
-		if r.Key() != key {
-			continue
-		}
-		switch r.Operator() {
-		case selection.Equals, selection.DoubleEquals, selection.In:
-			equalValueSet = equalValueSet.Union(r.Values())
-		case selection.NotEquals, selection.NotIn:
-			inEqualValueSet = inEqualValueSet.Union(r.Values())
-		default:
-			return nil, nil, fmt.Errorf("labelSelector operator %s not supported", r.Operator())
-		}
-	}
-	return equalValueSet, inEqualValueSet, nil
+	targetResource.SetGenericStatus(status)
 }
 
 func (k *KatalystCustomConfigTargetController) clearUnusedConfig() {
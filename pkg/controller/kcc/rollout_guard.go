@@ -0,0 +1,256 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kcc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	nodeinformers "github.com/kubewharf/katalyst-api/pkg/client/informers/externalversions/node/v1alpha1"
+	nodelisters "github.com/kubewharf/katalyst-api/pkg/client/listers/node/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/controller/lifecycle/agent-healthz/helper"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+)
+
+const (
+	rolloutGuardName = "kcct-rollout-guard"
+
+	// evictionRetention is the max period to keep the eviction records of nodes
+	evictionRetention = 24 * time.Hour
+
+	metricsNameRolloutRegressed = "kcct_rollout_regressed"
+)
+
+// RolloutGuard correlates the canary nodes of kcc config rollouts with the health of agents
+// on them, i.e. the heartbeats collected by agent-healthz, the evictions performed by agents and
+// the taints on CNRs, to detect whether the nodes receiving new configs got worse than the others.
+//
+// The state of rollouts is persisted in the API objects, i.e. the references to config revisions
+// in kcc target annotations, the ControllerRevisions storing their contents and the paused condition
+// in kcc target status; only the evictions are kept in memory, which are rebuilt from the events
+// listed by the informer after restarts.
+type RolloutGuard struct {
+	mu sync.Mutex
+
+	ctx     context.Context
+	conf    *controller.RolloutGuardConfig
+	emitter metrics.MetricEmitter
+
+	cnrLister     nodelisters.CustomNodeResourceLister
+	healthzHelper *helper.HealthzHelper
+	syncedFunc    []cache.InformerSynced
+
+	// evictions maps from node name to the timestamps of evictions on it
+	evictions map[string][]time.Time
+}
+
+func NewRolloutGuard(ctx context.Context,
+	conf *controller.RolloutGuardConfig,
+	lifeCycleConf *controller.LifeCycleConfig,
+	nodeInformer coreinformers.NodeInformer,
+	podInformer coreinformers.PodInformer,
+	eventInformer coreinformers.EventInformer,
+	cnrInformer nodeinformers.CustomNodeResourceInformer,
+	metricsEmitter metrics.MetricEmitter,
+) (*RolloutGuard, error) {
+	if conf == nil || lifeCycleConf == nil || lifeCycleConf.HealthzConfig == nil {
+		return nil, fmt.Errorf("rollout guard and healthz configuration can't be nil")
+	}
+
+	g := &RolloutGuard{
+		ctx:       ctx,
+		conf:      conf,
+		cnrLister: cnrInformer.Lister(),
+		syncedFunc: []cache.InformerSynced{
+			nodeInformer.Informer().HasSynced,
+			podInformer.Informer().HasSynced,
+			eventInformer.Informer().HasSynced,
+			cnrInformer.Informer().HasSynced,
+		},
+		evictions: make(map[string][]time.Time),
+	}
+
+	if metricsEmitter == nil {
+		g.emitter = metrics.DummyMetrics{}
+	} else {
+		g.emitter = metricsEmitter.WithTags(rolloutGuardName)
+	}
+
+	if err := native.AddNodeNameIndexerForPod(podInformer); err != nil {
+		return nil, err
+	}
+
+	g.healthzHelper = helper.NewHealthzHelper(ctx, lifeCycleConf, g.emitter, lifeCycleConf.NodeSelector,
+		lifeCycleConf.AgentSelector, podInformer.Informer().GetIndexer(), nodeInformer.Lister(), g.cnrLister)
+
+	eventInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: g.addEvent,
+	})
+
+	return g, nil
+}
+
+// HasSynced whether all cache has synced
+func (g *RolloutGuard) HasSynced() bool {
+	for _, hasSynced := range g.syncedFunc {
+		if !hasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *RolloutGuard) Run() {
+	g.healthzHelper.Run()
+}
+
+func (g *RolloutGuard) addEvent(obj interface{}) {
+	event, ok := obj.(*corev1.Event)
+	if !ok || event.Reason != consts.EventReasonEvictSucceeded {
+		return
+	}
+
+	// events are reported by the agents, whose instance is the name of the node
+	nodeName := event.Source.Host
+	if nodeName == "" {
+		nodeName = event.ReportingInstance
+	}
+	if nodeName == "" {
+		return
+	}
+
+	g.recordEviction(nodeName, getEventTime(event))
+}
+
+func (g *RolloutGuard) recordEviction(nodeName string, timestamp time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	expiry := time.Now().Add(-evictionRetention)
+	evictions := make([]time.Time, 0, len(g.evictions[nodeName])+1)
+	for _, t := range g.evictions[nodeName] {
+		if t.After(expiry) {
+			evictions = append(evictions, t)
+		}
+	}
+	g.evictions[nodeName] = append(evictions, timestamp)
+}
+
+// evaluate compares the unhealthy ratio of canary nodes (which have received the config of the given hash)
+// with that of the baseline nodes (the other target nodes) since the rollout started, and returns whether
+// the rollout is regressed.
+func (g *RolloutGuard) evaluate(gvr metav1.GroupVersionResource, kcctName, hash string, startTime time.Time,
+	canaryNodes, baselineNodes []string,
+) (bool, string) {
+	if len(canaryNodes) == 0 || len(baselineNodes) == 0 {
+		return false, ""
+	}
+
+	var unhealthyCanary []string
+	for _, node := range canaryNodes {
+		if reason := g.checkNodeUnhealthy(node, startTime); reason != "" {
+			general.Infof("canary node %s of kcct %s %s is unhealthy: %s", node, gvr.String(), kcctName, reason)
+			unhealthyCanary = append(unhealthyCanary, node)
+		}
+	}
+
+	unhealthyBaseline := 0
+	for _, node := range baselineNodes {
+		if reason := g.checkNodeUnhealthy(node, startTime); reason != "" {
+			unhealthyBaseline++
+		}
+	}
+
+	canaryRatio := float64(len(unhealthyCanary)) / float64(len(canaryNodes))
+	baselineRatio := float64(unhealthyBaseline) / float64(len(baselineNodes))
+	general.InfofV(4, "kcct %s %s hash %s canary unhealthy ratio %.2f, baseline unhealthy ratio %.2f",
+		gvr.String(), kcctName, hash, canaryRatio, baselineRatio)
+
+	if len(unhealthyCanary) < g.conf.MinUnhealthyNodes || canaryRatio-baselineRatio <= g.conf.RegressionThreshold {
+		return false, ""
+	}
+
+	_ = g.emitter.StoreInt64(metricsNameRolloutRegressed, 1, metrics.MetricTypeNameCount,
+		metrics.MetricTag{Key: "gvr", Val: gvr.String()},
+		metrics.MetricTag{Key: "kcct", Val: kcctName})
+
+	return true, fmt.Sprintf("rollout of hash %s regressed: %d/%d canary nodes unhealthy (ratio %.2f) "+
+		"while %d/%d other target nodes unhealthy (ratio %.2f), unhealthy canary nodes: %v",
+		hash, len(unhealthyCanary), len(canaryNodes), canaryRatio, unhealthyBaseline, len(baselineNodes), baselineRatio,
+		truncateNodeList(unhealthyCanary))
+}
+
+// checkNodeUnhealthy returns a non-empty reason if the node is regarded as unhealthy since the given time.
+func (g *RolloutGuard) checkNodeUnhealthy(node string, since time.Time) string {
+	if !g.healthzHelper.CheckAllAgentReady(node) {
+		return "agents not ready"
+	}
+
+	cnr, err := g.cnrLister.Get(node)
+	if err != nil && !apierrors.IsNotFound(err) {
+		general.Errorf("get cnr %s failed: %v", node, err)
+	} else if err == nil && len(cnr.Spec.Taints) > 0 {
+		return "cnr tainted"
+	}
+
+	if g.conf.EvictionThreshold > 0 {
+		g.mu.Lock()
+		evictions := 0
+		for _, t := range g.evictions[node] {
+			if !t.Before(since) {
+				evictions++
+			}
+		}
+		g.mu.Unlock()
+
+		if evictions >= g.conf.EvictionThreshold {
+			return fmt.Sprintf("%d evictions", evictions)
+		}
+	}
+
+	return ""
+}
+
+func getEventTime(event *corev1.Event) time.Time {
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	} else if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// truncateNodeList limits the number of nodes shown in status messages
+func truncateNodeList(nodes []string) []string {
+	const maxNodes = 10
+	if len(nodes) > maxNodes {
+		return nodes[:maxNodes]
+	}
+	return nodes
+}
@@ -0,0 +1,316 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kcc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	configapis "github.com/kubewharf/katalyst-api/pkg/apis/config/v1alpha1"
+	nodeapis "github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	katalyst_base "github.com/kubewharf/katalyst-core/cmd/base"
+	"github.com/kubewharf/katalyst-core/pkg/client/control"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/crd"
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/util"
+)
+
+func newTestRolloutGuard(t *testing.T, cnrs []runtime.Object) *RolloutGuard {
+	genericContext, err := katalyst_base.GenerateFakeGenericContext(nil, cnrs, nil)
+	require.NoError(t, err)
+
+	conf := generateTestConfiguration(t)
+	guard, err := NewRolloutGuard(context.TODO(),
+		&controller.RolloutGuardConfig{
+			EnableRolloutGuard:  true,
+			RegressionThreshold: 0.2,
+			MinUnhealthyNodes:   1,
+			EvictionThreshold:   2,
+		},
+		conf.ControllersConfiguration.LifeCycleConfig,
+		genericContext.KubeInformerFactory.Core().V1().Nodes(),
+		genericContext.KubeInformerFactory.Core().V1().Pods(),
+		genericContext.KubeInformerFactory.Core().V1().Events(),
+		genericContext.InternalInformerFactory.Node().V1alpha1().CustomNodeResources(),
+		genericContext.EmitterPool.GetDefaultMetricsEmitter(),
+	)
+	require.NoError(t, err)
+
+	genericContext.StartInformer(context.TODO())
+	require.Eventually(t, guard.HasSynced, time.Second, 10*time.Millisecond)
+	return guard
+}
+
+func taintedCNR(name string) *nodeapis.CustomNodeResource {
+	return &nodeapis.CustomNodeResource{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: nodeapis.CustomNodeResourceSpec{
+			Taints: []nodeapis.Taint{
+				{
+					Taint: corev1.Taint{
+						Key:    "test",
+						Effect: corev1.TaintEffectNoSchedule,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestRolloutGuard_evaluate(t *testing.T) {
+	t.Parallel()
+
+	gvr := metav1.GroupVersionResource(crd.AdminQoSConfigurationGVR)
+	for _, tt := range []struct {
+		name          string
+		cnrs          []runtime.Object
+		evictions     map[string]int
+		canaryNodes   []string
+		baselineNodes []string
+		wantRegressed bool
+	}{
+		{
+			name:          "all nodes healthy",
+			canaryNodes:   []string{"node-1", "node-2"},
+			baselineNodes: []string{"node-3", "node-4"},
+			wantRegressed: false,
+		},
+		{
+			name:          "canary node tainted",
+			cnrs:          []runtime.Object{taintedCNR("node-1")},
+			canaryNodes:   []string{"node-1", "node-2"},
+			baselineNodes: []string{"node-3", "node-4"},
+			wantRegressed: true,
+		},
+		{
+			name:          "both canary and baseline nodes tainted",
+			cnrs:          []runtime.Object{taintedCNR("node-1"), taintedCNR("node-3")},
+			canaryNodes:   []string{"node-1", "node-2"},
+			baselineNodes: []string{"node-3", "node-4"},
+			wantRegressed: false,
+		},
+		{
+			name:          "evictions on canary node",
+			evictions:     map[string]int{"node-2": 2},
+			canaryNodes:   []string{"node-1", "node-2"},
+			baselineNodes: []string{"node-3", "node-4"},
+			wantRegressed: true,
+		},
+		{
+			name:          "evictions below threshold",
+			evictions:     map[string]int{"node-2": 1},
+			canaryNodes:   []string{"node-1", "node-2"},
+			baselineNodes: []string{"node-3", "node-4"},
+			wantRegressed: false,
+		},
+		{
+			name:          "no baseline nodes",
+			cnrs:          []runtime.Object{taintedCNR("node-1")},
+			canaryNodes:   []string{"node-1", "node-2"},
+			wantRegressed: false,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			guard := newTestRolloutGuard(t, tt.cnrs)
+			startTime := time.Now()
+			for node, count := range tt.evictions {
+				for i := 0; i < count; i++ {
+					guard.addEvent(&corev1.Event{
+						Reason:    consts.EventReasonEvictSucceeded,
+						Source:    corev1.EventSource{Host: node},
+						EventTime: metav1.NewMicroTime(time.Now().Add(time.Second)),
+					})
+				}
+			}
+
+			regressed, message := guard.evaluate(gvr, "default/config-1", "hash-1", startTime, tt.canaryNodes, tt.baselineNodes)
+			assert.Equal(t, tt.wantRegressed, regressed)
+			assert.Equal(t, tt.wantRegressed, message != "")
+		})
+	}
+}
+
+func TestRolloutGuard_evictionsBeforeUpdate(t *testing.T) {
+	t.Parallel()
+
+	gvr := metav1.GroupVersionResource(crd.AdminQoSConfigurationGVR)
+	guard := newTestRolloutGuard(t, nil)
+
+	startTime := time.Now().Add(-time.Hour)
+	for i := 0; i < 2; i++ {
+		guard.addEvent(&corev1.Event{
+			Reason:    consts.EventReasonEvictSucceeded,
+			Source:    corev1.EventSource{Host: "node-1"},
+			EventTime: metav1.NewMicroTime(startTime.Add(time.Minute)),
+		})
+	}
+
+	// the evictions happened before the rollout started are not counted
+	regressed, _ := guard.evaluate(gvr, "default/config-1", "hash-1", startTime.Add(30*time.Minute),
+		[]string{"node-1", "node-2"}, []string{"node-3"})
+	assert.False(t, regressed)
+
+	regressed, _ = guard.evaluate(gvr, "default/config-1", "hash-1", startTime,
+		[]string{"node-1", "node-2"}, []string{"node-3"})
+	assert.True(t, regressed)
+}
+
+func TestKatalystCustomConfigTargetController_ensureConfigRevision(t *testing.T) {
+	t.Parallel()
+
+	gvr := metav1.GroupVersionResource(crd.AdminQoSConfigurationGVR)
+	kubeClient := fake.NewSimpleClientset()
+	k := &KatalystCustomConfigTargetController{
+		ctx:                 context.TODO(),
+		unstructuredControl: control.DummyUnstructuredControl{},
+		revisionControl:     control.NewRealControllerRevisionControl(kubeClient),
+	}
+
+	target := newTestAdminQoSTarget(1)
+	require.NoError(t, unstructured.SetNestedField(target.GetUnstructured().Object, int64(2),
+		consts.ObjectFieldNameSpec, consts.KCCTargetConfFieldNameRevisionHistoryLimit))
+	var revisions []util.KCCTargetConfigRevision
+	var err error
+	for _, hash := range []string{"hash-0", "hash-1", "hash-0", "hash-2"} {
+		target, revisions, err = k.ensureConfigRevision(gvr, target, hash, nil, nil)
+		require.NoError(t, err)
+	}
+
+	// the revision of an earlier hash is moved to the latest one, and the revisions beyond the limit are pruned
+	hashes := make([]string, 0, len(revisions))
+	for _, revision := range revisions {
+		hashes = append(hashes, revision.Hash)
+	}
+	assert.Equal(t, []string{"hash-0", "hash-2"}, hashes)
+
+	// only references are kept in the annotation, and the contents are stored in controller revisions
+	annotation := target.GetAnnotations()[consts.KCCTargetAnnotationKeyConfigRevisions]
+	assert.NotContains(t, annotation, "config")
+	controllerRevisions, err := kubeClient.AppsV1().ControllerRevisions("default").List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err)
+	names := make([]string, 0, len(controllerRevisions.Items))
+	for _, revision := range controllerRevisions.Items {
+		names = append(names, revision.Name)
+	}
+	assert.ElementsMatch(t, []string{
+		"adminqosconfigurations-config-1-hash-0",
+		"adminqosconfigurations-config-1-hash-2",
+	}, names)
+}
+
+func newTestAdminQoSTarget(threshold float64) util.KCCTargetResource {
+	return util.ToKCCTargetResource(toTestUnstructured(&configapis.AdminQoSConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "config-1"},
+		Spec: configapis.AdminQoSConfigurationSpec{
+			Config: configapis.AdminQoSConfig{
+				EvictionConfig: &configapis.EvictionConfig{
+					ReclaimedResourcesEvictionConfig: &configapis.ReclaimedResourcesEvictionConfig{
+						EvictionThreshold: map[corev1.ResourceName]float64{corev1.ResourceCPU: threshold},
+					},
+				},
+			},
+		},
+	}))
+}
+
+func TestKatalystCustomConfigTargetController_revertCNCs(t *testing.T) {
+	t.Parallel()
+
+	gvr := metav1.GroupVersionResource(crd.AdminQoSConfigurationGVR)
+	targetConfig := func(hash string) configapis.TargetConfig {
+		return configapis.TargetConfig{ConfigType: gvr, ConfigNamespace: "default", ConfigName: "config-1", Hash: hash}
+	}
+
+	kubeClient := fake.NewSimpleClientset()
+	k := &KatalystCustomConfigTargetController{
+		ctx:                 context.TODO(),
+		unstructuredControl: control.DummyUnstructuredControl{},
+		cncControl:          control.DummyCNCControl{},
+		revisionControl:     control.NewRealControllerRevisionControl(kubeClient),
+	}
+
+	// the target is modified in place from hash-0 to hash-1
+	oldTarget := newTestAdminQoSTarget(1)
+	oldHash, err := oldTarget.DeepCopy().GenerateConfigHash()
+	require.NoError(t, err)
+	target, revisions, err := k.ensureConfigRevision(gvr, oldTarget, oldHash, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+
+	// CNCs can't be reverted without a previous revision
+	cncs := []*configapis.CustomNodeConfig{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-0"}, Status: configapis.CustomNodeConfigStatus{
+			KatalystCustomConfigList: []configapis.TargetConfig{targetConfig(oldHash)},
+		}},
+	}
+	reverted, unrevertable, errs := k.revertCNCs(gvr, target, oldHash, revisions, []int{0}, cncs)
+	assert.Empty(t, errs)
+	assert.Equal(t, 0, reverted)
+	assert.Equal(t, 1, unrevertable)
+
+	newTargetResource := newTestAdminQoSTarget(2)
+	newTargetResource.SetAnnotations(target.GetAnnotations())
+	hash, err := newTargetResource.DeepCopy().GenerateConfigHash()
+	require.NoError(t, err)
+
+	cncs = []*configapis.CustomNodeConfig{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Status: configapis.CustomNodeConfigStatus{
+			KatalystCustomConfigList: []configapis.TargetConfig{targetConfig(hash)},
+		}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}, Status: configapis.CustomNodeConfigStatus{
+			KatalystCustomConfigList: []configapis.TargetConfig{targetConfig(hash)},
+		}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-3"}, Status: configapis.CustomNodeConfigStatus{
+			KatalystCustomConfigList: []configapis.TargetConfig{targetConfig(oldHash)},
+		}},
+	}
+	target, revisions, err = k.ensureConfigRevision(gvr, newTargetResource, hash, []int{0, 1, 2}, cncs)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+
+	// the CNCs updated by the rollout are reverted to the previous revision
+	reverted, unrevertable, errs = k.revertCNCs(gvr, target, hash, revisions, []int{0, 1, 2}, cncs)
+	assert.Empty(t, errs)
+	assert.Equal(t, 2, reverted)
+	assert.Equal(t, 0, unrevertable)
+	for _, cnc := range cncs {
+		assert.Equal(t, []configapis.TargetConfig{targetConfig(oldHash)}, cnc.Status.KatalystCustomConfigList)
+	}
+
+	// agents restore the content of the reverted hash from the controller revision
+	namespace, name := util.GetKCCTargetControllerRevisionKey(gvr, target.GetNamespace(), target.GetName(), oldHash)
+	revision, err := kubeClient.AppsV1().ControllerRevisions(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	restored, err := util.RestoreKCCTargetConfigRevision(target, oldHash, revision)
+	require.NoError(t, err)
+	conf := &configapis.AdminQoSConfiguration{}
+	require.NoError(t, restored.Unmarshal(conf))
+	assert.Equal(t, 1.0, conf.Spec.Config.EvictionConfig.ReclaimedResourcesEvictionConfig.EvictionThreshold[corev1.ResourceCPU])
+}
@@ -92,6 +92,24 @@ func ApplyKCCTargetConfigToCNC(
 	}
 }
 
+// RestoreTargetConfigToCNC sets the target config of the given gvr in CNC back to the previous one,
+// and the target config will be removed if there was no previous one.
+func RestoreTargetConfigToCNC(
+	cnc *apisv1alpha1.CustomNodeConfig,
+	gvr metav1.GroupVersionResource,
+	previous *apisv1alpha1.TargetConfig,
+) {
+	katalystCustomConfigList := make([]apisv1alpha1.TargetConfig, 0, len(cnc.Status.KatalystCustomConfigList))
+	for _, targetConfig := range cnc.Status.KatalystCustomConfigList {
+		if targetConfig.ConfigType != gvr {
+			katalystCustomConfigList = append(katalystCustomConfigList, targetConfig)
+		} else if previous != nil {
+			katalystCustomConfigList = append(katalystCustomConfigList, *previous)
+		}
+	}
+	cnc.Status.KatalystCustomConfigList = katalystCustomConfigList
+}
+
 // FindMatchedKCCTargetConfigForNode finds the KCC target that should be applied to this CNC.
 // The KCCTs passed in must be non-terminating and updated.
 // The rule of cnc match to config is:
@@ -213,6 +231,18 @@ func UpdateKCCTGenericConditions(status *apisv1alpha1.GenericConfigStatus, condi
 	return updated
 }
 
+// GetKCCTGenericCondition returns the condition of the given type, or nil if not found
+func GetKCCTGenericCondition(status apisv1alpha1.GenericConfigStatus,
+	conditionType apisv1alpha1.ConfigConditionType,
+) *apisv1alpha1.GenericConfigCondition {
+	for idx := range status.Conditions {
+		if status.Conditions[idx].Type == conditionType {
+			return &status.Conditions[idx]
+		}
+	}
+	return nil
+}
+
 // EnsureKCCTargetFinalizer is used to add finalizer in kcc target
 // any component (that depends on kcc target) should add a specific finalizer in the target CR
 func EnsureKCCTargetFinalizer(ctx context.Context, unstructuredControl control.UnstructuredControl, finalizerName string,
@@ -0,0 +1,281 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	apisv1alpha1 "github.com/kubewharf/katalyst-api/pkg/apis/config/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/util"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+)
+
+// ValidateTargetResourceGenericSpec validate target resource generic spec as follows rule:
+// 1. can not set both labelSelector and nodeNames config at the same time
+// 2. if nodeNames is not set, lastDuration must not be set either
+// 3. labelSelector config must only contain kcc' labelSelectorKey in priority allowed key list
+// 4. labelSelector config cannot overlap with other labelSelector config in same priority
+// 5. nodeNames config must set lastDuration to make sure it will be auto cleared
+// 6. nodeNames config cannot overlap with other nodeNames config
+// 7. it is not allowed two global config (without either labelSelector or nodeNames) overlap
+func ValidateTargetResourceGenericSpec(
+	kcc *apisv1alpha1.KatalystCustomConfig,
+	targetResource util.KCCTargetResource,
+	allTargetResources []util.KCCTargetResource,
+) (bool, string, error) {
+	labelSelector := targetResource.GetLabelSelector()
+	nodeNames := targetResource.GetNodeNames()
+	if len(labelSelector) != 0 && len(nodeNames) != 0 {
+		return false, "both labelSelector and nodeNames has been set", nil
+	} else if len(labelSelector) != 0 {
+		return validateTargetResourceLabelSelector(kcc, targetResource, allTargetResources)
+	} else if len(nodeNames) != 0 {
+		return validateTargetResourceNodeNames(kcc, targetResource, allTargetResources)
+	} else {
+		return validateTargetResourceGlobal(kcc, targetResource, allTargetResources)
+	}
+}
+
+func validateTargetResourceLabelSelector(
+	kcc *apisv1alpha1.KatalystCustomConfig,
+	targetResource util.KCCTargetResource,
+	allTargetResources []util.KCCTargetResource,
+) (bool, string, error) {
+	priorityAllowedKeyListMap := getPriorityAllowedKeyListMap(kcc)
+	if len(priorityAllowedKeyListMap) == 0 {
+		return false, fmt.Sprintf("kcc %s no support label selector", native.GenerateUniqObjectNameKey(kcc)), nil
+	}
+
+	valid, msg, err := validateLabelSelectorMatchWithKCCDefinition(priorityAllowedKeyListMap, targetResource)
+	if err != nil {
+		return false, "", nil
+	} else if !valid {
+		return false, msg, nil
+	}
+
+	return validateLabelSelectorOverlapped(priorityAllowedKeyListMap, targetResource, allTargetResources)
+}
+
+func getPriorityAllowedKeyListMap(kcc *apisv1alpha1.KatalystCustomConfig) map[int32]sets.String {
+	priorityAllowedKeyListMap := make(map[int32]sets.String)
+	for _, allowedKey := range kcc.Spec.NodeLabelSelectorAllowedKeyList {
+		priorityAllowedKeyListMap[allowedKey.Priority] = sets.NewString(allowedKey.KeyList...)
+	}
+	return priorityAllowedKeyListMap
+}
+
+// validateLabelSelectorMatchWithKCCDefinition make sures that labelSelector config must only contain key in kcc' allowed key list
+func validateLabelSelectorMatchWithKCCDefinition(priorityAllowedKeyListMap map[int32]sets.String, targetResource util.KCCTargetResource) (bool, string, error) {
+	if targetResource.GetLastDuration() != nil {
+		return false, "both labelSelector and lastDuration has been set", nil
+	}
+
+	labelSelector := targetResource.GetLabelSelector()
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return false, fmt.Sprintf("labelSelector parse failed: %s", err), nil
+	}
+
+	priority := targetResource.GetPriority()
+	allowedKeyList, ok := priorityAllowedKeyListMap[priority]
+	if !ok {
+		return false, fmt.Sprintf("priority %d not supported", priority), nil
+	}
+
+	reqs, selectable := selector.Requirements()
+	if !selectable {
+		return false, fmt.Sprintf("labelSelector cannot selectable"), nil
+	}
+
+	inValidLabelKeys := sets.String{}
+	for _, r := range reqs {
+		key := r.Key()
+		if !allowedKeyList.Has(key) {
+			inValidLabelKeys.Insert(key)
+		}
+	}
+
+	if len(inValidLabelKeys) > 0 {
+		return false, fmt.Sprintf("labelSelector with invalid key %v (%s)", inValidLabelKeys.List(), allowedKeyList.List()), nil
+	}
+
+	return true, "", nil
+}
+
+// validateLabelSelectorOverlapped make sures that labelSelector config cannot overlap with other labelSelector config
+func validateLabelSelectorOverlapped(priorityAllowedKeyListMap map[int32]sets.String, targetResource util.KCCTargetResource,
+	otherResources []util.KCCTargetResource,
+) (bool, string, error) {
+	labelSelector := targetResource.GetLabelSelector()
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return false, fmt.Sprintf("labelSelector parse failed: %s", err), nil
+	}
+
+	priority := targetResource.GetPriority()
+	allowedKeyList, ok := priorityAllowedKeyListMap[priority]
+	if !ok {
+		return false, fmt.Sprintf("priority %d not supported", priority), nil
+	}
+
+	overlapResources := sets.String{}
+	for _, res := range otherResources {
+		if (res.GetNamespace() == targetResource.GetNamespace() && res.GetName() == targetResource.GetName()) ||
+			len(res.GetLabelSelector()) == 0 {
+			continue
+		}
+
+		otherSelector, err := labels.Parse(res.GetLabelSelector())
+		if err != nil {
+			continue
+		}
+
+		otherPriority := res.GetPriority()
+		if otherPriority != priority {
+			continue
+		}
+
+		overlap := checkLabelSelectorOverlap(selector, otherSelector, allowedKeyList.List())
+		if overlap {
+			overlapResources.Insert(native.GenerateUniqObjectNameKey(res))
+		}
+	}
+
+	if len(overlapResources) > 0 {
+		return false, fmt.Sprintf("labelSelector overlay with others: %v", overlapResources.List()), nil
+	}
+
+	return true, "", nil
+}
+
+func validateTargetResourceNodeNames(
+	kcc *apisv1alpha1.KatalystCustomConfig,
+	targetResource util.KCCTargetResource,
+	allTargetResources []util.KCCTargetResource,
+) (bool, string, error) {
+	if targetResource.GetLastDuration() == nil {
+		return false, "nodeNames has been set but lastDuration no set", nil
+	}
+
+	return validateTargetResourceNodeNamesOverlapped(targetResource, allTargetResources)
+}
+
+// validateLabelSelectorOverlapped make sures that nodeNames config cannot overlap with other labelSelector config
+func validateTargetResourceNodeNamesOverlapped(targetResource util.KCCTargetResource, otherResources []util.KCCTargetResource) (bool, string, error) {
+	nodeNames := sets.NewString(targetResource.GetNodeNames()...)
+
+	overlapResources := sets.String{}
+	for _, res := range otherResources {
+		if (res.GetNamespace() == targetResource.GetNamespace() && res.GetName() == targetResource.GetName()) ||
+			len(res.GetNodeNames()) == 0 {
+			continue
+		}
+
+		otherNodeNames := sets.NewString(res.GetNodeNames()...)
+		if nodeNames.Intersection(otherNodeNames).Len() > 0 {
+			overlapResources.Insert(native.GenerateUniqObjectNameKey(res))
+		}
+	}
+
+	if len(overlapResources) > 0 {
+		return false, fmt.Sprintf("nodeNames overlay with others: %v", overlapResources.List()), nil
+	}
+
+	return true, "", nil
+}
+
+func validateTargetResourceGlobal(
+	kcc *apisv1alpha1.KatalystCustomConfig,
+	targetResource util.KCCTargetResource,
+	allTargetResources []util.KCCTargetResource,
+) (bool, string, error) {
+	if targetResource.GetLastDuration() != nil {
+		return false, "lastDuration has been set for global config", nil
+	}
+
+	return validateTargetResourceGlobalOverlapped(targetResource, allTargetResources)
+}
+
+// validateLabelSelectorOverlapped make sures that only one global configurations is created.
+func validateTargetResourceGlobalOverlapped(targetResource util.KCCTargetResource, otherResources []util.KCCTargetResource) (bool, string, error) {
+	overlapTargetNames := sets.String{}
+	for _, res := range otherResources {
+		if (res.GetNamespace() == targetResource.GetNamespace() && res.GetName() == targetResource.GetName()) ||
+			(len(res.GetNodeNames()) > 0 || len(res.GetLabelSelector()) > 0) {
+			continue
+		}
+
+		overlapTargetNames.Insert(native.GenerateUniqObjectNameKey(res))
+	}
+
+	if len(overlapTargetNames) > 0 {
+		return false, fmt.Sprintf("global config %s overlay with others: %v",
+			native.GenerateUniqObjectNameKey(targetResource), overlapTargetNames.List()), nil
+	}
+
+	return true, "", nil
+}
+
+// checkLabelSelectorOverlap checks whether the labelSelector overlap with other labelSelector by the keyList
+func checkLabelSelectorOverlap(selector labels.Selector, otherSelector labels.Selector,
+	keyList []string,
+) bool {
+	for _, key := range keyList {
+		equalValueSet, inEqualValueSet, _ := getMatchValueSet(selector, key)
+		otherEqualValueSet, otherInEqualValueSet, _ := getMatchValueSet(otherSelector, key)
+		if (equalValueSet.Len() > 0 && otherEqualValueSet.Len() > 0 && equalValueSet.Intersection(otherEqualValueSet).Len() > 0) ||
+			(equalValueSet.Len() == 0 && otherEqualValueSet.Len() == 0) ||
+			(inEqualValueSet.Len() > 0 && !inEqualValueSet.Intersection(otherEqualValueSet).Equal(otherEqualValueSet)) ||
+			(otherInEqualValueSet.Len() > 0 && !otherInEqualValueSet.Intersection(equalValueSet).Equal(equalValueSet)) ||
+			(equalValueSet.Len() > 0 && otherEqualValueSet.Len() == 0 && otherInEqualValueSet.Len() == 0) ||
+			(otherEqualValueSet.Len() > 0 && equalValueSet.Len() == 0 && inEqualValueSet.Len() == 0) {
+			continue
+		} else {
+			return false
+		}
+	}
+
+	return true
+}
+
+func getMatchValueSet(selector labels.Selector, key string) (sets.String, sets.String, error) {
+	reqs, selectable := selector.Requirements()
+	if !selectable {
+		return nil, nil, fmt.Errorf("labelSelector cannot selectable")
+	}
+
+	equalValueSet := sets.String{}
+	inEqualValueSet := sets.String{}
+	for _, r := range reqs {
+		if r.Key() != key {
+			continue
+		}
+		switch r.Operator() {
+		case selection.Equals, selection.DoubleEquals, selection.In:
+			equalValueSet = equalValueSet.Union(r.Values())
+		case selection.NotEquals, selection.NotIn:
+			inEqualValueSet = inEqualValueSet.Union(r.Values())
+		default:
+			return nil, nil, fmt.Errorf("labelSelector operator %s not supported", r.Operator())
+		}
+	}
+	return equalValueSet, inEqualValueSet, nil
+}
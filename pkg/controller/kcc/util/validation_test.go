@@ -0,0 +1,386 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	apisv1alpha1 "github.com/kubewharf/katalyst-api/pkg/apis/config/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/util"
+)
+
+func testLabelSelector(t *testing.T, labelSelector string) labels.Selector {
+	parse, err := labels.Parse(labelSelector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parse
+}
+
+func generateTestLabelSelectorTargetResource(name, labelSelector string, priority int32) util.KCCTargetResource {
+	return util.ToKCCTargetResource(toTestUnstructured(&apisv1alpha1.AdminQoSConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: apisv1alpha1.AdminQoSConfigurationSpec{
+			GenericConfigSpec: apisv1alpha1.GenericConfigSpec{
+				NodeLabelSelector: labelSelector,
+				Priority:          priority,
+			},
+		},
+	}))
+}
+
+func generateTestNodeNamesTargetResource(name string, nodeNames []string) util.KCCTargetResource {
+	return util.ToKCCTargetResource(toTestUnstructured(&apisv1alpha1.AdminQoSConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: apisv1alpha1.AdminQoSConfigurationSpec{
+			GenericConfigSpec: apisv1alpha1.GenericConfigSpec{
+				EphemeralSelector: apisv1alpha1.EphemeralSelector{
+					NodeNames: nodeNames,
+				},
+			},
+		},
+	}))
+}
+
+func Test_validateLabelSelectorWithOthers(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		priorityAllowedKeyListMap map[int32]sets.String
+		targetResource            util.KCCTargetResource
+		otherResources            []util.KCCTargetResource
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "test-1",
+			args: args{
+				priorityAllowedKeyListMap: map[int32]sets.String{
+					0: sets.NewString("aa"),
+				},
+				targetResource: generateTestLabelSelectorTargetResource("1", "aa=bb", 0),
+				otherResources: []util.KCCTargetResource{
+					generateTestLabelSelectorTargetResource("2", "aa=cc", 0),
+				},
+			},
+			want: true,
+		},
+		{
+			name: "test-2",
+			args: args{
+				priorityAllowedKeyListMap: map[int32]sets.String{
+					0: sets.NewString("aa"),
+				},
+				targetResource: generateTestLabelSelectorTargetResource("1", "aa=bb", 0),
+				otherResources: []util.KCCTargetResource{
+					generateTestLabelSelectorTargetResource("2", "aa in (cc,dd)", 0),
+				},
+			},
+			want: true,
+		},
+		{
+			name: "test-3",
+			args: args{
+				priorityAllowedKeyListMap: map[int32]sets.String{
+					0: sets.NewString("aa"),
+				},
+				targetResource: generateTestLabelSelectorTargetResource("1", "aa=bb", 0),
+				otherResources: []util.KCCTargetResource{
+					generateTestLabelSelectorTargetResource("2", "aa in (bb,cc)", 0),
+				},
+			},
+			want: false,
+		},
+		{
+			name: "test-4",
+			args: args{
+				priorityAllowedKeyListMap: map[int32]sets.String{
+					0: sets.NewString("aa"),
+				},
+				targetResource: generateTestLabelSelectorTargetResource("1", "aa=bb", 0),
+				otherResources: []util.KCCTargetResource{
+					generateTestLabelSelectorTargetResource("2", "aa notin (bb,cc)", 0),
+				},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, _, err := validateLabelSelectorOverlapped(tt.args.priorityAllowedKeyListMap, tt.args.targetResource, tt.args.otherResources)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateLabelSelectorOverlapped() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("validateLabelSelectorOverlapped() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_validateTargetResourceNodeNamesWithOthers(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		targetResource util.KCCTargetResource
+		otherResources []util.KCCTargetResource
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "test-1",
+			args: args{
+				targetResource: generateTestNodeNamesTargetResource("1", []string{"node-1"}),
+				otherResources: []util.KCCTargetResource{
+					generateTestNodeNamesTargetResource("2", []string{"node-2"}),
+				},
+			},
+			want: true,
+		},
+		{
+			name: "test-2",
+			args: args{
+				targetResource: generateTestNodeNamesTargetResource("1", []string{"node-1"}),
+				otherResources: []util.KCCTargetResource{
+					generateTestNodeNamesTargetResource("2", []string{"node-2", "node-3"}),
+				},
+			},
+			want: true,
+		},
+		{
+			name: "test-3",
+			args: args{
+				targetResource: generateTestNodeNamesTargetResource("1", []string{"node-1"}),
+				otherResources: []util.KCCTargetResource{
+					generateTestNodeNamesTargetResource("2", []string{"node-1", "node-3"}),
+				},
+			},
+			want: false,
+		},
+		{
+			name: "test-4",
+			args: args{
+				targetResource: generateTestNodeNamesTargetResource("1", []string{"node-1", "node-2"}),
+				otherResources: []util.KCCTargetResource{
+					generateTestNodeNamesTargetResource("2", []string{"node-3", "node-4"}),
+				},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, _, err := validateTargetResourceNodeNamesOverlapped(tt.args.targetResource, tt.args.otherResources)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateTargetResourceNodeNamesOverlapped() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("validateTargetResourceNodeNamesOverlapped() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_validateTargetResourceGlobalWithOthers(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		targetResource util.KCCTargetResource
+		otherResources []util.KCCTargetResource
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "test-1",
+			args: args{
+				targetResource: generateTestLabelSelectorTargetResource("1", "", 0),
+				otherResources: []util.KCCTargetResource{
+					generateTestLabelSelectorTargetResource("2", "", 0),
+				},
+			},
+			want: false,
+		},
+		{
+			name: "test-2",
+			args: args{
+				targetResource: generateTestLabelSelectorTargetResource("1", "", 0),
+				otherResources: []util.KCCTargetResource{
+					generateTestLabelSelectorTargetResource("1", "", 0),
+					generateTestLabelSelectorTargetResource("2", "aa=bb", 0),
+				},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, _, err := validateTargetResourceGlobalOverlapped(tt.args.targetResource, tt.args.otherResources)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateTargetResourceGlobalOverlapped() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("validateTargetResourceGlobalOverlapped() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_checkLabelSelectorOverlap(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		selector      labels.Selector
+		otherSelector labels.Selector
+		keyList       []string
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{
+			name: "test-1",
+			args: args{
+				selector:      testLabelSelector(t, "label1=aa"),
+				otherSelector: testLabelSelector(t, "label1=bb"),
+				keyList:       []string{"label1"},
+			},
+			want: false,
+		},
+		{
+			name: "test-2",
+			args: args{
+				selector:      testLabelSelector(t, "label1=aa"),
+				otherSelector: testLabelSelector(t, "label1!=bb"),
+				keyList:       []string{"label1"},
+			},
+			want: true,
+		},
+		{
+			name: "test-3",
+			args: args{
+				selector:      testLabelSelector(t, "label1=aa"),
+				otherSelector: testLabelSelector(t, "label1 in (aa,bb)"),
+				keyList:       []string{"label1"},
+			},
+			want: true,
+		},
+		{
+			name: "test-4",
+			args: args{
+				selector:      testLabelSelector(t, "label1=aa"),
+				otherSelector: testLabelSelector(t, "label1 notin (aa,bb)"),
+				keyList:       []string{"label1"},
+			},
+			want: false,
+		},
+		{
+			name: "test-5",
+			args: args{
+				selector:      testLabelSelector(t, "label1=aa"),
+				otherSelector: testLabelSelector(t, "label1 in (aa,bb),label2=cc"),
+				keyList:       []string{"label1", "label2"},
+			},
+			want: true,
+		},
+		{
+			name: "test-6",
+			args: args{
+				selector:      testLabelSelector(t, "label1=aa"),
+				otherSelector: testLabelSelector(t, "label2=bb"),
+				keyList:       []string{"label1", "label2"},
+			},
+			want: true,
+		},
+		{
+			name: "test-7",
+			args: args{
+				selector:      testLabelSelector(t, "label1 notin (aa, bb),label2=bb"),
+				otherSelector: testLabelSelector(t, "label1 in (aa),label2=bb"),
+				keyList:       []string{"label1", "label2"},
+			},
+			want: false,
+		},
+		{
+			name: "test-8",
+			args: args{
+				selector:      testLabelSelector(t, "label1 in (aa),label2 notin (bb,cc)"),
+				otherSelector: testLabelSelector(t, "label1 notin (cc,dd),label2 notin (cc)"),
+				keyList:       []string{"label1", "label2"},
+			},
+			want: true,
+		},
+		{
+			name: "test-9",
+			args: args{
+				selector:      testLabelSelector(t, "label1=aa"),
+				otherSelector: testLabelSelector(t, "label1 notin (cc,dd),label2 notin (cc)"),
+				keyList:       []string{"label1", "label2"},
+			},
+			want: true,
+		},
+		{
+			name: "test-10",
+			args: args{
+				selector:      testLabelSelector(t, "label1 notin (aa)"),
+				otherSelector: testLabelSelector(t, "label1=cc"),
+				keyList:       []string{"label1", "label2"},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equalf(t, tt.want, checkLabelSelectorOverlap(tt.args.selector, tt.args.otherSelector, tt.args.keyList), "checkLabelSelectorOverlap(%v, %v, %v)", tt.args.selector, tt.args.otherSelector, tt.args.keyList)
+		})
+	}
+}
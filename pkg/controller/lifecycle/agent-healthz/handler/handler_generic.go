@@ -44,7 +44,9 @@ func init() {
 }
 
 // GenericAgentHandler implements AgentHandler with generic
-// actions: i.e. taint cnr and trigger eviction for reclaimed_cores
+// actions: i.e. taint cnr and trigger eviction for reclaimed_cores;
+// if the agent is still ready but some of its components are degraded,
+// the actions are decided by the remediation configured for each component.
 type GenericAgentHandler struct {
 	ctx     context.Context
 	agent   string
@@ -53,6 +55,8 @@ type GenericAgentHandler struct {
 	nodeSelector labels.Selector
 	qosConf      *generic.QoSConfiguration
 
+	componentRemediations map[string]controller.ComponentRemediation
+
 	podIndexer cache.Indexer
 	nodeLister corelisters.NodeLister
 	cnrLister  listers.CustomNodeResourceLister
@@ -61,7 +65,7 @@ type GenericAgentHandler struct {
 }
 
 func NewGenericAgentHandler(ctx context.Context, agent string, emitter metrics.MetricEmitter,
-	genericConf *generic.GenericConfiguration, _ *controller.LifeCycleConfig, nodeSelector labels.Selector,
+	genericConf *generic.GenericConfiguration, conf *controller.LifeCycleConfig, nodeSelector labels.Selector,
 	podIndexer cache.Indexer, nodeLister corelisters.NodeLister, cnrLister listers.CustomNodeResourceLister,
 	checker *helper.HealthzHelper,
 ) AgentHandler {
@@ -73,6 +77,8 @@ func NewGenericAgentHandler(ctx context.Context, agent string, emitter metrics.M
 		nodeSelector: nodeSelector,
 		qosConf:      genericConf.QoSConfiguration,
 
+		componentRemediations: conf.ComponentRemediations,
+
 		podIndexer: podIndexer,
 		nodeLister: nodeLister,
 		cnrLister:  cnrLister,
@@ -89,8 +95,8 @@ func (g *GenericAgentHandler) GetEvictionInfo(nodeName string) (*helper.EvictIte
 	}
 
 	if g.checker.CheckAgentReady(nodeName, g.agent) {
-		// not to trigger eviction if agent is still ready
-		return nil, false
+		// not to trigger eviction if agent is still ready, unless some components require it
+		return g.getComponentEvictionInfo(node)
 	}
 
 	pods := g.getNodeReclaimedPods(node)
@@ -114,8 +120,8 @@ func (g *GenericAgentHandler) GetCNRTaintInfo(nodeName string) (*helper.CNRTaint
 	}
 
 	if g.checker.CheckAgentReady(nodeName, g.agent) {
-		// not to trigger eviction if agent is still ready
-		return nil, false
+		// not to trigger taints if agent is still ready, unless some components require it
+		return g.getComponentCNRTaintInfo(nodeName)
 	} else if util.CNRTaintExists(cnr.Spec.Taints, &helper.TaintReclaimedCoresNoSchedule) {
 		// if taint already exists, not to trigger taints
 		return nil, false
@@ -128,6 +134,76 @@ func (g *GenericAgentHandler) GetCNRTaintInfo(nodeName string) (*helper.CNRTaint
 	}, true
 }
 
+// getComponentEvictionInfo returns the reclaimed pods to evict for the degraded components
+// whose remediation is to evict reclaimed pods
+func (g *GenericAgentHandler) getComponentEvictionInfo(node *corev1.Node) (*helper.EvictItem, bool) {
+	var components []string
+	for _, component := range g.getRemediableComponents(node.Name) {
+		if g.componentRemediations[component] == controller.ComponentRemediationEvictReclaimed {
+			components = append(components, component)
+		}
+	}
+	if len(components) == 0 {
+		return nil, false
+	}
+
+	pods, err := native.GetPodsAssignedToNode(node.Name, g.podIndexer)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("unable to list pods from node %q: %v", node.Name, err))
+		return nil, false
+	}
+
+	var keys []string
+	for _, pod := range pods {
+		if ok, err := g.qosConf.CheckReclaimedQoSForPod(pod); err == nil && ok {
+			keys = append(keys, native.GenerateUniqObjectNameKey(pod))
+		}
+	}
+	if len(keys) == 0 {
+		return nil, false
+	}
+
+	item := &helper.EvictItem{
+		ComponentPodKeys: make(map[string][]string),
+	}
+	for _, component := range components {
+		item.ComponentPodKeys[component] = keys
+	}
+	return item, true
+}
+
+// getComponentCNRTaintInfo returns the taints required by the degraded components;
+// the taints are returned even if they already exist, to keep them from being removed.
+func (g *GenericAgentHandler) getComponentCNRTaintInfo(nodeName string) (*helper.CNRTaintItem, bool) {
+	taints := make(map[string]apis.Taint)
+	for _, component := range g.getRemediableComponents(nodeName) {
+		switch g.componentRemediations[component] {
+		case controller.ComponentRemediationTaintReclaimed:
+			taints[helper.TaintNameReclaimedCoresNoSchedule] = helper.TaintReclaimedCoresNoSchedule
+		case controller.ComponentRemediationNoScheduleDedicated:
+			taints[helper.TaintNameDedicatedCoresNoSchedule] = helper.TaintDedicatedCoresNoSchedule
+		}
+	}
+
+	if len(taints) == 0 {
+		return nil, false
+	}
+	return &helper.CNRTaintItem{Taints: taints}, true
+}
+
+// getRemediableComponents returns the degraded components whose remediations are not suspended
+func (g *GenericAgentHandler) getRemediableComponents(nodeName string) []string {
+	var components []string
+	for _, component := range g.checker.GetDegradedComponents(nodeName) {
+		if g.checker.IsComponentSuspended(component) {
+			klog.Infof("remediation of component %v for node %v is suspended", component, nodeName)
+			continue
+		}
+		components = append(components, component)
+	}
+	return components
+}
+
 // getNodeReclaimedPods returns reclaimed pods contained in the given node,
 // only those nodes with reclaimed pods should be triggered with eviction/taint logic for generic agents
 func (g *GenericAgentHandler) getNodeReclaimedPods(node *corev1.Node) (names []string) {
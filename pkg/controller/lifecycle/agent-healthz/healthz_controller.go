@@ -24,6 +24,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
@@ -72,6 +73,8 @@ type HealthzController struct {
 	evictionLimiterQPS float32
 	nodeSelector       labels.Selector
 
+	componentRemediations map[string]controller.ComponentRemediation
+
 	taintQueue *scheduler.RateLimitedTimedQueue
 	evictQueue *scheduler.RateLimitedTimedQueue
 
@@ -101,6 +104,8 @@ func NewHealthzController(ctx context.Context,
 		evictionLimiterQPS: conf.EvictQPS,
 		nodeSelector:       conf.NodeSelector,
 
+		componentRemediations: conf.ComponentRemediations,
+
 		taintQueue: scheduler.NewRateLimitedTimedQueue(flowcontrol.NewTokenBucketRateLimiter(conf.TaintQPS, scheduler.EvictionRateLimiterBurst)),
 		evictQueue: scheduler.NewRateLimitedTimedQueue(flowcontrol.NewTokenBucketRateLimiter(conf.EvictQPS, scheduler.EvictionRateLimiterBurst)),
 
@@ -183,6 +188,8 @@ func (ec *HealthzController) syncAgentHealth() {
 		return
 	}
 
+	ec.computeComponentStates(nodes)
+
 	taints := make(map[string]*helper.CNRTaintItem)
 	evicts := make(map[string]*helper.EvictItem)
 	for _, node := range nodes {
@@ -199,16 +206,21 @@ func (ec *HealthzController) syncAgentHealth() {
 				}
 			}
 
-			if item, ok := h.GetEvictionInfo(node.Name); ok && item != nil && len(item.PodKeys) > 0 {
+			if item, ok := h.GetEvictionInfo(node.Name); ok && item != nil &&
+				(len(item.PodKeys) > 0 || len(item.ComponentPodKeys) > 0) {
 				if _, exist := evicts[node.Name]; !exist {
 					evicts[node.Name] = &helper.EvictItem{
-						PodKeys: make(map[string][]string),
+						PodKeys:          make(map[string][]string),
+						ComponentPodKeys: make(map[string][]string),
 					}
 				}
 
 				for agent, pods := range item.PodKeys {
 					evicts[node.Name].PodKeys[agent] = pods
 				}
+				for component, pods := range item.ComponentPodKeys {
+					evicts[node.Name].ComponentPodKeys[component] = pods
+				}
 			}
 		}
 	}
@@ -256,6 +268,39 @@ func (ec *HealthzController) computeClusterState(readyNodes, notReadyNodes int,
 	}
 }
 
+// computeComponentStates computes the cluster state for each agent component; if a component
+// is degraded in a large scope, it's more likely to be caused by the component itself (e.g. a bad
+// release) rather than the nodes, so the remediations of this component will be suspended.
+func (ec *HealthzController) computeComponentStates(nodes []*corev1.Node) {
+	degradedNodes := make(map[string]int)
+	for _, node := range nodes {
+		for _, component := range ec.healthzHelper.GetDegradedComponents(node.Name) {
+			degradedNodes[component]++
+		}
+	}
+
+	suspended := sets.NewString()
+	for component := range ec.componentRemediations {
+		state := ec.computeClusterState(len(nodes)-degradedNodes[component], degradedNodes[component], ec.taintThreshold)
+		if state != stateNormal {
+			suspended.Insert(component)
+		}
+
+		_ = ec.emitter.StoreInt64(metricsNameHealthState, 1, metrics.MetricTypeNameRaw,
+			[]metrics.MetricTag{
+				{Key: "action", Val: "component"},
+				{Key: "component", Val: component},
+				{Key: "status", Val: state},
+				{Key: "threshold", Val: fmt.Sprintf("%v", ec.taintThreshold)},
+			}...)
+		if degradedNodes[component] > 0 {
+			klog.Infof("component %v is degraded on %v nodes, state %v", component, degradedNodes[component], state)
+		}
+	}
+
+	ec.healthzHelper.SetSuspendedComponents(suspended)
+}
+
 // handleTaintDisruption is used as a protection logic, if the cluster fall into
 // unhealthy state in a large scope, perhaps something goes wrong, we should hold on tainting
 func (ec *HealthzController) handleTaintDisruption(healthState string) {
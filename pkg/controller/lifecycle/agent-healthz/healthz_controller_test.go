@@ -37,9 +37,12 @@ import (
 	apis "github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
 	internalfake "github.com/kubewharf/katalyst-api/pkg/client/clientset/versioned/fake"
 	"github.com/kubewharf/katalyst-api/pkg/client/informers/externalversions"
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
 	"github.com/kubewharf/katalyst-core/cmd/katalyst-controller/app/options"
 	"github.com/kubewharf/katalyst-core/pkg/client"
 	pkgconfig "github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/controller/lifecycle/agent-healthz/helper"
 )
 
 func generateTestKubeClientSet(objects []runtime.Object, internalObjects []runtime.Object) *client.GenericClientSet {
@@ -212,3 +215,93 @@ func Test_podTransformerFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestHealthzController_componentRemediations(t *testing.T) {
+	t.Parallel()
+
+	agentLabels := map[string]string{"app": "katalyst-agent"}
+	componentHealth := `{"sysadvisor":{"ready":false,"message":"cpu_advisor_update: timeout"},` +
+		`"eviction-manager":{"ready":false,"message":"eviction_manager_sync: timeout"},"qrm-cpu":{"ready":true}}`
+
+	var kubeObjects, internalObjects []runtime.Object
+	for _, name := range []string{"node1", "node2"} {
+		kubeObjects = append(kubeObjects,
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "agent-" + name, Labels: agentLabels},
+				Spec:       corev1.PodSpec{NodeName: name, Containers: []corev1.Container{{Name: "katalyst-agent"}}},
+				Status:     corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Ready: true}}},
+			})
+
+		cnr := &apis.CustomNodeResource{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if name == "node1" {
+			cnr.Annotations = map[string]string{consts.AgentComponentHealthAnnotationKey: componentHealth}
+		}
+		internalObjects = append(internalObjects, cnr)
+	}
+	kubeObjects = append(kubeObjects, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "reclaimed-pod",
+			Annotations: map[string]string{apiconsts.PodAnnotationQoSLevelKey: apiconsts.PodAnnotationQoSLevelReclaimedCores},
+		},
+		Spec: corev1.PodSpec{NodeName: "node1", Containers: []corev1.Container{{Name: "main"}}},
+	})
+
+	clientSet := generateTestKubeClientSet(kubeObjects, internalObjects)
+	kubeInformerFactory := informers.NewSharedInformerFactoryWithOptions(clientSet.KubeClient, time.Hour*24)
+	internalInformerFactory := externalversions.NewSharedInformerFactoryWithOptions(clientSet.InternalClient, time.Hour*24)
+
+	conf := generateTestConfiguration(t)
+	lifeCycleConf := conf.ControllersConfiguration.LifeCycleConfig
+	lifeCycleConf.CheckWindow = 10 * time.Millisecond
+	lifeCycleConf.UnhealthyPeriods = 0
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ec, err := NewHealthzController(ctx, conf.GenericConfiguration, conf.GenericControllerConfiguration, lifeCycleConf,
+		clientSet, kubeInformerFactory.Core().V1().Nodes(), kubeInformerFactory.Core().V1().Pods(),
+		internalInformerFactory.Node().V1alpha1().CustomNodeResources(), nil)
+	require.NoError(t, err)
+
+	kubeInformerFactory.Start(ctx.Done())
+	internalInformerFactory.Start(ctx.Done())
+	require.True(t, cache.WaitForCacheSync(ctx.Done(), ec.nodeListerSynced, ec.cnrListerSynced, ec.podListerSynced))
+
+	ec.healthzHelper.Run()
+	require.Eventually(t, func() bool {
+		return len(ec.healthzHelper.GetDegradedComponents("node1")) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{consts.AgentComponentEvictionManager, consts.AgentComponentSysAdvisor},
+		ec.healthzHelper.GetDegradedComponents("node1"))
+	assert.Empty(t, ec.healthzHelper.GetDegradedComponents("node2"))
+
+	nodes, err := ec.nodeLister.List(labels.Everything())
+	require.NoError(t, err)
+	ec.computeComponentStates(nodes)
+
+	h := ec.handlers["katalyst-agent"]
+	taintItem, ok := h.GetCNRTaintInfo("node1")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]apis.Taint{
+		helper.TaintNameReclaimedCoresNoSchedule: helper.TaintReclaimedCoresNoSchedule,
+	}, taintItem.Taints)
+
+	evictItem, ok := h.GetEvictionInfo("node1")
+	assert.True(t, ok)
+	assert.Equal(t, map[string][]string{
+		consts.AgentComponentEvictionManager: {"default/reclaimed-pod"},
+	}, evictItem.ComponentPodKeys)
+
+	_, ok = h.GetCNRTaintInfo("node2")
+	assert.False(t, ok)
+
+	// remediations are suspended when components are degraded on all nodes
+	node1, err := ec.nodeLister.Get("node1")
+	require.NoError(t, err)
+	ec.computeComponentStates([]*corev1.Node{node1})
+	assert.True(t, ec.healthzHelper.IsComponentSuspended(consts.AgentComponentSysAdvisor))
+	_, ok = h.GetCNRTaintInfo("node1")
+	assert.False(t, ok)
+}
@@ -42,6 +42,8 @@ const metricsNameEvictedReclaimedPodCount = "evicted_reclaimed_pod_count"
 type EvictItem struct {
 	// PodKeys maps from agent-name to pod-keys (that should be evicted because of the Agents)
 	PodKeys map[string][]string
+	// ComponentPodKeys maps from agent component to pod-keys (that should be evicted because of the degraded components)
+	ComponentPodKeys map[string][]string
 }
 
 type EvictHelper struct {
@@ -101,6 +103,11 @@ func (e *EvictHelper) doEviction() {
 				keys.Insert(names...)
 			}
 		}
+		for component, names := range item.ComponentPodKeys {
+			if e.checker.CheckComponentDegraded(node.Name, component) && !e.checker.IsComponentSuspended(component) {
+				keys.Insert(names...)
+			}
+		}
 
 		if err := e.evictPods(node, keys.List()); err != nil {
 			klog.Warningf("failed to evict pods for cnr %v: %v", value.Value, err)
@@ -18,10 +18,13 @@ package helper
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -33,7 +36,9 @@ import (
 
 	listers "github.com/kubewharf/katalyst-api/pkg/client/listers/node/v1alpha1"
 	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
 	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
 	"github.com/kubewharf/katalyst-core/pkg/util/native"
 )
 
@@ -54,8 +59,11 @@ const (
 	metricsNameAgentNotFoundTotal = "agent_not_found_total"
 	metricsNameAgentReadyRate     = "agent_ready_rate"
 
-	metricsTagKeyAgentName = "agentName"
-	metricsTagKeyNodeName  = "nodeName"
+	metricsNameComponentNotReady = "agent_component_not_ready"
+
+	metricsTagKeyAgentName     = "agentName"
+	metricsTagKeyNodeName      = "nodeName"
+	metricsTagKeyComponentName = "componentName"
 )
 
 type healthData struct {
@@ -110,6 +118,22 @@ func (c *heartBeatMap) getHeartBeatInfo(node, agent string) (healthData, bool) {
 	return healthData{}, false
 }
 
+// retainHeartBeatInfo removes the agents of the given node which are not in the given set
+func (c *heartBeatMap) retainHeartBeatInfo(node string, agents sets.String) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for agent := range c.nodeHealths[node] {
+		if !agents.Has(agent) {
+			delete(c.nodeHealths[node], agent)
+		}
+	}
+
+	if len(c.nodeHealths[node]) == 0 {
+		delete(c.nodeHealths, node)
+	}
+}
+
 func (c *heartBeatMap) rangeNode(f func(node string) bool) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
@@ -137,6 +161,13 @@ type HealthzHelper struct {
 	cnrLister  listers.CustomNodeResourceLister
 
 	healthzMap *heartBeatMap
+	// componentMap stores the health of agent components reported by agents through CNR,
+	// and it shares the same structure with healthzMap by regarding components as agents.
+	componentMap *heartBeatMap
+
+	mutex sync.RWMutex
+	// suspendedComponents are the components whose remediations are suspended temporarily
+	suspendedComponents sets.String
 }
 
 // NewHealthzHelper todo add logic here
@@ -158,7 +189,10 @@ func NewHealthzHelper(ctx context.Context, conf *controller.LifeCycleConfig, emi
 		nodeLister: nodeLister,
 		cnrLister:  cnrLister,
 
-		healthzMap: newHeartBeatMap(),
+		healthzMap:   newHeartBeatMap(),
+		componentMap: newHeartBeatMap(),
+
+		suspendedComponents: sets.NewString(),
 	}
 }
 
@@ -190,6 +224,47 @@ func (h *HealthzHelper) CheckAgentReady(node string, agent string) bool {
 	return true
 }
 
+// CheckComponentDegraded checks whether the given agent component has been unhealthy for a period
+func (h *HealthzHelper) CheckComponentDegraded(node string, component string) bool {
+	health, found := h.componentMap.getHeartBeatInfo(node, component)
+	return found && health.status != agentReady && metav1.Now().After(health.probeTimestamp.Add(h.unhealthyPeriod))
+}
+
+// GetDegradedComponents returns the degraded agent components of the given node
+func (h *HealthzHelper) GetDegradedComponents(node string) []string {
+	h.componentMap.lock.RLock()
+	components := make([]string, 0, len(h.componentMap.nodeHealths[node]))
+	for component := range h.componentMap.nodeHealths[node] {
+		components = append(components, component)
+	}
+	h.componentMap.lock.RUnlock()
+
+	var degraded []string
+	for _, component := range components {
+		if h.CheckComponentDegraded(node, component) {
+			degraded = append(degraded, component)
+		}
+	}
+	sort.Strings(degraded)
+	return degraded
+}
+
+// SetSuspendedComponents sets the components whose remediations should be suspended
+func (h *HealthzHelper) SetSuspendedComponents(components sets.String) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.suspendedComponents = sets.NewString(components.UnsortedList()...)
+}
+
+// IsComponentSuspended checks whether the remediations of the given component are suspended
+func (h *HealthzHelper) IsComponentSuspended(component string) bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	return h.suspendedComponents.Has(component)
+}
+
 // syncHeartBeatMap is used to periodically sync health state ans s
 func (h *HealthzHelper) syncHeartBeatMap() {
 	nodes, err := h.nodeLister.List(h.nodeSelector)
@@ -242,6 +317,8 @@ func (h *HealthzHelper) syncHeartBeatMap() {
 				_ = h.emitter.StoreInt64(metricsNameAgentNotFound, 1, metrics.MetricTypeNameRaw, metricsTags...)
 			}
 		}
+
+		h.syncComponentHealth(node.Name)
 	}
 
 	nodeCount := len(nodes)
@@ -265,4 +342,51 @@ func (h *HealthzHelper) syncHeartBeatMap() {
 		}
 		return false
 	})
+
+	h.componentMap.rangeNode(func(node string) bool {
+		if !currentNodes.Has(node) {
+			delete(h.componentMap.nodeHealths, node)
+		}
+		return true
+	})
+}
+
+// syncComponentHealth updates the health of agent components from the annotation in CNR,
+// and the components no longer reported will be removed.
+func (h *HealthzHelper) syncComponentHealth(node string) {
+	components := sets.NewString()
+	defer h.componentMap.retainHeartBeatInfo(node, components)
+
+	cnr, err := h.cnrLister.Get(node)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			klog.Errorf("get cnr %v failed: %v", node, err)
+		}
+		return
+	}
+
+	value, ok := cnr.Annotations[consts.AgentComponentHealthAnnotationKey]
+	if !ok {
+		return
+	}
+
+	healths := make(map[string]general.ComponentHealth)
+	if err := json.Unmarshal([]byte(value), &healths); err != nil {
+		klog.Errorf("unmarshal component health of node %v failed: %v", node, err)
+		return
+	}
+
+	for component, health := range healths {
+		components.Insert(component)
+		if health.Ready {
+			h.componentMap.setHeartBeatInfo(node, component, agentReady, metav1.Now())
+			continue
+		}
+
+		h.componentMap.setHeartBeatInfo(node, component, agentNotReady, metav1.Now())
+		klog.Warningf("agent component %v for node %v is not ready: %v", component, node, health.Message)
+		_ = h.emitter.StoreInt64(metricsNameComponentNotReady, 1, metrics.MetricTypeNameRaw,
+			metrics.MetricTag{Key: metricsTagKeyNodeName, Val: node},
+			metrics.MetricTag{Key: metricsTagKeyComponentName, Val: component})
+	}
 }
@@ -52,8 +52,19 @@ var TaintReclaimedCoresNoSchedule = apis.Taint{
 	},
 }
 
+const TaintNameDedicatedCoresNoSchedule = "TaintNameDedicatedCoresNoSchedule"
+
+var TaintDedicatedCoresNoSchedule = apis.Taint{
+	QoSLevel: consts.QoSLevelDedicatedCores,
+	Taint: corev1.Taint{
+		Key:    corev1.TaintNodeUnschedulable,
+		Effect: corev1.TaintEffectNoSchedule,
+	},
+}
+
 var allTaints = []apis.Taint{
 	TaintReclaimedCoresNoSchedule,
+	TaintDedicatedCoresNoSchedule,
 }
 
 // CNRTaintItem records the detailed item to perform cnr-taints
@@ -0,0 +1,405 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	promapiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	metrics_plugin "github.com/kubewharf/katalyst-core/pkg/controller/npd/metrics-plugin"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/datasource/prometheus"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+)
+
+const (
+	PluginName = "usage-metrics-plugin"
+
+	podLabelNamespace = "namespace"
+	podLabelPod       = "pod"
+)
+
+// promql expressions of usage, the first argument is the node label to group
+// by and the second one is the extra filters.
+const (
+	NodeCPUUsageQueryExpr = `sum by (%[1]s) (rate(container_cpu_usage_seconds_total{id="/"%[2]s}[1m]))`
+	NodeMemUsageQueryExpr = `sum by (%[1]s) (container_memory_working_set_bytes{id="/"%[2]s})`
+	PodCPUUsageQueryExpr  = `sum by (%[1]s, namespace, pod) (rate(container_cpu_usage_seconds_total{container!="",container!="POD"%[2]s}[1m]))`
+	PodMemUsageQueryExpr  = `sum by (%[1]s, namespace, pod) (container_memory_working_set_bytes{container!="",container!="POD"%[2]s})`
+)
+
+// Aggregators are the aggregators that usage metrics are published with over each window
+var Aggregators = []v1alpha1.Aggregator{
+	v1alpha1.AggregatorAvg,
+	v1alpha1.AggregatorP95,
+	v1alpha1.AggregatorMax,
+}
+
+type usageQuery struct {
+	metricName string
+	resource   v1.ResourceName
+	nodeExpr   string
+	podExpr    string
+}
+
+var usageQueries = []usageQuery{
+	{
+		metricName: consts.NPDMetricNameCPUUsage,
+		resource:   v1.ResourceCPU,
+		nodeExpr:   NodeCPUUsageQueryExpr,
+		podExpr:    PodCPUUsageQueryExpr,
+	},
+	{
+		metricName: consts.NPDMetricNameMemoryUsage,
+		resource:   v1.ResourceMemory,
+		nodeExpr:   NodeMemUsageQueryExpr,
+		podExpr:    PodMemUsageQueryExpr,
+	},
+}
+
+// windowMetrics are the usage metrics aggregated over one window
+type windowMetrics struct {
+	refreshTime time.Time
+	// nodeMetrics maps from node name to its metrics
+	nodeMetrics map[string][]v1alpha1.MetricValue
+	// podMetrics maps from node name to the metrics of pods on it, keyed by pod namespace/name
+	podMetrics map[string]map[string]*v1alpha1.PodMetric
+}
+
+// usageMetricsPlugin fills node and pod usage metrics into npd, the usage is
+// aggregated by prometheus over each configured window. Since the subqueries
+// over long windows are expensive, each window is refreshed at an interval
+// proportional to its length, and the metrics of the others are kept.
+// Consumers should scale the expiration of metrics by their windows accordingly.
+type usageMetricsPlugin struct {
+	ctx     context.Context
+	conf    *controller.UsageMetricsPluginConfig
+	updater metrics_plugin.MetricsUpdater
+
+	promClient   promapiv1.API
+	extraFilters string
+
+	// nodeLister is used to drop metrics of deleted nodes, since prometheus
+	// keeps returning them until they are out of the windows.
+	nodeLister corelisters.NodeLister
+	nodeSynced cache.InformerSynced
+
+	// windows caches the latest metrics of each window, and it's only accessed by sync
+	windows map[time.Duration]*windowMetrics
+}
+
+var _ metrics_plugin.MetricsPlugin = &usageMetricsPlugin{}
+
+func NewUsageMetricsPlugin(ctx context.Context, conf *controller.NPDConfig, _ interface{},
+	controlCtx *katalystbase.GenericContext, updater metrics_plugin.MetricsUpdater,
+) (metrics_plugin.MetricsPlugin, error) {
+	if conf.UsageMetricsPluginConfig == nil || len(conf.Windows) == 0 {
+		return nil, fmt.Errorf("usage metrics plugin config with windows is required")
+	}
+
+	promDatasource, err := prometheus.NewPrometheus(&conf.DataSourcePromConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create prometheus datasource: %v", err)
+	}
+
+	nodeInformer := controlCtx.KubeInformerFactory.Core().V1().Nodes()
+	p := newUsageMetricsPlugin(ctx, conf.UsageMetricsPluginConfig, promDatasource.GetPromClient(),
+		nodeInformer.Lister(), updater)
+	p.nodeSynced = nodeInformer.Informer().HasSynced
+	return p, nil
+}
+
+func newUsageMetricsPlugin(ctx context.Context, conf *controller.UsageMetricsPluginConfig,
+	promClient promapiv1.API, nodeLister corelisters.NodeLister, updater metrics_plugin.MetricsUpdater,
+) *usageMetricsPlugin {
+	extraFilters := ""
+	if conf.DataSourcePromConfig.BaseFilter != "" {
+		extraFilters = "," + conf.DataSourcePromConfig.BaseFilter
+	}
+
+	return &usageMetricsPlugin{
+		ctx:          ctx,
+		conf:         conf,
+		updater:      updater,
+		promClient:   promClient,
+		extraFilters: extraFilters,
+		nodeLister:   nodeLister,
+		nodeSynced:   func() bool { return true },
+		windows:      make(map[time.Duration]*windowMetrics),
+	}
+}
+
+func (p *usageMetricsPlugin) Run() {
+	if !cache.WaitForCacheSync(p.ctx.Done(), p.nodeSynced) {
+		klog.Errorf("[npd] usage metrics plugin failed to sync nodes")
+		return
+	}
+	wait.UntilWithContext(p.ctx, p.sync, p.conf.SyncPeriod)
+}
+
+func (p *usageMetricsPlugin) Name() string {
+	return PluginName
+}
+
+func (p *usageMetricsPlugin) GetSupportedNodeMetricsScope() []string {
+	return []string{consts.NPDScopeLoadAware}
+}
+
+func (p *usageMetricsPlugin) GetSupportedPodMetricsScope() []string {
+	return []string{consts.NPDScopeLoadAware}
+}
+
+func (p *usageMetricsPlugin) sync(ctx context.Context) {
+	now := time.Now()
+	for _, window := range p.conf.Windows {
+		if cached, ok := p.windows[window]; ok && now.Sub(cached.refreshTime) < p.getRefreshPeriod(window) {
+			continue
+		}
+
+		// the previous metrics are kept if query fails, and it will be retried in the next sync
+		result, err := p.queryWindow(ctx, window, now)
+		if err != nil {
+			klog.Errorf("[npd] query usage over %v failed, keep the previous metrics: %v", window, err)
+			continue
+		}
+		p.windows[window] = result
+	}
+	p.dropDeletedNodes()
+
+	// merge the metrics of all windows, since metrics of each scope are updated as a whole
+	nodeMetrics := make(map[string][]v1alpha1.MetricValue)
+	podMetrics := make(map[string]map[string]*v1alpha1.PodMetric)
+	for _, window := range p.conf.Windows {
+		cached, ok := p.windows[window]
+		if !ok {
+			continue
+		}
+		for nodeName, metrics := range cached.nodeMetrics {
+			nodeMetrics[nodeName] = append(nodeMetrics[nodeName], metrics...)
+		}
+		for nodeName, pods := range cached.podMetrics {
+			if _, ok := podMetrics[nodeName]; !ok {
+				podMetrics[nodeName] = make(map[string]*v1alpha1.PodMetric)
+			}
+			for key, podMetric := range pods {
+				if _, ok := podMetrics[nodeName][key]; !ok {
+					podMetrics[nodeName][key] = &v1alpha1.PodMetric{Namespace: podMetric.Namespace, Name: podMetric.Name}
+				}
+				podMetrics[nodeName][key].Metrics = append(podMetrics[nodeName][key].Metrics, podMetric.Metrics...)
+			}
+		}
+	}
+
+	for nodeName, metrics := range nodeMetrics {
+		p.updater.UpdateNodeMetrics(nodeName, []v1alpha1.ScopedNodeMetrics{
+			{
+				Scope:   consts.NPDScopeLoadAware,
+				Metrics: metrics,
+			},
+		})
+	}
+
+	for nodeName, pods := range podMetrics {
+		metrics := make([]v1alpha1.PodMetric, 0, len(pods))
+		for _, podMetric := range pods {
+			metrics = append(metrics, *podMetric)
+		}
+		sort.Slice(metrics, func(i, j int) bool {
+			if metrics[i].Namespace != metrics[j].Namespace {
+				return metrics[i].Namespace < metrics[j].Namespace
+			}
+			return metrics[i].Name < metrics[j].Name
+		})
+
+		p.updater.UpdatePodMetrics(nodeName, []v1alpha1.ScopedPodMetrics{
+			{
+				Scope:      consts.NPDScopeLoadAware,
+				PodMetrics: metrics,
+			},
+		})
+	}
+	klog.V(4).Infof("[npd] usage metrics of %v nodes updated", len(nodeMetrics))
+}
+
+// dropDeletedNodes removes the cached metrics of nodes that have been deleted,
+// otherwise they will be filled into npd again after it's deleted along with nodes.
+func (p *usageMetricsPlugin) dropDeletedNodes() {
+	isDeleted := func(nodeName string) bool {
+		_, err := p.nodeLister.Get(nodeName)
+		return errors.IsNotFound(err)
+	}
+
+	for _, cached := range p.windows {
+		for nodeName := range cached.nodeMetrics {
+			if isDeleted(nodeName) {
+				delete(cached.nodeMetrics, nodeName)
+			}
+		}
+		for nodeName := range cached.podMetrics {
+			if isDeleted(nodeName) {
+				delete(cached.podMetrics, nodeName)
+			}
+		}
+	}
+}
+
+// getRefreshPeriod returns the interval to refresh the metrics aggregated over window
+func (p *usageMetricsPlugin) getRefreshPeriod(window time.Duration) time.Duration {
+	period := time.Duration(float64(window) * p.conf.WindowRefreshRatio)
+	if period < p.conf.SyncPeriod {
+		return p.conf.SyncPeriod
+	}
+	return period
+}
+
+// queryWindow queries the node (and pod if enabled) usage metrics aggregated over window,
+// and partial results are never returned since they would hide the usage of the window.
+func (p *usageMetricsPlugin) queryWindow(ctx context.Context, window time.Duration, now time.Time) (*windowMetrics, error) {
+	result := &windowMetrics{
+		refreshTime: now,
+		nodeMetrics: make(map[string][]v1alpha1.MetricValue),
+		podMetrics:  make(map[string]map[string]*v1alpha1.PodMetric),
+	}
+
+	for _, aggregator := range Aggregators {
+		for _, q := range usageQueries {
+			nodeSamples, err := p.query(ctx, fmt.Sprintf(q.nodeExpr, p.conf.NodeLabel, p.extraFilters), aggregator, window, now)
+			if err != nil {
+				return nil, fmt.Errorf("query node %v %v: %v", q.metricName, aggregator, err)
+			}
+			for _, sample := range nodeSamples {
+				nodeName := string(sample.Metric[model.LabelName(p.conf.NodeLabel)])
+				if nodeName == "" {
+					continue
+				}
+				result.nodeMetrics[nodeName] = append(result.nodeMetrics[nodeName], newMetricValue(q, aggregator, window, now, sample))
+			}
+
+			if !p.conf.EnablePodMetrics {
+				continue
+			}
+
+			podSamples, err := p.query(ctx, fmt.Sprintf(q.podExpr, p.conf.NodeLabel, p.extraFilters), aggregator, window, now)
+			if err != nil {
+				return nil, fmt.Errorf("query pod %v %v: %v", q.metricName, aggregator, err)
+			}
+			for _, sample := range podSamples {
+				nodeName := string(sample.Metric[model.LabelName(p.conf.NodeLabel)])
+				namespace := string(sample.Metric[podLabelNamespace])
+				podName := string(sample.Metric[podLabelPod])
+				if nodeName == "" || podName == "" {
+					continue
+				}
+
+				if _, ok := result.podMetrics[nodeName]; !ok {
+					result.podMetrics[nodeName] = make(map[string]*v1alpha1.PodMetric)
+				}
+				key := native.GenerateNamespaceNameKey(namespace, podName)
+				if _, ok := result.podMetrics[nodeName][key]; !ok {
+					result.podMetrics[nodeName][key] = &v1alpha1.PodMetric{Namespace: namespace, Name: podName}
+				}
+				result.podMetrics[nodeName][key].Metrics = append(result.podMetrics[nodeName][key].Metrics,
+					newMetricValue(q, aggregator, window, now, sample))
+			}
+		}
+	}
+	return result, nil
+}
+
+// query aggregates the usage expression over window by subquery, and returns
+// the instant vector at the given time.
+func (p *usageMetricsPlugin) query(ctx context.Context, expr string, aggregator v1alpha1.Aggregator,
+	window time.Duration, ts time.Time,
+) (model.Vector, error) {
+	query, err := buildAggregatedQuery(expr, aggregator, window, p.conf.Step)
+	if err != nil {
+		return nil, err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, p.conf.DataSourcePromConfig.Timeout)
+	defer cancel()
+
+	result, warnings, err := p.promClient.Query(timeoutCtx, query, ts)
+	if len(warnings) != 0 {
+		klog.Warningf("[npd] query %v warnings: %v", query, warnings)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	vector, ok := result.(model.Vector)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type %v of query %v", result.Type(), query)
+	}
+	return vector, nil
+}
+
+func buildAggregatedQuery(expr string, aggregator v1alpha1.Aggregator, window, step time.Duration) (string, error) {
+	subquery := fmt.Sprintf("(%s)[%s:%s]", expr, model.Duration(window), model.Duration(step))
+	switch aggregator {
+	case v1alpha1.AggregatorAvg:
+		return fmt.Sprintf("avg_over_time(%s)", subquery), nil
+	case v1alpha1.AggregatorMax:
+		return fmt.Sprintf("max_over_time(%s)", subquery), nil
+	case v1alpha1.AggregatorMin:
+		return fmt.Sprintf("min_over_time(%s)", subquery), nil
+	case v1alpha1.AggregatorP99:
+		return fmt.Sprintf("quantile_over_time(0.99, %s)", subquery), nil
+	case v1alpha1.AggregatorP95:
+		return fmt.Sprintf("quantile_over_time(0.95, %s)", subquery), nil
+	case v1alpha1.AggregatorP90:
+		return fmt.Sprintf("quantile_over_time(0.9, %s)", subquery), nil
+	default:
+		return "", fmt.Errorf("unsupported aggregator %v", aggregator)
+	}
+}
+
+func newMetricValue(q usageQuery, aggregator v1alpha1.Aggregator, window time.Duration, ts time.Time,
+	sample *model.Sample,
+) v1alpha1.MetricValue {
+	var value *resource.Quantity
+	if q.resource == v1.ResourceCPU {
+		value = resource.NewMilliQuantity(int64(float64(sample.Value)*1000), resource.DecimalSI)
+	} else {
+		value = resource.NewQuantity(int64(sample.Value), resource.BinarySI)
+	}
+
+	agg := aggregator
+	return v1alpha1.MetricValue{
+		MetricName: q.metricName,
+		Timestamp:  metav1.NewTime(ts),
+		Aggregator: &agg,
+		Window:     &metav1.Duration{Duration: window},
+		Value:      *value,
+	}
+}
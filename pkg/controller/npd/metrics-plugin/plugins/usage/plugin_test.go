@@ -0,0 +1,308 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	promapiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	metrics_plugin "github.com/kubewharf/katalyst-core/pkg/controller/npd/metrics-plugin"
+	datasourceprometheus "github.com/kubewharf/katalyst-core/pkg/util/datasource/prometheus"
+)
+
+func newTestNodeLister(names ...string) corelisters.NodeLister {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, name := range names {
+		_ = indexer.Add(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+	return corelisters.NewNodeLister(indexer)
+}
+
+func TestBuildAggregatedQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		aggregator v1alpha1.Aggregator
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "avg",
+			aggregator: v1alpha1.AggregatorAvg,
+			want:       "avg_over_time((up)[1h:1m])",
+		},
+		{
+			name:       "p95",
+			aggregator: v1alpha1.AggregatorP95,
+			want:       "quantile_over_time(0.95, (up)[1h:1m])",
+		},
+		{
+			name:       "max",
+			aggregator: v1alpha1.AggregatorMax,
+			want:       "max_over_time((up)[1h:1m])",
+		},
+		{
+			name:       "count",
+			aggregator: v1alpha1.AggregatorCount,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := buildAggregatedQuery("up", tt.aggregator, time.Hour, time.Minute)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUsageMetricsPluginSync(t *testing.T) {
+	t.Parallel()
+
+	conf := &controller.UsageMetricsPluginConfig{
+		DataSourcePromConfig: datasourceprometheus.PromConfig{
+			Timeout:    time.Second,
+			BaseFilter: `cluster="test"`,
+		},
+		SyncPeriod:       time.Minute,
+		Windows:          []time.Duration{5 * time.Minute},
+		Step:             time.Minute,
+		EnablePodMetrics: true,
+		NodeLabel:        "node",
+	}
+
+	var queries []string
+	promClient := &datasourceprometheus.MockPromAPIClient{
+		QueryFunc: func(ctx context.Context, query string, ts time.Time) (model.Value, promapiv1.Warnings, error) {
+			queries = append(queries, query)
+
+			value := model.SampleValue(1.5)
+			if strings.Contains(query, "memory") {
+				value = 1024 * 1024 * 1024
+			}
+			if strings.Contains(query, "namespace, pod") {
+				return model.Vector{
+					{Metric: model.Metric{"node": "n1", "namespace": "default", "pod": "p2"}, Value: value / 2},
+					{Metric: model.Metric{"node": "n1", "namespace": "default", "pod": "p1"}, Value: value / 2},
+				}, nil, nil
+			}
+			return model.Vector{
+				{Metric: model.Metric{"node": "n1"}, Value: value},
+				{Metric: model.Metric{}, Value: value},
+			}, nil, nil
+		},
+	}
+
+	manager := metrics_plugin.NewMetricsManager()
+	p := newUsageMetricsPlugin(context.TODO(), conf, promClient, newTestNodeLister("n1"), manager)
+	p.sync(context.TODO())
+
+	// 3 aggregators * 2 resources * (node + pod)
+	assert.Equal(t, 12, len(queries))
+	for _, query := range queries {
+		assert.Contains(t, query, `cluster="test"`)
+	}
+
+	status := manager.GetNodeProfileStatus("n1")
+	assert.NotNil(t, status)
+
+	assert.Equal(t, 1, len(status.NodeMetrics))
+	assert.Equal(t, consts.NPDScopeLoadAware, status.NodeMetrics[0].Scope)
+	assert.Equal(t, 6, len(status.NodeMetrics[0].Metrics))
+	for _, metric := range status.NodeMetrics[0].Metrics {
+		assert.Equal(t, 5*time.Minute, metric.Window.Duration)
+		switch metric.MetricName {
+		case consts.NPDMetricNameCPUUsage:
+			assert.True(t, metric.Value.Equal(resource.MustParse("1500m")))
+		case consts.NPDMetricNameMemoryUsage:
+			assert.True(t, metric.Value.Equal(resource.MustParse("1Gi")))
+		default:
+			t.Errorf("unexpected metric %v", metric.MetricName)
+		}
+	}
+
+	assert.Equal(t, 1, len(status.PodMetrics))
+	assert.Equal(t, 2, len(status.PodMetrics[0].PodMetrics))
+	assert.Equal(t, "p1", status.PodMetrics[0].PodMetrics[0].Name)
+	assert.Equal(t, "p2", status.PodMetrics[0].PodMetrics[1].Name)
+	assert.Equal(t, 6, len(status.PodMetrics[0].PodMetrics[0].Metrics))
+}
+
+func TestUsageMetricsPluginSyncRefresh(t *testing.T) {
+	t.Parallel()
+
+	conf := &controller.UsageMetricsPluginConfig{
+		SyncPeriod:         time.Minute,
+		Windows:            []time.Duration{5 * time.Minute, 24 * time.Hour},
+		WindowRefreshRatio: 0.1,
+		Step:               time.Minute,
+		NodeLabel:          "node",
+	}
+
+	queries := make(map[string]int)
+	promClient := &datasourceprometheus.MockPromAPIClient{
+		QueryFunc: func(ctx context.Context, query string, ts time.Time) (model.Value, promapiv1.Warnings, error) {
+			assert.NotContains(t, query, "namespace, pod")
+			if strings.Contains(query, "[1d:") {
+				queries["24h"]++
+			} else {
+				queries["5m"]++
+			}
+			return model.Vector{
+				{Metric: model.Metric{"node": "n1"}, Value: 1},
+			}, nil, nil
+		},
+	}
+
+	manager := metrics_plugin.NewMetricsManager()
+	p := newUsageMetricsPlugin(context.TODO(), conf, promClient, newTestNodeLister("n1"), manager)
+	p.sync(context.TODO())
+	assert.Equal(t, map[string]int{"5m": 6, "24h": 6}, queries)
+
+	// only the short window is refreshed after a sync period
+	for _, cached := range p.windows {
+		cached.refreshTime = cached.refreshTime.Add(-2 * time.Minute)
+	}
+	p.sync(context.TODO())
+	assert.Equal(t, map[string]int{"5m": 12, "24h": 6}, queries)
+
+	status := manager.GetNodeProfileStatus("n1")
+	assert.NotNil(t, status)
+	assert.Equal(t, 1, len(status.NodeMetrics))
+	assert.Equal(t, 12, len(status.NodeMetrics[0].Metrics))
+	assert.Equal(t, 0, len(status.PodMetrics))
+
+	assert.Equal(t, time.Minute, p.getRefreshPeriod(5*time.Minute))
+	assert.Equal(t, 144*time.Minute, p.getRefreshPeriod(24*time.Hour))
+}
+
+func TestUsageMetricsPluginSyncFailure(t *testing.T) {
+	t.Parallel()
+
+	conf := &controller.UsageMetricsPluginConfig{
+		SyncPeriod:         time.Minute,
+		Windows:            []time.Duration{5 * time.Minute},
+		WindowRefreshRatio: 0.1,
+		Step:               time.Minute,
+		NodeLabel:          "node",
+	}
+
+	var failed bool
+	promClient := &datasourceprometheus.MockPromAPIClient{
+		QueryFunc: func(ctx context.Context, query string, ts time.Time) (model.Value, promapiv1.Warnings, error) {
+			if failed {
+				return nil, nil, fmt.Errorf("prometheus unavailable")
+			}
+			return model.Vector{
+				{Metric: model.Metric{"node": "n1"}, Value: 1},
+			}, nil, nil
+		},
+	}
+
+	manager := metrics_plugin.NewMetricsManager()
+	p := newUsageMetricsPlugin(context.TODO(), conf, promClient, newTestNodeLister("n1"), manager)
+	p.sync(context.TODO())
+	refreshTime := p.windows[5*time.Minute].refreshTime
+
+	// the previous metrics are kept and the window is retried in the next sync if query fails
+	failed = true
+	p.windows[5*time.Minute].refreshTime = refreshTime.Add(-2 * time.Minute)
+	p.sync(context.TODO())
+	assert.Equal(t, refreshTime.Add(-2*time.Minute), p.windows[5*time.Minute].refreshTime)
+	assert.Equal(t, 6, len(manager.GetNodeProfileStatus("n1").NodeMetrics[0].Metrics))
+
+	failed = false
+	p.sync(context.TODO())
+	assert.True(t, p.windows[5*time.Minute].refreshTime.After(refreshTime))
+}
+
+func TestUsageMetricsPluginDropDeletedNodes(t *testing.T) {
+	t.Parallel()
+
+	conf := &controller.UsageMetricsPluginConfig{
+		SyncPeriod:         time.Minute,
+		Windows:            []time.Duration{5 * time.Minute, 24 * time.Hour},
+		WindowRefreshRatio: 0.1,
+		Step:               time.Minute,
+		EnablePodMetrics:   true,
+		NodeLabel:          "node",
+	}
+
+	promClient := &datasourceprometheus.MockPromAPIClient{
+		QueryFunc: func(ctx context.Context, query string, ts time.Time) (model.Value, promapiv1.Warnings, error) {
+			if strings.Contains(query, "namespace, pod") {
+				return model.Vector{
+					{Metric: model.Metric{"node": "n1", "namespace": "default", "pod": "p1"}, Value: 1},
+					{Metric: model.Metric{"node": "n2", "namespace": "default", "pod": "p2"}, Value: 1},
+				}, nil, nil
+			}
+			return model.Vector{
+				{Metric: model.Metric{"node": "n1"}, Value: 1},
+				{Metric: model.Metric{"node": "n2"}, Value: 1},
+			}, nil, nil
+		},
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	n1 := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "n1"}}
+	n2 := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "n2"}}
+	_ = indexer.Add(n1)
+	_ = indexer.Add(n2)
+
+	manager := metrics_plugin.NewMetricsManager()
+	p := newUsageMetricsPlugin(context.TODO(), conf, promClient, corelisters.NewNodeLister(indexer), manager)
+	p.sync(context.TODO())
+	assert.NotNil(t, manager.GetNodeProfileStatus("n2"))
+
+	// metrics of the deleted node are dropped from all windows, even if prometheus still returns them
+	_ = indexer.Delete(n2)
+	manager.DeleteNodeProfileStatus("n2")
+	p.windows[5*time.Minute].refreshTime = p.windows[5*time.Minute].refreshTime.Add(-2 * time.Minute)
+	p.sync(context.TODO())
+
+	for window, cached := range p.windows {
+		assert.NotContains(t, cached.nodeMetrics, "n2", window)
+		assert.NotContains(t, cached.podMetrics, "n2", window)
+		assert.Contains(t, cached.nodeMetrics, "n1", window)
+	}
+	assert.Nil(t, manager.GetNodeProfileStatus("n2"))
+	assert.NotNil(t, manager.GetNodeProfileStatus("n1"))
+}
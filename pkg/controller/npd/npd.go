@@ -38,6 +38,7 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/config/controller"
 	"github.com/kubewharf/katalyst-core/pkg/config/generic"
 	metrics_plugin "github.com/kubewharf/katalyst-core/pkg/controller/npd/metrics-plugin"
+	_ "github.com/kubewharf/katalyst-core/pkg/controller/npd/metrics-plugin/plugins"
 	"github.com/kubewharf/katalyst-core/pkg/metrics"
 )
 
@@ -77,6 +77,8 @@ type NodeOvercommitController struct {
 	reconcilePeriod time.Duration
 	firstReconcile  bool
 
+	recommendationConf controller.OvercommitRecommendationConfig
+
 	metricsEmitter metrics.MetricEmitter
 }
 
@@ -120,8 +122,9 @@ func NewNodeOvercommitController(
 			nodeOvercommitInformer.Informer().HasSynced,
 			cnrInformer.Informer().HasSynced,
 		},
-		matcher:         &matcher.DummyMatcher{},
-		reconcilePeriod: overcommitConf.Node.ConfigReconcilePeriod,
+		matcher:            &matcher.DummyMatcher{},
+		reconcilePeriod:    overcommitConf.Node.ConfigReconcilePeriod,
+		recommendationConf: overcommitConf.Node.Recommendation,
 	}
 
 	nodeOvercommitConfigController.metricsEmitter = controlCtx.EmitterPool.GetDefaultMetricsEmitter()
@@ -189,6 +192,10 @@ func (nc *NodeOvercommitController) Run() {
 
 	nc.reconcile()
 
+	if nc.recommendationConf.Enabled {
+		go wait.Until(nc.recommend, nc.recommendationConf.SyncPeriod, nc.ctx.Done())
+	}
+
 	<-nc.ctx.Done()
 }
 
@@ -0,0 +1,263 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	configv1alpha1 "github.com/kubewharf/katalyst-api/pkg/apis/overcommit/v1alpha1"
+	"github.com/kubewharf/katalyst-api/pkg/consts"
+	katalystconsts "github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const metricsNameRecommendedOvercommitRatio = "noc_recommended_overcommit_ratio"
+
+var defaultOvercommitRatio = map[corev1.ResourceName]string{
+	corev1.ResourceCPU:    consts.DefaultNodeCPUOvercommitRatio,
+	corev1.ResourceMemory: consts.DefaultNodeMemoryOvercommitRatio,
+}
+
+// overcommitRatioSample is the percentile of realtime overcommit ratios of matched nodes at Timestamp
+type overcommitRatioSample struct {
+	Timestamp int64   `json:"t"`
+	Ratio     float64 `json:"v"`
+}
+
+// recommend updates overcommit ratio recommendations of all configs
+func (nc *NodeOvercommitController) recommend() {
+	configList, err := nc.nodeOvercommitLister.List(labels.Everything())
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+
+	now := time.Now()
+	for _, config := range configList {
+		if err := nc.syncRecommendation(config.Name, now); err != nil {
+			klog.Errorf("%s controller sync recommendation of noc %s fail: %v", nodeOvercommitControllerName, config.Name, err)
+		}
+	}
+}
+
+// syncRecommendation samples realtime overcommit ratios of nodes matched by the config, and recommends
+// the min sample in history window as the pool-level overcommit ratio of each resource;
+// the recommendation is applied to config spec gradually if auto-apply is enabled.
+func (nc *NodeOvercommitController) syncRecommendation(configName string, now time.Time) error {
+	config, err := nc.nodeOvercommitLister.Get(configName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	realtimeRatios := nc.getRealtimeOvercommitRatios(nc.matcher.GetNodes(configName))
+	history := getOvercommitRatioHistory(config)
+	recommended := make(map[corev1.ResourceName]string)
+	newConfig := config.DeepCopy()
+	autoApplied := false
+	for resourceName := range resourceAnnotationKey {
+		samples := pruneOvercommitRatioSamples(history[resourceName], now.Add(-nc.recommendationConf.HistoryWindow))
+		sampled := false
+		if ratios := realtimeRatios[resourceName]; len(ratios) > 0 && len(ratios) >= nc.recommendationConf.MinNodes {
+			samples = append(samples, overcommitRatioSample{
+				Timestamp: now.Unix(),
+				Ratio:     getPercentile(ratios, nc.recommendationConf.Percentile),
+			})
+			sampled = true
+		}
+
+		if len(samples) == 0 {
+			delete(history, resourceName)
+			continue
+		}
+		history[resourceName] = samples
+
+		ratio := getRecommendedOvercommitRatio(samples)
+		recommended[resourceName] = strconv.FormatFloat(ratio, 'f', -1, 64)
+		_ = nc.metricsEmitter.StoreFloat64(metricsNameRecommendedOvercommitRatio, ratio, metrics.MetricTypeNameRaw,
+			metrics.MetricTag{Key: "config", Val: configName},
+			metrics.MetricTag{Key: "resource", Val: string(resourceName)})
+
+		// only fresh recommendations are applied, in case realtime ratios of nodes are missing
+		if !sampled || config.Annotations[katalystconsts.NocAnnotationAutoApplyRecommendationKey] != "true" {
+			continue
+		}
+		if applied, ok := nc.getAutoApplyOvercommitRatio(config, resourceName, ratio, now); ok {
+			klog.Infof("%s controller auto-apply %s overcommit ratio %v to noc %s",
+				nodeOvercommitControllerName, resourceName, applied, configName)
+			if newConfig.Spec.ResourceOvercommitRatio == nil {
+				newConfig.Spec.ResourceOvercommitRatio = make(map[corev1.ResourceName]string)
+			}
+			newConfig.Spec.ResourceOvercommitRatio[resourceName] = strconv.FormatFloat(applied, 'f', -1, 64)
+			autoApplied = true
+		}
+	}
+
+	if newConfig.Annotations == nil {
+		newConfig.Annotations = make(map[string]string)
+	}
+	if err := setJSONAnnotation(newConfig.Annotations, katalystconsts.NocAnnotationOvercommitRatioHistoryKey, history); err != nil {
+		return err
+	}
+	if err := setJSONAnnotation(newConfig.Annotations, katalystconsts.NocAnnotationRecommendedOvercommitRatioKey, recommended); err != nil {
+		return err
+	}
+	if autoApplied {
+		newConfig.Annotations[katalystconsts.NocAnnotationLastAutoApplyTimeKey] = now.Format(time.RFC3339)
+	}
+
+	if reflect.DeepEqual(config.Annotations, newConfig.Annotations) && reflect.DeepEqual(config.Spec, newConfig.Spec) {
+		return nil
+	}
+	_, err = nc.nocUpdater.PatchNoc(nc.ctx, config, newConfig)
+	return err
+}
+
+// getRealtimeOvercommitRatios returns realtime overcommit ratios of each resource reported by nodes in cnr
+func (nc *NodeOvercommitController) getRealtimeOvercommitRatios(nodeNames []string) map[corev1.ResourceName][]float64 {
+	ratios := make(map[corev1.ResourceName][]float64)
+	for _, nodeName := range nodeNames {
+		kcnr, err := nc.cnrLister.Get(nodeName)
+		if err != nil {
+			klog.V(5).Infof("get cnr %s fail: %v", nodeName, err)
+			continue
+		}
+
+		for resourceName, annotationKey := range resourceAnnotationKey {
+			value, ok := kcnr.Annotations[annotationKey]
+			if !ok {
+				continue
+			}
+
+			ratio, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				klog.Errorf("node %s realtime %s overcommit ratio %s invalid: %v", nodeName, resourceName, value, err)
+				continue
+			}
+			ratios[resourceName] = append(ratios[resourceName], ratio)
+		}
+	}
+	return ratios
+}
+
+// getAutoApplyOvercommitRatio returns the overcommit ratio to apply for the recommendation, guarded by:
+// decreases are applied at once, while increases are limited by max step, min interval and max ratio.
+func (nc *NodeOvercommitController) getAutoApplyOvercommitRatio(config *configv1alpha1.NodeOvercommitConfig,
+	resourceName corev1.ResourceName, recommended float64, now time.Time,
+) (float64, bool) {
+	value, ok := config.Spec.ResourceOvercommitRatio[resourceName]
+	if !ok {
+		value = defaultOvercommitRatio[resourceName]
+	}
+	current, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		klog.Errorf("noc %s %s overcommit ratio %s invalid: %v", config.Name, resourceName, value, err)
+		return 0, false
+	}
+
+	if recommended < current {
+		return recommended, true
+	}
+
+	if lastApplyTime, err := time.Parse(time.RFC3339, config.Annotations[katalystconsts.NocAnnotationLastAutoApplyTimeKey]); err == nil &&
+		now.Sub(lastApplyTime) < nc.recommendationConf.AutoApplyInterval {
+		return 0, false
+	}
+
+	target := math.Min(recommended, current+nc.recommendationConf.AutoApplyMaxStep)
+	target = math.Min(target, nc.recommendationConf.AutoApplyMaxRatio)
+	target = roundOvercommitRatio(target)
+	if target <= current {
+		return 0, false
+	}
+	return target, true
+}
+
+func getOvercommitRatioHistory(config *configv1alpha1.NodeOvercommitConfig) map[corev1.ResourceName][]overcommitRatioSample {
+	history := make(map[corev1.ResourceName][]overcommitRatioSample)
+	value, ok := config.Annotations[katalystconsts.NocAnnotationOvercommitRatioHistoryKey]
+	if !ok {
+		return history
+	}
+
+	if err := json.Unmarshal([]byte(value), &history); err != nil {
+		klog.Errorf("noc %s overcommit ratio history invalid: %v", config.Name, err)
+		return make(map[corev1.ResourceName][]overcommitRatioSample)
+	}
+	return history
+}
+
+// pruneOvercommitRatioSamples removes samples taken before since
+func pruneOvercommitRatioSamples(samples []overcommitRatioSample, since time.Time) []overcommitRatioSample {
+	pruned := make([]overcommitRatioSample, 0, len(samples)+1)
+	for _, sample := range samples {
+		if sample.Timestamp >= since.Unix() {
+			pruned = append(pruned, sample)
+		}
+	}
+	return pruned
+}
+
+// getRecommendedOvercommitRatio returns the min sample, which is no less than 1
+func getRecommendedOvercommitRatio(samples []overcommitRatioSample) float64 {
+	ratio := math.MaxFloat64
+	for _, sample := range samples {
+		ratio = math.Min(ratio, sample.Ratio)
+	}
+	return math.Max(roundOvercommitRatio(ratio), 1)
+}
+
+// getPercentile returns the nearest-rank percentile of values
+func getPercentile(values []float64, percentile float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	index := int(math.Ceil(percentile/100*float64(len(sorted)))) - 1
+	if index < 0 {
+		index = 0
+	} else if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+	return sorted[index]
+}
+
+// roundOvercommitRatio rounds down overcommit ratio to 2 decimal places to be conservative
+func roundOvercommitRatio(ratio float64) float64 {
+	return math.Floor(ratio*100+1e-9) / 100
+}
+
+func setJSONAnnotation(annotations map[string]string, key string, v interface{}) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	annotations[key] = string(value)
+	return nil
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+	cliflag "k8s.io/component-base/cli/flag"
+
+	nodev1alpha1 "github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	"github.com/kubewharf/katalyst-api/pkg/consts"
+	katalyst_base "github.com/kubewharf/katalyst-core/cmd/base"
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-controller/app/options"
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	katalystconsts "github.com/kubewharf/katalyst-core/pkg/consts"
+)
+
+func makeRealtimeCNR(name, cpuOvercommitRatio, memoryOvercommitRatio string) *nodev1alpha1.CustomNodeResource {
+	return &nodev1alpha1.CustomNodeResource{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{
+		consts.NodeAnnotationCPUOvercommitRatioKey:    cpuOvercommitRatio,
+		consts.NodeAnnotationMemoryOvercommitRatioKey: memoryOvercommitRatio,
+	}}}
+}
+
+func TestGetPercentile(t *testing.T) {
+	t.Parallel()
+
+	values := []float64{3, 1.2, 2, 1.5}
+	assert.Equal(t, 1.2, getPercentile(values, 0))
+	assert.Equal(t, 1.2, getPercentile(values, 10))
+	assert.Equal(t, 1.5, getPercentile(values, 50))
+	assert.Equal(t, 3.0, getPercentile(values, 100))
+	assert.Equal(t, []float64{3, 1.2, 2, 1.5}, values, "values should not be sorted in place")
+
+	assert.Equal(t, 1.0, getRecommendedOvercommitRatio([]overcommitRatioSample{{Ratio: 0.8}, {Ratio: 1.5}}))
+	assert.Equal(t, 1.33, getRecommendedOvercommitRatio([]overcommitRatioSample{{Ratio: 1.339}, {Ratio: 1.5}}))
+}
+
+func TestSyncRecommendation(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	historyAnnotation := func(samples map[corev1.ResourceName][]overcommitRatioSample) string {
+		data, _ := json.Marshal(samples)
+		return string(data)
+	}
+
+	testCases := []struct {
+		name            string
+		annotations     map[string]string
+		selectorVal     string
+		wantRecommended map[corev1.ResourceName]string
+		wantSpec        map[corev1.ResourceName]string
+		wantApplied     bool
+	}{
+		{
+			name:            "recommend without auto-apply",
+			selectorVal:     "pool1",
+			wantRecommended: map[corev1.ResourceName]string{corev1.ResourceCPU: "1.5", corev1.ResourceMemory: "1.3"},
+			wantSpec:        map[corev1.ResourceName]string{corev1.ResourceCPU: "1", corev1.ResourceMemory: "2"},
+		},
+		{
+			name:        "recommend the min sample in history window",
+			selectorVal: "pool1",
+			annotations: map[string]string{
+				katalystconsts.NocAnnotationOvercommitRatioHistoryKey: historyAnnotation(map[corev1.ResourceName][]overcommitRatioSample{
+					corev1.ResourceCPU: {
+						{Timestamp: now.Add(-48 * time.Hour).Unix(), Ratio: 1},
+						{Timestamp: now.Add(-time.Hour).Unix(), Ratio: 1.4},
+					},
+				}),
+			},
+			wantRecommended: map[corev1.ResourceName]string{corev1.ResourceCPU: "1.4", corev1.ResourceMemory: "1.3"},
+			wantSpec:        map[corev1.ResourceName]string{corev1.ResourceCPU: "1", corev1.ResourceMemory: "2"},
+		},
+		{
+			name:        "auto-apply increases by step and decreases at once",
+			selectorVal: "pool1",
+			annotations: map[string]string{
+				katalystconsts.NocAnnotationAutoApplyRecommendationKey: "true",
+			},
+			wantRecommended: map[corev1.ResourceName]string{corev1.ResourceCPU: "1.5", corev1.ResourceMemory: "1.3"},
+			wantSpec:        map[corev1.ResourceName]string{corev1.ResourceCPU: "1.1", corev1.ResourceMemory: "1.3"},
+			wantApplied:     true,
+		},
+		{
+			name:        "auto-apply waits for interval to increase",
+			selectorVal: "pool1",
+			annotations: map[string]string{
+				katalystconsts.NocAnnotationAutoApplyRecommendationKey: "true",
+				katalystconsts.NocAnnotationLastAutoApplyTimeKey:       now.Add(-10 * time.Minute).Format(time.RFC3339),
+			},
+			wantRecommended: map[corev1.ResourceName]string{corev1.ResourceCPU: "1.5", corev1.ResourceMemory: "1.3"},
+			wantSpec:        map[corev1.ResourceName]string{corev1.ResourceCPU: "1", corev1.ResourceMemory: "1.3"},
+			wantApplied:     true,
+		},
+		{
+			name:        "no recommendation with too few nodes",
+			selectorVal: "pool2",
+			annotations: map[string]string{
+				katalystconsts.NocAnnotationAutoApplyRecommendationKey: "true",
+			},
+			wantRecommended: map[corev1.ResourceName]string{},
+			wantSpec:        map[corev1.ResourceName]string{corev1.ResourceCPU: "1", corev1.ResourceMemory: "2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fss := &cliflag.NamedFlagSets{}
+			nocOptions := options.NewOvercommitOptions()
+			nocOptions.AddFlags(fss)
+			nocOptions.RecommendationPercentile = 50
+			nocConf := controller.NewOvercommitConfig()
+			require.NoError(t, nocOptions.ApplyTo(nocConf))
+
+			noc := makeSelectorNoc("config1", "1", "2", tc.selectorVal)
+			noc.Annotations = tc.annotations
+
+			var kubeObjects, internalObjects []runtime.Object
+			internalObjects = append(internalObjects, noc)
+			for i, ratios := range [][]string{{"1.2", "1.1"}, {"1.5", "1.3"}, {"2", "1.4"}, {"3", "1.6"}} {
+				name := fmt.Sprintf("node%d", i)
+				kubeObjects = append(kubeObjects, makeNode(name, map[string]string{consts.NodeOvercommitSelectorKey: "pool1"}))
+				internalObjects = append(internalObjects, makeRealtimeCNR(name, ratios[0], ratios[1]))
+			}
+			kubeObjects = append(kubeObjects, makeNode("node4", map[string]string{consts.NodeOvercommitSelectorKey: "pool2"}))
+			internalObjects = append(internalObjects, makeRealtimeCNR("node4", "2", "2"))
+
+			controlCtx, err := katalyst_base.GenerateFakeGenericContext(kubeObjects, internalObjects)
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			nocController, err := NewNodeOvercommitController(ctx, controlCtx, &generic.GenericConfiguration{}, nocConf)
+			require.NoError(t, err)
+
+			controlCtx.StartInformer(ctx)
+			require.True(t, cache.WaitForCacheSync(ctx.Done(), nocController.syncedFunc...))
+			require.NoError(t, nocController.matcher.Reconcile())
+
+			require.NoError(t, nocController.syncRecommendation(noc.Name, now))
+
+			got, err := controlCtx.Client.InternalClient.OvercommitV1alpha1().NodeOvercommitConfigs().Get(ctx, noc.Name, metav1.GetOptions{})
+			require.NoError(t, err)
+
+			recommended := map[corev1.ResourceName]string{}
+			require.NoError(t, json.Unmarshal([]byte(got.Annotations[katalystconsts.NocAnnotationRecommendedOvercommitRatioKey]), &recommended))
+			assert.Equal(t, tc.wantRecommended, recommended)
+			assert.Equal(t, tc.wantSpec, got.Spec.ResourceOvercommitRatio)
+
+			_, applied := got.Annotations[katalystconsts.NocAnnotationLastAutoApplyTimeKey]
+			assert.Equal(t, tc.wantApplied, applied)
+
+			history := getOvercommitRatioHistory(got)
+			for _, samples := range history {
+				for _, sample := range samples {
+					assert.True(t, sample.Timestamp >= now.Add(-nocConf.Node.Recommendation.HistoryWindow).Unix())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rescheduling
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	nodev1alpha1 "github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+)
+
+// nodeIssue is the reason why pods should be moved off from a node
+type nodeIssue string
+
+const (
+	nodeIssueTainted    nodeIssue = "tainted"
+	nodeIssueHot        nodeIssue = "hot"
+	nodeIssueFragmented nodeIssue = "fragmented"
+)
+
+// nodeIssues are ordered by priority, pods on tainted nodes are handled first
+var nodeIssues = []nodeIssue{nodeIssueTainted, nodeIssueHot, nodeIssueFragmented}
+
+// nodeMetricExpiration is the max age of node metrics in cnr to be trusted
+const nodeMetricExpiration = 10 * time.Minute
+
+// nodeStatus is the snapshot of a node evaluated in one sync period
+type nodeStatus struct {
+	node *v1.Node
+	cnr  *nodev1alpha1.CustomNodeResource
+	npd  *nodev1alpha1.NodeProfileDescriptor
+
+	// cpuUsage is the real-time cpu usage in cores reported in cnr, it's negative if unknown
+	cpuUsage float64
+
+	// taints are cnr taints set by eviction manager that pods should be moved for
+	taints []nodev1alpha1.Taint
+	// numaFreeCPU and numaConsumerCPU are free cpus and cpus allocated to each consumer per NUMA node
+	numaFreeCPU     map[int]float64
+	numaConsumerCPU map[int]map[string]float64
+
+	// issues maps each issue found on the node to its detail
+	issues map[nodeIssue]string
+}
+
+func newNodeStatus(node *v1.Node, cnr *nodev1alpha1.CustomNodeResource, npd *nodev1alpha1.NodeProfileDescriptor,
+	conf *controller.ReschedulingConfig, now time.Time,
+) *nodeStatus {
+	s := &nodeStatus{
+		node:     node,
+		cnr:      cnr,
+		npd:      npd,
+		cpuUsage: -1,
+		issues:   make(map[nodeIssue]string),
+	}
+
+	if cnr != nil {
+		s.taints = getEvictionTaints(cnr)
+		if len(s.taints) > 0 {
+			taintKeys := make([]string, 0, len(s.taints))
+			for _, taint := range s.taints {
+				taintKeys = append(taintKeys, taint.Key)
+			}
+			s.issues[nodeIssueTainted] = fmt.Sprintf("cnr taints %v", taintKeys)
+		}
+
+		s.numaFreeCPU, s.numaConsumerCPU = getNUMACPUAllocation(cnr)
+		if ratio, free := getFragmentation(s.numaFreeCPU); free >= conf.FragmentationMinFreeCPU &&
+			conf.FragmentationThreshold > 0 && ratio >= conf.FragmentationThreshold {
+			s.issues[nodeIssueFragmented] = fmt.Sprintf("%.2f of %.2f free cpus are outside the most free NUMA", ratio, free)
+		}
+	}
+
+	var hotDetails []string
+	if usage := getNodeUsage(cnr, now); usage != nil {
+		if usage.CPU != nil {
+			s.cpuUsage = usage.CPU.AsApproximateFloat64()
+			if ratio, ok := getUsageRatio(usage.CPU.AsApproximateFloat64(), node, v1.ResourceCPU); ok && ratio > conf.HotCPUUsageThreshold {
+				hotDetails = append(hotDetails, fmt.Sprintf("cpu usage %.2f", ratio))
+			}
+		}
+		if usage.Memory != nil {
+			if ratio, ok := getUsageRatio(usage.Memory.AsApproximateFloat64(), node, v1.ResourceMemory); ok && ratio > conf.HotMemoryUsageThreshold {
+				hotDetails = append(hotDetails, fmt.Sprintf("memory usage %.2f", ratio))
+			}
+		}
+	}
+	hotDetails = append(hotDetails, getHotNPDMetrics(npd, conf, now)...)
+	if len(hotDetails) > 0 {
+		s.issues[nodeIssueHot] = strings.Join(hotDetails, ", ")
+	}
+
+	return s
+}
+
+// getEvictionTaints returns NoSchedule and NoExecute cnr taints set by eviction manager
+func getEvictionTaints(cnr *nodev1alpha1.CustomNodeResource) []nodev1alpha1.Taint {
+	var taints []nodev1alpha1.Taint
+	for _, taint := range cnr.Spec.Taints {
+		if !strings.HasPrefix(taint.Key, consts.KatalystNodeDomainPrefix) {
+			continue
+		}
+
+		if taint.Effect == v1.TaintEffectNoSchedule || taint.Effect == v1.TaintEffectNoExecute {
+			taints = append(taints, taint)
+		}
+	}
+	return taints
+}
+
+// getNodeUsage returns node usage in cnr, or nil if it's missing or out of date
+func getNodeUsage(cnr *nodev1alpha1.CustomNodeResource, now time.Time) *nodev1alpha1.ResourceMetric {
+	if cnr == nil || cnr.Status.NodeMetricStatus == nil || cnr.Status.NodeMetricStatus.NodeMetric == nil {
+		return nil
+	}
+
+	if now.Sub(cnr.Status.NodeMetricStatus.UpdateTime.Time) > nodeMetricExpiration {
+		klog.V(4).Infof("node metric in cnr %v is out of date", cnr.Name)
+		return nil
+	}
+	return cnr.Status.NodeMetricStatus.NodeMetric.GenericUsage
+}
+
+func getUsageRatio(usage float64, node *v1.Node, resourceName v1.ResourceName) (float64, bool) {
+	allocatable, ok := node.Status.Allocatable[resourceName]
+	if !ok || allocatable.IsZero() {
+		return 0, false
+	}
+	return usage / allocatable.AsApproximateFloat64(), true
+}
+
+// getHotNPDMetrics returns details of node metrics in npd which exceed their thresholds,
+// only metrics with the configured window and aggregator that are not out of date are considered
+func getHotNPDMetrics(npd *nodev1alpha1.NodeProfileDescriptor, conf *controller.ReschedulingConfig, now time.Time) []string {
+	if npd == nil || len(conf.NPDMetricThresholds) == 0 {
+		return nil
+	}
+
+	expiration := conf.NPDMetricExpiration
+	if windowExpiration := time.Duration(float64(conf.NPDMetricWindow) * conf.NPDMetricExpirationWindowRatio); windowExpiration > expiration {
+		expiration = windowExpiration
+	}
+
+	var details []string
+	for _, scopedMetrics := range npd.Status.NodeMetrics {
+		if scopedMetrics.Scope != conf.NPDMetricScope {
+			continue
+		}
+
+		for _, metric := range scopedMetrics.Metrics {
+			threshold, ok := conf.NPDMetricThresholds[metric.MetricName]
+			if !ok || !matchNPDMetric(metric, conf.NPDMetricWindow, conf.NPDMetricAggregator) {
+				continue
+			}
+
+			if now.Sub(metric.Timestamp.Time) > expiration {
+				klog.V(4).Infof("npd metric %v of %v is out of date", metric.MetricName, npd.Name)
+				continue
+			}
+
+			if value := metric.Value.AsApproximateFloat64(); value > threshold {
+				details = append(details, fmt.Sprintf("%v %.2f", metric.MetricName, value))
+			}
+		}
+	}
+	return details
+}
+
+// matchNPDMetric returns true if the metric is calculated in the given window with the given aggregator
+func matchNPDMetric(metric nodev1alpha1.MetricValue, window time.Duration, aggregator string) bool {
+	var metricWindow time.Duration
+	if metric.Window != nil {
+		metricWindow = metric.Window.Duration
+	}
+
+	var metricAggregator string
+	if metric.Aggregator != nil {
+		metricAggregator = string(*metric.Aggregator)
+	}
+	return metricWindow == window && metricAggregator == aggregator
+}
+
+// getNUMACPUAllocation returns free cpus and cpus allocated to each consumer per NUMA node in cnr
+func getNUMACPUAllocation(cnr *nodev1alpha1.CustomNodeResource) (map[int]float64, map[int]map[string]float64) {
+	numaFreeCPU := make(map[int]float64)
+	numaConsumerCPU := make(map[int]map[string]float64)
+	for _, topologyZone := range cnr.Status.TopologyZone {
+		if topologyZone.Type != nodev1alpha1.TopologyTypeSocket {
+			continue
+		}
+
+		for _, child := range topologyZone.Children {
+			if child.Type != nodev1alpha1.TopologyTypeNuma || child.Resources.Allocatable == nil {
+				continue
+			}
+
+			numaID, err := strconv.Atoi(child.Name)
+			if err != nil {
+				klog.Errorf("invalid numa name: %v, %v", child.Name, err)
+				continue
+			}
+
+			allocatable, ok := (*child.Resources.Allocatable)[v1.ResourceCPU]
+			if !ok {
+				continue
+			}
+
+			free := allocatable.AsApproximateFloat64()
+			numaConsumerCPU[numaID] = make(map[string]float64)
+			for _, allocation := range child.Allocations {
+				if allocation == nil || allocation.Requests == nil {
+					continue
+				}
+
+				if cpu, ok := (*allocation.Requests)[v1.ResourceCPU]; ok {
+					free -= cpu.AsApproximateFloat64()
+					numaConsumerCPU[numaID][allocation.Consumer] += cpu.AsApproximateFloat64()
+				}
+			}
+
+			if free < 0 {
+				free = 0
+			}
+			numaFreeCPU[numaID] = free
+		}
+	}
+	return numaFreeCPU, numaConsumerCPU
+}
+
+// getFragmentation returns the ratio of free cpus outside the most free NUMA node, and total free cpus
+func getFragmentation(numaFreeCPU map[int]float64) (float64, float64) {
+	var total, max float64
+	for _, free := range numaFreeCPU {
+		total += free
+		if free > max {
+			max = free
+		}
+	}
+
+	if total == 0 {
+		return 0, 0
+	}
+	return 1 - max/total, total
+}
+
+// getMostFreeNUMA returns the NUMA node with most free cpus, ties are broken by the smaller id
+func getMostFreeNUMA(numaFreeCPU map[int]float64) int {
+	mostFree := -1
+	for numaID, free := range numaFreeCPU {
+		if mostFree == -1 || free > numaFreeCPU[mostFree] || (free == numaFreeCPU[mostFree] && numaID < mostFree) {
+			mostFree = numaID
+		}
+	}
+	return mostFree
+}
+
+// getPodNUMACPU returns cpus allocated to the pod in the given NUMA node
+func (s *nodeStatus) getPodNUMACPU(pod *v1.Pod, numaID int) float64 {
+	return s.numaConsumerCPU[numaID][native.GenerateUniqObjectUIDKey(pod)]
+}
@@ -0,0 +1,488 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rescheduling
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/klog/v2"
+	nodeutil "sigs.k8s.io/descheduler/pkg/descheduler/node"
+	podutil "sigs.k8s.io/descheduler/pkg/descheduler/pod"
+
+	nodev1alpha1 "github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	listers "github.com/kubewharf/katalyst-api/pkg/client/listers/node/v1alpha1"
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	"github.com/kubewharf/katalyst-core/pkg/client/control"
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+)
+
+const reschedulingControllerName = "rescheduling"
+
+const (
+	metricsNameReschedulingDecision = "rescheduling_decision"
+
+	decisionResultEvicted = "evicted"
+	decisionResultFailed  = "failed"
+	decisionResultSkipped = "skipped"
+	decisionResultDryRun  = "dry_run"
+)
+
+// qosLevelEvictionRanks defines the order to pick pods for each qos level; pods with
+// qos levels not listed here (e.g. system_cores) are never evicted
+var qosLevelEvictionRanks = map[string]int{
+	apiconsts.PodAnnotationQoSLevelReclaimedCores: 0,
+	apiconsts.PodAnnotationQoSLevelSharedCores:    1,
+	apiconsts.PodAnnotationQoSLevelDedicatedCores: 2,
+}
+
+var criticalPriorityClassNames = sets.NewString("system-cluster-critical", "system-node-critical")
+
+// Rescheduler moves pods off from nodes which are chronically tainted by eviction manager,
+// hot or fragmented according to CNR and NPD, so that they can land on better nodes.
+type Rescheduler struct {
+	ctx  context.Context
+	conf *controller.ReschedulingConfig
+
+	qosConf    *generic.QoSConfiguration
+	podEjector control.PodEjector
+
+	nodeListerSynced cache.InformerSynced
+	nodeLister       corelisters.NodeLister
+	podListerSynced  cache.InformerSynced
+	podLister        corelisters.PodLister
+	cnrListerSynced  cache.InformerSynced
+	cnrLister        listers.CustomNodeResourceLister
+	npdListerSynced  cache.InformerSynced
+	npdLister        listers.NodeProfileDescriptorLister
+
+	// issueSince records when each issue is first found on each node
+	issueSince map[string]map[nodeIssue]time.Time
+
+	recorder       events.EventRecorder
+	metricsEmitter metrics.MetricEmitter
+}
+
+func NewRescheduler(ctx context.Context,
+	controlCtx *katalystbase.GenericContext,
+	genericConf *generic.GenericConfiguration,
+	_ *controller.GenericControllerConfiguration,
+	conf *controller.ReschedulingConfig,
+) (*Rescheduler, error) {
+	if conf.SyncPeriod <= 0 {
+		return nil, fmt.Errorf("invalid sync period: %v", conf.SyncPeriod)
+	}
+
+	r := &Rescheduler{
+		ctx:        ctx,
+		conf:       conf,
+		qosConf:    genericConf.QoSConfiguration,
+		podEjector: control.NewRealPodEjector(controlCtx.Client.KubeClient),
+		issueSince: make(map[string]map[nodeIssue]time.Time),
+		recorder:   controlCtx.BroadcastAdapter.NewRecorder(reschedulingControllerName),
+	}
+
+	nodeInformer := controlCtx.KubeInformerFactory.Core().V1().Nodes()
+	r.nodeLister = nodeInformer.Lister()
+	r.nodeListerSynced = nodeInformer.Informer().HasSynced
+
+	podInformer := controlCtx.KubeInformerFactory.Core().V1().Pods()
+	r.podLister = podInformer.Lister()
+	r.podListerSynced = podInformer.Informer().HasSynced
+
+	cnrInformer := controlCtx.InternalInformerFactory.Node().V1alpha1().CustomNodeResources()
+	r.cnrLister = cnrInformer.Lister()
+	r.cnrListerSynced = cnrInformer.Informer().HasSynced
+
+	npdInformer := controlCtx.InternalInformerFactory.Node().V1alpha1().NodeProfileDescriptors()
+	r.npdLister = npdInformer.Lister()
+	r.npdListerSynced = npdInformer.Informer().HasSynced
+
+	r.metricsEmitter = controlCtx.EmitterPool.GetDefaultMetricsEmitter().WithTags(reschedulingControllerName)
+
+	return r, nil
+}
+
+func (r *Rescheduler) Run() {
+	defer utilruntime.HandleCrash()
+	defer klog.Infof("Shutting down %s controller", reschedulingControllerName)
+
+	if !cache.WaitForCacheSync(r.ctx.Done(), r.nodeListerSynced, r.podListerSynced,
+		r.cnrListerSynced, r.npdListerSynced) {
+		utilruntime.HandleError(fmt.Errorf("unable to sync caches for %s controller", reschedulingControllerName))
+		return
+	}
+	klog.Infof("Caches are synced for %s controller", reschedulingControllerName)
+
+	go wait.Until(r.reschedule, r.conf.SyncPeriod, r.ctx.Done())
+
+	<-r.ctx.Done()
+}
+
+// reschedule evaluates all nodes and evicts pods from nodes with chronic issues,
+// only if there is another node that the pod fits and which has no issue.
+func (r *Rescheduler) reschedule() {
+	now := time.Now()
+
+	nodes, err := r.nodeLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("[rescheduling] list nodes failed: %v", err)
+		return
+	}
+
+	pods, err := r.podLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("[rescheduling] list pods failed: %v", err)
+		return
+	}
+
+	podsByNode := make(map[string][]*v1.Pod)
+	for _, pod := range pods {
+		if native.IsAssignedPod(pod) && !native.PodIsTerminated(pod) {
+			podsByNode[pod.Spec.NodeName] = append(podsByNode[pod.Spec.NodeName], pod)
+		}
+	}
+	getPodsAssignedToNode := func(nodeName string, filter podutil.FilterFunc) ([]*v1.Pod, error) {
+		var result []*v1.Pod
+		for _, pod := range podsByNode[nodeName] {
+			if filter == nil || filter(pod) {
+				result = append(result, pod)
+			}
+		}
+		return result, nil
+	}
+
+	nodeStatuses := make([]*nodeStatus, 0, len(nodes))
+	for _, node := range nodes {
+		if !nodeutil.IsReady(node) {
+			continue
+		}
+		nodeStatuses = append(nodeStatuses, newNodeStatus(node, r.getCNR(node.Name), r.getNPD(node.Name), r.conf, now))
+	}
+	sort.Slice(nodeStatuses, func(i, j int) bool {
+		return nodeStatuses[i].node.Name < nodeStatuses[j].node.Name
+	})
+	r.updateIssueSince(nodeStatuses, now)
+
+	var destinations []*nodeStatus
+	for _, status := range nodeStatuses {
+		if len(status.issues) == 0 && !nodeutil.IsNodeUnschedulable(status.node) {
+			destinations = append(destinations, status)
+		}
+	}
+
+	evicted := 0
+	for _, status := range nodeStatuses {
+		if evicted >= r.conf.MaxEvictionsPerCycle {
+			klog.Infof("[rescheduling] max evictions %v per cycle reached", r.conf.MaxEvictionsPerCycle)
+			return
+		}
+
+		nodeEvicted := 0
+		picked := sets.NewString()
+		for _, issue := range nodeIssues {
+			detail, ok := status.issues[issue]
+			if !ok {
+				continue
+			} else if since := r.issueSince[status.node.Name][issue]; now.Sub(since) < r.conf.ChronicDuration {
+				klog.V(4).Infof("[rescheduling] node %v is %v since %v, wait until it's chronic", status.node.Name, issue, since)
+				continue
+			}
+
+			for _, pod := range r.getCandidates(status, issue, podsByNode[status.node.Name]) {
+				if evicted >= r.conf.MaxEvictionsPerCycle || nodeEvicted >= r.conf.MaxEvictionsPerNode {
+					break
+				} else if picked.Has(string(pod.UID)) {
+					continue
+				}
+				picked.Insert(string(pod.UID))
+
+				destination := r.findDestination(pod, destinations, getPodsAssignedToNode)
+				if destination == nil {
+					r.recordDecision(pod, v1.EventTypeNormal, consts.EventReasonReschedulingSkipped, issue, decisionResultSkipped,
+						"node %v is %v (%v), but no better node fits the pod", status.node.Name, issue, detail)
+					continue
+				}
+
+				if !r.evictPod(pod, status, issue, detail, destination) {
+					continue
+				}
+				evicted++
+				nodeEvicted++
+
+				// assume the pod on the destination, so that it's taken into account for following pods
+				assumedPod := pod.DeepCopy()
+				assumedPod.Spec.NodeName = destination.node.Name
+				podsByNode[destination.node.Name] = append(podsByNode[destination.node.Name], assumedPod)
+				if destination.cpuUsage >= 0 {
+					destination.cpuUsage += getPodCPURequest(pod)
+				}
+			}
+
+			if nodeEvicted > 0 {
+				// wait for the issue to be chronic again before moving more pods off from this node,
+				// since it takes time for cnr and npd to reflect the evictions
+				delete(r.issueSince[status.node.Name], issue)
+			}
+		}
+	}
+}
+
+// updateIssueSince keeps the first time of each issue found on each node, and forgets issues which have gone
+func (r *Rescheduler) updateIssueSince(nodeStatuses []*nodeStatus, now time.Time) {
+	issueSince := make(map[string]map[nodeIssue]time.Time, len(nodeStatuses))
+	for _, status := range nodeStatuses {
+		if len(status.issues) == 0 {
+			continue
+		}
+
+		issueSince[status.node.Name] = make(map[nodeIssue]time.Time, len(status.issues))
+		for issue := range status.issues {
+			if since, ok := r.issueSince[status.node.Name][issue]; ok {
+				issueSince[status.node.Name][issue] = since
+			} else {
+				klog.Infof("[rescheduling] node %v is %v: %v", status.node.Name, issue, status.issues[issue])
+				issueSince[status.node.Name][issue] = now
+			}
+		}
+	}
+	r.issueSince = issueSince
+}
+
+// getCandidates returns evictable pods on the node that moving them helps with the given issue,
+// in the order that they should be evicted.
+func (r *Rescheduler) getCandidates(status *nodeStatus, issue nodeIssue, pods []*v1.Pod) []*v1.Pod {
+	type candidate struct {
+		pod  *v1.Pod
+		rank int
+		cpu  float64
+	}
+
+	mostFreeNUMA := getMostFreeNUMA(status.numaFreeCPU)
+	var candidates []candidate
+	for _, pod := range pods {
+		rank, ok := r.getEvictionRank(pod)
+		if !ok {
+			continue
+		}
+
+		c := candidate{pod: pod, rank: rank, cpu: getPodCPURequest(pod)}
+		switch issue {
+		case nodeIssueTainted:
+			if !r.isTaintedFor(status.taints, pod) {
+				continue
+			}
+		case nodeIssueFragmented:
+			// moving pods off from the most free NUMA node makes free cpus more compact
+			if c.cpu = status.getPodNUMACPU(pod, mostFreeNUMA); c.cpu <= 0 {
+				continue
+			}
+		}
+		candidates = append(candidates, c)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].rank != candidates[j].rank {
+			return candidates[i].rank < candidates[j].rank
+		} else if candidates[i].cpu != candidates[j].cpu {
+			return candidates[i].cpu > candidates[j].cpu
+		}
+		return native.GenerateUniqObjectNameKey(candidates[i].pod) < native.GenerateUniqObjectNameKey(candidates[j].pod)
+	})
+
+	result := make([]*v1.Pod, 0, len(candidates))
+	for _, c := range candidates {
+		result = append(result, c.pod)
+	}
+	return result
+}
+
+// getEvictionRank returns the rank of pod to be evicted, and false if the pod should never be evicted
+func (r *Rescheduler) getEvictionRank(pod *v1.Pod) (int, bool) {
+	if pod.DeletionTimestamp != nil || len(pod.OwnerReferences) == 0 || native.CheckDaemonPod(pod) ||
+		criticalPriorityClassNames.Has(pod.Spec.PriorityClassName) {
+		return 0, false
+	} else if _, ok := pod.Annotations[v1.MirrorPodAnnotationKey]; ok {
+		return 0, false
+	}
+
+	for _, namespace := range r.conf.SkippedNamespaces {
+		if pod.Namespace == namespace {
+			return 0, false
+		}
+	}
+
+	qosLevel, err := r.qosConf.GetQoSLevelForPod(pod)
+	if err != nil {
+		klog.Warningf("[rescheduling] get qos level for pod %v failed: %v", native.GenerateUniqObjectNameKey(pod), err)
+		return 0, false
+	} else if qosLevel == apiconsts.PodAnnotationQoSLevelDedicatedCores && !r.conf.EvictDedicatedCores {
+		return 0, false
+	}
+
+	rank, ok := qosLevelEvictionRanks[qosLevel]
+	return rank, ok
+}
+
+// isTaintedFor returns true if any of the taints applies to the pod and isn't tolerated by it
+func (r *Rescheduler) isTaintedFor(taints []nodev1alpha1.Taint, pod *v1.Pod) bool {
+	qosLevel, err := r.qosConf.GetQoSLevelForPod(pod)
+	if err != nil {
+		return false
+	}
+
+	for i := range taints {
+		if taints[i].QoSLevel != "" && string(taints[i].QoSLevel) != qosLevel {
+			continue
+		}
+
+		tolerated := false
+		for j := range pod.Spec.Tolerations {
+			if pod.Spec.Tolerations[j].ToleratesTaint(&taints[i].Taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return true
+		}
+	}
+	return false
+}
+
+// findDestination returns the least loaded node which the pod fits without making it hot
+func (r *Rescheduler) findDestination(pod *v1.Pod, destinations []*nodeStatus,
+	getPodsAssignedToNode podutil.GetPodsAssignedToNodeFunc,
+) *nodeStatus {
+	podCPU := getPodCPURequest(pod)
+
+	var (
+		best      *nodeStatus
+		bestRatio float64
+	)
+	for _, destination := range destinations {
+		if destination.node.Name == pod.Spec.NodeName {
+			continue
+		}
+
+		if errs := nodeutil.NodeFit(getPodsAssignedToNode, pod, destination.node); len(errs) > 0 {
+			klog.V(4).Infof("[rescheduling] pod %v doesn't fit node %v: %v",
+				native.GenerateUniqObjectNameKey(pod), destination.node.Name, errs)
+			continue
+		}
+
+		// nodes without usage in cnr are less preferred
+		ratio := 1.0
+		if destination.cpuUsage >= 0 {
+			projected, ok := getUsageRatio(destination.cpuUsage+podCPU, destination.node, v1.ResourceCPU)
+			if ok && projected > r.conf.HotCPUUsageThreshold {
+				continue
+			} else if ok {
+				ratio = projected
+			}
+		}
+
+		if best == nil || ratio < bestRatio {
+			best, bestRatio = destination, ratio
+		}
+	}
+	return best
+}
+
+// evictPod evicts the pod with eviction api which respects pdb, and returns true if it's evicted
+func (r *Rescheduler) evictPod(pod *v1.Pod, status *nodeStatus, issue nodeIssue, detail string, destination *nodeStatus) bool {
+	if r.conf.DryRun {
+		r.recordDecision(pod, v1.EventTypeNormal, consts.EventReasonReschedulingDryRun, issue, decisionResultDryRun,
+			"would evict pod from node %v which is %v (%v), node %v fits it", status.node.Name, issue, detail, destination.node.Name)
+		return true
+	}
+
+	err := r.podEjector.EvictPod(r.ctx, &policy.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+		},
+	})
+	if err != nil {
+		reason := err.Error()
+		if errors.IsTooManyRequests(err) {
+			reason = fmt.Sprintf("disruption budget doesn't allow it: %v", err)
+		}
+		r.recordDecision(pod, v1.EventTypeWarning, consts.EventReasonEvictFailed, issue, decisionResultFailed,
+			"failed to evict pod from node %v which is %v (%v): %v", status.node.Name, issue, detail, reason)
+		return false
+	}
+
+	r.recordDecision(pod, v1.EventTypeNormal, consts.EventReasonEvictCreated, issue, decisionResultEvicted,
+		"evicted pod from node %v which is %v (%v), node %v fits it", status.node.Name, issue, detail, destination.node.Name)
+	return true
+}
+
+func (r *Rescheduler) recordDecision(pod *v1.Pod, eventType, reason string, issue nodeIssue, result string,
+	messageFmt string, args ...interface{},
+) {
+	klog.Infof("[rescheduling] pod %v: "+messageFmt, append([]interface{}{native.GenerateUniqObjectNameKey(pod)}, args...)...)
+	r.recorder.Eventf(pod, nil, eventType, reason, consts.EventActionRescheduling, messageFmt, args...)
+	_ = r.metricsEmitter.StoreInt64(metricsNameReschedulingDecision, 1, metrics.MetricTypeNameCount,
+		metrics.MetricTag{Key: "issue", Val: string(issue)},
+		metrics.MetricTag{Key: "result", Val: result})
+}
+
+func getPodCPURequest(pod *v1.Pod) float64 {
+	requests := native.SumUpPodRequestResources(pod)
+	return requests.Cpu().AsApproximateFloat64()
+}
+
+func (r *Rescheduler) getCNR(name string) *nodev1alpha1.CustomNodeResource {
+	cnr, err := r.cnrLister.Get(name)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			klog.Errorf("[rescheduling] get cnr %v failed: %v", name, err)
+		}
+		return nil
+	}
+	return cnr
+}
+
+func (r *Rescheduler) getNPD(name string) *nodev1alpha1.NodeProfileDescriptor {
+	npd, err := r.npdLister.Get(name)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			klog.Errorf("[rescheduling] get npd %v failed: %v", name, err)
+		}
+		return nil
+	}
+	return npd
+}
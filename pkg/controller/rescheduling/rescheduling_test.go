@@ -0,0 +1,508 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rescheduling
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/events"
+
+	nodev1alpha1 "github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+)
+
+type fakePodEjector struct {
+	mutex   sync.Mutex
+	err     error
+	evicted []string
+}
+
+func (f *fakePodEjector) DeletePod(_ context.Context, _, _ string, _ metav1.DeleteOptions) error {
+	return nil
+}
+
+func (f *fakePodEjector) EvictPod(_ context.Context, eviction *policy.Eviction) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.err != nil {
+		return f.err
+	}
+	f.evicted = append(f.evicted, eviction.Namespace+"/"+eviction.Name)
+	return nil
+}
+
+func makeNode(name string, cpu string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(cpu),
+				v1.ResourceMemory: resource.MustParse("64Gi"),
+				v1.ResourcePods:   resource.MustParse("110"),
+			},
+			Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}},
+		},
+	}
+}
+
+func makePod(name, nodeName, qosLevel, cpu string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			UID:         types.UID(name),
+			Annotations: map[string]string{apiconsts.PodAnnotationQoSLevelKey: qosLevel},
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "ReplicaSet", Name: "rs", APIVersion: "apps/v1"},
+			},
+		},
+		Spec: v1.PodSpec{
+			NodeName: nodeName,
+			Containers: []v1.Container{
+				{
+					Name: "main",
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)},
+					},
+				},
+			},
+		},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}
+}
+
+func makeCNR(name string, cpuUsage string, updateTime time.Time, taints ...nodev1alpha1.Taint) *nodev1alpha1.CustomNodeResource {
+	usage := resource.MustParse(cpuUsage)
+	return &nodev1alpha1.CustomNodeResource{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       nodev1alpha1.CustomNodeResourceSpec{Taints: taints},
+		Status: nodev1alpha1.CustomNodeResourceStatus{
+			NodeMetricStatus: &nodev1alpha1.NodeMetricStatus{
+				UpdateTime: metav1.NewTime(updateTime),
+				NodeMetric: &nodev1alpha1.NodeMetricInfo{
+					ResourceUsage: nodev1alpha1.ResourceUsage{
+						GenericUsage: &nodev1alpha1.ResourceMetric{CPU: &usage},
+					},
+				},
+			},
+		},
+	}
+}
+
+func makeNUMAZone(numaID string, cpu string, allocations map[string]string) *nodev1alpha1.TopologyZone {
+	zone := &nodev1alpha1.TopologyZone{
+		Type: nodev1alpha1.TopologyTypeNuma,
+		Name: numaID,
+		Resources: nodev1alpha1.Resources{
+			Allocatable: &v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)},
+		},
+	}
+	for consumer, request := range allocations {
+		zone.Allocations = append(zone.Allocations, &nodev1alpha1.Allocation{
+			Consumer: consumer,
+			Requests: &v1.ResourceList{v1.ResourceCPU: resource.MustParse(request)},
+		})
+	}
+	return zone
+}
+
+func makeNPDMetric(name, value string, window time.Duration, aggregator nodev1alpha1.Aggregator,
+	timestamp time.Time,
+) nodev1alpha1.MetricValue {
+	return nodev1alpha1.MetricValue{
+		MetricName: name,
+		Value:      resource.MustParse(value),
+		Window:     &metav1.Duration{Duration: window},
+		Aggregator: &aggregator,
+		Timestamp:  metav1.NewTime(timestamp),
+	}
+}
+
+func newTestReschedulingConfig() *controller.ReschedulingConfig {
+	return &controller.ReschedulingConfig{
+		SyncPeriod:                     time.Minute,
+		HotCPUUsageThreshold:           0.8,
+		HotMemoryUsageThreshold:        0.9,
+		NPDMetricScope:                 "load",
+		NPDMetricThresholds:            map[string]float64{"load_1min": 1},
+		NPDMetricWindow:                5 * time.Minute,
+		NPDMetricAggregator:            "avg",
+		NPDMetricExpiration:            10 * time.Minute,
+		NPDMetricExpirationWindowRatio: 0.2,
+		FragmentationThreshold:         0.5,
+		FragmentationMinFreeCPU:        4,
+		MaxEvictionsPerCycle:           5,
+		MaxEvictionsPerNode:            1,
+		SkippedNamespaces:              []string{"kube-system"},
+	}
+}
+
+func TestNewNodeStatus(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	conf := newTestReschedulingConfig()
+
+	fragmentedCNR := &nodev1alpha1.CustomNodeResource{
+		ObjectMeta: metav1.ObjectMeta{Name: "node"},
+		Status: nodev1alpha1.CustomNodeResourceStatus{
+			TopologyZone: []*nodev1alpha1.TopologyZone{
+				{
+					Type: nodev1alpha1.TopologyTypeSocket,
+					Name: "0",
+					Children: []*nodev1alpha1.TopologyZone{
+						makeNUMAZone("0", "8", map[string]string{"default/pod-1/pod-1": "4"}),
+						makeNUMAZone("1", "8", map[string]string{"default/pod-2/pod-2": "4"}),
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		cnr        *nodev1alpha1.CustomNodeResource
+		npd        *nodev1alpha1.NodeProfileDescriptor
+		wantIssues []nodeIssue
+	}{
+		{
+			name:       "healthy node",
+			cnr:        makeCNR("node", "4", now),
+			wantIssues: nil,
+		},
+		{
+			name:       "hot node by cnr usage",
+			cnr:        makeCNR("node", "15", now),
+			wantIssues: []nodeIssue{nodeIssueHot},
+		},
+		{
+			name:       "out of date usage is ignored",
+			cnr:        makeCNR("node", "15", now.Add(-time.Hour)),
+			wantIssues: nil,
+		},
+		{
+			name: "hot node by npd metrics",
+			npd: &nodev1alpha1.NodeProfileDescriptor{
+				ObjectMeta: metav1.ObjectMeta{Name: "node"},
+				Status: nodev1alpha1.NodeProfileDescriptorStatus{
+					NodeMetrics: []nodev1alpha1.ScopedNodeMetrics{
+						{
+							Scope: "load",
+							Metrics: []nodev1alpha1.MetricValue{
+								makeNPDMetric("load_1min", "2", 5*time.Minute, nodev1alpha1.AggregatorAvg, now),
+							},
+						},
+					},
+				},
+			},
+			wantIssues: []nodeIssue{nodeIssueHot},
+		},
+		{
+			name: "tainted node by eviction manager",
+			cnr: makeCNR("node", "4", now, nodev1alpha1.Taint{
+				Taint: v1.Taint{
+					Key:    consts.KatalystNodeDomainPrefix + "/MemoryPressure",
+					Effect: v1.TaintEffectNoSchedule,
+				},
+				QoSLevel: apiconsts.QoSLevelReclaimedCores,
+			}, nodev1alpha1.Taint{
+				Taint: v1.Taint{Key: "other", Effect: v1.TaintEffectNoSchedule},
+			}),
+			wantIssues: []nodeIssue{nodeIssueTainted},
+		},
+		{
+			name:       "fragmented node",
+			cnr:        fragmentedCNR,
+			wantIssues: []nodeIssue{nodeIssueFragmented},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			status := newNodeStatus(makeNode("node", "16"), tt.cnr, tt.npd, conf, now)
+			var issues []nodeIssue
+			for _, issue := range nodeIssues {
+				if _, ok := status.issues[issue]; ok {
+					issues = append(issues, issue)
+				}
+			}
+			assert.Equal(t, tt.wantIssues, issues)
+		})
+	}
+}
+
+func TestGetHotNPDMetrics(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		window      time.Duration
+		metrics     []nodev1alpha1.MetricValue
+		wantDetails []string
+	}{
+		{
+			name:   "configured window and aggregator",
+			window: 5 * time.Minute,
+			metrics: []nodev1alpha1.MetricValue{
+				makeNPDMetric("load_1min", "2", 5*time.Minute, nodev1alpha1.AggregatorAvg, now),
+			},
+			wantDetails: []string{"load_1min 2.00"},
+		},
+		{
+			name:   "other windows and aggregators are ignored",
+			window: 5 * time.Minute,
+			metrics: []nodev1alpha1.MetricValue{
+				makeNPDMetric("load_1min", "0.5", 5*time.Minute, nodev1alpha1.AggregatorAvg, now),
+				makeNPDMetric("load_1min", "2", 5*time.Minute, nodev1alpha1.AggregatorMax, now),
+				makeNPDMetric("load_1min", "2", time.Hour, nodev1alpha1.AggregatorAvg, now),
+				{MetricName: "load_1min", Value: resource.MustParse("2"), Timestamp: metav1.NewTime(now)},
+			},
+			wantDetails: nil,
+		},
+		{
+			name:   "out of date metrics are ignored",
+			window: 5 * time.Minute,
+			metrics: []nodev1alpha1.MetricValue{
+				makeNPDMetric("load_1min", "2", 5*time.Minute, nodev1alpha1.AggregatorAvg, now.Add(-11*time.Minute)),
+			},
+			wantDetails: nil,
+		},
+		{
+			name:   "long windows are trusted longer",
+			window: 24 * time.Hour,
+			metrics: []nodev1alpha1.MetricValue{
+				makeNPDMetric("load_1min", "2", 24*time.Hour, nodev1alpha1.AggregatorAvg, now.Add(-3*time.Hour)),
+			},
+			wantDetails: []string{"load_1min 2.00"},
+		},
+		{
+			name:   "long windows expire too",
+			window: 24 * time.Hour,
+			metrics: []nodev1alpha1.MetricValue{
+				makeNPDMetric("load_1min", "2", 24*time.Hour, nodev1alpha1.AggregatorAvg, now.Add(-6*time.Hour)),
+			},
+			wantDetails: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := newTestReschedulingConfig()
+			conf.NPDMetricWindow = tt.window
+			npd := &nodev1alpha1.NodeProfileDescriptor{
+				ObjectMeta: metav1.ObjectMeta{Name: "node"},
+				Status: nodev1alpha1.NodeProfileDescriptorStatus{
+					NodeMetrics: []nodev1alpha1.ScopedNodeMetrics{
+						{Scope: "load", Metrics: tt.metrics},
+					},
+				},
+			}
+			assert.Equal(t, tt.wantDetails, getHotNPDMetrics(npd, conf, now))
+		})
+	}
+}
+
+func TestReschedule(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	criticalPod := makePod("critical", "hot-node", apiconsts.PodAnnotationQoSLevelSharedCores, "1")
+	criticalPod.Spec.PriorityClassName = "system-node-critical"
+	daemonPod := makePod("daemon", "hot-node", apiconsts.PodAnnotationQoSLevelSharedCores, "1")
+	daemonPod.OwnerReferences[0].Kind = "DaemonSet"
+	systemPod := makePod("system", "hot-node", apiconsts.PodAnnotationQoSLevelSharedCores, "1")
+	systemPod.Namespace = "kube-system"
+
+	tests := []struct {
+		name        string
+		kubeObjects []runtime.Object
+		cnrs        []runtime.Object
+		confFunc    func(conf *controller.ReschedulingConfig)
+		ejectErr    error
+		wantEvicted []string
+		wantEvents  int
+	}{
+		{
+			name: "evict the shared pod with most cpu from hot node",
+			kubeObjects: []runtime.Object{
+				makeNode("hot-node", "16"), makeNode("cool-node", "16"),
+				makePod("small", "hot-node", apiconsts.PodAnnotationQoSLevelSharedCores, "1"),
+				makePod("large", "hot-node", apiconsts.PodAnnotationQoSLevelSharedCores, "4"),
+				makePod("dedicated", "hot-node", apiconsts.PodAnnotationQoSLevelDedicatedCores, "8"),
+				criticalPod, daemonPod, systemPod,
+			},
+			cnrs: []runtime.Object{
+				makeCNR("hot-node", "15", now), makeCNR("cool-node", "2", now),
+			},
+			wantEvicted: []string{"default/large"},
+			wantEvents:  1,
+		},
+		{
+			name: "evict dedicated pods if configured",
+			kubeObjects: []runtime.Object{
+				makeNode("hot-node", "16"), makeNode("cool-node", "16"),
+				makePod("dedicated", "hot-node", apiconsts.PodAnnotationQoSLevelDedicatedCores, "8"),
+			},
+			cnrs: []runtime.Object{
+				makeCNR("hot-node", "15", now), makeCNR("cool-node", "2", now),
+			},
+			confFunc: func(conf *controller.ReschedulingConfig) {
+				conf.EvictDedicatedCores = true
+			},
+			wantEvicted: []string{"default/dedicated"},
+			wantEvents:  1,
+		},
+		{
+			name: "skip when no better node fits the pod",
+			kubeObjects: []runtime.Object{
+				makeNode("hot-node", "16"), makeNode("cool-node", "16"),
+				makePod("large", "hot-node", apiconsts.PodAnnotationQoSLevelSharedCores, "4"),
+			},
+			cnrs: []runtime.Object{
+				makeCNR("hot-node", "15", now), makeCNR("cool-node", "11", now),
+			},
+			wantEvicted: nil,
+			wantEvents:  1,
+		},
+		{
+			name: "evict pods with matched qos level from tainted node",
+			kubeObjects: []runtime.Object{
+				makeNode("tainted-node", "16"), makeNode("cool-node", "16"),
+				makePod("shared", "tainted-node", apiconsts.PodAnnotationQoSLevelSharedCores, "4"),
+				makePod("reclaimed", "tainted-node", apiconsts.PodAnnotationQoSLevelReclaimedCores, "1"),
+			},
+			cnrs: []runtime.Object{
+				makeCNR("tainted-node", "4", now, nodev1alpha1.Taint{
+					Taint: v1.Taint{
+						Key:    consts.KatalystNodeDomainPrefix + "/MemoryPressure",
+						Effect: v1.TaintEffectNoExecute,
+					},
+					QoSLevel: apiconsts.QoSLevelReclaimedCores,
+				}),
+				makeCNR("cool-node", "2", now),
+			},
+			wantEvicted: []string{"default/reclaimed"},
+			wantEvents:  1,
+		},
+		{
+			name: "dry run doesn't evict pods",
+			kubeObjects: []runtime.Object{
+				makeNode("hot-node", "16"), makeNode("cool-node", "16"),
+				makePod("large", "hot-node", apiconsts.PodAnnotationQoSLevelSharedCores, "4"),
+			},
+			cnrs: []runtime.Object{
+				makeCNR("hot-node", "15", now), makeCNR("cool-node", "2", now),
+			},
+			confFunc: func(conf *controller.ReschedulingConfig) {
+				conf.DryRun = true
+			},
+			wantEvicted: nil,
+			wantEvents:  1,
+		},
+		{
+			name: "eviction denied by disruption budget",
+			kubeObjects: []runtime.Object{
+				makeNode("hot-node", "16"), makeNode("cool-node", "16"),
+				makePod("small", "hot-node", apiconsts.PodAnnotationQoSLevelSharedCores, "1"),
+				makePod("large", "hot-node", apiconsts.PodAnnotationQoSLevelSharedCores, "4"),
+			},
+			cnrs: []runtime.Object{
+				makeCNR("hot-node", "15", now), makeCNR("cool-node", "2", now),
+			},
+			ejectErr:    errors.NewTooManyRequests("cannot evict pod", 10),
+			wantEvicted: nil,
+			wantEvents:  2,
+		},
+		{
+			name: "wait until the issue is chronic",
+			kubeObjects: []runtime.Object{
+				makeNode("hot-node", "16"), makeNode("cool-node", "16"),
+				makePod("large", "hot-node", apiconsts.PodAnnotationQoSLevelSharedCores, "4"),
+			},
+			cnrs: []runtime.Object{
+				makeCNR("hot-node", "15", now), makeCNR("cool-node", "2", now),
+			},
+			confFunc: func(conf *controller.ReschedulingConfig) {
+				conf.ChronicDuration = time.Hour
+			},
+			wantEvicted: nil,
+			wantEvents:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			controlCtx, err := katalystbase.GenerateFakeGenericContext(tt.kubeObjects, tt.cnrs)
+			require.NoError(t, err)
+
+			conf := newTestReschedulingConfig()
+			if tt.confFunc != nil {
+				tt.confFunc(conf)
+			}
+
+			genericConf := &generic.GenericConfiguration{QoSConfiguration: generic.NewQoSConfiguration()}
+			r, err := NewRescheduler(ctx, controlCtx, genericConf, nil, conf)
+			require.NoError(t, err)
+
+			ejector := &fakePodEjector{err: tt.ejectErr}
+			recorder := events.NewFakeRecorder(10)
+			r.podEjector = ejector
+			r.recorder = recorder
+
+			controlCtx.StartInformer(ctx)
+			require.True(t, cache.WaitForCacheSync(ctx.Done(), r.nodeListerSynced, r.podListerSynced,
+				r.cnrListerSynced, r.npdListerSynced))
+
+			r.reschedule()
+			assert.Equal(t, tt.wantEvicted, ejector.evicted)
+			assert.Equal(t, tt.wantEvents, len(recorder.Events))
+		})
+	}
+}
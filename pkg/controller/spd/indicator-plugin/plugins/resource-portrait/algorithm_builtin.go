@@ -0,0 +1,401 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource_portrait
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+// in-tree algorithm methods, which forecast in process without any external algorithm serving.
+const (
+	ResourcePortraitMethodSeasonalNaive = "seasonal-naive"
+	ResourcePortraitMethodHoltWinters   = "holt-winters"
+	ResourcePortraitMethodQuantile      = "same-time-quantile"
+)
+
+// default params of in-tree algorithms, besides the ones shared with prediction provider.
+const (
+	builtinInputKeySeasonality   = "seasonality"
+	builtinInputValueSeasonality = "24h"
+
+	// builtinInputKeyWindow is the width of the same-time window around each
+	// predicted point, within which history samples of every season are collected.
+	builtinInputKeyWindow   = "window"
+	builtinInputValueWindow = "1h"
+
+	builtinInputKeyAlpha   = "alpha"
+	builtinInputValueAlpha = "0.3"
+	builtinInputKeyBeta    = "beta"
+	builtinInputValueBeta  = "0.05"
+	builtinInputKeyGamma   = "gamma"
+	builtinInputValueGamma = "0.3"
+)
+
+var defaultBuiltinInputMap = map[string]string{
+	defaultPredictionInputKeyQuantile: defaultPredictionInputValueQuantile,
+	defaultPredictionInputKeyDuration: defaultPredictionInputValueDuration,
+	defaultPredictionInputKeySteps:    defaultPredictionInputValueSteps,
+	builtinInputKeySeasonality:        builtinInputValueSeasonality,
+	builtinInputKeyWindow:             builtinInputValueWindow,
+	builtinInputKeyAlpha:              builtinInputValueAlpha,
+	builtinInputKeyBeta:               builtinInputValueBeta,
+	builtinInputKeyGamma:              builtinInputValueGamma,
+}
+
+// builtinConfig is the parsed params of in-tree algorithms, durations are in seconds.
+type builtinConfig struct {
+	interval    int64
+	steps       int
+	seasonality int64
+	window      int64
+	quantile    float64
+	alpha       float64
+	beta        float64
+	gamma       float64
+}
+
+// forecastFunc predicts values of the given timestamps based on history series.
+type forecastFunc func(series *regularSeries, cfg *builtinConfig, timestamps []int64) ([]float64, error)
+
+// builtinProviderImpl implements AlgorithmProvider with in-process forecasting, it
+// produces predictions at the same timestamps as the external prediction service,
+// and reports the strength of seasonality of each metric as periodicity.
+type builtinProviderImpl struct {
+	method   string
+	forecast forecastFunc
+	now      func() time.Time
+
+	Cfg     map[string]string
+	Metrics map[string][]timeSeriesItem
+}
+
+func newBuiltinProviderFunc(method string, forecast forecastFunc) func(string) AlgorithmProvider {
+	return func(_ string) AlgorithmProvider {
+		return &builtinProviderImpl{
+			method:   method,
+			forecast: forecast,
+			now:      time.Now,
+		}
+	}
+}
+
+func (p *builtinProviderImpl) Method() string {
+	return p.method
+}
+
+func (p *builtinProviderImpl) SetConfig(cfg map[string]string) {
+	p.Cfg = cfg
+}
+
+func (p *builtinProviderImpl) SetMetrics(metrics map[string][]model.SamplePair) {
+	p.Metrics = convertSamplePairsToTimeSeries(metrics)
+}
+
+func (p *builtinProviderImpl) Call() (map[string][]timeSeriesItem, map[string]float64, error) {
+	cfg, err := parseBuiltinConfig(p.Cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Align the start time of predicted data to whole minutes, which is the same as prediction provider.
+	start := p.now().Unix() / 60 * 60
+	timestamps := make([]int64, 0, cfg.steps)
+	for i := 0; i < cfg.steps; i++ {
+		timestamps = append(timestamps, start+int64(i)*cfg.interval)
+	}
+
+	timeSeries := make(map[string][]timeSeriesItem, len(p.Metrics))
+	periodicity := make(map[string]float64, len(p.Metrics))
+	for name, items := range p.Metrics {
+		series, err := newRegularSeries(items)
+		if err != nil {
+			return nil, nil, fmt.Errorf("metric %s: %v", name, err)
+		}
+
+		values, err := p.forecast(series, cfg, timestamps)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s forecast metric %s failed: %v", p.method, name, err)
+		}
+
+		predictions := make([]timeSeriesItem, 0, len(values))
+		for i, value := range values {
+			predictions = append(predictions, timeSeriesItem{Timestamp: timestamps[i], Value: math.Max(value, 0)})
+		}
+		timeSeries[name] = predictions
+
+		if strength, ok := series.seasonalStrength(cfg.seasonality); ok {
+			periodicity[periodicityMetricsPrefix+name] = strength
+		}
+	}
+	return timeSeries, periodicity, nil
+}
+
+func parseBuiltinConfig(cfg map[string]string) (*builtinConfig, error) {
+	cfg = general.MergeMap(defaultBuiltinInputMap, cfg)
+
+	parseSeconds := func(key string) (int64, error) {
+		d, err := time.ParseDuration(cfg[key])
+		if err != nil {
+			return 0, err
+		} else if d < time.Second {
+			return 0, fmt.Errorf("%s should be at least 1s, got %v", key, d)
+		}
+		return int64(d.Seconds()), nil
+	}
+	parseRatio := func(key string) (float64, error) {
+		f, err := strconv.ParseFloat(cfg[key], 64)
+		if err != nil {
+			return 0, err
+		} else if f < 0 || f > 1 {
+			return 0, fmt.Errorf("%s should be in [0, 1], got %v", key, f)
+		}
+		return f, nil
+	}
+
+	var (
+		c   = &builtinConfig{}
+		err error
+	)
+	if c.interval, err = parseSeconds(defaultPredictionInputKeyDuration); err != nil {
+		return nil, err
+	}
+	if c.seasonality, err = parseSeconds(builtinInputKeySeasonality); err != nil {
+		return nil, err
+	}
+	if c.window, err = parseSeconds(builtinInputKeyWindow); err != nil {
+		return nil, err
+	}
+	if c.steps, err = strconv.Atoi(cfg[defaultPredictionInputKeySteps]); err != nil {
+		return nil, err
+	}
+	if c.quantile, err = parseRatio(defaultPredictionInputKeyQuantile); err != nil {
+		return nil, err
+	}
+	if c.alpha, err = parseRatio(builtinInputKeyAlpha); err != nil {
+		return nil, err
+	}
+	if c.beta, err = parseRatio(builtinInputKeyBeta); err != nil {
+		return nil, err
+	}
+	if c.gamma, err = parseRatio(builtinInputKeyGamma); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// seasonalNaiveForecast takes the value of the latest history at the same time of the season.
+func seasonalNaiveForecast(series *regularSeries, cfg *builtinConfig, timestamps []int64) ([]float64, error) {
+	values := make([]float64, 0, len(timestamps))
+	for _, ts := range timestamps {
+		source := ts
+		if ts > series.end() {
+			source -= (ts - series.end() + cfg.seasonality - 1) / cfg.seasonality * cfg.seasonality
+		}
+
+		value, ok := series.valueAt(source)
+		if !ok {
+			return nil, fmt.Errorf("history shorter than seasonality %vs", cfg.seasonality)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// holtWintersForecast is the additive triple exponential smoothing, which needs
+// at least two full seasons of history to initialize level, trend and seasonal components.
+func holtWintersForecast(series *regularSeries, cfg *builtinConfig, timestamps []int64) ([]float64, error) {
+	m := int(cfg.seasonality / series.interval)
+	n := len(series.values)
+	if m < 2 || n < 2*m {
+		return nil, fmt.Errorf("holt-winters needs at least two seasons of history, got %d points with %d per season", n, m)
+	}
+
+	firstMean, secondMean := mean(series.values[:m]), mean(series.values[m:2*m])
+	level := firstMean
+	trend := (secondMean - firstMean) / float64(m)
+	seasonal := make([]float64, m)
+	for i := 0; i < m; i++ {
+		seasonal[i] = series.values[i] - firstMean
+	}
+
+	for i := m; i < n; i++ {
+		x, s := series.values[i], seasonal[i%m]
+		lastLevel := level
+		level = cfg.alpha*(x-s) + (1-cfg.alpha)*(level+trend)
+		trend = cfg.beta*(level-lastLevel) + (1-cfg.beta)*trend
+		seasonal[i%m] = cfg.gamma*(x-level) + (1-cfg.gamma)*s
+	}
+
+	values := make([]float64, 0, len(timestamps))
+	for _, ts := range timestamps {
+		h := int((ts - series.end()) / series.interval)
+		if h < 1 {
+			h = 1
+		}
+		values = append(values, level+float64(h)*trend+seasonal[(n-1+h)%m])
+	}
+	return values, nil
+}
+
+// quantileForecast takes the quantile of history samples, which are in the same
+// time window of every season as the predicted timestamp.
+func quantileForecast(series *regularSeries, cfg *builtinConfig, timestamps []int64) ([]float64, error) {
+	values := make([]float64, 0, len(timestamps))
+	for _, ts := range timestamps {
+		offset := ts % cfg.seasonality
+
+		var samples []float64
+		for i, value := range series.values {
+			distance := abs(series.timestamp(i)%cfg.seasonality - offset)
+			if distance > cfg.seasonality-distance {
+				distance = cfg.seasonality - distance
+			}
+			if distance*2 <= cfg.window {
+				samples = append(samples, value)
+			}
+		}
+		if len(samples) == 0 {
+			return nil, fmt.Errorf("no history in the same time window of %v", time.Unix(ts, 0))
+		}
+
+		values = append(values, quantile(samples, cfg.quantile))
+	}
+	return values, nil
+}
+
+// regularSeries is the history with a fixed interval, gaps are filled with the previous value.
+type regularSeries struct {
+	start    int64
+	interval int64
+	values   []float64
+}
+
+func newRegularSeries(items []timeSeriesItem) (*regularSeries, error) {
+	if len(items) < 2 {
+		return nil, fmt.Errorf("at least 2 history points are required, got %d", len(items))
+	}
+
+	sorted := make([]timeSeriesItem, len(items))
+	copy(sorted, items)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Timestamp < sorted[j].Timestamp })
+
+	diffs := make([]float64, 0, len(sorted)-1)
+	for i := 1; i < len(sorted); i++ {
+		if diff := sorted[i].Timestamp - sorted[i-1].Timestamp; diff > 0 {
+			diffs = append(diffs, float64(diff))
+		}
+	}
+	if len(diffs) == 0 {
+		return nil, fmt.Errorf("history points have the same timestamp")
+	}
+
+	series := &regularSeries{
+		start:    sorted[0].Timestamp,
+		interval: int64(quantile(diffs, 0.5)),
+	}
+	size := int((sorted[len(sorted)-1].Timestamp-series.start)/series.interval) + 1
+	series.values = make([]float64, size)
+	filled := make([]bool, size)
+	for _, item := range sorted {
+		idx := int((item.Timestamp - series.start + series.interval/2) / series.interval)
+		if idx >= size {
+			idx = size - 1
+		}
+		series.values[idx], filled[idx] = item.Value, true
+	}
+	for i := 1; i < size; i++ {
+		if !filled[i] {
+			series.values[i] = series.values[i-1]
+		}
+	}
+	return series, nil
+}
+
+func (s *regularSeries) timestamp(i int) int64 {
+	return s.start + int64(i)*s.interval
+}
+
+func (s *regularSeries) end() int64 {
+	return s.timestamp(len(s.values) - 1)
+}
+
+func (s *regularSeries) valueAt(ts int64) (float64, bool) {
+	if ts < s.start-s.interval/2 || ts > s.end()+s.interval/2 {
+		return 0, false
+	}
+	idx := int((ts - s.start + s.interval/2) / s.interval)
+	if idx >= len(s.values) {
+		idx = len(s.values) - 1
+	}
+	return s.values[idx], true
+}
+
+// seasonalStrength is the autocorrelation of the series at the lag of seasonality.
+func (s *regularSeries) seasonalStrength(seasonality int64) (float64, bool) {
+	lag := int(seasonality / s.interval)
+	if lag < 1 || lag >= len(s.values) {
+		return 0, false
+	}
+
+	avg := mean(s.values)
+	var numerator, denominator float64
+	for i, value := range s.values {
+		denominator += (value - avg) * (value - avg)
+		if i+lag < len(s.values) {
+			numerator += (value - avg) * (s.values[i+lag] - avg)
+		}
+	}
+	if denominator == 0 {
+		return 0, false
+	}
+	return numerator / denominator, true
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// quantile returns the q-quantile of values by linear interpolation.
+func quantile(values []float64, q float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
+
+func abs(x int64) int64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
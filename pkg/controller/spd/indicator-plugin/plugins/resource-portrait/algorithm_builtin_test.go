@@ -0,0 +1,186 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource_portrait
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func periodicValue(ts int64) float64 {
+	return 10 + 5*math.Sin(2*math.Pi*float64(ts%3600)/3600)
+}
+
+func generatePeriodicSamples(now time.Time, history time.Duration) []model.SamplePair {
+	var samples []model.SamplePair
+	for ts := now.Add(-history).Unix() / 60 * 60; ts < now.Unix(); ts += 60 {
+		samples = append(samples, model.SamplePair{
+			Timestamp: model.Time(ts * 1000),
+			Value:     model.SampleValue(periodicValue(ts)),
+		})
+	}
+	return samples
+}
+
+func TestParseBuiltinConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cfg     map[string]string
+		want    *builtinConfig
+		wantErr bool
+	}{
+		{
+			name: "default",
+			cfg:  nil,
+			want: &builtinConfig{
+				interval:    60,
+				steps:       120,
+				seasonality: 86400,
+				window:      3600,
+				quantile:    0.99,
+				alpha:       0.3,
+				beta:        0.05,
+				gamma:       0.3,
+			},
+		},
+		{
+			name:    "invalid quantile",
+			cfg:     map[string]string{"quantile": "1.5"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid seasonality",
+			cfg:     map[string]string{"seasonality": "1ms"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseBuiltinConfig(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewRegularSeries(t *testing.T) {
+	t.Parallel()
+
+	series, err := newRegularSeries([]timeSeriesItem{
+		{Timestamp: 240, Value: 4},
+		{Timestamp: 0, Value: 0},
+		{Timestamp: 60, Value: 1},
+		{Timestamp: 180, Value: 3},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), series.start)
+	assert.Equal(t, int64(60), series.interval)
+	assert.Equal(t, []float64{0, 1, 1, 3, 4}, series.values)
+	assert.Equal(t, int64(240), series.end())
+
+	_, err = newRegularSeries([]timeSeriesItem{{Timestamp: 0, Value: 0}})
+	assert.Error(t, err)
+}
+
+func TestBuiltinAlgorithmProvider_Call(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+	cfg := map[string]string{
+		"seasonality": "1h",
+		"duration":    "1m",
+		"step":        "30",
+		"window":      "2m",
+		"quantile":    "0.5",
+	}
+
+	tests := []struct {
+		name      string
+		method    string
+		history   time.Duration
+		tolerance float64
+		wantErr   bool
+	}{
+		{
+			name:      "seasonal naive",
+			method:    ResourcePortraitMethodSeasonalNaive,
+			history:   3 * time.Hour,
+			tolerance: 0.01,
+		},
+		{
+			name:      "holt winters",
+			method:    ResourcePortraitMethodHoltWinters,
+			history:   3 * time.Hour,
+			tolerance: 0.5,
+		},
+		{
+			name:      "same time quantile",
+			method:    ResourcePortraitMethodQuantile,
+			history:   3 * time.Hour,
+			tolerance: 0.1,
+		},
+		{
+			name:    "holt winters with insufficient history",
+			method:  ResourcePortraitMethodHoltWinters,
+			history: 90 * time.Minute,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := NewAlgorithmProvider("", tt.method)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.method, p.Method())
+			p.(*builtinProviderImpl).now = func() time.Time { return now }
+
+			p.SetConfig(cfg)
+			p.SetMetrics(map[string][]model.SamplePair{"cpu_utilization_usage_seconds_max": generatePeriodicSamples(now, tt.history)})
+			timeSeries, groupData, err := p.Call()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			predictions := timeSeries["cpu_utilization_usage_seconds_max"]
+			assert.Equal(t, 30, len(predictions))
+			for i, item := range predictions {
+				assert.Equal(t, now.Unix()/60*60+int64(i)*60, item.Timestamp)
+				assert.InDelta(t, periodicValue(item.Timestamp), item.Value, tt.tolerance)
+			}
+			assert.Greater(t, groupData["periodicity-cpu_utilization_usage_seconds_max"], 0.5)
+		})
+	}
+}
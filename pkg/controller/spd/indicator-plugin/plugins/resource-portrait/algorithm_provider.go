@@ -63,6 +63,9 @@ func NewAlgorithmProvider(addr, algorithmType string) (AlgorithmProvider, error)
 
 func init() {
 	register(ResourcePortraitMethodPredict, newPredictionProvider)
+	register(ResourcePortraitMethodSeasonalNaive, newBuiltinProviderFunc(ResourcePortraitMethodSeasonalNaive, seasonalNaiveForecast))
+	register(ResourcePortraitMethodHoltWinters, newBuiltinProviderFunc(ResourcePortraitMethodHoltWinters, holtWintersForecast))
+	register(ResourcePortraitMethodQuantile, newBuiltinProviderFunc(ResourcePortraitMethodQuantile, quantileForecast))
 }
 
 // predictProviderImpl is used to call the time series prediction algorithm based on the given
@@ -142,18 +145,7 @@ func (p *predictProviderImpl) SetConfig(cfg map[string]string) {
 }
 
 func (p *predictProviderImpl) SetMetrics(metrics map[string][]model.SamplePair) {
-	p.Metrics = map[string][]timeSeriesItem{}
-	for k, v := range metrics {
-		var items []timeSeriesItem
-		for _, item := range v {
-			items = append(items, timeSeriesItem{
-				// from milliseconds to second
-				Timestamp: int64(item.Timestamp) / 1000,
-				Value:     float64(item.Value),
-			})
-		}
-		p.Metrics[k] = items
-	}
+	p.Metrics = convertSamplePairsToTimeSeries(metrics)
 }
 
 func (p *predictProviderImpl) Call() (map[string][]timeSeriesItem, map[string]float64, error) {
@@ -234,6 +226,23 @@ func generatePredictionInput(cfg map[string]string, historyMetric map[string][]t
 	}, nil
 }
 
+// convertSamplePairsToTimeSeries converts prometheus samples into time series used by algorithm providers.
+func convertSamplePairsToTimeSeries(metrics map[string][]model.SamplePair) map[string][]timeSeriesItem {
+	timeSeries := map[string][]timeSeriesItem{}
+	for k, v := range metrics {
+		var items []timeSeriesItem
+		for _, item := range v {
+			items = append(items, timeSeriesItem{
+				// from milliseconds to second
+				Timestamp: int64(item.Timestamp) / 1000,
+				Value:     float64(item.Value),
+			})
+		}
+		timeSeries[k] = items
+	}
+	return timeSeries
+}
+
 // generateBodyByteReader is used to generate the body of http request
 func generateBodyByteReader(cfg map[string]string, historyMetric map[string][]timeSeriesItem, convertFunc func(map[string]string, map[string][]timeSeriesItem) (interface{}, error)) (*bytes.Reader, error) {
 	bbr, err := convertFunc(cfg, historyMetric)
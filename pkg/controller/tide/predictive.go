@@ -0,0 +1,501 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tide
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	promapiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	apis "github.com/kubewharf/katalyst-api/pkg/apis/tide/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+)
+
+var (
+	// AnnotationPredictiveMode enables predictive mode for the node pool if it's "true".
+	// Demand is forecast by the history of online demand in prometheus if it's configured,
+	// and by the daily profile of online demand observed by this controller otherwise.
+	AnnotationPredictiveMode = labelPrefix + "/" + "predictive-mode"
+	// AnnotationPredictiveLeadTime is how long ahead of forecast peaks tide nodes are converted to online
+	AnnotationPredictiveLeadTime = labelPrefix + "/" + "predictive-lead-time"
+	// AnnotationPredictiveConfidenceMargin is the ratio added to forecast online demand
+	AnnotationPredictiveConfidenceMargin = labelPrefix + "/" + "predictive-confidence-margin"
+
+	// AnnotationOnlineDemandProfile keeps historical online demand (cpu requests) of the node pool
+	// observed by this controller, and it's used when there is no demand history in prometheus
+	AnnotationOnlineDemandProfile = labelPrefix + "/" + "online-demand-profile"
+	// AnnotationPredictedOnlineDemand and AnnotationActualOnlineDemand report the predicted online cpu demand
+	// in lead time and the actual one, since there are no such fields in TideNodePoolStatus
+	AnnotationPredictedOnlineDemand = labelPrefix + "/" + "predicted-online-demand"
+	AnnotationActualOnlineDemand    = labelPrefix + "/" + "actual-online-demand"
+)
+
+const (
+	defaultPredictiveLeadTime         = 15 * time.Minute
+	defaultPredictiveConfidenceMargin = 0.1
+
+	// online demand is profiled by the peak in each slot of a day
+	demandProfileSlotDuration = 15 * time.Minute
+	demandProfileSlotCount    = int(24 * time.Hour / demandProfileSlotDuration)
+	// demandProfileDecay is the weight of the latest day when peaks of previous days are merged
+	demandProfileDecay = 0.5
+
+	demandProfileDateLayout = "2006-01-02"
+
+	metricsNameTideOnlineDemand = "tide_online_demand"
+
+	demandSourceMetrics = "metrics"
+	demandSourceProfile = "profile"
+
+	onlineDemandQueryNodePool = "${node_pool}"
+	onlineDemandQueryFilters  = "${filters}"
+)
+
+// DemandForecaster forecasts online cpu demand (in milli-cores) of a node pool;
+// it's fed with the actual demand observed in each tick.
+type DemandForecaster interface {
+	// Observe records the actual demand at now, and returns true if the forecaster is changed
+	Observe(now time.Time, demand int64) bool
+	// Forecast returns the max demand expected in [now, now+leadTime],
+	// and false if there is no history to forecast from
+	Forecast(now time.Time, leadTime time.Duration) (int64, bool)
+}
+
+// demandSlot keeps the peak demand of a time slot observed on Date, and the merged peaks of previous days
+type demandSlot struct {
+	Date    string `json:"d,omitempty"`
+	Peak    int64  `json:"p,omitempty"`
+	History int64  `json:"h,omitempty"`
+}
+
+// dailyDemandProfile forecasts demand by peaks of the same time slots in previous days,
+// it's persisted in annotations of the node pool so that it survives restarts.
+type dailyDemandProfile struct {
+	Slots []demandSlot `json:"slots"`
+}
+
+var _ DemandForecaster = &dailyDemandProfile{}
+
+func newDailyDemandProfile(data string) *dailyDemandProfile {
+	profile := &dailyDemandProfile{}
+	if data != "" {
+		if err := json.Unmarshal([]byte(data), profile); err != nil {
+			klog.Warningf("invalid online demand profile, reset it: %v", err)
+		}
+	}
+
+	if len(profile.Slots) != demandProfileSlotCount {
+		profile.Slots = make([]demandSlot, demandProfileSlotCount)
+	}
+	return profile
+}
+
+func (p *dailyDemandProfile) String() string {
+	data, _ := json.Marshal(p)
+	return string(data)
+}
+
+func getDemandSlotIndex(t time.Time) int {
+	return (t.Hour()*60 + t.Minute()) / int(demandProfileSlotDuration/time.Minute)
+}
+
+func (p *dailyDemandProfile) Observe(now time.Time, demand int64) bool {
+	slot := &p.Slots[getDemandSlotIndex(now)]
+	date := now.Format(demandProfileDateLayout)
+
+	if slot.Date != date {
+		// the first observation of this slot today, merge peak of the last observed day into history
+		if slot.Date != "" {
+			if slot.History == 0 {
+				slot.History = slot.Peak
+			} else {
+				slot.History = int64(math.Round(demandProfileDecay*float64(slot.Peak) + (1-demandProfileDecay)*float64(slot.History)))
+			}
+		}
+		slot.Date = date
+		slot.Peak = demand
+		return true
+	}
+
+	if demand > slot.Peak {
+		slot.Peak = demand
+		return true
+	}
+	return false
+}
+
+func (p *dailyDemandProfile) Forecast(now time.Time, leadTime time.Duration) (int64, bool) {
+	var forecast int64
+	observed := false
+	for t := now; !t.After(now.Add(leadTime)); t = t.Add(demandProfileSlotDuration) {
+		slot := p.Slots[getDemandSlotIndex(t)]
+		if slot.Date != "" {
+			observed = true
+		}
+		if slot.History > forecast {
+			forecast = slot.History
+		}
+		if slot.Peak > forecast {
+			forecast = slot.Peak
+		}
+	}
+	return forecast, observed
+}
+
+// metricsDemandForecaster forecasts demand by peaks of the same periods in previous days, which are
+// queried from the history of online demand in prometheus (e.g. cpu requests of online pods exported
+// by kube-state-metrics), so that it works as soon as the node pool or the controller starts.
+type metricsDemandForecaster struct {
+	ctx        context.Context
+	promClient promapiv1.API
+	conf       *controller.TideConfig
+	// query is the online demand query of the node pool
+	query string
+
+	// the forecast is cached for a step, since the history doesn't change in between
+	cacheTime     time.Time
+	cacheLeadTime time.Duration
+	cacheDemand   int64
+	cacheOK       bool
+}
+
+var _ DemandForecaster = &metricsDemandForecaster{}
+
+func newMetricsDemandForecaster(ctx context.Context, promClient promapiv1.API, conf *controller.TideConfig,
+	nodePool string,
+) *metricsDemandForecaster {
+	filters := conf.DataSourcePromConfig.BaseFilter
+	if filters != "" {
+		filters = "," + filters
+	}
+
+	return &metricsDemandForecaster{
+		ctx:        ctx,
+		promClient: promClient,
+		conf:       conf,
+		query: strings.NewReplacer(onlineDemandQueryNodePool, nodePool,
+			onlineDemandQueryFilters, filters).Replace(conf.OnlineDemandQuery),
+	}
+}
+
+// Observe does nothing since the demand is recorded in prometheus
+func (f *metricsDemandForecaster) Observe(_ time.Time, _ int64) bool {
+	return false
+}
+
+// Forecast merges peaks of the same periods in previous days with decay, from the oldest day
+// to the latest one, just like dailyDemandProfile; days without history are skipped.
+func (f *metricsDemandForecaster) Forecast(now time.Time, leadTime time.Duration) (int64, bool) {
+	if leadTime == f.cacheLeadTime && !now.Before(f.cacheTime) && now.Sub(f.cacheTime) < f.conf.OnlineDemandHistoryStep {
+		return f.cacheDemand, f.cacheOK
+	}
+
+	var forecast int64
+	ok := false
+	for day := f.conf.OnlineDemandHistoryDays; day > 0; day-- {
+		peak, found, err := f.queryPeak(now.AddDate(0, 0, -day), leadTime)
+		if err != nil {
+			klog.Warningf("query online demand history of %v days ago failed: %v", day, err)
+			continue
+		} else if !found {
+			continue
+		}
+
+		if !ok {
+			forecast, ok = peak, true
+		} else {
+			forecast = int64(math.Round(demandProfileDecay*float64(peak) + (1-demandProfileDecay)*float64(forecast)))
+		}
+	}
+
+	f.cacheTime, f.cacheLeadTime, f.cacheDemand, f.cacheOK = now, leadTime, forecast, ok
+	return forecast, ok
+}
+
+// queryPeak returns the peak demand in [start, start+leadTime] in milli-cores
+func (f *metricsDemandForecaster) queryPeak(start time.Time, leadTime time.Duration) (int64, bool, error) {
+	window := leadTime
+	if window < f.conf.OnlineDemandHistoryStep {
+		window = f.conf.OnlineDemandHistoryStep
+	}
+	query := fmt.Sprintf("max_over_time((sum(%s))[%s:%s])", f.query,
+		model.Duration(window), model.Duration(f.conf.OnlineDemandHistoryStep))
+
+	ctx, cancel := context.WithTimeout(f.ctx, f.conf.DataSourcePromConfig.Timeout)
+	defer cancel()
+
+	result, warnings, err := f.promClient.Query(ctx, query, start.Add(window))
+	if len(warnings) != 0 {
+		klog.Warningf("query %v warnings: %v", query, warnings)
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	vector, ok := result.(model.Vector)
+	if !ok {
+		return 0, false, fmt.Errorf("unexpected result type %v of query %v", result.Type(), query)
+	} else if len(vector) == 0 || math.IsNaN(float64(vector[0].Value)) {
+		return 0, false, nil
+	}
+	return int64(math.Ceil(float64(vector[0].Value) * 1000)), true, nil
+}
+
+// getMetricsDemandForecaster returns the forecaster by demand history of the node pool,
+// or nil if prometheus isn't configured
+func (t *Tide) getMetricsDemandForecaster(nodePool string) *metricsDemandForecaster {
+	if t.promClient == nil {
+		return nil
+	}
+
+	t.forecasterMutex.Lock()
+	defer t.forecasterMutex.Unlock()
+
+	forecaster, ok := t.metricsForecasters[nodePool]
+	if !ok {
+		forecaster = newMetricsDemandForecaster(t.ctx, t.promClient, t.tideConf, nodePool)
+		t.metricsForecasters[nodePool] = forecaster
+	}
+	return forecaster
+}
+
+func (t *Tide) deleteMetricsDemandForecaster(nodePool string) {
+	t.forecasterMutex.Lock()
+	defer t.forecasterMutex.Unlock()
+
+	delete(t.metricsForecasters, nodePool)
+}
+
+// forecastOnlineDemand forecasts by the demand history in prometheus if there is any,
+// and falls back to the daily profile observed by the controller otherwise
+func (t *Tide) forecastOnlineDemand(nodePool string, profile *dailyDemandProfile, now time.Time,
+	leadTime time.Duration,
+) (int64, string) {
+	if forecaster := t.getMetricsDemandForecaster(nodePool); forecaster != nil {
+		if forecast, ok := forecaster.Forecast(now, leadTime); ok {
+			return forecast, demandSourceMetrics
+		}
+	}
+
+	forecast, _ := profile.Forecast(now, leadTime)
+	return forecast, demandSourceProfile
+}
+
+// predictiveConfig is the predictive mode configuration of a node pool
+type predictiveConfig struct {
+	leadTime         time.Duration
+	confidenceMargin float64
+}
+
+// getPredictiveConfig returns nil if predictive mode isn't enabled for the node pool
+func getPredictiveConfig(nodePool metav1.Object) (*predictiveConfig, error) {
+	annotations := nodePool.GetAnnotations()
+	if annotations[AnnotationPredictiveMode] != "true" {
+		return nil, nil
+	}
+
+	conf := &predictiveConfig{
+		leadTime:         defaultPredictiveLeadTime,
+		confidenceMargin: defaultPredictiveConfidenceMargin,
+	}
+	if value, ok := annotations[AnnotationPredictiveLeadTime]; ok {
+		leadTime, err := time.ParseDuration(value)
+		if err != nil || leadTime < 0 {
+			return nil, fmt.Errorf("invalid predictive lead time %q: %v", value, err)
+		}
+		conf.leadTime = leadTime
+	}
+	if value, ok := annotations[AnnotationPredictiveConfidenceMargin]; ok {
+		margin, err := strconv.ParseFloat(value, 64)
+		if err != nil || margin < 0 {
+			return nil, fmt.Errorf("invalid predictive confidence margin %q: %v", value, err)
+		}
+		conf.confidenceMargin = margin
+	}
+	return conf, nil
+}
+
+// getPredictedOnlineDemand returns the predicted online demand reported in the node pool
+func getPredictedOnlineDemand(nodePool metav1.Object) int64 {
+	if conf, err := getPredictiveConfig(nodePool); err != nil || conf == nil {
+		return 0
+	}
+
+	demand, err := strconv.ParseInt(nodePool.GetAnnotations()[AnnotationPredictedOnlineDemand], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return demand
+}
+
+// getOnlineDemand returns cpu requests of online pods on nodes of the pool and pending online pods
+func getOnlineDemand(nodes []*corev1.Node, pods []*corev1.Pod, onlinePodChecker OnlinePodChecker) int64 {
+	poolNodes := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		poolNodes[node.Name] = true
+	}
+
+	var demand int64
+	for _, pod := range pods {
+		if !onlinePodChecker(pod) || native.PodIsTerminated(pod) {
+			continue
+		}
+
+		if poolNodes[pod.Spec.NodeName] || checkPendingOnlinePod(pod, onlinePodChecker) {
+			requests := native.SumUpPodRequestResources(pod)
+			demand += requests.Cpu().MilliValue()
+		}
+	}
+	return demand
+}
+
+// getOnlineCapacity returns allocatable cpu of online reserve and online tide nodes
+func getOnlineCapacity(nodes []*corev1.Node, tideNodePool NodePoolWrapper) int64 {
+	var capacity int64
+	for _, node := range nodes {
+		nodeLabels := labels.Set(node.GetLabels())
+		if tideNodePool.GetOnlineReserveNodeSelector().Matches(nodeLabels) ||
+			tideNodePool.GetOnlineTideNodeSelector().Matches(nodeLabels) {
+			capacity += node.Status.Allocatable.Cpu().MilliValue()
+		}
+	}
+	return capacity
+}
+
+// RunPredictive records online demand of the node pool, and converts offline tide nodes to online
+// ahead of forecast peaks; it returns true if any node is converted. Releasing online tide nodes
+// after the peak is left to RunOnce, which keeps enough online capacity for the predicted demand.
+func (t *Tide) RunPredictive(ctx context.Context, onlinePodChecker OnlinePodChecker,
+	tideNodePool *apis.TideNodePool, nodes []*corev1.Node,
+) (bool, error) {
+	conf, err := getPredictiveConfig(tideNodePool)
+	if err != nil || conf == nil {
+		return false, err
+	}
+
+	logger := klog.FromContext(ctx).WithValues("tideNodePool", tideNodePool.GetName())
+	pods, err := t.podLister.List(labels.Everything())
+	if err != nil {
+		return false, err
+	}
+
+	now := t.now()
+	actual := getOnlineDemand(nodes, pods, onlinePodChecker)
+	profile := newDailyDemandProfile(tideNodePool.Annotations[AnnotationOnlineDemandProfile])
+	profile.Observe(now, actual)
+	forecast, source := t.forecastOnlineDemand(tideNodePool.Name, profile, now, conf.leadTime)
+	predicted := int64(math.Ceil(float64(forecast) * (1 + conf.confidenceMargin)))
+
+	if err := t.updateOnlineDemand(ctx, tideNodePool, profile, predicted, actual); err != nil {
+		return false, err
+	}
+
+	nodePoolWrapper := NewNodePoolWrapper(tideNodePool)
+	capacity := getOnlineCapacity(nodes, nodePoolWrapper)
+	logger.V(2).Info("online demand", "predicted", predicted, "source", source, "actual", actual, "capacity", capacity)
+	if capacity >= predicted {
+		return false, nil
+	}
+
+	clusterSnapshot, _, err := t.GetNodePoolInfo(nodes, onlinePodChecker)
+	if err != nil {
+		return false, err
+	}
+	offlineNodesInfos, err := getNodeUsageWithSelector(clusterSnapshot, []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory},
+		nodePoolWrapper.GetOfflineTideNodeSelector())
+	if err != nil {
+		return false, err
+	}
+
+	converted := false
+	for i := 0; i < len(offlineNodesInfos) && capacity < predicted; i++ {
+		node := t.changeNodeToOnline(offlineNodesInfos[i].node.DeepCopy(), nodePoolWrapper)
+		if _, err := t.client.KubeClient.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{}); err != nil {
+			return converted, fmt.Errorf("update node offline to online failed: %v", err)
+		}
+		capacity += node.Status.Allocatable.Cpu().MilliValue()
+		converted = true
+		logger.Info("pre-convert offline node to online for forecast demand", "node", node.Name,
+			"predicted", predicted, "capacity", capacity)
+	}
+
+	if capacity < predicted {
+		logger.Info("no more offline node for forecast demand", "predicted", predicted, "capacity", capacity)
+	}
+	return converted, nil
+}
+
+// updateOnlineDemand patches the demand profile, predicted and actual demand to annotations of the node pool
+func (t *Tide) updateOnlineDemand(ctx context.Context, tideNodePool *apis.TideNodePool,
+	profile *dailyDemandProfile, predicted, actual int64,
+) error {
+	_ = t.metricsEmitter.StoreInt64(metricsNameTideOnlineDemand, predicted, metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "nodePool", Val: tideNodePool.Name}, metrics.MetricTag{Key: "type", Val: "predicted"})
+	_ = t.metricsEmitter.StoreInt64(metricsNameTideOnlineDemand, actual, metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "nodePool", Val: tideNodePool.Name}, metrics.MetricTag{Key: "type", Val: "actual"})
+
+	annotations := map[string]string{
+		AnnotationOnlineDemandProfile:   profile.String(),
+		AnnotationPredictedOnlineDemand: strconv.FormatInt(predicted, 10),
+		AnnotationActualOnlineDemand:    strconv.FormatInt(actual, 10),
+	}
+
+	changed := false
+	for key, value := range annotations {
+		if tideNodePool.Annotations[key] != value {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := t.client.InternalClient.TideV1alpha1().TideNodePools().Patch(ctx, tideNodePool.Name,
+		types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("update online demand of node pool failed: %v", err)
+	}
+
+	if tideNodePool.Annotations == nil {
+		tideNodePool.Annotations = make(map[string]string)
+	}
+	for key, value := range annotations {
+		tideNodePool.Annotations[key] = value
+	}
+	return nil
+}
@@ -0,0 +1,330 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tide
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	promapiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	v1alpha12 "github.com/kubewharf/katalyst-api/pkg/apis/tide/v1alpha1"
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	datasourceprometheus "github.com/kubewharf/katalyst-core/pkg/util/datasource/prometheus"
+)
+
+func TestDailyDemandProfile(t *testing.T) {
+	t.Parallel()
+
+	day := func(d, hour, minute int) time.Time {
+		return time.Date(2024, 1, d, hour, minute, 0, 0, time.Local)
+	}
+
+	profile := newDailyDemandProfile("")
+	assert.True(t, profile.Observe(day(1, 9, 0), 1000))
+	assert.True(t, profile.Observe(day(1, 9, 5), 4000))
+	assert.False(t, profile.Observe(day(1, 9, 10), 2000), "lower demand doesn't change the peak")
+	assert.True(t, profile.Observe(day(1, 23, 50), 3000))
+
+	forecast := func(now time.Time, leadTime time.Duration) int64 {
+		demand, _ := profile.Forecast(now, leadTime)
+		return demand
+	}
+
+	// the peak at 9:00 yesterday is forecast 15 minutes ahead
+	assert.Equal(t, int64(4000), forecast(day(2, 8, 45), 15*time.Minute))
+	assert.Equal(t, int64(0), forecast(day(2, 8, 0), 15*time.Minute))
+	// forecast wraps around midnight
+	assert.Equal(t, int64(3000), forecast(day(2, 23, 40), 30*time.Minute))
+	_, ok := profile.Forecast(day(2, 12, 0), 15*time.Minute)
+	assert.False(t, ok, "nothing is observed around 12:00")
+
+	// peaks of previous days are merged with decay
+	assert.True(t, profile.Observe(day(2, 9, 0), 2000))
+	assert.True(t, profile.Observe(day(3, 9, 0), 1000))
+	assert.Equal(t, int64(3000), profile.Slots[getDemandSlotIndex(day(3, 9, 0))].History)
+
+	// profile is persisted and restored
+	restored := newDailyDemandProfile(profile.String())
+	assert.Equal(t, profile.Slots, restored.Slots)
+
+	invalid := newDailyDemandProfile("invalid")
+	assert.Equal(t, demandProfileSlotCount, len(invalid.Slots))
+}
+
+func newTestTideConfig() *controller.TideConfig {
+	return &controller.TideConfig{
+		DataSourcePromConfig: datasourceprometheus.PromConfig{
+			Timeout:    time.Minute,
+			BaseFilter: `cluster="c1"`,
+		},
+		OnlineDemandQuery:       `demand{node_pool="${node_pool}"${filters}}`,
+		OnlineDemandHistoryDays: 3,
+		OnlineDemandHistoryStep: time.Minute,
+	}
+}
+
+// newTestPromClient returns demand (in cores) of days before now by the query timestamps
+func newTestPromClient(now time.Time, demands map[int]float64, queries *[]string) promapiv1.API {
+	return &datasourceprometheus.MockPromAPIClient{
+		QueryFunc: func(_ context.Context, query string, ts time.Time) (model.Value, promapiv1.Warnings, error) {
+			*queries = append(*queries, query)
+			for d, demand := range demands {
+				if ts.Equal(now.AddDate(0, 0, -d).Add(15 * time.Minute)) {
+					return model.Vector{{Value: model.SampleValue(demand)}}, nil, nil
+				}
+			}
+			return model.Vector{}, nil, nil
+		},
+	}
+}
+
+func TestMetricsDemandForecaster(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 8, 8, 45, 0, 0, time.Local)
+	conf := newTestTideConfig()
+
+	var queries []string
+	forecaster := newMetricsDemandForecaster(context.Background(),
+		newTestPromClient(now, map[int]float64{3: 4, 2: 2.0005}, &queries), conf, "np1")
+	assert.False(t, forecaster.Observe(now, 1000))
+
+	demand, ok := forecaster.Forecast(now, 15*time.Minute)
+	assert.True(t, ok)
+	// peaks of previous days are merged with decay, and the day without history is skipped
+	assert.Equal(t, int64(3001), demand)
+	require.Equal(t, 3, len(queries))
+	assert.Equal(t, `max_over_time((sum(demand{node_pool="np1",cluster="c1"}))[15m:1m])`, queries[0])
+
+	// forecast is cached within a step
+	demand, ok = forecaster.Forecast(now.Add(30*time.Second), 15*time.Minute)
+	assert.True(t, ok)
+	assert.Equal(t, int64(3001), demand)
+	assert.Equal(t, 3, len(queries))
+
+	_, ok = forecaster.Forecast(now.Add(time.Minute), 15*time.Minute)
+	assert.False(t, ok, "no history for other periods")
+	assert.Equal(t, 6, len(queries))
+
+	failed := newMetricsDemandForecaster(context.Background(), &datasourceprometheus.MockPromAPIClient{
+		QueryFunc: func(context.Context, string, time.Time) (model.Value, promapiv1.Warnings, error) {
+			return nil, nil, fmt.Errorf("unavailable")
+		},
+	}, conf, "np1")
+	_, ok = failed.Forecast(now, 15*time.Minute)
+	assert.False(t, ok)
+}
+
+func TestTide_ForecastOnlineDemand(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 8, 8, 45, 0, 0, time.Local)
+	profile := newDailyDemandProfile("")
+	profile.Observe(now.AddDate(0, 0, -1).Add(10*time.Minute), 1500)
+
+	var queries []string
+	tide := &Tide{
+		ctx:                context.Background(),
+		tideConf:           newTestTideConfig(),
+		metricsForecasters: make(map[string]*metricsDemandForecaster),
+	}
+
+	demand, source := tide.forecastOnlineDemand("np1", profile, now, 15*time.Minute)
+	assert.Equal(t, int64(1500), demand)
+	assert.Equal(t, demandSourceProfile, source, "prometheus isn't configured")
+
+	tide.promClient = newTestPromClient(now, map[int]float64{1: 2}, &queries)
+	demand, source = tide.forecastOnlineDemand("np1", profile, now, 15*time.Minute)
+	assert.Equal(t, int64(2000), demand)
+	assert.Equal(t, demandSourceMetrics, source)
+
+	tide.deleteMetricsDemandForecaster("np1")
+	tide.promClient = newTestPromClient(now, nil, &queries)
+	demand, source = tide.forecastOnlineDemand("np1", profile, now, 15*time.Minute)
+	assert.Equal(t, int64(1500), demand)
+	assert.Equal(t, demandSourceProfile, source, "no history in prometheus")
+}
+
+func TestTide_RunPredictive(t1 *testing.T) {
+	t1.Parallel()
+
+	now := time.Date(2024, 1, 2, 8, 50, 0, 0, time.Local)
+	profile := newDailyDemandProfile("")
+	profile.Observe(time.Date(2024, 1, 1, 9, 0, 0, 0, time.Local), 1500)
+
+	nodePool := &v1alpha12.TideNodePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "np1",
+			Annotations: map[string]string{
+				AnnotationPredictiveMode:             "true",
+				AnnotationPredictiveLeadTime:         "15m",
+				AnnotationPredictiveConfidenceMargin: "0.2",
+				AnnotationOnlineDemandProfile:        profile.String(),
+			},
+		},
+		Spec: v1alpha12.TideNodePoolSpec{
+			NodeConfigs: v1alpha12.NodeConfigs{
+				NodeSelector: map[string]string{"test": "test"},
+			},
+		},
+	}
+	wrapper := NewNodePoolWrapper(nodePool.DeepCopy())
+
+	tests := []struct {
+		name                 string
+		nodePool             *v1alpha12.TideNodePool
+		demandHistory        map[int]float64
+		nodeList             []runtime.Object
+		podList              []runtime.Object
+		wantOnlineNodeCount  int
+		wantOfflineNodeCount int
+		wantPredicted        string
+		wantActual           string
+	}{
+		{
+			name:     "convert offline nodes to online ahead of forecast peak",
+			nodePool: nodePool.DeepCopy(),
+			nodeList: []runtime.Object{
+				buildTideNode(NewNodePoolWrapper(nodePool.DeepCopy()), "n1", 1000, 1000, true),
+				buildTideNode(NewNodePoolWrapper(nodePool.DeepCopy()), "n2", 1000, 1000, false),
+				buildTideNode(NewNodePoolWrapper(nodePool.DeepCopy()), "n3", 1000, 1000, false),
+			},
+			podList:              []runtime.Object{buildOnlinePod(NewNodePoolWrapper(nodePool.DeepCopy()), "p1", 500, 500)},
+			wantOnlineNodeCount:  2,
+			wantOfflineNodeCount: 1,
+			wantPredicted:        "1800",
+			wantActual:           "500",
+		},
+		{
+			name:     "keep online nodes for forecast peak",
+			nodePool: nodePool.DeepCopy(),
+			nodeList: []runtime.Object{
+				buildTideNode(NewNodePoolWrapper(nodePool.DeepCopy()), "n1", 1000, 1000, true),
+				buildTideNode(NewNodePoolWrapper(nodePool.DeepCopy()), "n2", 1000, 1000, true),
+			},
+			wantOnlineNodeCount:  2,
+			wantOfflineNodeCount: 0,
+			wantPredicted:        "1800",
+			wantActual:           "0",
+		},
+		{
+			name: "convert offline nodes by demand history right after restart",
+			nodePool: &v1alpha12.TideNodePool{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "np1",
+					Annotations: map[string]string{
+						AnnotationPredictiveMode:             "true",
+						AnnotationPredictiveLeadTime:         "15m",
+						AnnotationPredictiveConfidenceMargin: "0.2",
+					},
+				},
+				Spec: nodePool.Spec,
+			},
+			demandHistory: map[int]float64{1: 1.5},
+			nodeList: []runtime.Object{
+				buildTideNode(NewNodePoolWrapper(nodePool.DeepCopy()), "n1", 1000, 1000, true),
+				buildTideNode(NewNodePoolWrapper(nodePool.DeepCopy()), "n2", 1000, 1000, false),
+				buildTideNode(NewNodePoolWrapper(nodePool.DeepCopy()), "n3", 1000, 1000, false),
+			},
+			wantOnlineNodeCount:  2,
+			wantOfflineNodeCount: 1,
+			wantPredicted:        "1800",
+			wantActual:           "0",
+		},
+		{
+			name: "release online nodes without predictive mode",
+			nodePool: &v1alpha12.TideNodePool{
+				ObjectMeta: metav1.ObjectMeta{Name: "np1"},
+				Spec:       nodePool.Spec,
+			},
+			nodeList: []runtime.Object{
+				buildTideNode(NewNodePoolWrapper(nodePool.DeepCopy()), "n1", 1000, 1000, true),
+				buildTideNode(NewNodePoolWrapper(nodePool.DeepCopy()), "n2", 1000, 1000, true),
+			},
+			wantOnlineNodeCount:  1,
+			wantOfflineNodeCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t1.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			controlCtx, err := katalystbase.GenerateFakeGenericContext(append(tt.nodeList, tt.podList...), []runtime.Object{tt.nodePool})
+			require.NoError(t1, err)
+			t, err := NewTide(ctx, controlCtx, nil, nil, nil)
+			require.NoError(t1, err)
+			t.now = func() time.Time { return now }
+			if tt.demandHistory != nil {
+				var queries []string
+				t.tideConf = newTestTideConfig()
+				t.promClient = newTestPromClient(now, tt.demandHistory, &queries)
+			}
+
+			controlCtx.StartInformer(ctx)
+			require.True(t1, cache.WaitForCacheSync(ctx.Done(), t.nodeListerSynced, t.tideListerSynced, t.podListerSynced))
+
+			nodes, err := t.nodeLister.List(labels.Everything())
+			require.NoError(t1, err)
+			checker := func(pod *corev1.Pod) bool {
+				return labels.SelectorFromSet(map[string]string{LabelPodTypeKey: LabelOnlinePodValue}).Matches(labels.Set(pod.GetLabels()))
+			}
+
+			tideNodePool := tt.nodePool.DeepCopy()
+			converted, err := t.RunPredictive(ctx, checker, tideNodePool, nodes)
+			require.NoError(t1, err)
+			if !converted {
+				require.NoError(t1, t.RunOnce(ctx, checker, NewNodePoolWrapper(tideNodePool)))
+			}
+
+			nodeList, err := t.client.KubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+			require.NoError(t1, err)
+			onlineNodes, offlineNodes := 0, 0
+			for _, node := range nodeList.Items {
+				if wrapper.GetOnlineTideNodeSelector().Matches(labels.Set(node.Labels)) {
+					onlineNodes++
+				}
+				if wrapper.GetOfflineTideNodeSelector().Matches(labels.Set(node.Labels)) {
+					offlineNodes++
+				}
+			}
+			assert.Equal(t1, tt.wantOnlineNodeCount, onlineNodes)
+			assert.Equal(t1, tt.wantOfflineNodeCount, offlineNodes)
+
+			gotNodePool, err := t.client.InternalClient.TideV1alpha1().TideNodePools().Get(ctx, tt.nodePool.Name, metav1.GetOptions{})
+			require.NoError(t1, err)
+			assert.Equal(t1, tt.wantPredicted, gotNodePool.Annotations[AnnotationPredictedOnlineDemand])
+			assert.Equal(t1, tt.wantActual, gotNodePool.Annotations[AnnotationActualOnlineDemand])
+		})
+	}
+}
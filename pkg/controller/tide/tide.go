@@ -21,8 +21,10 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"sync"
 	"time"
 
+	promapiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -47,6 +49,7 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/client"
 	"github.com/kubewharf/katalyst-core/pkg/config/controller"
 	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/datasource/prometheus"
 	"github.com/kubewharf/katalyst-core/pkg/metrics"
 )
 
@@ -91,19 +94,38 @@ type Tide struct {
 
 	// metricsEmitter for emit metrics
 	metricsEmitter metrics.MetricEmitter
+
+	// promClient provides the history of online demand for predictive mode, and it's nil if not configured
+	promClient         promapiv1.API
+	tideConf           *controller.TideConfig
+	forecasterMutex    sync.Mutex
+	metricsForecasters map[string]*metricsDemandForecaster
+
+	now func() time.Time
 }
 
 func NewTide(ctx context.Context,
 	controlCtx *katalystbase.GenericContext,
 	_ *generic.GenericConfiguration,
 	_ *controller.GenericControllerConfiguration,
+	tideConf *controller.TideConfig,
 ) (*Tide, error) {
 	tide := &Tide{
-		ctx:    ctx,
-		client: controlCtx.Client,
+		ctx:                ctx,
+		client:             controlCtx.Client,
+		now:                time.Now,
+		tideConf:           tideConf,
+		metricsForecasters: make(map[string]*metricsDemandForecaster),
 		syncQueue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(),
 			tideControllerName),
 	}
+	if tideConf != nil && tideConf.DataSourcePromConfig.Address != "" {
+		promDatasource, err := prometheus.NewPrometheus(&tideConf.DataSourcePromConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create prometheus datasource: %v", err)
+		}
+		tide.promClient = promDatasource.GetPromClient()
+	}
 	checker, err := simulator.NewSchedulerBasedPredicateChecker(controlCtx.Client.KubeClient, ctx.Done())
 	if err != nil {
 		return nil, err
@@ -225,6 +247,7 @@ func (t *Tide) deleteTideNodePoolEventHandle(obj interface{}) {
 	}
 	klog.V(4).Infof("notice addition of tide node pool %s", c.Name)
 
+	t.deleteMetricsDemandForecaster(c.Name)
 	t.enqueueWorkItem(obj)
 }
 
@@ -419,6 +442,15 @@ func (t *Tide) Reconcile(ctx context.Context, tideNodePool *apis.TideNodePool) e
 		return onlineLabelSet.Matches(labels.Set(pod.GetLabels()))
 	}
 
+	converted, err := t.RunPredictive(ctx, onlinePodChecker, tideNodePool, nodes)
+	if err != nil {
+		klog.Errorf("try to convert node for forecast demand failed: %v", err)
+		return err
+	} else if converted {
+		// wait for node changes to be synced before balancing
+		return nil
+	}
+
 	if err := t.RunOnce(ctx,
 		onlinePodChecker,
 		nodePoolWrapper); err != nil {
@@ -602,6 +634,15 @@ func (t *Tide) RunOnce(ctx context.Context, onlinePodChecker OnlinePodChecker, t
 		return nil
 	}
 	onlineNodesInfo := onlineNodesInfos[0]
+	// keep enough online nodes for forecast demand in predictive mode
+	if predicted := getPredictedOnlineDemand(tideNodePool); predicted > 0 {
+		capacity := getOnlineCapacity(nodeList, tideNodePool) - onlineNodesInfo.node.Status.Allocatable.Cpu().MilliValue()
+		if capacity < predicted {
+			logger.Info("keep online node for forecast demand", "node", onlineNodesInfo.node.Name,
+				"predicted", predicted, "capacity", capacity)
+			return nil
+		}
+	}
 	podsInNode := onlineNodesInfo.allPods
 	nodeInfo, err := clusterSnapshot.NodeInfos().Get(onlineNodesInfo.node.Name)
 	if err != nil {
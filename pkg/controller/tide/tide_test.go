@@ -98,7 +98,7 @@ func TestTide_RunOnce(t1 *testing.T) {
 			if err != nil {
 				t1.Error(err)
 			}
-			t, err := NewTide(tt.args.ctx, controlCtx, nil, nil)
+			t, err := NewTide(tt.args.ctx, controlCtx, nil, nil, nil)
 			if err != nil {
 				t1.Error(err)
 			}
@@ -304,7 +304,7 @@ func TestTide_Reconcile(t1 *testing.T) {
 			if err != nil {
 				t1.Error(err)
 			}
-			t, err := NewTide(tt.args.ctx, controlCtx, nil, nil)
+			t, err := NewTide(tt.args.ctx, controlCtx, nil, nil, nil)
 			if err != nil {
 				t1.Error(err)
 			}
@@ -15,8 +15,9 @@ limitations under the License.
 */
 
 // Package collector is the package that collects metric data
-// from katalyst agents (current implement is a pulling model),
-// and it's responsible to push those data to data stores.
+// from katalyst agents (either by pulling from them or accepting
+// samples pushed by them), and it's responsible to push those data
+// to data stores.
 package collector
 
 // MetricCollector is a standard metric collector interface
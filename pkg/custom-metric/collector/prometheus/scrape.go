@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -185,7 +186,7 @@ func (s *ScrapeManager) scrape() {
 	}
 
 	klog.V(6).Infof("node %v parseContents size %v", s.node, len(buf.Bytes()))
-	mf, err = parseContents(buf)
+	mf, err = ParseContents(buf)
 	if err != nil {
 		klog.Errorf("node %v parseContents contents failed: %v", s.node, err)
 		return
@@ -194,47 +195,18 @@ func (s *ScrapeManager) scrape() {
 
 	s.Lock()
 	defer s.Unlock()
-	// we only cares about metric with valid contents and types
-	for _, v := range mf {
-		if v == nil || v.Name == nil || len(v.Metric) == 0 || v.Type == nil || *v.Type != dto.MetricType_GAUGE {
-			continue
+	RangeGaugeSamples(mf, func(hash uint64, name string, labels map[string]string, d *data.MetricData) {
+		if _, ok := s.storedSeriesMap[hash]; ok {
+			return
 		}
 
-		for _, m := range v.Metric {
-			if m == nil || m.Gauge == nil || m.Gauge.Value == nil {
-				continue
-			}
-
-			labels := parseLabels(m)
-
-			timestamp, ok := parseTimestamp(labels, m)
-			if !ok {
-				continue
-			}
-
-			// calculating hash does not need to consider timestamp
-			delete(labels, string(data.CustomMetricLabelKeyTimestamp))
-			hash := calculateHash(*v.Name, labels, m)
-			if _, ok := s.storedSeriesMap[hash]; ok {
-				continue
-			}
-
-			if _, ok := s.storedSeriesMap[hash]; !ok {
-				s.storedSeriesMap[hash] = &data.MetricSeries{
-					Name:   *v.Name,
-					Labels: labels,
-					Series: []*data.MetricData{},
-				}
-			}
-
-			totalMetricDataCount++
-			s.storedSeriesMap[hash].Series = append(s.storedSeriesMap[hash].Series, &data.MetricData{
-				Data:      *m.Gauge.Value,
-				Timestamp: timestamp,
-			})
-
+		s.storedSeriesMap[hash] = &data.MetricSeries{
+			Name:   name,
+			Labels: labels,
+			Series: []*data.MetricData{d},
 		}
-	}
+		totalMetricDataCount++
+	})
 }
 
 // fetch gets contents from prometheus http service.
@@ -283,8 +255,9 @@ func (s *ScrapeManager) fetch(ctx context.Context, w io.Writer) error {
 	return nil
 }
 
-// parseContents analyzes the contents scraped from prometheus http service.
-func parseContents(r io.Reader) (map[string]*dto.MetricFamily, error) {
+// ParseContents analyzes the prometheus formatted contents, either scraped
+// from prometheus http service or pushed by the agents.
+func ParseContents(r io.Reader) (map[string]*dto.MetricFamily, error) {
 	var parser expfmt.TextParser
 	mf, err := parser.TextToMetricFamilies(r)
 	if err != nil {
@@ -294,16 +267,55 @@ func parseContents(r io.Reader) (map[string]*dto.MetricFamily, error) {
 	return mf, nil
 }
 
+// RangeGaugeSamples walks through all valid gauge samples in the given metric families,
+// and calls f with each sample converted into standard formats. the hash identifies
+// the series that the sample belongs to (timestamp label excluded).
+func RangeGaugeSamples(mf map[string]*dto.MetricFamily, f func(hash uint64, name string, labels map[string]string, d *data.MetricData)) {
+	// we only cares about metric with valid contents and types
+	for _, v := range mf {
+		if v == nil || v.Name == nil || len(v.Metric) == 0 || v.Type == nil || *v.Type != dto.MetricType_GAUGE {
+			continue
+		}
+
+		for _, m := range v.Metric {
+			if m == nil || m.Gauge == nil || m.Gauge.Value == nil {
+				continue
+			}
+
+			labels := parseLabels(m)
+
+			timestamp, ok := parseTimestamp(labels, m)
+			if !ok {
+				continue
+			}
+
+			// calculating hash does not need to consider timestamp
+			delete(labels, string(data.CustomMetricLabelKeyTimestamp))
+			f(calculateHash(*v.Name, labels, m), *v.Name, labels, &data.MetricData{
+				Data:      *m.Gauge.Value,
+				Timestamp: timestamp,
+			})
+		}
+	}
+}
+
 // calculateHash makes sure that we won't store duplicated metric contents
 func calculateHash(name string, labels map[string]string, metric *dto.Metric) uint64 {
 	b := make([]byte, 0, 1024)
 	b = append(b, name...)
 
-	for k, v := range labels {
+	// labels must be iterated in a fixed order to get a stable hash
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
 		b = append(b, '\xff')
 		b = append(b, k...)
 		b = append(b, '\xff')
-		b = append(b, v...)
+		b = append(b, labels[k]...)
 	}
 
 	if metric.TimestampMs != nil {
@@ -24,6 +24,7 @@ import (
 	"testing"
 	"time"
 
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/kubewharf/katalyst-core/pkg/custom-metric/store/data"
@@ -137,3 +138,27 @@ without_timestamp{label_test="without_timestamp",namespace="n1",object="pod",obj
 
 	s.Stop()
 }
+
+func Test_calculateHash(t *testing.T) {
+	t.Parallel()
+
+	labels := map[string]string{}
+	for i := 0; i < 16; i++ {
+		labels[fmt.Sprintf("label_%v", i)] = fmt.Sprintf("value_%v", i)
+	}
+
+	// the same series must always get the same hash, since pushed series are merged by it,
+	// no matter how labels are iterated
+	expected := calculateHash("m1", labels, &dto.Metric{})
+	for i := 0; i < 100; i++ {
+		copied := make(map[string]string, len(labels))
+		for k, v := range labels {
+			copied[k] = v
+		}
+		assert.Equal(t, expected, calculateHash("m1", copied, &dto.Metric{}))
+	}
+
+	assert.NotEqual(t, expected, calculateHash("m2", labels, &dto.Metric{}))
+	labels["label_0"] = "changed"
+	assert.NotEqual(t, expected, calculateHash("m1", labels, &dto.Metric{}))
+}
@@ -0,0 +1,454 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package push
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/units"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	"github.com/kubewharf/katalyst-core/pkg/config/metric"
+	"github.com/kubewharf/katalyst-core/pkg/custom-metric/collector"
+	"github.com/kubewharf/katalyst-core/pkg/custom-metric/collector/prometheus"
+	"github.com/kubewharf/katalyst-core/pkg/custom-metric/store"
+	"github.com/kubewharf/katalyst-core/pkg/custom-metric/store/data"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/credential"
+)
+
+const MetricCollectorNamePush = "push-collector"
+
+// HTTPPushPath is the path that agents push metric samples to
+const HTTPPushPath = "/custom_metric/push"
+
+const (
+	metricNamePushCollectorSyncCosts    = "kcmas_collector_push_sync_costs"
+	metricNamePushCollectorPendingCount = "kcmas_collector_push_pending_cnt"
+
+	metricNamePushCollectorPushReqCount  = "kcmas_collector_push_req_cnt"
+	metricNamePushCollectorPushItemCount = "kcmas_collector_push_item_cnt"
+
+	metricNamePushCollectorStoreReqCount  = "kcmas_collector_push_store_req_cnt"
+	metricNamePushCollectorStoreItemCount = "kcmas_collector_push_store_item_cnt"
+)
+
+// those variables define the http-related configurations for pushing
+var (
+	httpBodyLimit         = int64(10 * units.MiB)
+	httpRetryAfterSeconds = "1"
+)
+
+// those const variables define how the node is identified from the pushing subject;
+// only node credentials are trusted, i.e. user system:node:<name> in group system:nodes.
+// service account tokens bound to pods also carry the node name in extra, but they
+// are owned by any workloads on the node, so they are never treated as the node.
+const (
+	nodeSubjectNamePrefix    = "system:node:"
+	nodeSubjectGroup         = "system:nodes"
+	metricObjectNameNodes    = "nodes"
+	metricObjectNamePods     = "pods"
+	unauthorizedObjectFormat = "%v is not allowed to push metrics of %v %v/%v"
+)
+
+// pushCollector implements MetricCollector by accepting samples pushed from agents
+// (in prometheus text formats) instead of scraping them, to avoid the scrape fan-out
+// of a single collector. pushed samples are buffered in memory and flushed into store
+// periodically; pushing requests will be rejected with 429 if the buffer or concurrency
+// exceeds the limits, and agents are expected to back off and retry.
+//
+// the pushing endpoint is served over tls with the same certificates as generic endpoint,
+// and the subject of each request is authorized against the objects of the pushed series.
+type pushCollector struct {
+	ctx         context.Context
+	collectConf *metric.CollectorConfiguration
+	genericConf *metric.GenericMetricConfiguration
+
+	server      *http.Server
+	tlsCertFile string
+	tlsKeyFile  string
+	cred        credential.Credential
+
+	// privilegedSubjects can push metrics of any objects, while other subjects are
+	// authorized by the node of pushed objects, which is got from podLister for pods.
+	privilegedSubjects sets.String
+	podLister          corelisters.PodLister
+	podSynced          cache.InformerSynced
+
+	emitter     metrics.MetricEmitter
+	metricStore store.MetricStore
+
+	// inflight works as a semaphore to limit concurrent pushing requests
+	inflight chan struct{}
+
+	// pendingSeries maps series hash to the buffered series, and pendingCount
+	// is the total count of metric data (i.e. samples) in it.
+	sync.Mutex
+	pendingCount  int
+	pendingSeries map[uint64]*data.MetricSeries
+}
+
+var _ collector.MetricCollector = &pushCollector{}
+
+func NewPushCollector(ctx context.Context, baseCtx *katalystbase.GenericContext, serverConf *generic.GenericConfiguration,
+	genericConf *metric.GenericMetricConfiguration, collectConf *metric.CollectorConfiguration,
+	cred credential.Credential, metricStore store.MetricStore,
+) (collector.MetricCollector, error) {
+	if collectConf.PushMaxPendingSamples <= 0 || collectConf.PushMaxInflightRequests <= 0 {
+		return nil, fmt.Errorf("invalid push limits, max pending samples: %v, max inflight requests: %v",
+			collectConf.PushMaxPendingSamples, collectConf.PushMaxInflightRequests)
+	}
+
+	// pushed samples and credentials should never be transferred in plain text
+	tlsConfig, err := katalystbase.GetTLSConfig(serverConf)
+	if err != nil {
+		return nil, err
+	} else if tlsConfig == nil {
+		return nil, fmt.Errorf("tls cert file must be set for push collector")
+	}
+
+	podInformer := baseCtx.KubeInformerFactory.Core().V1().Pods()
+	p := &pushCollector{
+		ctx:                ctx,
+		genericConf:        genericConf,
+		collectConf:        collectConf,
+		tlsCertFile:        serverConf.GenericEndpointTLSCertFile,
+		tlsKeyFile:         serverConf.GenericEndpointTLSKeyFile,
+		cred:               cred,
+		privilegedSubjects: sets.NewString(collectConf.PushPrivilegedSubjects...),
+		podLister:          podInformer.Lister(),
+		podSynced:          podInformer.Informer().HasSynced,
+		emitter:            baseCtx.EmitterPool.GetDefaultMetricsEmitter().WithTags("push_collector"),
+		metricStore:        metricStore,
+		inflight:           make(chan struct{}, collectConf.PushMaxInflightRequests),
+		pendingSeries:      make(map[uint64]*data.MetricSeries),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(HTTPPushPath, p.handlePush)
+	p.server = &http.Server{
+		Addr:      collectConf.PushListenAddress,
+		Handler:   mux,
+		TLSConfig: tlsConfig,
+	}
+
+	return p, nil
+}
+
+func (p *pushCollector) Name() string { return MetricCollectorNamePush }
+
+func (p *pushCollector) Start() error {
+	p.cred.Run(p.ctx)
+
+	go func() {
+		// pods must be synced before serving, otherwise pushing of pods can't be authorized
+		if !cache.WaitForCacheSync(p.ctx.Done(), p.podSynced) {
+			klog.Errorf("push collector failed to sync pods")
+			return
+		}
+
+		klog.Infof("push collector listening on %v", p.server.Addr)
+		if err := p.server.ListenAndServeTLS(p.tlsCertFile, p.tlsKeyFile); err != nil && err != http.ErrServerClosed {
+			klog.Errorf("push collector serving failed: %v", err)
+		}
+	}()
+
+	go wait.Until(p.sync, p.collectConf.SyncInterval, p.ctx.Done())
+	go wait.Until(p.gc, time.Second*10, p.ctx.Done())
+	return nil
+}
+
+func (p *pushCollector) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	return p.server.Shutdown(ctx)
+}
+
+// handlePush authenticates the pushing request, parses samples from its body,
+// authorizes the subject against the pushed objects and puts them into the
+// pending buffer if there is still capacity.
+func (p *pushCollector) handlePush(w http.ResponseWriter, r *http.Request) {
+	var (
+		code    = http.StatusNoContent
+		subject = "unknown"
+	)
+	defer func() {
+		_ = p.emitter.StoreInt64(metricNamePushCollectorPushReqCount, 1, metrics.MetricTypeNameCount, []metrics.MetricTag{
+			{Key: "code", Val: fmt.Sprintf("%v", code)},
+			{Key: "user", Val: subject},
+		}...)
+	}()
+
+	if r.Method != http.MethodPost {
+		code = http.StatusMethodNotAllowed
+		http.Error(w, fmt.Sprintf("method %v not allowed", r.Method), code)
+		return
+	}
+
+	// inflight slot is acquired before authentication, since it may call remote api (e.g. TokenReview)
+	select {
+	case p.inflight <- struct{}{}:
+		defer func() { <-p.inflight }()
+	default:
+		code = http.StatusTooManyRequests
+		w.Header().Set("Retry-After", httpRetryAfterSeconds)
+		http.Error(w, "too many inflight requests", code)
+		return
+	}
+
+	authInfo, err := p.cred.Auth(r)
+	if err != nil {
+		klog.Warningf("push request from %v doesn't have proper auth: %v", r.RemoteAddr, err)
+		code = http.StatusUnauthorized
+		http.Error(w, "unauthorized", code)
+		return
+	}
+	subject = authInfo.SubjectName()
+
+	seriesMap, count, err := p.parseBody(r)
+	if err != nil {
+		klog.Errorf("parse push request from %v failed: %v", subject, err)
+		code = http.StatusBadRequest
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	if err := p.authorize(authInfo, seriesMap); err != nil {
+		klog.Warningf("push request from %v is forbidden: %v", subject, err)
+		code = http.StatusForbidden
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	if !p.addPendingSeries(seriesMap, count) {
+		code = http.StatusTooManyRequests
+		w.Header().Set("Retry-After", httpRetryAfterSeconds)
+		http.Error(w, "too many pending samples", code)
+		return
+	}
+
+	_ = p.emitter.StoreInt64(metricNamePushCollectorPushItemCount, int64(count), metrics.MetricTypeNameCount,
+		metrics.MetricTag{Key: "user", Val: subject})
+	klog.V(6).Infof("push collector accepted %v series, %v metric data from %v", len(seriesMap), count, subject)
+	w.WriteHeader(code)
+}
+
+// authorize checks whether the subject is allowed to push all those series; privileged
+// subjects can push metrics of any objects, and node credentials can only push metrics of
+// themselves and pods on them. series without objects are cluster-scoped, so only privileged can push.
+func (p *pushCollector) authorize(authInfo credential.AuthInfo, seriesMap map[uint64]*data.MetricSeries) error {
+	subject := authInfo.SubjectName()
+	if p.privilegedSubjects.Has(subject) {
+		return nil
+	}
+
+	nodeName, ok := getSubjectNodeName(authInfo)
+	if !ok {
+		return fmt.Errorf("%v is neither privileged nor a node", subject)
+	}
+
+	for _, series := range seriesMap {
+		object := series.Labels[string(data.CustomMetricLabelKeyObject)]
+		namespace := series.Labels[string(data.CustomMetricLabelKeyNamespace)]
+		name := series.Labels[string(data.CustomMetricLabelKeyObjectName)]
+
+		switch object {
+		case metricObjectNameNodes:
+			if name != nodeName {
+				return fmt.Errorf(unauthorizedObjectFormat, subject, object, namespace, name)
+			}
+		case metricObjectNamePods:
+			pod, err := p.podLister.Pods(namespace).Get(name)
+			if err != nil || pod.Spec.NodeName != nodeName {
+				return fmt.Errorf(unauthorizedObjectFormat, subject, object, namespace, name)
+			}
+		default:
+			return fmt.Errorf(unauthorizedObjectFormat, subject, object, namespace, name)
+		}
+	}
+	return nil
+}
+
+// getSubjectNodeName returns the node that the subject is identified as by node credentials,
+// i.e. tokens or client certificates of user system:node:<name> in group system:nodes.
+func getSubjectNodeName(authInfo credential.AuthInfo) (string, bool) {
+	var groups []string
+	switch info := authInfo.(type) {
+	case credential.TokenReviewAuthInfo:
+		groups = info.Groups
+	case credential.CertificateAuthInfo:
+		groups = info.Organizations
+	default:
+		return "", false
+	}
+
+	if !sets.NewString(groups...).Has(nodeSubjectGroup) {
+		return "", false
+	}
+
+	if nodeName := strings.TrimPrefix(authInfo.SubjectName(), nodeSubjectNamePrefix); nodeName != authInfo.SubjectName() && nodeName != "" {
+		return nodeName, true
+	}
+	return "", false
+}
+
+// parseBody converts the (optionally gzipped) prometheus formatted body
+// into standard series, along with the total count of metric data.
+func (p *pushCollector) parseBody(r *http.Request) (map[uint64]*data.MetricSeries, int, error) {
+	body := io.Reader(r.Body)
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gzipR, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to init gzipR: %v", err)
+		}
+		defer func() { _ = gzipR.Close() }()
+		body = gzipR
+	}
+
+	// read one more byte to tell whether the limit has been exceeded
+	limitedBody := &io.LimitedReader{R: body, N: httpBodyLimit + 1}
+	mf, err := prometheus.ParseContents(limitedBody)
+	if limitedBody.N <= 0 {
+		return nil, 0, fmt.Errorf("body size limit exceeded")
+	} else if err != nil {
+		return nil, 0, err
+	}
+
+	count := 0
+	seriesMap := make(map[uint64]*data.MetricSeries)
+	prometheus.RangeGaugeSamples(mf, func(hash uint64, name string, labels map[string]string, d *data.MetricData) {
+		if _, ok := seriesMap[hash]; !ok {
+			seriesMap[hash] = &data.MetricSeries{
+				Name:   name,
+				Labels: labels,
+				Series: []*data.MetricData{},
+			}
+		}
+		seriesMap[hash].Series = append(seriesMap[hash].Series, d)
+		count++
+	})
+	return seriesMap, count, nil
+}
+
+// addPendingSeries merges series into pending buffer, and returns false
+// without any modification if the buffer will exceed its capacity.
+func (p *pushCollector) addPendingSeries(seriesMap map[uint64]*data.MetricSeries, count int) bool {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.pendingCount+count > p.collectConf.PushMaxPendingSamples {
+		return false
+	}
+
+	p.mergePendingSeries(seriesMap)
+	p.pendingCount += count
+	return true
+}
+
+// mergePendingSeries must be called with lock held
+func (p *pushCollector) mergePendingSeries(seriesMap map[uint64]*data.MetricSeries) {
+	for hash, series := range seriesMap {
+		if pending, ok := p.pendingSeries[hash]; ok {
+			pending.Series = append(pending.Series, series.Series...)
+		} else {
+			p.pendingSeries[hash] = series
+		}
+	}
+}
+
+// sync flushes the pending series into store, and those series will
+// be put back into the buffer to retry next time if it fails.
+func (p *pushCollector) sync() {
+	p.Lock()
+	seriesMap, count := p.pendingSeries, p.pendingCount
+	p.pendingSeries, p.pendingCount = make(map[uint64]*data.MetricSeries), 0
+	p.Unlock()
+
+	if len(seriesMap) == 0 {
+		return
+	}
+
+	syncStart := time.Now()
+	defer func() {
+		costs := time.Since(syncStart)
+		klog.Infof("push collector flushed with total %v series, cost %s", len(seriesMap), costs.String())
+		_ = p.emitter.StoreInt64(metricNamePushCollectorSyncCosts, costs.Microseconds(), metrics.MetricTypeNameRaw)
+	}()
+
+	seriesList := make([]*data.MetricSeries, 0, len(seriesMap))
+	for _, series := range seriesMap {
+		seriesList = append(seriesList, series)
+	}
+
+	if err := p.metricStore.InsertMetric(seriesList); err != nil {
+		klog.Errorf("push collector failed to flush %v series, %v metric data: %v", len(seriesMap), count, err)
+		_ = p.emitter.StoreInt64(metricNamePushCollectorStoreReqCount, 1, metrics.MetricTypeNameCount,
+			metrics.MetricTag{Key: "type", Val: "failed"})
+
+		// the newly pushed series are merged after the failed ones to keep data in order
+		p.Lock()
+		newSeriesMap := p.pendingSeries
+		p.pendingSeries = seriesMap
+		p.mergePendingSeries(newSeriesMap)
+		p.pendingCount += count
+		p.Unlock()
+		return
+	}
+
+	_ = p.emitter.StoreInt64(metricNamePushCollectorStoreReqCount, 1, metrics.MetricTypeNameCount,
+		metrics.MetricTag{Key: "type", Val: "succeeded"})
+	_ = p.emitter.StoreInt64(metricNamePushCollectorStoreItemCount, int64(count), metrics.MetricTypeNameCount)
+}
+
+// gc drops those pending metric data that are out of date,
+// to avoid keeping them forever when store keeps failing.
+func (p *pushCollector) gc() {
+	p.Lock()
+	defer p.Unlock()
+
+	expiredTime := time.Now().Add(-1 * p.genericConf.OutOfDataPeriod).UnixMilli()
+	for hash, series := range p.pendingSeries {
+		var updatedSeries []*data.MetricData
+		for _, d := range series.Series {
+			if d.Timestamp > expiredTime {
+				updatedSeries = append(updatedSeries, d)
+			}
+		}
+
+		p.pendingCount -= len(series.Series) - len(updatedSeries)
+		if len(updatedSeries) == 0 {
+			delete(p.pendingSeries, hash)
+		} else {
+			series.Series = updatedSeries
+		}
+	}
+
+	_ = p.emitter.StoreInt64(metricNamePushCollectorPendingCount, int64(p.pendingCount), metrics.MetricTypeNameRaw)
+}
@@ -0,0 +1,440 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package push
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	"github.com/kubewharf/katalyst-core/pkg/config/metric"
+	"github.com/kubewharf/katalyst-core/pkg/custom-metric/store"
+	"github.com/kubewharf/katalyst-core/pkg/custom-metric/store/data"
+	"github.com/kubewharf/katalyst-core/pkg/util/credential"
+)
+
+type fakeMetricStore struct {
+	store.MetricStore
+
+	sync.Mutex
+	err      error
+	inserted []*data.MetricSeries
+}
+
+func (f *fakeMetricStore) InsertMetric(s []*data.MetricSeries) error {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.err != nil {
+		return f.err
+	}
+	f.inserted = append(f.inserted, s...)
+	return nil
+}
+
+type fakeCredential struct {
+	credential.Credential
+
+	authInfo credential.AuthInfo
+	err      error
+}
+
+func (f *fakeCredential) Auth(_ *http.Request) (credential.AuthInfo, error) {
+	return f.authInfo, f.err
+}
+
+// newTestServerConf writes a self-signed certificate for push collector to serve with
+func newTestServerConf(t *testing.T) *generic.GenericConfiguration {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "push-collector"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	conf := generic.NewGenericConfiguration()
+	conf.GenericEndpointTLSCertFile = filepath.Join(dir, "tls.crt")
+	conf.GenericEndpointTLSKeyFile = filepath.Join(dir, "tls.key")
+	require.NoError(t, os.WriteFile(conf.GenericEndpointTLSCertFile,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644))
+	require.NoError(t, os.WriteFile(conf.GenericEndpointTLSKeyFile,
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	return conf
+}
+
+func newTestPushCollector(t *testing.T, maxPendingSamples, maxInflightRequests int, metricStore store.MetricStore) *pushCollector {
+	return newTestPushCollectorWithCredential(t, maxPendingSamples, maxInflightRequests, metricStore,
+		credential.DefaultCredential(), []string{credential.SubjectNameAnonymous})
+}
+
+func newTestPushCollectorWithCredential(t *testing.T, maxPendingSamples, maxInflightRequests int, metricStore store.MetricStore,
+	cred credential.Credential, privilegedSubjects []string, kubeObjects ...runtime.Object,
+) *pushCollector {
+	baseCtx, err := katalystbase.GenerateFakeGenericContext(kubeObjects, nil, nil, nil)
+	require.NoError(t, err)
+
+	c, err := NewPushCollector(context.Background(), baseCtx, newTestServerConf(t),
+		&metric.GenericMetricConfiguration{OutOfDataPeriod: time.Minute},
+		&metric.CollectorConfiguration{
+			SyncInterval:            time.Second,
+			PushMaxPendingSamples:   maxPendingSamples,
+			PushMaxInflightRequests: maxInflightRequests,
+			PushPrivilegedSubjects:  privilegedSubjects,
+		}, cred, metricStore)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	baseCtx.KubeInformerFactory.Start(ctx.Done())
+	require.True(t, cache.WaitForCacheSync(ctx.Done(), c.(*pushCollector).podSynced))
+	return c.(*pushCollector)
+}
+
+func generateContents(ts int64, names ...string) string {
+	var buf bytes.Buffer
+	for _, name := range names {
+		buf.WriteString(fmt.Sprintf("# TYPE %s gauge\n", name))
+		buf.WriteString(fmt.Sprintf("%s{namespace=\"n1\",object=\"pod\",object_name=\"p1\",timestamp=\"%d\"} 1\n", name, ts))
+	}
+	return buf.String()
+}
+
+func gzipContents(t *testing.T, contents string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(contents))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestPushCollectorHandlePush(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UnixMilli()
+
+	for _, tc := range []struct {
+		name                string
+		method              string
+		body                []byte
+		gzip                bool
+		maxPendingSamples   int
+		maxInflightRequests int
+		occupiedInflight    int
+		wantCode            int
+		wantPendingCount    int
+	}{
+		{
+			name:                "method not allowed",
+			method:              http.MethodGet,
+			maxPendingSamples:   10,
+			maxInflightRequests: 1,
+			wantCode:            http.StatusMethodNotAllowed,
+		},
+		{
+			name:                "invalid contents",
+			method:              http.MethodPost,
+			body:                []byte("# TYPE m1 gauge\nm1{ 1\n"),
+			maxPendingSamples:   10,
+			maxInflightRequests: 1,
+			wantCode:            http.StatusBadRequest,
+		},
+		{
+			name:                "accepted",
+			method:              http.MethodPost,
+			body:                []byte(generateContents(now, "m1", "m2")),
+			maxPendingSamples:   10,
+			maxInflightRequests: 1,
+			wantCode:            http.StatusNoContent,
+			wantPendingCount:    2,
+		},
+		{
+			name:                "accepted with gzip",
+			method:              http.MethodPost,
+			body:                gzipContents(t, generateContents(now, "m1", "m2", "m3")),
+			gzip:                true,
+			maxPendingSamples:   10,
+			maxInflightRequests: 1,
+			wantCode:            http.StatusNoContent,
+			wantPendingCount:    3,
+		},
+		{
+			name:                "too many pending series",
+			method:              http.MethodPost,
+			body:                []byte(generateContents(now, "m1", "m2")),
+			maxPendingSamples:   1,
+			maxInflightRequests: 1,
+			wantCode:            http.StatusTooManyRequests,
+		},
+		{
+			name:                "too many inflight requests",
+			method:              http.MethodPost,
+			body:                []byte(generateContents(now, "m1")),
+			maxPendingSamples:   10,
+			maxInflightRequests: 1,
+			occupiedInflight:    1,
+			wantCode:            http.StatusTooManyRequests,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := newTestPushCollector(t, tc.maxPendingSamples, tc.maxInflightRequests, &fakeMetricStore{})
+			for i := 0; i < tc.occupiedInflight; i++ {
+				p.inflight <- struct{}{}
+			}
+
+			req := httptest.NewRequest(tc.method, HTTPPushPath, bytes.NewReader(tc.body))
+			if tc.gzip {
+				req.Header.Set("Content-Encoding", "gzip")
+			}
+			w := httptest.NewRecorder()
+			p.handlePush(w, req)
+
+			assert.Equal(t, tc.wantCode, w.Code)
+			assert.Equal(t, tc.wantPendingCount, p.pendingCount)
+			assert.Equal(t, tc.wantPendingCount, len(p.pendingSeries))
+		})
+	}
+}
+
+func TestPushCollectorSync(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UnixMilli()
+	metricStore := &fakeMetricStore{err: fmt.Errorf("store unavailable")}
+	p := newTestPushCollector(t, 10, 1, metricStore)
+
+	push := func(contents string) {
+		w := httptest.NewRecorder()
+		p.handlePush(w, httptest.NewRequest(http.MethodPost, HTTPPushPath, bytes.NewBufferString(contents)))
+		require.Equal(t, http.StatusNoContent, w.Code)
+	}
+
+	// series are kept in buffer if store fails, and merged with the newly pushed ones
+	push(generateContents(now-2000, "m1", "m2"))
+	p.sync()
+	assert.Equal(t, 2, p.pendingCount)
+
+	push(generateContents(now-1000, "m1"))
+	assert.Equal(t, 3, p.pendingCount)
+	assert.Equal(t, 2, len(p.pendingSeries))
+
+	// out-of-date metric data are dropped by gc
+	push(generateContents(now-time.Hour.Milliseconds(), "m3"))
+	assert.Equal(t, 4, p.pendingCount)
+	p.gc()
+	assert.Equal(t, 3, p.pendingCount)
+	assert.Equal(t, 2, len(p.pendingSeries))
+
+	metricStore.Lock()
+	metricStore.err = nil
+	metricStore.Unlock()
+
+	p.sync()
+	assert.Equal(t, 0, p.pendingCount)
+	assert.Equal(t, 0, len(p.pendingSeries))
+
+	dataCount := map[string]int{}
+	for _, series := range metricStore.inserted {
+		dataCount[series.Name] += len(series.Series)
+	}
+	assert.Equal(t, map[string]int{"m1": 2, "m2": 1}, dataCount)
+}
+
+func TestNewPushCollectorWithoutTLS(t *testing.T) {
+	t.Parallel()
+
+	baseCtx, err := katalystbase.GenerateFakeGenericContext(nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	_, err = NewPushCollector(context.Background(), baseCtx, generic.NewGenericConfiguration(),
+		&metric.GenericMetricConfiguration{OutOfDataPeriod: time.Minute},
+		&metric.CollectorConfiguration{PushMaxPendingSamples: 10, PushMaxInflightRequests: 1},
+		credential.DefaultCredential(), &fakeMetricStore{})
+	assert.Error(t, err)
+}
+
+func TestPushCollectorAuthorize(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UnixMilli()
+	contents := func(object, namespace, name string) []byte {
+		return []byte(fmt.Sprintf("# TYPE m1 gauge\nm1{namespace=%q,object=%q,object_name=%q,timestamp=\"%d\"} 1\n",
+			namespace, object, name, now))
+	}
+	nodeAuthInfo := credential.TokenReviewAuthInfo{Username: "system:node:node1", Groups: []string{"system:nodes"}}
+	pods := []runtime.Object{
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "n1", Name: "p1"}, Spec: v1.PodSpec{NodeName: "node1"}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "n1", Name: "p2"}, Spec: v1.PodSpec{NodeName: "node2"}},
+	}
+
+	for _, tc := range []struct {
+		name             string
+		authInfo         credential.AuthInfo
+		authErr          error
+		occupiedInflight int
+		body             []byte
+		wantCode         int
+	}{
+		{
+			name:     "unauthenticated",
+			authErr:  fmt.Errorf("invalid token"),
+			body:     contents("nodes", "", "node1"),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:             "inflight is acquired before authentication",
+			authErr:          fmt.Errorf("invalid token"),
+			occupiedInflight: 1,
+			body:             contents("nodes", "", "node1"),
+			wantCode:         http.StatusTooManyRequests,
+		},
+		{
+			name:     "node pushes itself",
+			authInfo: nodeAuthInfo,
+			body:     contents("nodes", "", "node1"),
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "node pushes other node",
+			authInfo: nodeAuthInfo,
+			body:     contents("nodes", "", "node2"),
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "node pushes pod on it",
+			authInfo: nodeAuthInfo,
+			body:     contents("pods", "n1", "p1"),
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name: "node client certificate pushes pod on it",
+			authInfo: credential.CertificateAuthInfo{
+				Username:      "system:node:node1",
+				Organizations: []string{"system:nodes"},
+			},
+			body:     contents("pods", "n1", "p1"),
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name: "bound service account token pushes pod on its node",
+			authInfo: credential.TokenReviewAuthInfo{
+				Username: "system:serviceaccount:default:test",
+				Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:default", "system:authenticated"},
+				Extra:    map[string][]string{"authentication.kubernetes.io/node-name": {"node1"}},
+			},
+			body:     contents("pods", "n1", "p1"),
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name: "bound service account token pushes its node",
+			authInfo: credential.TokenReviewAuthInfo{
+				Username: "system:serviceaccount:default:test",
+				Extra:    map[string][]string{"authentication.kubernetes.io/node-name": {"node1"}},
+			},
+			body:     contents("nodes", "", "node1"),
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "node user without nodes group",
+			authInfo: credential.TokenReviewAuthInfo{Username: "system:node:node1"},
+			body:     contents("nodes", "", "node1"),
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "node pushes pod on other node",
+			authInfo: nodeAuthInfo,
+			body:     contents("pods", "n1", "p2"),
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "node pushes unknown pod",
+			authInfo: nodeAuthInfo,
+			body:     contents("pods", "n1", "p3"),
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "node pushes cluster-scoped series",
+			authInfo: nodeAuthInfo,
+			body:     []byte(fmt.Sprintf("# TYPE m1 gauge\nm1{timestamp=\"%d\"} 1\n", now)),
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "subject is neither privileged nor a node",
+			authInfo: credential.TokenReviewAuthInfo{Username: "system:serviceaccount:default:test"},
+			body:     contents("pods", "n1", "p1"),
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "privileged subject pushes any objects",
+			authInfo: credential.TokenReviewAuthInfo{Username: "system:serviceaccount:default:exporter"},
+			body:     contents("pods", "n1", "p2"),
+			wantCode: http.StatusNoContent,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cred := &fakeCredential{authInfo: tc.authInfo, err: tc.authErr}
+			p := newTestPushCollectorWithCredential(t, 10, 1, &fakeMetricStore{}, cred,
+				[]string{"system:serviceaccount:default:exporter"}, pods...)
+			for i := 0; i < tc.occupiedInflight; i++ {
+				p.inflight <- struct{}{}
+			}
+
+			w := httptest.NewRecorder()
+			p.handlePush(w, httptest.NewRequest(http.MethodPost, HTTPPushPath, bytes.NewReader(tc.body)))
+			assert.Equal(t, tc.wantCode, w.Code)
+		})
+	}
+}
@@ -32,6 +32,8 @@ type ConfigManagerCheckpoint interface {
 	checkpointmanager.Checkpoint
 	GetData(kind string) (reflect.Value, metav1.Time)
 	SetData(kind string, v reflect.Value, t metav1.Time)
+	GetSource(kind string) (ConfigSource, bool)
+	SetSource(kind string, source ConfigSource)
 }
 
 type TargetConfigData struct {
@@ -50,6 +52,9 @@ type DataItem struct {
 	// Data maps from kind to target config data
 	Data     map[string]TargetConfigData
 	Checksum checksum.Checksum
+	// Sources maps from kind to the kcc target which the data comes from, and it's
+	// excluded from checksum to keep compatible with checkpoints written before
+	Sources map[string]ConfigSource `json:",omitempty"`
 }
 
 // NewCheckpoint returns an instance of Checkpoint
@@ -123,3 +128,21 @@ func (d *Data) SetData(kind string, val reflect.Value, t metav1.Time) {
 		Timestamp: t.Unix(),
 	}
 }
+
+func (d *Data) GetSource(kind string) (ConfigSource, bool) {
+	d.Lock()
+	defer d.Unlock()
+
+	source, ok := d.Item.Sources[kind]
+	return source, ok
+}
+
+func (d *Data) SetSource(kind string, source ConfigSource) {
+	d.Lock()
+	defer d.Unlock()
+
+	if d.Item.Sources == nil {
+		d.Item.Sources = make(map[string]ConfigSource)
+	}
+	d.Item.Sources[kind] = source
+}
@@ -59,6 +59,8 @@ func TestNewCheckpoint(t *testing.T) {
 
 	cp := NewCheckpoint(make(map[string]TargetConfigData))
 	cp.SetData(kind, configField, now)
+	source := ConfigSource{Type: ValueSourceKCC, Kind: kind, TargetName: "default", Hash: "e39c2dd73aac"}
+	cp.SetSource(kind, source)
 
 	checkpoint, err := cp.MarshalCheckpoint()
 	assert.NoError(t, err)
@@ -75,4 +77,8 @@ func TestNewCheckpoint(t *testing.T) {
 	configField.Set(configData)
 	assert.Equal(t, metav1.Unix(now.Unix(), 0), timestamp)
 	assert.Equal(t, dynamicCRD, dynamicConfigCRD)
+
+	gotSource, ok := cp.GetSource(kind)
+	assert.True(t, ok)
+	assert.Equal(t, source, gotSource)
 }
@@ -40,10 +40,19 @@ type ConfigurationLoader interface {
 	LoadConfig(ctx context.Context, gvr metav1.GroupVersionResource, conf interface{}) error
 }
 
+// targetConfigSourceGetter is implemented by loaders which know the kcc
+// target that the loaded configuration comes from.
+type targetConfigSourceGetter interface {
+	getTargetConfigSource(gvr metav1.GroupVersionResource) (ConfigSource, bool)
+}
+
 // configCache keeps a local in-memory cache for each configuration CRD.
 // each time when users want to get the latest configuration, return from
 // cache firstly (it still valid); otherwise, trigger a client getting action.
 type configCache struct {
+	// targetConfigNamespace and targetConfigName records the matched configurations in CNC.
+	targetConfigNamespace string
+	targetConfigName      string
 	// targetConfigHash records the config hash for matched configurations in CNC.
 	targetConfigHash string
 	// targetConfigContent records the contents for matched configurations in CNC.
@@ -110,6 +119,24 @@ func (c *katalystCustomConfigLoader) LoadConfig(ctx context.Context, gvr metav1.
 	return fmt.Errorf("get config cache for %s not found", gvr)
 }
 
+// getTargetConfigSource returns the kcc target which the cached configuration comes from
+func (c *katalystCustomConfigLoader) getTargetConfigSource(gvr metav1.GroupVersionResource) (ConfigSource, bool) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+
+	cache, ok := c.configCache[gvr]
+	if !ok {
+		return ConfigSource{}, false
+	}
+
+	return ConfigSource{
+		Type:            ValueSourceKCC,
+		TargetNamespace: cache.targetConfigNamespace,
+		TargetName:      cache.targetConfigName,
+		Hash:            cache.targetConfigHash,
+	}, true
+}
+
 // getCNCTargetConfig get cnc target from cnc fetcher
 func (c *katalystCustomConfigLoader) getCNCTargetConfig(ctx context.Context, gvr metav1.GroupVersionResource) (*v1alpha1.TargetConfig, error) {
 	currentCNC, err := c.cncFetcher.GetCNC(ctx)
@@ -160,9 +187,23 @@ func (c *katalystCustomConfigLoader) updateConfigCacheIfNeed(ctx context.Context
 			return err
 		}
 
+		// the hash in CNC may be an earlier one of the target (e.g. reverted by the rollout guard),
+		// whose contents are restored from its ControllerRevision
+		content := util.ToKCCTargetResource(conf)
+		if !util.CheckKCCTargetConfigHash(content, targetConfig.Hash) {
+			if restored, err := c.restoreConfigRevision(ctx, gvr, content, targetConfig.Hash); err != nil {
+				klog.Infof("[kcc-sdk] %s restore config of hash %s failed, use the latest one instead: %v",
+					gvr.String(), targetConfig.Hash, err)
+			} else {
+				content = restored
+			}
+		}
+
 		c.configCache[gvr] = configCache{
-			targetConfigHash:    targetConfig.Hash,
-			targetConfigContent: util.ToKCCTargetResource(conf),
+			targetConfigNamespace: targetConfig.ConfigNamespace,
+			targetConfigName:      targetConfig.ConfigName,
+			targetConfigHash:      targetConfig.Hash,
+			targetConfigContent:   content,
 		}
 
 		klog.Infof("[kcc-sdk] %s config cache has been updated to %v", gvr.String(), conf)
@@ -170,3 +211,21 @@ func (c *katalystCustomConfigLoader) updateConfigCacheIfNeed(ctx context.Context
 
 	return nil
 }
+
+// restoreConfigRevision returns the target with the contents of the given hash, which are stored in
+// the ControllerRevision created by the kcc controller.
+func (c *katalystCustomConfigLoader) restoreConfigRevision(ctx context.Context, gvr metav1.GroupVersionResource,
+	target util.KCCTargetResource, hash string,
+) (util.KCCTargetResource, error) {
+	if c.client.KubeClient == nil {
+		return nil, fmt.Errorf("kube client is nil")
+	}
+
+	namespace, name := util.GetKCCTargetControllerRevisionKey(gvr, target.GetNamespace(), target.GetName(), hash)
+	revision, err := c.client.KubeClient.AppsV1().ControllerRevisions(namespace).Get(ctx, name, metav1.GetOptions{ResourceVersion: "0"})
+	if err != nil {
+		return nil, err
+	}
+
+	return util.RestoreKCCTargetConfigRevision(target, hash, revision)
+}
@@ -0,0 +1,283 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kcc
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/crd"
+)
+
+type ValueSourceType string
+
+const (
+	// ValueSourceDefault means the value is the built-in default
+	ValueSourceDefault ValueSourceType = "default"
+	// ValueSourceFlag means the value is set by command line flags
+	ValueSourceFlag ValueSourceType = "flag"
+	// ValueSourceKCC means the value is set by the kcc target matched in cnc
+	ValueSourceKCC ValueSourceType = "kcc"
+	// ValueSourceCheckpoint means the value is set by the kcc target restored from
+	// local checkpoint, since it failed to be loaded from remote
+	ValueSourceCheckpoint ValueSourceType = "checkpoint"
+	// ValueSourceHook means the value differs from any kcc target, and it
+	// should be modified by a ConfigurationHook
+	ValueSourceHook ValueSourceType = "hook"
+)
+
+const (
+	EffectiveSectionAdminQoS        = "adminqos"
+	EffectiveSectionEviction        = "eviction"
+	EffectiveSectionTMO             = "tmo"
+	EffectiveSectionStrategyGroup   = "strategygroup"
+	EffectiveSectionMetricThreshold = "metricthreshold"
+)
+
+// effectiveSections maps each section to the configurations it consists of;
+// auth configuration is not exposed on purpose since it contains credentials.
+var effectiveSections = map[string]func(c *dynamic.Configuration) map[string]interface{}{
+	EffectiveSectionAdminQoS: func(c *dynamic.Configuration) map[string]interface{} {
+		return map[string]interface{}{
+			"ReclaimedResourceConfiguration": c.ReclaimedResourceConfiguration,
+			"AdvisorConfiguration":           c.AdvisorConfiguration,
+		}
+	},
+	EffectiveSectionEviction: func(c *dynamic.Configuration) map[string]interface{} {
+		return map[string]interface{}{"EvictionConfiguration": c.EvictionConfiguration}
+	},
+	EffectiveSectionTMO: func(c *dynamic.Configuration) map[string]interface{} {
+		return map[string]interface{}{"TransparentMemoryOffloadingConfiguration": c.TransparentMemoryOffloadingConfiguration}
+	},
+	EffectiveSectionStrategyGroup: func(c *dynamic.Configuration) map[string]interface{} {
+		return map[string]interface{}{"StrategyGroupConfiguration": c.StrategyGroupConfiguration}
+	},
+	EffectiveSectionMetricThreshold: func(c *dynamic.Configuration) map[string]interface{} {
+		return map[string]interface{}{"MetricThresholdConfiguration": c.MetricThresholdConfiguration}
+	},
+}
+
+// ConfigSource describes where a configuration value comes from
+type ConfigSource struct {
+	Type ValueSourceType `json:"type"`
+
+	// Kind, TargetNamespace, TargetName and Hash identify the kcc target, and
+	// they are only set if type is kcc or checkpoint
+	Kind            string `json:"kind,omitempty"`
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+	TargetName      string `json:"targetName,omitempty"`
+	Hash            string `json:"hash,omitempty"`
+
+	// CheckpointTime is the time when the checkpoint is written, and
+	// it's only set if type is checkpoint
+	CheckpointTime *metav1.Time `json:"checkpointTime,omitempty"`
+}
+
+type EffectiveValue struct {
+	Value  interface{}  `json:"value"`
+	Source ConfigSource `json:"source"`
+}
+
+// EffectiveConfiguration is the dynamic configuration currently in force, and
+// it maps from section to the field path (joined by dots) of each value.
+type EffectiveConfiguration struct {
+	Sections map[string]map[string]EffectiveValue `json:"sections"`
+
+	// LastUpdateTime is the time of the last successful update, and LastError is
+	// the error of the last failed update.
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+	LastError      string       `json:"lastError,omitempty"`
+	LastErrorTime  *metav1.Time `json:"lastErrorTime,omitempty"`
+}
+
+// EffectiveConfigurationGetter is implemented by configuration managers which
+// know the sources of the dynamic configuration currently in force.
+type EffectiveConfigurationGetter interface {
+	// GetEffectiveConfiguration returns the effective configuration; builtin is
+	// the configuration without any flags, and values equal to it are regarded
+	// as default. If builtin is nil, all static values are regarded as flags.
+	GetEffectiveConfiguration(builtin *dynamic.Configuration) *EffectiveConfiguration
+}
+
+// buildEffectiveSections attributes each value in current configuration to the
+// last layer which changes it, i.e. kcc (or checkpoint) > flag > default.
+func buildEffectiveSections(builtin, static, current *dynamic.Configuration,
+	dynamicConfigCRD *crd.DynamicConfigCRD, sources map[string]ConfigSource,
+) map[string]map[string]EffectiveValue {
+	builtinValues := flattenSections(builtin)
+	staticValues := flattenSections(static)
+	currentValues := flattenSections(current)
+
+	// apply each kind of kcc target separately to know which values it changes
+	kinds := make([]string, 0, len(sources))
+	for kind := range sources {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	kindValues := make(map[string]map[string]map[string]interface{}, len(kinds))
+	for _, kind := range kinds {
+		kindCRD, ok := singleKindCRD(dynamicConfigCRD, kind)
+		if !ok {
+			continue
+		}
+
+		conf := deepCopy(static)
+		applyDynamicConfig(conf, kindCRD)
+		kindValues[kind] = flattenSections(conf)
+	}
+
+	sections := make(map[string]map[string]EffectiveValue, len(currentValues))
+	for section, values := range currentValues {
+		sections[section] = make(map[string]EffectiveValue, len(values))
+		for path, value := range values {
+			staticValue := staticValues[section][path]
+
+			var source ConfigSource
+			switch {
+			case !valueEqual(value, staticValue):
+				source = ConfigSource{Type: ValueSourceHook}
+				for _, kind := range kinds {
+					if v, ok := kindValues[kind][section][path]; ok && valueEqual(v, value) {
+						source = sources[kind]
+					}
+				}
+			case builtin == nil:
+				source = ConfigSource{Type: ValueSourceFlag}
+			case !valueEqual(staticValue, builtinValues[section][path]):
+				source = ConfigSource{Type: ValueSourceFlag}
+			default:
+				source = ConfigSource{Type: ValueSourceDefault}
+			}
+
+			sections[section][path] = EffectiveValue{Value: value, Source: source}
+		}
+	}
+	return sections
+}
+
+// singleKindCRD returns a dynamic config crd only with the given kind set
+func singleKindCRD(dynamicConfigCRD *crd.DynamicConfigCRD, kind string) (*crd.DynamicConfigCRD, bool) {
+	if dynamicConfigCRD == nil {
+		return nil, false
+	}
+
+	field := reflect.ValueOf(dynamicConfigCRD).Elem().FieldByName(kind)
+	if !field.IsValid() || field.IsNil() {
+		return nil, false
+	}
+
+	kindCRD := &crd.DynamicConfigCRD{}
+	reflect.ValueOf(kindCRD).Elem().FieldByName(kind).Set(field)
+	return kindCRD, true
+}
+
+func flattenSections(c *dynamic.Configuration) map[string]map[string]interface{} {
+	sections := make(map[string]map[string]interface{}, len(effectiveSections))
+	if c == nil {
+		return sections
+	}
+
+	for section, getter := range effectiveSections {
+		values := make(map[string]interface{})
+		for name, conf := range getter(c) {
+			flattenValue(name, reflect.ValueOf(conf), values)
+		}
+		sections[section] = values
+	}
+	return sections
+}
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// flattenValue walks through structs recursively, and stores each leaf value
+// with its field path; types with customized json format are regarded as leaves.
+func flattenValue(path string, v reflect.Value, values map[string]interface{}) {
+	if !v.IsValid() {
+		values[path] = nil
+		return
+	}
+
+	if v.Type().Implements(jsonMarshalerType) || reflect.PtrTo(v.Type()).Implements(jsonMarshalerType) {
+		values[path] = v.Interface()
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			values[path] = nil
+			return
+		}
+		flattenValue(path, v.Elem(), values)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" {
+				// skip unexported fields
+				continue
+			}
+			flattenValue(path+"."+field.Name, v.Field(i), values)
+		}
+	case reflect.Map:
+		if v.IsNil() || !hasUnsupportedMapKey(v.Type()) {
+			values[path] = v.Interface()
+			return
+		}
+
+		// maps with keys unsupported by json are flattened by keys
+		for _, key := range v.MapKeys() {
+			flattenValue(fmt.Sprintf("%v.%v", path, key.Interface()), v.MapIndex(key), values)
+		}
+	default:
+		values[path] = v.Interface()
+	}
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// hasUnsupportedMapKey returns true if the type contains maps whose keys can't be
+// encoded by json, e.g. map[bool]float64.
+func hasUnsupportedMapKey(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Map:
+		switch t.Key().Kind() {
+		case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			if !t.Key().Implements(textMarshalerType) {
+				return true
+			}
+		}
+		return hasUnsupportedMapKey(t.Elem())
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return hasUnsupportedMapKey(t.Elem())
+	default:
+		return false
+	}
+}
+
+func valueEqual(a, b interface{}) bool {
+	return apiequality.Semantic.DeepEqual(a, b)
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kcc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/crd"
+)
+
+const (
+	testPathEvictionThreshold = "EvictionConfiguration.ReclaimedResourcesEvictionConfiguration.EvictionThreshold"
+	testPathDryRun            = "EvictionConfiguration.DryRun"
+	testPathEnableReclaim     = "ReclaimedResourceConfiguration.EnableReclaim"
+	testPathGracePeriod       = "EvictionConfiguration.MemoryPressureEvictionConfiguration.GracePeriod"
+)
+
+// constructTestEffectiveManager returns a manager whose EnableReclaim is set by flags,
+// and the built-in configuration without flags.
+func constructTestEffectiveManager(t *testing.T) (*DynamicConfigManager, *dynamic.Configuration) {
+	manager := constructTestDynamicConfigManager(t, "test-node", t.TempDir(),
+		generateTestEvictionConfiguration(map[v1.ResourceName]float64{
+			v1.ResourceCPU:    1.2,
+			v1.ResourceMemory: 1.3,
+		}))
+
+	builtin := deepCopy(manager.defaultConfig)
+	manager.defaultConfig.EnableReclaim = !builtin.EnableReclaim
+	manager.conf.SetDynamicConfiguration(deepCopy(manager.defaultConfig))
+	return manager, builtin
+}
+
+func TestDynamicConfigManager_GetEffectiveConfiguration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		hook        ConfigurationHook
+		wantErr     bool
+		wantSources map[string]ValueSourceType
+	}{
+		{
+			name: "values from default, flag and kcc",
+			wantSources: map[string]ValueSourceType{
+				testPathEvictionThreshold: ValueSourceKCC,
+				testPathEnableReclaim:     ValueSourceFlag,
+				testPathDryRun:            ValueSourceDefault,
+				testPathGracePeriod:       ValueSourceDefault,
+			},
+		},
+		{
+			name: "values modified by hook",
+			hook: func(_ context.Context, _, newConf *dynamic.Configuration) error {
+				newConf.DryRun = []string{"test"}
+				return nil
+			},
+			wantSources: map[string]ValueSourceType{
+				testPathEvictionThreshold: ValueSourceKCC,
+				testPathDryRun:            ValueSourceHook,
+			},
+		},
+		{
+			name: "failed update keeps the previous values",
+			hook: func(_ context.Context, _, _ *dynamic.Configuration) error {
+				return fmt.Errorf("test error")
+			},
+			wantErr: true,
+			wantSources: map[string]ValueSourceType{
+				testPathEvictionThreshold: ValueSourceDefault,
+				testPathEnableReclaim:     ValueSourceFlag,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			manager, builtin := constructTestEffectiveManager(t)
+			if tt.hook != nil {
+				manager.AddConfigHook(tt.hook)
+			}
+			err := manager.tryUpdateConfig(context.TODO(), false)
+			require.Equal(t, tt.wantErr, err != nil)
+
+			effective := manager.GetEffectiveConfiguration(builtin)
+			if tt.wantErr {
+				require.Nil(t, effective.LastUpdateTime)
+				require.NotNil(t, effective.LastErrorTime)
+				require.Contains(t, effective.LastError, "test error")
+			} else {
+				require.NotNil(t, effective.LastUpdateTime)
+				require.Empty(t, effective.LastError)
+			}
+
+			for path, sourceType := range tt.wantSources {
+				section := EffectiveSectionEviction
+				if path == testPathEnableReclaim {
+					section = EffectiveSectionAdminQoS
+				}
+
+				value, ok := effective.Sections[section][path]
+				require.True(t, ok, path)
+				require.Equal(t, sourceType, value.Source.Type, path)
+			}
+
+			if tt.wantErr {
+				return
+			}
+			source := effective.Sections[EffectiveSectionEviction][testPathEvictionThreshold].Source
+			require.Equal(t, ConfigSource{
+				Type:            ValueSourceKCC,
+				Kind:            crd.ResourceKindAdminQoSConfiguration,
+				TargetNamespace: "test-namespace",
+				TargetName:      "default",
+				Hash:            "e39c2dd73aac",
+			}, source)
+		})
+	}
+}
+
+type fakeConfigLoader struct{}
+
+func (f *fakeConfigLoader) LoadConfig(_ context.Context, _ metav1.GroupVersionResource, _ interface{}) error {
+	return fmt.Errorf("test error")
+}
+
+func TestDynamicConfigManager_GetEffectiveConfigurationFromCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	manager, builtin := constructTestEffectiveManager(t)
+	manager.checkpointGraceTime = time.Hour
+	require.NoError(t, manager.tryUpdateConfig(context.TODO(), false))
+
+	// fall back to the checkpoint written by the previous update
+	manager.configLoader = &fakeConfigLoader{}
+	require.NoError(t, manager.tryUpdateConfig(context.TODO(), false))
+
+	source := manager.GetEffectiveConfiguration(builtin).Sections[EffectiveSectionEviction][testPathEvictionThreshold].Source
+	require.NotNil(t, source.CheckpointTime)
+	source.CheckpointTime = nil
+	require.Equal(t, ConfigSource{
+		Type:            ValueSourceCheckpoint,
+		Kind:            crd.ResourceKindAdminQoSConfiguration,
+		TargetNamespace: "test-namespace",
+		TargetName:      "default",
+		Hash:            "e39c2dd73aac",
+	}, source)
+}
+
+type fakeRegister struct {
+	mux *http.ServeMux
+}
+
+func (f *fakeRegister) RegisterHTTPHandler(path string, handler http.Handler) error {
+	f.mux.Handle(path, handler)
+	return nil
+}
+
+func TestRegisterEffectiveConfigurationHandler(t *testing.T) {
+	t.Parallel()
+
+	manager, builtin := constructTestEffectiveManager(t)
+	require.NoError(t, manager.tryUpdateConfig(context.TODO(), false))
+
+	register := &fakeRegister{mux: http.NewServeMux()}
+	require.NoError(t, RegisterEffectiveConfigurationHandler(register, manager, builtin))
+	server := httptest.NewServer(register.mux)
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		query        string
+		wantStatus   int
+		wantSections []string
+	}{
+		{
+			name:       "all sections",
+			wantStatus: http.StatusOK,
+			wantSections: []string{
+				EffectiveSectionAdminQoS, EffectiveSectionEviction, EffectiveSectionTMO,
+				EffectiveSectionStrategyGroup, EffectiveSectionMetricThreshold,
+			},
+		},
+		{
+			name:         "single section",
+			query:        "?section=eviction",
+			wantStatus:   http.StatusOK,
+			wantSections: []string{EffectiveSectionEviction},
+		},
+		{
+			name:       "unknown section",
+			query:      "?section=auth",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(server.URL + PathEffectiveConfiguration + tt.query)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			require.Equal(t, tt.wantStatus, resp.StatusCode)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			effective := &EffectiveConfiguration{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(effective))
+
+			sections := make([]string, 0, len(effective.Sections))
+			for section := range effective.Sections {
+				sections = append(sections, section)
+			}
+			require.ElementsMatch(t, tt.wantSections, sections)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kcc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic"
+)
+
+const (
+	PathEffectiveConfiguration = "/dynamic-config/effective"
+
+	QueryParamSection = "section"
+)
+
+// HTTPHandlerRegister registers handler for the given path, and it's
+// usually implemented by the generic context of katalyst components.
+type HTTPHandlerRegister interface {
+	RegisterHTTPHandler(path string, handler http.Handler) error
+}
+
+// RegisterEffectiveConfigurationHandler exposes the effective dynamic configuration
+// through http endpoint; builtin is the configuration without any flags.
+func RegisterEffectiveConfigurationHandler(register HTTPHandlerRegister,
+	getter EffectiveConfigurationGetter, builtin *dynamic.Configuration,
+) error {
+	return register.RegisterHTTPHandler(PathEffectiveConfiguration, http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r == nil || r.Method != http.MethodGet || r.URL == nil {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				_, _ = fmt.Fprintf(w, "request must be GET")
+				return
+			}
+
+			effective := getter.GetEffectiveConfiguration(builtin)
+			if section := r.URL.Query().Get(QueryParamSection); section != "" {
+				values, ok := effective.Sections[section]
+				if !ok {
+					w.WriteHeader(http.StatusBadRequest)
+					_, _ = fmt.Fprintf(w, "unknown section %q", section)
+					return
+				}
+				effective.Sections = map[string]map[string]EffectiveValue{section: values}
+			}
+
+			bytes, err := json.Marshal(effective)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = fmt.Fprintf(w, "marshal effective configuration failed: %v", err)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(bytes)
+		}))
+}
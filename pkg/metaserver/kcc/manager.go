@@ -110,7 +110,10 @@ func (d *DummyConfigurationManager) AddConfigWatcher(_ ...metav1.GroupVersionRes
 
 func (d *DummyConfigurationManager) Run(_ context.Context) {}
 
-var _ ConfigurationManager = &DynamicConfigManager{}
+var (
+	_ ConfigurationManager         = &DynamicConfigManager{}
+	_ EffectiveConfigurationGetter = &DynamicConfigManager{}
+)
 
 // DynamicConfigManager is to fetch dynamic config from remote
 type DynamicConfigManager struct {
@@ -138,6 +141,16 @@ type DynamicConfigManager struct {
 	// checkpoint stores recent dynamic config
 	checkpointManager   checkpointmanager.CheckpointManager
 	checkpointGraceTime time.Duration
+
+	// loadedSources records the source of each kind loaded in the current update round,
+	// and appliedSources records those of lastDynamicConfigCRD
+	loadedSources map[string]ConfigSource
+
+	statusMux      sync.RWMutex
+	appliedSources map[string]ConfigSource
+	lastUpdateTime time.Time
+	lastError      error
+	lastErrorTime  time.Time
 }
 
 // NewDynamicConfigManager new a dynamic config manager use katalyst custom config sdk.
@@ -220,6 +233,7 @@ func (c *DynamicConfigManager) tryUpdateConfig(ctx context.Context, skipError bo
 	defer c.mux.RUnlock()
 
 	err := c.updateConfig(ctx)
+	c.updateStatus(err)
 	if err != nil {
 		_ = c.emitter.StoreInt64(metricsNameUpdateConfig, 1, metrics.MetricTypeNameCount, metrics.MetricTag{
 			Key: "status", Val: "failed",
@@ -249,6 +263,10 @@ func (c *DynamicConfigManager) updateConfig(ctx context.Context) error {
 		return err
 	} else if apiequality.Semantic.DeepEqual(c.lastDynamicConfigCRD, dynamicConfigCRD) {
 		klog.V(4).Infof("dynamic config is not changed")
+		// contents are the same, but they may be loaded from different sources
+		c.statusMux.Lock()
+		c.appliedSources = c.loadedSources
+		c.statusMux.Unlock()
 		return nil
 	}
 
@@ -269,11 +287,46 @@ func (c *DynamicConfigManager) updateConfig(ctx context.Context) error {
 	}
 
 	c.conf.SetDynamicConfiguration(currentConfig)
+	c.statusMux.Lock()
 	c.lastDynamicConfigCRD = dynamicConfigCRD
+	c.appliedSources = c.loadedSources
+	c.statusMux.Unlock()
 	return err
 }
 
-func (c *DynamicConfigManager) writeCheckpoint(kind string, configData reflect.Value) {
+func (c *DynamicConfigManager) updateStatus(err error) {
+	c.statusMux.Lock()
+	defer c.statusMux.Unlock()
+
+	if err != nil {
+		c.lastError = err
+		c.lastErrorTime = time.Now()
+		return
+	}
+	c.lastUpdateTime = time.Now()
+}
+
+// GetEffectiveConfiguration returns the dynamic configuration currently in force,
+// with the source of each value.
+func (c *DynamicConfigManager) GetEffectiveConfiguration(builtin *dynamic.Configuration) *EffectiveConfiguration {
+	c.statusMux.RLock()
+	defer c.statusMux.RUnlock()
+
+	effective := &EffectiveConfiguration{
+		Sections: buildEffectiveSections(builtin, c.defaultConfig, c.conf.GetDynamicConfiguration(),
+			c.lastDynamicConfigCRD, c.appliedSources),
+	}
+	if !c.lastUpdateTime.IsZero() {
+		effective.LastUpdateTime = &metav1.Time{Time: c.lastUpdateTime}
+	}
+	if c.lastError != nil {
+		effective.LastError = c.lastError.Error()
+		effective.LastErrorTime = &metav1.Time{Time: c.lastErrorTime}
+	}
+	return effective
+}
+
+func (c *DynamicConfigManager) writeCheckpoint(kind string, configData reflect.Value, source ConfigSource) {
 	// read checkpoint to get config data related to other gvr
 	data, err := c.readCheckpoint()
 	if err != nil {
@@ -291,6 +344,7 @@ func (c *DynamicConfigManager) writeCheckpoint(kind string, configData reflect.V
 
 	// set config value and timestamp for kind
 	data.SetData(kind, configData, metav1.Now())
+	data.SetSource(kind, source)
 	err = c.checkpointManager.CreateCheckpoint(configManagerCheckpoint, data)
 	if err != nil {
 		klog.Errorf("failed to write checkpoint file %q: %v", configManagerCheckpoint, err)
@@ -314,6 +368,7 @@ func (c *DynamicConfigManager) updateDynamicConfig(resourceGVRMap map[string]met
 ) (*crd.DynamicConfigCRD, bool, error) {
 	dynamicConfiguration := &crd.DynamicConfigCRD{}
 	success := false
+	c.loadedSources = make(map[string]ConfigSource)
 
 	var errList []error
 	for _, gvr := range resourceGVRMap {
@@ -346,6 +401,12 @@ func (c *DynamicConfigManager) updateDynamicConfig(resourceGVRMap map[string]met
 				if configData.Kind() == reflect.Ptr && !configData.IsNil() &&
 					time.Now().Before(timestamp.Add(c.checkpointGraceTime)) {
 					newConfigData = configData
+					// the target which the checkpoint comes from may be unknown for checkpoints written before
+					source, _ := data.GetSource(kind.Kind)
+					source.Type = ValueSourceCheckpoint
+					source.Kind = kind.Kind
+					source.CheckpointTime = &timestamp
+					c.loadedSources[kind.Kind] = source
 					klog.Infof("failed to load targetConfigMeta from remote, use local checkpoint instead")
 					_ = c.emitter.StoreInt64(metricsNameLoadCheckpoint, 1, metrics.MetricTypeNameRaw, []metrics.MetricTag{
 						{Key: "status", Val: metricsValueStatusCheckpointSuccess},
@@ -360,12 +421,21 @@ func (c *DynamicConfigManager) updateDynamicConfig(resourceGVRMap map[string]met
 					continue
 				}
 			}
+		} else {
+			source := ConfigSource{Type: ValueSourceKCC}
+			if getter, ok := c.configLoader.(targetConfigSourceGetter); ok {
+				if s, ok := getter.getTargetConfigSource(gvr); ok {
+					source = s
+				}
+			}
+			source.Kind = kind.Kind
+			c.loadedSources[kind.Kind] = source
 		}
 
 		// set target dynamic configField by new config field
 		configField.Set(newConfigData)
 		success = true
-		c.writeCheckpoint(kind.Kind, newConfigData)
+		c.writeCheckpoint(kind.Kind, newConfigData, c.loadedSources[kind.Kind])
 	}
 
 	return dynamicConfiguration, success, errors.NewAggregate(errList)
@@ -0,0 +1,183 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+)
+
+const (
+	// default windows must be among those published by npd usage metrics plugin, i.e. npd-usage-windows
+	defaultFilterWindow     = 5 * time.Minute
+	defaultScoreWindow      = time.Hour
+	defaultMetricExpiration = 5 * time.Minute
+
+	// defaultMetricExpirationWindowRatio is twice of the default npd-usage-window-refresh-ratio,
+	// so that metrics over long windows won't expire between two refreshes of npd
+	defaultMetricExpirationWindowRatio = 0.2
+
+	defaultCPUUsageThreshold    = 80
+	defaultMemoryUsageThreshold = 90
+
+	defaultCPUEstimatedScalingFactor    = 85
+	defaultMemoryEstimatedScalingFactor = 70
+)
+
+// UsageMetric selects the aggregated usage metric reported in npd.
+type UsageMetric struct {
+	// Aggregator is the aggregator of the metric, e.g. avg, max or p95.
+	Aggregator v1alpha1.Aggregator `json:"aggregator,omitempty"`
+	// Window is the time window the metric is aggregated over.
+	Window metav1.Duration `json:"window,omitempty"`
+}
+
+// LoadAwareArgs holds arguments used to configure the LoadAware plugin.
+type LoadAwareArgs struct {
+	// NPDScope is the npd scope that node and pod usage metrics are read from.
+	NPDScope string `json:"npdScope,omitempty"`
+
+	// FilterMetric is the usage metric compared with UsageThresholds in Filter,
+	// it is usually a peak aggregator over a short window.
+	FilterMetric UsageMetric `json:"filterMetric,omitempty"`
+	// ScoreMetric is the usage metric used to project post-placement usage in Score,
+	// it is usually an average aggregator over a longer window.
+	ScoreMetric UsageMetric `json:"scoreMetric,omitempty"`
+	// MetricExpiration is the max age of metrics, and nodes with expired metrics
+	// are neither filtered nor preferred.
+	MetricExpiration metav1.Duration `json:"metricExpiration,omitempty"`
+	// MetricExpirationWindowRatio extends the expiration of metrics aggregated over long windows
+	// to window * ratio, since npd refreshes them proportionally to their windows.
+	MetricExpirationWindowRatio float64 `json:"metricExpirationWindowRatio,omitempty"`
+
+	// UsageThresholds is the percentage of allocatable, above which nodes are regarded as hot.
+	UsageThresholds map[v1.ResourceName]int64 `json:"usageThresholds,omitempty"`
+	// ResourceWeights is the weight of each resource when scoring.
+	ResourceWeights map[v1.ResourceName]int64 `json:"resourceWeights,omitempty"`
+	// EstimatedScalingFactors is the percentage of requests taken as usage of pods
+	// which are not reflected in metrics yet, e.g. assumed or newly started pods.
+	EstimatedScalingFactors map[v1.ResourceName]int64 `json:"estimatedScalingFactors,omitempty"`
+}
+
+// supportedResources are resources whose usage can be found in npd metrics.
+var supportedResources = map[v1.ResourceName]string{
+	v1.ResourceCPU:    consts.NPDMetricNameCPUUsage,
+	v1.ResourceMemory: consts.NPDMetricNameMemoryUsage,
+}
+
+func setDefaultLoadAwareArgs(args *LoadAwareArgs) {
+	if args.NPDScope == "" {
+		args.NPDScope = consts.NPDScopeLoadAware
+	}
+
+	if args.FilterMetric.Aggregator == "" {
+		args.FilterMetric.Aggregator = v1alpha1.AggregatorMax
+	}
+	if args.FilterMetric.Window.Duration == 0 {
+		args.FilterMetric.Window.Duration = defaultFilterWindow
+	}
+	if args.ScoreMetric.Aggregator == "" {
+		args.ScoreMetric.Aggregator = v1alpha1.AggregatorAvg
+	}
+	if args.ScoreMetric.Window.Duration == 0 {
+		args.ScoreMetric.Window.Duration = defaultScoreWindow
+	}
+	if args.MetricExpiration.Duration == 0 {
+		args.MetricExpiration.Duration = defaultMetricExpiration
+	}
+	if args.MetricExpirationWindowRatio == 0 {
+		args.MetricExpirationWindowRatio = defaultMetricExpirationWindowRatio
+	}
+
+	if args.UsageThresholds == nil {
+		args.UsageThresholds = map[v1.ResourceName]int64{
+			v1.ResourceCPU:    defaultCPUUsageThreshold,
+			v1.ResourceMemory: defaultMemoryUsageThreshold,
+		}
+	}
+	if args.ResourceWeights == nil {
+		args.ResourceWeights = map[v1.ResourceName]int64{
+			v1.ResourceCPU:    1,
+			v1.ResourceMemory: 1,
+		}
+	}
+	if args.EstimatedScalingFactors == nil {
+		args.EstimatedScalingFactors = map[v1.ResourceName]int64{}
+	}
+	if _, ok := args.EstimatedScalingFactors[v1.ResourceCPU]; !ok {
+		args.EstimatedScalingFactors[v1.ResourceCPU] = defaultCPUEstimatedScalingFactor
+	}
+	if _, ok := args.EstimatedScalingFactors[v1.ResourceMemory]; !ok {
+		args.EstimatedScalingFactors[v1.ResourceMemory] = defaultMemoryEstimatedScalingFactor
+	}
+}
+
+func validateLoadAwareArgs(args *LoadAwareArgs) error {
+	if args.MetricExpirationWindowRatio < 0 {
+		return fmt.Errorf("metric expiration window ratio should not be negative, got %v", args.MetricExpirationWindowRatio)
+	}
+
+	for name, threshold := range args.UsageThresholds {
+		if _, ok := supportedResources[name]; !ok {
+			return fmt.Errorf("usage threshold of unsupported resource %v", name)
+		}
+		if threshold <= 0 || threshold > 100 {
+			return fmt.Errorf("usage threshold of %v should be in (0, 100], got %v", name, threshold)
+		}
+	}
+
+	var weightSum int64
+	for name, weight := range args.ResourceWeights {
+		if _, ok := supportedResources[name]; !ok {
+			return fmt.Errorf("resource weight of unsupported resource %v", name)
+		}
+		if weight < 0 {
+			return fmt.Errorf("resource weight of %v should not be negative, got %v", name, weight)
+		}
+		weightSum += weight
+	}
+	if weightSum == 0 {
+		return fmt.Errorf("at least one resource weight should be positive")
+	}
+
+	for name, factor := range args.EstimatedScalingFactors {
+		if _, ok := supportedResources[name]; !ok {
+			return fmt.Errorf("estimated scaling factor of unsupported resource %v", name)
+		}
+		if factor <= 0 || factor > 100 {
+			return fmt.Errorf("estimated scaling factor of %v should be in (0, 100], got %v", name, factor)
+		}
+	}
+
+	return nil
+}
+
+// getMetricExpiration returns the max age of the given metric, which is extended
+// for long windows since npd refreshes them less often.
+func getMetricExpiration(args *LoadAwareArgs, metric UsageMetric) time.Duration {
+	expiration := time.Duration(float64(metric.Window.Duration) * args.MetricExpirationWindowRatio)
+	if expiration < args.MetricExpiration.Duration {
+		return args.MetricExpiration.Duration
+	}
+	return expiration
+}
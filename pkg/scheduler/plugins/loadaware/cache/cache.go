@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+)
+
+var cache *npdCache
+
+func init() {
+	cache = &npdCache{
+		npds: map[string]*v1alpha1.NodeProfileDescriptor{},
+	}
+}
+
+// npdCache stores node profile descriptors keyed by node name,
+// it is only used by load-aware scheduling to get usage metrics.
+type npdCache struct {
+	sync.RWMutex
+	npds map[string]*v1alpha1.NodeProfileDescriptor
+}
+
+func GetCache() *npdCache {
+	return cache
+}
+
+// GetNPD returns the cached npd of the given node, and the returned
+// object should be regarded as read-only.
+func (c *npdCache) GetNPD(nodeName string) (*v1alpha1.NodeProfileDescriptor, bool) {
+	c.RLock()
+	defer c.RUnlock()
+
+	npd, ok := c.npds[nodeName]
+	return npd, ok
+}
+
+func (c *npdCache) AddOrUpdateNPD(npd *v1alpha1.NodeProfileDescriptor) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.npds[npd.Name] = npd
+}
+
+func (c *npdCache) RemoveNPD(npd *v1alpha1.NodeProfileDescriptor) {
+	c.Lock()
+	defer c.Unlock()
+
+	delete(c.npds, npd.Name)
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/plugins/loadaware/cache"
+)
+
+const (
+	ErrReasonUsageExceedThreshold = "node(s) %v usage exceed threshold"
+)
+
+// Filter rejects nodes whose usage, including estimated usage of pods not yet
+// reflected in metrics, exceeds the configured thresholds.
+func (l *LoadAware) Filter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	node := nodeInfo.Node()
+	if node == nil {
+		return framework.NewStatus(framework.Error, "node not found")
+	}
+
+	// daemonset pods must run on every node, so they should not be blocked by node load.
+	if isDaemonSetPod(pod) {
+		return nil
+	}
+
+	npd, ok := cache.GetCache().GetNPD(node.Name)
+	if !ok {
+		klog.V(5).Infof("npd of node %v not found, skip load-aware filter", node.Name)
+		return nil
+	}
+
+	usage, timestamp, ok := getNodeUsage(npd, l.args.NPDScope, l.args.FilterMetric,
+		getMetricExpiration(l.args, l.args.FilterMetric), time.Now())
+	if !ok {
+		klog.V(5).Infof("valid usage metrics of node %v not found, skip load-aware filter", node.Name)
+		return nil
+	}
+	usage.add(l.getUnreflectedUsage(nodeInfo, getReflectedPods(npd, l.args.NPDScope), timestamp))
+
+	allocatable := getNodeAllocatable(nodeInfo)
+	for name, threshold := range l.args.UsageThresholds {
+		if allocatable[name] <= 0 {
+			continue
+		}
+
+		if usage[name]*100 > allocatable[name]*threshold {
+			klog.V(5).Infof("node %v %v usage %v exceeds threshold %v%% of allocatable %v",
+				node.Name, name, usage[name], threshold, allocatable[name])
+			return framework.NewStatus(framework.Unschedulable, fmt.Sprintf(ErrReasonUsageExceedThreshold, name))
+		}
+	}
+
+	return nil
+}
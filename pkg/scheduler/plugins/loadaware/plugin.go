@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/plugins/loadaware/cache"
+)
+
+const (
+	// Name is the name of the plugin used in the plugin registry and configurations.
+	Name = "LoadAware"
+)
+
+var (
+	_ framework.FilterPlugin      = &LoadAware{}
+	_ framework.ScorePlugin       = &LoadAware{}
+	_ framework.EnqueueExtensions = &LoadAware{}
+)
+
+// LoadAware filters and scores nodes by their actual usage reported in npd,
+// rather than by requested quantities.
+type LoadAware struct {
+	args   *LoadAwareArgs
+	handle framework.Handle
+}
+
+func (l *LoadAware) Name() string {
+	return Name
+}
+
+func New(args runtime.Object, h framework.Handle) (framework.Plugin, error) {
+	klog.Info("Creating new LoadAware plugin")
+
+	loadAwareArgs := &LoadAwareArgs{}
+	if err := frameworkruntime.DecodeInto(args, loadAwareArgs); err != nil {
+		return nil, fmt.Errorf("decode LoadAwareArgs failed: %v", err)
+	}
+	setDefaultLoadAwareArgs(loadAwareArgs)
+	if err := validateLoadAwareArgs(loadAwareArgs); err != nil {
+		return nil, err
+	}
+	klog.Infof("args: %+v", loadAwareArgs)
+
+	cache.RegisterNPDHandler()
+	return &LoadAware{
+		args:   loadAwareArgs,
+		handle: h,
+	}, nil
+}
+
+// EventsToRegister returns the possible events that may make a Pod
+// failed by this plugin schedulable.
+func (l *LoadAware) EventsToRegister() []framework.ClusterEvent {
+	npdGVK := fmt.Sprintf("nodeprofiledescriptors.v1alpha1.%v", v1alpha1.GroupName)
+	return []framework.ClusterEvent{
+		{Resource: framework.Pod, ActionType: framework.Delete},
+		{Resource: framework.Node, ActionType: framework.Add | framework.UpdateNodeAllocatable},
+		{Resource: framework.GVK(npdGVK), ActionType: framework.Add | framework.Update},
+	}
+}
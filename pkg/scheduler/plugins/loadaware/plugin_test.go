@@ -0,0 +1,408 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-controller/app/options"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/controller/npd/metrics-plugin/plugins/usage"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/plugins/loadaware/cache"
+)
+
+var _ framework.SharedLister = &testSharedLister{}
+
+type testSharedLister struct {
+	nodeInfos   []*framework.NodeInfo
+	nodeInfoMap map[string]*framework.NodeInfo
+}
+
+func newTestSharedLister(nodeInfos ...*framework.NodeInfo) *testSharedLister {
+	nodeInfoMap := make(map[string]*framework.NodeInfo)
+	for _, nodeInfo := range nodeInfos {
+		nodeInfoMap[nodeInfo.Node().Name] = nodeInfo
+	}
+	return &testSharedLister{
+		nodeInfos:   nodeInfos,
+		nodeInfoMap: nodeInfoMap,
+	}
+}
+
+func (f *testSharedLister) NodeInfos() framework.NodeInfoLister {
+	return f
+}
+
+func (f *testSharedLister) List() ([]*framework.NodeInfo, error) {
+	return f.nodeInfos, nil
+}
+
+func (f *testSharedLister) HavePodsWithAffinityList() ([]*framework.NodeInfo, error) {
+	return nil, nil
+}
+
+func (f *testSharedLister) HavePodsWithRequiredAntiAffinityList() ([]*framework.NodeInfo, error) {
+	return nil, nil
+}
+
+func (f *testSharedLister) Get(nodeName string) (*framework.NodeInfo, error) {
+	return f.nodeInfoMap[nodeName], nil
+}
+
+func makeTestNode(name, cpu, memory string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(cpu),
+				v1.ResourceMemory: resource.MustParse(memory),
+			},
+		},
+	}
+}
+
+func makeTestPod(name, nodeName, cpu, memory string, startTime *metav1.Time) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: v1.PodSpec{
+			NodeName: nodeName,
+			Containers: []v1.Container{
+				{
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceCPU:    resource.MustParse(cpu),
+							v1.ResourceMemory: resource.MustParse(memory),
+						},
+					},
+				},
+			},
+		},
+		Status: v1.PodStatus{StartTime: startTime},
+	}
+}
+
+func makeTestMetric(name string, aggregator v1alpha1.Aggregator, window time.Duration,
+	timestamp time.Time, value string,
+) v1alpha1.MetricValue {
+	return v1alpha1.MetricValue{
+		MetricName: name,
+		Timestamp:  metav1.NewTime(timestamp),
+		Aggregator: &aggregator,
+		Window:     &metav1.Duration{Duration: window},
+		Value:      resource.MustParse(value),
+	}
+}
+
+func makeTestNPD(nodeName string, timestamp time.Time, maxCPU, maxMemory, avgCPU, avgMemory string,
+	reflectedPods ...string,
+) *v1alpha1.NodeProfileDescriptor {
+	podMetrics := make([]v1alpha1.PodMetric, 0, len(reflectedPods))
+	for _, pod := range reflectedPods {
+		podMetrics = append(podMetrics, v1alpha1.PodMetric{Namespace: "default", Name: pod})
+	}
+
+	return &v1alpha1.NodeProfileDescriptor{
+		ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+		Status: v1alpha1.NodeProfileDescriptorStatus{
+			NodeMetrics: []v1alpha1.ScopedNodeMetrics{
+				{
+					Scope: consts.NPDScopeLoadAware,
+					Metrics: []v1alpha1.MetricValue{
+						makeTestMetric(consts.NPDMetricNameCPUUsage, v1alpha1.AggregatorMax, defaultFilterWindow, timestamp, maxCPU),
+						makeTestMetric(consts.NPDMetricNameMemoryUsage, v1alpha1.AggregatorMax, defaultFilterWindow, timestamp, maxMemory),
+						makeTestMetric(consts.NPDMetricNameCPUUsage, v1alpha1.AggregatorAvg, defaultScoreWindow, timestamp, avgCPU),
+						makeTestMetric(consts.NPDMetricNameMemoryUsage, v1alpha1.AggregatorAvg, defaultScoreWindow, timestamp, avgMemory),
+					},
+				},
+			},
+			PodMetrics: []v1alpha1.ScopedPodMetrics{
+				{
+					Scope:      consts.NPDScopeLoadAware,
+					PodMetrics: podMetrics,
+				},
+			},
+		},
+	}
+}
+
+func newTestLoadAware(t *testing.T, nodeInfos ...*framework.NodeInfo) *LoadAware {
+	f, err := frameworkruntime.NewFramework(nil, nil,
+		frameworkruntime.WithSnapshotSharedLister(newTestSharedLister(nodeInfos...)))
+	assert.NoError(t, err)
+
+	p, err := New(nil, f)
+	assert.NoError(t, err)
+	return p.(*LoadAware)
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		args    runtime.Object
+		wantErr bool
+		check   func(t *testing.T, args *LoadAwareArgs)
+	}{
+		{
+			name: "default args",
+			args: nil,
+			check: func(t *testing.T, args *LoadAwareArgs) {
+				assert.Equal(t, consts.NPDScopeLoadAware, args.NPDScope)
+				assert.Equal(t, v1alpha1.AggregatorMax, args.FilterMetric.Aggregator)
+				assert.Equal(t, v1alpha1.AggregatorAvg, args.ScoreMetric.Aggregator)
+				assert.Equal(t, time.Hour, args.ScoreMetric.Window.Duration)
+				assert.Equal(t, int64(defaultCPUUsageThreshold), args.UsageThresholds[v1.ResourceCPU])
+				assert.Equal(t, int64(defaultMemoryEstimatedScalingFactor), args.EstimatedScalingFactors[v1.ResourceMemory])
+			},
+		},
+		{
+			name: "custom args",
+			args: &runtime.Unknown{
+				Raw: []byte(`{"scoreMetric":{"aggregator":"p95","window":"1h"},"usageThresholds":{"cpu":60},"estimatedScalingFactors":{"cpu":100}}`),
+			},
+			check: func(t *testing.T, args *LoadAwareArgs) {
+				assert.Equal(t, v1alpha1.AggregatorP95, args.ScoreMetric.Aggregator)
+				assert.Equal(t, time.Hour, args.ScoreMetric.Window.Duration)
+				assert.Equal(t, map[v1.ResourceName]int64{v1.ResourceCPU: 60}, args.UsageThresholds)
+				assert.Equal(t, int64(100), args.EstimatedScalingFactors[v1.ResourceCPU])
+				assert.Equal(t, int64(defaultMemoryEstimatedScalingFactor), args.EstimatedScalingFactors[v1.ResourceMemory])
+			},
+		},
+		{
+			name:    "unsupported resource",
+			args:    &runtime.Unknown{Raw: []byte(`{"usageThresholds":{"nvidia.com/gpu":60}}`)},
+			wantErr: true,
+		},
+		{
+			name:    "invalid threshold",
+			args:    &runtime.Unknown{Raw: []byte(`{"usageThresholds":{"cpu":120}}`)},
+			wantErr: true,
+		},
+		{
+			name:    "zero weights",
+			args:    &runtime.Unknown{Raw: []byte(`{"resourceWeights":{"cpu":0}}`)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := New(tt.args, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, Name, p.Name())
+			tt.check(t, p.(*LoadAware).args)
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	now := time.Now()
+	before := metav1.NewTime(now.Add(-time.Hour))
+	after := metav1.NewTime(now.Add(time.Minute))
+
+	tests := []struct {
+		name       string
+		npd        *v1alpha1.NodeProfileDescriptor
+		pods       []*v1.Pod
+		pod        *v1.Pod
+		wantStatus *framework.Status
+	}{
+		{
+			name:       "npd not found",
+			pod:        makeTestPod("pod", "", "1", "1Gi", nil),
+			wantStatus: nil,
+		},
+		{
+			name:       "usage below threshold",
+			npd:        makeTestNPD("node", now, "6", "8Gi", "4", "6Gi"),
+			pod:        makeTestPod("pod", "", "1", "1Gi", nil),
+			wantStatus: nil,
+		},
+		{
+			name:       "cpu usage above threshold",
+			npd:        makeTestNPD("node", now, "9", "8Gi", "4", "6Gi"),
+			pod:        makeTestPod("pod", "", "1", "1Gi", nil),
+			wantStatus: framework.NewStatus(framework.Unschedulable, "node(s) cpu usage exceed threshold"),
+		},
+		{
+			name:       "expired metrics",
+			npd:        makeTestNPD("node", now.Add(-time.Hour), "9", "8Gi", "4", "6Gi"),
+			pod:        makeTestPod("pod", "", "1", "1Gi", nil),
+			wantStatus: nil,
+		},
+		{
+			name: "assumed pods exceed threshold",
+			npd:  makeTestNPD("node", now, "6", "8Gi", "4", "6Gi", "running"),
+			pods: []*v1.Pod{
+				makeTestPod("running", "node", "4", "4Gi", &before),
+				makeTestPod("assumed", "node", "3", "1Gi", nil),
+			},
+			pod:        makeTestPod("pod", "", "1", "1Gi", nil),
+			wantStatus: framework.NewStatus(framework.Unschedulable, "node(s) cpu usage exceed threshold"),
+		},
+		{
+			name: "pods started after metrics exceed threshold",
+			npd:  makeTestNPD("node", now, "6", "8Gi", "4", "6Gi"),
+			pods: []*v1.Pod{
+				makeTestPod("running", "node", "4", "4Gi", &before),
+				makeTestPod("started", "node", "3", "1Gi", &after),
+			},
+			pod:        makeTestPod("pod", "", "1", "1Gi", nil),
+			wantStatus: framework.NewStatus(framework.Unschedulable, "node(s) cpu usage exceed threshold"),
+		},
+		{
+			name: "daemonset pod",
+			npd:  makeTestNPD("node", now, "9", "8Gi", "4", "6Gi"),
+			pod: func() *v1.Pod {
+				pod := makeTestPod("pod", "", "1", "1Gi", nil)
+				pod.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", Name: "ds"}}
+				return pod
+			}(),
+			wantStatus: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeInfo := framework.NewNodeInfo(tt.pods...)
+			nodeInfo.SetNode(makeTestNode("node", "10", "16Gi"))
+			if tt.npd != nil {
+				cache.GetCache().AddOrUpdateNPD(tt.npd)
+				defer cache.GetCache().RemoveNPD(tt.npd)
+			}
+
+			l := newTestLoadAware(t, nodeInfo)
+			status := l.Filter(context.TODO(), framework.NewCycleState(), tt.pod, nodeInfo)
+			assert.Equal(t, tt.wantStatus, status)
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	now := time.Now()
+
+	// all nodes have the same requests, but different usage
+	idle := framework.NewNodeInfo(makeTestPod("a", "idle", "4", "8Gi", nil))
+	idle.SetNode(makeTestNode("idle", "10", "16Gi"))
+	busy := framework.NewNodeInfo(makeTestPod("b", "busy", "4", "8Gi", nil))
+	busy.SetNode(makeTestNode("busy", "10", "16Gi"))
+	unknown := framework.NewNodeInfo(makeTestPod("c", "unknown", "4", "8Gi", nil))
+	unknown.SetNode(makeTestNode("unknown", "10", "16Gi"))
+
+	npds := []*v1alpha1.NodeProfileDescriptor{
+		makeTestNPD("idle", now, "4", "8Gi", "2", "4Gi", "a"),
+		makeTestNPD("busy", now, "8", "12Gi", "6", "10Gi", "b"),
+	}
+	for _, npd := range npds {
+		cache.GetCache().AddOrUpdateNPD(npd)
+		defer cache.GetCache().RemoveNPD(npd)
+	}
+
+	l := newTestLoadAware(t, idle, busy, unknown)
+	pod := makeTestPod("pod", "", "2", "4Gi", nil)
+
+	// idle: cpu (10 - 2 - 1.7) / 10 = 63, memory (16 - 4 - 2.8) / 16 = 57
+	// busy: cpu (10 - 6 - 1.7) / 10 = 23, memory (16 - 10 - 2.8) / 16 = 20
+	wantScores := map[string]int64{
+		"idle":    60,
+		"busy":    21,
+		"unknown": 0,
+	}
+	for nodeName, wantScore := range wantScores {
+		score, status := l.Score(context.TODO(), framework.NewCycleState(), pod, nodeName)
+		assert.Nil(t, status)
+		assert.Equal(t, wantScore, score, nodeName)
+	}
+}
+
+// TestDefaultArgsWithNPDUsageDefaults makes sure that metrics published by npd usage
+// metrics plugin with its default options can be used by load-aware default args, even
+// if they are read just before npd refreshes them.
+func TestDefaultArgsWithNPDUsageDefaults(t *testing.T) {
+	npdConf, err := options.NewNPDOptions().Config()
+	assert.NoError(t, err)
+	usageConf := npdConf.UsageMetricsPluginConfig
+
+	now := time.Now()
+	makeNPD := func(nodeName, cpu, memory string) *v1alpha1.NodeProfileDescriptor {
+		var metrics []v1alpha1.MetricValue
+		for _, window := range usageConf.Windows {
+			refreshPeriod := time.Duration(float64(window) * usageConf.WindowRefreshRatio)
+			if refreshPeriod < usageConf.SyncPeriod {
+				refreshPeriod = usageConf.SyncPeriod
+			}
+			timestamp := now.Add(-refreshPeriod - usageConf.SyncPeriod)
+
+			for _, aggregator := range usage.Aggregators {
+				metrics = append(metrics,
+					makeTestMetric(consts.NPDMetricNameCPUUsage, aggregator, window, timestamp, cpu),
+					makeTestMetric(consts.NPDMetricNameMemoryUsage, aggregator, window, timestamp, memory))
+			}
+		}
+		return &v1alpha1.NodeProfileDescriptor{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+			Status: v1alpha1.NodeProfileDescriptorStatus{
+				NodeMetrics: []v1alpha1.ScopedNodeMetrics{{Scope: consts.NPDScopeLoadAware, Metrics: metrics}},
+			},
+		}
+	}
+
+	idle := framework.NewNodeInfo()
+	idle.SetNode(makeTestNode("default-idle", "10", "16Gi"))
+	hot := framework.NewNodeInfo()
+	hot.SetNode(makeTestNode("default-hot", "10", "16Gi"))
+	for _, npd := range []*v1alpha1.NodeProfileDescriptor{
+		makeNPD("default-idle", "2", "4Gi"),
+		makeNPD("default-hot", "9", "15Gi"),
+	} {
+		cache.GetCache().AddOrUpdateNPD(npd)
+		defer cache.GetCache().RemoveNPD(npd)
+	}
+
+	l := newTestLoadAware(t, idle, hot)
+	pod := makeTestPod("pod", "", "1", "1Gi", nil)
+
+	assert.Nil(t, l.Filter(context.TODO(), framework.NewCycleState(), pod, idle))
+	assert.Equal(t, framework.NewStatus(framework.Unschedulable, "node(s) cpu usage exceed threshold"),
+		l.Filter(context.TODO(), framework.NewCycleState(), pod, hot))
+
+	idleScore, status := l.Score(context.TODO(), framework.NewCycleState(), pod, "default-idle")
+	assert.Nil(t, status)
+	hotScore, status := l.Score(context.TODO(), framework.NewCycleState(), pod, "default-hot")
+	assert.Nil(t, status)
+	assert.Greater(t, idleScore, hotScore)
+	assert.Greater(t, idleScore, int64(0))
+}
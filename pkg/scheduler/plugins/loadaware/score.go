@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/plugins/loadaware/cache"
+)
+
+// Score prefers nodes with lower projected usage after the pod is placed,
+// and nodes without valid usage metrics get the lowest score.
+func (l *LoadAware) Score(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
+	nodeInfo, err := l.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	if err != nil {
+		return 0, framework.AsStatus(fmt.Errorf("getting node %q from Snapshot: %w", nodeName, err))
+	}
+
+	npd, ok := cache.GetCache().GetNPD(nodeName)
+	if !ok {
+		return 0, nil
+	}
+
+	usage, timestamp, ok := getNodeUsage(npd, l.args.NPDScope, l.args.ScoreMetric,
+		getMetricExpiration(l.args, l.args.ScoreMetric), time.Now())
+	if !ok {
+		return 0, nil
+	}
+	usage.add(l.getUnreflectedUsage(nodeInfo, getReflectedPods(npd, l.args.NPDScope), timestamp))
+	usage.add(l.estimatePodUsage(pod))
+
+	score := l.leastUsedScore(usage, getNodeAllocatable(nodeInfo))
+	klog.V(6).Infof("pod %v on node %v load-aware score %v, projected usage: %v", pod.Name, nodeName, score, usage)
+	return score, nil
+}
+
+func (l *LoadAware) ScoreExtensions() framework.ScoreExtensions {
+	return nil
+}
+
+// leastUsedScore calculates the weighted score of unused ratio of each resource.
+func (l *LoadAware) leastUsedScore(usage, allocatable resourceUsage) int64 {
+	var score, weightSum int64
+	for name, weight := range l.args.ResourceWeights {
+		weightSum += weight
+
+		if allocatable[name] <= 0 || usage[name] >= allocatable[name] {
+			continue
+		}
+		score += (allocatable[name] - usage[name]) * framework.MaxNodeScore / allocatable[name] * weight
+	}
+
+	if weightSum == 0 {
+		return 0
+	}
+	return score / weightSum
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	schedutil "k8s.io/kubernetes/pkg/scheduler/util"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+)
+
+// resourceUsage records usage of each resource, in milli-cores for cpu and in bytes for memory.
+type resourceUsage map[v1.ResourceName]int64
+
+func (r resourceUsage) add(other resourceUsage) {
+	for name, value := range other {
+		r[name] += value
+	}
+}
+
+// getNodeUsage returns node usage matching the given metric from npd, along with
+// the oldest timestamp of those metrics; ok is false if any supported resource
+// has no valid metric, since partial usage would under-estimate the node load.
+func getNodeUsage(npd *v1alpha1.NodeProfileDescriptor, scope string, metric UsageMetric,
+	expiration time.Duration, now time.Time,
+) (usage resourceUsage, timestamp time.Time, ok bool) {
+	usage = resourceUsage{}
+	for _, scopedMetrics := range npd.Status.NodeMetrics {
+		if scopedMetrics.Scope != scope {
+			continue
+		}
+
+		for _, value := range scopedMetrics.Metrics {
+			name, found := metricResourceName(value.MetricName)
+			if !found || !matchUsageMetric(value, metric) {
+				continue
+			}
+			if now.Sub(value.Timestamp.Time) > expiration {
+				continue
+			}
+
+			usage[name] = quantityValue(name, value.Value)
+			if timestamp.IsZero() || value.Timestamp.Time.Before(timestamp) {
+				timestamp = value.Timestamp.Time
+			}
+		}
+	}
+
+	for name := range supportedResources {
+		if _, found := usage[name]; !found {
+			return nil, time.Time{}, false
+		}
+	}
+	return usage, timestamp, true
+}
+
+// getReflectedPods returns keys of pods whose usage metrics are reported in npd.
+func getReflectedPods(npd *v1alpha1.NodeProfileDescriptor, scope string) map[string]struct{} {
+	pods := make(map[string]struct{})
+	for _, scopedMetrics := range npd.Status.PodMetrics {
+		if scopedMetrics.Scope != scope {
+			continue
+		}
+
+		for _, podMetric := range scopedMetrics.PodMetrics {
+			pods[native.GenerateNamespaceNameKey(podMetric.Namespace, podMetric.Name)] = struct{}{}
+		}
+	}
+	return pods
+}
+
+// getUnreflectedUsage estimates usage of pods on node which are not reflected
+// in node metrics yet, including pods assumed in the scheduler cache and pods
+// started after the metrics were produced.
+func (l *LoadAware) getUnreflectedUsage(nodeInfo *framework.NodeInfo, reflectedPods map[string]struct{},
+	timestamp time.Time,
+) resourceUsage {
+	usage := resourceUsage{}
+	for _, podInfo := range nodeInfo.Pods {
+		pod := podInfo.Pod
+		if _, ok := reflectedPods[native.GenerateNamespaceNameKey(pod.Namespace, pod.Name)]; ok {
+			continue
+		}
+		if pod.Status.StartTime != nil && pod.Status.StartTime.Time.Before(timestamp) {
+			continue
+		}
+
+		usage.add(l.estimatePodUsage(pod))
+	}
+	return usage
+}
+
+// estimatePodUsage estimates pod usage by scaling down its requests,
+// and reclaimed resources are counted into their native counterparts.
+func (l *LoadAware) estimatePodUsage(pod *v1.Pod) resourceUsage {
+	requests := native.SumUpPodRequestResources(pod)
+
+	milliCPU := requests.Cpu().MilliValue()
+	if reclaimed, ok := requests[apiconsts.ReclaimedResourceMilliCPU]; ok {
+		milliCPU += reclaimed.Value()
+	}
+	if milliCPU == 0 {
+		milliCPU = schedutil.DefaultMilliCPURequest
+	}
+
+	memory := requests.Memory().Value()
+	if reclaimed, ok := requests[apiconsts.ReclaimedResourceMemory]; ok {
+		memory += reclaimed.Value()
+	}
+	if memory == 0 {
+		memory = schedutil.DefaultMemoryRequest
+	}
+
+	return resourceUsage{
+		v1.ResourceCPU:    milliCPU * l.args.EstimatedScalingFactors[v1.ResourceCPU] / 100,
+		v1.ResourceMemory: memory * l.args.EstimatedScalingFactors[v1.ResourceMemory] / 100,
+	}
+}
+
+func getNodeAllocatable(nodeInfo *framework.NodeInfo) resourceUsage {
+	return resourceUsage{
+		v1.ResourceCPU:    nodeInfo.Allocatable.MilliCPU,
+		v1.ResourceMemory: nodeInfo.Allocatable.Memory,
+	}
+}
+
+func matchUsageMetric(value v1alpha1.MetricValue, metric UsageMetric) bool {
+	if value.Aggregator == nil || *value.Aggregator != metric.Aggregator {
+		return false
+	}
+	return value.Window != nil && value.Window.Duration == metric.Window.Duration
+}
+
+func metricResourceName(metricName string) (v1.ResourceName, bool) {
+	for name, supported := range supportedResources {
+		if supported == metricName {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func quantityValue(name v1.ResourceName, quantity resource.Quantity) int64 {
+	if name == v1.ResourceCPU {
+		return quantity.MilliValue()
+	}
+	return quantity.Value()
+}
+
+func isDaemonSetPod(pod *v1.Pod) bool {
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
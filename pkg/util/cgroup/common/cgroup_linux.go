@@ -83,6 +83,26 @@ func GetCgroupParamInt(cgroupPath, cgroupFile string) (int64, error) {
 	return res, nil
 }
 
+// GetHugetlbPageSizes returns huge page sizes (e.g. 2MB, 1GB) supported in cgroupPath
+// by listing hugetlb limit files, and limitSuffix is limit_in_bytes for v1 and max for v2.
+func GetHugetlbPageSizes(cgroupPath, limitSuffix string) ([]string, error) {
+	limitFiles, err := filepath.Glob(filepath.Join(cgroupPath, fmt.Sprintf("hugetlb.*.%s", limitSuffix)))
+	if err != nil {
+		return nil, err
+	}
+
+	var pageSizes []string
+	for _, limitFile := range limitFiles {
+		pageSize := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(limitFile), "hugetlb."), "."+limitSuffix)
+		// skip limits of reservations, e.g. hugetlb.2MB.rsvd.max
+		if strings.Contains(pageSize, ".") {
+			continue
+		}
+		pageSizes = append(pageSizes, pageSize)
+	}
+	return pageSizes, nil
+}
+
 /*
 ParseCgroupNumaValue parse cgroup numa stat files like `memory.numa_stat`.
 
@@ -136,7 +156,8 @@ func ParseCgroupNumaValue(content string) (map[string]map[int]uint64, error) {
 	return result, nil
 }
 
-// InstrumentedWriteFileIfChange wraps WriteFileIfChange with audit logic
+// InstrumentedWriteFileIfChange wraps WriteFileIfChange with audit logic,
+// and records data as the desired value of the file for reconciliation
 func InstrumentedWriteFileIfChange(dir, file, data string) (err error, applied bool, oldData string) {
 	startTime := time.Now()
 	defer func() {
@@ -155,6 +176,9 @@ func InstrumentedWriteFileIfChange(dir, file, data string) (err error, applied b
 	}()
 
 	err, applied, oldData = writeFileIfChange(dir, file, data)
+	if err == nil {
+		GetDesiredStateStore().Record(dir, file, data)
+	}
 	return
 }
 
@@ -31,10 +31,18 @@ func GetCgroupParamInt(cgroupPath, cgroupFile string) (int64, error) {
 	return 0, fmt.Errorf("unsupported write file")
 }
 
+func GetHugetlbPageSizes(cgroupPath, limitSuffix string) ([]string, error) {
+	return nil, fmt.Errorf("unsupported hugetlb")
+}
+
 func WriteFileIfChange(dir, file, data string) (error, bool, string) {
 	return fmt.Errorf("unsupported write file"), false, ""
 }
 
+func InstrumentedWriteFileIfChange(dir, file, data string) (err error, applied bool, oldData string) {
+	return fmt.Errorf("unsupported write file"), false, ""
+}
+
 func IsCPUIdleSupported() bool {
 	return false
 }
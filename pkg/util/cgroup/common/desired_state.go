@@ -0,0 +1,299 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+// unreconcilableCgroupFiles are cgroup files whose contents can't be compared with
+// the written data, e.g. writing to memory.reclaim triggers an action rather than
+// sets a value, and io.cost.qos reformats the parameters.
+var unreconcilableCgroupFiles = sets.NewString(
+	"cgroup.procs",
+	"cgroup.kill",
+	"tasks",
+	"memory.reclaim",
+	"memory.force_empty",
+	"io.cost.qos",
+	"io.cost.model",
+)
+
+// DesiredCgroupValue is the data katalyst applied to a cgroup file
+type DesiredCgroupValue struct {
+	AbsCgroupPath string    `json:"absCgroupPath"`
+	CgroupFile    string    `json:"cgroupFile"`
+	Data          string    `json:"data"`
+	UpdateTime    time.Time `json:"updateTime"`
+}
+
+// CgroupValueStatus is the desired value of a cgroup file along with its actual content
+type CgroupValueStatus struct {
+	Desired DesiredCgroupValue `json:"desired"`
+	Actual  string             `json:"actual"`
+	Drifted bool               `json:"drifted"`
+}
+
+// DesiredStateStore records the desired values of cgroup files per path and file,
+// and it's populated by cgroup apply functions once it's enabled.
+type DesiredStateStore struct {
+	mutex   sync.RWMutex
+	enabled bool
+	values  map[string]map[string]DesiredCgroupValue
+}
+
+var desiredStateStore = NewDesiredStateStore()
+
+// GetDesiredStateStore returns the global desired state store
+func GetDesiredStateStore() *DesiredStateStore {
+	return desiredStateStore
+}
+
+func NewDesiredStateStore() *DesiredStateStore {
+	return &DesiredStateStore{
+		values: make(map[string]map[string]DesiredCgroupValue),
+	}
+}
+
+// Enable starts recording desired values; it's disabled by default to avoid
+// keeping values of removed cgroups if no reconciler prunes them.
+func (s *DesiredStateStore) Enable() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.enabled = true
+}
+
+func (s *DesiredStateStore) Enabled() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.enabled
+}
+
+// Record records data as the desired value of the cgroup file, and cgroup files
+// whose contents can't be compared with written data are ignored.
+func (s *DesiredStateStore) Record(absCgroupPath, cgroupFile, data string) {
+	if unreconcilableCgroupFiles.Has(cgroupFile) {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.enabled {
+		return
+	}
+
+	if s.values[absCgroupPath] == nil {
+		s.values[absCgroupPath] = make(map[string]DesiredCgroupValue)
+	}
+	s.values[absCgroupPath][cgroupFile] = DesiredCgroupValue{
+		AbsCgroupPath: absCgroupPath,
+		CgroupFile:    cgroupFile,
+		Data:          data,
+		UpdateTime:    time.Now(),
+	}
+}
+
+func (s *DesiredStateStore) Get(absCgroupPath, cgroupFile string) (DesiredCgroupValue, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	value, ok := s.values[absCgroupPath][cgroupFile]
+	return value, ok
+}
+
+// List returns all desired values sorted by path and file
+func (s *DesiredStateStore) List() []DesiredCgroupValue {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var values []DesiredCgroupValue
+	for _, files := range s.values {
+		for _, value := range files {
+			values = append(values, value)
+		}
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].AbsCgroupPath != values[j].AbsCgroupPath {
+			return values[i].AbsCgroupPath < values[j].AbsCgroupPath
+		}
+		return values[i].CgroupFile < values[j].CgroupFile
+	})
+	return values
+}
+
+// ForgetPath removes desired values of all files in the cgroup path, e.g. the cgroup is removed
+func (s *DesiredStateStore) ForgetPath(absCgroupPath string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.values, absCgroupPath)
+}
+
+// GetStatus returns the desired value of the cgroup file along with its actual content
+func (s *DesiredStateStore) GetStatus(absCgroupPath, cgroupFile string) (*CgroupValueStatus, error) {
+	desired, ok := s.Get(absCgroupPath, cgroupFile)
+	if !ok {
+		return nil, fmt.Errorf("no desired value of %s", filepath.Join(absCgroupPath, cgroupFile))
+	}
+
+	actual, err := os.ReadFile(filepath.Join(absCgroupPath, cgroupFile))
+	if err != nil {
+		return nil, err
+	}
+
+	return &CgroupValueStatus{
+		Desired: desired,
+		Actual:  string(actual),
+		Drifted: IsCgroupValueDrifted(cgroupFile, desired.Data, string(actual)),
+	}, nil
+}
+
+// IsCgroupValueDrifted returns true if the actual content of cgroup file doesn't match the desired data;
+// formats of cgroup files are considered to avoid false positives, e.g. cpuset lists in different forms,
+// limits rounded to pages, unlimited values in different forms and keyed files (like io.weight)
+// containing lines of other devices.
+func IsCgroupValueDrifted(cgroupFile, desired, actual string) bool {
+	desired, actual = strings.TrimSpace(desired), strings.TrimSpace(actual)
+	if desired == actual {
+		return false
+	}
+
+	if strings.HasPrefix(cgroupFile, "cpuset.cpus") || strings.HasPrefix(cgroupFile, "cpuset.mems") {
+		desiredSet, err := machine.Parse(desired)
+		if err != nil {
+			return true
+		}
+		actualSet, err := machine.Parse(actual)
+		return err != nil || !desiredSet.Equals(actualSet)
+	}
+
+	if desiredValue, ok := parseCgroupNumber(desired); ok {
+		actualValue, ok := parseCgroupNumber(actual)
+		if !ok {
+			return true
+		} else if desiredValue == unlimitedCgroupValue || actualValue == unlimitedCgroupValue {
+			return desiredValue != actualValue
+		}
+
+		// limits in bytes are rounded down to pages by kernel
+		if strings.HasPrefix(cgroupFile, "memory.") || strings.HasPrefix(cgroupFile, "hugetlb.") {
+			granularity := getCgroupLimitGranularity(cgroupFile)
+			return actualValue != desiredValue/granularity*granularity
+		}
+		return actualValue != desiredValue
+	}
+
+	desiredFields := strings.Fields(desired)
+	if len(desiredFields) == 0 || !isDeviceID(desiredFields[0]) {
+		return true
+	}
+
+	// keyed by device, and the line of the device should contain all desired fields
+	for _, line := range strings.Split(actual, "\n") {
+		actualFields := strings.Fields(line)
+		if len(actualFields) == 0 || actualFields[0] != desiredFields[0] {
+			continue
+		}
+
+		actualFieldSet := sets.NewString(actualFields[1:]...)
+		return !actualFieldSet.HasAll(desiredFields[1:]...)
+	}
+
+	// lines of devices without limits are dropped, e.g. in io.max and blkio.throttle files
+	for _, field := range desiredFields[1:] {
+		if !isUnlimitedKeyedValue(field) {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	unlimitedCgroupValue int64 = math.MaxInt64
+	// unlimitedCgroupValueThreshold is the min value regarded as unlimited, since cgroup v1 reads
+	// unlimited values back as the max int64 rounded down to pages, e.g. 9223372036854771712.
+	unlimitedCgroupValueThreshold int64 = math.MaxInt64 - 1<<30
+)
+
+// parseCgroupNumber parses the content of a single-value cgroup file, and unlimited values
+// in any form ("max" in cgroup v2, -1 and rounded max int64 in cgroup v1) are unified
+// as unlimitedCgroupValue.
+func parseCgroupNumber(s string) (int64, bool) {
+	if s == "max" {
+		return unlimitedCgroupValue, true
+	}
+
+	value, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, false
+	} else if value == -1 || value >= unlimitedCgroupValueThreshold {
+		return unlimitedCgroupValue, true
+	}
+	return value, true
+}
+
+// getCgroupLimitGranularity returns the unit that limits in cgroup file are rounded to, i.e.
+// the huge page size for hugetlb files (e.g. hugetlb.2MB.limit_in_bytes) and page size for others.
+func getCgroupLimitGranularity(cgroupFile string) int64 {
+	pageSize := int64(os.Getpagesize())
+	parts := strings.Split(cgroupFile, ".")
+	if len(parts) < 3 || parts[0] != "hugetlb" {
+		return pageSize
+	}
+
+	units := map[string]int64{"KB": 1 << 10, "MB": 1 << 20, "GB": 1 << 30}
+	for suffix, unit := range units {
+		if size, err := strconv.ParseInt(strings.TrimSuffix(parts[1], suffix), 10, 64); err == nil &&
+			strings.HasSuffix(parts[1], suffix) && size > 0 {
+			return size * unit
+		}
+	}
+	return pageSize
+}
+
+// isUnlimitedKeyedValue returns true if the value of a device removes its limit,
+// e.g. "rbps=max" in io.max and "0" in blkio.throttle files
+func isUnlimitedKeyedValue(s string) bool {
+	return s == "0" || s == "max" || strings.HasSuffix(s, "=max")
+}
+
+// isDeviceID returns true if s is in the format of major:minor
+func isDeviceID(s string) bool {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return false
+	}
+
+	for _, part := range parts {
+		if _, err := strconv.ParseUint(part, 10, 32); err != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsCgroupValueDrifted(t *testing.T) {
+	t.Parallel()
+
+	pageSize := os.Getpagesize()
+
+	tests := []struct {
+		name       string
+		cgroupFile string
+		desired    string
+		actual     string
+		want       bool
+	}{
+		{
+			name:       "same value with line break",
+			cgroupFile: "cpu.max",
+			desired:    "max 100000",
+			actual:     "max 100000\n",
+			want:       false,
+		},
+		{
+			name:       "different value",
+			cgroupFile: "cpu.max",
+			desired:    "200000 100000",
+			actual:     "max 100000\n",
+			want:       true,
+		},
+		{
+			name:       "cpuset in different forms",
+			cgroupFile: "cpuset.cpus",
+			desired:    "0,1,2,3,8",
+			actual:     "0-3,8\n",
+			want:       false,
+		},
+		{
+			name:       "different cpuset",
+			cgroupFile: "cpuset.mems",
+			desired:    "0",
+			actual:     "0-1\n",
+			want:       true,
+		},
+		{
+			name:       "memory limit rounded to page",
+			cgroupFile: "memory.max",
+			desired:    fmt.Sprint(pageSize*10 + 1),
+			actual:     fmt.Sprint(pageSize * 10),
+			want:       false,
+		},
+		{
+			name:       "different memory limit",
+			cgroupFile: "memory.max",
+			desired:    fmt.Sprint(pageSize * 10),
+			actual:     "max",
+			want:       true,
+		},
+		{
+			name:       "unlimited memory limit read back in cgroup v1",
+			cgroupFile: "memory.limit_in_bytes",
+			desired:    "-1",
+			actual:     "9223372036854771712\n",
+			want:       false,
+		},
+		{
+			name:       "unlimited memory limit in cgroup v2",
+			cgroupFile: "memory.high",
+			desired:    "-1",
+			actual:     "max\n",
+			want:       false,
+		},
+		{
+			name:       "memory limit overwritten as unlimited",
+			cgroupFile: "memory.limit_in_bytes",
+			desired:    fmt.Sprint(pageSize * 10),
+			actual:     "9223372036854771712\n",
+			want:       true,
+		},
+		{
+			name:       "unlimited hugetlb limit read back in cgroup v1",
+			cgroupFile: "hugetlb.2MB.limit_in_bytes",
+			desired:    "-1",
+			actual:     "9223372036854771712\n",
+			want:       false,
+		},
+		{
+			name:       "hugetlb limit rounded to huge page",
+			cgroupFile: "hugetlb.2MB.limit_in_bytes",
+			desired:    fmt.Sprint(5 << 20),
+			actual:     fmt.Sprint(4 << 20),
+			want:       false,
+		},
+		{
+			name:       "different hugetlb limit",
+			cgroupFile: "hugetlb.1GB.max",
+			desired:    fmt.Sprint(2 << 30),
+			actual:     fmt.Sprint(1 << 30),
+			want:       true,
+		},
+		{
+			name:       "unlimited cpu quota",
+			cgroupFile: "cpu.cfs_quota_us",
+			desired:    "-1",
+			actual:     "-1\n",
+			want:       false,
+		},
+		{
+			name:       "different numeric value",
+			cgroupFile: "cpu.shares",
+			desired:    "1024",
+			actual:     "2048\n",
+			want:       true,
+		},
+		{
+			name:       "keyed file with other devices",
+			cgroupFile: "io.weight",
+			desired:    "8:0 200",
+			actual:     "default 100\n8:0 200\n8:16 300",
+			want:       false,
+		},
+		{
+			name:       "keyed file with partial fields",
+			cgroupFile: "io.max",
+			desired:    "8:0 rbps=1000",
+			actual:     "8:0 rbps=1000 wbps=max riops=max wiops=max",
+			want:       false,
+		},
+		{
+			name:       "keyed file with different value",
+			cgroupFile: "io.weight",
+			desired:    "8:0 200",
+			actual:     "default 100\n8:0 100",
+			want:       true,
+		},
+		{
+			name:       "keyed file without the unlimited device",
+			cgroupFile: "io.max",
+			desired:    "8:0 rbps=max wbps=max riops=max wiops=max",
+			actual:     "8:16 rbps=1000 wbps=max riops=max wiops=max",
+			want:       false,
+		},
+		{
+			name:       "keyed file without the limited device",
+			cgroupFile: "blkio.throttle.read_bps_device",
+			desired:    "8:0 1000",
+			actual:     "",
+			want:       true,
+		},
+		{
+			name:       "keyed file without the device",
+			cgroupFile: "io.weight",
+			desired:    "8:0 200",
+			actual:     "default 100",
+			want:       true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, IsCgroupValueDrifted(tt.cgroupFile, tt.desired, tt.actual))
+		})
+	}
+}
+
+func TestDesiredStateStore(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cpu.max"), []byte("max 100000\n"), 0o644))
+
+	store := NewDesiredStateStore()
+
+	// nothing is recorded before enabled
+	store.Record(dir, "cpu.max", "max 100000")
+	assert.Empty(t, store.List())
+
+	store.Enable()
+	store.Record(dir, "cpu.max", "200000 100000")
+	store.Record(dir, "cpu.weight", "100")
+	store.Record(dir, "memory.reclaim", "1000")
+
+	values := store.List()
+	require.Len(t, values, 2)
+	assert.Equal(t, "cpu.max", values[0].CgroupFile)
+	assert.Equal(t, "cpu.weight", values[1].CgroupFile)
+
+	status, err := store.GetStatus(dir, "cpu.max")
+	require.NoError(t, err)
+	assert.Equal(t, "200000 100000", status.Desired.Data)
+	assert.Equal(t, "max 100000\n", status.Actual)
+	assert.True(t, status.Drifted)
+
+	_, err = store.GetStatus(dir, "memory.reclaim")
+	assert.Error(t, err)
+	_, err = store.GetStatus(dir, "cpu.weight")
+	assert.Error(t, err)
+
+	store.ForgetPath(dir)
+	assert.Empty(t, store.List())
+	_, ok := store.Get(dir, "cpu.max")
+	assert.False(t, ok)
+}
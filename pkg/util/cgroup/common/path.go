@@ -199,3 +199,20 @@ func GetReclaimRelativeRootCgroupPath(reclaimRelativeRootCgroupPath string, NUMA
 	}
 	return strings.Join([]string{reclaimRelativeRootCgroupPath, strconv.Itoa(NUMANode)}, numaBindingReclaimRelativeRootCgroupPathSeparator)
 }
+
+// GetPodUIDFromCgroupPath returns the uid of pod that the cgroup (of pod or container) belongs to, and cgroup
+// paths of both cgroupfs (e.g. .../pod<uid>/<container-id>) and systemd (e.g. .../kubepods-burstable-pod<uid>.slice)
+// drivers are supported.
+func GetPodUIDFromCgroupPath(cgroupPath string) (string, bool) {
+	for _, part := range strings.Split(cgroupPath, "/") {
+		part = strings.TrimSuffix(part, ".slice")
+		idx := strings.LastIndex(part, "pod")
+		if idx < 0 || (idx > 0 && part[idx-1] != '-') || idx+len("pod") == len(part) {
+			continue
+		}
+
+		// dashes in pod uid are escaped as underscores by systemd driver
+		return strings.ReplaceAll(part[idx+len("pod"):], "_", "-"), true
+	}
+	return "", false
+}
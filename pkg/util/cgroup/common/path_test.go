@@ -78,3 +78,20 @@ func TestIsContainerCgroupFileExist(t *testing.T) {
 	_, err := IsContainerCgroupFileExist("cpuset", "fake-pod-uid", "fake-container-id", "nonexistentfile")
 	as.NotNil(err)
 }
+
+func TestGetPodUIDFromCgroupPath(t *testing.T) {
+	t.Parallel()
+
+	as := require.New(t)
+	uid, ok := GetPodUIDFromCgroupPath("/sys/fs/cgroup/cpu/kubepods/burstable/pod1b2c3d4e-0000-1111-2222-333344445555/abcdef")
+	as.True(ok)
+	as.Equal("1b2c3d4e-0000-1111-2222-333344445555", uid)
+
+	uid, ok = GetPodUIDFromCgroupPath("/sys/fs/cgroup/kubepods.slice/kubepods-besteffort.slice/" +
+		"kubepods-besteffort-pod1b2c3d4e_0000_1111_2222_333344445555.slice/cri-containerd-abcdef.scope")
+	as.True(ok)
+	as.Equal("1b2c3d4e-0000-1111-2222-333344445555", uid)
+
+	_, ok = GetPodUIDFromCgroupPath("/sys/fs/cgroup/cpu/kubepods/besteffort/offline-besteffort")
+	as.False(ok)
+}
@@ -34,6 +34,10 @@ const (
 	CgroupSubsysMemory = "memory"
 	CgroupSubsysCPU    = "cpu"
 	CgroupSubsysIO     = "io"
+	// CgroupSubsysBlkIO is the io sub-system for cgroupv1
+	CgroupSubsysBlkIO   = "blkio"
+	CgroupSubsysPids    = "pids"
+	CgroupSubsysHugetlb = "hugetlb"
 	// CgroupSubsysNetCls is the net_cls sub-system
 	CgroupSubsysNetCls = "net_cls"
 
@@ -76,6 +80,8 @@ type MemoryData struct {
 	WmarkRatio int32
 	// SwapMaxInBytes < 0 means disable cgroup-level swap
 	SwapMaxInBytes int64
+	// ZswapMaxInBytes for memory.zswap.max, and < 0 means disable cgroup-level zswap
+	ZswapMaxInBytes int64
 }
 
 type PressureType int
@@ -85,13 +91,18 @@ const (
 	FULL
 )
 
-// MemoryPressure get cgroup memory pressure
-type MemoryPressure struct {
+// Pressure get cgroup pressure stall information of a resource,
+// avg values are percentages and total is the stall time in microseconds
+type Pressure struct {
 	Avg10  uint64
 	Avg60  uint64
 	Avg300 uint64
+	Total  uint64
 }
 
+// MemoryPressure get cgroup memory pressure
+type MemoryPressure = Pressure
+
 // CPUData set cgroup cpu data
 type CPUData struct {
 	Shares     uint64
@@ -117,6 +128,28 @@ type NetClsData struct {
 	Attributes map[string]string
 }
 
+// IOThrottleData set cgroup io throttle limits of a device, and zero means unlimited
+type IOThrottleData struct {
+	ReadBPS   uint64 `json:"read_bps"`   // read bytes per second
+	WriteBPS  uint64 `json:"write_bps"`  // write bytes per second
+	ReadIOPS  uint64 `json:"read_iops"`  // read IO operations per second
+	WriteIOPS uint64 `json:"write_iops"` // write IO operations per second
+}
+
+// PidsData set cgroup pids data
+type PidsData struct {
+	// MaxPids > 0 limits the number of processes, and MaxPids < 0 means unlimited
+	MaxPids int64
+}
+
+// HugetlbData set cgroup hugetlb limit of a huge page size
+type HugetlbData struct {
+	// PageSize is the huge page size in cgroup file names, e.g. 2MB, 1GB
+	PageSize string
+	// LimitInBytes < 0 means unlimited
+	LimitInBytes int64
+}
+
 type (
 	IOCostCtrlMode string
 	IOCostModel    string
@@ -186,6 +219,37 @@ type CPUStats struct {
 	CpuQuota  int64
 }
 
+// SwapStats get cgroup swap data, and zswap is the compressed cache for
+// swapped out pages, which is only available if the kernel supports it
+type SwapStats struct {
+	SwapUsage  uint64
+	SwapLimit  uint64
+	ZswapUsage uint64
+	ZswapLimit uint64
+}
+
+// MemoryEvents get cgroup memory events, OOM is the times that memory usage
+// hits the limit and OOM killer is invoked, and OOMKill is the times that
+// processes are killed by OOM killer
+type MemoryEvents struct {
+	OOM     uint64
+	OOMKill uint64
+}
+
+// PidsStats get cgroup pids data
+type PidsStats struct {
+	Current uint64
+	Limit   uint64
+}
+
+// HugetlbStats get cgroup hugetlb data of a huge page size
+type HugetlbStats struct {
+	Limit uint64
+	Usage uint64
+	// Failcnt is the number of allocation failures due to the limit
+	Failcnt uint64
+}
+
 // CPUSetStats get cgroup cpuset data
 type CPUSetStats struct {
 	CPUs          string
@@ -167,6 +167,57 @@ func ApplyIOWeightWithAbsolutePath(absCgroupPath string, devID string, weight ui
 	return GetManager().ApplyIOWeight(absCgroupPath, devID, weight)
 }
 
+func ApplyIOThrottleWithRelativePath(relCgroupPath string, devID string, data *common.IOThrottleData) error {
+	if data == nil {
+		return fmt.Errorf("ApplyIOThrottleWithRelativePath with nil cgroup data")
+	}
+
+	absCgroupPath := common.GetAbsCgroupPath(common.CgroupSubsysBlkIO, relCgroupPath)
+	return ApplyIOThrottleWithAbsolutePath(absCgroupPath, devID, data)
+}
+
+func ApplyIOThrottleWithAbsolutePath(absCgroupPath string, devID string, data *common.IOThrottleData) error {
+	if data == nil {
+		return fmt.Errorf("ApplyIOThrottleWithAbsolutePath with nil cgroup data")
+	}
+
+	return GetManager().ApplyIOThrottle(absCgroupPath, devID, data)
+}
+
+func ApplyPidsWithRelativePath(relCgroupPath string, data *common.PidsData) error {
+	if data == nil {
+		return fmt.Errorf("ApplyPidsWithRelativePath with nil cgroup data")
+	}
+
+	absCgroupPath := common.GetAbsCgroupPath(common.CgroupSubsysPids, relCgroupPath)
+	return ApplyPidsWithAbsolutePath(absCgroupPath, data)
+}
+
+func ApplyPidsWithAbsolutePath(absCgroupPath string, data *common.PidsData) error {
+	if data == nil {
+		return fmt.Errorf("ApplyPidsWithAbsolutePath with nil cgroup data")
+	}
+
+	return GetManager().ApplyPids(absCgroupPath, data)
+}
+
+func ApplyHugetlbWithRelativePath(relCgroupPath string, data *common.HugetlbData) error {
+	if data == nil {
+		return fmt.Errorf("ApplyHugetlbWithRelativePath with nil cgroup data")
+	}
+
+	absCgroupPath := common.GetAbsCgroupPath(common.CgroupSubsysHugetlb, relCgroupPath)
+	return ApplyHugetlbWithAbsolutePath(absCgroupPath, data)
+}
+
+func ApplyHugetlbWithAbsolutePath(absCgroupPath string, data *common.HugetlbData) error {
+	if data == nil {
+		return fmt.Errorf("ApplyHugetlbWithAbsolutePath with nil cgroup data")
+	}
+
+	return GetManager().ApplyHugetlb(absCgroupPath, data)
+}
+
 func ApplyUnifiedDataWithAbsolutePath(absCgroupPath, cgroupFileName, data string) error {
 	return GetManager().ApplyUnifiedData(absCgroupPath, cgroupFileName, data)
 }
@@ -181,6 +232,22 @@ func ApplyUnifiedDataForContainer(podUID, containerId, subsys, cgroupFileName, d
 	return ApplyUnifiedDataWithAbsolutePath(absCgroupPath, cgroupFileName, data)
 }
 
+// GetCgroupValueStatusWithAbsolutePath returns the desired value recorded when the cgroup file
+// is applied along with its actual content, and it fails if the file isn't applied by katalyst.
+func GetCgroupValueStatusWithAbsolutePath(absCgroupPath, cgroupFileName string) (*common.CgroupValueStatus, error) {
+	return common.GetDesiredStateStore().GetStatus(absCgroupPath, cgroupFileName)
+}
+
+// GetCgroupValueStatusForContainer returns the desired value and actual content of cgroupFileName in subsys for a container.
+func GetCgroupValueStatusForContainer(podUID, containerId, subsys, cgroupFileName string) (*common.CgroupValueStatus, error) {
+	absCgroupPath, err := common.GetContainerAbsCgroupPath(subsys, podUID, containerId)
+	if err != nil {
+		return nil, fmt.Errorf("GetContainerAbsCgroupPath failed with error: %v", err)
+	}
+
+	return GetCgroupValueStatusWithAbsolutePath(absCgroupPath, cgroupFileName)
+}
+
 func GetMemoryWithRelativePath(relCgroupPath string) (*common.MemoryStats, error) {
 	absCgroupPath := common.GetAbsCgroupPath("memory", relCgroupPath)
 	return GetManager().GetMemory(absCgroupPath)
@@ -194,6 +261,22 @@ func GetMemoryPressureWithAbsolutePath(absCgroupPath string, t common.PressureTy
 	return GetManager().GetMemoryPressure(absCgroupPath, t)
 }
 
+func GetMemoryEventsWithAbsolutePath(absCgroupPath string) (*common.MemoryEvents, error) {
+	return GetManager().GetMemoryEvents(absCgroupPath)
+}
+
+func GetSwapWithAbsolutePath(absCgroupPath string) (*common.SwapStats, error) {
+	return GetManager().GetSwap(absCgroupPath)
+}
+
+func GetCPUPressureWithAbsolutePath(absCgroupPath string, t common.PressureType) (*common.Pressure, error) {
+	return GetManager().GetCPUPressure(absCgroupPath, t)
+}
+
+func GetIOPressureWithAbsolutePath(absCgroupPath string, t common.PressureType) (*common.Pressure, error) {
+	return GetManager().GetIOPressure(absCgroupPath, t)
+}
+
 func GetIOCostQoSWithRelativePath(relCgroupPath string) (map[string]*common.IOCostQoSData, error) {
 	absCgroupPath := common.GetAbsCgroupPath(common.CgroupSubsysIO, relCgroupPath)
 	return GetIOCostQoSWithAbsolutePath(absCgroupPath)
@@ -230,6 +313,33 @@ func GetIOStatWithAbsolutePath(absCgroupPath string) (map[string]map[string]stri
 	return GetManager().GetIOStat(absCgroupPath)
 }
 
+func GetIOThrottleWithRelativePath(relCgroupPath string) (map[string]*common.IOThrottleData, error) {
+	absCgroupPath := common.GetAbsCgroupPath(common.CgroupSubsysBlkIO, relCgroupPath)
+	return GetIOThrottleWithAbsolutePath(absCgroupPath)
+}
+
+func GetIOThrottleWithAbsolutePath(absCgroupPath string) (map[string]*common.IOThrottleData, error) {
+	return GetManager().GetIOThrottle(absCgroupPath)
+}
+
+func GetPidsStatsWithRelativePath(relCgroupPath string) (*common.PidsStats, error) {
+	absCgroupPath := common.GetAbsCgroupPath(common.CgroupSubsysPids, relCgroupPath)
+	return GetPidsStatsWithAbsolutePath(absCgroupPath)
+}
+
+func GetPidsStatsWithAbsolutePath(absCgroupPath string) (*common.PidsStats, error) {
+	return GetManager().GetPidsStats(absCgroupPath)
+}
+
+func GetHugetlbWithRelativePath(relCgroupPath string) (map[string]*common.HugetlbStats, error) {
+	absCgroupPath := common.GetAbsCgroupPath(common.CgroupSubsysHugetlb, relCgroupPath)
+	return GetHugetlbWithAbsolutePath(absCgroupPath)
+}
+
+func GetHugetlbWithAbsolutePath(absCgroupPath string) (map[string]*common.HugetlbStats, error) {
+	return GetManager().GetHugetlb(absCgroupPath)
+}
+
 func GetCPUWithRelativePath(relCgroupPath string) (*common.CPUStats, error) {
 	absCgroupPath := common.GetAbsCgroupPath("cpu", relCgroupPath)
 	return GetManager().GetCPU(absCgroupPath)
@@ -48,6 +48,18 @@ func (f *FakeCgroupManager) ApplyIOWeight(absCgroupPath string, devID string, we
 	return nil
 }
 
+func (f *FakeCgroupManager) ApplyIOThrottle(absCgroupPath string, devID string, data *common.IOThrottleData) error {
+	return nil
+}
+
+func (f *FakeCgroupManager) ApplyPids(absCgroupPath string, data *common.PidsData) error {
+	return nil
+}
+
+func (f *FakeCgroupManager) ApplyHugetlb(absCgroupPath string, data *common.HugetlbData) error {
+	return nil
+}
+
 func (f *FakeCgroupManager) ApplyUnifiedData(absCgroupPath, cgroupFileName, data string) error {
 	return nil
 }
@@ -64,6 +76,14 @@ func (m *FakeCgroupManager) GetMemoryPressure(absCgroupPath string, t common.Pre
 	return nil, nil
 }
 
+func (f *FakeCgroupManager) GetCPUPressure(absCgroupPath string, t common.PressureType) (*common.Pressure, error) {
+	return nil, nil
+}
+
+func (f *FakeCgroupManager) GetIOPressure(absCgroupPath string, t common.PressureType) (*common.Pressure, error) {
+	return nil, nil
+}
+
 func (f *FakeCgroupManager) GetCPU(absCgroupPath string) (*common.CPUStats, error) {
 	return nil, nil
 }
@@ -88,6 +108,26 @@ func (f *FakeCgroupManager) GetIOStat(absCgroupPath string) (map[string]map[stri
 	return nil, nil
 }
 
+func (f *FakeCgroupManager) GetIOThrottle(absCgroupPath string) (map[string]*common.IOThrottleData, error) {
+	return nil, nil
+}
+
+func (f *FakeCgroupManager) GetMemoryEvents(absCgroupPath string) (*common.MemoryEvents, error) {
+	return nil, nil
+}
+
+func (f *FakeCgroupManager) GetSwap(absCgroupPath string) (*common.SwapStats, error) {
+	return nil, nil
+}
+
+func (f *FakeCgroupManager) GetPidsStats(absCgroupPath string) (*common.PidsStats, error) {
+	return nil, nil
+}
+
+func (f *FakeCgroupManager) GetHugetlb(absCgroupPath string) (map[string]*common.HugetlbStats, error) {
+	return nil, nil
+}
+
 func (f *FakeCgroupManager) GetMetrics(relCgroupPath string, subsystems map[string]struct{}) (*common.CgroupMetrics, error) {
 	return nil, nil
 }
@@ -41,17 +41,27 @@ type Manager interface {
 	ApplyIOCostQoS(absCgroupPath string, devID string, data *common.IOCostQoSData) error
 	ApplyIOCostModel(absCgroupPath string, devID string, data *common.IOCostModelData) error
 	ApplyIOWeight(absCgroupPath string, devID string, weight uint64) error
+	ApplyIOThrottle(absCgroupPath string, devID string, data *common.IOThrottleData) error
+	ApplyPids(absCgroupPath string, data *common.PidsData) error
+	ApplyHugetlb(absCgroupPath string, data *common.HugetlbData) error
 	ApplyUnifiedData(absCgroupPath, cgroupFileName, data string) error
 
 	GetMemory(absCgroupPath string) (*common.MemoryStats, error)
 	GetNumaMemory(absCgroupPath string) (map[int]*common.MemoryNumaMetrics, error)
 	GetMemoryPressure(absCgroupPath string, t common.PressureType) (*common.MemoryPressure, error)
+	GetMemoryEvents(absCgroupPath string) (*common.MemoryEvents, error)
+	GetSwap(absCgroupPath string) (*common.SwapStats, error)
+	GetCPUPressure(absCgroupPath string, t common.PressureType) (*common.Pressure, error)
+	GetIOPressure(absCgroupPath string, t common.PressureType) (*common.Pressure, error)
 	GetCPU(absCgroupPath string) (*common.CPUStats, error)
 	GetCPUSet(absCgroupPath string) (*common.CPUSetStats, error)
 	GetIOCostQoS(absCgroupPath string) (map[string]*common.IOCostQoSData, error)
 	GetIOCostModel(absCgroupPath string) (map[string]*common.IOCostModelData, error)
 	GetDeviceIOWeight(absCgroupPath string, devID string) (uint64, bool, error)
 	GetIOStat(absCgroupPath string) (map[string]map[string]string, error)
+	GetIOThrottle(absCgroupPath string) (map[string]*common.IOThrottleData, error)
+	GetPidsStats(absCgroupPath string) (*common.PidsStats, error)
+	GetHugetlb(absCgroupPath string) (map[string]*common.HugetlbStats, error)
 	GetMetrics(relCgroupPath string, subsystems map[string]struct{}) (*common.CgroupMetrics, error)
 
 	GetPids(absCgroupPath string) ([]string, error)
@@ -0,0 +1,236 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	metricsNameCgroupDesiredValueCount = "cgroup_desired_value_count"
+	metricsNameCgroupDrift             = "cgroup_drift"
+	metricsNameCgroupReapplyFailed     = "cgroup_reapply_failed"
+	metricsNameCgroupReapplyGivenUp    = "cgroup_reapply_given_up"
+
+	metricsTagKeyCgroupFile = "cgroupFile"
+	metricsTagKeyQoSLevel   = "qosLevel"
+
+	metricsTagValueQoSLevelUnknown = "unknown"
+)
+
+const (
+	// maxCgroupReapplyAttempts is the max times that a desired value is reapplied, and the reconciler
+	// gives up reapplying it afterward, in case it fights with others (e.g. kubelet or runtime) forever.
+	maxCgroupReapplyAttempts = 8
+	// maxCgroupReapplyBackoff limits the interval between reapplying a desired value, and the attempts
+	// are reset if the value isn't drifted for it.
+	maxCgroupReapplyBackoff = 30 * time.Minute
+)
+
+// reapplyRecord records the attempts of reapplying a desired value
+type reapplyRecord struct {
+	// desiredUpdateTime identifies the desired value, and attempts are reset once it's changed
+	desiredUpdateTime time.Time
+	attempts          int
+	lastAttemptTime   time.Time
+}
+
+// DesiredStateReconciler periodically compares cgroup files with the desired values recorded
+// when they are applied, and applies the desired values again once they are overwritten by
+// others, e.g. container runtime, kubelet or operators.
+type DesiredStateReconciler struct {
+	store    *common.DesiredStateStore
+	eventBus eventbus.EventBus
+	emitter  metrics.MetricEmitter
+	interval time.Duration
+
+	// getQoSLevel returns the qos level of the pod that the cgroup belongs to, and
+	// empty string is returned if it's unknown, e.g. the cgroup doesn't belong to pods
+	getQoSLevel func(absCgroupPath string) string
+
+	// reapplyRecords maps from cgroup path and file to the attempts of reapplying, and
+	// it's only accessed by Reconcile
+	reapplyRecords map[string]map[string]*reapplyRecord
+
+	// writeFile is used to reapply desired values, and it's replaceable in tests
+	// since cgroup files can't be written out of cgroupfs
+	writeFile func(dir, file, data string) (error, bool, string)
+}
+
+func NewDesiredStateReconciler(store *common.DesiredStateStore, eventBus eventbus.EventBus,
+	emitter metrics.MetricEmitter, interval time.Duration, getQoSLevel func(absCgroupPath string) string,
+) *DesiredStateReconciler {
+	return &DesiredStateReconciler{
+		store:          store,
+		eventBus:       eventBus,
+		emitter:        emitter,
+		interval:       interval,
+		getQoSLevel:    getQoSLevel,
+		reapplyRecords: make(map[string]map[string]*reapplyRecord),
+
+		writeFile: common.InstrumentedWriteFileIfChange,
+	}
+}
+
+func (r *DesiredStateReconciler) Run(ctx context.Context) {
+	go wait.UntilWithContext(ctx, r.Reconcile, r.interval)
+	<-ctx.Done()
+}
+
+// Reconcile checks all desired values once, and values of removed cgroups are forgotten
+func (r *DesiredStateReconciler) Reconcile(_ context.Context) {
+	values := r.store.List()
+	_ = r.emitter.StoreInt64(metricsNameCgroupDesiredValueCount, int64(len(values)), metrics.MetricTypeNameRaw)
+
+	for _, value := range values {
+		status, err := r.store.GetStatus(value.AbsCgroupPath, value.CgroupFile)
+		if err != nil {
+			if !general.IsPathExists(value.AbsCgroupPath) {
+				general.Infof("cgroup %s is removed, forget its desired values", value.AbsCgroupPath)
+				r.store.ForgetPath(value.AbsCgroupPath)
+				delete(r.reapplyRecords, value.AbsCgroupPath)
+			} else {
+				general.Errorf("get status of cgroup file %s in %s failed: %v", value.CgroupFile, value.AbsCgroupPath, err)
+			}
+			continue
+		}
+
+		if status.Drifted {
+			r.reapply(status)
+		} else {
+			r.resetReapplyRecordIfStable(value)
+		}
+	}
+}
+
+func (r *DesiredStateReconciler) reapply(status *common.CgroupValueStatus) {
+	desired := status.Desired
+	// the path is logged rather than tagged in metrics to avoid unbounded cardinality
+	tags := []metrics.MetricTag{
+		{Key: metricsTagKeyCgroupFile, Val: desired.CgroupFile},
+		{Key: metricsTagKeyQoSLevel, Val: r.getCgroupQoSLevel(desired.AbsCgroupPath)},
+	}
+	_ = r.emitter.StoreInt64(metricsNameCgroupDrift, 1, metrics.MetricTypeNameCount, tags...)
+
+	now := time.Now()
+	record := r.getReapplyRecord(desired)
+	if record.attempts >= maxCgroupReapplyAttempts {
+		general.InfofV(4, "cgroup file %s in %s drifted, and reapplying is given up, desired: %q, actual: %q",
+			desired.CgroupFile, desired.AbsCgroupPath, desired.Data, status.Actual)
+		return
+	} else if backoff := r.getReapplyBackoff(record.attempts); now.Before(record.lastAttemptTime.Add(backoff)) {
+		general.InfofV(4, "cgroup file %s in %s drifted, and reapplying is backed off for %v, desired: %q, actual: %q",
+			desired.CgroupFile, desired.AbsCgroupPath, backoff, desired.Data, status.Actual)
+		return
+	}
+
+	record.attempts++
+	record.lastAttemptTime = now
+	general.Warningf("cgroup file %s in %s drifted, reapply it (attempt %d), desired: %q, actual: %q",
+		desired.CgroupFile, desired.AbsCgroupPath, record.attempts, desired.Data, status.Actual)
+	if record.attempts == maxCgroupReapplyAttempts {
+		general.Errorf("cgroup file %s in %s keeps drifting, give up reapplying it after this attempt",
+			desired.CgroupFile, desired.AbsCgroupPath)
+		_ = r.emitter.StoreInt64(metricsNameCgroupReapplyGivenUp, 1, metrics.MetricTypeNameCount, tags...)
+	}
+
+	event := eventbus.CGroupDriftEvent{
+		BaseEventImpl: eventbus.BaseEventImpl{
+			Time: time.Now(),
+		},
+		CGroupPath: desired.AbsCgroupPath,
+		CGroupFile: desired.CgroupFile,
+		Desired:    desired.Data,
+		Actual:     status.Actual,
+	}
+
+	if err, _, _ := r.writeFile(desired.AbsCgroupPath, desired.CgroupFile, desired.Data); err != nil {
+		general.Errorf("reapply cgroup file %s in %s failed: %v", desired.CgroupFile, desired.AbsCgroupPath, err)
+		_ = r.emitter.StoreInt64(metricsNameCgroupReapplyFailed, 1, metrics.MetricTypeNameCount, tags...)
+		event.Err = err.Error()
+	} else {
+		event.Reapplied = true
+	}
+
+	_ = r.eventBus.Publish(consts.TopicNameCGroupDrift, event)
+}
+
+func (r *DesiredStateReconciler) getCgroupQoSLevel(absCgroupPath string) string {
+	if r.getQoSLevel == nil {
+		return metricsTagValueQoSLevelUnknown
+	}
+
+	if qosLevel := r.getQoSLevel(absCgroupPath); qosLevel != "" {
+		return qosLevel
+	}
+	return metricsTagValueQoSLevelUnknown
+}
+
+// getReapplyRecord returns the record of the desired value, and the record of
+// the previous desired value of the same file is reset.
+func (r *DesiredStateReconciler) getReapplyRecord(desired common.DesiredCgroupValue) *reapplyRecord {
+	if r.reapplyRecords[desired.AbsCgroupPath] == nil {
+		r.reapplyRecords[desired.AbsCgroupPath] = make(map[string]*reapplyRecord)
+	}
+
+	record, ok := r.reapplyRecords[desired.AbsCgroupPath][desired.CgroupFile]
+	if !ok || !record.desiredUpdateTime.Equal(desired.UpdateTime) {
+		record = &reapplyRecord{desiredUpdateTime: desired.UpdateTime}
+		r.reapplyRecords[desired.AbsCgroupPath][desired.CgroupFile] = record
+	}
+	return record
+}
+
+// resetReapplyRecordIfStable forgets the attempts of reapplying the desired value
+// if it hasn't drifted for the max backoff
+func (r *DesiredStateReconciler) resetReapplyRecordIfStable(desired common.DesiredCgroupValue) {
+	record, ok := r.reapplyRecords[desired.AbsCgroupPath][desired.CgroupFile]
+	if !ok || time.Since(record.lastAttemptTime) < maxCgroupReapplyBackoff {
+		return
+	}
+
+	delete(r.reapplyRecords[desired.AbsCgroupPath], desired.CgroupFile)
+	if len(r.reapplyRecords[desired.AbsCgroupPath]) == 0 {
+		delete(r.reapplyRecords, desired.AbsCgroupPath)
+	}
+}
+
+// getReapplyBackoff returns the min interval after the last attempt, which
+// is doubled by each attempt
+func (r *DesiredStateReconciler) getReapplyBackoff(attempts int) time.Duration {
+	if attempts == 0 {
+		return 0
+	}
+
+	backoff := r.interval
+	for i := 1; i < attempts && backoff < maxCgroupReapplyBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxCgroupReapplyBackoff {
+		return maxCgroupReapplyBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,194 @@
+//go:build linux
+// +build linux
+
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+)
+
+func TestDesiredStateReconciler_Reconcile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		desired       string
+		actual        string
+		removeCgroup  bool
+		writeErr      error
+		wantContent   string
+		wantEvent     bool
+		wantForgotten bool
+	}{
+		{
+			name:        "drifted value is reapplied",
+			desired:     "200000 100000",
+			actual:      "max 100000",
+			wantContent: "200000 100000",
+			wantEvent:   true,
+		},
+		{
+			name:        "drifted value failed to be reapplied",
+			desired:     "200000 100000",
+			actual:      "max 100000",
+			writeErr:    fmt.Errorf("permission denied"),
+			wantContent: "max 100000",
+			wantEvent:   true,
+		},
+		{
+			name:        "value not drifted",
+			desired:     "200000 100000",
+			actual:      "200000 100000\n",
+			wantContent: "200000 100000\n",
+		},
+		{
+			name:          "removed cgroup is forgotten",
+			desired:       "200000 100000",
+			removeCgroup:  true,
+			wantForgotten: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cgroupPath := filepath.Join(t.TempDir(), "pod")
+			require.NoError(t, os.MkdirAll(cgroupPath, 0o755))
+			cgroupFile := filepath.Join(cgroupPath, "cpu.max")
+
+			store := common.NewDesiredStateStore()
+			store.Enable()
+			store.Record(cgroupPath, "cpu.max", tt.desired)
+
+			if tt.removeCgroup {
+				require.NoError(t, os.RemoveAll(cgroupPath))
+			} else {
+				require.NoError(t, os.WriteFile(cgroupFile, []byte(tt.actual), 0o644))
+			}
+
+			bus := eventbus.NewEventBus(10)
+			events := make(chan interface{}, 10)
+			require.NoError(t, bus.Subscribe(consts.TopicNameCGroupDrift, "test", 10, func(event interface{}) error {
+				events <- event
+				return nil
+			}))
+
+			reconciler := NewDesiredStateReconciler(store, bus, metrics.DummyMetrics{}, time.Second, nil)
+			reconciler.writeFile = func(dir, file, data string) (error, bool, string) {
+				if tt.writeErr != nil {
+					return tt.writeErr, false, ""
+				}
+				return os.WriteFile(filepath.Join(dir, file), []byte(data), 0o644), true, ""
+			}
+			reconciler.Reconcile(context.Background())
+
+			if tt.wantForgotten {
+				assert.Empty(t, store.List())
+				return
+			}
+
+			content, err := os.ReadFile(cgroupFile)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantContent, string(content))
+
+			if !tt.wantEvent {
+				assert.Never(t, func() bool { return len(events) > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+				return
+			}
+
+			select {
+			case event := <-events:
+				driftEvent, ok := event.(eventbus.CGroupDriftEvent)
+				require.True(t, ok)
+				assert.Equal(t, cgroupPath, driftEvent.CGroupPath)
+				assert.Equal(t, "cpu.max", driftEvent.CGroupFile)
+				assert.Equal(t, tt.desired, driftEvent.Desired)
+				assert.Equal(t, tt.actual, driftEvent.Actual)
+				assert.Equal(t, tt.writeErr == nil, driftEvent.Reapplied)
+			case <-time.After(time.Second):
+				t.Fatal("no drift event is published")
+			}
+		})
+	}
+}
+
+func TestDesiredStateReconciler_ReapplyBackoff(t *testing.T) {
+	t.Parallel()
+
+	cgroupPath := filepath.Join(t.TempDir(), "pod")
+	require.NoError(t, os.MkdirAll(cgroupPath, 0o755))
+	cgroupFile := filepath.Join(cgroupPath, "cpu.max")
+
+	store := common.NewDesiredStateStore()
+	store.Enable()
+	store.Record(cgroupPath, "cpu.max", "200000 100000")
+
+	reconciler := NewDesiredStateReconciler(store, eventbus.NewEventBus(10), metrics.DummyMetrics{}, time.Second,
+		func(_ string) string { return "shared_cores" })
+	attempts := 0
+	reconciler.writeFile = func(dir, file, data string) (error, bool, string) {
+		attempts++
+		return nil, true, ""
+	}
+
+	// the value is overwritten by others right after each attempt
+	require.NoError(t, os.WriteFile(cgroupFile, []byte("max 100000"), 0o644))
+	reconciler.Reconcile(context.Background())
+	assert.Equal(t, 1, attempts)
+
+	// backed off until the interval after last attempt
+	reconciler.Reconcile(context.Background())
+	assert.Equal(t, 1, attempts)
+
+	record := reconciler.reapplyRecords[cgroupPath]["cpu.max"]
+	for i := 1; i < maxCgroupReapplyAttempts+2; i++ {
+		record.lastAttemptTime = record.lastAttemptTime.Add(-maxCgroupReapplyBackoff)
+		reconciler.Reconcile(context.Background())
+	}
+	assert.Equal(t, maxCgroupReapplyAttempts, attempts)
+
+	// attempts are reset once the desired value is changed
+	store.Record(cgroupPath, "cpu.max", "300000 100000")
+	reconciler.Reconcile(context.Background())
+	assert.Equal(t, maxCgroupReapplyAttempts+1, attempts)
+
+	// attempts are forgotten once the value keeps stable
+	require.NoError(t, os.WriteFile(cgroupFile, []byte("300000 100000"), 0o644))
+	reconciler.reapplyRecords[cgroupPath]["cpu.max"].lastAttemptTime = time.Now().Add(-maxCgroupReapplyBackoff)
+	reconciler.Reconcile(context.Background())
+	assert.Empty(t, reconciler.reapplyRecords)
+
+	assert.Equal(t, time.Second, reconciler.getReapplyBackoff(1))
+	assert.Equal(t, 4*time.Second, reconciler.getReapplyBackoff(3))
+	assert.Equal(t, maxCgroupReapplyBackoff, reconciler.getReapplyBackoff(100))
+}
@@ -37,6 +37,13 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/util/general"
 )
 
+const (
+	blkioThrottleReadBPSFile   = "blkio.throttle.read_bps_device"
+	blkioThrottleWriteBPSFile  = "blkio.throttle.write_bps_device"
+	blkioThrottleReadIOPSFile  = "blkio.throttle.read_iops_device"
+	blkioThrottleWriteIOPSFile = "blkio.throttle.write_iops_device"
+)
+
 type manager struct{}
 
 // NewManager return a manager for cgroupv1
@@ -206,6 +213,89 @@ func (m *manager) ApplyIOWeight(absCgroupPath string, devID string, weight uint6
 	return errors.New("cgroups v1 does not support io.weight")
 }
 
+// ApplyIOThrottle applies io throttle limits of the device to blkio.throttle files,
+// and writing zero to the files removes the limits.
+func (m *manager) ApplyIOThrottle(absCgroupPath string, devID string, data *common.IOThrottleData) error {
+	curThrottles, err := m.GetIOThrottle(absCgroupPath)
+	if err != nil {
+		return fmt.Errorf("try GetIOThrottle before ApplyIOThrottle failed with error: %v", err)
+	}
+
+	curThrottle, found := curThrottles[devID]
+	if !found {
+		curThrottle = &common.IOThrottleData{}
+	}
+
+	for _, throttleFile := range []struct {
+		name    string
+		current uint64
+		value   uint64
+	}{
+		{name: blkioThrottleReadBPSFile, current: curThrottle.ReadBPS, value: data.ReadBPS},
+		{name: blkioThrottleWriteBPSFile, current: curThrottle.WriteBPS, value: data.WriteBPS},
+		{name: blkioThrottleReadIOPSFile, current: curThrottle.ReadIOPS, value: data.ReadIOPS},
+		{name: blkioThrottleWriteIOPSFile, current: curThrottle.WriteIOPS, value: data.WriteIOPS},
+	} {
+		if throttleFile.current == throttleFile.value {
+			continue
+		}
+
+		dataContent := fmt.Sprintf("%s %d", devID, throttleFile.value)
+		if err, applied, oldData := common.InstrumentedWriteFileIfChange(absCgroupPath, throttleFile.name, dataContent); err != nil {
+			return err
+		} else if applied {
+			klog.Infof("[CgroupV1] apply %s for device: %s successfully, cgroupPath: %s, data: %v, old data: %v\n",
+				throttleFile.name, devID, absCgroupPath, dataContent, oldData)
+		}
+	}
+
+	return nil
+}
+
+func (m *manager) ApplyPids(absCgroupPath string, data *common.PidsData) error {
+	if data.MaxPids != 0 {
+		maxPids := "max"
+		if data.MaxPids > 0 {
+			maxPids = strconv.FormatInt(data.MaxPids, 10)
+		}
+
+		if err, applied, oldData := common.InstrumentedWriteFileIfChange(absCgroupPath, "pids.max", maxPids); err != nil {
+			return err
+		} else if applied {
+			klog.Infof("[CgroupV1] apply pids.max successfully, cgroupPath: %s, data: %v, old data: %v\n", absCgroupPath, maxPids, oldData)
+		}
+	}
+
+	return nil
+}
+
+func (m *manager) ApplyHugetlb(absCgroupPath string, data *common.HugetlbData) error {
+	if data.PageSize == "" {
+		return fmt.Errorf("empty huge page size")
+	}
+
+	limit := strconv.FormatInt(data.LimitInBytes, 10)
+	if data.LimitInBytes < 0 {
+		limit = "-1"
+	}
+
+	limitFile := fmt.Sprintf("hugetlb.%s.limit_in_bytes", data.PageSize)
+	// kernel reads limits back rounded down to huge pages (e.g. -1 as 9223372036854771712), so the
+	// file is only written if the current limit differs from the desired one
+	if current, err := libcgroups.ReadFile(absCgroupPath, limitFile); err == nil && !common.IsCgroupValueDrifted(limitFile, limit, current) {
+		common.GetDesiredStateStore().Record(absCgroupPath, limitFile, limit)
+		return nil
+	}
+
+	if err, applied, oldData := common.InstrumentedWriteFileIfChange(absCgroupPath, limitFile, limit); err != nil {
+		return err
+	} else if applied {
+		klog.Infof("[CgroupV1] apply %s successfully, cgroupPath: %s, data: %v, old data: %v\n", limitFile, absCgroupPath, limit, oldData)
+	}
+
+	return nil
+}
+
 func (m *manager) ApplyUnifiedData(absCgroupPath, cgroupFileName, data string) error {
 	if err, applied, oldData := common.InstrumentedWriteFileIfChange(absCgroupPath, cgroupFileName, data); err != nil {
 		return err
@@ -290,6 +380,32 @@ func (m *manager) GetMemoryPressure(absCgroupPath string, t common.PressureType)
 	return memoryPressure, nil
 }
 
+// GetMemoryEvents reads memory.oom_control, and since cgroups v1 doesn't count
+// the times that memory usage hits the limit, OOM is the same as OOMKill.
+func (m *manager) GetMemoryEvents(absCgroupPath string) (*common.MemoryEvents, error) {
+	oomKill, err := fscommon.GetValueByKey(absCgroupPath, "memory.oom_control", "oom_kill")
+	if err != nil {
+		return nil, fmt.Errorf("get oom kill %s err, %v", absCgroupPath, err)
+	}
+
+	return &common.MemoryEvents{
+		OOM:     oomKill,
+		OOMKill: oomKill,
+	}, nil
+}
+
+func (m *manager) GetSwap(absCgroupPath string) (*common.SwapStats, error) {
+	return nil, errors.New("cgroups v1 does not support memory.swap.current")
+}
+
+func (m *manager) GetCPUPressure(absCgroupPath string, t common.PressureType) (*common.Pressure, error) {
+	return nil, errors.New("cgroups v1 does not support cpu.pressure")
+}
+
+func (m *manager) GetIOPressure(absCgroupPath string, t common.PressureType) (*common.Pressure, error) {
+	return nil, errors.New("cgroups v1 does not support io.pressure")
+}
+
 func (m *manager) GetCPU(absCgroupPath string) (*common.CPUStats, error) {
 	cpuStats := &common.CPUStats{}
 
@@ -349,6 +465,97 @@ func (m *manager) GetIOStat(absCgroupPath string) (map[string]map[string]string,
 	return nil, errors.New("cgroups v1 does not support io.stat")
 }
 
+func (m *manager) GetIOThrottle(absCgroupPath string) (map[string]*common.IOThrottleData, error) {
+	devIDtoThrottle := make(map[string]*common.IOThrottleData)
+	for _, throttleFile := range []struct {
+		name  string
+		apply func(data *common.IOThrottleData, value uint64)
+	}{
+		{name: blkioThrottleReadBPSFile, apply: func(data *common.IOThrottleData, value uint64) { data.ReadBPS = value }},
+		{name: blkioThrottleWriteBPSFile, apply: func(data *common.IOThrottleData, value uint64) { data.WriteBPS = value }},
+		{name: blkioThrottleReadIOPSFile, apply: func(data *common.IOThrottleData, value uint64) { data.ReadIOPS = value }},
+		{name: blkioThrottleWriteIOPSFile, apply: func(data *common.IOThrottleData, value uint64) { data.WriteIOPS = value }},
+	} {
+		contents, err := libcgroups.ReadFile(absCgroupPath, throttleFile.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", throttleFile.name, err)
+		}
+
+		for _, line := range strings.Split(contents, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			} else if len(fields) != 2 {
+				general.Errorf("invalid line %s in %s", line, throttleFile.name)
+				continue
+			}
+
+			value, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				general.Errorf("invalid line %s in %s, err %v", line, throttleFile.name, err)
+				continue
+			}
+
+			devID := fields[0]
+			if _, ok := devIDtoThrottle[devID]; !ok {
+				devIDtoThrottle[devID] = &common.IOThrottleData{}
+			}
+			throttleFile.apply(devIDtoThrottle[devID], value)
+		}
+	}
+
+	return devIDtoThrottle, nil
+}
+
+func (m *manager) GetPidsStats(absCgroupPath string) (*common.PidsStats, error) {
+	pidsStats := &common.PidsStats{}
+
+	current, err := fscommon.GetCgroupParamUint(absCgroupPath, "pids.current")
+	if err != nil {
+		return nil, fmt.Errorf("get pids current %s err, %v", absCgroupPath, err)
+	}
+	pidsStats.Current = current
+
+	limit, err := fscommon.GetCgroupParamUint(absCgroupPath, "pids.max")
+	if err != nil {
+		return nil, fmt.Errorf("get pids max %s err, %v", absCgroupPath, err)
+	}
+	pidsStats.Limit = limit
+
+	return pidsStats, nil
+}
+
+func (m *manager) GetHugetlb(absCgroupPath string) (map[string]*common.HugetlbStats, error) {
+	pageSizes, err := common.GetHugetlbPageSizes(absCgroupPath, "limit_in_bytes")
+	if err != nil {
+		return nil, err
+	}
+
+	pageSizeToStats := make(map[string]*common.HugetlbStats)
+	for _, pageSize := range pageSizes {
+		hugetlbStats := &common.HugetlbStats{}
+
+		hugetlbStats.Limit, err = fscommon.GetCgroupParamUint(absCgroupPath, fmt.Sprintf("hugetlb.%s.limit_in_bytes", pageSize))
+		if err != nil {
+			return nil, err
+		}
+
+		hugetlbStats.Usage, err = fscommon.GetCgroupParamUint(absCgroupPath, fmt.Sprintf("hugetlb.%s.usage_in_bytes", pageSize))
+		if err != nil {
+			return nil, err
+		}
+
+		hugetlbStats.Failcnt, err = fscommon.GetCgroupParamUint(absCgroupPath, fmt.Sprintf("hugetlb.%s.failcnt", pageSize))
+		if err != nil {
+			return nil, err
+		}
+
+		pageSizeToStats[pageSize] = hugetlbStats
+	}
+
+	return pageSizeToStats, nil
+}
+
 func (m *manager) GetMetrics(relCgroupPath string, subsystemMap map[string]struct{}) (*common.CgroupMetrics, error) {
 	errOmit := func(err error) error {
 		return nil
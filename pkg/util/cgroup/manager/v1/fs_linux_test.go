@@ -20,9 +20,18 @@ limitations under the License.
 package v1
 
 import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
+	libcgroups "github.com/opencontainers/runc/libcontainer/cgroups"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
 )
 
@@ -97,3 +106,182 @@ func Test_manager_ApplyMemory(t *testing.T) {
 		})
 	}
 }
+
+// writeCgroupFiles creates a fake cgroup directory with the given files
+func writeCgroupFiles(t *testing.T, files map[string]string) string {
+	libcgroups.TestMode = true
+
+	absCgroupPath := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(absCgroupPath, name), []byte(content), 0o644))
+	}
+	return absCgroupPath
+}
+
+func readCgroupFile(t *testing.T, absCgroupPath, name string) string {
+	content, err := os.ReadFile(filepath.Join(absCgroupPath, name))
+	require.NoError(t, err)
+	return string(content)
+}
+
+func Test_manager_IOThrottle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		files     map[string]string
+		devID     string
+		data      *common.IOThrottleData
+		wantFiles map[string]string
+	}{
+		{
+			name: "apply limits of new device",
+			files: map[string]string{
+				blkioThrottleReadBPSFile:   "8:16 1000\n",
+				blkioThrottleWriteBPSFile:  "",
+				blkioThrottleReadIOPSFile:  "",
+				blkioThrottleWriteIOPSFile: "",
+			},
+			devID: "8:0",
+			data:  &common.IOThrottleData{ReadBPS: 2097152, WriteIOPS: 120},
+			wantFiles: map[string]string{
+				blkioThrottleReadBPSFile:   "8:0 2097152",
+				blkioThrottleWriteBPSFile:  "",
+				blkioThrottleReadIOPSFile:  "",
+				blkioThrottleWriteIOPSFile: "8:0 120",
+			},
+		},
+		{
+			name: "remove limits of device",
+			files: map[string]string{
+				blkioThrottleReadBPSFile:   "8:0 2097152\n",
+				blkioThrottleWriteBPSFile:  "",
+				blkioThrottleReadIOPSFile:  "",
+				blkioThrottleWriteIOPSFile: "8:0 120\n",
+			},
+			devID: "8:0",
+			data:  &common.IOThrottleData{WriteIOPS: 120},
+			wantFiles: map[string]string{
+				blkioThrottleReadBPSFile:   "8:0 0",
+				blkioThrottleWriteBPSFile:  "",
+				blkioThrottleReadIOPSFile:  "",
+				blkioThrottleWriteIOPSFile: "8:0 120\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			absCgroupPath := writeCgroupFiles(t, tt.files)
+			m := NewManager()
+			assert.NoError(t, m.ApplyIOThrottle(absCgroupPath, tt.devID, tt.data))
+			for name, content := range tt.wantFiles {
+				assert.Equal(t, content, readCgroupFile(t, absCgroupPath, name), name)
+			}
+
+			// kernel drops lines of devices without limits
+			for name := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(absCgroupPath, name),
+					[]byte(strings.ReplaceAll(readCgroupFile(t, absCgroupPath, name), "8:0 0", "")), 0o644))
+			}
+			got, err := m.GetIOThrottle(absCgroupPath)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.data, got[tt.devID])
+		})
+	}
+}
+
+func Test_manager_Pids(t *testing.T) {
+	t.Parallel()
+
+	absCgroupPath := writeCgroupFiles(t, map[string]string{
+		"pids.max":     "max\n",
+		"pids.current": "12\n",
+	})
+
+	m := NewManager()
+	stats, err := m.GetPidsStats(absCgroupPath)
+	assert.NoError(t, err)
+	assert.Equal(t, &common.PidsStats{Current: 12, Limit: math.MaxUint64}, stats)
+
+	assert.NoError(t, m.ApplyPids(absCgroupPath, &common.PidsData{MaxPids: 1024}))
+	stats, err = m.GetPidsStats(absCgroupPath)
+	assert.NoError(t, err)
+	assert.Equal(t, &common.PidsStats{Current: 12, Limit: 1024}, stats)
+}
+
+func Test_manager_Hugetlb(t *testing.T) {
+	t.Parallel()
+
+	absCgroupPath := writeCgroupFiles(t, map[string]string{
+		"hugetlb.2MB.limit_in_bytes":      "9223372036854771712\n",
+		"hugetlb.2MB.usage_in_bytes":      "4194304\n",
+		"hugetlb.2MB.failcnt":             "3\n",
+		"hugetlb.2MB.rsvd.limit_in_bytes": "9223372036854771712\n",
+	})
+
+	m := NewManager()
+	assert.NoError(t, m.ApplyHugetlb(absCgroupPath, &common.HugetlbData{PageSize: "2MB", LimitInBytes: 8388608}))
+	assert.Equal(t, "8388608", readCgroupFile(t, absCgroupPath, "hugetlb.2MB.limit_in_bytes"))
+
+	stats, err := m.GetHugetlb(absCgroupPath)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*common.HugetlbStats{
+		"2MB": {Limit: 8388608, Usage: 4194304, Failcnt: 3},
+	}, stats)
+
+	assert.NoError(t, m.ApplyHugetlb(absCgroupPath, &common.HugetlbData{PageSize: "2MB", LimitInBytes: -1}))
+	assert.Equal(t, "-1", readCgroupFile(t, absCgroupPath, "hugetlb.2MB.limit_in_bytes"))
+}
+
+func Test_manager_HugetlbRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	const limitFile = "hugetlb.2MB.limit_in_bytes"
+	tests := []struct {
+		name         string
+		limitInBytes int64
+		readBack     string
+	}{
+		{
+			name:         "unlimited",
+			limitInBytes: -1,
+			readBack:     "9223372036854771712\n",
+		},
+		{
+			name:         "limit rounded down to huge pages",
+			limitInBytes: 5 << 20,
+			readBack:     fmt.Sprintf("%d\n", 4<<20),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			absCgroupPath := writeCgroupFiles(t, map[string]string{limitFile: "0\n"})
+			m := NewManager()
+			require.NoError(t, m.ApplyHugetlb(absCgroupPath, &common.HugetlbData{PageSize: "2MB", LimitInBytes: tt.limitInBytes}))
+			written := readCgroupFile(t, absCgroupPath, limitFile)
+
+			// the value read back by kernel isn't regarded as drifted, and it's not rewritten by later applies
+			require.NoError(t, os.WriteFile(filepath.Join(absCgroupPath, limitFile), []byte(tt.readBack), 0o644))
+			assert.False(t, common.IsCgroupValueDrifted(limitFile, written, readCgroupFile(t, absCgroupPath, limitFile)))
+
+			require.NoError(t, m.ApplyHugetlb(absCgroupPath, &common.HugetlbData{PageSize: "2MB", LimitInBytes: tt.limitInBytes}))
+			assert.Equal(t, tt.readBack, readCgroupFile(t, absCgroupPath, limitFile))
+		})
+	}
+}
+
+func Test_manager_GetPressure(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager()
+	_, err := m.GetCPUPressure("test-fake-path", common.SOME)
+	assert.Error(t, err)
+	_, err = m.GetIOPressure("test-fake-path", common.SOME)
+	assert.Error(t, err)
+}
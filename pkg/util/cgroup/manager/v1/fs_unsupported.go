@@ -60,6 +60,18 @@ func (m *unsupportedManager) ApplyIOWeight(absCgroupPath string, devID string, w
 	return fmt.Errorf("unsupported manager v1")
 }
 
+func (m *unsupportedManager) ApplyIOThrottle(_ string, _ string, _ *common.IOThrottleData) error {
+	return fmt.Errorf("unsupported manager v1")
+}
+
+func (m *unsupportedManager) ApplyPids(_ string, _ *common.PidsData) error {
+	return fmt.Errorf("unsupported manager v1")
+}
+
+func (m *unsupportedManager) ApplyHugetlb(_ string, _ *common.HugetlbData) error {
+	return fmt.Errorf("unsupported manager v1")
+}
+
 func (m *unsupportedManager) ApplyUnifiedData(absCgroupPath, cgroupFileName, data string) error {
 	return fmt.Errorf("unsupported manager v1")
 }
@@ -76,6 +88,14 @@ func (m *unsupportedManager) GetMemoryPressure(absCgroupPath string, t common.Pr
 	return nil, fmt.Errorf("unsupported manager v1")
 }
 
+func (m *unsupportedManager) GetCPUPressure(_ string, _ common.PressureType) (*common.Pressure, error) {
+	return nil, fmt.Errorf("unsupported manager v1")
+}
+
+func (m *unsupportedManager) GetIOPressure(_ string, _ common.PressureType) (*common.Pressure, error) {
+	return nil, fmt.Errorf("unsupported manager v1")
+}
+
 func (m *unsupportedManager) GetCPU(_ string) (*common.CPUStats, error) {
 	return nil, fmt.Errorf("unsupported manager v1")
 }
@@ -104,6 +124,26 @@ func (m *unsupportedManager) GetMetrics(_ string, _ map[string]struct{}) (*commo
 	return nil, fmt.Errorf("unsupported manager v1")
 }
 
+func (m *unsupportedManager) GetIOThrottle(_ string) (map[string]*common.IOThrottleData, error) {
+	return nil, fmt.Errorf("unsupported manager v1")
+}
+
+func (m *unsupportedManager) GetMemoryEvents(_ string) (*common.MemoryEvents, error) {
+	return nil, fmt.Errorf("unsupported manager v1")
+}
+
+func (m *unsupportedManager) GetSwap(_ string) (*common.SwapStats, error) {
+	return nil, fmt.Errorf("unsupported manager v1")
+}
+
+func (m *unsupportedManager) GetPidsStats(_ string) (*common.PidsStats, error) {
+	return nil, fmt.Errorf("unsupported manager v1")
+}
+
+func (m *unsupportedManager) GetHugetlb(_ string) (map[string]*common.HugetlbStats, error) {
+	return nil, fmt.Errorf("unsupported manager v1")
+}
+
 func (m *unsupportedManager) GetPids(_ string) ([]string, error) {
 	return nil, fmt.Errorf("unsupported manager v1")
 }
@@ -94,6 +94,19 @@ func (m *manager) ApplyMemory(absCgroupPath string, data *common.MemoryData) err
 			klog.Infof("[CgroupV2] apply memory swap max successfully, cgroupPath: %s, data: %v, old data: %v\n", absCgroupPath, swapMax, oldData)
 		}
 	}
+
+	if data.ZswapMaxInBytes != 0 {
+		// Do Not change zswap max setting if ZswapMaxInBytes equals to 0
+		var zswapMax int64 = 0
+		if data.ZswapMaxInBytes > 0 {
+			zswapMax = data.ZswapMaxInBytes
+		}
+		if err, applied, oldData := common.InstrumentedWriteFileIfChange(absCgroupPath, "memory.zswap.max", fmt.Sprintf("%d", zswapMax)); err != nil {
+			return err
+		} else if applied {
+			klog.Infof("[CgroupV2] apply memory zswap max successfully, cgroupPath: %s, data: %v, old data: %v\n", absCgroupPath, zswapMax, oldData)
+		}
+	}
 	return nil
 }
 
@@ -249,6 +262,73 @@ func (m *manager) ApplyIOWeight(absCgroupPath string, devID string, weight uint6
 	return nil
 }
 
+func (m *manager) ApplyIOThrottle(absCgroupPath string, devID string, data *common.IOThrottleData) error {
+	curThrottles, err := m.GetIOThrottle(absCgroupPath)
+	if err != nil {
+		return fmt.Errorf("try GetIOThrottle before ApplyIOThrottle failed with error: %v", err)
+	}
+
+	// devices without any limit are not listed in io.max
+	curThrottle, found := curThrottles[devID]
+	if !found {
+		curThrottle = &common.IOThrottleData{}
+	}
+
+	if *curThrottle == *data {
+		klog.Infof("[CgroupV2] io.max: %+v in cgroupPath: %s for device: %s isn't changed, not to apply it",
+			*curThrottle, absCgroupPath, devID)
+		return nil
+	}
+
+	dataContent := fmt.Sprintf("%s rbps=%s wbps=%s riops=%s wiops=%s", devID, ioMaxValueToStr(data.ReadBPS),
+		ioMaxValueToStr(data.WriteBPS), ioMaxValueToStr(data.ReadIOPS), ioMaxValueToStr(data.WriteIOPS))
+	if err, applied, oldData := common.InstrumentedWriteFileIfChange(absCgroupPath, "io.max", dataContent); err != nil {
+		return err
+	} else if applied {
+		klog.Infof("[CgroupV2] apply io.max for device: %s successfully,"+
+			"cgroupPath: %s, added data: %s, old data: %s\n", devID, absCgroupPath, dataContent, oldData)
+	}
+
+	return nil
+}
+
+func (m *manager) ApplyPids(absCgroupPath string, data *common.PidsData) error {
+	if data.MaxPids != 0 {
+		maxPids := "max"
+		if data.MaxPids > 0 {
+			maxPids = strconv.FormatInt(data.MaxPids, 10)
+		}
+
+		if err, applied, oldData := common.InstrumentedWriteFileIfChange(absCgroupPath, "pids.max", maxPids); err != nil {
+			return err
+		} else if applied {
+			klog.Infof("[CgroupV2] apply pids.max successfully, cgroupPath: %s, data: %v, old data: %v\n", absCgroupPath, maxPids, oldData)
+		}
+	}
+
+	return nil
+}
+
+func (m *manager) ApplyHugetlb(absCgroupPath string, data *common.HugetlbData) error {
+	if data.PageSize == "" {
+		return fmt.Errorf("empty huge page size")
+	}
+
+	limit := "max"
+	if data.LimitInBytes >= 0 {
+		limit = strconv.FormatInt(data.LimitInBytes, 10)
+	}
+
+	limitFile := fmt.Sprintf("hugetlb.%s.max", data.PageSize)
+	if err, applied, oldData := common.InstrumentedWriteFileIfChange(absCgroupPath, limitFile, limit); err != nil {
+		return err
+	} else if applied {
+		klog.Infof("[CgroupV2] apply %s successfully, cgroupPath: %s, data: %v, old data: %v\n", limitFile, absCgroupPath, limit, oldData)
+	}
+
+	return nil
+}
+
 func (m *manager) ApplyUnifiedData(absCgroupPath, cgroupFileName, data string) error {
 	if err, applied, oldData := common.InstrumentedWriteFileIfChange(absCgroupPath, cgroupFileName, data); err != nil {
 		return err
@@ -345,10 +425,10 @@ const (
 )
 
 func getPsiFormat(lines []string) PsiFormat {
-	if strings.Contains(lines[0], "avg10") {
-		return UPSTREAM
-	} else if len(lines) == 0 {
+	if len(lines) == 0 {
 		return MISSING
+	} else if strings.Contains(lines[0], "avg10") {
+		return UPSTREAM
 	}
 	return INVALID
 }
@@ -365,7 +445,7 @@ func atou64(s string) uint64 {
 	return uint64(f)
 }
 
-func readRespressureFromLines(lines []string, t common.PressureType) (*common.MemoryPressure, error) {
+func readRespressureFromLines(lines []string, t common.PressureType) (*common.Pressure, error) {
 	typeName := pressureTypeToString(t)
 	var pressureLineIndex int
 	switch t {
@@ -377,7 +457,15 @@ func readRespressureFromLines(lines []string, t common.PressureType) (*common.Me
 
 	switch getPsiFormat(lines) {
 	case UPSTREAM:
+		// full line is missing in cpu.pressure of old kernels
+		if pressureLineIndex >= len(lines) {
+			return nil, fmt.Errorf("missing %s pressure", typeName)
+		}
+
 		toks := split(lines[pressureLineIndex], " ")
+		if len(toks) != 5 {
+			return nil, errors.New("invalid pressure format")
+		}
 		if toks[0] != typeName {
 			return nil, errors.New("invalid type name")
 		}
@@ -397,10 +485,11 @@ func readRespressureFromLines(lines []string, t common.PressureType) (*common.Me
 		if total[0] != "total" {
 			return nil, errors.New("invalid total format")
 		}
-		return &common.MemoryPressure{
+		return &common.Pressure{
 			Avg10:  atou64(avg10[1]),
 			Avg60:  atou64(avg60[1]),
 			Avg300: atou64(avg300[1]),
+			Total:  atou64(total[1]),
 		}, nil
 
 	case MISSING:
@@ -411,8 +500,8 @@ func readRespressureFromLines(lines []string, t common.PressureType) (*common.Me
 	return nil, errors.New("unreachable")
 }
 
-func (m *manager) GetMemoryPressure(absCgroupPath string, t common.PressureType) (*common.MemoryPressure, error) {
-	pressureFile := filepath.Join(absCgroupPath, "memory.pressure")
+func readPressure(absCgroupPath, pressureFileName string, t common.PressureType) (*common.Pressure, error) {
+	pressureFile := filepath.Join(absCgroupPath, pressureFileName)
 
 	file, err := os.Open(pressureFile)
 	if err != nil {
@@ -431,6 +520,66 @@ func (m *manager) GetMemoryPressure(absCgroupPath string, t common.PressureType)
 	return readRespressureFromLines(lines, t)
 }
 
+func (m *manager) GetMemoryPressure(absCgroupPath string, t common.PressureType) (*common.MemoryPressure, error) {
+	return readPressure(absCgroupPath, "memory.pressure", t)
+}
+
+func (m *manager) GetMemoryEvents(absCgroupPath string) (*common.MemoryEvents, error) {
+	memoryEvents := &common.MemoryEvents{}
+
+	oom, err := fscommon.GetValueByKey(absCgroupPath, "memory.events", "oom")
+	if err != nil {
+		return nil, fmt.Errorf("get oom %s err, %v", absCgroupPath, err)
+	}
+	memoryEvents.OOM = oom
+
+	oomKill, err := fscommon.GetValueByKey(absCgroupPath, "memory.events", "oom_kill")
+	if err != nil {
+		return nil, fmt.Errorf("get oom kill %s err, %v", absCgroupPath, err)
+	}
+	memoryEvents.OOMKill = oomKill
+
+	return memoryEvents, nil
+}
+
+// GetSwap reads swap usage and limit of the cgroup, and zswap stats are
+// left as zero if the kernel doesn't support zswap
+func (m *manager) GetSwap(absCgroupPath string) (*common.SwapStats, error) {
+	swapStats := &common.SwapStats{}
+
+	var err error
+	swapStats.SwapUsage, err = fscommon.GetCgroupParamUint(absCgroupPath, "memory.swap.current")
+	if err != nil {
+		return nil, fmt.Errorf("get swap usage %s err, %v", absCgroupPath, err)
+	}
+	swapStats.SwapLimit, err = fscommon.GetCgroupParamUint(absCgroupPath, "memory.swap.max")
+	if err != nil {
+		return nil, fmt.Errorf("get swap limit %s err, %v", absCgroupPath, err)
+	}
+
+	if !general.IsPathExists(filepath.Join(absCgroupPath, "memory.zswap.current")) {
+		return swapStats, nil
+	}
+	swapStats.ZswapUsage, err = fscommon.GetCgroupParamUint(absCgroupPath, "memory.zswap.current")
+	if err != nil {
+		return nil, fmt.Errorf("get zswap usage %s err, %v", absCgroupPath, err)
+	}
+	swapStats.ZswapLimit, err = fscommon.GetCgroupParamUint(absCgroupPath, "memory.zswap.max")
+	if err != nil {
+		return nil, fmt.Errorf("get zswap limit %s err, %v", absCgroupPath, err)
+	}
+
+	return swapStats, nil
+}
+
+func (m *manager) GetCPUPressure(absCgroupPath string, t common.PressureType) (*common.Pressure, error) {
+	return readPressure(absCgroupPath, "cpu.pressure", t)
+}
+
+func (m *manager) GetIOPressure(absCgroupPath string, t common.PressureType) (*common.Pressure, error) {
+	return readPressure(absCgroupPath, "io.pressure", t)
+}
+
 func (m *manager) GetCPUSet(absCgroupPath string) (*common.CPUSetStats, error) {
 	cpusetStats := &common.CPUSetStats{}
 
@@ -621,6 +770,83 @@ func (m *manager) GetIOStat(absCgroupPath string) (map[string]map[string]string,
 	return devIDtoIOStat, nil
 }
 
+func (m *manager) GetIOThrottle(absCgroupPath string) (map[string]*common.IOThrottleData, error) {
+	ioMaxFile := path.Join(absCgroupPath, "io.max")
+	contents, err := ioutil.ReadFile(ioMaxFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ReadFile %s, err %v", ioMaxFile, err)
+	}
+
+	rawStr := strings.TrimRight(string(contents), "\n")
+	ioMaxStrList := strings.Split(rawStr, "\n")
+
+	devIDtoThrottle := make(map[string]*common.IOThrottleData)
+	for _, str := range ioMaxStrList {
+		if strings.TrimSpace(str) == "" {
+			continue
+		}
+
+		devID, data, err := parseDeviceIOMax(str)
+		if err != nil {
+			general.Errorf("invalid device io max %s, err %v", str, err)
+			continue
+		}
+
+		devIDtoThrottle[devID] = data
+	}
+
+	return devIDtoThrottle, nil
+}
+
+func (m *manager) GetPidsStats(absCgroupPath string) (*common.PidsStats, error) {
+	pidsStats := &common.PidsStats{}
+
+	current, err := fscommon.GetCgroupParamUint(absCgroupPath, "pids.current")
+	if err != nil {
+		return nil, fmt.Errorf("get pids current %s err, %v", absCgroupPath, err)
+	}
+	pidsStats.Current = current
+
+	limit, err := fscommon.GetCgroupParamUint(absCgroupPath, "pids.max")
+	if err != nil {
+		return nil, fmt.Errorf("get pids max %s err, %v", absCgroupPath, err)
+	}
+	pidsStats.Limit = limit
+
+	return pidsStats, nil
+}
+
+func (m *manager) GetHugetlb(absCgroupPath string) (map[string]*common.HugetlbStats, error) {
+	pageSizes, err := common.GetHugetlbPageSizes(absCgroupPath, "max")
+	if err != nil {
+		return nil, err
+	}
+
+	pageSizeToStats := make(map[string]*common.HugetlbStats)
+	for _, pageSize := range pageSizes {
+		hugetlbStats := &common.HugetlbStats{}
+
+		hugetlbStats.Limit, err = fscommon.GetCgroupParamUint(absCgroupPath, fmt.Sprintf("hugetlb.%s.max", pageSize))
+		if err != nil {
+			return nil, err
+		}
+
+		hugetlbStats.Usage, err = fscommon.GetCgroupParamUint(absCgroupPath, fmt.Sprintf("hugetlb.%s.current", pageSize))
+		if err != nil {
+			return nil, err
+		}
+
+		hugetlbStats.Failcnt, err = fscommon.GetValueByKey(absCgroupPath, fmt.Sprintf("hugetlb.%s.events", pageSize), "max")
+		if err != nil {
+			return nil, err
+		}
+
+		pageSizeToStats[pageSize] = hugetlbStats
+	}
+
+	return pageSizeToStats, nil
+}
+
 func (m *manager) GetMetrics(relCgroupPath string, _ map[string]struct{}) (*common.CgroupMetrics, error) {
 	c, err := cgroupsv2.LoadManager(common.CgroupFSMountPoint, relCgroupPath)
 	if err != nil {
@@ -714,6 +940,54 @@ func numToStr(value int64) (ret string) {
 	return ret
 }
 
+// ioMaxValueToStr converts io throttle limit to the value in io.max, and zero means unlimited
+func ioMaxValueToStr(value uint64) string {
+	if value == 0 {
+		return "max"
+	}
+	return strconv.FormatUint(value, 10)
+}
+
+func parseDeviceIOMax(str string) (string, *common.IOThrottleData, error) {
+	fields := strings.Fields(str)
+	if len(fields) < 2 {
+		return "", nil, fmt.Errorf("device io max line(%s) has no limits", str)
+	}
+
+	devID := fields[0]
+	ioThrottleData := &common.IOThrottleData{}
+	for _, o := range fields[1:] {
+		kv := strings.Split(o, "=")
+		if len(kv) != 2 {
+			return "", nil, fmt.Errorf("invalid device io max line(%s) with invalid limit %s", str, o)
+		}
+
+		var val uint64
+		if kv[1] != "max" {
+			var err error
+			val, err = strconv.ParseUint(kv[1], 10, 64)
+			if err != nil {
+				return "", nil, fmt.Errorf("device io max(%s) has invalid value(%s) for limit %s", str, kv[1], kv[0])
+			}
+		}
+
+		switch kv[0] {
+		case "rbps":
+			ioThrottleData.ReadBPS = val
+		case "wbps":
+			ioThrottleData.WriteBPS = val
+		case "riops":
+			ioThrottleData.ReadIOPS = val
+		case "wiops":
+			ioThrottleData.WriteIOPS = val
+		default:
+			return "", nil, fmt.Errorf("device io max(%s) has unknown limit %s", str, kv[0])
+		}
+	}
+
+	return devID, ioThrottleData, nil
+}
+
 func parseDeviceIOCostQoS(str string) (string, *common.IOCostQoSData, error) {
 	fields := strings.Fields(str)
 	if len(fields) != 9 {
@@ -20,9 +20,16 @@ limitations under the License.
 package v2
 
 import (
+	"math"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
+	libcgroups "github.com/opencontainers/runc/libcontainer/cgroups"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
 )
 
@@ -949,3 +956,314 @@ func Test_parseDeviceIOCostModel(t *testing.T) {
 		})
 	}
 }
+
+// writeCgroupFiles creates a fake cgroup directory with the given files
+func writeCgroupFiles(t *testing.T, files map[string]string) string {
+	libcgroups.TestMode = true
+
+	absCgroupPath := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(absCgroupPath, name), []byte(content), 0o644))
+	}
+	return absCgroupPath
+}
+
+func readCgroupFile(t *testing.T, absCgroupPath, name string) string {
+	content, err := os.ReadFile(filepath.Join(absCgroupPath, name))
+	require.NoError(t, err)
+	return string(content)
+}
+
+func Test_manager_ApplyIOThrottle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		ioMax     string
+		devID     string
+		data      *common.IOThrottleData
+		wantIOMax string
+	}{
+		{
+			name:      "apply limits of new device",
+			ioMax:     "8:16 rbps=1000 wbps=max riops=max wiops=max\n",
+			devID:     "8:0",
+			data:      &common.IOThrottleData{ReadBPS: 2097152, WriteIOPS: 120},
+			wantIOMax: "8:0 rbps=2097152 wbps=max riops=max wiops=120",
+		},
+		{
+			name:      "limits not changed",
+			ioMax:     "8:0 rbps=2097152 wbps=max riops=max wiops=120\n",
+			devID:     "8:0",
+			data:      &common.IOThrottleData{ReadBPS: 2097152, WriteIOPS: 120},
+			wantIOMax: "8:0 rbps=2097152 wbps=max riops=max wiops=120\n",
+		},
+		{
+			name:      "remove limits",
+			ioMax:     "8:0 rbps=2097152 wbps=max riops=max wiops=120\n",
+			devID:     "8:0",
+			data:      &common.IOThrottleData{},
+			wantIOMax: "8:0 rbps=max wbps=max riops=max wiops=max",
+		},
+		{
+			name:      "no limits to remove",
+			ioMax:     "",
+			devID:     "8:0",
+			data:      &common.IOThrottleData{},
+			wantIOMax: "",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			absCgroupPath := writeCgroupFiles(t, map[string]string{"io.max": tt.ioMax})
+			assert.NoError(t, NewManager().ApplyIOThrottle(absCgroupPath, tt.devID, tt.data))
+			assert.Equal(t, tt.wantIOMax, readCgroupFile(t, absCgroupPath, "io.max"))
+		})
+	}
+}
+
+func Test_manager_GetIOThrottle(t *testing.T) {
+	t.Parallel()
+
+	absCgroupPath := writeCgroupFiles(t, map[string]string{
+		"io.max": "8:0 rbps=2097152 wbps=max riops=max wiops=120\n" +
+			"8:16 rbps=max wbps=1000 riops=300 wiops=max\n" +
+			"8:32 rbps=invalid wbps=max riops=max wiops=max\n",
+	})
+
+	got, err := NewManager().GetIOThrottle(absCgroupPath)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*common.IOThrottleData{
+		"8:0":  {ReadBPS: 2097152, WriteIOPS: 120},
+		"8:16": {WriteBPS: 1000, ReadIOPS: 300},
+	}, got)
+
+	_, err = NewManager().GetIOThrottle("test-fake-path")
+	assert.Error(t, err)
+}
+
+func Test_manager_Pids(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		data          *common.PidsData
+		wantPidsMax   string
+		wantPidsLimit uint64
+	}{
+		{
+			name:          "limit pids",
+			data:          &common.PidsData{MaxPids: 1024},
+			wantPidsMax:   "1024",
+			wantPidsLimit: 1024,
+		},
+		{
+			name:          "unlimited pids",
+			data:          &common.PidsData{MaxPids: -1},
+			wantPidsMax:   "max",
+			wantPidsLimit: math.MaxUint64,
+		},
+		{
+			name:          "pids max not set",
+			data:          &common.PidsData{},
+			wantPidsMax:   "4096\n",
+			wantPidsLimit: 4096,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			absCgroupPath := writeCgroupFiles(t, map[string]string{
+				"pids.max":     "4096\n",
+				"pids.current": "12\n",
+			})
+
+			m := NewManager()
+			assert.NoError(t, m.ApplyPids(absCgroupPath, tt.data))
+			assert.Equal(t, tt.wantPidsMax, readCgroupFile(t, absCgroupPath, "pids.max"))
+
+			stats, err := m.GetPidsStats(absCgroupPath)
+			assert.NoError(t, err)
+			assert.Equal(t, &common.PidsStats{Current: 12, Limit: tt.wantPidsLimit}, stats)
+		})
+	}
+}
+
+func Test_manager_Hugetlb(t *testing.T) {
+	t.Parallel()
+
+	absCgroupPath := writeCgroupFiles(t, map[string]string{
+		"hugetlb.2MB.max":      "max\n",
+		"hugetlb.2MB.current":  "4194304\n",
+		"hugetlb.2MB.events":   "max 3\n",
+		"hugetlb.2MB.rsvd.max": "max\n",
+		"hugetlb.1GB.max":      "max\n",
+		"hugetlb.1GB.current":  "0\n",
+		"hugetlb.1GB.events":   "max 0\n",
+	})
+
+	m := NewManager()
+	assert.NoError(t, m.ApplyHugetlb(absCgroupPath, &common.HugetlbData{PageSize: "2MB", LimitInBytes: 8388608}))
+	assert.NoError(t, m.ApplyHugetlb(absCgroupPath, &common.HugetlbData{PageSize: "1GB", LimitInBytes: -1}))
+	assert.Error(t, m.ApplyHugetlb(absCgroupPath, &common.HugetlbData{LimitInBytes: 8388608}))
+	assert.Equal(t, "8388608", readCgroupFile(t, absCgroupPath, "hugetlb.2MB.max"))
+	assert.Equal(t, "max\n", readCgroupFile(t, absCgroupPath, "hugetlb.1GB.max"))
+
+	stats, err := m.GetHugetlb(absCgroupPath)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*common.HugetlbStats{
+		"2MB": {Limit: 8388608, Usage: 4194304, Failcnt: 3},
+		"1GB": {Limit: math.MaxUint64},
+	}, stats)
+}
+
+func Test_manager_GetPressure(t *testing.T) {
+	t.Parallel()
+
+	absCgroupPath := writeCgroupFiles(t, map[string]string{
+		"cpu.pressure": "some avg10=1.50 avg60=2.00 avg300=3.00 total=67455876\n",
+		"io.pressure": "some avg10=4.00 avg60=5.00 avg300=6.00 total=1234\n" +
+			"full avg10=7.00 avg60=8.00 avg300=9.00 total=567\n",
+		"memory.pressure": "",
+	})
+
+	m := NewManager()
+	got, err := m.GetCPUPressure(absCgroupPath, common.SOME)
+	assert.NoError(t, err)
+	assert.Equal(t, &common.Pressure{Avg10: 1, Avg60: 2, Avg300: 3, Total: 67455876}, got)
+
+	// full line is missing in cpu.pressure
+	_, err = m.GetCPUPressure(absCgroupPath, common.FULL)
+	assert.Error(t, err)
+
+	got, err = m.GetIOPressure(absCgroupPath, common.SOME)
+	assert.NoError(t, err)
+	assert.Equal(t, &common.Pressure{Avg10: 4, Avg60: 5, Avg300: 6, Total: 1234}, got)
+
+	got, err = m.GetIOPressure(absCgroupPath, common.FULL)
+	assert.NoError(t, err)
+	assert.Equal(t, &common.Pressure{Avg10: 7, Avg60: 8, Avg300: 9, Total: 567}, got)
+
+	_, err = m.GetMemoryPressure(absCgroupPath, common.SOME)
+	assert.Error(t, err)
+}
+
+func Test_parseDeviceIOMax(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		str       string
+		wantDevID string
+		want      *common.IOThrottleData
+		wantErr   bool
+	}{
+		{
+			name:      "valid io max",
+			str:       "8:0 rbps=2097152 wbps=max riops=max wiops=120",
+			wantDevID: "8:0",
+			want:      &common.IOThrottleData{ReadBPS: 2097152, WriteIOPS: 120},
+		},
+		{
+			name:    "no limits",
+			str:     "8:0",
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			str:     "8:0 rbps=-1",
+			wantErr: true,
+		},
+		{
+			name:    "unknown limit",
+			str:     "8:0 rlat=100",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			devID, got, err := parseDeviceIOMax(tt.str)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantDevID, devID)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_manager_GetMemoryEvents(t *testing.T) {
+	t.Parallel()
+
+	absCgroupPath := writeCgroupFiles(t, map[string]string{
+		"memory.events": "low 0\nhigh 12\nmax 5\noom 3\noom_kill 2\noom_group_kill 0\n",
+	})
+
+	m := NewManager()
+	events, err := m.GetMemoryEvents(absCgroupPath)
+	assert.NoError(t, err)
+	assert.Equal(t, &common.MemoryEvents{OOM: 3, OOMKill: 2}, events)
+}
+
+func Test_manager_GetSwap(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    *common.SwapStats
+		wantErr bool
+	}{
+		{
+			name: "swap with zswap",
+			files: map[string]string{
+				"memory.swap.current":  "4096\n",
+				"memory.swap.max":      "max\n",
+				"memory.zswap.current": "1024\n",
+				"memory.zswap.max":     "2048\n",
+			},
+			want: &common.SwapStats{SwapUsage: 4096, SwapLimit: math.MaxUint64, ZswapUsage: 1024, ZswapLimit: 2048},
+		},
+		{
+			name: "zswap not supported",
+			files: map[string]string{
+				"memory.swap.current": "4096\n",
+				"memory.swap.max":     "8192\n",
+			},
+			want: &common.SwapStats{SwapUsage: 4096, SwapLimit: 8192},
+		},
+		{
+			name: "swap not supported",
+			files: map[string]string{
+				"memory.current": "4096\n",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := NewManager()
+			got, err := m.GetSwap(writeCgroupFiles(t, tt.files))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -26,12 +26,14 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/kubernetes/pkg/apis/core/helper"
 
 	apis "github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
 	nodev1alpha1 "github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
 	"github.com/kubewharf/katalyst-api/pkg/consts"
 	pkgconsts "github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
 	"github.com/kubewharf/katalyst-core/pkg/util/native"
 )
 
@@ -39,6 +41,15 @@ const (
 	CNRKind = "CustomNodeResource"
 )
 
+const (
+	// TopologyTypeL3Cache is the zone type of L3 caches (e.g. CCDs of AMD EPYC), and it
+	// is defined here since katalyst-api has not supported it yet.
+	TopologyTypeL3Cache nodev1alpha1.TopologyType = "L3Cache"
+
+	// ZoneAttributeNameCPUs is the attribute name of cpus in a zone
+	ZoneAttributeNameCPUs = "cpus"
+)
+
 // those fields are used by in-tree reporter plugins to
 // refer specific field names of CNR.
 const (
@@ -506,6 +517,61 @@ func GenerateNumaSocketZone(nodes []info.Node) map[ZoneNode]ZoneNode {
 	return numaSocketZoneMap
 }
 
+// GenerateL3CacheZone parse numa info to get the map of L3 cache zone node to its parent zone node,
+// and the parent is numa zone node if the L3 cache is in a single numa, otherwise socket zone node;
+// it also returns cpus of each L3 cache as zone attributes.
+func GenerateL3CacheZone(nodes []info.Node) (map[ZoneNode]ZoneNode, map[ZoneNode]ZoneAttributes) {
+	l3CacheCPUs := make(map[int]machine.CPUSet)
+	l3CacheNUMAs := make(map[int]sets.Int)
+	l3CacheSocket := make(map[int]int)
+	for _, node := range nodes {
+		for _, core := range node.Cores {
+			l3CacheID, ok := machine.GetL3CacheID(node, core)
+			if !ok {
+				continue
+			}
+
+			if _, ok := l3CacheCPUs[l3CacheID]; !ok {
+				l3CacheCPUs[l3CacheID] = machine.NewCPUSet()
+				l3CacheNUMAs[l3CacheID] = sets.NewInt()
+			}
+			l3CacheCPUs[l3CacheID] = l3CacheCPUs[l3CacheID].Union(machine.NewCPUSet(core.Threads...))
+			l3CacheNUMAs[l3CacheID].Insert(node.Id)
+			l3CacheSocket[l3CacheID] = core.SocketID
+		}
+	}
+
+	l3CacheZoneMap := make(map[ZoneNode]ZoneNode, len(l3CacheCPUs))
+	l3CacheZoneAttributes := make(map[ZoneNode]ZoneAttributes, len(l3CacheCPUs))
+	for l3CacheID, cpus := range l3CacheCPUs {
+		l3CacheZoneNode := GenerateL3CacheZoneNode(l3CacheID)
+		if l3CacheNUMAs[l3CacheID].Len() == 1 {
+			l3CacheZoneMap[l3CacheZoneNode] = GenerateNumaZoneNode(l3CacheNUMAs[l3CacheID].List()[0])
+		} else {
+			l3CacheZoneMap[l3CacheZoneNode] = GenerateSocketZoneNode(l3CacheSocket[l3CacheID])
+		}
+
+		l3CacheZoneAttributes[l3CacheZoneNode] = ZoneAttributes{
+			{
+				Name:  ZoneAttributeNameCPUs,
+				Value: cpus.String(),
+			},
+		}
+	}
+
+	return l3CacheZoneMap, l3CacheZoneAttributes
+}
+
+// GenerateL3CacheZoneNode generates L3 cache zone node by L3 cache id, which must be unique
+func GenerateL3CacheZoneNode(l3CacheID int) ZoneNode {
+	return ZoneNode{
+		Meta: ZoneMeta{
+			Type: TopologyTypeL3Cache,
+			Name: strconv.Itoa(l3CacheID),
+		},
+	}
+}
+
 // GenerateNumaZoneNode generates numa zone node by numa id, which must be unique
 func GenerateNumaZoneNode(numaID int) ZoneNode {
 	return ZoneNode{
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"testing"
 
+	info "github.com/google/cadvisor/info/v1"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -1722,3 +1723,80 @@ func TestMergeTopologyZone(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateL3CacheZone(t *testing.T) {
+	t.Parallel()
+
+	l3Cache := func(id int) []info.Cache {
+		return []info.Cache{{Id: id, Level: 3, Type: "Unified"}}
+	}
+
+	tests := []struct {
+		name           string
+		nodes          []info.Node
+		wantZoneMap    map[ZoneNode]ZoneNode
+		wantAttributes map[ZoneNode]ZoneAttributes
+	}{
+		{
+			name: "L3 caches in numa",
+			nodes: []info.Node{
+				{
+					Id: 0,
+					Cores: []info.Core{
+						{Id: 0, SocketID: 0, Threads: []int{0, 4}, UncoreCaches: l3Cache(0)},
+						{Id: 1, SocketID: 0, Threads: []int{1, 5}, UncoreCaches: l3Cache(1)},
+					},
+				},
+				{
+					Id: 1,
+					Cores: []info.Core{
+						{Id: 2, SocketID: 0, Threads: []int{2, 6}, UncoreCaches: l3Cache(2)},
+						{Id: 3, SocketID: 0, Threads: []int{3, 7}},
+					},
+				},
+			},
+			wantZoneMap: map[ZoneNode]ZoneNode{
+				GenerateL3CacheZoneNode(0): GenerateNumaZoneNode(0),
+				GenerateL3CacheZoneNode(1): GenerateNumaZoneNode(0),
+				GenerateL3CacheZoneNode(2): GenerateNumaZoneNode(1),
+			},
+			wantAttributes: map[ZoneNode]ZoneAttributes{
+				GenerateL3CacheZoneNode(0): {{Name: ZoneAttributeNameCPUs, Value: "0,4"}},
+				GenerateL3CacheZoneNode(1): {{Name: ZoneAttributeNameCPUs, Value: "1,5"}},
+				GenerateL3CacheZoneNode(2): {{Name: ZoneAttributeNameCPUs, Value: "2,6"}},
+			},
+		},
+		{
+			name: "L3 cache across numa",
+			nodes: []info.Node{
+				{
+					Id:     0,
+					Cores:  []info.Core{{Id: 0, SocketID: 1, Threads: []int{0, 2}}},
+					Caches: l3Cache(1),
+				},
+				{
+					Id:     1,
+					Cores:  []info.Core{{Id: 1, SocketID: 1, Threads: []int{1, 3}}},
+					Caches: l3Cache(1),
+				},
+			},
+			wantZoneMap: map[ZoneNode]ZoneNode{
+				GenerateL3CacheZoneNode(1): GenerateSocketZoneNode(1),
+			},
+			wantAttributes: map[ZoneNode]ZoneAttributes{
+				GenerateL3CacheZoneNode(1): {{Name: ZoneAttributeNameCPUs, Value: "0-3"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			zoneMap, attributes := GenerateL3CacheZone(tt.nodes)
+			assert.Equal(t, tt.wantZoneMap, zoneMap)
+			assert.Equal(t, tt.wantAttributes, attributes)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credential
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+)
+
+const (
+	// CertificateUsernameSourceCN uses common name of the client certificate as the user
+	CertificateUsernameSourceCN = "CN"
+	// CertificateUsernameSourceSAN uses the first subject alternative name of the client
+	// certificate as the user, and the order is URI, DNS name and email address
+	CertificateUsernameSourceSAN = "SAN"
+)
+
+type CertificateAuthInfo struct {
+	Username       string
+	CommonName     string
+	Organizations  []string
+	DNSNames       []string
+	EmailAddresses []string
+	URIs           []string
+}
+
+func (c CertificateAuthInfo) AuthType() AuthType {
+	return AuthTypeCertificate
+}
+
+func (c CertificateAuthInfo) SubjectName() string {
+	return c.Username
+}
+
+// NewCertificateCredential returns the credential which authenticates requests by the
+// client certificates of mutual TLS, so generic endpoint must be served with TLS.
+func NewCertificateCredential(authConfig *generic.AuthConfiguration, _ *dynamic.DynamicAgentConfiguration) (Credential, error) {
+	usernameSource := authConfig.CertificateUsernameSource
+	switch usernameSource {
+	case "":
+		usernameSource = CertificateUsernameSourceCN
+	case CertificateUsernameSourceCN, CertificateUsernameSourceSAN:
+	default:
+		return nil, fmt.Errorf("unsupported certificate username source: %v", usernameSource)
+	}
+
+	roots, err := LoadCertPool(authConfig.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &certificateCredential{
+		roots:          roots,
+		usernameSource: usernameSource,
+	}, nil
+}
+
+// LoadCertPool loads the PEM encoded certificates in file into a cert pool
+func LoadCertPool(file string) (*x509.CertPool, error) {
+	if file == "" {
+		return nil, fmt.Errorf("client ca file is not configured")
+	}
+
+	pem, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read client ca file %v failed: %v", file, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no valid certificate found in client ca file %v", file)
+	}
+	return pool, nil
+}
+
+type certificateCredential struct {
+	roots          *x509.CertPool
+	usernameSource string
+}
+
+func (c *certificateCredential) Run(_ context.Context) {
+}
+
+func (c *certificateCredential) AuthType() AuthType {
+	return AuthTypeCertificate
+}
+
+func (c *certificateCredential) Auth(r *http.Request) (AuthInfo, error) {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return nil, fmt.Errorf("no client certificate is provided")
+	}
+
+	// verify the chain again, in case that server doesn't verify client certificates
+	intermediates := x509.NewCertPool()
+	for _, cert := range r.TLS.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	cert := r.TLS.PeerCertificates[0]
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:         c.roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}); err != nil {
+		return nil, fmt.Errorf("verify client certificate failed: %v", err)
+	}
+
+	return c.getAuthInfo(cert)
+}
+
+// AuthToken is not supported, since a certificate without its private key proves nothing
+func (c *certificateCredential) AuthToken(_ string) (AuthInfo, error) {
+	return nil, fmt.Errorf("token is not supported by certificate credential")
+}
+
+func (c *certificateCredential) getAuthInfo(cert *x509.Certificate) (AuthInfo, error) {
+	authInfo := CertificateAuthInfo{
+		CommonName:     cert.Subject.CommonName,
+		Organizations:  cert.Subject.Organization,
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+	}
+	for _, uri := range cert.URIs {
+		authInfo.URIs = append(authInfo.URIs, uri.String())
+	}
+
+	switch c.usernameSource {
+	case CertificateUsernameSourceSAN:
+		for _, sans := range [][]string{authInfo.URIs, authInfo.DNSNames, authInfo.EmailAddresses} {
+			if len(sans) > 0 {
+				authInfo.Username = sans[0]
+				break
+			}
+		}
+	default:
+		authInfo.Username = authInfo.CommonName
+	}
+
+	if authInfo.Username == "" {
+		return nil, fmt.Errorf("no %v found in client certificate", c.usernameSource)
+	}
+	return authInfo, nil
+}
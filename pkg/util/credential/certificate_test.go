@@ -0,0 +1,235 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credential
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) writeFile(t *testing.T) string {
+	file := filepath.Join(t.TempDir(), "ca.crt")
+	require.NoError(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}), 0o644))
+	return file
+}
+
+func (ca *testCA) issue(t *testing.T, template *x509.Certificate) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template.SerialNumber = big.NewInt(2)
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	if template.ExtKeyUsage == nil {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+func Test_certificateCredential_Auth(t *testing.T) {
+	t.Parallel()
+
+	ca := newTestCA(t)
+	caFile := ca.writeFile(t)
+	spiffeID, err := url.Parse("spiffe://katalyst/ns/default/sa/test")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		usernameSource string
+		cert           *x509.Certificate
+		wantUsername   string
+		wantErr        bool
+	}{
+		{
+			name: "common name as user",
+			cert: ca.issue(t, &x509.Certificate{
+				Subject: pkix.Name{CommonName: "operator", Organization: []string{"admins"}},
+			}),
+			wantUsername: "operator",
+		},
+		{
+			name:           "uri san as user",
+			usernameSource: CertificateUsernameSourceSAN,
+			cert: ca.issue(t, &x509.Certificate{
+				Subject:  pkix.Name{CommonName: "operator"},
+				DNSNames: []string{"plugin.katalyst"},
+				URIs:     []*url.URL{spiffeID},
+			}),
+			wantUsername: spiffeID.String(),
+		},
+		{
+			name:           "dns san as user",
+			usernameSource: CertificateUsernameSourceSAN,
+			cert: ca.issue(t, &x509.Certificate{
+				Subject:  pkix.Name{CommonName: "operator"},
+				DNSNames: []string{"plugin.katalyst"},
+			}),
+			wantUsername: "plugin.katalyst",
+		},
+		{
+			name:           "no san",
+			usernameSource: CertificateUsernameSourceSAN,
+			cert:           ca.issue(t, &x509.Certificate{Subject: pkix.Name{CommonName: "operator"}}),
+			wantErr:        true,
+		},
+		{
+			name: "certificate not for client auth",
+			cert: ca.issue(t, &x509.Certificate{
+				Subject:     pkix.Name{CommonName: "operator"},
+				ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			}),
+			wantErr: true,
+		},
+		{
+			name:    "certificate signed by unknown ca",
+			cert:    newTestCA(t).issue(t, &x509.Certificate{Subject: pkix.Name{CommonName: "operator"}}),
+			wantErr: true,
+		},
+		{
+			name:    "no certificate",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generic.NewAuthConfiguration()
+			conf.ClientCAFile = caFile
+			conf.CertificateUsernameSource = tt.usernameSource
+			cred, err := NewCertificateCredential(conf, nil)
+			require.NoError(t, err)
+
+			r := &http.Request{TLS: &tls.ConnectionState{}}
+			if tt.cert != nil {
+				r.TLS.PeerCertificates = []*x509.Certificate{tt.cert}
+			}
+
+			got, err := cred.Auth(r)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantUsername, got.SubjectName())
+			assert.Equal(t, AuthType(AuthTypeCertificate), got.AuthType())
+		})
+	}
+}
+
+func Test_NewCertificateCredential(t *testing.T) {
+	t.Parallel()
+
+	invalidCAFile := filepath.Join(t.TempDir(), "invalid.crt")
+	require.NoError(t, os.WriteFile(invalidCAFile, []byte("invalid"), 0o644))
+
+	tests := []struct {
+		name           string
+		caFile         string
+		usernameSource string
+		wantErr        bool
+	}{
+		{
+			name:    "valid ca file",
+			caFile:  newTestCA(t).writeFile(t),
+			wantErr: false,
+		},
+		{
+			name:    "no ca file",
+			wantErr: true,
+		},
+		{
+			name:    "invalid ca file",
+			caFile:  invalidCAFile,
+			wantErr: true,
+		},
+		{
+			name:           "unsupported username source",
+			caFile:         newTestCA(t).writeFile(t),
+			usernameSource: "OU",
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generic.NewAuthConfiguration()
+			conf.ClientCAFile = tt.caFile
+			conf.CertificateUsernameSource = tt.usernameSource
+			cred, err := NewCertificateCredential(conf, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			_, err = cred.AuthToken("any")
+			assert.Error(t, err)
+		})
+	}
+}
@@ -28,8 +28,10 @@ import (
 type AuthType string
 
 const (
-	AuthTypeBasicAuth = "Basic"
-	AuthTypeInsecure  = "Insecure"
+	AuthTypeBasicAuth   = "Basic"
+	AuthTypeInsecure    = "Insecure"
+	AuthTypeTokenReview = "TokenReview"
+	AuthTypeCertificate = "Certificate"
 )
 
 // AuthInfo defines the common interface for the auth information the users are interested in.
@@ -69,6 +71,7 @@ func GetCredentialInitializer() map[AuthType]NewCredentialFunc {
 func init() {
 	RegisterCredentialInitializer(AuthTypeBasicAuth, NewBasicAuthCredential)
 	RegisterCredentialInitializer(AuthTypeInsecure, NewInsecureCredential)
+	RegisterCredentialInitializer(AuthTypeCertificate, NewCertificateCredential)
 }
 
 func GetCredential(genericConf *generic.GenericConfiguration, dynamicConfig *dynamic.DynamicAgentConfiguration) (Credential, error) {
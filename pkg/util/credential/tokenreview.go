@@ -0,0 +1,232 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credential
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+)
+
+const (
+	tokenReviewTimeout      = 10 * time.Second
+	tokenCachePurgeInterval = time.Minute
+	bearerTokenPrefix       = "Bearer "
+)
+
+type TokenReviewAuthInfo struct {
+	Username string
+	UID      string
+	Groups   []string
+	Extra    map[string][]string
+
+	// ServiceAccountNamespace and ServiceAccountName are only set
+	// if the token belongs to a service account
+	ServiceAccountNamespace string
+	ServiceAccountName      string
+}
+
+func (t TokenReviewAuthInfo) AuthType() AuthType {
+	return AuthTypeTokenReview
+}
+
+func (t TokenReviewAuthInfo) SubjectName() string {
+	return t.Username
+}
+
+// NewTokenReviewCredentialInitializer returns the initializer of credential which validates bearer
+// tokens through TokenReview API, since kube client can't be obtained from configurations.
+func NewTokenReviewCredentialInitializer(kubeClient kubernetes.Interface) NewCredentialFunc {
+	return func(authConfig *generic.AuthConfiguration, _ *dynamic.DynamicAgentConfiguration) (Credential, error) {
+		if kubeClient == nil {
+			return nil, fmt.Errorf("nil kube client for token review credential")
+		}
+
+		return &tokenReviewCredential{
+			kubeClient: kubeClient,
+			audiences:  authConfig.TokenReviewAudiences,
+			cacheTTL:   authConfig.TokenReviewCacheTTL,
+			cache:      make(map[string]tokenCacheEntry),
+		}, nil
+	}
+}
+
+type tokenCacheEntry struct {
+	authInfo TokenReviewAuthInfo
+	expireAt time.Time
+}
+
+type tokenReviewCredential struct {
+	kubeClient kubernetes.Interface
+	audiences  []string
+	cacheTTL   time.Duration
+
+	// cache records authenticated tokens (keyed by their hash) to
+	// avoid creating TokenReview for each request
+	mutex sync.RWMutex
+	cache map[string]tokenCacheEntry
+}
+
+func (t *tokenReviewCredential) Run(ctx context.Context) {
+	go wait.Until(t.purgeExpiredCache, tokenCachePurgeInterval, ctx.Done())
+}
+
+func (t *tokenReviewCredential) AuthType() AuthType {
+	return AuthTypeTokenReview
+}
+
+func (t *tokenReviewCredential) Auth(r *http.Request) (AuthInfo, error) {
+	return t.AuthToken(r.Header.Get("Authorization"))
+}
+
+func (t *tokenReviewCredential) AuthToken(token string) (AuthInfo, error) {
+	bearerToken, ok := parseBearerToken(token)
+	if !ok {
+		return nil, fmt.Errorf("invalid bearer token")
+	}
+
+	key := hashToken(bearerToken)
+	if authInfo, ok := t.getCache(key); ok {
+		return authInfo, nil
+	}
+
+	authInfo, err := t.review(bearerToken)
+	if err != nil {
+		return nil, err
+	}
+
+	t.setCache(key, authInfo)
+	return authInfo, nil
+}
+
+func (t *tokenReviewCredential) review(token string) (TokenReviewAuthInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), tokenReviewTimeout)
+	defer cancel()
+
+	review, err := t.kubeClient.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token:     token,
+			Audiences: t.audiences,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return TokenReviewAuthInfo{}, fmt.Errorf("create token review failed: %v", err)
+	}
+
+	if !review.Status.Authenticated {
+		return TokenReviewAuthInfo{}, fmt.Errorf("token is not authenticated: %v", review.Status.Error)
+	}
+	if len(t.audiences) > 0 && !audiencesIntersect(t.audiences, review.Status.Audiences) {
+		return TokenReviewAuthInfo{}, fmt.Errorf("token audiences %v mismatch with %v", review.Status.Audiences, t.audiences)
+	}
+
+	authInfo := TokenReviewAuthInfo{
+		Username: review.Status.User.Username,
+		UID:      review.Status.User.UID,
+		Groups:   review.Status.User.Groups,
+	}
+	if len(review.Status.User.Extra) > 0 {
+		authInfo.Extra = make(map[string][]string, len(review.Status.User.Extra))
+		for key, value := range review.Status.User.Extra {
+			authInfo.Extra[key] = value
+		}
+	}
+	if namespace, name, err := serviceaccount.SplitUsername(authInfo.Username); err == nil {
+		authInfo.ServiceAccountNamespace = namespace
+		authInfo.ServiceAccountName = name
+	}
+	return authInfo, nil
+}
+
+func (t *tokenReviewCredential) getCache(key string) (TokenReviewAuthInfo, bool) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	entry, ok := t.cache[key]
+	if !ok || time.Now().After(entry.expireAt) {
+		return TokenReviewAuthInfo{}, false
+	}
+	return entry.authInfo, true
+}
+
+func (t *tokenReviewCredential) setCache(key string, authInfo TokenReviewAuthInfo) {
+	if t.cacheTTL <= 0 {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.cache[key] = tokenCacheEntry{
+		authInfo: authInfo,
+		expireAt: time.Now().Add(t.cacheTTL),
+	}
+}
+
+func (t *tokenReviewCredential) purgeExpiredCache() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	for key, entry := range t.cache {
+		if now.After(entry.expireAt) {
+			delete(t.cache, key)
+		}
+	}
+}
+
+// parseBearerToken parses an HTTP Bearer Authentication string.
+// "Bearer abc" returns ("abc", true).
+func parseBearerToken(auth string) (string, bool) {
+	// Case-insensitive prefix match.
+	if len(auth) <= len(bearerTokenPrefix) || !strings.EqualFold(auth[:len(bearerTokenPrefix)], bearerTokenPrefix) {
+		return "", false
+	}
+
+	token := strings.TrimSpace(auth[len(bearerTokenPrefix):])
+	return token, token != ""
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func audiencesIntersect(expected, actual []string) bool {
+	for _, e := range expected {
+		for _, a := range actual {
+			if e == a {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credential
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+)
+
+const (
+	testValidToken = "valid-token"
+	testUserToken  = "user-token"
+)
+
+// newFakeTokenReviewClient returns a fake client which authenticates testValidToken as service
+// account default/test with audience "katalyst", and testUserToken as user "admin"
+func newFakeTokenReviewClient(reviewCount *int) *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		*reviewCount++
+
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		switch review.Spec.Token {
+		case testValidToken:
+			review.Status = authenticationv1.TokenReviewStatus{
+				Authenticated: true,
+				User: authenticationv1.UserInfo{
+					Username: "system:serviceaccount:default:test",
+					UID:      "uid-1",
+					Groups:   []string{"system:serviceaccounts"},
+				},
+				Audiences: []string{"katalyst"},
+			}
+		case testUserToken:
+			review.Status = authenticationv1.TokenReviewStatus{
+				Authenticated: true,
+				User:          authenticationv1.UserInfo{Username: "admin"},
+			}
+		default:
+			review.Status = authenticationv1.TokenReviewStatus{Error: "invalid token"}
+		}
+		return true, review, nil
+	})
+	return client
+}
+
+func Test_tokenReviewCredential_Auth(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		header    string
+		audiences []string
+		want      AuthInfo
+		wantErr   bool
+	}{
+		{
+			name:   "service account token",
+			header: "Bearer " + testValidToken,
+			want: TokenReviewAuthInfo{
+				Username:                "system:serviceaccount:default:test",
+				UID:                     "uid-1",
+				Groups:                  []string{"system:serviceaccounts"},
+				ServiceAccountNamespace: "default",
+				ServiceAccountName:      "test",
+			},
+		},
+		{
+			name:      "user token with mismatched audiences",
+			header:    "Bearer " + testUserToken,
+			audiences: []string{"katalyst"},
+			wantErr:   true,
+		},
+		{
+			name:    "unauthenticated token",
+			header:  "Bearer unknown",
+			wantErr: true,
+		},
+		{
+			name:    "basic auth header",
+			header:  makeBasicToken("user-1", "123456"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reviewCount := 0
+			conf := generic.NewAuthConfiguration()
+			conf.TokenReviewAudiences = tt.audiences
+			cred, err := NewTokenReviewCredentialInitializer(newFakeTokenReviewClient(&reviewCount))(conf, nil)
+			assert.NoError(t, err)
+
+			r := &http.Request{Header: http.Header{}}
+			r.Header.Set("Authorization", tt.header)
+			got, err := cred.Auth(r)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, AuthType(AuthTypeTokenReview), got.AuthType())
+		})
+	}
+}
+
+func Test_tokenReviewCredential_Cache(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		cacheTTL        time.Duration
+		token           string
+		wantReviewCount int
+	}{
+		{
+			name:            "authenticated token is cached",
+			cacheTTL:        time.Minute,
+			token:           testValidToken,
+			wantReviewCount: 1,
+		},
+		{
+			name:            "cache is disabled",
+			token:           testValidToken,
+			wantReviewCount: 2,
+		},
+		{
+			name:            "unauthenticated token is not cached",
+			cacheTTL:        time.Minute,
+			token:           "unknown",
+			wantReviewCount: 2,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reviewCount := 0
+			conf := generic.NewAuthConfiguration()
+			conf.TokenReviewCacheTTL = tt.cacheTTL
+			cred, err := NewTokenReviewCredentialInitializer(newFakeTokenReviewClient(&reviewCount))(conf, nil)
+			assert.NoError(t, err)
+
+			for i := 0; i < 2; i++ {
+				_, _ = cred.AuthToken("Bearer " + tt.token)
+			}
+			assert.Equal(t, tt.wantReviewCount, reviewCount)
+
+			tokenCred := cred.(*tokenReviewCredential)
+			for key, entry := range tokenCred.cache {
+				entry.expireAt = time.Now().Add(-time.Second)
+				tokenCred.cache[key] = entry
+			}
+			tokenCred.purgeExpiredCache()
+			assert.Empty(t, tokenCred.cache)
+		})
+	}
+}
+
+func Test_NewTokenReviewCredentialInitializer(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewTokenReviewCredentialInitializer(nil)(generic.NewAuthConfiguration(), nil)
+	assert.Error(t, err)
+}
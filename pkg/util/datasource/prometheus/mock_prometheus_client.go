@@ -26,6 +26,7 @@ import (
 
 // MockPromAPIClient is a mock implementation of v1.API for testing purposes.
 type MockPromAPIClient struct {
+	QueryFunc      func(ctx context.Context, query string, ts time.Time) (model.Value, v1.Warnings, error)
 	QueryRangeFunc func(ctx context.Context, query string, r v1.Range) (model.Value, v1.Warnings, error)
 }
 
@@ -62,7 +63,10 @@ func (m *MockPromAPIClient) LabelValues(ctx context.Context, label string, match
 }
 
 func (m *MockPromAPIClient) Query(ctx context.Context, query string, ts time.Time, opts ...v1.Option) (model.Value, v1.Warnings, error) {
-	return nil, nil, nil
+	if m.QueryFunc == nil {
+		return nil, nil, nil
+	}
+	return m.QueryFunc(ctx, query, ts)
 }
 
 func (m *MockPromAPIClient) QueryExemplars(ctx context.Context, query string, startTime time.Time, endTime time.Time) ([]v1.ExemplarQueryResult, error) {
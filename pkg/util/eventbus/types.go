@@ -41,6 +41,31 @@ type RawCGroupEvent struct {
 	OldData    string
 }
 
+// CGroupDriftEvent is published once a cgroup file is found drifted from the desired data
+type CGroupDriftEvent struct {
+	BaseEventImpl
+	CGroupPath string
+	CGroupFile string
+	Desired    string
+	Actual     string
+	// Reapplied is false if the desired data failed to be applied again, and Err is the reason
+	Reapplied bool
+	Err       string
+}
+
+// ContainerOOMEvent is published once a container is found to hit its memory limit,
+// and OOM/OOMKill are the increments of memory events since the last observation
+type ContainerOOMEvent struct {
+	BaseEventImpl
+	PodUID        string
+	PodNamespace  string
+	PodName       string
+	ContainerName string
+	ContainerID   string
+	OOM           uint64
+	OOMKill       uint64
+}
+
 type SyscallEvent struct {
 	BaseEventImpl
 	Cost        time.Duration
@@ -42,6 +42,12 @@ type HealthzCheckResult struct {
 	Message string `json:"message"`
 }
 
+// ComponentHealth is the health of one agent component aggregated from its healthz checks
+type ComponentHealth struct {
+	Ready   bool   `json:"ready"`
+	Message string `json:"message,omitempty"`
+}
+
 type healthzCheckStatus struct {
 	State          HealthzCheckState `json:"state"`
 	Message        string            `json:"message"`
@@ -0,0 +1,197 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/robfig/cron/v3"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apis "github.com/kubewharf/katalyst-api/pkg/apis/autoscaling/v1alpha2"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+)
+
+// ScheduledMinReplicas keeps replicas of the ihpa no less than MinReplicas in a window,
+// which starts each time CronTab is scheduled and lasts for Duration, e.g. a window with
+// CronTab "0 9 * * 1-5" and Duration "2h" works in 09:00-11:00 on weekdays.
+type ScheduledMinReplicas struct {
+	CronTab     string          `json:"cronTab"`
+	Duration    metav1.Duration `json:"duration"`
+	MinReplicas int32           `json:"minReplicas"`
+}
+
+// ScaleUpOverride keeps replicas of the ihpa no less than MinReplicas until ExpireTime,
+// it's set by users or external event sources, or by the ihpa controller when a ScaleUpTrigger fires.
+type ScaleUpOverride struct {
+	MinReplicas int32       `json:"minReplicas"`
+	ExpireTime  metav1.Time `json:"expireTime"`
+	Reason      string      `json:"reason,omitempty"`
+}
+
+// ScaleUpTrigger fires a ScaleUpOverride lasting for Duration when the current value of
+// the resource portrait metric Metric reaches Threshold.
+type ScaleUpTrigger struct {
+	Metric      core.ResourceName `json:"metric"`
+	Threshold   resource.Quantity `json:"threshold"`
+	MinReplicas int32             `json:"minReplicas"`
+	Duration    metav1.Duration   `json:"duration"`
+}
+
+// IHPAScalingRulesStatus shows how scaling rules are merged with the predicted replicas,
+// since there are no such fields in IntelligentHorizontalPodAutoscalerStatus.
+type IHPAScalingRulesStatus struct {
+	ScheduledMinReplicas int32        `json:"scheduledMinReplicas,omitempty"`
+	OverrideMinReplicas  int32        `json:"overrideMinReplicas,omitempty"`
+	OverrideExpireTime   *metav1.Time `json:"overrideExpireTime,omitempty"`
+	OverrideReason       string       `json:"overrideReason,omitempty"`
+	PredictedReplicas    int32        `json:"predictedReplicas,omitempty"`
+	DesiredReplicas      int32        `json:"desiredReplicas"`
+}
+
+// CheckIHPAScalingRulesEnabled returns whether any scaling rule is set for the ihpa
+func CheckIHPAScalingRulesEnabled(ihpa *apis.IntelligentHorizontalPodAutoscaler) bool {
+	if ihpa == nil {
+		return false
+	}
+
+	for _, key := range []string{
+		consts.IHPAAnnotationKeyScheduledMinReplicas,
+		consts.IHPAAnnotationKeyScaleUpOverride,
+		consts.IHPAAnnotationKeyScaleUpTriggers,
+	} {
+		if _, ok := ihpa.Annotations[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// GetIHPAScheduledMinReplicas parses scheduled min replicas windows from ihpa annotation
+func GetIHPAScheduledMinReplicas(ihpa *apis.IntelligentHorizontalPodAutoscaler) ([]ScheduledMinReplicas, error) {
+	var scheduled []ScheduledMinReplicas
+	if _, err := getIHPAAnnotation(ihpa, consts.IHPAAnnotationKeyScheduledMinReplicas, &scheduled); err != nil {
+		return nil, err
+	}
+	return scheduled, nil
+}
+
+// GetIHPAScaleUpOverride parses the scale-up override from ihpa annotation, it returns nil if it's not set
+func GetIHPAScaleUpOverride(ihpa *apis.IntelligentHorizontalPodAutoscaler) (*ScaleUpOverride, error) {
+	override := &ScaleUpOverride{}
+	if ok, err := getIHPAAnnotation(ihpa, consts.IHPAAnnotationKeyScaleUpOverride, override); err != nil || !ok {
+		return nil, err
+	}
+	return override, nil
+}
+
+// SetIHPAScaleUpOverride sets the scale-up override to ihpa annotation
+func SetIHPAScaleUpOverride(ihpa *apis.IntelligentHorizontalPodAutoscaler, override *ScaleUpOverride) error {
+	return setIHPAAnnotation(ihpa, consts.IHPAAnnotationKeyScaleUpOverride, override)
+}
+
+// GetIHPAScaleUpTriggers parses scale-up triggers from ihpa annotation
+func GetIHPAScaleUpTriggers(ihpa *apis.IntelligentHorizontalPodAutoscaler) ([]ScaleUpTrigger, error) {
+	var triggers []ScaleUpTrigger
+	if _, err := getIHPAAnnotation(ihpa, consts.IHPAAnnotationKeyScaleUpTriggers, &triggers); err != nil {
+		return nil, err
+	}
+	return triggers, nil
+}
+
+// SetIHPAScalingRulesStatus sets the status of scaling rules to ihpa annotation
+func SetIHPAScalingRulesStatus(ihpa *apis.IntelligentHorizontalPodAutoscaler, status *IHPAScalingRulesStatus) error {
+	return setIHPAAnnotation(ihpa, consts.IHPAAnnotationKeyScalingRulesStatus, status)
+}
+
+// ValidateIHPAScalingRules checks if scaling rules in ihpa annotations are well-formed
+func ValidateIHPAScalingRules(ihpa *apis.IntelligentHorizontalPodAutoscaler) error {
+	scheduled, err := GetIHPAScheduledMinReplicas(ihpa)
+	if err != nil {
+		return err
+	}
+	for i, s := range scheduled {
+		if _, err := cron.ParseStandard(s.CronTab); err != nil {
+			return fmt.Errorf("scheduled min replicas[%d]: invalid crontab %q: %v", i, s.CronTab, err)
+		} else if s.Duration.Duration <= 0 {
+			return fmt.Errorf("scheduled min replicas[%d]: duration must be positive", i)
+		} else if s.MinReplicas < 1 {
+			return fmt.Errorf("scheduled min replicas[%d]: minReplicas %d must be greater than 0", i, s.MinReplicas)
+		}
+	}
+
+	override, err := GetIHPAScaleUpOverride(ihpa)
+	if err != nil {
+		return err
+	} else if override != nil && override.MinReplicas < 1 {
+		return fmt.Errorf("scale-up override: minReplicas %d must be greater than 0", override.MinReplicas)
+	}
+
+	triggers, err := GetIHPAScaleUpTriggers(ihpa)
+	if err != nil {
+		return err
+	}
+	for i, trigger := range triggers {
+		if trigger.Metric == "" {
+			return fmt.Errorf("scale-up triggers[%d]: metric must be set", i)
+		} else if trigger.Threshold.Sign() <= 0 {
+			return fmt.Errorf("scale-up triggers[%d]: threshold must be positive", i)
+		} else if trigger.Duration.Duration <= 0 {
+			return fmt.Errorf("scale-up triggers[%d]: duration must be positive", i)
+		} else if trigger.MinReplicas < 1 {
+			return fmt.Errorf("scale-up triggers[%d]: minReplicas %d must be greater than 0", i, trigger.MinReplicas)
+		}
+	}
+	return nil
+}
+
+// getIHPAAnnotation unmarshals the json value of ihpa annotation into v, it returns false if it's not set
+func getIHPAAnnotation(ihpa *apis.IntelligentHorizontalPodAutoscaler, key string, v interface{}) (bool, error) {
+	if ihpa == nil {
+		return false, nil
+	}
+
+	value, ok := ihpa.Annotations[key]
+	if !ok {
+		return false, nil
+	}
+
+	if err := json.Unmarshal([]byte(value), v); err != nil {
+		return false, fmt.Errorf("invalid annotation %s: %v", key, err)
+	}
+	return true, nil
+}
+
+func setIHPAAnnotation(ihpa *apis.IntelligentHorizontalPodAutoscaler, key string, v interface{}) error {
+	if ihpa == nil {
+		return fmt.Errorf("ihpa is nil")
+	}
+
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if ihpa.Annotations == nil {
+		ihpa.Annotations = map[string]string{}
+	}
+	ihpa.Annotations[key] = string(value)
+	return nil
+}
@@ -21,11 +21,13 @@ import (
 	"fmt"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
 
 	"github.com/kubewharf/katalyst-api/pkg/apis/config/v1alpha1"
 	"github.com/kubewharf/katalyst-core/pkg/consts"
@@ -272,3 +274,176 @@ func (g KCCTargetResourceGeneral) NeedValidateKCC() bool {
 func (g KCCTargetResourceGeneral) IsPerNode() bool {
 	return false
 }
+
+// KCCTargetConfigRevision refers to the hashed contents of a kcc target, i.e. spec.config and the
+// non-generic status, which are stored in a ControllerRevision so that the config of an earlier hash
+// referenced by CNCs can be restored; only the references are kept in kcc target annotations.
+type KCCTargetConfigRevision struct {
+	Hash      string      `json:"hash"`
+	Timestamp metav1.Time `json:"timestamp"`
+}
+
+// kccTargetConfigRevisionData is the data of the ControllerRevision for a kcc target config revision
+type kccTargetConfigRevisionData struct {
+	Config json.RawMessage `json:"config,omitempty"`
+	Status json.RawMessage `json:"status,omitempty"`
+}
+
+// GetKCCTargetControllerRevisionKey returns the namespace and name of the ControllerRevision which stores
+// the contents of the given hash of the kcc target; revisions of cluster-scoped targets are stored in
+// the namespace KCCTargetClusterScopedRevisionNamespace.
+func GetKCCTargetControllerRevisionKey(gvr metav1.GroupVersionResource, namespace, name, hash string) (string, string) {
+	if namespace == "" {
+		namespace = consts.KCCTargetClusterScopedRevisionNamespace
+	}
+	return namespace, fmt.Sprintf("%s-%s-%s", gvr.Resource, name, hash)
+}
+
+// NewKCCTargetControllerRevision snapshots the current contents of the kcc target as the ControllerRevision
+// of the given hash, which is owned by the target to be garbage collected along with it.
+func NewKCCTargetControllerRevision(gvr metav1.GroupVersionResource, target KCCTargetResource, hash string) (*appsv1.ControllerRevision, error) {
+	data := kccTargetConfigRevisionData{}
+
+	obj := target.GetUnstructured().Object
+	config, ok, err := unstructured.NestedFieldNoCopy(obj, consts.ObjectFieldNameSpec, consts.KCCTargetConfFieldNameConfig)
+	if err != nil {
+		return nil, err
+	} else if ok {
+		data.Config, err = json.Marshal(config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	status, ok, err := unstructured.NestedMap(obj, consts.ObjectFieldNameStatus)
+	if err != nil {
+		return nil, err
+	} else if ok {
+		genericStatus := target.GetGenericStatus()
+		genericStatusObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&genericStatus)
+		if err != nil {
+			return nil, err
+		}
+		for key := range genericStatusObj {
+			delete(status, key)
+		}
+
+		if len(status) > 0 {
+			data.Status, err = json.Marshal(status)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace, name := GetKCCTargetControllerRevisionKey(gvr, target.GetNamespace(), target.GetName(), hash)
+	revision := &appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Data: runtime.RawExtension{Raw: raw},
+	}
+
+	// owner references across namespaces are invalid, so a namespaced target only owns its revisions in the same namespace
+	unstructuredTarget := target.GetUnstructured()
+	if target.GetUID() != "" && unstructuredTarget.GetKind() != "" &&
+		(target.GetNamespace() == "" || target.GetNamespace() == namespace) {
+		revision.OwnerReferences = []metav1.OwnerReference{
+			{
+				APIVersion: unstructuredTarget.GetAPIVersion(),
+				Kind:       unstructuredTarget.GetKind(),
+				Name:       target.GetName(),
+				UID:        target.GetUID(),
+			},
+		}
+	}
+	return revision, nil
+}
+
+// GetKCCTargetConfigRevisions returns the config revisions recorded in kcc target annotations, from old to new.
+func GetKCCTargetConfigRevisions(target KCCTargetResource) ([]KCCTargetConfigRevision, error) {
+	value, ok := target.GetAnnotations()[consts.KCCTargetAnnotationKeyConfigRevisions]
+	if !ok {
+		return nil, nil
+	}
+
+	var revisions []KCCTargetConfigRevision
+	if err := json.Unmarshal([]byte(value), &revisions); err != nil {
+		return nil, fmt.Errorf("unmarshal config revisions failed: %w", err)
+	}
+	return revisions, nil
+}
+
+// SetKCCTargetConfigRevisions records the config revisions in kcc target annotations.
+func SetKCCTargetConfigRevisions(target KCCTargetResource, revisions []KCCTargetConfigRevision) error {
+	value, err := json.Marshal(revisions)
+	if err != nil {
+		return err
+	}
+
+	annotations := target.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[consts.KCCTargetAnnotationKeyConfigRevisions] = string(value)
+	target.SetAnnotations(annotations)
+	return nil
+}
+
+// CheckKCCTargetConfigHash returns true if the current contents of the kcc target match the given hash.
+func CheckKCCTargetConfigHash(target KCCTargetResource, hash string) bool {
+	// generating hash may modify the status of the target, so it's always done on a copy
+	currentHash, err := target.DeepCopy().GenerateConfigHash()
+	return err == nil && currentHash == hash
+}
+
+// RestoreKCCTargetConfigRevision returns the kcc target with the contents of the given hash restored
+// from its ControllerRevision.
+func RestoreKCCTargetConfigRevision(target KCCTargetResource, hash string, revision *appsv1.ControllerRevision) (KCCTargetResource, error) {
+	data := kccTargetConfigRevisionData{}
+	if err := json.Unmarshal(revision.Data.Raw, &data); err != nil {
+		return nil, fmt.Errorf("unmarshal data of revision %s failed: %w", hash, err)
+	}
+
+	restored := target.DeepCopy()
+	obj := restored.GetUnstructured().Object
+	unstructured.RemoveNestedField(obj, consts.ObjectFieldNameSpec, consts.KCCTargetConfFieldNameConfig)
+	if len(data.Config) > 0 {
+		var config interface{}
+		if err := utiljson.Unmarshal(data.Config, &config); err != nil {
+			return nil, fmt.Errorf("unmarshal config of revision %s failed: %w", hash, err)
+		}
+		if err := unstructured.SetNestedField(obj, config, consts.ObjectFieldNameSpec, consts.KCCTargetConfFieldNameConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	genericStatus := restored.GetGenericStatus()
+	status, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&genericStatus)
+	if err != nil {
+		return nil, err
+	}
+	if len(data.Status) > 0 {
+		extraStatus := make(map[string]interface{})
+		if err := utiljson.Unmarshal(data.Status, &extraStatus); err != nil {
+			return nil, fmt.Errorf("unmarshal status of revision %s failed: %w", hash, err)
+		}
+		for key, val := range extraStatus {
+			status[key] = val
+		}
+	}
+	if err := unstructured.SetNestedField(obj, status, consts.ObjectFieldNameStatus); err != nil {
+		return nil, err
+	}
+
+	if !CheckKCCTargetConfigHash(restored, hash) {
+		return nil, fmt.Errorf("hash of restored revision mismatches %s", hash)
+	}
+	return restored, nil
+}
@@ -1036,3 +1036,69 @@ func TestKCCTargetResource_IsExpired(t *testing.T) {
 		})
 	}
 }
+
+func TestRestoreKCCTargetConfigRevision(t *testing.T) {
+	t.Parallel()
+
+	newTarget := func(threshold float64) KCCTargetResource {
+		return ToKCCTargetResource(toTestUnstructured(&v1alpha1.AdminQoSConfiguration{
+			TypeMeta:   metav1.TypeMeta{Kind: "AdminQoSConfiguration"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "config-1"},
+			Spec: v1alpha1.AdminQoSConfigurationSpec{
+				Config: v1alpha1.AdminQoSConfig{
+					EvictionConfig: &v1alpha1.EvictionConfig{
+						MemoryPressureEvictionConfig: &v1alpha1.MemoryPressureEvictionConfig{
+							NumaFreeBelowWatermarkTimesThreshold: &nonDefaultNumaFreeBelowWatermarkTimesThreshold,
+						},
+						ReclaimedResourcesEvictionConfig: &v1alpha1.ReclaimedResourcesEvictionConfig{
+							EvictionThreshold: map[v1.ResourceName]float64{"cpu": threshold},
+						},
+					},
+				},
+			},
+		}))
+	}
+
+	gvr := metav1.GroupVersionResource{Group: "config.katalyst.kubewharf.io", Version: "v1alpha1", Resource: "adminqosconfigurations"}
+	oldTarget := newTarget(1.5)
+	oldHash, err := oldTarget.DeepCopy().GenerateConfigHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	revision, err := NewKCCTargetControllerRevision(gvr, oldTarget, oldHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revision.Namespace != "default" || revision.Name != "adminqosconfigurations-config-1-"+oldHash {
+		t.Errorf("NewKCCTargetControllerRevision() got key %s/%s", revision.Namespace, revision.Name)
+	}
+
+	target := newTarget(2)
+	currentHash, err := target.DeepCopy().GenerateConfigHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !CheckKCCTargetConfigHash(target, currentHash) || CheckKCCTargetConfigHash(target, oldHash) {
+		t.Errorf("CheckKCCTargetConfigHash() mismatches")
+	}
+
+	got, err := RestoreKCCTargetConfigRevision(target, oldHash, revision)
+	if err != nil {
+		t.Fatalf("RestoreKCCTargetConfigRevision() of old hash err = %v", err)
+	}
+	restored := &v1alpha1.AdminQoSConfiguration{}
+	if err := got.Unmarshal(restored); err != nil {
+		t.Fatal(err)
+	}
+	if threshold := restored.Spec.Config.EvictionConfig.ReclaimedResourcesEvictionConfig.EvictionThreshold["cpu"]; threshold != 1.5 {
+		t.Errorf("restored eviction threshold got = %v, want 1.5", threshold)
+	}
+	if times := restored.Spec.Config.EvictionConfig.MemoryPressureEvictionConfig.NumaFreeBelowWatermarkTimesThreshold; times == nil ||
+		*times != nonDefaultNumaFreeBelowWatermarkTimesThreshold {
+		t.Errorf("restored numa free below watermark times got = %v", times)
+	}
+
+	if _, err := RestoreKCCTargetConfigRevision(target, "unknown", revision); err == nil {
+		t.Errorf("RestoreKCCTargetConfigRevision() of mismatched hash should fail")
+	}
+}
@@ -45,7 +45,10 @@ func (i NUMANodeInfo) CPUSizeInNUMAs(nodes ...int) int {
 	return cpus
 }
 
-// CPUDetails is a map from CPU ID to Core ID, Socket ID, and NUMA ID.
+// l3CacheLevel is the cache level of L3 cache reported in sysfs cache topology
+const l3CacheLevel = 3
+
+// CPUDetails is a map from CPU ID to Core ID, L3 Cache ID, Socket ID, and NUMA ID.
 type CPUDetails map[int]CPUInfo
 
 // CPUTopology contains details of node cpu, where :
@@ -53,11 +56,13 @@ type CPUDetails map[int]CPUInfo
 // Core - physical CPU, cadvisor - Core
 // Socket - socket, cadvisor - Socket
 // NUMA Node - NUMA cell, cadvisor - Node
+// L3 Cache - last level cache shared by cores, cadvisor - UncoreCaches or Node Caches
 type CPUTopology struct {
 	NumCPUs              int
 	NumCores             int
 	NumSockets           int
 	NumNUMANodes         int
+	NumL3Caches          int
 	NUMANodeIDToSocketID map[int]int
 	NUMAToCPUs           NUMANodeInfo
 	CPUDetails           CPUDetails
@@ -191,6 +196,12 @@ func GenerateDummyMachineInfo(numaNum int, memoryCapacityGB int) (*info.MachineI
 }
 
 func GenerateDummyCPUTopology(cpuNum, socketNum, numaNum int) (*CPUTopology, error) {
+	return GenerateDummyCPUTopologyWithL3Caches(cpuNum, socketNum, numaNum, 1)
+}
+
+// GenerateDummyCPUTopologyWithL3Caches generates cpu topology in which each NUMA
+// is divided into l3CacheNumPerNUMA L3 caches evenly by cores.
+func GenerateDummyCPUTopologyWithL3Caches(cpuNum, socketNum, numaNum, l3CacheNumPerNUMA int) (*CPUTopology, error) {
 	if numaNum%socketNum != 0 {
 		return nil, fmt.Errorf("invalid NUMA number: %d and socket number: %d", numaNum, socketNum)
 	} else if cpuNum%numaNum != 0 {
@@ -198,6 +209,9 @@ func GenerateDummyCPUTopology(cpuNum, socketNum, numaNum int) (*CPUTopology, err
 	} else if cpuNum%2 != 0 {
 		// assume that we should use hyper-threads
 		return nil, fmt.Errorf("invalid cpu number: %d and NUMA number: %d", cpuNum, numaNum)
+	} else if l3CacheNumPerNUMA <= 0 || (cpuNum/numaNum/2)%l3CacheNumPerNUMA != 0 {
+		return nil, fmt.Errorf("invalid cpu number: %d, NUMA number: %d and L3 cache number per NUMA: %d",
+			cpuNum, numaNum, l3CacheNumPerNUMA)
 	}
 
 	cpuTopology := new(CPUTopology)
@@ -206,24 +220,30 @@ func GenerateDummyCPUTopology(cpuNum, socketNum, numaNum int) (*CPUTopology, err
 	cpuTopology.NumCores = cpuNum / 2
 	cpuTopology.NumSockets = socketNum
 	cpuTopology.NumNUMANodes = numaNum
+	cpuTopology.NumL3Caches = numaNum * l3CacheNumPerNUMA
 	cpuTopology.NUMANodeIDToSocketID = make(map[int]int, numaNum)
 
 	numaPerSocket := numaNum / socketNum
 	cpusPerNUMA := cpuNum / numaNum
+	coresPerL3Cache := cpusPerNUMA / 2 / l3CacheNumPerNUMA
 
 	for i := 0; i < socketNum; i++ {
 		for j := i * numaPerSocket; j < (i+1)*numaPerSocket; j++ {
 			for k := j * (cpusPerNUMA / 2); k < (j+1)*(cpusPerNUMA/2); k++ {
+				l3CacheID := k / coresPerL3Cache
+
 				cpuTopology.CPUDetails[k] = CPUInfo{
 					NUMANodeID: j,
 					SocketID:   i,
 					CoreID:     k,
+					L3CacheID:  l3CacheID,
 				}
 
 				cpuTopology.CPUDetails[k+cpuNum/2] = CPUInfo{
 					NUMANodeID: j,
 					SocketID:   i,
 					CoreID:     k,
+					L3CacheID:  l3CacheID,
 				}
 
 				cpuTopology.NUMANodeIDToSocketID[j] = i
@@ -288,11 +308,12 @@ func GenerateDummyExtraTopology(numaNum int) (*ExtraTopologyInfo, error) {
 	return extraTopology, nil
 }
 
-// CPUInfo contains the NUMA, socket, and core IDs associated with a CPU.
+// CPUInfo contains the NUMA, socket, core and L3 cache IDs associated with a CPU.
 type CPUInfo struct {
 	NUMANodeID int
 	SocketID   int
 	CoreID     int
+	L3CacheID  int
 }
 
 // KeepOnly returns a new CPUDetails object with only the supplied cpus.
@@ -440,6 +461,57 @@ func (d CPUDetails) CPUsInCores(ids ...int) CPUSet {
 	return b
 }
 
+// L3Caches returns all L3 cache IDs associated with the CPUs in this CPUDetails.
+func (d CPUDetails) L3Caches() CPUSet {
+	b := NewCPUSet()
+	for _, info := range d {
+		b.Add(info.L3CacheID)
+	}
+	return b
+}
+
+// L3CachesInNUMANodes returns all L3 cache IDs associated with the given
+// NUMANode IDs in this CPUDetails.
+func (d CPUDetails) L3CachesInNUMANodes(ids ...int) CPUSet {
+	b := NewCPUSet()
+	for _, id := range ids {
+		for _, info := range d {
+			if info.NUMANodeID == id {
+				b.Add(info.L3CacheID)
+			}
+		}
+	}
+	return b
+}
+
+// CoresInL3Caches returns all core IDs associated with the given
+// L3 cache IDs in this CPUDetails.
+func (d CPUDetails) CoresInL3Caches(ids ...int) CPUSet {
+	b := NewCPUSet()
+	for _, id := range ids {
+		for _, info := range d {
+			if info.L3CacheID == id {
+				b.Add(info.CoreID)
+			}
+		}
+	}
+	return b
+}
+
+// CPUsInL3Caches returns all logical CPU IDs associated with the given
+// L3 cache IDs in this CPUDetails.
+func (d CPUDetails) CPUsInL3Caches(ids ...int) CPUSet {
+	b := NewCPUSet()
+	for _, id := range ids {
+		for cpu, info := range d {
+			if info.L3CacheID == id {
+				b.Add(cpu)
+			}
+		}
+	}
+	return b
+}
+
 // Discover returns CPUTopology based on cadvisor node info
 func Discover(machineInfo *info.MachineInfo) (*CPUTopology, *MemoryTopology, error) {
 	if machineInfo.NumCores == 0 {
@@ -449,6 +521,7 @@ func Discover(machineInfo *info.MachineInfo) (*CPUTopology, *MemoryTopology, err
 	cpuDetails := CPUDetails{}
 	numaNodeIDToSocketID := make(map[int]int, len(machineInfo.Topology))
 	numPhysicalCores := 0
+	l3CacheFound := true
 
 	memoryTopology := MemoryTopology{
 		MemoryDetails: map[int]uint64{},
@@ -461,11 +534,15 @@ func Discover(machineInfo *info.MachineInfo) (*CPUTopology, *MemoryTopology, err
 		numPhysicalCores += len(node.Cores)
 		for _, core := range node.Cores {
 			if coreID, err := getUniqueCoreID(core.Threads); err == nil {
+				l3CacheID, found := GetL3CacheID(node, core)
+				l3CacheFound = l3CacheFound && found
+
 				for _, cpu := range core.Threads {
 					cpuDetails[cpu] = CPUInfo{
 						CoreID:     coreID,
 						SocketID:   core.SocketID,
 						NUMANodeID: node.Id,
+						L3CacheID:  l3CacheID,
 					}
 
 					numaNodeIDToSocketID[node.Id] = core.SocketID
@@ -478,6 +555,16 @@ func Discover(machineInfo *info.MachineInfo) (*CPUTopology, *MemoryTopology, err
 		}
 	}
 
+	// L3 cache IDs may conflict with each other if some of them are missing,
+	// so we take each NUMA as an L3 cache domain in this case.
+	if !l3CacheFound {
+		klog.Warningf("L3 cache of some cores not found, take NUMA nodes as L3 cache domains")
+		for cpu, info := range cpuDetails {
+			info.L3CacheID = info.NUMANodeID
+			cpuDetails[cpu] = info
+		}
+	}
+
 	numNUMANodes := cpuDetails.NUMANodes().Size()
 	numaToCPUs := make(NUMANodeInfo, numNUMANodes)
 	for id := range numaNodeIDToSocketID {
@@ -489,12 +576,32 @@ func Discover(machineInfo *info.MachineInfo) (*CPUTopology, *MemoryTopology, err
 		NumSockets:           machineInfo.NumSockets,
 		NumCores:             numPhysicalCores,
 		NumNUMANodes:         numNUMANodes,
+		NumL3Caches:          cpuDetails.L3Caches().Size(),
 		NUMANodeIDToSocketID: numaNodeIDToSocketID,
 		NUMAToCPUs:           numaToCPUs,
 		CPUDetails:           cpuDetails,
 	}, &memoryTopology, nil
 }
 
+// GetL3CacheID returns the ID of L3 cache shared by the core, which is discovered
+// from sysfs cache topology by cadvisor; L3 caches shared by some cores of the NUMA
+// (e.g. CCDs of AMD EPYC) are in UncoreCaches, and those shared by all cores of the
+// NUMA are in node Caches.
+func GetL3CacheID(node info.Node, core info.Core) (int, bool) {
+	for _, cache := range core.UncoreCaches {
+		if cache.Level == l3CacheLevel {
+			return cache.Id, true
+		}
+	}
+
+	for _, cache := range node.Caches {
+		if cache.Level == l3CacheLevel {
+			return cache.Id, true
+		}
+	}
+	return 0, false
+}
+
 // getUniqueCoreID computes coreId as the lowest cpuID
 // for a given Threads []int slice. This will assure that coreID's are
 // platform unique (opposite to what cAdvisor reports)
@@ -20,6 +20,7 @@ import (
 	"reflect"
 	"testing"
 
+	info "github.com/google/cadvisor/info/v1"
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/util/sets"
 
@@ -810,3 +811,117 @@ func TestGetInterfaceSocketInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestDiscoverL3Caches(t *testing.T) {
+	t.Parallel()
+
+	newCore := func(id, socketID int, threads []int, uncoreCaches []info.Cache) info.Core {
+		return info.Core{Id: id, SocketID: socketID, Threads: threads, UncoreCaches: uncoreCaches}
+	}
+	l3Cache := func(id int) []info.Cache {
+		return []info.Cache{{Id: id, Level: 3, Type: "Unified"}}
+	}
+
+	tests := []struct {
+		name           string
+		machineInfo    *info.MachineInfo
+		wantL3CacheIDs map[int]int
+		wantNumL3      int
+	}{
+		{
+			name: "L3 caches shared by part of cores in NUMA",
+			machineInfo: &info.MachineInfo{
+				NumCores: 8,
+				Topology: []info.Node{
+					{
+						Id: 0,
+						Cores: []info.Core{
+							newCore(0, 0, []int{0, 4}, l3Cache(0)),
+							newCore(1, 0, []int{1, 5}, l3Cache(1)),
+						},
+					},
+					{
+						Id: 1,
+						Cores: []info.Core{
+							newCore(2, 0, []int{2, 6}, l3Cache(2)),
+							newCore(3, 0, []int{3, 7}, l3Cache(3)),
+						},
+					},
+				},
+			},
+			wantL3CacheIDs: map[int]int{0: 0, 4: 0, 1: 1, 5: 1, 2: 2, 6: 2, 3: 3, 7: 3},
+			wantNumL3:      4,
+		},
+		{
+			name: "L3 caches shared by all cores in NUMA",
+			machineInfo: &info.MachineInfo{
+				NumCores: 4,
+				Topology: []info.Node{
+					{
+						Id:     0,
+						Cores:  []info.Core{newCore(0, 0, []int{0, 2}, nil)},
+						Caches: []info.Cache{{Id: 5, Level: 3, Type: "Unified"}},
+					},
+					{
+						Id:     1,
+						Cores:  []info.Core{newCore(1, 1, []int{1, 3}, nil)},
+						Caches: []info.Cache{{Id: 6, Level: 3, Type: "Unified"}},
+					},
+				},
+			},
+			wantL3CacheIDs: map[int]int{0: 5, 2: 5, 1: 6, 3: 6},
+			wantNumL3:      2,
+		},
+		{
+			name: "L3 caches missing",
+			machineInfo: &info.MachineInfo{
+				NumCores: 4,
+				Topology: []info.Node{
+					{
+						Id:    0,
+						Cores: []info.Core{newCore(0, 0, []int{0, 2}, l3Cache(1))},
+					},
+					{
+						Id:    1,
+						Cores: []info.Core{newCore(1, 1, []int{1, 3}, nil)},
+					},
+				},
+			},
+			wantL3CacheIDs: map[int]int{0: 0, 2: 0, 1: 1, 3: 1},
+			wantNumL3:      2,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			topology, _, err := Discover(tt.machineInfo)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantNumL3, topology.NumL3Caches)
+			for cpu, l3CacheID := range tt.wantL3CacheIDs {
+				assert.Equal(t, l3CacheID, topology.CPUDetails[cpu].L3CacheID, "cpu %d", cpu)
+			}
+		})
+	}
+}
+
+func TestGenerateDummyCPUTopologyWithL3Caches(t *testing.T) {
+	t.Parallel()
+
+	topology, err := GenerateDummyCPUTopologyWithL3Caches(16, 1, 2, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, topology.NumL3Caches)
+	assert.Equal(t, NewCPUSet(0, 1, 2, 3), topology.CPUDetails.L3Caches())
+	assert.Equal(t, NewCPUSet(2, 3), topology.CPUDetails.L3CachesInNUMANodes(1))
+	assert.Equal(t, NewCPUSet(0, 1), topology.CPUDetails.CoresInL3Caches(0))
+	assert.Equal(t, NewCPUSet(0, 1, 8, 9), topology.CPUDetails.CPUsInL3Caches(0))
+
+	_, err = GenerateDummyCPUTopologyWithL3Caches(16, 1, 2, 3)
+	assert.Error(t, err)
+
+	topology, err = GenerateDummyCPUTopology(16, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, topology.CPUDetails.NUMANodes(), topology.CPUDetails.L3Caches())
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	NodeKind = "Node"
+)
+
+// those fields are used by reporter plugins to refer specific field names of Node;
+// labels and annotations should be reported as metadata fields with map[string]string values,
+// while capacity and allocatable should be reported as status fields with v1.ResourceList values.
+const (
+	NodeFieldNameLabels      = "Labels"
+	NodeFieldNameAnnotations = "Annotations"
+	NodeFieldNameCapacity    = "Capacity"
+	NodeFieldNameAllocatable = "Allocatable"
+)
+
+var NodeGroupVersionKind = metav1.GroupVersionKind{
+	Group:   corev1.SchemeGroupVersion.Group,
+	Kind:    NodeKind,
+	Version: corev1.SchemeGroupVersion.Version,
+}
@@ -103,10 +103,10 @@ func (h *HTTPHandler) Run(ctx context.Context) {
 		go wait.Until(h.cleanupVisitor, httpCleanupVisitorPeriod, ctx.Done())
 	}
 
-	if h.enabled.Has(HTTPChainCredential) {
-		h.cred.Run(ctx)
-		h.accessCtl.Run(ctx)
-	}
+	// credential and access control are always run, since handlers wrapped by
+	// WithStrictCredential need them even if the credential chain is disabled
+	h.cred.Run(ctx)
+	h.accessCtl.Run(ctx)
 }
 
 func (h *HTTPHandler) getHTTPVisitor(subject string) *rate.Limiter {
@@ -194,6 +194,39 @@ func (h *HTTPHandler) withCredential(f http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// WithStrictCredential wraps the handler to always authenticate and authorize requests, no matter
+// whether strict authentication or the credential chain is enabled; anonymous subjects of insecure
+// credentials are rejected as well, so the handler won't be accessible until credentials are set up.
+func (h *HTTPHandler) WithStrictCredential(f http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		authInfo, err := h.cred.Auth(r)
+		if err != nil || authInfo.AuthType() == credential.AuthTypeInsecure {
+			klog.Warningf("request %+v to strict path doesn't have proper auth", r.URL)
+			w.Header().Set("Katalyst-Authenticate", `Basic realm="Restricted"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = h.emitter.StoreInt64(HTTPAuthenticateFailed, 1, metrics.MetricTypeNameCount,
+				metrics.MetricTag{Key: "path", Val: r.URL.Path})
+			return
+		}
+
+		if verifyErr := h.accessCtl.Verify(authInfo, authorization.PermissionTypeHttpEndpoint); verifyErr != nil {
+			klog.Warningf("request %+v with user %v doesn't have permission, msg: %v", r.URL, authInfo.SubjectName(), verifyErr)
+			w.WriteHeader(http.StatusForbidden)
+			_ = h.emitter.StoreInt64(HTTPNoPermission, 1, metrics.MetricTypeNameCount,
+				metrics.MetricTag{Key: "path", Val: r.URL.Path},
+				metrics.MetricTag{Key: "user", Val: authInfo.SubjectName()})
+			return
+		}
+
+		f.ServeHTTP(w, attachAuthInfo(r, authInfo))
+	})
+}
+
 // withRateLimiter is used to limit user-requests to protect server
 func (h *HTTPHandler) withRateLimiter(f http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 	"time"
@@ -27,6 +28,8 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/credential"
+	"github.com/kubewharf/katalyst-core/pkg/util/credential/authorization"
 )
 
 type dummyHandler struct {
@@ -164,3 +167,68 @@ func TestHTTPHandler(t *testing.T) {
 		cancel()
 	}
 }
+
+type fakeCredential struct {
+	credential.Credential
+	authInfo credential.AuthInfo
+	err      error
+}
+
+func (f *fakeCredential) Auth(_ *http.Request) (credential.AuthInfo, error) { return f.authInfo, f.err }
+func (f *fakeCredential) Run(_ context.Context)                             {}
+
+type fakeAccessControl struct {
+	allowed string
+}
+
+func (f *fakeAccessControl) Verify(authInfo credential.AuthInfo, _ authorization.PermissionType) error {
+	if authInfo.SubjectName() != f.allowed {
+		return fmt.Errorf("%v is not allowed", authInfo.SubjectName())
+	}
+	return nil
+}
+func (f *fakeAccessControl) Run(_ context.Context) {}
+
+func TestHTTPHandlerWithStrictCredential(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		comment  string
+		cred     credential.Credential
+		wantCode int
+	}{
+		{
+			comment:  "anonymous subject of insecure credential is rejected",
+			cred:     credential.DefaultCredential(),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			comment:  "unauthenticated request is rejected",
+			cred:     &fakeCredential{err: fmt.Errorf("invalid token")},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			comment:  "unauthorized subject is rejected",
+			cred:     &fakeCredential{authInfo: credential.BasicAuthInfo{Username: "other"}},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			comment:  "authorized subject is served",
+			cred:     &fakeCredential{authInfo: credential.BasicAuthInfo{Username: "admin"}},
+			wantCode: http.StatusOK,
+		},
+	} {
+		t.Logf("test case: %v", tc.comment)
+
+		// strict authentication and credential chain are both disabled
+		h := NewHTTPHandler([]string{}, []string{}, false, metrics.DummyMetrics{})
+		assert.NoError(t, h.WithCredential(tc.cred))
+		assert.NoError(t, h.WithAuthorization(&fakeAccessControl{allowed: "admin"}))
+
+		f := &dummyHandler{}
+		w := httptest.NewRecorder()
+		h.WithHandleChain(h.WithStrictCredential(f)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/strict", nil))
+		assert.Equal(t, tc.wantCode, w.Code)
+		assert.Equal(t, tc.wantCode == http.StatusOK, f.success == 1)
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ihpa
+
+import (
+	"context"
+	"fmt"
+
+	kubewebhook "github.com/slok/kubewebhook/pkg/webhook"
+	"github.com/slok/kubewebhook/pkg/webhook/validating"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	apis "github.com/kubewharf/katalyst-api/pkg/apis/autoscaling/v1alpha2"
+	apiListers "github.com/kubewharf/katalyst-api/pkg/client/listers/autoscaling/v1alpha2"
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	webhookconsts "github.com/kubewharf/katalyst-core/cmd/katalyst-webhook/app/webhook"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	webhookconfig "github.com/kubewharf/katalyst-core/pkg/config/webhook"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const (
+	ihpaWebhookName = "ihpa"
+)
+
+// WebhookIHPA is the implementation of Kubernetes Webhook
+// any implementation should at least implement the interface of mutating.Mutator of validating.Validator
+type WebhookIHPA struct {
+	ctx    context.Context
+	dryRun bool
+
+	validators    []WebhookIHPAValidator
+	metricEmitter metrics.MetricEmitter
+
+	// ihpaListerSynced returns true if the IntelligentHorizontalPodAutoscaler store has been synced at least once.
+	ihpaListerSynced cache.InformerSynced
+	// ihpaLister can list/get IntelligentHorizontalPodAutoscaler from the shared informer's store
+	ihpaLister apiListers.IntelligentHorizontalPodAutoscalerLister
+}
+
+type WebhookIHPAValidator interface {
+	ValidateIHPA(ihpa *apis.IntelligentHorizontalPodAutoscaler) (valid bool, message string, err error)
+}
+
+func NewWebhookIHPA(ctx context.Context, webhookCtx *katalystbase.GenericContext,
+	genericConf *generic.GenericConfiguration, _ *webhookconfig.GenericWebhookConfiguration,
+	_ *webhookconfig.WebhooksConfiguration, metricsEmitter metrics.MetricEmitter,
+) (kubewebhook.Webhook, webhookconsts.GenericStartFunc, error) {
+	wi := &WebhookIHPA{
+		ctx:    ctx,
+		dryRun: genericConf.DryRun,
+	}
+
+	wi.metricEmitter = metricsEmitter
+	if metricsEmitter == nil {
+		wi.metricEmitter = metrics.DummyMetrics{}
+	}
+
+	wi.ihpaListerSynced = webhookCtx.InternalInformerFactory.Autoscaling().V1alpha2().IntelligentHorizontalPodAutoscalers().Informer().HasSynced
+	wi.ihpaLister = webhookCtx.InternalInformerFactory.Autoscaling().V1alpha2().IntelligentHorizontalPodAutoscalers().Lister()
+
+	wi.validators = []WebhookIHPAValidator{
+		NewWebhookIHPASpecValidator(),
+		NewWebhookIHPAOverlapValidator(wi.ihpaLister),
+	}
+
+	cfg := validating.WebhookConfig{
+		Name: "ihpaValidator",
+		Obj:  &apis.IntelligentHorizontalPodAutoscaler{},
+	}
+
+	webhook, err := validating.NewWebhook(cfg, wi, nil, nil, nil)
+	if err != nil {
+		return nil, wi.Run, err
+	}
+	return webhook, wi.Run, nil
+}
+
+func (wi *WebhookIHPA) Run() bool {
+	if !cache.WaitForCacheSync(wi.ctx.Done(), wi.ihpaListerSynced) {
+		klog.Errorf("unable to sync caches for %s webhook", ihpaWebhookName)
+		return false
+	}
+	klog.Infof("Caches are synced for %s webhook", ihpaWebhookName)
+
+	return true
+}
+
+func (wi *WebhookIHPA) Validate(_ context.Context, obj metav1.Object) (bool, validating.ValidatorResult, error) {
+	klog.V(5).Info("notice an obj to be validated")
+	ihpa, ok := obj.(*apis.IntelligentHorizontalPodAutoscaler)
+	if !ok {
+		err := fmt.Errorf("failed to convert obj to ihpa: %v", obj)
+		klog.Error(err.Error())
+		return false, validating.ValidatorResult{}, err
+	}
+	if ihpa == nil {
+		err := fmt.Errorf("ihpa can't be nil")
+		klog.Error(err.Error())
+		return false, validating.ValidatorResult{}, err
+	}
+
+	klog.V(5).Infof("begin to validate ihpa %s/%s", ihpa.Namespace, ihpa.Name)
+
+	for _, validator := range wi.validators {
+		succeed, msg, err := validator.ValidateIHPA(ihpa)
+		if err != nil {
+			klog.Errorf("an err occurred when validating ihpa %s/%s: %v", ihpa.Namespace, ihpa.Name, err)
+			_ = wi.metricEmitter.StoreInt64("ihpa_webhook_error", 1, metrics.MetricTypeNameCount,
+				metrics.MetricTag{Key: "name", Val: ihpa.Name})
+			return false, validating.ValidatorResult{}, err
+		} else if !succeed {
+			klog.Infof("ihpa %s/%s didn't pass the webhook: %s", ihpa.Namespace, ihpa.Name, msg)
+			_ = wi.metricEmitter.StoreInt64("ihpa_webhook_fail", 1, metrics.MetricTypeNameCount,
+				metrics.MetricTag{Key: "name", Val: ihpa.Name})
+			if !wi.dryRun {
+				return false, validating.ValidatorResult{Valid: false, Message: msg}, nil
+			}
+		}
+	}
+
+	_ = wi.metricEmitter.StoreInt64("ihpa_webhook_succeed", 1, metrics.MetricTypeNameCount,
+		metrics.MetricTag{Key: "name", Val: ihpa.Name})
+	klog.Infof("ihpa %s/%s passed the validation webhook", ihpa.Namespace, ihpa.Name)
+	return false, validating.ValidatorResult{Valid: true, Message: "validation succeed"}, nil
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ihpa
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+
+	apis "github.com/kubewharf/katalyst-api/pkg/apis/autoscaling/v1alpha2"
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	webhookconfig "github.com/kubewharf/katalyst-core/pkg/config/webhook"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+)
+
+func makeIHPA(name, workload string, mutate func(spec *apis.IntelligentHorizontalPodAutoscalerSpec)) *apis.IntelligentHorizontalPodAutoscaler {
+	ihpa := &apis.IntelligentHorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+		},
+		Spec: apis.IntelligentHorizontalPodAutoscalerSpec{
+			Autoscaler: apis.AutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+					Kind:       "Deployment",
+					Name:       workload,
+					APIVersion: "apps/v1",
+				},
+				MinReplicas: pointer.Int32(1),
+				MaxReplicas: 10,
+			},
+			ScaleStrategy: apis.Auto,
+		},
+	}
+	if mutate != nil {
+		mutate(&ihpa.Spec)
+	}
+	return ihpa
+}
+
+func withAnnotations(ihpa *apis.IntelligentHorizontalPodAutoscaler, annotations map[string]string) *apis.IntelligentHorizontalPodAutoscaler {
+	ihpa.Annotations = annotations
+	return ihpa
+}
+
+func TestValidateIHPA(t *testing.T) {
+	t.Parallel()
+
+	existing := []runtime.Object{
+		makeIHPA("ihpa1", "dp1", nil),
+	}
+
+	quantity := resource.MustParse("100m")
+	for _, tc := range []struct {
+		name       string
+		ihpa       *apis.IntelligentHorizontalPodAutoscaler
+		dryRun     bool
+		expAllowed bool
+		expMessage string
+	}{
+		{
+			name: "valid ihpa",
+			ihpa: makeIHPA("ihpa2", "dp2", func(spec *apis.IntelligentHorizontalPodAutoscalerSpec) {
+				spec.Autoscaler.Metrics = []apis.MetricSpec{
+					{CustomMetric: &apis.CustomMetricSpec{Identify: "cpu_usage", Value: &quantity}},
+				}
+				spec.TimeBounds = []apis.TimeBound{
+					{
+						Start: metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+						End:   metav1.NewTime(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)),
+						Bounds: []apis.Bound{
+							{CronTab: "0 8 * * *", MinReplicas: pointer.Int32(5)},
+						},
+					},
+				}
+			}),
+			expAllowed: true,
+			expMessage: "validation succeed",
+		},
+		{
+			name:       "update existing ihpa",
+			ihpa:       makeIHPA("ihpa1", "dp1", nil),
+			expAllowed: true,
+			expMessage: "validation succeed",
+		},
+		{
+			name:       "overlapped scale target reference",
+			ihpa:       makeIHPA("ihpa2", "dp1", nil),
+			expAllowed: false,
+			expMessage: "has the same scale target reference",
+		},
+		{
+			name:       "overlapped scale target reference in dry run",
+			ihpa:       makeIHPA("ihpa2", "dp1", nil),
+			dryRun:     true,
+			expAllowed: true,
+			expMessage: "validation succeed",
+		},
+		{
+			name: "min replicas greater than max replicas",
+			ihpa: makeIHPA("ihpa2", "dp2", func(spec *apis.IntelligentHorizontalPodAutoscalerSpec) {
+				spec.Autoscaler.MinReplicas = pointer.Int32(11)
+			}),
+			expAllowed: false,
+			expMessage: "is greater than maxReplicas",
+		},
+		{
+			name: "unsupported scale strategy",
+			ihpa: makeIHPA("ihpa2", "dp2", func(spec *apis.IntelligentHorizontalPodAutoscalerSpec) {
+				spec.ScaleStrategy = "Manual"
+			}),
+			expAllowed: false,
+			expMessage: "unsupported scale strategy",
+		},
+		{
+			name: "custom metric without value",
+			ihpa: makeIHPA("ihpa2", "dp2", func(spec *apis.IntelligentHorizontalPodAutoscalerSpec) {
+				spec.Autoscaler.Metrics = []apis.MetricSpec{
+					{CustomMetric: &apis.CustomMetricSpec{Identify: "cpu_usage"}},
+				}
+			}),
+			expAllowed: false,
+			expMessage: "value of custom metric cpu_usage must be set",
+		},
+		{
+			name: "empty metric",
+			ihpa: makeIHPA("ihpa2", "dp2", func(spec *apis.IntelligentHorizontalPodAutoscalerSpec) {
+				spec.Autoscaler.Metrics = []apis.MetricSpec{{}}
+			}),
+			expAllowed: false,
+			expMessage: "exactly one of metric and customMetric must be set",
+		},
+		{
+			name: "invalid crontab",
+			ihpa: makeIHPA("ihpa2", "dp2", func(spec *apis.IntelligentHorizontalPodAutoscalerSpec) {
+				spec.TimeBounds = []apis.TimeBound{
+					{Bounds: []apis.Bound{{CronTab: "every day"}}},
+				}
+			}),
+			expAllowed: false,
+			expMessage: "invalid crontab",
+		},
+		{
+			name: "time bound ends before start",
+			ihpa: makeIHPA("ihpa2", "dp2", func(spec *apis.IntelligentHorizontalPodAutoscalerSpec) {
+				spec.TimeBounds = []apis.TimeBound{
+					{
+						Start: metav1.NewTime(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)),
+						End:   metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+					},
+				}
+			}),
+			expAllowed: false,
+			expMessage: "start must be before end",
+		},
+		{
+			name: "valid scaling rules",
+			ihpa: withAnnotations(makeIHPA("ihpa2", "dp2", nil), map[string]string{
+				consts.IHPAAnnotationKeyScheduledMinReplicas: `[{"cronTab":"0 9 * * 1-5","duration":"2h","minReplicas":5}]`,
+				consts.IHPAAnnotationKeyScaleUpOverride:      `{"minReplicas":8,"expireTime":"2024-01-01T10:00:00Z"}`,
+				consts.IHPAAnnotationKeyScaleUpTriggers:      `[{"metric":"qps","threshold":"1k","minReplicas":8,"duration":"30m"}]`,
+			}),
+			expAllowed: true,
+			expMessage: "validation succeed",
+		},
+		{
+			name: "malformed scheduled min replicas",
+			ihpa: withAnnotations(makeIHPA("ihpa2", "dp2", nil), map[string]string{
+				consts.IHPAAnnotationKeyScheduledMinReplicas: `{"cronTab":"0 9 * * 1-5"}`,
+			}),
+			expAllowed: false,
+			expMessage: "invalid annotation",
+		},
+		{
+			name: "scale-up trigger without duration",
+			ihpa: withAnnotations(makeIHPA("ihpa2", "dp2", nil), map[string]string{
+				consts.IHPAAnnotationKeyScaleUpTriggers: `[{"metric":"qps","threshold":"1k","minReplicas":8}]`,
+			}),
+			expAllowed: false,
+			expMessage: "duration must be positive",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			genericConf := generic.NewGenericConfiguration()
+			genericConf.DryRun = tc.dryRun
+			webhookGenericConf := webhookconfig.NewGenericWebhookConfiguration()
+
+			webhookCtx, err := katalystbase.GenerateFakeGenericContext(nil, existing, nil)
+			assert.NoError(t, err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			wh, run, err := NewWebhookIHPA(ctx, webhookCtx, genericConf, webhookGenericConf,
+				webhookconfig.NewWebhooksConfiguration(), nil)
+			assert.NoError(t, err)
+
+			webhookCtx.StartInformer(ctx)
+			assert.True(t, run())
+
+			raw, err := json.Marshal(tc.ihpa)
+			assert.NoError(t, err)
+
+			review := &admissionv1beta1.AdmissionReview{
+				Request: &admissionv1beta1.AdmissionRequest{
+					UID:       "test",
+					Operation: admissionv1beta1.Create,
+					Object: runtime.RawExtension{
+						Raw: raw,
+					},
+				},
+			}
+
+			gotResponse := wh.Review(context.TODO(), review)
+			assert.Equal(t, tc.expAllowed, gotResponse.Allowed)
+			assert.Equal(t, review.Request.UID, gotResponse.UID)
+			assert.NotNil(t, gotResponse.Result)
+			assert.Contains(t, gotResponse.Result.Message, tc.expMessage)
+		})
+	}
+}
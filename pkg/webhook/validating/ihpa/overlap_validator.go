@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ihpa
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	apis "github.com/kubewharf/katalyst-api/pkg/apis/autoscaling/v1alpha2"
+	apiListers "github.com/kubewharf/katalyst-api/pkg/client/listers/autoscaling/v1alpha2"
+)
+
+// WebhookIHPAOverlapValidator validate if one ihpa overlaps with other ihpas in the same
+// namespace by checking their scale target reference, since the generated hpas
+// will fight with each other otherwise
+type WebhookIHPAOverlapValidator struct {
+	// ihpaLister can list/get IntelligentHorizontalPodAutoscaler from the shared informer's store
+	ihpaLister apiListers.IntelligentHorizontalPodAutoscalerLister
+}
+
+func NewWebhookIHPAOverlapValidator(ihpaLister apiListers.IntelligentHorizontalPodAutoscalerLister) *WebhookIHPAOverlapValidator {
+	return &WebhookIHPAOverlapValidator{
+		ihpaLister: ihpaLister,
+	}
+}
+
+func (wo *WebhookIHPAOverlapValidator) ValidateIHPA(ihpa *apis.IntelligentHorizontalPodAutoscaler) (valid bool, message string, err error) {
+	if ihpa == nil {
+		err := fmt.Errorf("ihpa is nil")
+		return false, err.Error(), err
+	}
+
+	ihpas, err := wo.ihpaLister.IntelligentHorizontalPodAutoscalers(ihpa.Namespace).List(labels.Everything())
+	if err != nil {
+		return false, "failed to list ihpas", err
+	}
+	klog.V(5).Infof("find %d ihpa existing in namespace %s", len(ihpas), ihpa.Namespace)
+
+	targetRef := ihpa.Spec.Autoscaler.ScaleTargetRef
+	for _, anotherIHPA := range ihpas {
+		if anotherIHPA == nil || anotherIHPA.Name == ihpa.Name {
+			continue
+		}
+
+		anotherTargetRef := anotherIHPA.Spec.Autoscaler.ScaleTargetRef
+		if anotherTargetRef.Kind == targetRef.Kind && anotherTargetRef.Name == targetRef.Name {
+			return false, fmt.Sprintf("ihpa %s has the same scale target reference %s/%s",
+				anotherIHPA.Name, targetRef.Kind, targetRef.Name), nil
+		}
+	}
+
+	return true, "", nil
+}
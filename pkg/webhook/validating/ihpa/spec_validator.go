@@ -0,0 +1,128 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ihpa
+
+import (
+	"fmt"
+
+	"github.com/robfig/cron/v3"
+
+	apis "github.com/kubewharf/katalyst-api/pkg/apis/autoscaling/v1alpha2"
+	"github.com/kubewharf/katalyst-core/pkg/util"
+)
+
+// WebhookIHPASpecValidator validate:
+// 1. if scale target reference is set
+// 2. if min/max replicas are consistent
+// 3. if scale strategy is supported
+// 4. if each metric is either a native metric or a well-formed custom metric
+// 5. if time bounds have valid time ranges, crontabs and replicas
+// 6. if scaling rules in annotations are well-formed
+type WebhookIHPASpecValidator struct{}
+
+func NewWebhookIHPASpecValidator() *WebhookIHPASpecValidator {
+	return &WebhookIHPASpecValidator{}
+}
+
+func (sv *WebhookIHPASpecValidator) ValidateIHPA(ihpa *apis.IntelligentHorizontalPodAutoscaler) (valid bool, message string, err error) {
+	if ihpa == nil {
+		err := fmt.Errorf("ihpa is nil")
+		return false, err.Error(), err
+	}
+
+	autoscaler := ihpa.Spec.Autoscaler
+	if autoscaler.ScaleTargetRef.Kind == "" || autoscaler.ScaleTargetRef.Name == "" {
+		return false, "kind and name of scale target reference must be set", nil
+	}
+
+	if msg := validateReplicas(autoscaler.MinReplicas, &autoscaler.MaxReplicas); msg != "" {
+		return false, msg, nil
+	}
+
+	switch ihpa.Spec.ScaleStrategy {
+	case "", apis.Preview, apis.Auto:
+	default:
+		return false, fmt.Sprintf("unsupported scale strategy %s", ihpa.Spec.ScaleStrategy), nil
+	}
+
+	for i, metric := range autoscaler.Metrics {
+		if msg := validateMetric(metric); msg != "" {
+			return false, fmt.Sprintf("metrics[%d]: %s", i, msg), nil
+		}
+	}
+
+	for i, timeBound := range ihpa.Spec.TimeBounds {
+		if msg := validateTimeBound(timeBound); msg != "" {
+			return false, fmt.Sprintf("timeBounds[%d]: %s", i, msg), nil
+		}
+	}
+
+	if err := util.ValidateIHPAScalingRules(ihpa); err != nil {
+		return false, err.Error(), nil
+	}
+
+	return true, "", nil
+}
+
+func validateReplicas(minReplicas, maxReplicas *int32) string {
+	if maxReplicas != nil && *maxReplicas < 1 {
+		return fmt.Sprintf("maxReplicas %d must be greater than 0", *maxReplicas)
+	}
+
+	if minReplicas != nil {
+		if *minReplicas < 1 {
+			return fmt.Sprintf("minReplicas %d must be greater than 0", *minReplicas)
+		} else if maxReplicas != nil && *minReplicas > *maxReplicas {
+			return fmt.Sprintf("minReplicas %d is greater than maxReplicas %d", *minReplicas, *maxReplicas)
+		}
+	}
+	return ""
+}
+
+func validateMetric(metric apis.MetricSpec) string {
+	if (metric.Metric == nil) == (metric.CustomMetric == nil) {
+		return "exactly one of metric and customMetric must be set"
+	}
+
+	if metric.CustomMetric != nil {
+		if metric.CustomMetric.Identify == "" {
+			return "identify of custom metric must be set"
+		} else if metric.CustomMetric.Value == nil {
+			return fmt.Sprintf("value of custom metric %s must be set", metric.CustomMetric.Identify)
+		} else if metric.CustomMetric.Value.Sign() < 0 {
+			return fmt.Sprintf("value of custom metric %s must not be negative", metric.CustomMetric.Identify)
+		}
+	}
+	return ""
+}
+
+func validateTimeBound(timeBound apis.TimeBound) string {
+	if !timeBound.Start.IsZero() && !timeBound.End.IsZero() && !timeBound.Start.Before(&timeBound.End) {
+		return "start must be before end"
+	}
+
+	for i, bound := range timeBound.Bounds {
+		if _, err := cron.ParseStandard(bound.CronTab); err != nil {
+			return fmt.Sprintf("bounds[%d]: invalid crontab %q: %v", i, bound.CronTab, err)
+		}
+
+		if msg := validateReplicas(bound.MinReplicas, bound.MaxReplicas); msg != "" {
+			return fmt.Sprintf("bounds[%d]: %s", i, msg)
+		}
+	}
+	return ""
+}
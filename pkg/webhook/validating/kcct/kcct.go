@@ -0,0 +1,231 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kcct
+
+import (
+	"context"
+	"fmt"
+
+	kubewebhook "github.com/slok/kubewebhook/pkg/webhook"
+	whcontext "github.com/slok/kubewebhook/pkg/webhook/context"
+	"github.com/slok/kubewebhook/pkg/webhook/validating"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	configlisters "github.com/kubewharf/katalyst-api/pkg/client/listers/config/v1alpha1"
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	webhookconsts "github.com/kubewharf/katalyst-core/cmd/katalyst-webhook/app/webhook"
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	webhookconfig "github.com/kubewharf/katalyst-core/pkg/config/webhook"
+	kcctarget "github.com/kubewharf/katalyst-core/pkg/controller/kcc/target"
+	kccutil "github.com/kubewharf/katalyst-core/pkg/controller/kcc/util"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+)
+
+const (
+	kcctWebhookName = "kcct"
+)
+
+// WebhookKCCT validates the kcc targets (i.e. the config CRDs referenced by KCCs) with the same
+// generic spec rules as KatalystCustomConfigTargetController, so that invalid configurations
+// are rejected when being applied instead of being marked invalid afterwards.
+type WebhookKCCT struct {
+	ctx    context.Context
+	dryRun bool
+
+	metricEmitter metrics.MetricEmitter
+
+	targetHandler *kcctarget.KatalystCustomConfigTargetHandler
+
+	// kccListerSynced returns true if the KatalystCustomConfig store has been synced at least once.
+	kccListerSynced cache.InformerSynced
+	// kccLister can list/get KatalystCustomConfig from the shared informer's store
+	kccLister configlisters.KatalystCustomConfigLister
+}
+
+func NewWebhookKCCT(ctx context.Context, webhookCtx *katalystbase.GenericContext,
+	genericConf *generic.GenericConfiguration, _ *webhookconfig.GenericWebhookConfiguration,
+	webhookConf *webhookconfig.WebhooksConfiguration, metricsEmitter metrics.MetricEmitter,
+) (kubewebhook.Webhook, webhookconsts.GenericStartFunc, error) {
+	if webhookConf == nil || webhookConf.KCCTConfig == nil {
+		return nil, nil, fmt.Errorf("kcct webhook configuration can't be nil")
+	}
+
+	wk := &WebhookKCCT{
+		ctx:    ctx,
+		dryRun: genericConf.DryRun,
+	}
+
+	wk.metricEmitter = metricsEmitter
+	if metricsEmitter == nil {
+		wk.metricEmitter = metrics.DummyMetrics{}
+	}
+
+	kccInformer := webhookCtx.InternalInformerFactory.Config().V1alpha1().KatalystCustomConfigs()
+	wk.kccListerSynced = kccInformer.Informer().HasSynced
+	wk.kccLister = kccInformer.Lister()
+
+	wk.targetHandler = kcctarget.NewKatalystCustomConfigTargetHandler(ctx, webhookCtx.Client,
+		&controller.KCCConfig{
+			ValidAPIGroupSet: webhookConf.KCCTConfig.ValidAPIGroupSet,
+			DefaultGVRs:      webhookConf.KCCTConfig.DefaultGVRs,
+		}, kccInformer)
+
+	cfg := validating.WebhookConfig{
+		Name: "kcctValidator",
+		Obj:  &unstructured.Unstructured{},
+	}
+
+	webhook, err := validating.NewWebhook(cfg, wk, nil, nil, nil)
+	if err != nil {
+		return nil, wk.Run, err
+	}
+	return webhook, wk.Run, nil
+}
+
+func (wk *WebhookKCCT) Run() bool {
+	go wk.targetHandler.Run()
+
+	if !cache.WaitForCacheSync(wk.ctx.Done(), wk.kccListerSynced, wk.targetHandler.HasSynced) {
+		klog.Errorf("unable to sync caches for %s webhook", kcctWebhookName)
+		return false
+	}
+	klog.Infof("Caches are synced for %s webhook", kcctWebhookName)
+
+	return true
+}
+
+func (wk *WebhookKCCT) Validate(ctx context.Context, obj metav1.Object) (bool, validating.ValidatorResult, error) {
+	klog.V(5).Info("notice an obj to be validated")
+	ar := whcontext.GetAdmissionRequest(ctx)
+	if ar == nil {
+		err := fmt.Errorf("failed to get admission request from context")
+		klog.Error(err.Error())
+		return false, validating.ValidatorResult{}, err
+	}
+
+	// deletion and status updates are always allowed, since the controller
+	// is responsible for writing status of kcc targets
+	if ar.Operation == admissionv1beta1.Delete || ar.SubResource != "" {
+		return false, validating.ValidatorResult{Valid: true, Message: "validation skipped"}, nil
+	}
+
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok || u == nil {
+		err := fmt.Errorf("failed to convert obj to unstructured: %v", obj)
+		klog.Error(err.Error())
+		return false, validating.ValidatorResult{}, err
+	}
+
+	// namespace may be omitted in the object, and it should be filled by request
+	if u.GetNamespace() == "" {
+		u.SetNamespace(ar.Namespace)
+	}
+
+	gvr := ar.Resource
+	key := native.GenerateUniqObjectNameKey(u)
+	tags := []metrics.MetricTag{
+		{Key: "gvr", Val: gvr.String()},
+		{Key: "name", Val: key},
+	}
+
+	klog.V(5).Infof("begin to validate kcc target %s %s", gvr.String(), key)
+
+	succeed, msg, err := wk.validateKCCT(gvr, util.ToKCCTargetResource(u))
+	if err != nil {
+		klog.Errorf("an err occurred when validating kcc target %s %s: %v", gvr.String(), key, err)
+		_ = wk.metricEmitter.StoreInt64("kcct_webhook_error", 1, metrics.MetricTypeNameCount, tags...)
+		return false, validating.ValidatorResult{}, err
+	} else if !succeed {
+		klog.Infof("kcc target %s %s didn't pass the webhook: %s", gvr.String(), key, msg)
+		_ = wk.metricEmitter.StoreInt64("kcct_webhook_fail", 1, metrics.MetricTypeNameCount, tags...)
+		if !wk.dryRun {
+			return false, validating.ValidatorResult{Valid: false, Message: msg}, nil
+		}
+	}
+
+	_ = wk.metricEmitter.StoreInt64("kcct_webhook_succeed", 1, metrics.MetricTypeNameCount, tags...)
+	klog.Infof("kcc target %s %s passed the validation webhook", gvr.String(), key)
+	return false, validating.ValidatorResult{Valid: true, Message: "validation succeed"}, nil
+}
+
+// validateKCCT checks the kcc target against the kcc referencing its gvr
+// and all the existing targets of the same gvr.
+func (wk *WebhookKCCT) validateKCCT(gvr metav1.GroupVersionResource, targetResource util.KCCTargetResource) (bool, string, error) {
+	if !targetResource.NeedValidateKCC() {
+		return true, "", nil
+	}
+
+	kccKeys := wk.targetHandler.GetKCCKeyListByGVR(gvr)
+	if len(kccKeys) != 1 {
+		return false, fmt.Sprintf("more or less than one kcc %v match same gvr %s", kccKeys, gvr.String()), nil
+	}
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(kccKeys[0])
+	if err != nil {
+		return false, "", fmt.Errorf("failed to split namespace and name from kcc key %s: %w", kccKeys[0], err)
+	}
+
+	kcc, err := wk.kccLister.KatalystCustomConfigs(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		return false, fmt.Sprintf("kcc %s is not found", kccKeys[0]), nil
+	} else if err != nil {
+		return false, "", fmt.Errorf("get kcc %s failed: %w", kccKeys[0], err)
+	}
+
+	accessor, ok := wk.targetHandler.GetTargetAccessorByGVR(gvr)
+	if !ok {
+		return false, "", fmt.Errorf("target accessor for gvr %s not found", gvr.String())
+	}
+
+	targets, err := accessor.List(labels.Everything())
+	if err != nil {
+		return false, "", fmt.Errorf("list kcc targets of gvr %s failed: %w", gvr.String(), err)
+	}
+
+	allTargetResources := make([]util.KCCTargetResource, 0, len(targets)+1)
+	for _, target := range targets {
+		// the target being terminated won't take effect any more
+		if target.GetDeletionTimestamp() != nil {
+			continue
+		}
+		allTargetResources = append(allTargetResources, util.ToKCCTargetResource(target))
+	}
+	allTargetResources = append(allTargetResources, targetResource)
+
+	valid, msg, err := kccutil.ValidateTargetResourceGenericSpec(kcc, targetResource, allTargetResources)
+	if err != nil || !valid {
+		return valid, msg, err
+	}
+
+	if canary := targetResource.GetCanary(); canary != nil {
+		if _, err := intstr.GetScaledValueFromIntOrPercent(canary, 100, false); err != nil {
+			return false, fmt.Sprintf("invalid canary %s: %s", canary.String(), err), nil
+		}
+	}
+
+	return true, "", nil
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kcct
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	whcontext "github.com/slok/kubewebhook/pkg/webhook/context"
+	"github.com/stretchr/testify/assert"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/config/v1alpha1"
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/crd"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	webhookconfig "github.com/kubewharf/katalyst-core/pkg/config/webhook"
+)
+
+func makeAdminQoSConfiguration(name string, spec v1alpha1.GenericConfigSpec) *v1alpha1.AdminQoSConfiguration {
+	return &v1alpha1.AdminQoSConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+			Kind:       "AdminQoSConfiguration",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: v1alpha1.AdminQoSConfigurationSpec{
+			GenericConfigSpec: spec,
+			Config: v1alpha1.AdminQoSConfig{
+				EvictionConfig: &v1alpha1.EvictionConfig{
+					ReclaimedResourcesEvictionConfig: &v1alpha1.ReclaimedResourcesEvictionConfig{
+						EvictionThreshold: map[v1.ResourceName]float64{
+							v1.ResourceCPU: 5.0,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestValidateKCCT(t *testing.T) {
+	t.Parallel()
+
+	kcc := &v1alpha1.KatalystCustomConfig{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+			Kind:       "KatalystCustomConfig",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-kcc",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.KatalystCustomConfigSpec{
+			TargetType: crd.AdminQoSConfigurationGVR,
+			NodeLabelSelectorAllowedKeyList: []v1alpha1.PriorityNodeLabelSelectorAllowedKeyList{
+				{
+					Priority: 0,
+					KeyList:  []string{"aa"},
+				},
+			},
+		},
+	}
+
+	existing := []runtime.Object{
+		makeAdminQoSConfiguration("default", v1alpha1.GenericConfigSpec{}),
+		makeAdminQoSConfiguration("aa-bb", v1alpha1.GenericConfigSpec{NodeLabelSelector: "aa=bb"}),
+	}
+
+	canary := intstr.FromString("abc")
+	for _, tc := range []struct {
+		name       string
+		target     *v1alpha1.AdminQoSConfiguration
+		operation  admissionv1beta1.Operation
+		dryRun     bool
+		expAllowed bool
+		expMessage string
+	}{
+		{
+			name:       "update existing global config",
+			target:     makeAdminQoSConfiguration("default", v1alpha1.GenericConfigSpec{}),
+			operation:  admissionv1beta1.Update,
+			expAllowed: true,
+			expMessage: "validation succeed",
+		},
+		{
+			name:       "another global config",
+			target:     makeAdminQoSConfiguration("default-2", v1alpha1.GenericConfigSpec{}),
+			operation:  admissionv1beta1.Create,
+			expAllowed: false,
+			expMessage: "overlay with others",
+		},
+		{
+			name:       "another global config in dry run",
+			target:     makeAdminQoSConfiguration("default-2", v1alpha1.GenericConfigSpec{}),
+			operation:  admissionv1beta1.Create,
+			dryRun:     true,
+			expAllowed: true,
+			expMessage: "validation succeed",
+		},
+		{
+			name:       "non-overlapped label selector",
+			target:     makeAdminQoSConfiguration("aa-cc", v1alpha1.GenericConfigSpec{NodeLabelSelector: "aa=cc"}),
+			operation:  admissionv1beta1.Create,
+			expAllowed: true,
+			expMessage: "validation succeed",
+		},
+		{
+			name:       "overlapped label selector",
+			target:     makeAdminQoSConfiguration("aa-bb-2", v1alpha1.GenericConfigSpec{NodeLabelSelector: "aa in (bb,cc)"}),
+			operation:  admissionv1beta1.Create,
+			expAllowed: false,
+			expMessage: "labelSelector overlay with others",
+		},
+		{
+			name:       "label selector with invalid key",
+			target:     makeAdminQoSConfiguration("dd-ee", v1alpha1.GenericConfigSpec{NodeLabelSelector: "dd=ee"}),
+			operation:  admissionv1beta1.Create,
+			expAllowed: false,
+			expMessage: "labelSelector with invalid key",
+		},
+		{
+			name: "node names without last duration",
+			target: makeAdminQoSConfiguration("node-1", v1alpha1.GenericConfigSpec{
+				EphemeralSelector: v1alpha1.EphemeralSelector{NodeNames: []string{"node-1"}},
+			}),
+			operation:  admissionv1beta1.Create,
+			expAllowed: false,
+			expMessage: "lastDuration no set",
+		},
+		{
+			name: "invalid canary",
+			target: makeAdminQoSConfiguration("aa-cc", v1alpha1.GenericConfigSpec{
+				NodeLabelSelector: "aa=cc",
+				UpdateStrategy: v1alpha1.ConfigUpdateStrategy{
+					RollingUpdate: &v1alpha1.RollingUpdateConfig{Canary: &canary},
+				},
+			}),
+			operation:  admissionv1beta1.Create,
+			expAllowed: false,
+			expMessage: "invalid canary",
+		},
+		{
+			name:       "delete global config",
+			target:     makeAdminQoSConfiguration("default-2", v1alpha1.GenericConfigSpec{}),
+			operation:  admissionv1beta1.Delete,
+			expAllowed: true,
+			expMessage: "validation skipped",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			genericConf := generic.NewGenericConfiguration()
+			genericConf.DryRun = tc.dryRun
+			webhookGenericConf := webhookconfig.NewGenericWebhookConfiguration()
+			webhookConf := webhookconfig.NewWebhooksConfiguration()
+			webhookConf.KCCTConfig.ValidAPIGroupSet = sets.NewString(v1alpha1.SchemeGroupVersion.Group)
+
+			webhookCtx, err := katalystbase.GenerateFakeGenericContext(nil, []runtime.Object{kcc}, existing)
+			assert.NoError(t, err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			wh, run, err := NewWebhookKCCT(ctx, webhookCtx, genericConf, webhookGenericConf, webhookConf, nil)
+			assert.NoError(t, err)
+
+			webhookCtx.StartInformer(ctx)
+			assert.True(t, run())
+
+			raw, err := json.Marshal(tc.target)
+			assert.NoError(t, err)
+
+			review := &admissionv1beta1.AdmissionReview{
+				Request: &admissionv1beta1.AdmissionRequest{
+					UID:       "test",
+					Operation: tc.operation,
+					Namespace: tc.target.Namespace,
+					Resource:  crd.AdminQoSConfigurationGVR,
+				},
+			}
+			if tc.operation == admissionv1beta1.Delete {
+				review.Request.OldObject = runtime.RawExtension{Raw: raw}
+			} else {
+				review.Request.Object = runtime.RawExtension{Raw: raw}
+			}
+
+			// the existing targets are listed by the dynamic informer started asynchronously
+			assert.EventuallyWithT(t, func(c *assert.CollectT) {
+				gotResponse := wh.Review(whcontext.SetAdmissionRequest(context.TODO(), review.Request), review)
+				assert.Equal(c, tc.expAllowed, gotResponse.Allowed)
+				assert.Equal(c, review.Request.UID, gotResponse.UID)
+				if assert.NotNil(c, gotResponse.Result) {
+					assert.Contains(c, gotResponse.Result.Message, tc.expMessage)
+				}
+			}, 5*time.Second, 50*time.Millisecond)
+		})
+	}
+}
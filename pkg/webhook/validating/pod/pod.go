@@ -0,0 +1,146 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	kubewebhook "github.com/slok/kubewebhook/pkg/webhook"
+	"github.com/slok/kubewebhook/pkg/webhook/validating"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	webhookconsts "github.com/kubewharf/katalyst-core/cmd/katalyst-webhook/app/webhook"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	webhookconfig "github.com/kubewharf/katalyst-core/pkg/config/webhook"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const (
+	podWebhookName = "pod-validating"
+)
+
+// WebhookPodValidator validates the given pod; if the pod is valid but with a non-empty message,
+// the message will be treated as a warning, and it won't block the admission of the pod.
+type WebhookPodValidator interface {
+	ValidatePod(pod *core.Pod) (valid bool, message string, err error)
+}
+
+// WebhookPod is the implementation of Kubernetes Webhook
+// any implementation should at least implement the interface of mutating.Mutator of validating.Validator
+type WebhookPod struct {
+	ctx    context.Context
+	dryRun bool
+
+	validators    []WebhookPodValidator
+	metricEmitter metrics.MetricEmitter
+}
+
+// NewWebhookPod makes the validating webhook of Pod
+func NewWebhookPod(ctx context.Context, _ *katalystbase.GenericContext,
+	genericConf *generic.GenericConfiguration, _ *webhookconfig.GenericWebhookConfiguration,
+	_ *webhookconfig.WebhooksConfiguration, metricsEmitter metrics.MetricEmitter,
+) (kubewebhook.Webhook, webhookconsts.GenericStartFunc, error) {
+	if genericConf == nil || genericConf.QoSConfiguration == nil {
+		return nil, nil, fmt.Errorf("qos configuration can't be nil")
+	}
+
+	wp := &WebhookPod{
+		ctx:    ctx,
+		dryRun: genericConf.DryRun,
+	}
+
+	wp.metricEmitter = metricsEmitter
+	if metricsEmitter == nil {
+		wp.metricEmitter = metrics.DummyMetrics{}
+	}
+
+	wp.validators = []WebhookPodValidator{
+		NewWebhookPodQoSValidator(genericConf.QoSConfiguration),
+		NewWebhookPodResourceValidator(genericConf.QoSConfiguration),
+	}
+
+	cfg := validating.WebhookConfig{
+		Name: "podValidator",
+		Obj:  &core.Pod{},
+	}
+
+	webhook, err := validating.NewWebhook(cfg, wp, nil, nil, nil)
+	if err != nil {
+		return nil, wp.Run, err
+	}
+	return webhook, wp.Run, nil
+}
+
+func (wp *WebhookPod) Run() bool {
+	klog.Infof("%s webhook run", podWebhookName)
+	return true
+}
+
+func (wp *WebhookPod) Validate(_ context.Context, obj metav1.Object) (bool, validating.ValidatorResult, error) {
+	klog.V(5).Info("notice an obj to be validated")
+	pod, ok := obj.(*core.Pod)
+	if !ok {
+		err := fmt.Errorf("failed to convert obj to pod: %v", obj)
+		klog.Error(err.Error())
+		return false, validating.ValidatorResult{}, err
+	}
+	if pod == nil {
+		err := fmt.Errorf("pod can't be nil")
+		klog.Error(err.Error())
+		return false, validating.ValidatorResult{}, err
+	}
+
+	klog.V(5).Infof("begin to validate pod %s/%s", pod.Namespace, pod.Name)
+
+	var warnings []string
+	for _, validator := range wp.validators {
+		succeed, msg, err := validator.ValidatePod(pod)
+		if err != nil {
+			klog.Errorf("an err occurred when validating pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			_ = wp.metricEmitter.StoreInt64("pod_validating_webhook_error", 1, metrics.MetricTypeNameCount,
+				metrics.MetricTag{Key: "namespace", Val: pod.Namespace})
+			return false, validating.ValidatorResult{}, err
+		} else if !succeed {
+			klog.Infof("pod %s/%s didn't pass the webhook: %s", pod.Namespace, pod.Name, msg)
+			_ = wp.metricEmitter.StoreInt64("pod_validating_webhook_fail", 1, metrics.MetricTypeNameCount,
+				metrics.MetricTag{Key: "namespace", Val: pod.Namespace})
+			if !wp.dryRun {
+				return false, validating.ValidatorResult{Valid: false, Message: msg}, nil
+			}
+			warnings = append(warnings, msg)
+		} else if msg != "" {
+			klog.Warningf("pod %s/%s passed the webhook with warning: %s", pod.Namespace, pod.Name, msg)
+			_ = wp.metricEmitter.StoreInt64("pod_validating_webhook_warn", 1, metrics.MetricTypeNameCount,
+				metrics.MetricTag{Key: "namespace", Val: pod.Namespace})
+			warnings = append(warnings, msg)
+		}
+	}
+
+	_ = wp.metricEmitter.StoreInt64("pod_validating_webhook_succeed", 1, metrics.MetricTypeNameCount,
+		metrics.MetricTag{Key: "namespace", Val: pod.Namespace})
+	klog.V(4).Infof("pod %s/%s passed the validation webhook", pod.Namespace, pod.Name)
+
+	if len(warnings) > 0 {
+		return false, validating.ValidatorResult{Valid: true, Message: strings.Join(warnings, "; ")}, nil
+	}
+	return false, validating.ValidatorResult{Valid: true, Message: "validation succeed"}, nil
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	webhookconfig "github.com/kubewharf/katalyst-core/pkg/config/webhook"
+)
+
+func makePod(annotations map[string]string, requests v1.ResourceList) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "pod1",
+			Annotations: annotations,
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name: "c1",
+					Resources: v1.ResourceRequirements{
+						Requests: requests,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestValidatePod(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name       string
+		pod        *v1.Pod
+		dryRun     bool
+		expAllowed bool
+		expMessage string
+	}{
+		{
+			name: "shared_cores pod without annotations",
+			pod: makePod(nil, v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("1500m"),
+			}),
+			expAllowed: true,
+			expMessage: "validation succeed",
+		},
+		{
+			name: "unknown qos level",
+			pod: makePod(map[string]string{
+				apiconsts.PodAnnotationQoSLevelKey: "unknown_cores",
+			}, nil),
+			expAllowed: false,
+			expMessage: "invalid qos level",
+		},
+		{
+			name: "unknown qos level in dry run",
+			pod: makePod(map[string]string{
+				apiconsts.PodAnnotationQoSLevelKey: "unknown_cores",
+			}, nil),
+			dryRun:     true,
+			expAllowed: true,
+			expMessage: "invalid qos level",
+		},
+		{
+			name: "malformed memory enhancement",
+			pod: makePod(map[string]string{
+				apiconsts.PodAnnotationQoSLevelKey:          apiconsts.PodAnnotationQoSLevelDedicatedCores,
+				apiconsts.PodAnnotationMemoryEnhancementKey: `{"numa_binding": true}`,
+			}, nil),
+			expAllowed: false,
+			expMessage: "invalid qos enhancement",
+		},
+		{
+			name: "numa_exclusive for shared_cores",
+			pod: makePod(map[string]string{
+				apiconsts.PodAnnotationQoSLevelKey:          apiconsts.PodAnnotationQoSLevelSharedCores,
+				apiconsts.PodAnnotationMemoryEnhancementKey: `{"numa_binding": "true", "numa_exclusive": "true"}`,
+			}, nil),
+			expAllowed: false,
+			expMessage: "numa_exclusive is only supported for dedicated_cores",
+		},
+		{
+			name: "numa_binding for shared_cores",
+			pod: makePod(map[string]string{
+				apiconsts.PodAnnotationQoSLevelKey:          apiconsts.PodAnnotationQoSLevelSharedCores,
+				apiconsts.PodAnnotationMemoryEnhancementKey: `{"numa_binding": "true"}`,
+			}, nil),
+			expAllowed: true,
+			expMessage: "relies on numa binding support",
+		},
+		{
+			name: "invalid oom priority",
+			pod: makePod(map[string]string{
+				apiconsts.PodAnnotationMemoryEnhancementKey: `{"oom_priority": "high"}`,
+			}, nil),
+			expAllowed: false,
+			expMessage: "invalid oom_priority",
+		},
+		{
+			name: "dedicated_cores with integer cpu",
+			pod: makePod(map[string]string{
+				apiconsts.PodAnnotationQoSLevelKey:          apiconsts.PodAnnotationQoSLevelDedicatedCores,
+				apiconsts.PodAnnotationMemoryEnhancementKey: `{"numa_binding": "true", "numa_exclusive": "true"}`,
+			}, v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("4"),
+			}),
+			expAllowed: true,
+			expMessage: "validation succeed",
+		},
+		{
+			name: "dedicated_cores with non-integer cpu",
+			pod: makePod(map[string]string{
+				apiconsts.PodAnnotationQoSLevelKey: apiconsts.PodAnnotationQoSLevelDedicatedCores,
+			}, v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("1500m"),
+			}),
+			expAllowed: false,
+			expMessage: "must be an integer",
+		},
+		{
+			name: "dedicated_cores with non-integer cpu in init container",
+			pod: func() *v1.Pod {
+				pod := makePod(map[string]string{
+					apiconsts.PodAnnotationQoSLevelKey: apiconsts.PodAnnotationQoSLevelDedicatedCores,
+				}, v1.ResourceList{
+					v1.ResourceCPU: resource.MustParse("4"),
+				})
+				pod.Spec.InitContainers = []v1.Container{{
+					Name: "init",
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")},
+					},
+				}}
+				return pod
+			}(),
+			expAllowed: false,
+			expMessage: "cpu request 500m of container init must be an integer",
+		},
+		{
+			name: "shared_cores with reclaimed resources in init container",
+			pod: func() *v1.Pod {
+				pod := makePod(nil, nil)
+				pod.Spec.InitContainers = []v1.Container{{
+					Name: "init",
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{apiconsts.ReclaimedResourceMemory: resource.MustParse("1Gi")},
+					},
+				}}
+				return pod
+			}(),
+			expAllowed: false,
+			expMessage: "container init requests resource.katalyst.kubewharf.io/reclaimed_memory",
+		},
+		{
+			name: "shared_cores with reclaimed resources",
+			pod: makePod(nil, v1.ResourceList{
+				apiconsts.ReclaimedResourceMilliCPU: resource.MustParse("1000"),
+			}),
+			expAllowed: false,
+			expMessage: "which is only supported for reclaimed_cores",
+		},
+		{
+			name: "reclaimed_cores with reclaimed resources",
+			pod: makePod(map[string]string{
+				apiconsts.PodAnnotationQoSLevelKey: apiconsts.PodAnnotationQoSLevelReclaimedCores,
+			}, v1.ResourceList{
+				apiconsts.ReclaimedResourceMilliCPU: resource.MustParse("1000"),
+				apiconsts.ReclaimedResourceMemory:   resource.MustParse("1Gi"),
+			}),
+			expAllowed: true,
+			expMessage: "validation succeed",
+		},
+		{
+			name: "reclaimed_cores with native resources",
+			pod: makePod(map[string]string{
+				apiconsts.PodAnnotationQoSLevelKey: apiconsts.PodAnnotationQoSLevelReclaimedCores,
+			}, v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("1"),
+			}),
+			expAllowed: true,
+			expMessage: "instead of reclaimed resources",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			genericConf := generic.NewGenericConfiguration()
+			genericConf.DryRun = tc.dryRun
+			webhookGenericConf := webhookconfig.NewGenericWebhookConfiguration()
+
+			controlCtx, err := katalystbase.GenerateFakeGenericContext(nil, nil, []runtime.Object{})
+			assert.NoError(t, err)
+
+			wh, _, err := NewWebhookPod(context.TODO(), controlCtx, genericConf, webhookGenericConf,
+				webhookconfig.NewWebhooksConfiguration(), nil)
+			assert.NoError(t, err)
+
+			raw, err := json.Marshal(tc.pod)
+			assert.NoError(t, err)
+
+			review := &admissionv1beta1.AdmissionReview{
+				Request: &admissionv1beta1.AdmissionRequest{
+					UID:       "test",
+					Operation: admissionv1beta1.Create,
+					Object: runtime.RawExtension{
+						Raw: raw,
+					},
+				},
+			}
+
+			gotResponse := wh.Review(context.TODO(), review)
+			assert.Equal(t, tc.expAllowed, gotResponse.Allowed)
+			assert.Equal(t, review.Request.UID, gotResponse.UID)
+			assert.NotNil(t, gotResponse.Result)
+			assert.Contains(t, gotResponse.Result.Message, tc.expMessage)
+		})
+	}
+}
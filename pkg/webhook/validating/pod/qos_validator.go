@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"fmt"
+
+	core "k8s.io/api/core/v1"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	"github.com/kubewharf/katalyst-core/pkg/util/qos"
+)
+
+// WebhookPodQoSValidator validate:
+// 1. if qos level annotations can be parsed into a valid katalyst qos level without conflicts
+// 2. if qos enhancement annotations are well-formed json
+// 3. if the memory enhancements are supported by the qos level of the pod
+type WebhookPodQoSValidator struct {
+	qosConf *generic.QoSConfiguration
+}
+
+func NewWebhookPodQoSValidator(qosConf *generic.QoSConfiguration) *WebhookPodQoSValidator {
+	return &WebhookPodQoSValidator{
+		qosConf: qosConf,
+	}
+}
+
+func (qv *WebhookPodQoSValidator) ValidatePod(pod *core.Pod) (valid bool, message string, err error) {
+	if pod == nil {
+		err := fmt.Errorf("pod is nil")
+		return false, err.Error(), err
+	}
+
+	qosLevel, err := qv.qosConf.GetQoSLevelForPod(pod)
+	if err != nil {
+		return false, fmt.Sprintf("invalid qos level: %v", err), nil
+	}
+
+	if err := qv.qosConf.CheckQoSEnhancements(pod, map[string]string{}); err != nil {
+		return false, fmt.Sprintf("invalid qos enhancement: %v", err), nil
+	}
+
+	memoryEnhancement := qos.ParseMemoryEnhancement(qv.qosConf, pod)
+	_, exclusiveSpecified := memoryEnhancement[apiconsts.PodAnnotationMemoryEnhancementNumaExclusive]
+	if exclusiveSpecified && qosLevel != apiconsts.PodAnnotationQoSLevelDedicatedCores {
+		return false, fmt.Sprintf("%s is only supported for %s, but got %s",
+			apiconsts.PodAnnotationMemoryEnhancementNumaExclusive, apiconsts.PodAnnotationQoSLevelDedicatedCores, qosLevel), nil
+	}
+
+	if _, invalid := qos.GetRSSOverUseEvictThreshold(qv.qosConf, pod); invalid {
+		return false, fmt.Sprintf("invalid %s in memory enhancement",
+			apiconsts.PodAnnotationMemoryEnhancementRssOverUseThreshold), nil
+	}
+
+	if _, invalid := qos.GetOOMPriority(qv.qosConf, pod); invalid {
+		return false, fmt.Sprintf("invalid %s in memory enhancement",
+			apiconsts.PodAnnotationMemoryEnhancementOOMPriority), nil
+	}
+
+	if qosLevel == apiconsts.PodAnnotationQoSLevelReclaimedCores {
+		if _, err := qos.GetPodCPUSuppressionToleranceRate(qv.qosConf, pod); err != nil {
+			return false, fmt.Sprintf("invalid %s in cpu enhancement: %v",
+				apiconsts.PodAnnotationCPUEnhancementSuppressionToleranceRate, err), nil
+		}
+	}
+
+	// the following combinations are accepted by the agents, but may not behave as users expect
+	if exclusiveSpecified && !qos.AnnotationsIndicateNUMABinding(memoryEnhancement) {
+		return true, fmt.Sprintf("%s takes no effect without %s",
+			apiconsts.PodAnnotationMemoryEnhancementNumaExclusive, apiconsts.PodAnnotationMemoryEnhancementNumaBinding), nil
+	}
+
+	if qos.AnnotationsIndicateNUMABinding(memoryEnhancement) && qosLevel == apiconsts.PodAnnotationQoSLevelSharedCores {
+		return true, fmt.Sprintf("%s for %s relies on numa binding support of the agents",
+			apiconsts.PodAnnotationMemoryEnhancementNumaBinding, qosLevel), nil
+	}
+
+	return true, "", nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"fmt"
+
+	core "k8s.io/api/core/v1"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+)
+
+var reclaimedResources = []core.ResourceName{apiconsts.ReclaimedResourceMilliCPU, apiconsts.ReclaimedResourceMemory}
+
+// WebhookPodResourceValidator validate (for both init containers and containers):
+// 1. if cpu requests of dedicated_cores containers are integers
+// 2. if reclaimed resources are only requested by reclaimed_cores pods
+type WebhookPodResourceValidator struct {
+	qosConf *generic.QoSConfiguration
+}
+
+func NewWebhookPodResourceValidator(qosConf *generic.QoSConfiguration) *WebhookPodResourceValidator {
+	return &WebhookPodResourceValidator{
+		qosConf: qosConf,
+	}
+}
+
+func (rv *WebhookPodResourceValidator) ValidatePod(pod *core.Pod) (valid bool, message string, err error) {
+	if pod == nil {
+		err := fmt.Errorf("pod is nil")
+		return false, err.Error(), err
+	}
+
+	qosLevel, err := rv.qosConf.GetQoSLevelForPod(pod)
+	if err != nil {
+		return false, fmt.Sprintf("invalid qos level: %v", err), nil
+	}
+
+	containers := make([]core.Container, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers))
+	containers = append(containers, pod.Spec.InitContainers...)
+	containers = append(containers, pod.Spec.Containers...)
+
+	switch qosLevel {
+	case apiconsts.PodAnnotationQoSLevelDedicatedCores:
+		for _, container := range containers {
+			cpuRequest := container.Resources.Requests.Cpu()
+			if cpuRequest.MilliValue()%1000 != 0 {
+				return false, fmt.Sprintf("cpu request %s of container %s must be an integer for %s",
+					cpuRequest.String(), container.Name, qosLevel), nil
+			}
+		}
+	case apiconsts.PodAnnotationQoSLevelReclaimedCores:
+		var nativeRequested []string
+		for _, container := range containers {
+			for _, resourceName := range []core.ResourceName{core.ResourceCPU, core.ResourceMemory} {
+				if quantity, ok := container.Resources.Requests[resourceName]; ok && !quantity.IsZero() {
+					nativeRequested = append(nativeRequested, fmt.Sprintf("%s/%s", container.Name, resourceName))
+				}
+			}
+		}
+
+		// reclaimed_cores pods are supposed to consume reclaimed resources only,
+		// but native resources are still accepted for compatibility
+		if len(nativeRequested) > 0 {
+			return true, fmt.Sprintf("%s pod requests native resources %v instead of reclaimed resources",
+				qosLevel, nativeRequested), nil
+		}
+		return true, "", nil
+	}
+
+	for _, container := range containers {
+		for _, resourceName := range reclaimedResources {
+			if _, ok := container.Resources.Requests[resourceName]; ok {
+				return false, fmt.Sprintf("container %s requests %s, which is only supported for %s, but got %s",
+					container.Name, resourceName, apiconsts.PodAnnotationQoSLevelReclaimedCores, qosLevel), nil
+			}
+			if _, ok := container.Resources.Limits[resourceName]; ok {
+				return false, fmt.Sprintf("container %s limits %s, which is only supported for %s, but got %s",
+					container.Name, resourceName, apiconsts.PodAnnotationQoSLevelReclaimedCores, qosLevel), nil
+			}
+		}
+	}
+
+	return true, "", nil
+}
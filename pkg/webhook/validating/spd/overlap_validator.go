@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spd
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	apis "github.com/kubewharf/katalyst-api/pkg/apis/workload/v1alpha1"
+	apiListers "github.com/kubewharf/katalyst-api/pkg/client/listers/workload/v1alpha1"
+)
+
+// WebhookSPDOverlapValidator validate if one spd overlaps with other spds in the same
+// namespace by checking their target reference, since each workload can only be
+// described by one spd
+type WebhookSPDOverlapValidator struct {
+	// spdLister can list/get ServiceProfileDescriptor from the shared informer's store
+	spdLister apiListers.ServiceProfileDescriptorLister
+}
+
+func NewWebhookSPDOverlapValidator(spdLister apiListers.ServiceProfileDescriptorLister) *WebhookSPDOverlapValidator {
+	return &WebhookSPDOverlapValidator{
+		spdLister: spdLister,
+	}
+}
+
+func (wo *WebhookSPDOverlapValidator) ValidateSPD(spd *apis.ServiceProfileDescriptor) (valid bool, message string, err error) {
+	if spd == nil {
+		err := fmt.Errorf("spd is nil")
+		return false, err.Error(), err
+	}
+
+	spds, err := wo.spdLister.ServiceProfileDescriptors(spd.Namespace).List(labels.Everything())
+	if err != nil {
+		return false, "failed to list spds", err
+	}
+	klog.V(5).Infof("find %d spd existing in namespace %s", len(spds), spd.Namespace)
+
+	for _, anotherSPD := range spds {
+		if anotherSPD == nil || anotherSPD.Name == spd.Name {
+			continue
+		}
+
+		if anotherSPD.Spec.TargetRef.Kind == spd.Spec.TargetRef.Kind &&
+			anotherSPD.Spec.TargetRef.Name == spd.Spec.TargetRef.Name {
+			return false, fmt.Sprintf("spd %s has the same target reference %s/%s",
+				anotherSPD.Name, spd.Spec.TargetRef.Kind, spd.Spec.TargetRef.Name), nil
+		}
+	}
+
+	return true, "", nil
+}
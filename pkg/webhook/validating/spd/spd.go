@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spd
+
+import (
+	"context"
+	"fmt"
+
+	kubewebhook "github.com/slok/kubewebhook/pkg/webhook"
+	"github.com/slok/kubewebhook/pkg/webhook/validating"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	apis "github.com/kubewharf/katalyst-api/pkg/apis/workload/v1alpha1"
+	apiListers "github.com/kubewharf/katalyst-api/pkg/client/listers/workload/v1alpha1"
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	webhookconsts "github.com/kubewharf/katalyst-core/cmd/katalyst-webhook/app/webhook"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	webhookconfig "github.com/kubewharf/katalyst-core/pkg/config/webhook"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const (
+	spdWebhookName = "spd"
+)
+
+// WebhookSPD is the implementation of Kubernetes Webhook
+// any implementation should at least implement the interface of mutating.Mutator of validating.Validator
+type WebhookSPD struct {
+	ctx    context.Context
+	dryRun bool
+
+	validators    []WebhookSPDValidator
+	metricEmitter metrics.MetricEmitter
+
+	// spdListerSynced returns true if the ServiceProfileDescriptor store has been synced at least once.
+	spdListerSynced cache.InformerSynced
+	// spdLister can list/get ServiceProfileDescriptor from the shared informer's store
+	spdLister apiListers.ServiceProfileDescriptorLister
+}
+
+type WebhookSPDValidator interface {
+	ValidateSPD(spd *apis.ServiceProfileDescriptor) (valid bool, message string, err error)
+}
+
+func NewWebhookSPD(ctx context.Context, webhookCtx *katalystbase.GenericContext,
+	genericConf *generic.GenericConfiguration, _ *webhookconfig.GenericWebhookConfiguration,
+	_ *webhookconfig.WebhooksConfiguration, metricsEmitter metrics.MetricEmitter,
+) (kubewebhook.Webhook, webhookconsts.GenericStartFunc, error) {
+	ws := &WebhookSPD{
+		ctx:    ctx,
+		dryRun: genericConf.DryRun,
+	}
+
+	ws.metricEmitter = metricsEmitter
+	if metricsEmitter == nil {
+		ws.metricEmitter = metrics.DummyMetrics{}
+	}
+
+	ws.spdListerSynced = webhookCtx.InternalInformerFactory.Workload().V1alpha1().ServiceProfileDescriptors().Informer().HasSynced
+	ws.spdLister = webhookCtx.InternalInformerFactory.Workload().V1alpha1().ServiceProfileDescriptors().Lister()
+
+	ws.validators = []WebhookSPDValidator{
+		NewWebhookSPDSpecValidator(),
+		NewWebhookSPDOverlapValidator(ws.spdLister),
+	}
+
+	cfg := validating.WebhookConfig{
+		Name: "spdValidator",
+		Obj:  &apis.ServiceProfileDescriptor{},
+	}
+
+	webhook, err := validating.NewWebhook(cfg, ws, nil, nil, nil)
+	if err != nil {
+		return nil, ws.Run, err
+	}
+	return webhook, ws.Run, nil
+}
+
+func (ws *WebhookSPD) Run() bool {
+	if !cache.WaitForCacheSync(ws.ctx.Done(), ws.spdListerSynced) {
+		klog.Errorf("unable to sync caches for %s webhook", spdWebhookName)
+		return false
+	}
+	klog.Infof("Caches are synced for %s webhook", spdWebhookName)
+
+	return true
+}
+
+func (ws *WebhookSPD) Validate(_ context.Context, obj metav1.Object) (bool, validating.ValidatorResult, error) {
+	klog.V(5).Info("notice an obj to be validated")
+	spd, ok := obj.(*apis.ServiceProfileDescriptor)
+	if !ok {
+		err := fmt.Errorf("failed to convert obj to spd: %v", obj)
+		klog.Error(err.Error())
+		return false, validating.ValidatorResult{}, err
+	}
+	if spd == nil {
+		err := fmt.Errorf("spd can't be nil")
+		klog.Error(err.Error())
+		return false, validating.ValidatorResult{}, err
+	}
+
+	klog.V(5).Infof("begin to validate spd %s/%s", spd.Namespace, spd.Name)
+
+	for _, validator := range ws.validators {
+		succeed, msg, err := validator.ValidateSPD(spd)
+		if err != nil {
+			klog.Errorf("an err occurred when validating spd %s/%s: %v", spd.Namespace, spd.Name, err)
+			_ = ws.metricEmitter.StoreInt64("spd_webhook_error", 1, metrics.MetricTypeNameCount,
+				metrics.MetricTag{Key: "name", Val: spd.Name})
+			return false, validating.ValidatorResult{}, err
+		} else if !succeed {
+			klog.Infof("spd %s/%s didn't pass the webhook: %s", spd.Namespace, spd.Name, msg)
+			_ = ws.metricEmitter.StoreInt64("spd_webhook_fail", 1, metrics.MetricTypeNameCount,
+				metrics.MetricTag{Key: "name", Val: spd.Name})
+			if !ws.dryRun {
+				return false, validating.ValidatorResult{Valid: false, Message: msg}, nil
+			}
+		}
+	}
+
+	_ = ws.metricEmitter.StoreInt64("spd_webhook_succeed", 1, metrics.MetricTypeNameCount,
+		metrics.MetricTag{Key: "name", Val: spd.Name})
+	klog.Infof("spd %s/%s passed the validation webhook", spd.Namespace, spd.Name)
+	return false, validating.ValidatorResult{Valid: true, Message: "validation succeed"}, nil
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spd
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+
+	autoscalingapis "github.com/kubewharf/katalyst-api/pkg/apis/autoscaling/v1alpha1"
+	apis "github.com/kubewharf/katalyst-api/pkg/apis/workload/v1alpha1"
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	webhookconfig "github.com/kubewharf/katalyst-core/pkg/config/webhook"
+)
+
+func makeSPD(name, workload string, spec apis.ServiceProfileDescriptorSpec) *apis.ServiceProfileDescriptor {
+	spec.TargetRef = autoscalingapis.CrossVersionObjectReference{
+		Kind:       "Deployment",
+		Name:       workload,
+		APIVersion: "apps/v1",
+	}
+	return &apis.ServiceProfileDescriptor{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+		},
+		Spec: spec,
+	}
+}
+
+func TestValidateSPD(t *testing.T) {
+	t.Parallel()
+
+	existing := []runtime.Object{
+		makeSPD("spd1", "dp1", apis.ServiceProfileDescriptorSpec{}),
+	}
+
+	for _, tc := range []struct {
+		name       string
+		spd        *apis.ServiceProfileDescriptor
+		dryRun     bool
+		expAllowed bool
+		expMessage string
+	}{
+		{
+			name: "valid spd",
+			spd: makeSPD("spd2", "dp2", apis.ServiceProfileDescriptorSpec{
+				BaselinePercent: pointer.Int32(50),
+				BusinessIndicator: []apis.ServiceBusinessIndicatorSpec{
+					{
+						Name: apis.ServiceBusinessIndicatorNameRPCLatency,
+						Indicators: []apis.Indicator{
+							{IndicatorLevel: apis.IndicatorLevelLowerBound, Value: 10},
+							{IndicatorLevel: apis.IndicatorLevelUpperBound, Value: 20},
+						},
+					},
+				},
+			}),
+			expAllowed: true,
+			expMessage: "validation succeed",
+		},
+		{
+			name:       "update existing spd",
+			spd:        makeSPD("spd1", "dp1", apis.ServiceProfileDescriptorSpec{}),
+			expAllowed: true,
+			expMessage: "validation succeed",
+		},
+		{
+			name:       "overlapped target reference",
+			spd:        makeSPD("spd2", "dp1", apis.ServiceProfileDescriptorSpec{}),
+			expAllowed: false,
+			expMessage: "has the same target reference",
+		},
+		{
+			name:       "overlapped target reference in dry run",
+			spd:        makeSPD("spd2", "dp1", apis.ServiceProfileDescriptorSpec{}),
+			dryRun:     true,
+			expAllowed: true,
+			expMessage: "validation succeed",
+		},
+		{
+			name:       "empty target reference",
+			spd:        makeSPD("spd2", "", apis.ServiceProfileDescriptorSpec{}),
+			expAllowed: false,
+			expMessage: "target reference must be set",
+		},
+		{
+			name: "baseline percent out of range",
+			spd: makeSPD("spd2", "dp2", apis.ServiceProfileDescriptorSpec{
+				BaselinePercent: pointer.Int32(101),
+			}),
+			expAllowed: false,
+			expMessage: "out of range",
+		},
+		{
+			name: "duplicated extended indicator",
+			spd: makeSPD("spd2", "dp2", apis.ServiceProfileDescriptorSpec{
+				ExtendedIndicator: []apis.ServiceExtendedIndicatorSpec{
+					{Name: "TestExtended"},
+					{Name: "TestExtended"},
+				},
+			}),
+			expAllowed: false,
+			expMessage: "extended indicator TestExtended is duplicated",
+		},
+		{
+			name: "lower bound greater than upper bound",
+			spd: makeSPD("spd2", "dp2", apis.ServiceProfileDescriptorSpec{
+				SystemIndicator: []apis.ServiceSystemIndicatorSpec{
+					{
+						Name: apis.ServiceSystemIndicatorNameCPUUsageRatio,
+						Indicators: []apis.Indicator{
+							{IndicatorLevel: apis.IndicatorLevelLowerBound, Value: 0.8},
+							{IndicatorLevel: apis.IndicatorLevelUpperBound, Value: 0.5},
+						},
+					},
+				},
+			}),
+			expAllowed: false,
+			expMessage: "is greater than upper bound",
+		},
+		{
+			name: "unknown indicator level",
+			spd: makeSPD("spd2", "dp2", apis.ServiceProfileDescriptorSpec{
+				SystemIndicator: []apis.ServiceSystemIndicatorSpec{
+					{
+						Name: apis.ServiceSystemIndicatorNameCPUUsageRatio,
+						Indicators: []apis.Indicator{
+							{IndicatorLevel: "Middle", Value: 0.5},
+						},
+					},
+				},
+			}),
+			expAllowed: false,
+			expMessage: "unknown indicator level",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			genericConf := generic.NewGenericConfiguration()
+			genericConf.DryRun = tc.dryRun
+			webhookGenericConf := webhookconfig.NewGenericWebhookConfiguration()
+
+			webhookCtx, err := katalystbase.GenerateFakeGenericContext(nil, existing, nil)
+			assert.NoError(t, err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			wh, run, err := NewWebhookSPD(ctx, webhookCtx, genericConf, webhookGenericConf,
+				webhookconfig.NewWebhooksConfiguration(), nil)
+			assert.NoError(t, err)
+
+			webhookCtx.StartInformer(ctx)
+			assert.True(t, run())
+
+			raw, err := json.Marshal(tc.spd)
+			assert.NoError(t, err)
+
+			review := &admissionv1beta1.AdmissionReview{
+				Request: &admissionv1beta1.AdmissionRequest{
+					UID:       "test",
+					Operation: admissionv1beta1.Create,
+					Object: runtime.RawExtension{
+						Raw: raw,
+					},
+				},
+			}
+
+			gotResponse := wh.Review(context.TODO(), review)
+			assert.Equal(t, tc.expAllowed, gotResponse.Allowed)
+			assert.Equal(t, review.Request.UID, gotResponse.UID)
+			assert.NotNil(t, gotResponse.Result)
+			assert.Contains(t, gotResponse.Result.Message, tc.expMessage)
+		})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spd
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	apis "github.com/kubewharf/katalyst-api/pkg/apis/workload/v1alpha1"
+	"github.com/kubewharf/katalyst-api/pkg/consts"
+)
+
+// WebhookSPDSpecValidator validate:
+// 1. if target reference is set
+// 2. if baseline percents are within the valid range
+// 3. if indicator names are not duplicated
+// 4. if indicator levels are known and lower bounds are not greater than upper bounds
+type WebhookSPDSpecValidator struct{}
+
+func NewWebhookSPDSpecValidator() *WebhookSPDSpecValidator {
+	return &WebhookSPDSpecValidator{}
+}
+
+func (sv *WebhookSPDSpecValidator) ValidateSPD(spd *apis.ServiceProfileDescriptor) (valid bool, message string, err error) {
+	if spd == nil {
+		err := fmt.Errorf("spd is nil")
+		return false, err.Error(), err
+	}
+
+	if spd.Spec.TargetRef.Kind == "" || spd.Spec.TargetRef.Name == "" {
+		return false, "kind and name of target reference must be set", nil
+	}
+
+	if msg := validateBaselinePercent(spd.Spec.BaselinePercent); msg != "" {
+		return false, msg, nil
+	}
+
+	extendedNames := sets.NewString()
+	for _, indicator := range spd.Spec.ExtendedIndicator {
+		if indicator.Name == "" {
+			return false, "name of extended indicator must be set", nil
+		} else if extendedNames.Has(indicator.Name) {
+			return false, fmt.Sprintf("extended indicator %s is duplicated", indicator.Name), nil
+		}
+		extendedNames.Insert(indicator.Name)
+
+		if msg := validateBaselinePercent(indicator.BaselinePercent); msg != "" {
+			return false, fmt.Sprintf("extended indicator %s: %s", indicator.Name, msg), nil
+		}
+	}
+
+	businessNames := sets.NewString()
+	for _, indicator := range spd.Spec.BusinessIndicator {
+		name := string(indicator.Name)
+		if businessNames.Has(name) {
+			return false, fmt.Sprintf("business indicator %s is duplicated", name), nil
+		}
+		businessNames.Insert(name)
+
+		if msg := validateIndicators(indicator.Indicators); msg != "" {
+			return false, fmt.Sprintf("business indicator %s: %s", name, msg), nil
+		}
+	}
+
+	systemNames := sets.NewString()
+	for _, indicator := range spd.Spec.SystemIndicator {
+		name := string(indicator.Name)
+		if systemNames.Has(name) {
+			return false, fmt.Sprintf("system indicator %s is duplicated", name), nil
+		}
+		systemNames.Insert(name)
+
+		if msg := validateIndicators(indicator.Indicators); msg != "" {
+			return false, fmt.Sprintf("system indicator %s: %s", name, msg), nil
+		}
+	}
+
+	return true, "", nil
+}
+
+func validateBaselinePercent(baselinePercent *int32) string {
+	if baselinePercent == nil {
+		return ""
+	}
+
+	if *baselinePercent < consts.SPDBaselinePercentMin || *baselinePercent > consts.SPDBaselinePercentMax {
+		return fmt.Sprintf("baseline percent %d is out of range [%d, %d]",
+			*baselinePercent, consts.SPDBaselinePercentMin, consts.SPDBaselinePercentMax)
+	}
+	return ""
+}
+
+func validateIndicators(indicators []apis.Indicator) string {
+	levels := make(map[apis.IndicatorLevelName]float32, len(indicators))
+	for _, indicator := range indicators {
+		switch indicator.IndicatorLevel {
+		case apis.IndicatorLevelLowerBound, apis.IndicatorLevelUpperBound:
+		default:
+			return fmt.Sprintf("unknown indicator level %s", indicator.IndicatorLevel)
+		}
+
+		if _, ok := levels[indicator.IndicatorLevel]; ok {
+			return fmt.Sprintf("indicator level %s is duplicated", indicator.IndicatorLevel)
+		}
+		levels[indicator.IndicatorLevel] = indicator.Value
+	}
+
+	lower, lowerOk := levels[apis.IndicatorLevelLowerBound]
+	upper, upperOk := levels[apis.IndicatorLevelUpperBound]
+	if lowerOk && upperOk && lower > upper {
+		return fmt.Sprintf("lower bound %v is greater than upper bound %v", lower, upper)
+	}
+	return ""
+}